@@ -81,6 +81,34 @@ func (mr *MockStreamHandlerMockRecorder) Delete(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockStreamHandler)(nil).Delete), arg0, arg1)
 }
 
+// DeleteObject mocks base method.
+func (m *MockStreamHandler) DeleteObject(arg0 context.Context, arg1 *access0.ObjectInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteObject", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteObject indicates an expected call of DeleteObject.
+func (mr *MockStreamHandlerMockRecorder) DeleteObject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteObject", reflect.TypeOf((*MockStreamHandler)(nil).DeleteObject), arg0, arg1)
+}
+
+// Expire mocks base method.
+func (m *MockStreamHandler) Expire(arg0 context.Context, arg1 *access0.Location, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expire", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockStreamHandlerMockRecorder) Expire(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockStreamHandler)(nil).Expire), arg0, arg1, arg2)
+}
+
 // Get mocks base method.
 func (m *MockStreamHandler) Get(arg0 context.Context, arg1 io.Writer, arg2 access0.Location, arg3, arg4 uint64) (func() error, error) {
 	m.ctrl.T.Helper()
@@ -96,6 +124,51 @@ func (mr *MockStreamHandlerMockRecorder) Get(arg0, arg1, arg2, arg3, arg4 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStreamHandler)(nil).Get), arg0, arg1, arg2, arg3, arg4)
 }
 
+// GetObject mocks base method.
+func (m *MockStreamHandler) GetObject(arg0 context.Context, arg1, arg2 string) (*access0.ObjectInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetObject", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*access0.ObjectInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetObject indicates an expected call of GetObject.
+func (mr *MockStreamHandlerMockRecorder) GetObject(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObject", reflect.TypeOf((*MockStreamHandler)(nil).GetObject), arg0, arg1, arg2)
+}
+
+// ListExpiring mocks base method.
+func (m *MockStreamHandler) ListExpiring(arg0 context.Context, arg1 *access0.ListExpiringArgs) (*access0.ListExpiringResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiring", arg0, arg1)
+	ret0, _ := ret[0].(*access0.ListExpiringResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiring indicates an expected call of ListExpiring.
+func (mr *MockStreamHandlerMockRecorder) ListExpiring(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiring", reflect.TypeOf((*MockStreamHandler)(nil).ListExpiring), arg0, arg1)
+}
+
+// ListObjects mocks base method.
+func (m *MockStreamHandler) ListObjects(arg0 context.Context, arg1 *access0.ListObjectsArgs) (*access0.ListObjectsResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListObjects", arg0, arg1)
+	ret0, _ := ret[0].(*access0.ListObjectsResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListObjects indicates an expected call of ListObjects.
+func (mr *MockStreamHandlerMockRecorder) ListObjects(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListObjects", reflect.TypeOf((*MockStreamHandler)(nil).ListObjects), arg0, arg1)
+}
+
 // Put mocks base method.
 func (m *MockStreamHandler) Put(arg0 context.Context, arg1 io.Reader, arg2 int64, arg3 access0.HasherMap) (*access0.Location, error) {
 	m.ctrl.T.Helper()
@@ -125,6 +198,20 @@ func (mr *MockStreamHandlerMockRecorder) PutAt(arg0, arg1, arg2, arg3, arg4, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutAt", reflect.TypeOf((*MockStreamHandler)(nil).PutAt), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 }
 
+// SetObject mocks base method.
+func (m *MockStreamHandler) SetObject(arg0 context.Context, arg1, arg2 *access0.ObjectInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetObject", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetObject indicates an expected call of SetObject.
+func (mr *MockStreamHandlerMockRecorder) SetObject(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetObject", reflect.TypeOf((*MockStreamHandler)(nil).SetObject), arg0, arg1, arg2)
+}
+
 // MockLimiter is a mock of Limiter interface.
 type MockLimiter struct {
 	ctrl     *gomock.Controller
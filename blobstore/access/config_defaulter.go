@@ -23,6 +23,9 @@ const (
 	defaultAllocRetryIntervalMS   int = 100
 	defaultEncoderConcurrency     int = 1000
 	defaultMinReadShardsX         int = 1
+	defaultHedgeReadMinDelayMS    int = 5
+	defaultHedgeReadMaxDelayMS    int = 500
+	defaultHedgeReadMaxShards     int = 1
 
 	// client timeout ms
 	defaultTimeoutClusterMgr int64 = 1000 * 3
@@ -97,6 +97,7 @@ var (
 	ErrNoSuchCluster      = errors.New("controller: no such cluster")
 	ErrNoClusterAvailable = errors.New("controller: no cluster available")
 	ErrInvalidChooseAlg   = errors.New("controller: invalid cluster chosen algorithm")
+	ErrBlobDedupDisabled  = errors.New("controller: blob dedup disabled")
 )
 
 // ClusterController controller of clusters in one region
@@ -111,6 +112,14 @@ type ClusterController interface {
 	GetServiceController(clusterID proto.ClusterID) (ServiceController, error)
 	// GetVolumeGetter return VolumeGetter in specified cluster
 	GetVolumeGetter(clusterID proto.ClusterID) (VolumeGetter, error)
+	// GetBlobExpirer return client of blobs expiry in specified cluster
+	GetBlobExpirer(clusterID proto.ClusterID) (cmapi.APIBlobExpire, error)
+	// GetObjectMeta return client of named objects metadata in specified cluster
+	GetObjectMeta(clusterID proto.ClusterID) (cmapi.APIObjectMeta, error)
+	// GetBlobDedup return client of blobs dedup index if it is enabled in specified cluster
+	GetBlobDedup(clusterID proto.ClusterID) (cmapi.APIBlobDedup, error)
+	// GetBlobReplica return client of translation table of replicated locations in specified cluster
+	GetBlobReplica(clusterID proto.ClusterID) (cmapi.APIBlobReplica, error)
 	// GetConfig get specified config of key from cluster manager
 	GetConfig(ctx context.Context, key string) (string, error)
 	// ChangeChooseAlg change alloc algorithm
@@ -145,6 +154,7 @@ type Cluster struct {
 type cluster struct {
 	clusterInfo *cmapi.ClusterInfo
 	client      *cmapi.Client
+	dedup       bool // blob dedup is enabled
 }
 
 type clusterMap map[proto.ClusterID]*cluster
@@ -313,21 +323,21 @@ func (c *clusterControllerImpl) deal(ctx context.Context, available []*cmapi.Clu
 	})
 
 	newClusters := make([]*cmapi.ClusterInfo, 0, len(allClusters))
-	for clusterID := range allClusters {
+	for clusterID, cluster := range allClusters {
+		if cluster.client == nil {
+			conf := c.config.CMClientConfig
+			conf.Hosts = cluster.clusterInfo.Nodes
+			cluster.client = getClusterClient(conf)
+		}
+		cluster.dedup = loadBlobDedup(ctx, cluster.client)
+
 		if _, ok := c.serviceMgrs.Load(clusterID); !ok {
-			newClusters = append(newClusters, allClusters[clusterID].clusterInfo)
+			newClusters = append(newClusters, cluster.clusterInfo)
 		}
 	}
 
 	for _, newCluster := range newClusters {
 		clusterID := newCluster.ClusterID
-
-		if allClusters[clusterID].client == nil {
-			conf := c.config.CMClientConfig
-			conf.Hosts = newCluster.Nodes
-			allClusters[clusterID].client = getClusterClient(conf)
-		}
-
 		cmCli := allClusters[clusterID].client
 
 		removeThisCluster := func() {
@@ -377,6 +387,16 @@ func (c *clusterControllerImpl) deal(ctx context.Context, available []*cmapi.Clu
 	return nil
 }
 
+// loadBlobDedup returns blob dedup is enabled or not in cluster
+func loadBlobDedup(ctx context.Context, cmCli *cmapi.Client) bool {
+	val, err := cmCli.GetConfig(ctx, proto.BlobDedupConfigKey)
+	if err != nil {
+		return false
+	}
+	enable, err := strconv.ParseBool(val)
+	return err == nil && enable
+}
+
 func (c *clusterControllerImpl) Region() string {
 	return c.region
 }
@@ -459,6 +479,42 @@ func (c *clusterControllerImpl) GetVolumeGetter(clusterID proto.ClusterID) (Volu
 	return nil, fmt.Errorf("no volume getter for %d", clusterID)
 }
 
+func (c *clusterControllerImpl) GetBlobExpirer(clusterID proto.ClusterID) (cmapi.APIBlobExpire, error) {
+	allClusters := c.clusters.Load().(clusterMap)
+	if cluster, ok := allClusters[clusterID]; ok {
+		return cluster.client, nil
+	}
+	return nil, ErrNoSuchCluster
+}
+
+func (c *clusterControllerImpl) GetObjectMeta(clusterID proto.ClusterID) (cmapi.APIObjectMeta, error) {
+	allClusters := c.clusters.Load().(clusterMap)
+	if cluster, ok := allClusters[clusterID]; ok {
+		return cluster.client, nil
+	}
+	return nil, ErrNoSuchCluster
+}
+
+func (c *clusterControllerImpl) GetBlobDedup(clusterID proto.ClusterID) (cmapi.APIBlobDedup, error) {
+	allClusters := c.clusters.Load().(clusterMap)
+	cluster, ok := allClusters[clusterID]
+	if !ok {
+		return nil, ErrNoSuchCluster
+	}
+	if !cluster.dedup {
+		return nil, ErrBlobDedupDisabled
+	}
+	return cluster.client, nil
+}
+
+func (c *clusterControllerImpl) GetBlobReplica(clusterID proto.ClusterID) (cmapi.APIBlobReplica, error) {
+	allClusters := c.clusters.Load().(clusterMap)
+	if cluster, ok := allClusters[clusterID]; ok {
+		return cluster.client, nil
+	}
+	return nil, ErrNoSuchCluster
+}
+
 func (c *clusterControllerImpl) GetConfig(ctx context.Context, key string) (ret string, err error) {
 	span := trace.SpanFromContextSafe(ctx)
 
@@ -59,6 +59,7 @@ func initCluster() {
 	mux.HandleFunc("/", consul)
 	mux.HandleFunc("/service/get", serviceGet)
 	mux.HandleFunc("/stat", stat)
+	mux.HandleFunc("/config/get", configGet)
 
 	testServer := httptest.NewServer(mux)
 	hostAddr = testServer.URL
@@ -128,6 +129,14 @@ func consul(w http.ResponseWriter, req *http.Request) {
 	w.Write(data)
 }
 
+func configGet(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Query().Get("key") != proto.BlobDedupConfigKey {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Write([]byte(`"true"`))
+}
+
 func serviceGet(w http.ResponseWriter, req *http.Request) {
 	if val := stableCluster.Load(); val != nil {
 		if b := val.([]byte); b != nil {
@@ -329,6 +338,22 @@ func TestAccessClusterGetHandler(t *testing.T) {
 		_, err = cc1.GetConfig(context.TODO(), "key")
 		require.Error(t, err)
 	}
+	{
+		_, err := cc2.GetBlobDedup(1)
+		require.ErrorIs(t, err, controller.ErrNoSuchCluster)
+
+		dedup, err := cc1.GetBlobDedup(1)
+		require.NoError(t, err)
+		require.NotNil(t, dedup)
+	}
+	{
+		_, err := cc1.GetBlobReplica(100)
+		require.ErrorIs(t, err, controller.ErrNoSuchCluster)
+
+		replica, err := cc1.GetBlobReplica(1)
+		require.NoError(t, err)
+		require.NotNil(t, replica)
+	}
 }
 
 func TestAccessClusterChangeChooseAlg(t *testing.T) {
@@ -112,6 +112,7 @@ func init() {
 			return cmapi.ServiceInfo{}, errNotFound
 		})
 	cli.EXPECT().ListDisk(A, A).AnyTimes().Return(cmapi.ListDiskRet{}, nil)
+	cli.EXPECT().ListTranscodeMapping(A, A).AnyTimes().Return(cmapi.ListTranscodeMappingRet{}, nil)
 	cmcli = cli
 
 	pcli := mocks.NewMockProxyClient(C(&testing.T{}))
@@ -68,6 +68,8 @@ type ServiceController interface {
 	// PunishDiskWithThreshold will punish a disk host for
 	// an punishTimeSec interval if disk host failed times satisfied with threshold
 	PunishDiskWithThreshold(ctx context.Context, diskID proto.DiskID, punishTimeSec int)
+	// GetTranscodeMapping return the transcode mapping if the volume is transcoded
+	GetTranscodeMapping(ctx context.Context, vid proto.Vid) (mapping *clustermgr.TranscodeMapping, ok bool)
 }
 
 type (
@@ -98,6 +100,7 @@ type ServiceConfig struct {
 	IDC                         string
 	ReloadSec                   int
 	LoadDiskInterval            int
+	LoadTranscodeInterval       int
 	ServicePunishThreshold      uint32
 	ServicePunishValidIntervalS int
 }
@@ -107,6 +110,8 @@ type serviceControllerImpl struct {
 	allServices  sync.Map
 	serviceHosts serviceMap
 	brokenDisks  sync.Map
+	// transcode mappings of transcoded volumes
+	transcodeMappings sync.Map
 
 	group        singleflight.Group
 	serviceLocks map[string]*sync.RWMutex
@@ -122,6 +127,7 @@ func NewServiceController(cfg ServiceConfig, cmCli clustermgr.APIAccess, proxy p
 	defaulter.Equal(&cfg.ServicePunishThreshold, defaultServicePinishThreshold)
 	defaulter.LessOrEqual(&cfg.ServicePunishValidIntervalS, defaultServicePinishValidIntervalS)
 	defaulter.LessOrEqual(&cfg.LoadDiskInterval, int(300))
+	defaulter.LessOrEqual(&cfg.LoadTranscodeInterval, int(30))
 	defaulter.LessOrEqual(&cfg.ReloadSec, int(10))
 
 	controller := &serviceControllerImpl{
@@ -171,6 +177,19 @@ func NewServiceController(cfg ServiceConfig, cmCli clustermgr.APIAccess, proxy p
 			}
 		}
 	}()
+	go func() {
+		controller.loadTranscodeMappings()
+		tick := time.NewTicker(time.Duration(cfg.LoadTranscodeInterval) * time.Second)
+		defer tick.Stop()
+		for {
+			select {
+			case <-tick.C:
+				controller.loadTranscodeMappings()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
 	return controller, nil
 }
 
@@ -239,6 +258,38 @@ func (s *serviceControllerImpl) loadBrokenDisks() {
 	}
 }
 
+func (s *serviceControllerImpl) loadTranscodeMappings() {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "access_cluster_load_transcode")
+
+	mappings := make(map[proto.Vid]*clustermgr.TranscodeMapping)
+	args := &clustermgr.ListTranscodeMappingArgs{Count: 1 << 10}
+	for {
+		list, err := s.cmClient.ListTranscodeMapping(ctx, args)
+		if err != nil {
+			span.Errorf("load transcode mappings of cluster %d %s", s.config.ClusterID, err.Error())
+			return
+		}
+		for _, mapping := range list.Mappings {
+			mappings[mapping.Vid] = mapping
+		}
+		if list.Marker == proto.InvalidVid {
+			break
+		}
+		args.Marker = list.Marker
+	}
+
+	s.transcodeMappings.Range(func(key, value interface{}) bool {
+		if _, ok := mappings[key.(proto.Vid)]; !ok {
+			s.transcodeMappings.Delete(key)
+		}
+		return true
+	})
+	for vid, mapping := range mappings {
+		s.transcodeMappings.Store(vid, mapping)
+	}
+	span.Debugf("load transcode mappings of cluster %d count %d", s.config.ClusterID, len(mappings))
+}
+
 // GetServiceHost return an available service host
 func (s *serviceControllerImpl) GetServiceHost(ctx context.Context, name string) (host string, err error) {
 	serviceList, ok := s.serviceHosts[name].Load().(serviceList)
@@ -415,3 +466,12 @@ func (s *serviceControllerImpl) PunishServiceWithThreshold(ctx context.Context,
 func (s *serviceControllerImpl) getServiceLock(name string) *sync.RWMutex {
 	return s.serviceLocks[name]
 }
+
+// GetTranscodeMapping return the transcode mapping if the volume is transcoded
+func (s *serviceControllerImpl) GetTranscodeMapping(ctx context.Context, vid proto.Vid) (*clustermgr.TranscodeMapping, bool) {
+	value, ok := s.transcodeMappings.Load(vid)
+	if !ok {
+		return nil, false
+	}
+	return value.(*clustermgr.TranscodeMapping), true
+}
@@ -219,6 +219,7 @@ func TestAccessServiceGetBrokenDiskHost(t *testing.T) {
 			return cmapi.ServiceInfo{}, errNotFound
 		})
 	cli.EXPECT().ListDisk(A, A).Times(6).Return(brokenRet, nil)
+	cli.EXPECT().ListTranscodeMapping(A, A).AnyTimes().Return(cmapi.ListTranscodeMappingRet{}, nil)
 
 	pcli := mocks.NewMockProxyClient(C(t))
 	pcli.EXPECT().GetCacheDisk(A, A, A).AnyTimes().DoAndReturn(
@@ -270,6 +271,7 @@ func TestAccessServiceGetBrokenDiskHost(t *testing.T) {
 	brokenRet.Disks[0].DiskID = 10000
 	brokenRet.Disks[1].DiskID = 10000
 	cli.EXPECT().ListDisk(A, A).Times(1).Return(brokenRet, errors.New("list error"))
+	cli.EXPECT().ListTranscodeMapping(A, A).AnyTimes().Return(cmapi.ListTranscodeMappingRet{}, nil)
 	time.Sleep(time.Second)
 	{
 		host, err := sc.GetDiskHost(serviceCtx, 10001)
@@ -282,6 +284,7 @@ func TestAccessServiceGetBrokenDiskHost(t *testing.T) {
 
 	brokenRet.Disks = brokenRet.Disks[:0]
 	cli.EXPECT().ListDisk(A, A).Times(2).Return(brokenRet, nil)
+	cli.EXPECT().ListTranscodeMapping(A, A).AnyTimes().Return(cmapi.ListTranscodeMappingRet{}, nil)
 	time.Sleep(time.Second)
 	{
 		host, err := sc.GetDiskHost(serviceCtx, 10001)
@@ -80,6 +80,51 @@ func (mr *MockClusterControllerMockRecorder) ChooseOne() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChooseOne", reflect.TypeOf((*MockClusterController)(nil).ChooseOne))
 }
 
+// GetBlobDedup mocks base method.
+func (m *MockClusterController) GetBlobDedup(arg0 proto.ClusterID) (clustermgr.APIBlobDedup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlobDedup", arg0)
+	ret0, _ := ret[0].(clustermgr.APIBlobDedup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlobDedup indicates an expected call of GetBlobDedup.
+func (mr *MockClusterControllerMockRecorder) GetBlobDedup(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlobDedup", reflect.TypeOf((*MockClusterController)(nil).GetBlobDedup), arg0)
+}
+
+// GetBlobExpirer mocks base method.
+func (m *MockClusterController) GetBlobExpirer(arg0 proto.ClusterID) (clustermgr.APIBlobExpire, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlobExpirer", arg0)
+	ret0, _ := ret[0].(clustermgr.APIBlobExpire)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlobExpirer indicates an expected call of GetBlobExpirer.
+func (mr *MockClusterControllerMockRecorder) GetBlobExpirer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlobExpirer", reflect.TypeOf((*MockClusterController)(nil).GetBlobExpirer), arg0)
+}
+
+// GetBlobReplica mocks base method.
+func (m *MockClusterController) GetBlobReplica(arg0 proto.ClusterID) (clustermgr.APIBlobReplica, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlobReplica", arg0)
+	ret0, _ := ret[0].(clustermgr.APIBlobReplica)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlobReplica indicates an expected call of GetBlobReplica.
+func (mr *MockClusterControllerMockRecorder) GetBlobReplica(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlobReplica", reflect.TypeOf((*MockClusterController)(nil).GetBlobReplica), arg0)
+}
+
 // GetConfig mocks base method.
 func (m *MockClusterController) GetConfig(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +140,21 @@ func (mr *MockClusterControllerMockRecorder) GetConfig(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfig", reflect.TypeOf((*MockClusterController)(nil).GetConfig), arg0, arg1)
 }
 
+// GetObjectMeta mocks base method.
+func (m *MockClusterController) GetObjectMeta(arg0 proto.ClusterID) (clustermgr.APIObjectMeta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetObjectMeta", arg0)
+	ret0, _ := ret[0].(clustermgr.APIObjectMeta)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetObjectMeta indicates an expected call of GetObjectMeta.
+func (mr *MockClusterControllerMockRecorder) GetObjectMeta(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObjectMeta", reflect.TypeOf((*MockClusterController)(nil).GetObjectMeta), arg0)
+}
+
 // GetServiceController mocks base method.
 func (m *MockClusterController) GetServiceController(arg0 proto.ClusterID) (controller.ServiceController, error) {
 	m.ctrl.T.Helper()
@@ -207,6 +267,21 @@ func (mr *MockServiceControllerMockRecorder) GetServiceHosts(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceHosts", reflect.TypeOf((*MockServiceController)(nil).GetServiceHosts), arg0, arg1)
 }
 
+// GetTranscodeMapping mocks base method.
+func (m *MockServiceController) GetTranscodeMapping(arg0 context.Context, arg1 proto.Vid) (*clustermgr.TranscodeMapping, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTranscodeMapping", arg0, arg1)
+	ret0, _ := ret[0].(*clustermgr.TranscodeMapping)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetTranscodeMapping indicates an expected call of GetTranscodeMapping.
+func (mr *MockServiceControllerMockRecorder) GetTranscodeMapping(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTranscodeMapping", reflect.TypeOf((*MockServiceController)(nil).GetTranscodeMapping), arg0, arg1)
+}
+
 // PunishDisk mocks base method.
 func (m *MockServiceController) PunishDisk(arg0 context.Context, arg1 proto.DiskID, arg2 int) {
 	m.ctrl.T.Helper()
@@ -40,9 +40,42 @@ var downloadMetric = prometheus.NewCounterVec(
 	[]string{"cluster", "way", "reason"},
 )
 
+var hedgeReadMetric = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "blobstore",
+		Subsystem: "access",
+		Name:      "hedge_read",
+		Help:      "hedged shard read on access",
+	},
+	[]string{"cluster", "result"},
+)
+
+var blobDedupMetric = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "blobstore",
+		Subsystem: "access",
+		Name:      "blob_dedup",
+		Help:      "blob dedup on access put",
+	},
+	[]string{"cluster", "result"},
+)
+
+var blobDedupBytesMetric = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "blobstore",
+		Subsystem: "access",
+		Name:      "blob_dedup_bytes",
+		Help:      "deduplicated bytes on access put",
+	},
+	[]string{"cluster"},
+)
+
 func init() {
 	prometheus.MustRegister(unhealthMetric)
 	prometheus.MustRegister(downloadMetric)
+	prometheus.MustRegister(hedgeReadMetric)
+	prometheus.MustRegister(blobDedupMetric)
+	prometheus.MustRegister(blobDedupBytesMetric)
 }
 
 func reportUnhealth(cid proto.ClusterID, action, module, host, reason string) {
@@ -52,3 +85,18 @@ func reportUnhealth(cid proto.ClusterID, action, module, host, reason string) {
 func reportDownload(cid proto.ClusterID, way, reason string) {
 	downloadMetric.WithLabelValues(cid.ToString(), way, reason).Inc()
 }
+
+// reportHedgeRead result is "issued" when a hedged shard read is issued,
+// and is "win" when the hedged shard is used to read the blob.
+func reportHedgeRead(cid proto.ClusterID, result string) {
+	hedgeReadMetric.WithLabelValues(cid.ToString(), result).Inc()
+}
+
+// reportBlobDedup result is "hit" when the blob is deduplicated with size bytes,
+// is "miss" when the blob is written, and is "error" when dedup index failed.
+func reportBlobDedup(cid proto.ClusterID, result string, size uint32) {
+	blobDedupMetric.WithLabelValues(cid.ToString(), result).Inc()
+	if size > 0 {
+		blobDedupBytesMetric.WithLabelValues(cid.ToString()).Add(float64(size))
+	}
+}
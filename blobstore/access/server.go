@@ -15,8 +15,10 @@
 package access
 
 import (
+	"context"
 	"crypto/sha1"
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
 	"sync"
@@ -27,6 +29,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/cmd"
 	"github.com/cubefs/cubefs/blobstore/common/consul"
+	"github.com/cubefs/cubefs/blobstore/common/discovery"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/profile"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
@@ -35,6 +38,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/common/trace"
 	"github.com/cubefs/cubefs/blobstore/common/uptoken"
 	"github.com/cubefs/cubefs/blobstore/util/closer"
+	"github.com/cubefs/cubefs/blobstore/util/defaulter"
 	"github.com/cubefs/cubefs/blobstore/util/errors"
 	"github.com/cubefs/cubefs/blobstore/util/log"
 )
@@ -50,6 +54,11 @@ const (
 
 const (
 	_tokenExpiration = time.Hour * 12
+
+	// register service to clustermgr
+	defaultTickInterval   = uint32(1)
+	defaultHeartbeatTicks = uint32(30)
+	defaultExpiresTicks   = uint32(60)
 )
 
 var (
@@ -96,9 +105,24 @@ type accessStatus struct {
 type Config struct {
 	cmd.Config
 
-	ServiceRegister consul.Config `json:"service_register"`
-	Stream          StreamConfig  `json:"stream"`
-	Limit           LimitConfig   `json:"limit"`
+	ServiceRegister ServiceRegisterConfig `json:"service_register"`
+	Stream          StreamConfig          `json:"stream"`
+	Limit           LimitConfig           `json:"limit"`
+}
+
+// ServiceRegisterConfig register access service to consul if consul_addr is setting,
+// otherwise to clustermgr of cluster_id if it is setting.
+// Clustermgr hosts are the hosts of cluster_id in stream clusters config if not setting.
+type ServiceRegisterConfig struct {
+	consul.Config
+
+	ClusterID      proto.ClusterID   `json:"cluster_id"`
+	ClusterMgr     clustermgr.Config `json:"clustermgr"`
+	Host           string            `json:"host"` // default is http://{service_ip}:{port of bind_addr}
+	Idc            string            `json:"idc"`  // default is idc of stream
+	TickInterval   uint32            `json:"tick_interval"`
+	HeartbeatTicks uint32            `json:"heartbeat_ticks"`
+	ExpiresTicks   uint32            `json:"expires_ticks"`
 }
 
 // Service rpc service
@@ -106,6 +130,7 @@ type Service struct {
 	config        Config
 	streamHandler StreamHandler
 	limiter       Limiter
+	registrar     discovery.Registrar
 	closer        closer.Closer
 }
 
@@ -125,18 +150,65 @@ func New(cfg Config) *Service {
 
 // Close close server
 func (s *Service) Close() {
+	if s.registrar != nil {
+		s.registrar.Close()
+	}
 	s.closer.Close()
 }
 
 // RegisterService register service to rpc
 func (s *Service) RegisterService() {
-	if s.config.ServiceRegister.ConsulAddr == "" {
-		return
-	}
-	_, err := consul.ServiceRegister(s.config.BindAddr, &s.config.ServiceRegister)
+	registrar, err := s.register()
 	if err != nil {
 		log.Fatalf("service register failed, err: %v", err)
 	}
+	s.registrar = registrar
+}
+
+func (s *Service) register() (discovery.Registrar, error) {
+	cfg := s.config.ServiceRegister
+	if cfg.ConsulAddr != "" {
+		return consul.ServiceRegister(s.config.BindAddr, &s.config.ServiceRegister.Config)
+	}
+	if cfg.ClusterID == 0 {
+		return nil, nil
+	}
+
+	if len(cfg.ClusterMgr.Hosts) == 0 {
+		cfg.ClusterMgr = s.config.Stream.ClusterConfig.CMClientConfig
+		for _, cluster := range s.config.Stream.ClusterConfig.Clusters {
+			if cluster.ClusterID == cfg.ClusterID {
+				cfg.ClusterMgr.Hosts = cluster.Hosts
+			}
+		}
+		if len(cfg.ClusterMgr.Hosts) == 0 {
+			return nil, fmt.Errorf("no clustermgr hosts of cluster %d", cfg.ClusterID)
+		}
+	}
+	if cfg.Host == "" {
+		_, port, err := net.SplitHostPort(s.config.BindAddr)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.ServiceIP == "" {
+			return nil, errors.New("service_ip or host should be setting")
+		}
+		cfg.Host = "http://" + net.JoinHostPort(cfg.ServiceIP, port)
+	}
+	defaulter.Empty(&cfg.Idc, s.config.Stream.IDC)
+	defaulter.LessOrEqual(&cfg.TickInterval, defaultTickInterval)
+	defaulter.LessOrEqual(&cfg.HeartbeatTicks, defaultHeartbeatTicks)
+	defaulter.LessOrEqual(&cfg.ExpiresTicks, defaultExpiresTicks)
+
+	node := clustermgr.ServiceNode{
+		ClusterID: uint64(cfg.ClusterID),
+		Name:      proto.ServiceNameAccess,
+		Host:      cfg.Host,
+		Idc:       cfg.Idc,
+	}
+	log.Infof("register service %+v to clustermgr %v", node, cfg.ClusterMgr.Hosts)
+	return clustermgr.NewServiceRegistrar(context.Background(), clustermgr.New(&cfg.ClusterMgr), node,
+		cfg.TickInterval, cfg.HeartbeatTicks, cfg.ExpiresTicks)
 }
 
 // RegisterAdminHandler register admin handler to profile
@@ -214,13 +286,13 @@ func (s *Service) Limit(c *rpc.Context) {
 	switch c.Request.URL.Path {
 	case "/alloc":
 		name = limitNameAlloc
-	case "/put":
+	case "/put", "/object/put":
 		name = limitNamePut
 	case "/putat":
 		name = limitNamePutAt
-	case "/get":
+	case "/get", "/object/get":
 		name = limitNameGet
-	case "/delete":
+	case "/delete", "/object/delete":
 		name = limitNameDelete
 	case "/sign":
 		name = limitNameSign
@@ -277,6 +349,18 @@ func (s *Service) Put(c *rpc.Context) {
 		hashSumMap[alg] = hasher.Sum(nil)
 	}
 
+	if args.TTL > 0 {
+		if err := s.streamHandler.Expire(ctx, loc, time.Now().Unix()+args.TTL); err != nil {
+			span.Error("stream put expire failed", errors.Detail(err))
+			// the object can not be left without expiry
+			if errDel := s.streamHandler.Delete(ctx, loc); errDel != nil {
+				span.Warn("stream put clean location failed", errors.Detail(errDel))
+			}
+			c.RespondError(httpError(err))
+			return
+		}
+	}
+
 	if err := fillCrc(loc); err != nil {
 		span.Error("stream put fill location crc", err)
 		c.RespondError(httpError(err))
@@ -400,20 +484,30 @@ func (s *Service) Get(c *rpc.Context) {
 		return
 	}
 
+	if s.transfer(c, args.Location, args.ReadSize, args.Offset) {
+		span.Info("done /get request")
+	}
+}
+
+// transfer responds data of location to client, returns true if all data was transferred
+func (s *Service) transfer(c *rpc.Context, location access.Location, readSize, offset uint64) bool {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
 	w := c.Writer
 	writer := s.limiter.Writer(ctx, w)
-	transfer, err := s.streamHandler.Get(ctx, writer, args.Location, args.ReadSize, args.Offset)
+	transfer, err := s.streamHandler.Get(ctx, writer, location, readSize, offset)
 	if err != nil {
 		span.Error("stream get prepare failed", errors.Detail(err))
 		c.RespondError(httpError(err))
-		return
+		return false
 	}
 
 	w.Header().Set(rpc.HeaderContentType, rpc.MIMEStream)
-	w.Header().Set(rpc.HeaderContentLength, strconv.FormatInt(int64(args.ReadSize), 10))
-	if args.ReadSize > 0 && args.ReadSize != args.Location.Size {
+	w.Header().Set(rpc.HeaderContentLength, strconv.FormatInt(int64(readSize), 10))
+	if readSize > 0 && readSize != location.Size {
 		w.Header().Set(rpc.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d",
-			args.Offset, args.Offset+args.ReadSize-1, args.Location.Size))
+			offset, offset+readSize-1, location.Size))
 		c.RespondStatus(http.StatusPartialContent)
 	} else {
 		c.RespondStatus(http.StatusOK)
@@ -424,11 +518,11 @@ func (s *Service) Get(c *rpc.Context) {
 
 	err = transfer()
 	if err != nil {
-		reportDownload(args.Location.ClusterID, "StatusOKError", "-")
+		reportDownload(location.ClusterID, "StatusOKError", "-")
 		span.Error("stream get transfer failed", errors.Detail(err))
-		return
+		return false
 	}
-	span.Info("done /get request")
+	return true
 }
 
 // Delete  all blobs in this location
@@ -589,6 +683,59 @@ func (s *Service) DeleteBlob(c *rpc.Context) {
 	span.Info("done /deleteblob request")
 }
 
+// Expire set the location expire after ttl seconds
+func (s *Service) Expire(c *rpc.Context) {
+	args := new(access.ExpireArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
+	span.Debugf("accept /expire request args:%+v", args)
+	if !args.IsValid() || !verifyCrc(&args.Location) {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	if err := s.streamHandler.Expire(ctx, &args.Location, time.Now().Unix()+args.TTL); err != nil {
+		span.Error("stream expire failed", errors.Detail(err))
+		c.RespondError(httpError(err))
+		return
+	}
+
+	c.Respond()
+	span.Info("done /expire request")
+}
+
+// ListExpiring list expiring blobs in cluster
+func (s *Service) ListExpiring(c *rpc.Context) {
+	args := new(access.ListExpiringArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
+	span.Debugf("accept /expire/list request args:%+v", args)
+	if !args.IsValid() {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	resp, err := s.streamHandler.ListExpiring(ctx, args)
+	if err != nil {
+		span.Error("stream list expiring failed", errors.Detail(err))
+		c.RespondError(httpError(err))
+		return
+	}
+	c.RespondJSON(resp)
+}
+
 // Sign generate crc with locations
 func (s *Service) Sign(c *rpc.Context) {
 	args := new(access.SignArgs)
@@ -0,0 +1,246 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// setObjectRetryTimes is the times to set object meta again if it is changed by others concurrently
+const setObjectRetryTimes = 3
+
+// PutObject put data as named object, the blobs of old object are deleted if overwritten.
+// The meta is set only if the old object is not changed by others, so the blobs of
+// each overwritten object are deleted exactly once by the put replacing it.
+func (s *Service) PutObject(c *rpc.Context) {
+	args := new(access.PutObjectArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
+	span.Debugf("accept /object/put request args:%+v", args)
+	if !args.IsValid() {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	old, err := s.streamHandler.GetObject(ctx, args.Bucket, args.Key)
+	if err != nil && rpc.DetectStatusCode(err) != http.StatusNotFound {
+		span.Error("stream get old object failed", errors.Detail(err))
+		c.RespondError(httpError(err))
+		return
+	}
+
+	rc := s.limiter.Reader(ctx, c.Request.Body)
+	loc, err := s.streamHandler.Put(ctx, rc, args.Size, nil)
+	if err != nil {
+		span.Error("stream put object failed", errors.Detail(err))
+		c.RespondError(httpError(err))
+		return
+	}
+	if err = fillCrc(loc); err != nil {
+		span.Error("stream put object fill location crc", err)
+		c.RespondError(httpError(err))
+		return
+	}
+
+	obj := &access.ObjectInfo{
+		Bucket:     args.Bucket,
+		Key:        args.Key,
+		Size:       loc.Size,
+		Location:   *loc,
+		Meta:       access.ObjectMetaFromHeader(c.Request.Header),
+		CreateTime: time.Now().Unix(),
+	}
+	for ii := 0; ; ii++ {
+		if err = s.streamHandler.SetObject(ctx, obj, old); err == nil ||
+			rpc.DetectStatusCode(err) != errcode.CodeObjectMetaConflict || ii >= setObjectRetryTimes {
+			break
+		}
+		span.Warnf("stream set object conflicted, retry times %d", ii+1)
+		if old, err = s.streamHandler.GetObject(ctx, args.Bucket, args.Key); err != nil {
+			if rpc.DetectStatusCode(err) != http.StatusNotFound {
+				break
+			}
+			old, err = nil, nil
+		}
+	}
+	if err != nil {
+		span.Error("stream set object failed", errors.Detail(err))
+		if errDel := s.streamHandler.Delete(ctx, loc); errDel != nil {
+			span.Warn("stream put object clean location failed", errors.Detail(errDel))
+		}
+		c.RespondError(httpError(err))
+		return
+	}
+
+	if old != nil {
+		if err = s.streamHandler.Delete(ctx, &old.Location); err != nil {
+			span.Warnf("stream delete overwritten object failed %+v %s", old.Location, errors.Detail(err))
+		}
+	}
+
+	c.RespondJSON(obj)
+	span.Infof("done /object/put request %s/%s location:%+v", obj.Bucket, obj.Key, loc)
+}
+
+// GetObject read data of named object
+func (s *Service) GetObject(c *rpc.Context) {
+	args := new(access.GetObjectArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
+	span.Debugf("accept /object/get request args:%+v", args)
+	if !args.IsValid() {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	obj, err := s.streamHandler.GetObject(ctx, args.Bucket, args.Key)
+	if err != nil {
+		span.Error("stream get object failed", errors.Detail(err))
+		c.RespondError(httpError(err))
+		return
+	}
+
+	getArgs := access.GetArgs{Location: obj.Location, Offset: args.Offset, ReadSize: args.ReadSize}
+	if args.ReadSize == 0 && args.Offset < obj.Location.Size {
+		getArgs.ReadSize = obj.Location.Size - args.Offset
+	}
+	if !getArgs.IsValid() {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	if s.transfer(c, getArgs.Location, getArgs.ReadSize, getArgs.Offset) {
+		span.Info("done /object/get request")
+	}
+}
+
+// StatObject returns location and user metadata of named object
+func (s *Service) StatObject(c *rpc.Context) {
+	args := new(access.ObjectArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
+	span.Debugf("accept /object/stat request args:%+v", args)
+	if !args.IsValid() {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	obj, err := s.streamHandler.GetObject(ctx, args.Bucket, args.Key)
+	if err != nil {
+		span.Error("stream get object failed", errors.Detail(err))
+		c.RespondError(httpError(err))
+		return
+	}
+	c.RespondJSON(obj)
+}
+
+// DeleteObject delete named object, the blobs are deleted before the metadata
+// so that it can be retried if failed
+func (s *Service) DeleteObject(c *rpc.Context) {
+	args := new(access.ObjectArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
+	span.Debugf("accept /object/delete request args:%+v", args)
+	if !args.IsValid() {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	// the meta is deleted only if it is still the object whose blobs are deleted,
+	// the object put by others meanwhile is read and deleted again
+	for ii := 0; ; ii++ {
+		obj, err := s.streamHandler.GetObject(ctx, args.Bucket, args.Key)
+		if err != nil {
+			span.Error("stream get object failed", errors.Detail(err))
+			c.RespondError(httpError(err))
+			return
+		}
+		if err = s.streamHandler.Delete(ctx, &obj.Location); err != nil {
+			span.Error("stream delete object failed", errors.Detail(err))
+			c.RespondError(httpError(err))
+			return
+		}
+		err = s.streamHandler.DeleteObject(ctx, obj)
+		if err == nil {
+			break
+		}
+		if rpc.DetectStatusCode(err) != errcode.CodeObjectMetaConflict || ii >= setObjectRetryTimes {
+			span.Error("stream delete object meta failed", errors.Detail(err))
+			c.RespondError(httpError(err))
+			return
+		}
+		span.Warnf("stream delete object meta conflicted, retry times %d", ii+1)
+	}
+
+	c.Respond()
+	span.Info("done /object/delete request")
+}
+
+// ListObjects list named objects by prefix in bucket
+func (s *Service) ListObjects(c *rpc.Context) {
+	args := new(access.ListObjectsArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
+	span.Debugf("accept /object/list request args:%+v", args)
+	if !args.IsValid() {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	resp, err := s.streamHandler.ListObjects(ctx, args)
+	if err != nil {
+		span.Error("stream list objects failed", errors.Detail(err))
+		c.RespondError(httpError(err))
+		return
+	}
+	c.RespondJSON(resp)
+}
@@ -23,6 +23,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strconv"
 	"sync"
 	"testing"
@@ -30,12 +31,15 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/cubefs/cubefs/blobstore/access/controller"
 	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/common/uptoken"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
 )
 
 var (
@@ -119,6 +123,84 @@ func newService() *Service {
 			return nil
 		})
 
+	s.EXPECT().Expire(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, location *access.Location, expireAt int64) error {
+			if location.Size > 4096 {
+				return errors.New("fake expire error")
+			}
+			return nil
+		})
+	s.EXPECT().ListExpiring(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, args *access.ListExpiringArgs) (*access.ListExpiringResp, error) {
+			if args.ClusterID >= 10 {
+				return nil, errors.New("fake list expiring error")
+			}
+			return &access.ListExpiringResp{Blobs: []access.ExpiringBlob{{
+				Vid: 1, MinBid: 100, Count: 1, Size: 1024, ExpireAt: 100,
+			}}}, nil
+		})
+
+	var objectsMu sync.Mutex
+	objects := make(map[string]access.ObjectInfo)
+	s.EXPECT().SetObject(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, obj, prev *access.ObjectInfo) error {
+			if obj.Bucket == "failed" {
+				return errors.New("fake set object error")
+			}
+			objectsMu.Lock()
+			defer objectsMu.Unlock()
+			name := obj.Bucket + "/" + obj.Key
+			if obj.Bucket == "conflict" && prev == nil {
+				// put by others concurrently
+				objects[name] = access.ObjectInfo{Bucket: obj.Bucket, Key: obj.Key, CreateTime: 1}
+				return errcode.ErrObjectMetaConflict
+			}
+			if cur, ok := objects[name]; ok != (prev != nil) || (ok && !reflect.DeepEqual(cur, *prev)) {
+				return errcode.ErrObjectMetaConflict
+			}
+			objects[name] = *obj
+			return nil
+		})
+	s.EXPECT().GetObject(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, bucket, key string) (*access.ObjectInfo, error) {
+			objectsMu.Lock()
+			defer objectsMu.Unlock()
+			obj, ok := objects[bucket+"/"+key]
+			if !ok {
+				return nil, errcode.ErrNotFound
+			}
+			return &obj, nil
+		})
+	s.EXPECT().DeleteObject(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, obj *access.ObjectInfo) error {
+			objectsMu.Lock()
+			defer objectsMu.Unlock()
+			name := obj.Bucket + "/" + obj.Key
+			cur, ok := objects[name]
+			if obj.Bucket == "conflict" && ok && cur.CreateTime != 1 {
+				// put by others concurrently
+				objects[name] = access.ObjectInfo{Bucket: obj.Bucket, Key: obj.Key, CreateTime: 1}
+				return errcode.ErrObjectMetaConflict
+			}
+			if !ok || !reflect.DeepEqual(cur, *obj) {
+				return errcode.ErrObjectMetaConflict
+			}
+			delete(objects, name)
+			return nil
+		})
+	s.EXPECT().ListObjects(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, args *access.ListObjectsArgs) (*access.ListObjectsResp, error) {
+			objectsMu.Lock()
+			defer objectsMu.Unlock()
+			resp := &access.ListObjectsResp{}
+			for _, obj := range objects {
+				if obj.Bucket == args.Bucket {
+					resp.Objects = append(resp.Objects, obj)
+				}
+			}
+			return resp, nil
+		})
+
 	return &Service{
 		streamHandler: s,
 		limiter: NewLimiter(LimitConfig{
@@ -139,6 +221,53 @@ func TestAccessServiceNew(t *testing.T) {
 	runMockService(newService())
 }
 
+func TestAccessServiceRegister(t *testing.T) {
+	var mu sync.Mutex
+	nodes := make(map[string]clustermgr.ServiceNode)
+	cmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/service/register":
+			args := clustermgr.RegisterArgs{}
+			json.NewDecoder(r.Body).Decode(&args)
+			nodes[args.Host] = args.ServiceNode
+		case "/service/unregister":
+			args := clustermgr.UnregisterArgs{}
+			json.NewDecoder(r.Body).Decode(&args)
+			delete(nodes, args.Host)
+		}
+	}))
+	defer cmServer.Close()
+
+	svr := &Service{config: Config{}}
+	svr.config.BindAddr = ":9500"
+	svr.config.Stream.IDC = "z0"
+	registrar, err := svr.register()
+	require.NoError(t, err)
+	require.Nil(t, registrar)
+
+	svr.config.ServiceRegister.ClusterID = 1
+	_, err = svr.register()
+	require.Error(t, err)
+
+	svr.config.Stream.ClusterConfig.Clusters = []controller.Cluster{
+		{ClusterID: 1, Hosts: []string{cmServer.URL}},
+	}
+	_, err = svr.register()
+	require.Error(t, err)
+
+	svr.config.ServiceRegister.ServiceIP = "127.0.0.1"
+	svr.RegisterService()
+	require.Equal(t, map[string]clustermgr.ServiceNode{
+		"http://127.0.0.1:9500": {ClusterID: 1, Name: proto.ServiceNameAccess, Host: "http://127.0.0.1:9500", Idc: "z0"},
+	}, nodes)
+
+	svr.closer = closer.New()
+	svr.Close()
+	require.Equal(t, 0, len(nodes))
+}
+
 func TestAccessServiceAlloc(t *testing.T) {
 	host := runMockService(newService())
 	cli := newClient()
@@ -281,6 +410,141 @@ func TestAccessServicePut(t *testing.T) {
 	}
 }
 
+func TestAccessServiceExpire(t *testing.T) {
+	host := runMockService(newService())
+	cli := newClient()
+
+	{
+		req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/put?size=%d&ttl=%d", host, 1024, -1),
+			bytes.NewReader(make([]byte, 1024)))
+		err := cli.DoWith(ctx, req, &access.PutResp{}, rpc.WithCrcEncode())
+		assertErrorCode(t, 400, err)
+	}
+	{
+		req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/put?size=%d&ttl=%d", host, 8192, 10),
+			bytes.NewReader(make([]byte, 8192)))
+		err := cli.DoWith(ctx, req, &access.PutResp{}, rpc.WithCrcEncode())
+		assertErrorCode(t, 500, err)
+	}
+	loc := location.Copy()
+	{
+		req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/put?size=%d&ttl=%d", host, 1024, 10),
+			bytes.NewReader(make([]byte, 1024)))
+		resp := &access.PutResp{}
+		err := cli.DoWith(ctx, req, resp, rpc.WithCrcEncode())
+		require.NoError(t, err)
+		loc = resp.Location
+	}
+
+	{
+		err := cli.PostWith(ctx, host+"/expire", nil, access.ExpireArgs{Location: loc, TTL: 0})
+		assertErrorCode(t, 400, err)
+		badCrc := loc.Copy()
+		badCrc.Crc++
+		err = cli.PostWith(ctx, host+"/expire", nil, access.ExpireArgs{Location: badCrc, TTL: 10})
+		assertErrorCode(t, 400, err)
+		err = cli.PostWith(ctx, host+"/expire", nil, access.ExpireArgs{Location: loc, TTL: 10})
+		require.NoError(t, err)
+	}
+
+	{
+		resp := &access.ListExpiringResp{}
+		err := cli.GetWith(ctx, host+"/expire/list?clusterid=0&count=10", resp)
+		assertErrorCode(t, 400, err)
+		err = cli.GetWith(ctx, host+"/expire/list?clusterid=10&count=10", resp)
+		assertErrorCode(t, 500, err)
+		err = cli.GetWith(ctx, host+"/expire/list?clusterid=1&count=10", resp)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(resp.Blobs))
+		require.Equal(t, int64(100), resp.Blobs[0].ExpireAt)
+	}
+}
+
+func TestAccessServiceObject(t *testing.T) {
+	host := runMockService(newService())
+	cli := newClient()
+
+	putObject := func(bucket, key string, size int) (*access.ObjectInfo, error) {
+		req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/object/put?bucket=%s&key=%s&size=%d",
+			host, bucket, key, size), bytes.NewReader(make([]byte, size)))
+		req.Header.Set(access.HeaderObjectMetaPrefix+"Content-Type", "text/plain")
+		obj := &access.ObjectInfo{}
+		err := cli.DoWith(ctx, req, obj, rpc.WithCrcEncode())
+		return obj, err
+	}
+
+	{
+		_, err := putObject("a%2Fb", "key", 1024)
+		assertErrorCode(t, 400, err)
+		_, err = putObject("bucket", "key", 100)
+		assertErrorCode(t, 500, err)
+		_, err = putObject("failed", "key", 1024)
+		assertErrorCode(t, 500, err)
+		obj, err := putObject("conflict", "key", 1024)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1024), obj.Size)
+	}
+	{
+		obj, err := putObject("bucket", "dir%2Fkey", 1024)
+		require.NoError(t, err)
+		require.Equal(t, "dir/key", obj.Key)
+		require.Equal(t, uint64(1024), obj.Size)
+		require.Equal(t, "text/plain", obj.Meta["content-type"])
+		// overwrite
+		obj, err = putObject("bucket", "dir%2Fkey", 2048)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2048), obj.Location.Size)
+	}
+	{
+		obj := &access.ObjectInfo{}
+		err := cli.GetWith(ctx, host+"/object/stat?bucket=bucket&key=dir%2Fkey", obj)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2048), obj.Size)
+		require.True(t, verifyCrc(&obj.Location))
+		err = cli.GetWith(ctx, host+"/object/stat?bucket=bucket&key=not-exist", obj)
+		assertErrorCode(t, 404, err)
+	}
+	{
+		resp, err := cli.Post(ctx, host+"/object/get", access.GetObjectArgs{Bucket: "bucket", Key: "dir/key"})
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "2048", resp.Header.Get(rpc.HeaderContentLength))
+
+		resp, err = cli.Post(ctx, host+"/object/get", access.GetObjectArgs{Bucket: "bucket", Key: "dir/key", Offset: 1024})
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+
+		resp, err = cli.Post(ctx, host+"/object/get", access.GetObjectArgs{Bucket: "bucket", Key: "dir/key", Offset: 4096})
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+	{
+		resp := &access.ListObjectsResp{}
+		err := cli.GetWith(ctx, host+"/object/list?bucket=&count=10", resp)
+		assertErrorCode(t, 400, err)
+		err = cli.GetWith(ctx, host+"/object/list?bucket=bucket&prefix=dir%2F&count=10", resp)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(resp.Objects))
+	}
+	{
+		err := cli.PostWith(ctx, host+"/object/delete", nil, access.ObjectArgs{Bucket: "bucket", Key: "dir/key"})
+		require.NoError(t, err)
+		err = cli.PostWith(ctx, host+"/object/delete", nil, access.ObjectArgs{Bucket: "bucket", Key: "dir/key"})
+		assertErrorCode(t, 404, err)
+	}
+	{
+		// put by others between reading and deleting the meta, the new object is deleted too
+		err := cli.PostWith(ctx, host+"/object/delete", nil, access.ObjectArgs{Bucket: "conflict", Key: "key"})
+		require.NoError(t, err)
+		obj := &access.ObjectInfo{}
+		err = cli.GetWith(ctx, host+"/object/stat?bucket=conflict&key=key", obj)
+		assertErrorCode(t, 404, err)
+	}
+}
+
 func TestAccessServiceGet(t *testing.T) {
 	host := runMockService(newService())
 	cli := newClient()
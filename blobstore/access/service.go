@@ -61,14 +61,18 @@ func NewHandler(service *Service) *rpc.Router {
 	rpc.RegisterArgsParser(&access.PutArgs{}, "json")
 	rpc.RegisterArgsParser(&access.PutAtArgs{}, "json")
 	rpc.RegisterArgsParser(&access.DeleteBlobArgs{}, "json")
+	rpc.RegisterArgsParser(&access.ListExpiringArgs{}, "json")
+	rpc.RegisterArgsParser(&access.PutObjectArgs{}, "json")
+	rpc.RegisterArgsParser(&access.ObjectArgs{}, "json")
+	rpc.RegisterArgsParser(&access.ListObjectsArgs{}, "json")
 
 	rpc.Use(service.Limit)
 
-	// POST /put?size={size}&hashes={hashes}
+	// POST /put?size={size}&hashes={hashes}&ttl={ttl}
 	// request  body:  DataStream
 	// response body:  json
 	rpc.POST("/put", service.Put, rpc.OptArgsQuery())
-	// PUT /put?size={size}&hashes={hashes}
+	// PUT /put?size={size}&hashes={hashes}&ttl={ttl}
 	rpc.PUT("/put", service.Put, rpc.OptArgsQuery())
 
 	// POST /putat?clusterid={clusterid}&volumeid={volumeid}&blobid={blobid}&size={size}&hashes={hashes}&token={token}
@@ -95,6 +99,34 @@ func NewHandler(service *Service) *rpc.Router {
 	// DELETE /deleteblob
 	rpc.DELETE("/deleteblob", service.DeleteBlob, rpc.OptArgsQuery())
 
+	// POST /expire
+	// request  body:  json
+	rpc.POST("/expire", service.Expire, rpc.OptArgsBody())
+	// GET /expire/list?clusterid={clusterid}&expire_before={expire_before}&marker={marker}&count={count}
+	// response body:  json
+	rpc.GET("/expire/list", service.ListExpiring, rpc.OptArgsQuery())
+
+	// POST /object/put?bucket={bucket}&key={key}&size={size}
+	// request  header: X-Object-Meta-{name}: {value}
+	// request  body:   DataStream
+	// response body:   json
+	rpc.POST("/object/put", service.PutObject, rpc.OptArgsQuery())
+	// PUT /object/put?bucket={bucket}&key={key}&size={size}
+	rpc.PUT("/object/put", service.PutObject, rpc.OptArgsQuery())
+	// POST /object/get
+	// request  body:  json
+	// response body:  DataStream
+	rpc.POST("/object/get", service.GetObject, rpc.OptArgsBody())
+	// GET /object/stat?bucket={bucket}&key={key}
+	// response body:  json
+	rpc.GET("/object/stat", service.StatObject, rpc.OptArgsQuery())
+	// POST /object/delete
+	// request  body:  json
+	rpc.POST("/object/delete", service.DeleteObject, rpc.OptArgsBody())
+	// GET /object/list?bucket={bucket}&prefix={prefix}&marker={marker}&count={count}
+	// response body:  json
+	rpc.GET("/object/list", service.ListObjects, rpc.OptArgsQuery())
+
 	// POST /sign
 	// request  body:  json
 	// response body:  json
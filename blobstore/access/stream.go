@@ -98,6 +98,22 @@ type StreamHandler interface {
 	// Delete delete all blobs in this location
 	Delete(ctx context.Context, location *access.Location) error
 
+	// Expire set all blobs in this location expire at unix time expireAt
+	Expire(ctx context.Context, location *access.Location, expireAt int64) error
+
+	// ListExpiring list expiring blobs in cluster
+	ListExpiring(ctx context.Context, args *access.ListExpiringArgs) (*access.ListExpiringResp, error)
+
+	// SetObject set or overwrite the metadata of named object only if it is still prev
+	SetObject(ctx context.Context, obj, prev *access.ObjectInfo) error
+	// GetObject get the metadata of named object
+	GetObject(ctx context.Context, bucket, key string) (*access.ObjectInfo, error)
+	// DeleteObject delete the metadata of named object only if it is still obj,
+	// blobs of the object are not deleted
+	DeleteObject(ctx context.Context, obj *access.ObjectInfo) error
+	// ListObjects list the metadata of named objects in bucket
+	ListObjects(ctx context.Context, args *access.ListObjectsArgs) (*access.ListObjectsResp, error)
+
 	// Admin returns internal admin interface.
 	Admin() interface{}
 }
@@ -123,6 +139,27 @@ type StreamConfig struct {
 	MinReadShardsX             int    `json:"min_read_shards_x"`
 	ShardCrcDisabled           bool   `json:"shard_crc_disabled"`
 
+	// HedgeReadPercentile read one more shard if a shard is not returned after
+	// this percentile of latency on its disk, hedged read is disabled if it is zero.
+	// the delay is limited in [HedgeReadMinDelayMS, HedgeReadMaxDelayMS],
+	// the max delay is used if the disk has no enough latency samples.
+	HedgeReadPercentile float64 `json:"hedge_read_percentile"`
+	HedgeReadMinDelayMS int     `json:"hedge_read_min_delay_ms"`
+	HedgeReadMaxDelayMS int     `json:"hedge_read_max_delay_ms"`
+	// HedgeReadMaxShards max hedged shards of one blob
+	HedgeReadMaxShards int `json:"hedge_read_max_shards"`
+
+	// ReplicateEnabled sends change log of put and deleted locations to proxy,
+	// the locations are replicated to another cluster by scheduler asynchronously
+	ReplicateEnabled bool `json:"replicate_enabled"`
+	// ReplicaClusterID locations are read from their replicas in this cluster
+	// if the primary cluster is unavailable, failover read is disabled if it is zero
+	ReplicaClusterID proto.ClusterID `json:"replica_cluster_id"`
+
+	// ObjectMetaClusterID metadata of named objects are stored in this cluster,
+	// named objects are disabled if it is zero
+	ObjectMetaClusterID proto.ClusterID `json:"object_meta_cluster_id"`
+
 	MemPoolSizeClasses map[int]int `json:"mem_pool_size_classes"`
 
 	// CodeModesPutQuorums
@@ -160,6 +197,7 @@ type Handler struct {
 	memPool           *resourcepool.MemPool
 	encoder           map[codemode.CodeMode]ec.Encoder
 	clusterController controller.ClusterController
+	latency           *latencyTracker
 
 	blobnodeClient blobnode.StorageAPI
 	proxyClient    proxy.Client
@@ -203,6 +241,15 @@ func confCheck(cfg *StreamConfig) {
 	}
 	defaulter.LessOrEqual(&cfg.EncoderConcurrency, defaultEncoderConcurrency)
 	defaulter.LessOrEqual(&cfg.MinReadShardsX, defaultMinReadShardsX)
+	if cfg.HedgeReadPercentile < 0 || cfg.HedgeReadPercentile > 1 {
+		log.Fatalf("invalid hedge read percentile(%f), should be in [0, 1]", cfg.HedgeReadPercentile)
+	}
+	defaulter.LessOrEqual(&cfg.HedgeReadMinDelayMS, defaultHedgeReadMinDelayMS)
+	defaulter.LessOrEqual(&cfg.HedgeReadMaxDelayMS, defaultHedgeReadMaxDelayMS)
+	if cfg.HedgeReadMaxDelayMS < cfg.HedgeReadMinDelayMS {
+		cfg.HedgeReadMaxDelayMS = cfg.HedgeReadMinDelayMS
+	}
+	defaulter.LessOrEqual(&cfg.HedgeReadMaxShards, defaultHedgeReadMaxShards)
 
 	defaulter.LessOrEqual(&cfg.ClusterConfig.CMClientConfig.Config.ClientTimeoutMs, defaultTimeoutClusterMgr)
 	defaulter.LessOrEqual(&cfg.BlobnodeConfig.ClientTimeoutMs, defaultTimeoutBlobnode)
@@ -237,6 +284,7 @@ func NewStreamHandler(cfg *StreamConfig, stopCh <-chan struct{}) StreamHandler {
 	handler := &Handler{
 		memPool:           resourcepool.NewMemPool(cfg.MemPoolSizeClasses),
 		clusterController: clusterController,
+		latency:           newLatencyTracker(),
 
 		blobnodeClient: blobnode.New(&cfg.BlobnodeConfig),
 		proxyClient:    proxyClient,
@@ -300,7 +348,14 @@ func NewStreamHandler(cfg *StreamConfig, stopCh <-chan struct{}) StreamHandler {
 func (h *Handler) Delete(ctx context.Context, location *access.Location) error {
 	span := trace.SpanFromContextSafe(ctx)
 	span.Debugf("to delete %+v", location)
-	return h.clearGarbage(ctx, location)
+	if err := h.clearGarbage(ctx, location); err != nil {
+		return err
+	}
+	if h.ReplicateEnabled {
+		// replicas are leaked if the change log of delete is lost, so return the error to retry
+		return h.sendReplicateMsg(ctx, proto.ReplicateOpDelete, location)
+	}
+	return nil
 }
 
 // Admin returns internal admin interface.
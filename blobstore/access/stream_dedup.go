@@ -0,0 +1,132 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// blobDedupKey returns content addressed key of blob data,
+// blobs are deduplicated only in the same code mode and size.
+func blobDedupKey(codeMode codemode.CodeMode, data []byte) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%d-%d-%s", codeMode, len(data), hex.EncodeToString(sum[:]))
+}
+
+// blobsToSlices merges continuous blobs into slices of location
+func blobsToSlices(blobs []access.Blob) []access.SliceInfo {
+	slices := make([]access.SliceInfo, 0, 1)
+	for _, blob := range blobs {
+		if n := len(slices); n > 0 {
+			last := &slices[n-1]
+			if last.Vid == blob.Vid && last.MinBid+proto.BlobID(last.Count) == blob.Bid {
+				last.Count++
+				continue
+			}
+		}
+		slices = append(slices, access.SliceInfo{MinBid: blob.Bid, Vid: blob.Vid, Count: 1})
+	}
+	return slices
+}
+
+// blobDedupPut deduplicates blobs of one put request,
+// located holds blobs of the location in order, and
+// acquired holds blobs referenced by this put.
+type blobDedupPut struct {
+	dedup     cmapi.APIBlobDedup
+	clusterID proto.ClusterID
+	codeMode  codemode.CodeMode
+	blobSize  uint32
+
+	located  []access.Blob
+	acquired []access.Blob
+}
+
+// acquire references the blob with the same data,
+// remain is the allocated blobs has not been put.
+func (d *blobDedupPut) acquire(ctx context.Context, h *Handler, key string, remain []access.Blob) bool {
+	span := trace.SpanFromContextSafe(ctx)
+	ret, err := d.dedup.AcquireBlobDedup(ctx, &cmapi.AcquireBlobDedupArgs{Hash: key})
+	if err != nil {
+		if rpc.DetectStatusCode(err) == http.StatusNotFound {
+			reportBlobDedup(d.clusterID, "miss", 0)
+			return false
+		}
+		span.Warnf("acquire blob dedup %s failed, %s", key, errors.Detail(err))
+		reportBlobDedup(d.clusterID, "error", 0)
+		return false
+	}
+
+	size := remain[0].Size
+	blob := access.Blob{Vid: ret.Vid, Bid: ret.Bid, Size: size}
+	blobs := make([]access.Blob, 0, len(d.located)+len(remain))
+	blobs = append(blobs, d.located...)
+	blobs = append(blobs, blob)
+	blobs = append(blobs, remain[1:]...)
+	if len(blobsToSlices(blobs)) > access.MaxLocationBlobs {
+		span.Debugf("dedup blob %+v exceed max location blobs", blob)
+		// the reference is released by deleting
+		if err = h.clearGarbage(ctx, d.location([]access.Blob{blob})); err != nil {
+			span.Warn(errors.Detail(err))
+		}
+		reportBlobDedup(d.clusterID, "miss", 0)
+		return false
+	}
+
+	span.Debugf("dedup blob %s to %+v", key, ret)
+	d.located = append(d.located, blob)
+	d.acquired = append(d.acquired, blob)
+	reportBlobDedup(d.clusterID, "hit", size)
+	return true
+}
+
+// register the written blob, it can be referenced by others
+func (d *blobDedupPut) register(ctx context.Context, key string, blob access.Blob) {
+	d.located = append(d.located, blob)
+	if _, err := d.dedup.RegisterBlobDedup(ctx, &cmapi.RegisterBlobDedupArgs{
+		Hash: key,
+		Vid:  blob.Vid,
+		Bid:  blob.Bid,
+	}); err != nil {
+		span := trace.SpanFromContextSafe(ctx)
+		span.Warnf("register blob dedup %s %+v failed, %s", key, blob, errors.Detail(err))
+	}
+}
+
+func (d *blobDedupPut) location(blobs []access.Blob) *access.Location {
+	size := uint64(0)
+	for _, blob := range blobs {
+		size += uint64(blob.Size)
+	}
+	return &access.Location{
+		ClusterID: d.clusterID,
+		CodeMode:  d.codeMode,
+		Size:      size,
+		BlobSize:  d.blobSize,
+		Blobs:     blobsToSlices(blobs),
+	}
+}
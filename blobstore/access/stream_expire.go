@@ -0,0 +1,90 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"context"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// Expire set all blobs in this location expire at unix time expireAt,
+// the expired blobs are deleted by scheduler
+func (h *Handler) Expire(ctx context.Context, location *access.Location, expireAt int64) error {
+	span := trace.SpanFromContextSafe(ctx)
+	span.Debugf("to expire at %d %+v", expireAt, location)
+
+	if location.BlobSize == 0 || len(location.Blobs) == 0 {
+		return errcode.ErrIllegalArguments
+	}
+	expirer, err := h.clusterController.GetBlobExpirer(location.ClusterID)
+	if err != nil {
+		return errors.Info(err, "get blob expirer").Detail(err)
+	}
+
+	blobs := make([]cmapi.BlobExpire, 0, len(location.Blobs))
+	remainSize := location.Size
+	for _, blob := range location.Blobs {
+		size := uint64(blob.Count) * uint64(location.BlobSize)
+		if size > remainSize {
+			size = remainSize
+		}
+		remainSize -= size
+		blobs = append(blobs, cmapi.BlobExpire{
+			Vid:      blob.Vid,
+			MinBid:   blob.MinBid,
+			Count:    blob.Count,
+			Size:     size,
+			ExpireAt: expireAt,
+		})
+	}
+	if err = expirer.SetBlobExpire(ctx, &cmapi.SetBlobExpireArgs{Blobs: blobs}); err != nil {
+		span.Error("set blob expire failed", errors.Detail(err))
+		return err
+	}
+	return nil
+}
+
+// ListExpiring list expiring blobs in cluster
+func (h *Handler) ListExpiring(ctx context.Context, args *access.ListExpiringArgs) (*access.ListExpiringResp, error) {
+	expirer, err := h.clusterController.GetBlobExpirer(args.ClusterID)
+	if err != nil {
+		return nil, errors.Info(err, "get blob expirer").Detail(err)
+	}
+	ret, err := expirer.ListBlobExpire(ctx, &cmapi.ListBlobExpireArgs{
+		ExpireBefore: args.ExpireBefore,
+		Marker:       args.Marker,
+		Count:        args.Count,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &access.ListExpiringResp{Blobs: make([]access.ExpiringBlob, 0, len(ret.Blobs)), Marker: ret.Marker}
+	for _, blob := range ret.Blobs {
+		resp.Blobs = append(resp.Blobs, access.ExpiringBlob{
+			Vid:      blob.Vid,
+			MinBid:   blob.MinBid,
+			Count:    blob.Count,
+			Size:     blob.Size,
+			ExpireAt: blob.ExpireAt,
+		})
+	}
+	return resp, nil
+}
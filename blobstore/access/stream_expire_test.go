@@ -0,0 +1,83 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/access/controller"
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func TestAccessStreamExpire(t *testing.T) {
+	ctr := gomock.NewController(t)
+	var expired []clustermgr.BlobExpire
+	cmcli := mocks.NewMockClientAPI(ctr)
+	cmcli.EXPECT().SetBlobExpire(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, args *clustermgr.SetBlobExpireArgs) error {
+			expired = append(expired, args.Blobs...)
+			return nil
+		})
+	cmcli.EXPECT().ListBlobExpire(gomock.Any(), gomock.Any()).Return(clustermgr.ListBlobExpireRet{
+		Blobs:  []clustermgr.BlobExpire{{Vid: 1, MinBid: 100, Count: 2, Size: 2048, ExpireAt: 100}},
+		Marker: "marker",
+	}, nil)
+
+	cc := NewMockClusterController(ctr)
+	cc.EXPECT().GetBlobExpirer(gomock.Any()).AnyTimes().DoAndReturn(
+		func(clusterID proto.ClusterID) (clustermgr.APIBlobExpire, error) {
+			if clusterID != 1 {
+				return nil, controller.ErrNoSuchCluster
+			}
+			return cmcli, nil
+		})
+	h := &Handler{clusterController: cc}
+	ctx := context.Background()
+
+	loc := &access.Location{
+		ClusterID: 1,
+		Size:      1024*3 + 100,
+		BlobSize:  1024,
+		Blobs: []access.SliceInfo{
+			{MinBid: 100, Vid: 1, Count: 2},
+			{MinBid: 200, Vid: 2, Count: 2},
+		},
+	}
+	require.NoError(t, h.Expire(ctx, loc, 1000))
+	require.Equal(t, 2, len(expired))
+	require.Equal(t, uint64(2048), expired[0].Size)
+	require.Equal(t, uint64(1124), expired[1].Size)
+	require.Equal(t, int64(1000), expired[1].ExpireAt)
+
+	loc.ClusterID = 2
+	require.Error(t, h.Expire(ctx, loc, 1000))
+	loc.BlobSize = 0
+	require.Error(t, h.Expire(ctx, loc, 1000))
+
+	_, err := h.ListExpiring(ctx, &access.ListExpiringArgs{ClusterID: 2, Count: 10})
+	require.Error(t, err)
+	resp, err := h.ListExpiring(ctx, &access.ListExpiringArgs{ClusterID: 1, Count: 10})
+	require.NoError(t, err)
+	require.Equal(t, "marker", resp.Marker)
+	require.Equal(t, 1, len(resp.Blobs))
+	require.Equal(t, uint64(2048), resp.Blobs[0].Size)
+}
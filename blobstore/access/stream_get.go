@@ -65,6 +65,7 @@ func (blob *blobGetArgs) ID() string {
 type shardData struct {
 	index  int
 	status bool
+	hedged bool
 	buffer []byte
 }
 
@@ -111,6 +112,14 @@ type pipeBuffer struct {
 // read-9 [d4                                       p5]
 // failed
 func (h *Handler) Get(ctx context.Context, w io.Writer, location access.Location, readSize, offset uint64) (func() error, error) {
+	if h.ReplicaClusterID == 0 || location.ClusterID == h.ReplicaClusterID {
+		return h.getLocation(ctx, w, location, readSize, offset)
+	}
+	return h.getWithReplica(ctx, w, location, readSize, offset)
+}
+
+// getLocation reads the location in its own cluster
+func (h *Handler) getLocation(ctx context.Context, w io.Writer, location access.Location, readSize, offset uint64) (func() error, error) {
 	span := trace.SpanFromContextSafe(ctx)
 	span.Debugf("get request cluster:%d size:%d offset:%d", location.ClusterID, readSize, offset)
 
@@ -136,6 +145,7 @@ func (h *Handler) Get(ctx context.Context, w io.Writer, location access.Location
 		span.Error("get service", errors.Detail(err))
 		return func() error { return nil }, err
 	}
+	redirectTranscodedBlobs(ctx, serviceController, blobs)
 
 	return func() error {
 		getTime := new(timeReadWrite)
@@ -179,9 +189,9 @@ func (h *Handler) Get(ctx context.Context, w io.Writer, location access.Location
 
 				var blobVolume *controller.VolumePhy
 				var sortedVuids []sortedVuid
-				tactic := location.CodeMode.Tactic()
 				for _, blob := range blobs {
 					var err error
+					tactic := blob.CodeMode.Tactic()
 					if blobVolume == nil || blobVolume.Vid != blob.Vid {
 						blobVolume, err = h.getVolume(ctx, clusterID, blob.Vid, true)
 						if err != nil {
@@ -191,7 +201,7 @@ func (h *Handler) Get(ctx context.Context, w io.Writer, location access.Location
 						}
 
 						// do not use local shards
-						sortedVuids = genSortedVuidByIDC(ctx, serviceController, h.IDC, blobVolume.Units[:tactic.N+tactic.M])
+						sortedVuids = genSortedVuidByIDC(ctx, serviceController, h.IDC, blobVolume.Units[:tactic.N+tactic.M], h.latency)
 						span.Debugf("to read %s with read-shard-x:%d active-shard-n:%d of data-n:%d party-n:%d",
 							blob.ID(), h.MinReadShardsX, len(sortedVuids), tactic.N, tactic.M)
 						if len(sortedVuids) < tactic.N {
@@ -320,6 +330,8 @@ func (h *Handler) readOneBlob(ctx context.Context, getTime *timeReadWrite,
 
 	stopChan := make(chan struct{})
 	nextChan := make(chan struct{}, len(sortedVuids))
+	// hedgeChan is notified if a shard is not returned after the hedge delay of its disk
+	hedgeChan := make(chan struct{}, 1)
 	shardPipe := func() <-chan shardData {
 		ch := make(chan shardData)
 		go func() {
@@ -329,32 +341,54 @@ func (h *Handler) readOneBlob(ctx context.Context, getTime *timeReadWrite,
 				close(ch)
 			}()
 
+			readShard := func(vuid sortedVuid, hedged bool) {
+				wg.Add(1)
+				go func() {
+					if delay := h.hedgeDelay(vuid.diskID); delay > 0 {
+						timer := time.AfterFunc(delay, func() {
+							select {
+							case hedgeChan <- struct{}{}:
+							default:
+							}
+						})
+						defer timer.Stop()
+					}
+					shard := h.readOneShard(ctx, serviceController, blob, vuid, stopChan)
+					shard.hedged = hedged
+					ch <- shard
+					wg.Done()
+				}()
+			}
+
 			for _, vuid := range sortedVuids[:minShardsRead] {
 				if _, ok := empties[vuid.index]; !ok {
-					wg.Add(1)
-					go func(vuid sortedVuid) {
-						ch <- h.readOneShard(ctx, serviceController, blob, vuid, stopChan)
-						wg.Done()
-					}(vuid)
+					readShard(vuid, false)
 				}
 			}
 
+			hedgedN := 0
 			for _, vuid := range sortedVuids[minShardsRead:] {
 				if _, ok := empties[vuid.index]; ok {
 					continue
 				}
 
+				var hedgeCh <-chan struct{}
+				if hedgedN < h.HedgeReadMaxShards {
+					hedgeCh = hedgeChan
+				}
+
+				hedged := false
 				select {
 				case <-stopChan:
 					return
 				case <-nextChan:
+				case <-hedgeCh:
+					hedged = true
+					hedgedN++
+					reportHedgeRead(blob.Cid, "issued")
+					span.Debugf("%s hedged read on %s", blob.ID(), vuid.ID())
 				}
-
-				wg.Add(1)
-				go func(vuid sortedVuid) {
-					ch <- h.readOneShard(ctx, serviceController, blob, vuid, stopChan)
-					wg.Done()
-				}(vuid)
+				readShard(vuid, hedged)
 			}
 		}()
 
@@ -369,12 +403,16 @@ func (h *Handler) readOneBlob(ctx context.Context, getTime *timeReadWrite,
 
 	startRead := time.Now()
 	reconstructed := false
+	hedgedWin := false
 	for shard := range shardPipe {
 		// swap shard buffer
 		if shard.status {
 			buf := shards[shard.index]
 			shards[shard.index] = shard.buffer
 			h.memPool.Put(buf)
+			if shard.hedged {
+				hedgedWin = true
+			}
 		}
 
 		received[shard.index] = shard.status
@@ -456,11 +494,32 @@ func (h *Handler) readOneBlob(ctx context.Context, getTime *timeReadWrite,
 	}()
 
 	if reconstructed {
+		if hedgedWin {
+			reportHedgeRead(blob.Cid, "win")
+		}
 		return nil
 	}
 	return fmt.Errorf("broken %s", blob.ID())
 }
 
+// hedgeDelay returns the delay of hedged read on the disk, zero means no hedged read
+func (h *Handler) hedgeDelay(diskID proto.DiskID) time.Duration {
+	if h.HedgeReadPercentile <= 0 || h.HedgeReadMaxShards <= 0 {
+		return 0
+	}
+	minDelay := time.Duration(h.HedgeReadMinDelayMS) * time.Millisecond
+	maxDelay := time.Duration(h.HedgeReadMaxDelayMS) * time.Millisecond
+
+	delay := h.latency.Percentile(diskID, h.HedgeReadPercentile)
+	if delay <= 0 || delay > maxDelay {
+		return maxDelay
+	}
+	if delay < minDelay {
+		return minDelay
+	}
+	return delay
+}
+
 func (h *Handler) readOneShard(ctx context.Context, serviceController controller.ServiceController,
 	blob blobGetArgs, vuid sortedVuid, stopChan <-chan struct{}) shardData {
 	clusterID, vid := blob.Cid, blob.Vid
@@ -485,6 +544,7 @@ func (h *Handler) readOneShard(ctx context.Context, serviceController controller
 		err  error
 		body io.ReadCloser
 	)
+	startTime := time.Now()
 	if hErr := hystrix.Do(rwCommand, func() error {
 		body, err = h.getOneShardFromHost(ctx, serviceController, vuid.host, vuid.diskID, args,
 			vuid.index, clusterID, vid, 3, stopChan)
@@ -494,6 +554,7 @@ func (h *Handler) readOneShard(ctx context.Context, serviceController controller
 		return nil
 	}, nil); hErr != nil {
 		span.Warnf("hystrix: read %s on %s: %s", blob.ID(), vuid.ID(), hErr.Error())
+		h.latency.ObserveFailure(vuid.diskID, time.Since(startTime))
 		return shardResult
 	}
 
@@ -503,6 +564,7 @@ func (h *Handler) readOneShard(ctx context.Context, serviceController controller
 			return shardResult
 		}
 		span.Warnf("rpc read %s on %s: %s", blob.ID(), vuid.ID(), errors.Detail(err))
+		h.latency.ObserveFailure(vuid.diskID, time.Since(startTime))
 		return shardResult
 	}
 	defer body.Close()
@@ -517,8 +579,10 @@ func (h *Handler) readOneShard(ctx context.Context, serviceController controller
 	if err != nil {
 		h.memPool.Put(buf)
 		span.Warnf("io read %s on %s: %s", blob.ID(), vuid.ID(), err.Error())
+		h.latency.ObserveFailure(vuid.diskID, time.Since(startTime))
 		return shardResult
 	}
+	h.latency.Observe(vuid.diskID, time.Since(startTime))
 
 	shardResult.status = true
 	shardResult.buffer = buf
@@ -771,8 +835,36 @@ func genLocationBlobs(location *access.Location, readSize uint64, offset uint64)
 	return blobs, nil
 }
 
+// redirectTranscodedBlobs reads blobs of transcoded volumes from the destination volumes,
+// the data of blob in destination volume is padded to the ec data size of source code mode.
+func redirectTranscodedBlobs(ctx context.Context, serviceController controller.ServiceController, blobs []blobGetArgs) {
+	span := trace.SpanFromContextSafe(ctx)
+	for idx := range blobs {
+		blob := &blobs[idx]
+		for {
+			mapping, ok := serviceController.GetTranscodeMapping(ctx, blob.Vid)
+			if !ok || !mapping.Done() || mapping.CodeMode != blob.CodeMode {
+				break
+			}
+			sizes, err := ec.GetBufferSizes(int(blob.BlobSize), blob.CodeMode.Tactic())
+			if err != nil {
+				span.Warnf("redirect transcoded %s %s", blob.ID(), err.Error())
+				break
+			}
+			span.Debugf("redirect transcoded %s to vid:%d", blob.ID(), mapping.DstVid)
+
+			blob.Vid = mapping.DstVid
+			blob.CodeMode = mapping.DstCodeMode
+			blob.BlobSize = uint64(sizes.ECDataSize)
+			sizes, _ = ec.GetBufferSizes(int(blob.BlobSize), blob.CodeMode.Tactic())
+			blob.ShardSize = sizes.ShardSize
+			blob.ShardOffset, blob.ShardReadSize = shardSegment(blob.ShardSize, int(blob.Offset), int(blob.ReadSize))
+		}
+	}
+}
+
 func genSortedVuidByIDC(ctx context.Context, serviceController controller.ServiceController, idc string,
-	vuidPhys []controller.Unit) []sortedVuid {
+	vuidPhys []controller.Unit, latency *latencyTracker) []sortedVuid {
 	span := trace.SpanFromContextSafe(ctx)
 
 	vuids := make([]sortedVuid, 0, len(vuidPhys))
@@ -815,6 +907,17 @@ func genSortedVuidByIDC(ctx context.Context, serviceController controller.Servic
 		rand.Shuffle(len(ids), func(i, j int) {
 			ids[i], ids[j] = ids[j], ids[i]
 		})
+		// faster disks first, disks of similar latency stay shuffled
+		if latency != nil {
+			diskIDs := make([]proto.DiskID, 0, len(ids))
+			for _, id := range ids {
+				diskIDs = append(diskIDs, id.diskID)
+			}
+			buckets := latency.Buckets(diskIDs)
+			sort.SliceStable(ids, func(i, j int) bool {
+				return buckets[ids[i].diskID] < buckets[ids[j].diskID]
+			})
+		}
 		vuids = append(vuids, ids...)
 		if dis > 1 {
 			span.Debugf("distance: %d punished vuids: %+v", dis, ids)
@@ -16,16 +16,20 @@ package access
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"math"
 	mrand "math/rand"
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/ec"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 )
 
@@ -249,6 +253,53 @@ func TestAccessStreamGetShardTimeout(t *testing.T) {
 	}
 }
 
+func TestAccessStreamGetHedged(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamGetHedged")
+	dataShards.clean()
+	vuidController.Unbreak(1005)
+	streamer.MinReadShardsX = 0
+	defer func() {
+		vuidController.Break(1005)
+		streamer.MinReadShardsX = minReadShardsX
+		dataShards.clean()
+	}()
+
+	size := 1 << 22
+	buff := make([]byte, size)
+	rand.Read(buff)
+	loc, err := streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil)
+	require.NoError(t, err)
+
+	vuidController.Block(1001)
+	defer func() {
+		vuidController.Unblock(1001)
+	}()
+
+	// wait the blocked shard if no hedged read
+	{
+		startTime := time.Now()
+		transfer, _ := streamer.Get(ctx(), bytes.NewBuffer(nil), *loc, uint64(size), 0)
+		require.NoError(t, transfer())
+		require.LessOrEqual(t, vuidController.duration, time.Since(startTime))
+	}
+
+	streamer.HedgeReadPercentile = 0.9
+	streamer.HedgeReadMinDelayMS = 10
+	streamer.HedgeReadMaxDelayMS = 50
+	streamer.HedgeReadMaxShards = 1
+	defer func() {
+		streamer.HedgeReadPercentile = 0
+	}()
+	{
+		startTime := time.Now()
+		transfer, _ := streamer.Get(ctx(), bytes.NewBuffer(nil), *loc, uint64(size), 0)
+		require.NoError(t, transfer())
+		duration := time.Since(startTime)
+		t.Log(duration, vuidController.duration)
+		require.Greater(t, vuidController.duration, duration)
+	}
+}
+
 func TestAccessStreamGetShardBroken(t *testing.T) {
 	ctx := ctxWithName("TestAccessStreamGetShardBroken")
 	dataShards.clean()
@@ -537,6 +588,82 @@ func TestAccessStreamGenLocationBlobs(t *testing.T) {
 	}
 }
 
+func TestAccessStreamRedirectTranscodedBlobs(t *testing.T) {
+	srcVid, dstVid, copyingVid := proto.Vid(1001), proto.Vid(3001), proto.Vid(2001)
+	srcMode, dstMode := codemode.EC6P6, codemode.EC3P3
+	sc := NewMockServiceController(gomock.NewController(t))
+	sc.EXPECT().GetTranscodeMapping(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, vid proto.Vid) (*clustermgr.TranscodeMapping, bool) {
+			switch vid {
+			case srcVid:
+				return &clustermgr.TranscodeMapping{
+					Vid: srcVid, CodeMode: srcMode, DstVid: dstVid, DstCodeMode: dstMode,
+					Status: clustermgr.TranscodeMappingStatusDone,
+				}, true
+			case copyingVid:
+				return &clustermgr.TranscodeMapping{
+					Vid: copyingVid, CodeMode: srcMode, DstVid: dstVid + 1, DstCodeMode: dstMode,
+					Status: clustermgr.TranscodeMappingStatusCopying,
+				}, true
+			}
+			return nil, false
+		})
+
+	blobSize := 1 << 16
+	data := make([]byte, blobSize*3+37)
+	rand.Read(data)
+	loc := access.Location{
+		CodeMode: srcMode,
+		Size:     uint64(len(data)),
+		BlobSize: uint32(blobSize),
+		Blobs: []access.SliceInfo{
+			{MinBid: 100, Vid: srcVid, Count: 2},
+			{MinBid: 200, Vid: copyingVid, Count: 2},
+		},
+	}
+
+	for _, cs := range []struct {
+		readSize, offset uint64
+	}{
+		{uint64(len(data)), 0},
+		{1, 0},
+		{uint64(blobSize) + 1, uint64(blobSize) - 1},
+		{100, uint64(blobSize) + 777},
+	} {
+		blobs, err := genLocationBlobs(&loc, cs.readSize, cs.offset)
+		require.NoError(t, err)
+		origin := make([]blobGetArgs, len(blobs))
+		copy(origin, blobs)
+		redirectTranscodedBlobs(context.Background(), sc, blobs)
+
+		for idx, blob := range blobs {
+			if origin[idx].Vid != srcVid {
+				require.Equal(t, origin[idx], blob)
+				continue
+			}
+			require.Equal(t, dstVid, blob.Vid)
+			require.Equal(t, dstMode, blob.CodeMode)
+			require.Equal(t, origin[idx].Offset, blob.Offset)
+			require.Equal(t, origin[idx].ReadSize, blob.ReadSize)
+
+			// the destination blob is the padded data shards of source blob
+			start := int(blob.Bid-100) * blobSize
+			blobData := data[start : start+int(origin[idx].BlobSize)]
+			sizes, err := ec.GetBufferSizes(len(blobData), srcMode.Tactic())
+			require.NoError(t, err)
+			dstData := make([]byte, sizes.ECDataSize)
+			copy(dstData, blobData)
+			require.Equal(t, int(blob.BlobSize), len(dstData))
+
+			sizes, err = ec.GetBufferSizes(len(dstData), dstMode.Tactic())
+			require.NoError(t, err)
+			require.Equal(t, sizes.ShardSize, blob.ShardSize)
+			require.Equal(t, blobData[blob.Offset:blob.Offset+blob.ReadSize],
+				dstData[blob.Offset:blob.Offset+blob.ReadSize])
+		}
+	}
+}
+
 func TestAccessStreamShardSegment(t *testing.T) {
 	shardSize := 2333
 	for _, cs := range []struct {
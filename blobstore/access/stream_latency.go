@@ -0,0 +1,176 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"math/bits"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+const (
+	latencyEWMAAlpha  = 0.2
+	latencySamples    = 128
+	minLatencySamples = 16
+
+	// latencyFailurePenalty the least latency recorded for a failed read
+	latencyFailurePenalty = time.Second
+)
+
+type diskLatency struct {
+	mu      sync.Mutex
+	ewma    float64
+	samples [latencySamples]time.Duration
+	count   int
+	next    int
+}
+
+// latencyTracker tracks latency of reading shards on disks,
+// the EWMA latency is used to sort shards in the same distance,
+// the percentile latency is used as the threshold of hedged read.
+type latencyTracker struct {
+	disks sync.Map // proto.DiskID -> *diskLatency
+}
+
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{}
+}
+
+func (t *latencyTracker) get(diskID proto.DiskID) *diskLatency {
+	if val, ok := t.disks.Load(diskID); ok {
+		return val.(*diskLatency)
+	}
+	val, _ := t.disks.LoadOrStore(diskID, &diskLatency{})
+	return val.(*diskLatency)
+}
+
+// Observe records one latency of disk
+func (t *latencyTracker) Observe(diskID proto.DiskID, latency time.Duration) {
+	if t == nil {
+		return
+	}
+	disk := t.get(diskID)
+	disk.mu.Lock()
+	if disk.count == 0 {
+		disk.ewma = float64(latency)
+	} else {
+		disk.ewma = latencyEWMAAlpha*float64(latency) + (1-latencyEWMAAlpha)*disk.ewma
+	}
+	disk.samples[disk.next] = latency
+	disk.next = (disk.next + 1) % latencySamples
+	if disk.count < latencySamples {
+		disk.count++
+	}
+	disk.mu.Unlock()
+}
+
+// ObserveFailure records a failed read of disk as a penalty latency
+func (t *latencyTracker) ObserveFailure(diskID proto.DiskID, latency time.Duration) {
+	if latency < latencyFailurePenalty {
+		latency = latencyFailurePenalty
+	}
+	t.Observe(diskID, latency)
+}
+
+// EWMA returns the moving average latency of disk, zero if it has not been observed
+func (t *latencyTracker) EWMA(diskID proto.DiskID) time.Duration {
+	if t == nil {
+		return 0
+	}
+	val, ok := t.disks.Load(diskID)
+	if !ok {
+		return 0
+	}
+	disk := val.(*diskLatency)
+	disk.mu.Lock()
+	ewma := disk.ewma
+	disk.mu.Unlock()
+	return time.Duration(ewma)
+}
+
+// Percentile returns the p-th (0, 1] percentile of recent latency of disk,
+// zero if there are no enough samples.
+func (t *latencyTracker) Percentile(diskID proto.DiskID, p float64) time.Duration {
+	if t == nil || p <= 0 || p > 1 {
+		return 0
+	}
+	val, ok := t.disks.Load(diskID)
+	if !ok {
+		return 0
+	}
+	disk := val.(*diskLatency)
+	disk.mu.Lock()
+	if disk.count < minLatencySamples {
+		disk.mu.Unlock()
+		return 0
+	}
+	samples := make([]time.Duration, disk.count)
+	copy(samples, disk.samples[:disk.count])
+	disk.mu.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	idx := int(p*float64(len(samples))+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return samples[idx]
+}
+
+// latencyBucket groups latencies by power of two milliseconds,
+// disks in the same bucket are regarded as the same fast.
+func latencyBucket(latency time.Duration) int {
+	return bits.Len64(uint64(latency / time.Millisecond))
+}
+
+// Buckets returns the latency buckets of disks. A disk without latency is put
+// into the bucket of the average latency of the others, so that it is neither
+// always tried first nor never tried.
+func (t *latencyTracker) Buckets(diskIDs []proto.DiskID) map[proto.DiskID]int {
+	buckets := make(map[proto.DiskID]int, len(diskIDs))
+	if t == nil {
+		return buckets
+	}
+	var (
+		unknown []proto.DiskID
+		sum     time.Duration
+	)
+	for _, diskID := range diskIDs {
+		val, ok := t.disks.Load(diskID)
+		if !ok {
+			unknown = append(unknown, diskID)
+			continue
+		}
+		disk := val.(*diskLatency)
+		disk.mu.Lock()
+		ewma := time.Duration(disk.ewma)
+		disk.mu.Unlock()
+		buckets[diskID] = latencyBucket(ewma)
+		sum += ewma
+	}
+	if len(unknown) == 0 {
+		return buckets
+	}
+	average := 0
+	if known := len(diskIDs) - len(unknown); known > 0 {
+		average = latencyBucket(sum / time.Duration(known))
+	}
+	for _, diskID := range unknown {
+		buckets[diskID] = average
+	}
+	return buckets
+}
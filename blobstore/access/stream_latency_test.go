@@ -0,0 +1,101 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+func TestAccessStreamLatencyTracker(t *testing.T) {
+	var nilTracker *latencyTracker
+	nilTracker.Observe(1, time.Second)
+	require.Equal(t, time.Duration(0), nilTracker.EWMA(1))
+	require.Equal(t, time.Duration(0), nilTracker.Percentile(1, 0.9))
+
+	tracker := newLatencyTracker()
+	require.Equal(t, time.Duration(0), tracker.EWMA(1))
+	tracker.Observe(1, 10*time.Millisecond)
+	require.Equal(t, 10*time.Millisecond, tracker.EWMA(1))
+	tracker.Observe(1, 20*time.Millisecond)
+	require.Equal(t, 12*time.Millisecond, tracker.EWMA(1))
+	// no enough samples
+	require.Equal(t, time.Duration(0), tracker.Percentile(1, 0.9))
+
+	for i := 1; i <= 200; i++ {
+		tracker.Observe(2, time.Duration(i)*time.Millisecond)
+	}
+	// only the latest samples are kept
+	require.Equal(t, 73*time.Millisecond, tracker.Percentile(2, 0.001))
+	require.Equal(t, 200*time.Millisecond, tracker.Percentile(2, 1))
+	require.Equal(t, 136*time.Millisecond, tracker.Percentile(2, 0.5))
+	require.Equal(t, time.Duration(0), tracker.Percentile(2, 0))
+	require.Equal(t, time.Duration(0), tracker.Percentile(2, 1.1))
+}
+
+func TestAccessStreamLatencyBuckets(t *testing.T) {
+	var nilTracker *latencyTracker
+	require.Empty(t, nilTracker.Buckets([]proto.DiskID{1}))
+
+	tracker := newLatencyTracker()
+	// all disks without latency are the same
+	require.Equal(t, map[proto.DiskID]int{1: 0, 2: 0}, tracker.Buckets([]proto.DiskID{1, 2}))
+
+	tracker.Observe(1, 5*time.Millisecond)
+	tracker.Observe(2, 6*time.Millisecond)
+	tracker.Observe(3, 60*time.Millisecond)
+	tracker.ObserveFailure(4, time.Millisecond)
+	require.Equal(t, latencyFailurePenalty, tracker.EWMA(4))
+
+	buckets := tracker.Buckets([]proto.DiskID{1, 2, 3, 4, 5})
+	// similar latency in the same bucket
+	require.Equal(t, buckets[1], buckets[2])
+	require.Less(t, buckets[2], buckets[3])
+	require.Less(t, buckets[3], buckets[4])
+	// disk without latency is not the first
+	require.Less(t, buckets[1], buckets[5])
+	require.Less(t, buckets[5], buckets[4])
+
+	// failures push the disk back
+	for i := 0; i < 8; i++ {
+		tracker.ObserveFailure(1, 10*time.Millisecond)
+	}
+	buckets = tracker.Buckets([]proto.DiskID{1, 2})
+	require.Less(t, buckets[2], buckets[1])
+}
+
+func TestAccessStreamHedgeDelay(t *testing.T) {
+	h := &Handler{latency: newLatencyTracker()}
+	require.Equal(t, time.Duration(0), h.hedgeDelay(1))
+
+	h.HedgeReadPercentile = 0.9
+	h.HedgeReadMinDelayMS = 10
+	h.HedgeReadMaxDelayMS = 100
+	h.HedgeReadMaxShards = 1
+	require.Equal(t, 100*time.Millisecond, h.hedgeDelay(1))
+
+	for i := 0; i < minLatencySamples; i++ {
+		h.latency.Observe(1, time.Millisecond)
+		h.latency.Observe(2, 50*time.Millisecond)
+		h.latency.Observe(3, time.Second)
+	}
+	require.Equal(t, 10*time.Millisecond, h.hedgeDelay(1))
+	require.Equal(t, 50*time.Millisecond, h.hedgeDelay(2))
+	require.Equal(t, 100*time.Millisecond, h.hedgeDelay(3))
+}
@@ -90,6 +90,11 @@ var (
 
 	vuidController *vuidControl
 
+	// blob dedup is enabled if it is not nil
+	blobDedupClient clustermgr.APIBlobDedup
+	// translation table of replicated locations
+	blobReplicaClient clustermgr.APIBlobReplica
+
 	putErrors = []errcode.Error{
 		errcode.ErrDiskBroken, errcode.ErrReadonlyVUID,
 		errcode.ErrChunkNoSpace,
@@ -344,6 +349,8 @@ func initMockData() {
 			}
 			return clustermgr.ServiceInfo{}, errNotFound
 		})
+	cli.EXPECT().ListTranscodeMapping(gomock.Any(), gomock.Any()).AnyTimes().
+		Return(clustermgr.ListTranscodeMappingRet{}, nil)
 	cmcli = cli
 
 	clusterInfo = &clustermgr.ClusterInfo{
@@ -378,6 +385,20 @@ func initMockData() {
 	c.EXPECT().ChooseOne().AnyTimes().Return(clusterInfo, nil)
 	c.EXPECT().GetServiceController(gomock.Any()).AnyTimes().Return(serviceController, nil)
 	c.EXPECT().GetVolumeGetter(gomock.Any()).AnyTimes().Return(volumeGetter, nil)
+	c.EXPECT().GetBlobDedup(gomock.Any()).AnyTimes().DoAndReturn(
+		func(proto.ClusterID) (clustermgr.APIBlobDedup, error) {
+			if blobDedupClient == nil {
+				return nil, controller.ErrBlobDedupDisabled
+			}
+			return blobDedupClient, nil
+		})
+	c.EXPECT().GetBlobReplica(gomock.Any()).AnyTimes().DoAndReturn(
+		func(proto.ClusterID) (clustermgr.APIBlobReplica, error) {
+			if blobReplicaClient == nil {
+				return nil, controller.ErrNoSuchCluster
+			}
+			return blobReplicaClient, nil
+		})
 	c.EXPECT().ChangeChooseAlg(gomock.Any()).AnyTimes().DoAndReturn(
 		func(alg controller.AlgChoose) error {
 			if alg < 10 {
@@ -507,6 +528,7 @@ func init() {
 		memPool:           memPool,
 		encoder:           encoder,
 		clusterController: cc,
+		latency:           newLatencyTracker(),
 
 		blobnodeClient: newMockStorageAPI(),
 		proxyClient:    proxyClient,
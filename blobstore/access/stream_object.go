@@ -0,0 +1,129 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+func (h *Handler) getObjectMeta() (cmapi.APIObjectMeta, error) {
+	if h.ObjectMetaClusterID == 0 {
+		return nil, errcode.ErrAccessObjectDisabled
+	}
+	objectMeta, err := h.clusterController.GetObjectMeta(h.ObjectMetaClusterID)
+	if err != nil {
+		return nil, errors.Info(err, "get object meta").Detail(err)
+	}
+	return objectMeta, nil
+}
+
+// SetObject set or overwrite the metadata of named object only if it is still prev,
+// the object must not exist if prev is nil
+func (h *Handler) SetObject(ctx context.Context, obj, prev *access.ObjectInfo) error {
+	span := trace.SpanFromContextSafe(ctx)
+	span.Debugf("to set object %s/%s %+v", obj.Bucket, obj.Key, obj.Location)
+
+	objectMeta, err := h.getObjectMeta()
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	args := &cmapi.CompareAndSetObjectMetaArgs{
+		Bucket: obj.Bucket,
+		Key:    obj.Key,
+		Value:  value,
+	}
+	if prev != nil {
+		if args.PrevValue, err = json.Marshal(prev); err != nil {
+			return err
+		}
+	}
+	if err = objectMeta.CompareAndSetObjectMeta(ctx, args); err != nil {
+		span.Error("set object meta failed", errors.Detail(err))
+		return err
+	}
+	return nil
+}
+
+// GetObject get the metadata of named object
+func (h *Handler) GetObject(ctx context.Context, bucket, key string) (*access.ObjectInfo, error) {
+	objectMeta, err := h.getObjectMeta()
+	if err != nil {
+		return nil, err
+	}
+	meta, err := objectMeta.GetObjectMeta(ctx, &cmapi.GetObjectMetaArgs{Bucket: bucket, Key: key})
+	if err != nil {
+		return nil, err
+	}
+	obj := &access.ObjectInfo{}
+	if err = json.Unmarshal(meta.Value, obj); err != nil {
+		return nil, errors.Info(err, "unmarshal object", bucket, key).Detail(err)
+	}
+	return obj, nil
+}
+
+// DeleteObject delete the metadata of named object only if it is still obj
+func (h *Handler) DeleteObject(ctx context.Context, obj *access.ObjectInfo) error {
+	objectMeta, err := h.getObjectMeta()
+	if err != nil {
+		return err
+	}
+	prevValue, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return objectMeta.DeleteObjectMeta(ctx, &cmapi.DeleteObjectMetaArgs{
+		Bucket:    obj.Bucket,
+		Key:       obj.Key,
+		PrevValue: prevValue,
+	})
+}
+
+// ListObjects list the metadata of named objects in bucket
+func (h *Handler) ListObjects(ctx context.Context, args *access.ListObjectsArgs) (*access.ListObjectsResp, error) {
+	objectMeta, err := h.getObjectMeta()
+	if err != nil {
+		return nil, err
+	}
+	ret, err := objectMeta.ListObjectMeta(ctx, &cmapi.ListObjectMetaArgs{
+		Bucket: args.Bucket,
+		Prefix: args.Prefix,
+		Marker: args.Marker,
+		Count:  args.Count,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &access.ListObjectsResp{Objects: make([]access.ObjectInfo, 0, len(ret.Objects)), Marker: ret.Marker}
+	for _, meta := range ret.Objects {
+		obj := access.ObjectInfo{}
+		if err = json.Unmarshal(meta.Value, &obj); err != nil {
+			return nil, errors.Info(err, "unmarshal object", meta.Bucket, meta.Key).Detail(err)
+		}
+		resp.Objects = append(resp.Objects, obj)
+	}
+	return resp, nil
+}
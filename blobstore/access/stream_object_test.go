@@ -0,0 +1,113 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func TestAccessStreamObject(t *testing.T) {
+	ctr := gomock.NewController(t)
+	metas := make(map[string]clustermgr.ObjectMeta)
+	cmcli := mocks.NewMockClientAPI(ctr)
+	cmcli.EXPECT().CompareAndSetObjectMeta(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, args *clustermgr.CompareAndSetObjectMetaArgs) error {
+			meta, ok := metas[args.Key]
+			if ok != (len(args.PrevValue) > 0) || !bytes.Equal(meta.Value, args.PrevValue) {
+				return errcode.ErrObjectMetaConflict
+			}
+			metas[args.Key] = clustermgr.ObjectMeta{Bucket: args.Bucket, Key: args.Key, Value: args.Value}
+			return nil
+		})
+	cmcli.EXPECT().GetObjectMeta(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, args *clustermgr.GetObjectMetaArgs) (clustermgr.ObjectMeta, error) {
+			meta, ok := metas[args.Key]
+			if !ok {
+				return meta, errcode.ErrNotFound
+			}
+			return meta, nil
+		})
+	cmcli.EXPECT().DeleteObjectMeta(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, args *clustermgr.DeleteObjectMetaArgs) error {
+			if meta, ok := metas[args.Key]; !ok || !bytes.Equal(meta.Value, args.PrevValue) {
+				return errcode.ErrObjectMetaConflict
+			}
+			delete(metas, args.Key)
+			return nil
+		})
+	cmcli.EXPECT().ListObjectMeta(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, args *clustermgr.ListObjectMetaArgs) (clustermgr.ListObjectMetaRet, error) {
+			ret := clustermgr.ListObjectMetaRet{Marker: "key"}
+			for _, meta := range metas {
+				ret.Objects = append(ret.Objects, meta)
+			}
+			return ret, nil
+		})
+
+	cc := NewMockClusterController(ctr)
+	cc.EXPECT().GetObjectMeta(gomock.Any()).AnyTimes().Return(cmcli, nil)
+	h := &Handler{clusterController: cc}
+	ctx := context.Background()
+
+	obj := &access.ObjectInfo{
+		Bucket:   "bucket",
+		Key:      "key",
+		Size:     1024,
+		Location: location.Copy(),
+		Meta:     map[string]string{"name": "value"},
+	}
+	// disabled
+	require.ErrorIs(t, h.SetObject(ctx, obj, nil), errcode.ErrAccessObjectDisabled)
+	_, err := h.GetObject(ctx, "bucket", "key")
+	require.ErrorIs(t, err, errcode.ErrAccessObjectDisabled)
+
+	h.ObjectMetaClusterID = 1
+	require.NoError(t, h.SetObject(ctx, obj, nil))
+	got, err := h.GetObject(ctx, "bucket", "key")
+	require.NoError(t, err)
+	require.Equal(t, obj, got)
+
+	// changed by others
+	newObj := *obj
+	newObj.Size = 2048
+	require.ErrorIs(t, h.SetObject(ctx, &newObj, nil), errcode.ErrObjectMetaConflict)
+	require.ErrorIs(t, h.SetObject(ctx, &newObj, &newObj), errcode.ErrObjectMetaConflict)
+	require.NoError(t, h.SetObject(ctx, &newObj, got))
+	got, err = h.GetObject(ctx, "bucket", "key")
+	require.NoError(t, err)
+	require.Equal(t, uint64(2048), got.Size)
+
+	resp, err := h.ListObjects(ctx, &access.ListObjectsArgs{Bucket: "bucket", Count: 10})
+	require.NoError(t, err)
+	require.Equal(t, "key", resp.Marker)
+	require.Equal(t, 1, len(resp.Objects))
+	require.Equal(t, "value", resp.Objects[0].Meta["name"])
+
+	// deleted only if not changed since read
+	require.ErrorIs(t, h.DeleteObject(ctx, obj), errcode.ErrObjectMetaConflict)
+	require.NoError(t, h.DeleteObject(ctx, got))
+	_, err = h.GetObject(ctx, "bucket", "key")
+	require.ErrorIs(t, err, errcode.ErrNotFound)
+}
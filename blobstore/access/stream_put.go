@@ -30,6 +30,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/api/blobnode"
 	"github.com/cubefs/cubefs/blobstore/common/ec"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/common/trace"
 	"github.com/cubefs/cubefs/blobstore/util/errors"
@@ -83,6 +84,16 @@ func (h *Handler) Put(ctx context.Context, rc io.Reader, size int64,
 		Blobs:     blobs,
 	}
 
+	var dedup *blobDedupPut
+	if dedupCli, err := h.clusterController.GetBlobDedup(clusterID); err == nil {
+		dedup = &blobDedupPut{
+			dedup:     dedupCli,
+			clusterID: clusterID,
+			codeMode:  selectedCodeMode,
+			blobSize:  blobSize,
+		}
+	}
+
 	uploadSucc := false
 	defer func() {
 		if !uploadSucc {
@@ -90,6 +101,11 @@ func (h *Handler) Put(ctx context.Context, rc io.Reader, size int64,
 			if err := h.clearGarbage(ctx, location); err != nil {
 				span.Warn(errors.Detail(err))
 			}
+			if dedup != nil && len(dedup.acquired) > 0 {
+				if err := h.clearGarbage(ctx, dedup.location(dedup.acquired)); err != nil {
+					span.Warn(errors.Detail(err))
+				}
+			}
 		}
 	}()
 
@@ -110,7 +126,8 @@ func (h *Handler) Put(ctx context.Context, rc io.Reader, size int64,
 
 	encoder := h.encoder[selectedCodeMode]
 	tactic := selectedCodeMode.Tactic()
-	for _, blob := range location.Spread() {
+	spread := location.Spread()
+	for idx, blob := range spread {
 		vid, bid, bsize := blob.Vid, blob.Bid, int(blob.Size)
 
 		// new an empty ec buffer for per blob
@@ -140,6 +157,16 @@ func (h *Handler) Put(ctx context.Context, rc io.Reader, size int64,
 			return nil, errcode.ErrAccessReadRequestBody
 		}
 
+		var dedupKey string
+		if dedup != nil {
+			dedupKey = blobDedupKey(selectedCodeMode, readBuff)
+			if dedup.acquire(ctx, h, dedupKey, spread[idx:]) {
+				buffer.Release()
+				buffer = nil
+				continue
+			}
+		}
+
 		// ec encode
 		if err = encoder.Encode(shards); err != nil {
 			return nil, err
@@ -161,10 +188,29 @@ func (h *Handler) Put(ctx context.Context, rc io.Reader, size int64,
 		if err != nil {
 			return nil, errors.Info(err, "write to blobnode failed")
 		}
+		if dedup != nil {
+			dedup.register(ctx, dedupKey, blob)
+		}
 	}
 
+	putLocation := location
+	if dedup != nil && len(dedup.acquired) > 0 {
+		putLocation = &access.Location{
+			ClusterID: clusterID,
+			CodeMode:  selectedCodeMode,
+			Size:      uint64(size),
+			BlobSize:  blobSize,
+			Blobs:     blobsToSlices(dedup.located),
+		}
+	}
+	if h.ReplicateEnabled {
+		// the location would never be replicated if its change log is lost
+		if err = h.sendReplicateMsg(ctx, proto.ReplicateOpPut, putLocation); err != nil {
+			return nil, err
+		}
+	}
 	uploadSucc = true
-	return location, nil
+	return putLocation, nil
 }
 
 func (h *Handler) writeToBlobnodesWithHystrix(ctx context.Context,
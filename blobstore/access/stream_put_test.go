@@ -16,6 +16,7 @@ package access
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"crypto/rand"
 	"crypto/sha1"
@@ -25,11 +26,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
 )
 
 func TestAccessStreamPutBase(t *testing.T) {
@@ -347,3 +351,58 @@ func BenchmarkAccessStreamPut(b *testing.B) {
 func sum2Str(b []byte) string {
 	return hex.EncodeToString(b[:])
 }
+
+func TestAccessStreamPutDedup(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamPutDedup")
+	dataShards.clean()
+
+	registered := make(map[string]clustermgr.BlobDedup)
+	cmcli := mocks.NewMockClientAPI(gomock.NewController(t))
+	cmcli.EXPECT().AcquireBlobDedup(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, args *clustermgr.AcquireBlobDedupArgs) (clustermgr.BlobDedup, error) {
+			blob, ok := registered[args.Hash]
+			if !ok {
+				return blob, errcode.ErrNotFound
+			}
+			blob.Refs++
+			registered[args.Hash] = blob
+			return blob, nil
+		})
+	cmcli.EXPECT().RegisterBlobDedup(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, args *clustermgr.RegisterBlobDedupArgs) (clustermgr.BlobDedup, error) {
+			blob := clustermgr.BlobDedup{Hash: args.Hash, Vid: args.Vid, Bid: args.Bid, Refs: 1}
+			registered[args.Hash] = blob
+			return blob, nil
+		})
+	blobDedupClient = cmcli
+	defer func() {
+		blobDedupClient = nil
+	}()
+
+	// the first two blobs have the same data
+	size := blobSize*2 + 1024
+	data := make([]byte, size)
+	rand.Read(data[blobSize*2:])
+	loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(size), nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(size), loc.Size)
+	require.Equal(t, 3, len(loc.Blobs))
+	require.Equal(t, loc.Blobs[0].MinBid, loc.Blobs[1].MinBid)
+	require.Equal(t, 2, len(registered))
+	require.Equal(t, uint32(2), registered[blobDedupKey(loc.CodeMode, data[:blobSize])].Refs)
+
+	buff := bytes.NewBuffer(nil)
+	transfer, err := streamer.Get(ctx(), buff, *loc, uint64(size), 0)
+	require.NoError(t, err)
+	require.NoError(t, transfer())
+	require.True(t, dataEqual(data, buff.Bytes()))
+
+	// all blobs are deduplicated
+	loc, err = streamer.Put(ctx(), bytes.NewReader(data), int64(size), nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(loc.Blobs))
+	require.Equal(t, uint32(4), registered[blobDedupKey(loc.CodeMode, data[:blobSize])].Refs)
+	require.Equal(t, uint32(2), registered[blobDedupKey(loc.CodeMode, data[blobSize*2:])].Refs)
+
+	dataShards.clean()
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"context"
+	"io"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/api/proxy"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+	"github.com/cubefs/cubefs/blobstore/util/retry"
+)
+
+// countWriter counts the bytes have been written,
+// and keeps the error of writer.
+type countWriter struct {
+	w   io.Writer
+	n   uint64
+	err error
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += uint64(n)
+	if err != nil {
+		cw.err = err
+	}
+	return n, err
+}
+
+// sendReplicateMsg sends change log of location to proxy in the cluster of location
+func (h *Handler) sendReplicateMsg(ctx context.Context, op proto.ReplicateOp, location *access.Location) error {
+	span := trace.SpanFromContextSafe(ctx)
+	serviceController, err := h.clusterController.GetServiceController(location.ClusterID)
+	if err != nil {
+		span.Error(errors.Detail(err))
+		return errors.Base(err, "replicate location:", *location)
+	}
+
+	// the location is signed, so replicator can read it by access
+	signed := location.Copy()
+	if err = fillCrc(&signed); err != nil {
+		span.Error(errors.Detail(err))
+		return err
+	}
+	replicateArgs := &proxy.ReplicateArgs{
+		ClusterID: location.ClusterID,
+		Op:        op,
+		Location:  signed.HexString(),
+		Key:       location.ReplicaKey(),
+	}
+	if err := retry.Timed(3, 200).On(func() error {
+		host, err := serviceController.GetServiceHost(ctx, serviceProxy)
+		if err != nil {
+			span.Warn(err)
+			return err
+		}
+		err = h.proxyClient.SendReplicateMsg(ctx, host, replicateArgs)
+		if err != nil {
+			if errorTimeout(err) || errorConnectionRefused(err) {
+				serviceController.PunishServiceWithThreshold(ctx, serviceProxy, host, h.ServicePunishIntervalS)
+			}
+			span.Warnf("send to %s replicate message(%+v) %s", host, replicateArgs, err.Error())
+			reportUnhealth(location.ClusterID, "punish", serviceProxy, host, "failed")
+			err = errors.Base(err, host)
+		}
+		return err
+	}); err != nil {
+		reportUnhealth(location.ClusterID, "replicate.msg", serviceProxy, "-", "failed")
+		span.Errorf("send replicate message(%+v) failed %s", replicateArgs, errors.Detail(err))
+		return errors.Base(err, "send replicate message:", replicateArgs)
+	}
+
+	span.Infof("send replicate message(%+v)", replicateArgs)
+	return nil
+}
+
+// getReplica returns the replicated location in replica cluster
+func (h *Handler) getReplica(ctx context.Context, location *access.Location) (*access.Location, error) {
+	replicaCli, err := h.clusterController.GetBlobReplica(h.ReplicaClusterID)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := replicaCli.GetBlobReplica(ctx, location.ReplicaKey())
+	if err != nil {
+		return nil, err
+	}
+	if ret.Deleted || len(ret.Replicas) == 0 {
+		return nil, errcode.ErrNotFound
+	}
+	replica, err := access.DecodeLocationFromHex(ret.Replicas[0])
+	if err != nil {
+		return nil, err
+	}
+	return &replica, nil
+}
+
+// getWithReplica reads the location in its own cluster, and reads the rest
+// data from replica if the cluster is unavailable during preparing or transferring.
+func (h *Handler) getWithReplica(ctx context.Context, w io.Writer, location access.Location,
+	readSize, offset uint64) (func() error, error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	failover := func(cause error, written uint64) (func() error, error) {
+		replica, err := h.getReplica(ctx, &location)
+		if err != nil {
+			span.Warnf("get replica of cluster %d failed, %s", h.ReplicaClusterID, errors.Detail(err))
+			reportDownload(location.ClusterID, "Replica", "miss")
+			return nil, cause
+		}
+		span.Infof("failover to read replica %+v, written %d, cause %s", replica, written, cause.Error())
+		reportDownload(location.ClusterID, "Replica", "-")
+		return h.getLocation(ctx, w, *replica, readSize-written, offset+written)
+	}
+
+	cw := &countWriter{w: w}
+	transfer, err := h.getLocation(ctx, cw, location, readSize, offset)
+	if err != nil {
+		if err == errcode.ErrIllegalArguments {
+			return transfer, err
+		}
+		replicaTransfer, rerr := failover(err, 0)
+		if rerr != nil {
+			return func() error { return nil }, rerr
+		}
+		return replicaTransfer, nil
+	}
+
+	return func() error {
+		err := transfer()
+		// no failover if the data can not be written
+		if err == nil || cw.err != nil {
+			return err
+		}
+		replicaTransfer, rerr := failover(err, cw.n)
+		if rerr != nil {
+			return rerr
+		}
+		return replicaTransfer()
+	}, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/api/proxy"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func TestAccessStreamReplicateMsg(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamReplicateMsg")
+	dataShards.clean()
+
+	origin := streamer.proxyClient
+	var msgs []*proxy.ReplicateArgs
+	proxycli := mocks.NewMockProxyClient(gomock.NewController(t))
+	proxycli.EXPECT().VolumeAlloc(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(origin.VolumeAlloc)
+	proxycli.EXPECT().SendDeleteMsg(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+	proxycli.EXPECT().SendShardRepairMsg(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+	proxycli.EXPECT().SendReplicateMsg(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, _ string, args *proxy.ReplicateArgs) error {
+			msgs = append(msgs, args)
+			if len(msgs) > 2 {
+				return errcode.ErrReplicateDisabled
+			}
+			return nil
+		})
+	streamer.proxyClient = proxycli
+	streamer.ReplicateEnabled = true
+	defer func() {
+		streamer.proxyClient = origin
+		streamer.ReplicateEnabled = false
+	}()
+
+	data := make([]byte, 1024)
+	rand.Read(data)
+	loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil)
+	require.NoError(t, err)
+	require.NoError(t, streamer.Delete(ctx(), loc))
+
+	signed := loc.Copy()
+	require.NoError(t, fillCrc(&signed))
+	require.Equal(t, 2, len(msgs))
+	require.Equal(t, proxy.ReplicateArgs{
+		ClusterID: clusterID, Op: proto.ReplicateOpPut, Location: signed.HexString(), Key: loc.ReplicaKey(),
+	}, *msgs[0])
+	require.Equal(t, proxy.ReplicateArgs{
+		ClusterID: clusterID, Op: proto.ReplicateOpDelete, Location: signed.HexString(), Key: loc.ReplicaKey(),
+	}, *msgs[1])
+
+	// delete is failed if the change log is not sent
+	require.Error(t, streamer.Delete(ctx(), loc))
+	require.Equal(t, 2+3, len(msgs))
+
+	// and so is put
+	_, err = streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil)
+	require.Error(t, err)
+	require.Equal(t, 2+3+3, len(msgs))
+}
+
+func TestAccessStreamGetReplica(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamGetReplica")
+	dataShards.clean()
+
+	replicas := make(map[string]clustermgr.BlobReplica)
+	cmcli := mocks.NewMockClientAPI(gomock.NewController(t))
+	cmcli.EXPECT().GetBlobReplica(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, key string) (clustermgr.BlobReplica, error) {
+			replica, ok := replicas[key]
+			if !ok {
+				return replica, errcode.ErrNotFound
+			}
+			return replica, nil
+		})
+	blobReplicaClient = cmcli
+	streamer.ReplicaClusterID = clusterID
+	defer func() {
+		blobReplicaClient = nil
+		streamer.ReplicaClusterID = 0
+	}()
+
+	size := blobSize + 1024
+	data := make([]byte, size)
+	rand.Read(data)
+	replica, err := streamer.Put(ctx(), bytes.NewReader(data), int64(size), nil)
+	require.NoError(t, err)
+
+	// the blobs of primary location are not found in its cluster
+	primary := replica.Copy()
+	primary.ClusterID = clusterID + 1
+	primary.Blobs = []access.SliceInfo{{MinBid: 1 << 40, Vid: volumeID, Count: 2}}
+
+	buff := bytes.NewBuffer(nil)
+	transfer, err := streamer.Get(ctx(), buff, primary, uint64(size), 0)
+	require.NoError(t, err)
+	require.Error(t, transfer())
+
+	replicas[primary.ReplicaKey()] = clustermgr.BlobReplica{
+		Key:      primary.ReplicaKey(),
+		Replicas: []string{replica.HexString()},
+	}
+	for _, offset := range []int{0, 1, blobSize - 1, blobSize, size - 1} {
+		buff.Reset()
+		readSize := size - offset
+		transfer, err = streamer.Get(ctx(), buff, primary, uint64(readSize), uint64(offset))
+		require.NoError(t, err)
+		require.NoError(t, transfer())
+		require.True(t, dataEqual(data[offset:], buff.Bytes()))
+	}
+
+	// the tombstone of deleted location
+	replicas[primary.ReplicaKey()] = clustermgr.BlobReplica{
+		Key:      primary.ReplicaKey(),
+		Replicas: []string{replica.HexString()},
+		Deleted:  true,
+	}
+	buff.Reset()
+	transfer, err = streamer.Get(ctx(), buff, primary, uint64(size), 0)
+	require.NoError(t, err)
+	require.Error(t, transfer())
+
+	// illegal arguments
+	_, err = streamer.Get(ctx(), buff, primary, uint64(size+1), 0)
+	require.ErrorIs(t, err, errcode.ErrIllegalArguments)
+}
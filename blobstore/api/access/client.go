@@ -20,14 +20,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"runtime"
-	"sort"
 	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/consul/api"
 	"gopkg.in/natefinch/lumberjack.v2"
 
+	"github.com/cubefs/cubefs/blobstore/common/consul"
+	"github.com/cubefs/cubefs/blobstore/common/discovery"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/resourcepool"
@@ -153,6 +155,9 @@ type Config struct {
 
 	// Consul is consul config for discovering service
 	Consul ConsulConfig
+	// Discovery discovers access service, Consul is ignored if it's not nil,
+	// see NewServiceDiscovery of clustermgr and NewDNS of discovery
+	Discovery discovery.Discovery
 	// ServiceIntervalS is interval seconds for discovering service
 	ServiceIntervalS int
 	// PriorityAddrs priority addrs of access service when retry
@@ -219,6 +224,21 @@ type API interface {
 	// Delete all blobs in these locations.
 	// return failed locations which have yet been deleted if error is not nil.
 	Delete(ctx context.Context, args *DeleteArgs) (failedLocations []Location, err error)
+	// Expire sets the location expire after ttl seconds, blobs are deleted after expired.
+	Expire(ctx context.Context, args *ExpireArgs) (err error)
+	// ListExpiring lists the expiring blobs in cluster.
+	ListExpiring(ctx context.Context, args *ListExpiringArgs) (resp ListExpiringResp, err error)
+
+	// PutObject puts data as named object bucket/key with user metadata, overwrites the old one if existed.
+	PutObject(ctx context.Context, args *PutObjectArgs) (obj ObjectInfo, err error)
+	// GetObject reads data of named object, range is supported.
+	GetObject(ctx context.Context, args *GetObjectArgs) (body io.ReadCloser, err error)
+	// StatObject returns location and user metadata of named object.
+	StatObject(ctx context.Context, args *ObjectArgs) (obj ObjectInfo, err error)
+	// DeleteObject deletes named object and all of its blobs.
+	DeleteObject(ctx context.Context, args *ObjectArgs) (err error)
+	// ListObjects lists named objects by prefix in bucket.
+	ListObjects(ctx context.Context, args *ListObjectsArgs) (resp ListObjectsResp, err error)
 }
 
 var _ API = (*client)(nil)
@@ -272,7 +292,16 @@ func New(cfg Config) (API, error) {
 		close(c.stop)
 	})
 
-	if cfg.Consul.Address == "" {
+	serviceDiscovery := cfg.Discovery
+	if serviceDiscovery == nil && cfg.Consul.Address != "" {
+		consulConfig := cfg.Consul
+		consulClient, err := api.NewClient(&consulConfig)
+		if err != nil {
+			return nil, errcode.ErrAccessServiceDiscovery
+		}
+		serviceDiscovery = consul.NewDiscovery(consulClient)
+	}
+	if serviceDiscovery == nil {
 		if len(cfg.PriorityAddrs) < 1 {
 			return nil, errcode.ErrAccessServiceDiscovery
 		}
@@ -280,90 +309,30 @@ func New(cfg Config) (API, error) {
 		return c, nil
 	}
 
-	consulConfig := cfg.Consul
-	consulClient, err := api.NewClient(&consulConfig)
-	if err != nil {
-		return nil, errcode.ErrAccessServiceDiscovery
-	}
-
-	first := true
-	serviceName := defaultServiceName
-	hostGetter := func() ([]string, error) {
-		if first && len(cfg.PriorityAddrs) > 0 {
-			hosts := make([]string, len(cfg.PriorityAddrs))
-			copy(hosts, cfg.PriorityAddrs[:])
-			first = false
-			return hosts, nil
-		}
-		services, _, err := consulClient.Health().Service(serviceName, "", true, nil)
+	hosts := make([]string, len(cfg.PriorityAddrs))
+	copy(hosts, cfg.PriorityAddrs[:])
+	if len(hosts) == 0 {
+		var err error
+		hosts, err = serviceDiscovery.Hosts(context.Background(), defaultServiceName)
 		if err != nil {
-			return nil, err
-		}
-		hosts := make([]string, 0, len(services))
-		for _, s := range services {
-			address := s.Service.Address
-			if address == "" {
-				address = s.Node.Address
-			}
-			hosts = append(hosts, fmt.Sprintf("http://%s:%d", address, s.Service.Port))
-		}
-		if len(hosts) == 0 {
-			return nil, fmt.Errorf("unavailable service")
+			log.Errorf("get hosts from discovery failed: %v", err)
+			return nil, errcode.ErrAccessServiceDiscovery
 		}
-		return hosts, nil
-	}
-
-	hosts, err := hostGetter()
-	if err != nil {
-		log.Errorf("get hosts from consul failed: %v", err)
-		return nil, errcode.ErrAccessServiceDiscovery
 	}
 	c.rpcClient.Store(getClient(&cfg, hosts))
 
-	ticker := time.NewTicker(time.Duration(cfg.ServiceIntervalS) * time.Second)
-	go func() {
-		for {
-			old := hosts
-			select {
-			case <-ticker.C:
-				hosts, err = hostGetter()
-				if err != nil {
-					log.Warnf("update hosts from consul failed: %v", err)
-					continue
-				}
-				if isUpdated(old, hosts) {
-					oldClient, ok := c.rpcClient.Load().(rpc.Client)
-					if ok && oldClient != nil {
-						oldClient.Close()
-					}
-					c.rpcClient.Store(getClient(&cfg, hosts))
-				}
-			case <-c.stop:
-				ticker.Stop()
-				return
+	go discovery.Watch(serviceDiscovery, defaultServiceName, hosts,
+		time.Duration(cfg.ServiceIntervalS)*time.Second, c.stop, func(hosts []string) {
+			oldClient, ok := c.rpcClient.Load().(rpc.Client)
+			if ok && oldClient != nil {
+				oldClient.Close()
 			}
-		}
-	}()
+			c.rpcClient.Store(getClient(&cfg, hosts))
+		})
 
 	return c, nil
 }
 
-func isUpdated(a, b []string) bool {
-	if len(a) != len(b) {
-		return true
-	}
-
-	sort.Slice(a, func(i, j int) bool { return a[i] < a[j] })
-	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
-
-	for i := 0; i < len(a); i++ {
-		if a[i] != b[i] {
-			return true
-		}
-	}
-	return false
-}
-
 func getClient(cfg *Config, hosts []string) rpc.Client {
 	lbConfig := &rpc.LbConfig{
 		Hosts:              hosts,
@@ -405,6 +374,9 @@ func (c *client) putObject(ctx context.Context, args *PutArgs) (location Locatio
 	rpcClient := c.rpcClient.Load().(rpc.Client)
 
 	urlStr := fmt.Sprintf("/put?size=%d&hashes=%d", args.Size, args.Hashes)
+	if args.TTL > 0 {
+		urlStr += fmt.Sprintf("&ttl=%d", args.TTL)
+	}
 	req, err := http.NewRequest(http.MethodPut, urlStr, args.Body)
 	if err != nil {
 		return
@@ -710,6 +682,13 @@ func (c *client) putParts(ctx context.Context, args *PutArgs) (Location, HashSum
 		loc = signResp.Location
 	}
 
+	if args.TTL > 0 {
+		if err := c.Expire(ctx, &ExpireArgs{Location: loc, TTL: args.TTL}); err != nil {
+			span.Error("expire location", err)
+			return Location{}, nil, err
+		}
+	}
+
 	for alg, hasher := range hasherMap {
 		hashSumMap[alg] = hasher.Sum(nil)
 	}
@@ -777,6 +756,31 @@ func (c *client) Delete(ctx context.Context, args *DeleteArgs) ([]Location, erro
 	return nil, nil
 }
 
+func (c *client) Expire(ctx context.Context, args *ExpireArgs) error {
+	if !args.IsValid() {
+		return errcode.ErrIllegalArguments
+	}
+	rpcClient := c.rpcClient.Load().(rpc.Client)
+
+	ctx = withReqidContext(ctx)
+	return retry.Timed(3, 10).On(func() error {
+		return rpcClient.PostWith(ctx, "/expire", nil, args)
+	})
+}
+
+func (c *client) ListExpiring(ctx context.Context, args *ListExpiringArgs) (resp ListExpiringResp, err error) {
+	if !args.IsValid() {
+		err = errcode.ErrIllegalArguments
+		return
+	}
+	rpcClient := c.rpcClient.Load().(rpc.Client)
+
+	ctx = withReqidContext(ctx)
+	err = rpcClient.GetWith(ctx, fmt.Sprintf("/expire/list?clusterid=%d&expire_before=%d&marker=%s&count=%d",
+		args.ClusterID, args.ExpireBefore, url.QueryEscape(args.Marker), args.Count), &resp)
+	return
+}
+
 func shouldRetry(code int, err error) bool {
 	if err != nil {
 		if httpErr, ok := err.(rpc.HTTPError); ok {
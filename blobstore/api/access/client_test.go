@@ -36,6 +36,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/common/discovery"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
@@ -605,6 +606,41 @@ func TestAccessServiceUnavailable(t *testing.T) {
 	require.Equal(t, errcode.ErrAccessServiceDiscovery, err)
 }
 
+type hostsDiscovery []string
+
+func (d hostsDiscovery) Hosts(ctx context.Context, service string) ([]string, error) {
+	if len(d) == 0 {
+		return nil, discovery.ErrNoAvailableHost
+	}
+	return d, nil
+}
+
+func TestAccessClientDiscovery(t *testing.T) {
+	cfg := access.Config{}
+	cfg.Discovery = hostsDiscovery{}
+	_, err := access.New(cfg)
+	require.Equal(t, errcode.ErrAccessServiceDiscovery, err)
+
+	// discovery is preferred to consul
+	cfg.Consul.Address = "127.0.0.1:1"
+	cfg.Discovery = hostsDiscovery{mockServer.URL}
+	cfg.LogLevel = log.Lfatal
+	client, err := access.New(cfg)
+	require.NoError(t, err)
+
+	dataCache.clean()
+	buff := make([]byte, 1<<10)
+	rand.Read(buff)
+	loc, _, err := client.Put(randCtx(), &access.PutArgs{Size: int64(len(buff)), Body: bytes.NewReader(buff)})
+	require.NoError(t, err)
+	body, err := client.Get(randCtx(), &access.GetArgs{Location: loc, ReadSize: uint64(len(buff))})
+	require.NoError(t, err)
+	defer body.Close()
+	got, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+	require.Equal(t, buff, got)
+}
+
 func TestAccessClientPutAtMerge(t *testing.T) {
 	cfg := access.Config{}
 	cfg.MaxSizePutOnce = 1 << 20
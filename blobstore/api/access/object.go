@@ -0,0 +1,202 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package access
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+)
+
+// HeaderObjectMetaPrefix user metadata of named object is passed by headers with this prefix,
+// the names of metadata are case-insensitive and stored in lower case.
+const HeaderObjectMetaPrefix = "X-Object-Meta-"
+
+// ObjectInfo named object bucket/key with its location and user metadata
+type ObjectInfo struct {
+	Bucket     string            `json:"bucket"`
+	Key        string            `json:"key"`
+	Size       uint64            `json:"size"`
+	Location   Location          `json:"location"`
+	Meta       map[string]string `json:"meta,omitempty"`
+	CreateTime int64             `json:"create_time"`
+}
+
+// PutObjectArgs for service /object/put
+type PutObjectArgs struct {
+	Bucket string            `json:"bucket"`
+	Key    string            `json:"key"`
+	Size   int64             `json:"size"`
+	Meta   map[string]string `json:"-"`
+	Body   io.Reader         `json:"-"`
+}
+
+// IsValid is valid put object args
+func (args *PutObjectArgs) IsValid() bool {
+	if args == nil {
+		return false
+	}
+	return clustermgr.IsValidObjectName(args.Bucket, args.Key) && args.Size > 0
+}
+
+// GetObjectArgs for service /object/get, read to the end of object if ReadSize is zero
+type GetObjectArgs struct {
+	Bucket   string `json:"bucket"`
+	Key      string `json:"key"`
+	Offset   uint64 `json:"offset"`
+	ReadSize uint64 `json:"read_size"`
+}
+
+// IsValid is valid get object args
+func (args *GetObjectArgs) IsValid() bool {
+	if args == nil {
+		return false
+	}
+	return clustermgr.IsValidObjectName(args.Bucket, args.Key)
+}
+
+// ObjectArgs for service /object/stat and /object/delete
+type ObjectArgs struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+}
+
+// IsValid is valid object args
+func (args *ObjectArgs) IsValid() bool {
+	if args == nil {
+		return false
+	}
+	return clustermgr.IsValidObjectName(args.Bucket, args.Key)
+}
+
+// ListObjectsArgs for service /object/list
+// list objects with Prefix after the key Marker in bucket
+type ListObjectsArgs struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix,omitempty"`
+	Marker string `json:"marker,omitempty"`
+	Count  int    `json:"count"`
+}
+
+// IsValid is valid list objects args
+func (args *ListObjectsArgs) IsValid() bool {
+	if args == nil {
+		return false
+	}
+	return clustermgr.IsValidObjectName(args.Bucket, "-") && args.Count >= 0
+}
+
+// ListObjectsResp list objects response, Marker is empty if there are no more objects
+type ListObjectsResp struct {
+	Objects []ObjectInfo `json:"objects"`
+	Marker  string       `json:"marker,omitempty"`
+}
+
+func (c *client) PutObject(ctx context.Context, args *PutObjectArgs) (obj ObjectInfo, err error) {
+	if !args.IsValid() {
+		err = errcode.ErrIllegalArguments
+		return
+	}
+	rpcClient := c.rpcClient.Load().(rpc.Client)
+
+	ctx = withReqidContext(ctx)
+	urlStr := fmt.Sprintf("/object/put?bucket=%s&key=%s&size=%d",
+		url.QueryEscape(args.Bucket), url.QueryEscape(args.Key), args.Size)
+	req, err := http.NewRequest(http.MethodPut, urlStr, args.Body)
+	if err != nil {
+		return
+	}
+	for name, value := range args.Meta {
+		req.Header.Set(HeaderObjectMetaPrefix+name, value)
+	}
+
+	err = rpcClient.DoWith(ctx, req, &obj, rpc.WithCrcEncode())
+	return
+}
+
+func (c *client) GetObject(ctx context.Context, args *GetObjectArgs) (body io.ReadCloser, err error) {
+	if !args.IsValid() {
+		return nil, errcode.ErrIllegalArguments
+	}
+	rpcClient := c.rpcClient.Load().(rpc.Client)
+
+	ctx = withReqidContext(ctx)
+	resp, err := rpcClient.Post(ctx, "/object/get", args)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, rpc.NewError(resp.StatusCode, "StatusCode", fmt.Errorf("code: %d", resp.StatusCode))
+	}
+	return resp.Body, nil
+}
+
+func (c *client) StatObject(ctx context.Context, args *ObjectArgs) (obj ObjectInfo, err error) {
+	if !args.IsValid() {
+		err = errcode.ErrIllegalArguments
+		return
+	}
+	rpcClient := c.rpcClient.Load().(rpc.Client)
+
+	ctx = withReqidContext(ctx)
+	err = rpcClient.GetWith(ctx, fmt.Sprintf("/object/stat?bucket=%s&key=%s",
+		url.QueryEscape(args.Bucket), url.QueryEscape(args.Key)), &obj)
+	return
+}
+
+func (c *client) DeleteObject(ctx context.Context, args *ObjectArgs) error {
+	if !args.IsValid() {
+		return errcode.ErrIllegalArguments
+	}
+	rpcClient := c.rpcClient.Load().(rpc.Client)
+
+	ctx = withReqidContext(ctx)
+	return rpcClient.PostWith(ctx, "/object/delete", nil, args)
+}
+
+func (c *client) ListObjects(ctx context.Context, args *ListObjectsArgs) (resp ListObjectsResp, err error) {
+	if !args.IsValid() {
+		err = errcode.ErrIllegalArguments
+		return
+	}
+	rpcClient := c.rpcClient.Load().(rpc.Client)
+
+	ctx = withReqidContext(ctx)
+	err = rpcClient.GetWith(ctx, fmt.Sprintf("/object/list?bucket=%s&prefix=%s&marker=%s&count=%d",
+		url.QueryEscape(args.Bucket), url.QueryEscape(args.Prefix), url.QueryEscape(args.Marker), args.Count), &resp)
+	return
+}
+
+// ObjectMetaFromHeader returns user metadata of named object in headers
+func ObjectMetaFromHeader(header http.Header) map[string]string {
+	var meta map[string]string
+	for name := range header {
+		if len(name) > len(HeaderObjectMetaPrefix) && strings.HasPrefix(name, HeaderObjectMetaPrefix) {
+			if meta == nil {
+				meta = make(map[string]string)
+			}
+			meta[strings.ToLower(name[len(HeaderObjectMetaPrefix):])] = header.Get(name)
+		}
+	}
+	return meta
+}
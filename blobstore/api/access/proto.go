@@ -342,6 +342,14 @@ func (loc *Location) Base64String() string {
 	return base64.StdEncoding.EncodeToString(loc.Encode())
 }
 
+// ReplicaKey returns key of the location in translation table of replicas,
+// crc is excluded as it is signed by access in each region
+func (loc *Location) ReplicaKey() string {
+	dst := loc.Copy()
+	dst.Crc = 0
+	return dst.HexString()
+}
+
 // Spread location blobs to slice
 func (loc *Location) Spread() []Blob {
 	count := 0
@@ -484,9 +492,11 @@ func DecodeLocationFromBase64(s string) (Location, error) {
 // PutArgs for service /put
 // Hashes means how to calculate check sum,
 // HashAlgCRC32 | HashAlgMD5 equal 2 + 4 = 6
+// TTL is seconds the object lives, the object never expires if TTL is zero
 type PutArgs struct {
 	Size   int64         `json:"size"`
 	Hashes HashAlgorithm `json:"hashes,omitempty"`
+	TTL    int64         `json:"ttl,omitempty"`
 	Body   io.Reader     `json:"-"`
 }
 
@@ -495,7 +505,7 @@ func (args *PutArgs) IsValid() bool {
 	if args == nil {
 		return false
 	}
-	return args.Size > 0
+	return args.Size > 0 && args.TTL >= 0
 }
 
 // PutResp put response result
@@ -634,3 +644,50 @@ func (args *SignArgs) IsValid() bool {
 type SignResp struct {
 	Location Location `json:"location"`
 }
+
+// ExpireArgs for service /expire
+// the location expires after TTL seconds from now, it overwrites the previous expiry
+type ExpireArgs struct {
+	Location Location `json:"location"`
+	TTL      int64    `json:"ttl"`
+}
+
+// IsValid is valid expire args
+func (args *ExpireArgs) IsValid() bool {
+	if args == nil {
+		return false
+	}
+	return args.TTL > 0 && args.Location.Size > 0 && len(args.Location.Blobs) > 0
+}
+
+// ListExpiringArgs for service /expire/list
+// only list blobs expire before ExpireBefore if it is not zero
+type ListExpiringArgs struct {
+	ClusterID    proto.ClusterID `json:"clusterid"`
+	ExpireBefore int64           `json:"expire_before,omitempty"`
+	Marker       string          `json:"marker,omitempty"`
+	Count        int             `json:"count"`
+}
+
+// IsValid is valid list expiring args
+func (args *ListExpiringArgs) IsValid() bool {
+	if args == nil {
+		return false
+	}
+	return args.ClusterID > proto.ClusterID(0) && args.Count >= 0
+}
+
+// ExpiringBlob consecutive blobs of a location with expiry
+type ExpiringBlob struct {
+	Vid      proto.Vid    `json:"vid"`
+	MinBid   proto.BlobID `json:"min_bid"`
+	Count    uint32       `json:"count"`
+	Size     uint64       `json:"size"`
+	ExpireAt int64        `json:"expire_at"`
+}
+
+// ListExpiringResp list expiring response, Marker is empty if there are no more blobs
+type ListExpiringResp struct {
+	Blobs  []ExpiringBlob `json:"blobs"`
+	Marker string         `json:"marker,omitempty"`
+}
@@ -378,6 +378,26 @@ func TestLocationDecodeError(t *testing.T) {
 	}
 }
 
+func TestLocationReplicaKey(t *testing.T) {
+	loc := access.Location{
+		ClusterID: 1,
+		Size:      10,
+		BlobSize:  1 << 22,
+		Crc:       0xff,
+		Blobs:     []access.SliceInfo{{MinBid: 100, Vid: 4, Count: 1}},
+	}
+	key := loc.ReplicaKey()
+	require.Equal(t, uint32(0xff), loc.Crc)
+
+	signed := loc.Copy()
+	signed.Crc = 0xee
+	require.Equal(t, key, signed.ReplicaKey())
+	require.NotEqual(t, loc.HexString(), signed.HexString())
+
+	signed.Blobs[0].MinBid++
+	require.NotEqual(t, key, signed.ReplicaKey())
+}
+
 func TestLocationSpread(t *testing.T) {
 	{
 		var loc access.Location
@@ -0,0 +1,65 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package blobnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// ClusterKeySize cluster keys are AES-256 keys
+const ClusterKeySize = 32
+
+var (
+	ErrClusterKeyNotFound = errors.New("cluster key not found")
+	ErrInvalidClusterKey  = errors.New("invalid cluster key")
+)
+
+// ClusterKeys versioned cluster keys used to wrap the data keys of disks,
+// keys are base64 encoded in json, version 0 is reserved for plain disks.
+type ClusterKeys struct {
+	Active uint32            `json:"active"`
+	Keys   map[uint32][]byte `json:"keys"`
+}
+
+func (ck *ClusterKeys) Check() error {
+	if _, ok := ck.Keys[ck.Active]; !ok {
+		return ErrClusterKeyNotFound
+	}
+	for version, key := range ck.Keys {
+		if version == 0 || len(key) != ClusterKeySize {
+			return ErrInvalidClusterKey
+		}
+	}
+	return nil
+}
+
+// Keeps returns true if the key of version is the same in both cluster keys
+func (ck *ClusterKeys) Keeps(old *ClusterKeys, version uint32) bool {
+	key, ok := ck.Keys[version]
+	return ok && bytes.Equal(key, old.Keys[version])
+}
+
+func ParseClusterKeys(data []byte) (*ClusterKeys, error) {
+	ck := &ClusterKeys{}
+	if err := json.Unmarshal(data, ck); err != nil {
+		return nil, err
+	}
+	if err := ck.Check(); err != nil {
+		return nil, err
+	}
+	return ck, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"context"
+
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+// BlobDedup is the deduplicated blob Bid in volume Vid with content Hash,
+// Refs is the number of locations referencing this blob.
+type BlobDedup struct {
+	Hash string       `json:"hash"`
+	Vid  proto.Vid    `json:"vid"`
+	Bid  proto.BlobID `json:"bid"`
+	Refs uint32       `json:"refs"`
+}
+
+type AcquireBlobDedupArgs struct {
+	Hash string `json:"hash"`
+}
+
+type RegisterBlobDedupArgs struct {
+	Hash string       `json:"hash"`
+	Vid  proto.Vid    `json:"vid"`
+	Bid  proto.BlobID `json:"bid"`
+}
+
+type ReleaseBlobDedupArgs struct {
+	Vid proto.Vid    `json:"vid"`
+	Bid proto.BlobID `json:"bid"`
+	// RefID identifies the released reference, it is released only once
+	// with the same RefID, so that a redelivered release takes no effect
+	RefID string `json:"ref_id,omitempty"`
+}
+
+type ReleaseBlobDedupRet struct {
+	// Refs is the remaining references, the blob can be deleted if it is zero
+	Refs uint32 `json:"refs"`
+}
+
+// AcquireBlobDedup returns the blob with the same content hash and increases its references,
+// returns not found error if no such blob
+func (c *Client) AcquireBlobDedup(ctx context.Context, args *AcquireBlobDedupArgs) (ret BlobDedup, err error) {
+	err = c.PostWith(ctx, "/blob/dedup/acquire", &ret, args)
+	return
+}
+
+// RegisterBlobDedup registers the new written blob with one reference,
+// returns the existing blob if the hash has been registered by another blob
+func (c *Client) RegisterBlobDedup(ctx context.Context, args *RegisterBlobDedupArgs) (ret BlobDedup, err error) {
+	err = c.PostWith(ctx, "/blob/dedup/register", &ret, args)
+	return
+}
+
+// ReleaseBlobDedup decreases the references of blob, the dedup record is removed
+// when there are no references, and zero is returned if the blob is not registered
+func (c *Client) ReleaseBlobDedup(ctx context.Context, args *ReleaseBlobDedupArgs) (ret ReleaseBlobDedupRet, err error) {
+	err = c.PostWith(ctx, "/blob/dedup/release", &ret, args)
+	return
+}
@@ -0,0 +1,136 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/common/discovery"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+)
+
+// ServiceDiscovery discovers service nodes of one cluster registered in clustermgr.
+type ServiceDiscovery struct {
+	client    *Client
+	clusterID proto.ClusterID
+}
+
+var _ discovery.Discovery = (*ServiceDiscovery)(nil)
+
+// NewServiceDiscovery returns discovery of cluster served by clustermgr.
+func NewServiceDiscovery(client *Client, clusterID proto.ClusterID) *ServiceDiscovery {
+	return &ServiceDiscovery{client: client, clusterID: clusterID}
+}
+
+// Hosts returns alive hosts of service, service names in clustermgr are upper case.
+func (d *ServiceDiscovery) Hosts(ctx context.Context, service string) ([]string, error) {
+	info, err := d.client.GetService(ctx, GetServiceArgs{Name: strings.ToUpper(service)})
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, 0, len(info.Nodes))
+	for _, node := range info.Nodes {
+		if proto.ClusterID(node.ClusterID) == d.clusterID {
+			hosts = append(hosts, node.Host)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, discovery.ErrNoAvailableHost
+	}
+	return hosts, nil
+}
+
+// ServiceRegistrar keeps service node alive in clustermgr,
+// the node is registered again if heartbeat failed.
+type ServiceRegistrar struct {
+	client  *Client
+	node    ServiceNode
+	timeout int
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+var _ discovery.Registrar = (*ServiceRegistrar)(nil)
+
+// NewServiceRegistrar registers service node to clustermgr, and heartbeats until closed.
+// tickInterval: unit of second
+// HeartbeatInterval = heartbeatTicks * tickInterval
+// expires = expiresTicks * tickInterval
+func NewServiceRegistrar(ctx context.Context, client *Client, node ServiceNode,
+	tickInterval, heartbeatTicks, expiresTicks uint32) (*ServiceRegistrar, error) {
+	r := &ServiceRegistrar{
+		client:  client,
+		node:    node,
+		timeout: int(expiresTicks * tickInterval),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if err := r.register(ctx); err != nil {
+		return nil, err
+	}
+	go r.loop(time.Duration(heartbeatTicks*tickInterval) * time.Second)
+	return r, nil
+}
+
+func (r *ServiceRegistrar) register(ctx context.Context) error {
+	return r.client.PostWith(ctx, registerUrl, nil, &RegisterArgs{ServiceNode: r.node, Timeout: r.timeout})
+}
+
+func (r *ServiceRegistrar) loop(interval time.Duration) {
+	defer close(r.done)
+	span, ctx := trace.StartSpanFromContext(context.Background(), "")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		select {
+		case <-ticker.C:
+			err := r.client.heartbeat(ctx, r.node.Name, r.node.Host)
+			if err != nil {
+				err = r.register(ctx)
+			}
+			// only when err status change, print log info
+			if (lastErr == nil) != (err == nil) {
+				if err != nil {
+					span.Errorf("heartbeat error:%v, name:[%s] host:[%s]", err, r.node.Name, r.node.Host)
+				} else {
+					span.Infof("heartbeat recover, name:[%s] host:[%s]", r.node.Name, r.node.Host)
+				}
+			}
+			lastErr = err
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// Close stops heartbeat and unregisters the service node.
+func (r *ServiceRegistrar) Close() {
+	r.once.Do(func() {
+		close(r.stop)
+		<-r.done
+		span, ctx := trace.StartSpanFromContext(context.Background(), "")
+		if err := r.client.UnregisterService(ctx, UnregisterArgs{Name: r.node.Name, Host: r.node.Host}); err != nil {
+			span.Warnf("unregister service %+v failed: %v", r.node, err)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/discovery"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+)
+
+type mockServiceMgr struct {
+	sync.Mutex
+	nodes      map[string]ServiceNode
+	heartbeats int
+}
+
+func (m *mockServiceMgr) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.Lock()
+	defer m.Unlock()
+	switch r.URL.Path {
+	case getserviceUrl:
+		info := ServiceInfo{}
+		for _, node := range m.nodes {
+			if node.Name == r.URL.Query().Get("name") {
+				info.Nodes = append(info.Nodes, node)
+			}
+		}
+		json.NewEncoder(w).Encode(info)
+	case registerUrl:
+		args := RegisterArgs{}
+		json.NewDecoder(r.Body).Decode(&args)
+		m.nodes[args.Host] = args.ServiceNode
+	case heartbeatUrl:
+		args := HeartbeatArgs{}
+		json.NewDecoder(r.Body).Decode(&args)
+		m.heartbeats++
+		if _, ok := m.nodes[args.Host]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case unregisterUrl:
+		args := UnregisterArgs{}
+		json.NewDecoder(r.Body).Decode(&args)
+		delete(m.nodes, args.Host)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (m *mockServiceMgr) count() int {
+	m.Lock()
+	defer m.Unlock()
+	return len(m.nodes)
+}
+
+func TestServiceDiscovery(t *testing.T) {
+	ctx := context.Background()
+	mgr := &mockServiceMgr{nodes: map[string]ServiceNode{
+		"http://127.0.0.1:9600": {ClusterID: 1, Name: "BLOBNODE", Host: "http://127.0.0.1:9600"},
+	}}
+	server := httptest.NewServer(mgr)
+	defer server.Close()
+	cli := New(&Config{LbConfig: rpc.LbConfig{Hosts: []string{server.URL}}})
+
+	d := NewServiceDiscovery(cli, 1)
+	_, err := d.Hosts(ctx, "access")
+	require.ErrorIs(t, err, discovery.ErrNoAvailableHost)
+
+	node := ServiceNode{ClusterID: 1, Name: "ACCESS", Host: "http://127.0.0.1:9500", Idc: "z0"}
+	r, err := NewServiceRegistrar(ctx, cli, node, 1, 1, 3)
+	require.NoError(t, err)
+	hosts, err := d.Hosts(ctx, "access")
+	require.NoError(t, err)
+	require.Equal(t, []string{node.Host}, hosts)
+
+	// the other cluster
+	_, err = NewServiceDiscovery(cli, 2).Hosts(ctx, "access")
+	require.ErrorIs(t, err, discovery.ErrNoAvailableHost)
+
+	// register again if the node was removed
+	mgr.Lock()
+	delete(mgr.nodes, node.Host)
+	mgr.Unlock()
+	require.Eventually(t, func() bool { return mgr.count() == 2 }, 5*time.Second, 100*time.Millisecond)
+
+	r.Close()
+	r.Close()
+	require.Equal(t, 1, mgr.count())
+	mgr.Lock()
+	heartbeats := mgr.heartbeats
+	mgr.Unlock()
+	require.Less(t, 0, heartbeats)
+}
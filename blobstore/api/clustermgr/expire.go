@@ -0,0 +1,79 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+// BlobExpire is the expiry of consecutive blobs [MinBid, MinBid+Count) in volume Vid,
+// Size is the total bytes of these blobs.
+type BlobExpire struct {
+	Vid      proto.Vid    `json:"vid"`
+	MinBid   proto.BlobID `json:"min_bid"`
+	Count    uint32       `json:"count"`
+	Size     uint64       `json:"size"`
+	ExpireAt int64        `json:"expire_at"`
+}
+
+// Expired returns true if the blobs have expired at unix time now
+func (e *BlobExpire) Expired(now int64) bool {
+	return e.ExpireAt <= now
+}
+
+type SetBlobExpireArgs struct {
+	Blobs []BlobExpire `json:"blobs"`
+}
+
+type DeleteBlobExpireArgs struct {
+	Blobs []BlobExpire `json:"blobs"`
+}
+
+type ListBlobExpireArgs struct {
+	// only list blobs expire before ExpireBefore if it is not zero
+	ExpireBefore int64  `json:"expire_before,omitempty"`
+	Marker       string `json:"marker,omitempty"`
+	Count        int    `json:"count"`
+}
+
+type ListBlobExpireRet struct {
+	Blobs []BlobExpire `json:"blobs"`
+	// Marker is empty if there are no more blobs
+	Marker string `json:"marker"`
+}
+
+// SetBlobExpire sets or overwrites the expiry of blobs
+func (c *Client) SetBlobExpire(ctx context.Context, args *SetBlobExpireArgs) (err error) {
+	err = c.PostWith(ctx, "/blob/expire/set", nil, args)
+	return
+}
+
+// DeleteBlobExpire removes the expiry of blobs, Vid, MinBid and ExpireAt are required,
+// the expiry is kept if it does not expire at ExpireAt any more
+func (c *Client) DeleteBlobExpire(ctx context.Context, args *DeleteBlobExpireArgs) (err error) {
+	err = c.PostWith(ctx, "/blob/expire/delete", nil, args)
+	return
+}
+
+// ListBlobExpire lists the expiry of blobs ordered by expiry time
+func (c *Client) ListBlobExpire(ctx context.Context, args *ListBlobExpireArgs) (ret ListBlobExpireRet, err error) {
+	err = c.GetWith(ctx, fmt.Sprintf("/blob/expire/list?expire_before=%d&marker=%s&count=%d",
+		args.ExpireBefore, url.QueryEscape(args.Marker), args.Count), &ret)
+	return
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	MaxObjectBucketLength = 63
+	MaxObjectKeyLength    = 1024
+)
+
+// ObjectMeta is the metadata of named object bucket/key,
+// Value is opaque to clustermgr and maintained by access.
+type ObjectMeta struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	Value  []byte `json:"value"`
+}
+
+// IsValidObjectName returns true if the bucket and key are legal names of object
+func IsValidObjectName(bucket, key string) bool {
+	return bucket != "" && len(bucket) <= MaxObjectBucketLength && !strings.Contains(bucket, "/") &&
+		key != "" && len(key) <= MaxObjectKeyLength
+}
+
+// CompareAndSetObjectMetaArgs sets Value of object only if its current value is PrevValue,
+// the object must not exist if PrevValue is empty
+type CompareAndSetObjectMetaArgs struct {
+	Bucket    string `json:"bucket"`
+	Key       string `json:"key"`
+	PrevValue []byte `json:"prev_value,omitempty"`
+	Value     []byte `json:"value"`
+}
+
+type GetObjectMetaArgs struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+}
+
+// DeleteObjectMetaArgs deletes the object only if its current value is PrevValue,
+// the object is deleted whatever its value is if PrevValue is empty
+type DeleteObjectMetaArgs struct {
+	Bucket    string `json:"bucket"`
+	Key       string `json:"key"`
+	PrevValue []byte `json:"prev_value,omitempty"`
+}
+
+type ListObjectMetaArgs struct {
+	Bucket string `json:"bucket"`
+	// list keys with the Prefix after the key Marker
+	Prefix string `json:"prefix,omitempty"`
+	Marker string `json:"marker,omitempty"`
+	Count  int    `json:"count"`
+}
+
+type ListObjectMetaRet struct {
+	Objects []ObjectMeta `json:"objects"`
+	// Marker is empty if there are no more objects
+	Marker string `json:"marker"`
+}
+
+// SetObjectMeta sets or overwrites the metadata of object
+func (c *Client) SetObjectMeta(ctx context.Context, args *ObjectMeta) (err error) {
+	err = c.PostWith(ctx, "/object/meta/set", nil, args)
+	return
+}
+
+// CompareAndSetObjectMeta sets the metadata of object if it is not changed by others,
+// ErrObjectMetaConflict is returned otherwise
+func (c *Client) CompareAndSetObjectMeta(ctx context.Context, args *CompareAndSetObjectMetaArgs) (err error) {
+	err = c.PostWith(ctx, "/object/meta/cas", nil, args)
+	return
+}
+
+// GetObjectMeta gets the metadata of object
+func (c *Client) GetObjectMeta(ctx context.Context, args *GetObjectMetaArgs) (ret ObjectMeta, err error) {
+	err = c.GetWith(ctx, fmt.Sprintf("/object/meta/get?bucket=%s&key=%s",
+		url.QueryEscape(args.Bucket), url.QueryEscape(args.Key)), &ret)
+	return
+}
+
+// DeleteObjectMeta deletes the metadata of object,
+// ErrObjectMetaConflict is returned if PrevValue is set and the object is changed by others
+func (c *Client) DeleteObjectMeta(ctx context.Context, args *DeleteObjectMetaArgs) (err error) {
+	err = c.PostWith(ctx, "/object/meta/delete", nil, args)
+	return
+}
+
+// ListObjectMeta lists the metadata of objects in bucket ordered by key
+func (c *Client) ListObjectMeta(ctx context.Context, args *ListObjectMetaArgs) (ret ListObjectMetaRet, err error) {
+	err = c.GetWith(ctx, fmt.Sprintf("/object/meta/list?bucket=%s&prefix=%s&marker=%s&count=%d",
+		url.QueryEscape(args.Bucket), url.QueryEscape(args.Prefix), url.QueryEscape(args.Marker), args.Count), &ret)
+	return
+}
@@ -61,6 +61,11 @@ type APIAccess interface {
 	GetConfig(ctx context.Context, key string) (string, error)
 	GetService(ctx context.Context, args GetServiceArgs) (ServiceInfo, error)
 	ListDisk(ctx context.Context, options *ListOptionArgs) (ListDiskRet, error)
+	ListTranscodeMapping(ctx context.Context, args *ListTranscodeMappingArgs) (ListTranscodeMappingRet, error)
+	APIBlobExpire
+	APIObjectMeta
+	APIBlobDedup
+	APIBlobReplica
 }
 
 // APIProxy sub of cluster manager api for allocator
@@ -72,6 +77,46 @@ type APIProxy interface {
 	AllocBid(ctx context.Context, args *BidScopeArgs) (*BidScopeRet, error)
 	RetainVolume(ctx context.Context, args *RetainVolumeArgs) (RetainVolumes, error)
 	RegisterService(ctx context.Context, node ServiceNode, tickInterval, heartbeatTicks, expiresTicks uint32) error
+	APIQueue
+}
+
+// APIQueue sub of cluster manager api for embedded message queue
+type APIQueue interface {
+	ProduceMessage(ctx context.Context, args *ProduceMessageArgs) error
+	ConsumeMessage(ctx context.Context, args *ConsumeMessageArgs) (ConsumeMessageRet, error)
+	RegisterQueueGroup(ctx context.Context, args *RegisterQueueGroupArgs) error
+	CommitQueueOffset(ctx context.Context, args *CommitQueueOffsetArgs) error
+	StatQueue(ctx context.Context, args *StatQueueArgs) (QueueStat, error)
+}
+
+// APIBlobExpire sub of cluster manager api for expiry of blobs
+type APIBlobExpire interface {
+	SetBlobExpire(ctx context.Context, args *SetBlobExpireArgs) error
+	DeleteBlobExpire(ctx context.Context, args *DeleteBlobExpireArgs) error
+	ListBlobExpire(ctx context.Context, args *ListBlobExpireArgs) (ListBlobExpireRet, error)
+}
+
+// APIObjectMeta sub of cluster manager api for metadata of named objects
+type APIObjectMeta interface {
+	SetObjectMeta(ctx context.Context, args *ObjectMeta) error
+	CompareAndSetObjectMeta(ctx context.Context, args *CompareAndSetObjectMetaArgs) error
+	GetObjectMeta(ctx context.Context, args *GetObjectMetaArgs) (ObjectMeta, error)
+	DeleteObjectMeta(ctx context.Context, args *DeleteObjectMetaArgs) error
+	ListObjectMeta(ctx context.Context, args *ListObjectMetaArgs) (ListObjectMetaRet, error)
+}
+
+// APIBlobDedup sub of cluster manager api for deduplicated blobs
+type APIBlobDedup interface {
+	AcquireBlobDedup(ctx context.Context, args *AcquireBlobDedupArgs) (BlobDedup, error)
+	RegisterBlobDedup(ctx context.Context, args *RegisterBlobDedupArgs) (BlobDedup, error)
+	ReleaseBlobDedup(ctx context.Context, args *ReleaseBlobDedupArgs) (ReleaseBlobDedupRet, error)
+}
+
+// APIBlobReplica sub of cluster manager api for translation table of replicated locations
+type APIBlobReplica interface {
+	GetBlobReplica(ctx context.Context, key string) (BlobReplica, error)
+	SetBlobReplica(ctx context.Context, args *BlobReplica) error
+	DeleteBlobReplica(ctx context.Context, key string) error
 }
 
 // APIService sub of cluster manager api for service
@@ -0,0 +1,132 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// QueueMessage is a message of the embedded queue, offsets in a topic start from 1
+type QueueMessage struct {
+	Offset uint64 `json:"offset"`
+	Time   int64  `json:"time"`
+	Value  []byte `json:"value"`
+}
+
+type ProduceMessageArgs struct {
+	Topic string   `json:"topic"`
+	Msgs  [][]byte `json:"msgs"`
+}
+
+type ConsumeMessageArgs struct {
+	Topic string `json:"topic"`
+	Group string `json:"group"`
+	// consume messages from Offset, 0 means after the committed offset of the group
+	Offset uint64 `json:"offset,omitempty"`
+	Count  int    `json:"count"`
+}
+
+type ConsumeMessageRet struct {
+	Msgs []*QueueMessage `json:"msgs"`
+}
+
+// RegisterQueueGroupArgs registers the consumer group of topic, messages are not
+// trimmed before consumed by all registered groups
+type RegisterQueueGroupArgs struct {
+	Topic string `json:"topic"`
+	Group string `json:"group"`
+}
+
+type CommitQueueOffsetArgs struct {
+	Topic string `json:"topic"`
+	Group string `json:"group"`
+	// Offset is the last consumed offset of the group
+	Offset uint64 `json:"offset"`
+}
+
+type StatQueueArgs struct {
+	Topic string `json:"topic"`
+}
+
+type QueueStat struct {
+	Topic string `json:"topic"`
+	// FirstOffset is the offset of the oldest message not trimmed
+	FirstOffset uint64 `json:"first_offset"`
+	// LastOffset is the offset of the latest produced message
+	LastOffset uint64 `json:"last_offset"`
+	// Groups is the committed offsets of consumer groups
+	Groups map[string]uint64 `json:"groups"`
+}
+
+// ProduceMessage appends messages to the topic in order
+func (c *Client) ProduceMessage(ctx context.Context, args *ProduceMessageArgs) (err error) {
+	err = c.PostWith(ctx, "/queue/produce", nil, args)
+	return
+}
+
+func (c *Client) ConsumeMessage(ctx context.Context, args *ConsumeMessageArgs) (ret ConsumeMessageRet, err error) {
+	err = c.GetWith(ctx, fmt.Sprintf("/queue/consume?topic=%s&group=%s&offset=%d&count=%d",
+		args.Topic, args.Group, args.Offset, args.Count), &ret)
+	return
+}
+
+// RegisterQueueGroup registers the group before consuming, a registered group is kept as
+// nothing committed until it commits
+func (c *Client) RegisterQueueGroup(ctx context.Context, args *RegisterQueueGroupArgs) (err error) {
+	err = c.PostWith(ctx, "/queue/register", nil, args)
+	return
+}
+
+// CommitQueueOffset commits the consumed offset of group, messages consumed by all groups will be trimmed
+func (c *Client) CommitQueueOffset(ctx context.Context, args *CommitQueueOffsetArgs) (err error) {
+	err = c.PostWith(ctx, "/queue/commit", nil, args)
+	return
+}
+
+func (c *Client) StatQueue(ctx context.Context, args *StatQueueArgs) (ret QueueStat, err error) {
+	err = c.GetWith(ctx, "/queue/stat?topic="+args.Topic, &ret)
+	return
+}
+
+// QueueProducer sends messages to the embedded queue
+type QueueProducer struct {
+	cli     APIQueue
+	timeout time.Duration
+}
+
+func NewQueueProducer(cli APIQueue, timeout time.Duration) *QueueProducer {
+	return &QueueProducer{cli: cli, timeout: timeout}
+}
+
+func (p *QueueProducer) SendMessage(topic string, msg []byte) error {
+	return p.SendMessages(topic, [][]byte{msg})
+}
+
+// SendKeyedMessage sends message in order, the embedded queue keeps the order of all messages
+func (p *QueueProducer) SendKeyedMessage(topic string, key, msg []byte) error {
+	return p.SendMessages(topic, [][]byte{msg})
+}
+
+func (p *QueueProducer) SendMessages(topic string, msgs [][]byte) error {
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	return p.cli.ProduceMessage(ctx, &ProduceMessageArgs{Topic: topic, Msgs: msgs})
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// translation table of replicated locations is stored in kv of the replica cluster
+//
+//	for example:
+//		blob_replica-<key of source location>
+const blobReplicaKeyPrefix = "blob_replica-"
+
+// BlobReplica translates the location in source cluster with Key to the location
+// replicated in this cluster, Replicas are hex encoded locations, a location is
+// replicated once and the others are left by former failures.
+// The translation of a deleted location is kept as a tombstone, so that its put
+// replayed again is not replicated.
+type BlobReplica struct {
+	Key      string   `json:"key"`
+	Replicas []string `json:"replicas"`
+	Deleted  bool     `json:"deleted,omitempty"`
+}
+
+// GetBlobReplica returns the translation of source location key,
+// returns not found error if the location has not been replicated
+func (c *Client) GetBlobReplica(ctx context.Context, key string) (ret BlobReplica, err error) {
+	kv, err := c.GetKV(ctx, blobReplicaKeyPrefix+key)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(kv.Value, &ret)
+	return
+}
+
+// SetBlobReplica sets or overwrites the translation of source location
+func (c *Client) SetBlobReplica(ctx context.Context, args *BlobReplica) error {
+	value, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	return c.SetKV(ctx, blobReplicaKeyPrefix+args.Key, value)
+}
+
+// DeleteBlobReplica deletes the translation of source location key
+func (c *Client) DeleteBlobReplica(ctx context.Context, key string) error {
+	return c.DeleteKV(ctx, blobReplicaKeyPrefix+key)
+}
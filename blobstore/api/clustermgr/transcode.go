@@ -0,0 +1,81 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+type TranscodeMappingStatus uint8
+
+const (
+	// TranscodeMappingStatusCopying blobs are being copied into the destination volume,
+	// deletions are postponed and reads stay on the source volume
+	TranscodeMappingStatusCopying = TranscodeMappingStatus(iota + 1)
+	// TranscodeMappingStatusDone all blobs of the source volume are in the destination volume
+	TranscodeMappingStatusDone
+)
+
+// TranscodeMapping maps a transcoded volume to the volume holding its blobs in another code mode.
+// The blob ids are unchanged, the data of a blob in the destination volume is the data
+// padded to the ec data size of the source code mode.
+type TranscodeMapping struct {
+	Vid         proto.Vid              `json:"vid"`
+	CodeMode    codemode.CodeMode      `json:"code_mode"`
+	DstVid      proto.Vid              `json:"dst_vid"`
+	DstCodeMode codemode.CodeMode      `json:"dst_code_mode"`
+	Status      TranscodeMappingStatus `json:"status"`
+	UpdateTime  int64                  `json:"update_time"`
+}
+
+func (m *TranscodeMapping) Done() bool {
+	return m.Status == TranscodeMappingStatusDone
+}
+
+type GetTranscodeMappingArgs struct {
+	Vid proto.Vid `json:"vid"`
+}
+
+type ListTranscodeMappingArgs struct {
+	// list mappings after Marker vid
+	Marker proto.Vid `json:"marker,omitempty"`
+	Count  int       `json:"count"`
+}
+
+type ListTranscodeMappingRet struct {
+	Mappings []*TranscodeMapping `json:"mappings"`
+	Marker   proto.Vid           `json:"marker"`
+}
+
+// SetTranscodeMapping set the mapping of a locked volume, it keeps the volume locked
+func (c *Client) SetTranscodeMapping(ctx context.Context, args *TranscodeMapping) (err error) {
+	err = c.PostWith(ctx, "/volume/transcode/mapping/set", nil, args)
+	return
+}
+
+func (c *Client) GetTranscodeMapping(ctx context.Context, args *GetTranscodeMappingArgs) (ret *TranscodeMapping, err error) {
+	ret = &TranscodeMapping{}
+	err = c.GetWith(ctx, "/volume/transcode/mapping/get?vid="+args.Vid.ToString(), ret)
+	return
+}
+
+func (c *Client) ListTranscodeMapping(ctx context.Context, args *ListTranscodeMappingArgs) (ret ListTranscodeMappingRet, err error) {
+	err = c.GetWith(ctx, fmt.Sprintf("/volume/transcode/mapping/list?marker=%d&count=%d", args.Marker, args.Count), &ret)
+	return
+}
@@ -59,6 +59,10 @@ func (c *client) SendDeleteMsg(ctx context.Context, host string, args *DeleteArg
 	return c.PostWith(ctx, host+"/deletemsg", nil, args)
 }
 
+func (c *client) SendReplicateMsg(ctx context.Context, host string, args *ReplicateArgs) error {
+	return c.PostWith(ctx, host+"/replicatemsg", nil, args)
+}
+
 func (c *client) GetCacheVolume(ctx context.Context, host string, args *CacheVolumeArgs) (volume *VersionVolume, err error) {
 	volume = new(VersionVolume)
 	url := fmt.Sprintf("%s/cache/volume/%d?flush=%v&version=%d", host, args.Vid, args.Flush, args.Version)
@@ -23,6 +23,7 @@ import (
 type MsgSender interface {
 	SendDeleteMsg(ctx context.Context, host string, args *DeleteArgs) error
 	SendShardRepairMsg(ctx context.Context, host string, args *ShardRepairArgs) error
+	SendReplicateMsg(ctx context.Context, host string, args *ReplicateArgs) error
 }
 
 type LbMsgSender interface {
@@ -47,3 +48,13 @@ type ShardRepairArgs struct {
 	BadIdxes  []uint8         `json:"bad_idxes"`
 	Reason    string          `json:"reason"`
 }
+
+// ReplicateArgs is the change log of location to replicate to another cluster,
+// Location is hex encoded location of access, and the messages of the same Key
+// are kept in order.
+type ReplicateArgs struct {
+	ClusterID proto.ClusterID   `json:"cluster_id"`
+	Op        proto.ReplicateOp `json:"op"`
+	Location  string            `json:"location"`
+	Key       string            `json:"key,omitempty"`
+}
@@ -40,6 +40,11 @@ const (
 	PathInspectAcquire       = "/inspect/acquire"
 	PathManualMigrateTaskAdd = "/manual/migrate/task/add"
 
+	PathTranscodeAcquire    = "/transcode/acquire"
+	PathTranscodeComplete   = "/transcode/complete"
+	PathTranscodeTaskAdd    = "/transcode/task/add"
+	PathTranscodeTaskDetail = "/transcode/task/detail"
+
 	PathTaskDetail    = "/task/detail"
 	PathTaskDetailURI = PathTaskDetail + "/:type/:id" // "/task/detail/:type/:id"
 	PathUpdateVolume  = "/update/vol"
@@ -65,6 +70,14 @@ type IInspector interface {
 	CompleteInspectTask(ctx context.Context, args *proto.VolumeInspectRet) (err error)
 }
 
+// ITranscoder volume transcode task.
+type ITranscoder interface {
+	AcquireTranscodeTask(ctx context.Context) (ret *proto.TranscodeTask, err error)
+	CompleteTranscodeTask(ctx context.Context, args *proto.TranscodeRet) (err error)
+	AddTranscodeTask(ctx context.Context, args *AddTranscodeArgs) (ret *proto.TranscodeTask, err error)
+	DetailTranscodeTask(ctx context.Context, args *TranscodeTaskDetailArgs) (ret *proto.TranscodeTask, err error)
+}
+
 // ISchedulerStatus scheduler status.
 type ISchedulerStatus interface {
 	DetailMigrateTask(ctx context.Context, args *MigrateTaskDetailArgs) (detail MigrateTaskDetail, err error)
@@ -87,6 +100,7 @@ type IVolumeUpdater interface {
 type IScheduler interface {
 	IMigrator
 	IInspector
+	ITranscoder
 	ISchedulerStatus
 	IManualMigrator
 	IVolumeUpdater
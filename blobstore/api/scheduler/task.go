@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 )
@@ -121,6 +122,47 @@ func (c *client) AddManualMigrateTask(ctx context.Context, args *AddManualMigrat
 	})
 }
 
+func (c *client) AcquireTranscodeTask(ctx context.Context) (ret *proto.TranscodeTask, err error) {
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, host+PathTranscodeAcquire, &ret)
+	})
+	return
+}
+
+func (c *client) CompleteTranscodeTask(ctx context.Context, args *proto.TranscodeRet) (err error) {
+	return c.request(func(host string) error {
+		return c.PostWith(ctx, host+PathTranscodeComplete, nil, args)
+	})
+}
+
+// AddTranscodeArgs transcode all blobs of the volume into the code mode.
+type AddTranscodeArgs struct {
+	Vid      proto.Vid         `json:"vid"`
+	CodeMode codemode.CodeMode `json:"code_mode"`
+}
+
+func (args *AddTranscodeArgs) Valid() bool {
+	return args.Vid != proto.InvalidVid && args.CodeMode.IsValid()
+}
+
+func (c *client) AddTranscodeTask(ctx context.Context, args *AddTranscodeArgs) (ret *proto.TranscodeTask, err error) {
+	err = c.request(func(host string) error {
+		return c.PostWith(ctx, host+PathTranscodeTaskAdd, &ret, args)
+	})
+	return
+}
+
+type TranscodeTaskDetailArgs struct {
+	ID string `json:"id"`
+}
+
+func (c *client) DetailTranscodeTask(ctx context.Context, args *TranscodeTaskDetailArgs) (ret *proto.TranscodeTask, err error) {
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, host+PathTranscodeTaskDetail+"?id="+url.QueryEscape(args.ID), &ret)
+	})
+	return
+}
+
 // MigrateTaskDetailArgs migrate task detail args.
 type MigrateTaskDetailArgs struct {
 	Type proto.TaskType `json:"type"`
@@ -177,6 +219,13 @@ type VolumeInspectTasksStat struct {
 	TimeOutPerMin  string `json:"time_out_per_min"`
 }
 
+type TranscodeTasksStat struct {
+	Enable         bool   `json:"enable"`
+	RunningCnt     int    `json:"running_cnt"`
+	FinishedPerMin string `json:"finished_per_min"`
+	TimeOutPerMin  string `json:"time_out_per_min"`
+}
+
 // RunnerStat shard repair and blob delete stat
 type RunnerStat struct {
 	Enable        bool     `json:"enable"`
@@ -192,8 +241,10 @@ type TasksStat struct {
 	Balance       *BalanceTasksStat       `json:"balance,omitempty"`
 	ManualMigrate *ManualMigrateTasksStat `json:"manual_migrate,omitempty"`
 	VolumeInspect *VolumeInspectTasksStat `json:"volume_inspect,omitempty"`
+	Transcode     *TranscodeTasksStat     `json:"transcode,omitempty"`
 	ShardRepair   *RunnerStat             `json:"shard_repair"`
 	BlobDelete    *RunnerStat             `json:"blob_delete"`
+	BlobReplicate *RunnerStat             `json:"blob_replicate,omitempty"`
 }
 
 func (c *client) DetailMigrateTask(ctx context.Context, args *MigrateTaskDetailArgs) (detail MigrateTaskDetail, err error) {
@@ -34,6 +34,10 @@ type IBlobNode interface {
 	ListShards(ctx context.Context, location proto.VunitLocation) (shards []*ShardInfo, err error)
 	GetShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, ioType api.IOType) (body io.ReadCloser, crc32 uint32, err error)
 	PutShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, size int64, body io.Reader, ioType api.IOType) (err error)
+	MarkDeleteShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (err error)
+	DeleteShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (err error)
+	SetChunkReadonly(ctx context.Context, location proto.VunitLocation) (err error)
+	SetChunkReadwrite(ctx context.Context, location proto.VunitLocation) (err error)
 }
 
 // BlobNodeClient blobnode client
@@ -157,3 +161,31 @@ func (c *BlobNodeClient) PutShard(ctx context.Context, location proto.VunitLocat
 	_, err = c.cli.PutShard(ctx, location.Host, &api.PutShardArgs{DiskID: location.DiskID, Vuid: location.Vuid, Bid: bid, Body: body, Size: size, Type: ioType})
 	return
 }
+
+// MarkDeleteShard mark delete shard
+func (c *BlobNodeClient) MarkDeleteShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (err error) {
+	pSpan := trace.SpanFromContextSafe(ctx)
+	_, ctx = trace.StartSpanFromContextWithTraceID(context.Background(), "MarkDeleteShard", pSpan.TraceID())
+	return c.cli.MarkDeleteShard(ctx, location.Host, &api.DeleteShardArgs{DiskID: location.DiskID, Vuid: location.Vuid, Bid: bid})
+}
+
+// DeleteShard delete the shard which has been mark deleted
+func (c *BlobNodeClient) DeleteShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (err error) {
+	pSpan := trace.SpanFromContextSafe(ctx)
+	_, ctx = trace.StartSpanFromContextWithTraceID(context.Background(), "DeleteShard", pSpan.TraceID())
+	return c.cli.DeleteShard(ctx, location.Host, &api.DeleteShardArgs{DiskID: location.DiskID, Vuid: location.Vuid, Bid: bid})
+}
+
+// SetChunkReadonly set chunk readonly
+func (c *BlobNodeClient) SetChunkReadonly(ctx context.Context, location proto.VunitLocation) (err error) {
+	pSpan := trace.SpanFromContextSafe(ctx)
+	_, ctx = trace.StartSpanFromContextWithTraceID(context.Background(), "SetChunkReadonly", pSpan.TraceID())
+	return c.cli.SetChunkReadonly(ctx, location.Host, &api.ChangeChunkStatusArgs{DiskID: location.DiskID, Vuid: location.Vuid})
+}
+
+// SetChunkReadwrite set chunk readwrite
+func (c *BlobNodeClient) SetChunkReadwrite(ctx context.Context, location proto.VunitLocation) (err error) {
+	pSpan := trace.SpanFromContextSafe(ctx)
+	_, ctx = trace.StartSpanFromContextWithTraceID(context.Background(), "SetChunkReadwrite", pSpan.TraceID())
+	return c.cli.SetChunkReadwrite(ctx, location.Host, &api.ChangeChunkStatusArgs{DiskID: location.DiskID, Vuid: location.Vuid})
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/blobnode/core"
 	"github.com/cubefs/cubefs/blobstore/blobnode/db"
 	"github.com/cubefs/cubefs/blobstore/cmd"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/common/trace"
 	"github.com/cubefs/cubefs/blobstore/util/defaulter"
@@ -73,6 +74,8 @@ type Config struct {
 	DeleteQpsLimitPerDisk int `json:"delete_qps_limit_per_disk"`
 
 	InspectConf DataInspectConf `json:"inspect_conf"`
+
+	DataEncryption core.EncryptionConfig `json:"data_encryption"`
 }
 
 func configInit(config *Config) {
@@ -110,6 +113,33 @@ func configInit(config *Config) {
 	}
 	defaulter.LessOrEqual(&config.InspectConf.IntervalSec, DefaultChunkInspectIntervalSec)
 	defaulter.LessOrEqual(&config.InspectConf.RateLimit, DefaultInspectRate)
+
+	if config.DataEncryption.Enable {
+		defaulter.Empty(&config.DataEncryption.KeySource, core.EncryptionKeySourceFile)
+		switch config.DataEncryption.KeySource {
+		case core.EncryptionKeySourceClusterMgr:
+		case core.EncryptionKeySourceFile:
+			if config.DataEncryption.KeyFile == "" {
+				log.Fatalf("key file of data encryption is empty")
+			}
+		default:
+			log.Fatalf("invalid key source of data encryption: %s", config.DataEncryption.KeySource)
+		}
+	}
+}
+
+// loadClusterKeys load cluster keys which wrap data keys of disks
+func (s *Service) loadClusterKeys(ctx context.Context) (*core.ClusterKeys, error) {
+	conf := s.Conf.DataEncryption
+	if conf.KeySource == core.EncryptionKeySourceFile {
+		return core.ReadClusterKeys(conf.KeyFile)
+	}
+
+	value, err := s.ClusterMgrClient.GetConfig(ctx, proto.DataEncryptionKeysConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	return core.ParseClusterKeys([]byte(value))
 }
 
 func (s *Service) changeLimit(ctx context.Context, c Config) {
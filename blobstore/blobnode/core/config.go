@@ -17,6 +17,7 @@ package core
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 
 	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/blobnode/base/qos"
@@ -105,6 +106,21 @@ type Config struct {
 	AllocDiskID      func(ctx context.Context) (proto.DiskID, error)
 	HandleIOError    func(ctx context.Context, diskID proto.DiskID, diskErr error)
 	NotifyCompacting func(ctx context.Context, args *cmapi.SetCompactChunkArgs) (err error)
+
+	// LoadClusterKeys is set if chunk data encryption enabled
+	LoadClusterKeys func(ctx context.Context) (*ClusterKeys, error)
+	// DataCipher encrypts new chunks of disk, set after the data key is loaded
+	DataCipher *DataCipher
+	// dataKeyVersion version of cluster key wrapping the data key, reported to clustermgr
+	dataKeyVersion uint32
+}
+
+func (conf *Config) DataKeyVersion() uint32 {
+	return atomic.LoadUint32(&conf.dataKeyVersion)
+}
+
+func (conf *Config) SetDataKeyVersion(version uint32) {
+	atomic.StoreUint32(&conf.dataKeyVersion, version)
 }
 
 func InitConfig(conf *Config) error {
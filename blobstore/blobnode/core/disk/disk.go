@@ -204,6 +204,8 @@ func (ds *DiskStorage) DiskInfo() (info bnapi.DiskInfo) {
 	info.Host = hostInfo.Host
 	info.Path = ds.Conf.Path
 
+	info.DataKeyVersion = ds.Conf.DataKeyVersion()
+
 	// status
 	info.Status = ds.status
 
@@ -465,6 +467,24 @@ func newDiskStorage(ctx context.Context, conf core.Config) (ds *DiskStorage, err
 		return nil, bloberr.ErrUnexpected
 	}
 
+	// load data key of disk if encryption enabled
+	if conf.LoadClusterKeys != nil {
+		clusterKeys, err := conf.LoadClusterKeys(ctx)
+		if err != nil {
+			span.Errorf("Failed load cluster keys, err:%v", err)
+			return nil, err
+		}
+		dataKey, version, err := core.LoadDataKey(ctx, path, dm.DiskID, clusterKeys)
+		if err != nil {
+			span.Errorf("Failed load data key, err:%v", err)
+			return nil, err
+		}
+		conf.SetDataKeyVersion(version)
+		if conf.DataCipher, err = core.NewDataCipher(dataKey); err != nil {
+			return nil, err
+		}
+	}
+
 	// init eio handler
 	sb.SetHandlerIOError(func(err error) {
 		conf.HandleIOError(context.Background(), dm.DiskID, err)
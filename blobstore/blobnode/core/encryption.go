@@ -0,0 +1,369 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package core
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	bnapi "github.com/cubefs/cubefs/blobstore/api/blobnode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+)
+
+/*
+ * Chunk data is encrypted by the data key of disk, the data key is generated
+ * randomly when the disk is first opened with encryption enabled, and saved in
+ * ${diskRoot}/.sys/.datakey.json after wrapped by the active cluster key.
+ *
+ * Rotation of cluster key:
+ * 	1. add a new version of cluster key and set it active
+ * 	2. restart blobnode or request /disk/datakey/rotate, data keys are rewrapped by the active version
+ * 	3. remove the old version after all disks in cluster are rewrapped
+ *
+ * The data key itself is never rotated, it lives as long as the disk is formatted.
+ */
+
+const (
+	dataKeyFile    = ".datakey.json"
+	dataKeyFileTmp = ".datakey.json.tmp"
+)
+
+const (
+	// DataKeySize data key and cluster key are AES-256 keys
+	DataKeySize = 32
+
+	EncryptionKeySourceClusterMgr = "clustermgr"
+	EncryptionKeySourceFile       = "file"
+)
+
+var (
+	ErrClusterKeyNotFound = bnapi.ErrClusterKeyNotFound
+	ErrInvalidClusterKey  = bnapi.ErrInvalidClusterKey
+	ErrInvalidDataKey     = errors.New("invalid data key")
+	ErrDataKeyNotMatch    = errors.New("data key not match disk")
+	ErrDataOverwrite      = errors.New("encrypted data can not be overwritten")
+)
+
+// EncryptionConfig at-rest encryption of chunk data
+type EncryptionConfig struct {
+	Enable bool `json:"enable"`
+	// KeySource where cluster keys are loaded from, file or clustermgr
+	KeySource string `json:"key_source"`
+	// KeyFile local file of cluster keys if key source is file
+	KeyFile string `json:"key_file"`
+}
+
+// ClusterKeys versioned cluster keys used to wrap the data keys of disks
+type ClusterKeys = bnapi.ClusterKeys
+
+func clusterKey(ck *ClusterKeys, version uint32) (cipher.AEAD, error) {
+	key, ok := ck.Keys[version]
+	if !ok {
+		return nil, ErrClusterKeyNotFound
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ReadClusterKeys read cluster keys in local file, as a stand-in of kms
+func ReadClusterKeys(file string) (*ClusterKeys, error) {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseClusterKeys(buf)
+}
+
+func ParseClusterKeys(data []byte) (*ClusterKeys, error) {
+	return bnapi.ParseClusterKeys(data)
+}
+
+// DataKeyInfo data key of disk wrapped by the cluster key of Version
+type DataKeyInfo struct {
+	DiskID     proto.DiskID `json:"diskid"`
+	Version    uint32       `json:"version"`
+	WrappedKey []byte       `json:"wrapped_key"`
+}
+
+func dataKeyAdditional(diskID proto.DiskID, version uint32) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint32(buf, uint32(diskID))
+	binary.BigEndian.PutUint32(buf[4:], version)
+	return buf
+}
+
+// WrapDataKey wrap data key of disk by the active cluster key with AES-GCM
+func WrapDataKey(ck *ClusterKeys, diskID proto.DiskID, dataKey []byte) (*DataKeyInfo, error) {
+	if len(dataKey) != DataKeySize {
+		return nil, ErrInvalidDataKey
+	}
+	aead, err := clusterKey(ck, ck.Active)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	wrapped := aead.Seal(nonce, nonce, dataKey, dataKeyAdditional(diskID, ck.Active))
+	return &DataKeyInfo{DiskID: diskID, Version: ck.Active, WrappedKey: wrapped}, nil
+}
+
+// Unwrap returns the plain data key
+func (info *DataKeyInfo) Unwrap(ck *ClusterKeys) ([]byte, error) {
+	aead, err := clusterKey(ck, info.Version)
+	if err != nil {
+		return nil, err
+	}
+	if len(info.WrappedKey) < aead.NonceSize() {
+		return nil, ErrInvalidDataKey
+	}
+	nonce, sealed := info.WrappedKey[:aead.NonceSize()], info.WrappedKey[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, dataKeyAdditional(info.DiskID, info.Version))
+	if err != nil {
+		return nil, ErrInvalidDataKey
+	}
+	if len(dataKey) != DataKeySize {
+		return nil, ErrInvalidDataKey
+	}
+	return dataKey, nil
+}
+
+func SaveDataKeyInfo(ctx context.Context, diskPath string, info *DataKeyInfo) (err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	keyFile := filepath.Join(sysRootPath(diskPath), dataKeyFile)
+	keyFileTemp := filepath.Join(sysRootPath(diskPath), dataKeyFileTmp)
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		span.Errorf("Failed marshal, err:%v", err)
+		return err
+	}
+
+	if err = ioutil.WriteFile(keyFileTemp, data, 0o600); err != nil {
+		span.Errorf("Failed write file:%s, err:%v", keyFileTemp, err)
+		return err
+	}
+
+	if err = os.Rename(keyFileTemp, keyFile); err != nil {
+		span.Errorf("Failed rename, err:%v", err)
+		return err
+	}
+
+	span.Infof("save data key of disk:%d version:%d success", info.DiskID, info.Version)
+	return nil
+}
+
+func ReadDataKeyInfo(ctx context.Context, diskPath string) (info *DataKeyInfo, err error) {
+	keyFile := filepath.Join(sysRootPath(diskPath), dataKeyFile)
+	buf, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	info = &DataKeyInfo{}
+	if err = json.Unmarshal(buf, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// LoadDataKey returns the data key of disk and the version of cluster key wrapping it,
+// a new one is generated if not exist, and it is rewrapped if the active cluster key has been rotated.
+func LoadDataKey(ctx context.Context, diskPath string, diskID proto.DiskID, ck *ClusterKeys) ([]byte, uint32, error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	info, err := ReadDataKeyInfo(ctx, diskPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			span.Errorf("Failed read data key, err:%v", err)
+			return nil, 0, err
+		}
+
+		span.Warnf("data key of disk:%d not exist, generate new one", diskID)
+		dataKey := make([]byte, DataKeySize)
+		if _, err = io.ReadFull(rand.Reader, dataKey); err != nil {
+			return nil, 0, err
+		}
+		if info, err = WrapDataKey(ck, diskID, dataKey); err != nil {
+			return nil, 0, err
+		}
+		if err = SaveDataKeyInfo(ctx, diskPath, info); err != nil {
+			return nil, 0, err
+		}
+		return dataKey, info.Version, nil
+	}
+
+	return rewrapDataKey(ctx, diskPath, diskID, info, ck)
+}
+
+// RewrapDataKey rewrap the existing data key of disk by the active cluster key,
+// returns the version of cluster key wrapping it
+func RewrapDataKey(ctx context.Context, diskPath string, diskID proto.DiskID, ck *ClusterKeys) (uint32, error) {
+	info, err := ReadDataKeyInfo(ctx, diskPath)
+	if err != nil {
+		return 0, err
+	}
+	_, version, err := rewrapDataKey(ctx, diskPath, diskID, info, ck)
+	return version, err
+}
+
+func rewrapDataKey(ctx context.Context, diskPath string, diskID proto.DiskID, info *DataKeyInfo, ck *ClusterKeys) ([]byte, uint32, error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	if info.DiskID != diskID {
+		span.Errorf("data key of disk:%d, but disk:%d", info.DiskID, diskID)
+		return nil, 0, ErrDataKeyNotMatch
+	}
+
+	dataKey, err := info.Unwrap(ck)
+	if err != nil {
+		span.Errorf("Failed unwrap data key by version:%d, err:%v", info.Version, err)
+		return nil, 0, err
+	}
+
+	if info.Version != ck.Active {
+		span.Warnf("rotate data key of disk:%d from version:%d to %d", diskID, info.Version, ck.Active)
+		newInfo, err := WrapDataKey(ck, diskID, dataKey)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err = SaveDataKeyInfo(ctx, diskPath, newInfo); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return dataKey, ck.Active, nil
+}
+
+// DataCipher encrypts chunk data with AES-CTR under a key derived from the data key
+// and the chunk id, the counter block is the offset in chunk file, so that data can be
+// read at any range.
+//
+// Threat model: it protects the confidentiality of chunk data on lost or retired disks
+// only. The ciphertext is not authenticated, the crc32block of shard detects corruption
+// but not tampering by one who can write the disk. The key stream must never be reused,
+// so every offset of chunk file is encrypted only once: the key is unique per chunk,
+// and the ChunkCipher rejects to write the offsets before the end of chunk file when it
+// is opened or the space allocated before.
+type DataCipher struct {
+	dataKey []byte
+}
+
+func NewDataCipher(dataKey []byte) (*DataCipher, error) {
+	if len(dataKey) != DataKeySize {
+		return nil, ErrInvalidDataKey
+	}
+	return &DataCipher{dataKey: append([]byte{}, dataKey...)}, nil
+}
+
+// ChunkCipher returns the cipher of chunk, the chunk file has been written before end
+func (c *DataCipher) ChunkCipher(chunk bnapi.ChunkId, end int64) (*ChunkCipher, error) {
+	mac := hmac.New(sha256.New, c.dataKey)
+	mac.Write(chunk[:])
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return &ChunkCipher{block: block, end: end}, nil
+}
+
+// ChunkCipher encrypts data of one chunk
+type ChunkCipher struct {
+	block cipher.Block
+
+	mu sync.Mutex
+	// offsets before end may have been encrypted
+	end int64
+}
+
+// XORKeyStreamAt encrypts or decrypts src into dst which located at off of chunk file
+func (c *ChunkCipher) XORKeyStreamAt(dst, src []byte, off int64) {
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint64(iv[8:], uint64(off/aes.BlockSize))
+
+	stream := cipher.NewCTR(c.block, iv)
+	if skip := int(off % aes.BlockSize); skip > 0 {
+		var pad [aes.BlockSize]byte
+		stream.XORKeyStream(pad[:skip], pad[:skip])
+	}
+	stream.XORKeyStream(dst, src)
+}
+
+func (c *ChunkCipher) ReaderAt(r io.ReaderAt) io.ReaderAt {
+	return &cipherReaderAt{cipher: c, r: r}
+}
+
+// WriterAt returns writer encrypting data into the space [off, off+size) of chunk file,
+// the space must be after all spaces allocated before, and every offset of it can be
+// written only once.
+func (c *ChunkCipher) WriterAt(w io.WriterAt, off, size int64) (io.WriterAt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if off < c.end || size < 0 {
+		return nil, ErrDataOverwrite
+	}
+	c.end = off + size
+	return &cipherWriterAt{cipher: c, w: w, next: off, end: off + size}, nil
+}
+
+type cipherReaderAt struct {
+	cipher *ChunkCipher
+	r      io.ReaderAt
+}
+
+func (cr *cipherReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	n, err = cr.r.ReadAt(p, off)
+	if n > 0 {
+		cr.cipher.XORKeyStreamAt(p[:n], p[:n], off)
+	}
+	return
+}
+
+type cipherWriterAt struct {
+	cipher *ChunkCipher
+	w      io.WriterAt
+	// offsets in [next, end) are not written yet
+	next int64
+	end  int64
+}
+
+func (cw *cipherWriterAt) WriteAt(p []byte, off int64) (n int, err error) {
+	if off < cw.next || off+int64(len(p)) > cw.end {
+		return 0, ErrDataOverwrite
+	}
+	cw.next = off + int64(len(p))
+
+	buf := make([]byte, len(p))
+	cw.cipher.XORKeyStreamAt(buf, p, off)
+	return cw.w.WriteAt(buf, off)
+}
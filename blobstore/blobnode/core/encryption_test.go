@@ -0,0 +1,204 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	bnapi "github.com/cubefs/cubefs/blobstore/api/blobnode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+func TestClusterKeys(t *testing.T) {
+	_, err := ParseClusterKeys([]byte("{"))
+	require.Error(t, err)
+
+	ck := &ClusterKeys{Active: 1, Keys: map[uint32][]byte{2: bytes.Repeat([]byte{1}, DataKeySize)}}
+	require.ErrorIs(t, ck.Check(), ErrClusterKeyNotFound)
+	ck.Keys[1] = []byte("short")
+	require.ErrorIs(t, ck.Check(), ErrInvalidClusterKey)
+	ck.Keys[0] = bytes.Repeat([]byte{3}, DataKeySize)
+	require.ErrorIs(t, ck.Check(), ErrInvalidClusterKey)
+	delete(ck.Keys, 0)
+	ck.Keys[1] = bytes.Repeat([]byte{2}, DataKeySize)
+	require.NoError(t, ck.Check())
+
+	changed := &ClusterKeys{Active: 1, Keys: map[uint32][]byte{1: bytes.Repeat([]byte{3}, DataKeySize)}}
+	require.True(t, ck.Keeps(ck, 2))
+	require.False(t, changed.Keeps(ck, 1))
+	require.False(t, changed.Keeps(ck, 2))
+
+	data, err := json.Marshal(ck)
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, ioutil.WriteFile(file, data, 0o600))
+	ck1, err := ReadClusterKeys(file)
+	require.NoError(t, err)
+	require.Equal(t, ck, ck1)
+}
+
+func TestDataKeyWrap(t *testing.T) {
+	ck := &ClusterKeys{Active: 1, Keys: map[uint32][]byte{1: bytes.Repeat([]byte{1}, DataKeySize)}}
+	dataKey := bytes.Repeat([]byte{0xf}, DataKeySize)
+
+	_, err := WrapDataKey(ck, 101, dataKey[:8])
+	require.ErrorIs(t, err, ErrInvalidDataKey)
+
+	info, err := WrapDataKey(ck, 101, dataKey)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), info.Version)
+	require.False(t, bytes.Contains(info.WrappedKey, dataKey))
+
+	key, err := info.Unwrap(ck)
+	require.NoError(t, err)
+	require.Equal(t, dataKey, key)
+
+	// wrong disk
+	info.DiskID = 102
+	_, err = info.Unwrap(ck)
+	require.ErrorIs(t, err, ErrInvalidDataKey)
+	info.DiskID = 101
+
+	// wrong cluster key
+	ck.Keys[1] = bytes.Repeat([]byte{2}, DataKeySize)
+	_, err = info.Unwrap(ck)
+	require.ErrorIs(t, err, ErrInvalidDataKey)
+	delete(ck.Keys, 1)
+	_, err = info.Unwrap(ck)
+	require.ErrorIs(t, err, ErrClusterKeyNotFound)
+}
+
+func TestLoadDataKey(t *testing.T) {
+	ctx := context.Background()
+	diskPath := t.TempDir()
+	require.NoError(t, EnsureDiskArea(diskPath, ""))
+	diskID := proto.DiskID(101)
+
+	ck := &ClusterKeys{Active: 1, Keys: map[uint32][]byte{1: bytes.Repeat([]byte{1}, DataKeySize)}}
+	_, err := RewrapDataKey(ctx, diskPath, diskID, ck)
+	require.True(t, os.IsNotExist(err))
+
+	// generate
+	dataKey, version, err := LoadDataKey(ctx, diskPath, diskID, ck)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), version)
+	key, _, err := LoadDataKey(ctx, diskPath, diskID, ck)
+	require.NoError(t, err)
+	require.Equal(t, dataKey, key)
+
+	_, _, err = LoadDataKey(ctx, diskPath, 102, ck)
+	require.ErrorIs(t, err, ErrDataKeyNotMatch)
+
+	// rotate
+	ck.Keys[2] = bytes.Repeat([]byte{2}, DataKeySize)
+	ck.Active = 2
+	version, err = RewrapDataKey(ctx, diskPath, diskID, ck)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), version)
+	info, err := ReadDataKeyInfo(ctx, diskPath)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), info.Version)
+
+	delete(ck.Keys, 1)
+	key, version, err = LoadDataKey(ctx, diskPath, diskID, ck)
+	require.NoError(t, err)
+	require.Equal(t, dataKey, key)
+	require.Equal(t, uint32(2), version)
+}
+
+func TestDataCipher(t *testing.T) {
+	_, err := NewDataCipher([]byte("short"))
+	require.ErrorIs(t, err, ErrInvalidDataKey)
+
+	c, err := NewDataCipher(bytes.Repeat([]byte{1}, DataKeySize))
+	require.NoError(t, err)
+
+	cc, err := c.ChunkCipher(bnapi.NewChunkId(1), 0)
+	require.NoError(t, err)
+	plain := bytes.Repeat([]byte("0123456789"), 100)
+	encrypted := make([]byte, len(plain))
+	cc.XORKeyStreamAt(encrypted, plain, 7)
+	require.NotEqual(t, plain, encrypted)
+
+	// decrypt at any range
+	for _, r := range [][2]int{{0, len(plain)}, {1, 17}, {9, 9 + 16}, {33, 100}, {500, len(plain)}} {
+		buf := make([]byte, r[1]-r[0])
+		cc.XORKeyStreamAt(buf, encrypted[r[0]:r[1]], int64(7+r[0]))
+		require.Equal(t, plain[r[0]:r[1]], buf)
+	}
+
+	// different chunk has different key stream, even at the same offset
+	other, err := c.ChunkCipher(bnapi.NewChunkId(2), 0)
+	require.NoError(t, err)
+	buf := make([]byte, len(plain))
+	other.XORKeyStreamAt(buf, plain, 7)
+	require.NotEqual(t, encrypted, buf)
+	for ii := 0; ii+aes.BlockSize <= len(plain); ii += aes.BlockSize {
+		require.NotEqual(t, encrypted[ii:ii+aes.BlockSize], buf[ii:ii+aes.BlockSize])
+	}
+}
+
+type bufferWriterAt []byte
+
+func (b bufferWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	return copy(b[off:], p), nil
+}
+
+func TestDataCipherRejectOverwrite(t *testing.T) {
+	c, err := NewDataCipher(bytes.Repeat([]byte{1}, DataKeySize))
+	require.NoError(t, err)
+	file := make(bufferWriterAt, 1024)
+
+	// written before 100 when opened
+	cc, err := c.ChunkCipher(bnapi.NewChunkId(1), 100)
+	require.NoError(t, err)
+	_, err = cc.WriterAt(file, 0, 100)
+	require.ErrorIs(t, err, ErrDataOverwrite)
+
+	w, err := cc.WriterAt(file, 100, 100)
+	require.NoError(t, err)
+	_, err = cc.WriterAt(file, 150, 100)
+	require.ErrorIs(t, err, ErrDataOverwrite)
+
+	_, err = w.WriteAt(make([]byte, 10), 90)
+	require.ErrorIs(t, err, ErrDataOverwrite)
+	_, err = w.WriteAt(make([]byte, 10), 195)
+	require.ErrorIs(t, err, ErrDataOverwrite)
+	n, err := w.WriteAt([]byte("encrypted"), 110)
+	require.NoError(t, err)
+	require.Equal(t, 9, n)
+	require.NotEqual(t, []byte("encrypted"), []byte(file[110:119]))
+	_, err = w.WriteAt([]byte("again"), 110)
+	require.ErrorIs(t, err, ErrDataOverwrite)
+
+	buf := make([]byte, 9)
+	_, err = cc.ReaderAt(bytes.NewReader(file)).ReadAt(buf, 110)
+	require.NoError(t, err)
+	require.Equal(t, []byte("encrypted"), buf)
+
+	w, err = cc.WriterAt(file, 200, 100)
+	require.NoError(t, err)
+	_, err = w.WriteAt(make([]byte, 100), 200)
+	require.NoError(t, err)
+}
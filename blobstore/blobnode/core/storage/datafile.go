@@ -45,7 +45,8 @@ import (
 // | version      |   ---- 1 byte
 // | parent chunk |   ---- 16 byte
 // | create time  |   ---- 8 byte
-// | padding      |   ---- aligned with shard padding size ( 4k-4-1-16-8)
+// | flag         |   ---- 1 byte
+// | padding      |   ---- aligned with shard padding size ( 4k-4-1-16-8-1)
 //  --------------
 // |    shard     |
 // |    shard     |
@@ -61,14 +62,22 @@ const (
 	_chunkVerSize         = 1
 	_chunkParentChunkSize = bnapi.ChunkIdLength
 	_chunkCreateTimeSize  = 8
-	//_chunkPaddingSize     = _chunkHeaderSize - _chunkMagicSize - _chunkVerSize - _chunkParentChunkSize - _chunkCreateTimeSize
+	_chunkFlagSize        = 1
+	//_chunkPaddingSize     = _chunkHeaderSize - _chunkMagicSize - _chunkVerSize - _chunkParentChunkSize - _chunkCreateTimeSize - _chunkFlagSize
 
 	// chunk offset
 	_chunkMagicOffset       = 0
 	_chunkVerOffset         = _chunkMagicOffset + _chunkMagicSize
 	_chunkParentChunkOffset = _chunkVerOffset + _chunkVerSize
 	_chunkCreateTimeOffset  = _chunkParentChunkOffset + _chunkParentChunkSize
-	//_chunkPaddingOffset     = _chunkCreateTimeOffset + _chunkCreateTimeSize
+	_chunkFlagOffset        = _chunkCreateTimeOffset + _chunkCreateTimeSize
+	//_chunkPaddingOffset     = _chunkFlagOffset + _chunkFlagSize
+)
+
+const (
+	// chunkFlagEncrypted shard body in chunk is encrypted by data key of disk,
+	// shard header and footer are always plain.
+	chunkFlagEncrypted = byte(1 << 0)
 )
 
 const (
@@ -86,6 +95,7 @@ var (
 	ErrShardHeaderNotMatch  = errors.New("chunkdata: shard header not match")
 	ErrChunkDataMagic       = errors.New("chunkdata: magic not match")
 	ErrChunkHeaderBufSize   = errors.New("chunkdata: buf size not match")
+	ErrChunkDataKeyMissing  = errors.New("chunkdata: encrypted but data key missing")
 )
 
 type ChunkHeader struct {
@@ -93,6 +103,7 @@ type ChunkHeader struct {
 	version     byte
 	parentChunk bnapi.ChunkId
 	createTime  int64
+	flag        byte
 }
 
 type datafile struct {
@@ -105,6 +116,7 @@ type datafile struct {
 	chunk  bnapi.ChunkId
 	header ChunkHeader
 	conf   *core.Config
+	cipher *core.ChunkCipher
 
 	ioQos  qos.Qos
 	closed bool
@@ -121,6 +133,8 @@ func (hdr *ChunkHeader) Marshal() ([]byte, error) {
 	copy(buf[_chunkParentChunkOffset:], hdr.parentChunk[:])
 	// create time
 	binary.BigEndian.PutUint64(buf[_chunkCreateTimeOffset:], uint64(hdr.createTime))
+	// flag
+	buf[_chunkFlagOffset] = hdr.flag
 
 	return buf, nil
 }
@@ -138,14 +152,15 @@ func (hdr *ChunkHeader) Unmarshal(data []byte) error {
 	hdr.version = data[_chunkVerOffset : _chunkVerOffset+_chunkVerSize][0]
 	copy(hdr.parentChunk[:], data[_chunkParentChunkOffset:_chunkParentChunkOffset+_chunkParentChunkSize])
 	hdr.createTime = int64(binary.BigEndian.Uint64(data[_chunkCreateTimeOffset : _chunkCreateTimeOffset+_chunkCreateTimeSize]))
+	hdr.flag = data[_chunkFlagOffset]
 
 	return nil
 }
 
 func (hdr *ChunkHeader) String() string {
 	ctime := time.Unix(0, hdr.createTime)
-	s := fmt.Sprintf("magic:\t%v\nversion:\t%v\nparent:\t%s\nctime:\t%s",
-		hdr.magic, hdr.version, hdr.parentChunk, ctime)
+	s := fmt.Sprintf("magic:\t%v\nversion:\t%v\nparent:\t%s\nctime:\t%s\nflag:\t%d",
+		hdr.magic, hdr.version, hdr.parentChunk, ctime, hdr.flag)
 	return s
 }
 
@@ -200,6 +215,10 @@ func (cd *datafile) initHeader(meta *core.VuidMeta) {
 		parentChunk: meta.ParentChunk,
 		createTime:  meta.Ctime,
 	}
+	// only new chunks are encrypted, the old plain chunks are still readable
+	if cd.conf.DataCipher != nil {
+		cd.header.flag |= chunkFlagEncrypted
+	}
 }
 
 func (cd *datafile) init(meta *core.VuidMeta) (err error) {
@@ -224,6 +243,10 @@ func (cd *datafile) init(meta *core.VuidMeta) (err error) {
 		cd.wOff = core.AlignSize(chunkSize, int64(_pageSize))
 	}
 
+	// the space before wOff is never encrypted again
+	if cd.encrypted() {
+		cd.cipher, err = cd.conf.DataCipher.ChunkCipher(cd.chunk, cd.wOff)
+	}
 	return
 }
 
@@ -285,20 +308,32 @@ func (cd *datafile) parseMeta() (err error) {
 		return
 	}
 
+	if hdr.flag&chunkFlagEncrypted != 0 && cd.conf.DataCipher == nil {
+		return ErrChunkDataKeyMissing
+	}
+
 	cd.header = *hdr
 	return
 }
 
-func (cd *datafile) allocSpace(fsize int64) (pos int64, err error) {
+// allocSpace returns the position of allocated space and the writer of shard body in it,
+// the body is encrypted if chunk is encrypted
+func (cd *datafile) allocSpace(fsize int64) (pos int64, bodyw io.WriterAt, err error) {
 	cd.wLock.Lock()
 	defer cd.wLock.Unlock()
 
 	pos = cd.wOff
+	bodyw = cd.ef
+	if cd.cipher != nil {
+		if bodyw, err = cd.cipher.WriterAt(cd.ef, pos, fsize); err != nil {
+			return 0, nil, err
+		}
+	}
 
 	cd.wOff += fsize
 	cd.wOff = core.AlignSize(cd.wOff, _pageSize)
 
-	return pos, nil
+	return pos, bodyw, nil
 }
 
 func (cd *datafile) Write(ctx context.Context, shard *core.Shard) error {
@@ -316,7 +351,7 @@ func (cd *datafile) Write(ctx context.Context, shard *core.Shard) error {
 
 	// allocate space
 	phySize := core.Alignphysize(int64(shard.Size))
-	pos, err := cd.allocSpace(phySize)
+	pos, bodyw, err := cd.allocSpace(phySize)
 	if err != nil {
 		return err
 	}
@@ -343,7 +378,7 @@ func (cd *datafile) Write(ctx context.Context, shard *core.Shard) error {
 
 	pos += core.GetShardHeaderSize()
 
-	w = &bncomm.Writer{WriterAt: cd.ef, Offset: pos}
+	w = &bncomm.Writer{WriterAt: bodyw, Offset: pos}
 	twRaw := bncomm.NewTimeWriter(w)
 
 	qosw := cd.qosWriter(ctx, twRaw)
@@ -418,7 +453,7 @@ func (cd *datafile) Read(ctx context.Context, shard *core.Shard, from, to uint32
 	pos := shard.Offset + core.GetShardHeaderSize()
 
 	// new reader
-	iosr := cd.qosReaderAt(ctx, cd.ef)
+	iosr := cd.qosReaderAt(ctx, cd.bodyReaderAt())
 
 	// new buffer
 	block := make([]byte, core.CrcBlockUnitSize)
@@ -515,6 +550,18 @@ func (cd *datafile) spaceInfo() (size int64, phySpace int64, err error) {
 	return
 }
 
+func (cd *datafile) encrypted() bool {
+	return cd.header.flag&chunkFlagEncrypted != 0
+}
+
+// bodyReaderAt returns reader of shard body, decrypted if chunk is encrypted
+func (cd *datafile) bodyReaderAt() io.ReaderAt {
+	if cd.cipher != nil {
+		return cd.cipher.ReaderAt(cd.ef)
+	}
+	return cd.ef
+}
+
 func (cd *datafile) qosReaderAt(ctx context.Context, reader io.ReaderAt) io.ReaderAt {
 	ioType := bnapi.GetIoType(ctx)
 	return cd.ioQos.ReaderAt(ctx, ioType, reader)
@@ -171,6 +171,71 @@ func TestChunkData_Write(t *testing.T) {
 	require.Equal(t, expectedOff, cd.wOff)
 }
 
+func TestChunkData_WriteEncrypted(t *testing.T) {
+	testDir, err := ioutil.TempDir(os.TempDir(), defaultDiskTestDir+"ChunkDataWriteEncrypted")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	ctx := context.Background()
+	chunkname := filepath.Join(testDir, bnapi.NewChunkId(0).String())
+
+	dataCipher, err := core.NewDataCipher(bytes.Repeat([]byte{0x1}, core.DataKeySize))
+	require.NoError(t, err)
+	diskConfig := &core.Config{
+		BaseConfig:    core.BaseConfig{Path: testDir},
+		RuntimeConfig: core.RuntimeConfig{BlockBufferSize: 64 * 1024},
+		DataCipher:    dataCipher,
+	}
+
+	ioPool := newIoPoolMock(t)
+	ioQos, _ := qos.NewIoQueueQos(qos.Config{ReadQueueDepth: 2, WriteQueueDepth: 2, MaxWaitCount: 4, WriteChanQueCnt: 2})
+	defer ioQos.Close()
+	cd, err := NewChunkData(ctx, core.VuidMeta{}, chunkname, diskConfig, true, ioQos, ioPool, ioPool)
+	require.NoError(t, err)
+	require.True(t, cd.encrypted())
+
+	sharddata := bytes.Repeat([]byte("encrypted shard data "), 10*1024)
+	shard := &core.Shard{
+		Bid:  1024,
+		Vuid: 10,
+		Flag: bnapi.ShardStatusNormal,
+		Size: uint32(len(sharddata)),
+		Body: bytes.NewBuffer(sharddata),
+	}
+	require.NoError(t, cd.Write(ctx, shard))
+
+	r, err := cd.Read(ctx, shard, 0, shard.Size)
+	require.NoError(t, err)
+	rd, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, sharddata, rd)
+
+	r, err = cd.Read(ctx, shard, 70*1024+3, 100*1024)
+	require.NoError(t, err)
+	rd, err = ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, sharddata[70*1024+3:100*1024], rd)
+
+	// no plain data on disk
+	raw, err := ioutil.ReadFile(chunkname)
+	require.NoError(t, err)
+	require.False(t, bytes.Contains(raw, []byte("encrypted shard data")))
+	cd.Close()
+
+	// data key missing
+	_, err = NewChunkData(ctx, core.VuidMeta{}, chunkname, &core.Config{}, false, ioQos, ioPool, ioPool)
+	require.Error(t, err)
+
+	cd, err = NewChunkData(ctx, core.VuidMeta{}, chunkname, diskConfig, false, ioQos, ioPool, ioPool)
+	require.NoError(t, err)
+	defer cd.Close()
+	r, err = cd.Read(ctx, shard, 0, shard.Size)
+	require.NoError(t, err)
+	rd, err = ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, sharddata, rd)
+}
+
 func TestChunkData_ConcurrencyWrite(t *testing.T) {
 	testDir, err := ioutil.TempDir(os.TempDir(), defaultDiskTestDir+"ChunkDataWriteCon")
 	require.NoError(t, err)
@@ -500,6 +565,7 @@ func TestChunkHeader(t *testing.T) {
 		version:     version,
 		parentChunk: parent,
 		createTime:  createTime,
+		flag:        chunkFlagEncrypted,
 	}
 
 	buffer, err := hdr.Marshal()
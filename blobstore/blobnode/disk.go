@@ -19,6 +19,7 @@ import (
 
 	bnapi "github.com/cubefs/cubefs/blobstore/api/blobnode"
 	"github.com/cubefs/cubefs/blobstore/blobnode/base"
+	"github.com/cubefs/cubefs/blobstore/blobnode/core"
 	"github.com/cubefs/cubefs/blobstore/blobnode/core/disk"
 	bloberr "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
@@ -145,3 +146,39 @@ func (s *Service) DiskProbe(c *rpc.Context) {
 
 	span.Infof("probe path<%s> diskId:%d success.", probePath, ds.DiskID)
 }
+
+/*
+ *  method:         POST
+ *  url:            /disk/datakey/rotate
+ *  note:           rewrap data keys of all disks by the active cluster key
+ */
+func (s *Service) DiskDataKeyRotate(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
+	if !s.Conf.DataEncryption.Enable {
+		span.Warnf("data encryption is not enabled")
+		c.RespondError(bloberr.ErrRequestNotAllow)
+		return
+	}
+
+	clusterKeys, err := s.loadClusterKeys(ctx)
+	if err != nil {
+		span.Errorf("Failed load cluster keys, err:%v", err)
+		c.RespondError(err)
+		return
+	}
+
+	disks := s.copyDiskStorages(ctx)
+	for _, ds := range disks {
+		version, err := core.RewrapDataKey(ctx, ds.GetConfig().Path, ds.ID(), clusterKeys)
+		if err != nil {
+			span.Errorf("Failed rewrap data key of disk:%d, err:%v", ds.ID(), err)
+			c.RespondError(err)
+			return
+		}
+		ds.GetConfig().SetDataKeyVersion(version)
+	}
+
+	span.Infof("rotate data key of %d disks to version:%d success", len(disks), clusterKeys.Active)
+}
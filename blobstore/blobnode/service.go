@@ -124,6 +124,7 @@ func NewHandler(service *Service) *rpc.Router {
 
 	r.Handle(http.MethodGet, "/disk/stat/diskid/:diskid", service.DiskStat, rpc.OptArgsURI())
 	r.Handle(http.MethodPost, "/disk/probe", service.DiskProbe, rpc.OptArgsBody())
+	r.Handle(http.MethodPost, "/disk/datakey/rotate", service.DiskDataKeyRotate)
 
 	r.Handle(http.MethodPost, "/chunk/inspect/diskid/:diskid/vuid/:vuid", service.ChunkInspect, rpc.OptArgsURI())
 	r.Handle(http.MethodPost, "/chunk/create/diskid/:diskid/vuid/:vuid", service.ChunkCreate, rpc.OptArgsURI(), rpc.OptArgsQuery())
@@ -203,6 +203,9 @@ func (s *Service) fixDiskConf(config *core.Config) {
 	config.AllocDiskID = s.ClusterMgrClient.AllocDiskID
 	config.NotifyCompacting = s.ClusterMgrClient.SetCompactChunk
 	config.HandleIOError = s.handleDiskIOError
+	if s.Conf.DataEncryption.Enable {
+		config.LoadClusterKeys = s.loadClusterKeys
+	}
 
 	// init configs
 	config.RuntimeConfig = s.Conf.DiskConfig
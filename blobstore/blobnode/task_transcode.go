@@ -0,0 +1,352 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package blobnode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	api "github.com/cubefs/cubefs/blobstore/api/blobnode"
+	"github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/blobnode/client"
+	"github.com/cubefs/cubefs/blobstore/common/ec"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/limit"
+	"github.com/cubefs/cubefs/blobstore/util/limit/count"
+)
+
+var (
+	errSrcNotLocked     = errors.New("source volume is not locked")
+	errBlobUnreadable   = errors.New("blob can not be read from the source volume")
+	errShardReadFailed  = errors.New("read shards from the source volume failed")
+	errUnexpectTaskStat = errors.New("unexpect transcode task state")
+)
+
+// TranscodeTaskMgr transcode task manager
+type TranscodeTaskMgr struct {
+	taskLimit   limit.Limiter
+	blobnodeCli client.IBlobNode
+	reporter    scheduler.ITranscoder
+}
+
+// NewTranscodeTaskMgr returns transcode task manager
+func NewTranscodeTaskMgr(concurrency int, blobnodeCli client.IBlobNode, reporter scheduler.ITranscoder) *TranscodeTaskMgr {
+	return &TranscodeTaskMgr{
+		taskLimit:   count.New(concurrency),
+		blobnodeCli: blobnodeCli,
+		reporter:    reporter,
+	}
+}
+
+// AddTask adds transcode task
+func (mgr *TranscodeTaskMgr) AddTask(ctx context.Context, task *proto.TranscodeTask) error {
+	span := trace.SpanFromContextSafe(ctx)
+	if err := mgr.taskLimit.Acquire(); err != nil {
+		return err
+	}
+
+	go func() {
+		defer mgr.taskLimit.Release()
+		ret := mgr.doTask(ctx, task)
+		if err := mgr.reporter.CompleteTranscodeTask(ctx, ret); err != nil {
+			span.Errorf("report transcode result failed: result[%+v], err[%+v]", ret, err)
+		}
+		span.Debugf("finish transcode: taskID[%s], state[%d]", task.TaskID, task.State)
+	}()
+	return nil
+}
+
+// RunningTaskSize returns running transcode task size
+func (mgr *TranscodeTaskMgr) RunningTaskSize() int {
+	return mgr.taskLimit.Running()
+}
+
+func (mgr *TranscodeTaskMgr) doTask(ctx context.Context, task *proto.TranscodeTask) *proto.TranscodeRet {
+	ret := &proto.TranscodeRet{TaskID: task.TaskID, State: task.State}
+
+	var err error
+	switch task.State {
+	case proto.TranscodeStatePrepared:
+		ret.BlobCnt, ret.DataSize, err = mgr.copyBlobs(ctx, task)
+	case proto.TranscodeStateCopied:
+		err = mgr.cleanSources(ctx, task)
+	default:
+		err = errUnexpectTaskStat
+	}
+	if err != nil {
+		ret.ErrStr = err.Error()
+	}
+	return ret
+}
+
+// copyBlobs re-encodes all blobs of the source volume into the destination volume,
+// the data of a blob in the destination is the data shards of the source joined together.
+func (mgr *TranscodeTaskMgr) copyBlobs(ctx context.Context, task *proto.TranscodeTask) (blobCnt, dataSize int64, err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	// the source volume should be readonly, so that none of the blobs is modified while copying
+	if !majorityLocked(ctx, mgr.blobnodeCli, task.Sources, task.SrcCodeMode) {
+		return 0, 0, errSrcNotLocked
+	}
+
+	srcEncoder, err := ec.NewEncoder(ec.Config{CodeMode: task.SrcCodeMode.Tactic()})
+	if err != nil {
+		return
+	}
+	dstEncoder, err := ec.NewEncoder(ec.Config{CodeMode: task.DstCodeMode.Tactic(), EnableVerify: true})
+	if err != nil {
+		return
+	}
+
+	replicasBids := GetReplicasBids(ctx, mgr.blobnodeCli, task.Sources)
+	for _, bid := range MergeBids(replicasBids) {
+		if blobMarkDeleted(replicasBids, bid.Bid) {
+			span.Debugf("skip mark deleted blob: vid[%d], bid[%d]", task.SrcVid, bid.Bid)
+			continue
+		}
+
+		data, err := mgr.readBlob(ctx, task, srcEncoder, replicasBids, bid)
+		if err != nil {
+			if err == errBlobUnreadable {
+				// blob was never written successfully
+				span.Warnf("blob may be lost and skip it: vid[%d], bid[%d]", task.SrcVid, bid.Bid)
+				continue
+			}
+			span.Errorf("read blob failed: vid[%d], bid[%d], err[%+v]", task.SrcVid, bid.Bid, err)
+			return 0, 0, err
+		}
+		if err = mgr.writeBlob(ctx, task, dstEncoder, bid.Bid, data); err != nil {
+			span.Errorf("write blob failed: vid[%d], bid[%d], err[%+v]", task.DstVid, bid.Bid, err)
+			return 0, 0, err
+		}
+		blobCnt++
+		dataSize += int64(len(data))
+	}
+	span.Infof("copy blobs success: task_id[%s], blob cnt[%d], data size[%d]", task.TaskID, blobCnt, dataSize)
+	return blobCnt, dataSize, nil
+}
+
+// readBlob returns the data shards of the blob joined together
+func (mgr *TranscodeTaskMgr) readBlob(ctx context.Context, task *proto.TranscodeTask, encoder ec.Encoder,
+	replicasBids map[proto.Vuid]*ReplicaBidsRet, bid *ShardInfoSimple) ([]byte, error) {
+	tactic := task.SrcCodeMode.Tactic()
+	if bid.Size == 0 {
+		return []byte{}, nil
+	}
+
+	shards := make([][]byte, tactic.N+tactic.M+tactic.L)
+	failed := mgr.getShards(ctx, task.Sources[:tactic.N], replicasBids, bid, shards)
+	var badIdx []int
+	for idx := 0; idx < tactic.N; idx++ {
+		if shards[idx] == nil {
+			badIdx = append(badIdx, idx)
+		}
+	}
+
+	if len(badIdx) > 0 {
+		if mgr.getShards(ctx, task.Sources[tactic.N:tactic.N+tactic.M], replicasBids, bid, shards) {
+			failed = true
+		}
+		for idx := tactic.N; idx < tactic.N+tactic.M; idx++ {
+			if shards[idx] == nil {
+				badIdx = append(badIdx, idx)
+			}
+		}
+		if len(badIdx) > tactic.M {
+			if failed {
+				return nil, errShardReadFailed
+			}
+			return nil, errBlobUnreadable
+		}
+		if err := encoder.ReconstructData(shards, badIdx); err != nil {
+			return nil, err
+		}
+	}
+
+	data := make([]byte, 0, int64(tactic.N)*bid.Size)
+	for _, shard := range encoder.GetDataShards(shards) {
+		data = append(data, shard...)
+	}
+	return data, nil
+}
+
+// getShards downloads the shards of the units, the shard is nil if missed or failed,
+// returns true if any of the listed shards failed to be downloaded
+func (mgr *TranscodeTaskMgr) getShards(ctx context.Context, units []proto.VunitLocation,
+	replicasBids map[proto.Vuid]*ReplicaBidsRet, bid *ShardInfoSimple, shards [][]byte) (failed bool) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	var mu sync.Mutex
+	wg := sync.WaitGroup{}
+	for idx := range units {
+		unit := units[idx]
+		replBids, ok := replicasBids[unit.Vuid]
+		if !ok || replBids.RetErr != nil {
+			mu.Lock()
+			failed = true
+			mu.Unlock()
+			continue
+		}
+		if info, ok := replBids.Bids[bid.Bid]; !ok || !info.Normal() {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shard, err := mgr.getShard(ctx, unit, bid)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				span.Warnf("get shard failed: location[%+v], bid[%d], err[%+v]", unit, bid.Bid, err)
+				failed = true
+				return
+			}
+			shards[unit.Vuid.Index()] = shard
+		}()
+	}
+	wg.Wait()
+	return
+}
+
+func (mgr *TranscodeTaskMgr) getShard(ctx context.Context, location proto.VunitLocation, bid *ShardInfoSimple) ([]byte, error) {
+	body, _, err := mgr.blobnodeCli.GetShard(ctx, location, bid.Bid, api.BackgroundIO)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	shard := make([]byte, bid.Size)
+	if _, err = io.ReadFull(body, shard); err != nil {
+		return nil, err
+	}
+	return shard, nil
+}
+
+// writeBlob encodes the data in the destination code mode and puts the shards with the same bid
+func (mgr *TranscodeTaskMgr) writeBlob(ctx context.Context, task *proto.TranscodeTask, encoder ec.Encoder,
+	bid proto.BlobID, data []byte) error {
+	tactic := task.DstCodeMode.Tactic()
+
+	shards := make([][]byte, tactic.N+tactic.M+tactic.L)
+	if len(data) > 0 {
+		sizes, err := ec.GetBufferSizes(len(data), tactic)
+		if err != nil {
+			return err
+		}
+		buf := make([]byte, sizes.ECSize)
+		copy(buf, data)
+		if shards, err = encoder.Split(buf[:sizes.ECDataSize]); err != nil {
+			return err
+		}
+		if err = encoder.Encode(shards); err != nil {
+			return err
+		}
+	}
+
+	errs := make([]error, len(task.Destinations))
+	wg := sync.WaitGroup{}
+	for idx := range task.Destinations {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			shard := shards[idx]
+			errs[idx] = mgr.blobnodeCli.PutShard(ctx, task.Destinations[idx], bid, int64(len(shard)),
+				bytes.NewReader(shard), api.BackgroundIO)
+		}(idx)
+	}
+	wg.Wait()
+
+	for idx, err := range errs {
+		if err != nil {
+			return fmt.Errorf("put shard failed: location[%+v], err[%w]", task.Destinations[idx], err)
+		}
+	}
+	return nil
+}
+
+// cleanSources deletes all of the shards in the source volume, which has been redirected.
+// the chunks are set readwrite for deleting and readonly again after that.
+func (mgr *TranscodeTaskMgr) cleanSources(ctx context.Context, task *proto.TranscodeTask) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	errs := make([]error, len(task.Sources))
+	wg := sync.WaitGroup{}
+	for idx := range task.Sources {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			errs[idx] = mgr.cleanChunk(ctx, task.Sources[idx])
+		}(idx)
+	}
+	wg.Wait()
+
+	for idx, err := range errs {
+		if err != nil {
+			span.Errorf("clean source chunk failed: location[%+v], err[%+v]", task.Sources[idx], err)
+			return err
+		}
+	}
+	span.Infof("clean source volume success: task_id[%s], vid[%d]", task.TaskID, task.SrcVid)
+	return nil
+}
+
+func (mgr *TranscodeTaskMgr) cleanChunk(ctx context.Context, location proto.VunitLocation) (err error) {
+	if err = mgr.blobnodeCli.SetChunkReadwrite(ctx, location); err != nil {
+		return
+	}
+	defer func() {
+		if errReadonly := mgr.blobnodeCli.SetChunkReadonly(ctx, location); err == nil {
+			err = errReadonly
+		}
+	}()
+
+	shards, err := mgr.blobnodeCli.ListShards(ctx, location)
+	if err != nil {
+		return
+	}
+	for _, shard := range shards {
+		if !shard.MarkDeleted() {
+			err = mgr.blobnodeCli.MarkDeleteShard(ctx, location, shard.Bid)
+			if err != nil && rpc.DetectStatusCode(err) != errcode.CodeShardMarkDeleted {
+				return
+			}
+		}
+		err = mgr.blobnodeCli.DeleteShard(ctx, location, shard.Bid)
+		if err != nil && rpc.DetectStatusCode(err) != errcode.CodeBidNotFound {
+			return
+		}
+	}
+	return nil
+}
+
+func blobMarkDeleted(replicasBids map[proto.Vuid]*ReplicaBidsRet, bid proto.BlobID) bool {
+	for _, replBids := range replicasBids {
+		if replBids.RetErr != nil {
+			continue
+		}
+		if info, ok := replBids.Bids[bid]; ok && info.MarkDeleted() {
+			return true
+		}
+	}
+	return false
+}
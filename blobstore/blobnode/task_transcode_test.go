@@ -0,0 +1,196 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package blobnode
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	api "github.com/cubefs/cubefs/blobstore/api/blobnode"
+	"github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/ec"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+func newMockTranscodeReporter(t *testing.T) scheduler.ITranscoder {
+	cli := mocks.NewMockIScheduler(C(t))
+	cli.EXPECT().CompleteTranscodeTask(A, A).AnyTimes().Return(nil)
+	return cli
+}
+
+func newMockTranscodeTask(srcMode, dstMode codemode.CodeMode) (*proto.TranscodeTask, *MockGetter) {
+	task := &proto.TranscodeTask{
+		TaskID:       "transcode-1-xxx",
+		State:        proto.TranscodeStatePrepared,
+		SrcVid:       1,
+		SrcCodeMode:  srcMode,
+		Sources:      genMockVol(1, srcMode),
+		DstVid:       2,
+		DstCodeMode:  dstMode,
+		Destinations: genMockVol(2, dstMode),
+	}
+	getter := NewMockGetter(task.Sources, srcMode)
+	for _, dst := range task.Destinations {
+		getter.vunits[dst.Vuid] = newMockVunit(dst.Vuid, api.ChunkStatusNormal)
+	}
+	return task, getter
+}
+
+func verifyTranscodeBlobs(t *testing.T, task *proto.TranscodeTask, getter *MockGetter, bids []proto.BlobID) {
+	srcTactic := task.SrcCodeMode.Tactic()
+	dstTactic := task.DstCodeMode.Tactic()
+	for _, bid := range bids {
+		var expected []byte
+		for _, src := range task.Sources[:srcTactic.N] {
+			expected = append(expected, getter.vunits[src.Vuid].shards[bid]...)
+		}
+		var actual []byte
+		shards := make([][]byte, 0, len(task.Destinations))
+		for _, dst := range task.Destinations {
+			shards = append(shards, getter.vunits[dst.Vuid].shards[bid])
+		}
+		for _, shard := range shards[:dstTactic.N] {
+			actual = append(actual, shard...)
+		}
+		if len(expected) == 0 {
+			require.Equal(t, 0, len(actual))
+			continue
+		}
+		sizes, err := ec.GetBufferSizes(len(expected), dstTactic)
+		require.NoError(t, err)
+		require.Equal(t, sizes.ECDataSize, len(actual))
+		require.True(t, bytes.Equal(expected, actual[:len(expected)]))
+
+		encoder, err := ec.NewEncoder(ec.Config{CodeMode: dstTactic})
+		require.NoError(t, err)
+		ok, err := encoder.Verify(shards)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+}
+
+func TestTranscodeTaskCopy(t *testing.T) {
+	testWithAllMode(t, func(t *testing.T, mode codemode.CodeMode) {
+		dstMode := codemode.EC6P6
+		if mode == dstMode {
+			dstMode = codemode.EC3P3
+		}
+		testTranscodeTaskCopy(t, mode, dstMode)
+	})
+}
+
+func testTranscodeTaskCopy(t *testing.T, srcMode, dstMode codemode.CodeMode) {
+	ctx := context.Background()
+	task, getter := newMockTranscodeTask(srcMode, dstMode)
+	mgr := NewTranscodeTaskMgr(1, getter, newMockTranscodeReporter(t))
+
+	ret := mgr.doTask(ctx, task)
+	require.NoError(t, ret.Err())
+	require.Equal(t, int64(len(getter.getBids())), ret.BlobCnt)
+	verifyTranscodeBlobs(t, task, getter, getter.getBids())
+
+	// skip the mark deleted blob
+	getter.MarkDelete(ctx, task.Sources[0].Vuid, 1)
+	ret = mgr.doTask(ctx, task)
+	require.NoError(t, ret.Err())
+	require.Equal(t, int64(len(getter.getBids())-1), ret.BlobCnt)
+
+	// reconstruct the missed data shards
+	srcTactic := srcMode.Tactic()
+	for _, src := range task.Sources[:srcTactic.M] {
+		getter.Delete(ctx, src.Vuid, 2)
+	}
+	ret = mgr.doTask(ctx, task)
+	require.NoError(t, ret.Err())
+	require.Equal(t, int64(len(getter.getBids())-1), ret.BlobCnt)
+	verifyTranscodeBlobs(t, task, getter, []proto.BlobID{2})
+
+	// skip the blob which is lost
+	getter.Delete(ctx, task.Sources[srcTactic.M].Vuid, 2)
+	ret = mgr.doTask(ctx, task)
+	require.NoError(t, ret.Err())
+	require.Equal(t, int64(len(getter.getBids())-2), ret.BlobCnt)
+
+	// failed to read
+	for _, src := range task.Sources[:srcTactic.M+1] {
+		getter.setFail(src.Vuid, errors.New("fake error"))
+	}
+	ret = mgr.doTask(ctx, task)
+	require.EqualError(t, ret.Err(), errShardReadFailed.Error())
+	for _, src := range task.Sources[:srcTactic.M+1] {
+		getter.setWell(src.Vuid)
+	}
+
+	// failed to write
+	getter.setFail(task.Destinations[0].Vuid, errors.New("fake error"))
+	ret = mgr.doTask(ctx, task)
+	require.Error(t, ret.Err())
+	getter.setWell(task.Destinations[0].Vuid)
+
+	// source volume is not locked
+	for _, src := range task.Sources {
+		getter.setVunitStatus(src.Vuid, api.ChunkStatusNormal)
+	}
+	ret = mgr.doTask(ctx, task)
+	require.EqualError(t, ret.Err(), errSrcNotLocked.Error())
+}
+
+func TestTranscodeTaskClean(t *testing.T) {
+	ctx := context.Background()
+	task, getter := newMockTranscodeTask(codemode.EC6P10L2, codemode.EC6P6)
+	mgr := NewTranscodeTaskMgr(1, getter, newMockTranscodeReporter(t))
+
+	task.State = proto.TranscodeStateCopied
+	getter.MarkDelete(ctx, task.Sources[1].Vuid, 1)
+	getter.setFail(task.Sources[0].Vuid, errors.New("fake error"))
+	ret := mgr.doTask(ctx, task)
+	require.Error(t, ret.Err())
+	getter.setWell(task.Sources[0].Vuid)
+
+	ret = mgr.doTask(ctx, task)
+	require.NoError(t, ret.Err())
+	for _, src := range task.Sources {
+		shards, err := getter.ListShards(ctx, src)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(shards))
+		ci, err := getter.StatChunk(ctx, src)
+		require.NoError(t, err)
+		require.True(t, ci.Locked())
+	}
+
+	task.State = proto.TranscodeStateFinished
+	ret = mgr.doTask(ctx, task)
+	require.EqualError(t, ret.Err(), errUnexpectTaskStat.Error())
+}
+
+func TestTranscodeTaskMgrAddTask(t *testing.T) {
+	task, getter := newMockTranscodeTask(codemode.EC6P10L2, codemode.EC6P6)
+	mgr := NewTranscodeTaskMgr(1, getter, newMockTranscodeReporter(t))
+
+	err := mgr.AddTask(context.Background(), task)
+	require.NoError(t, err)
+
+	err = mgr.AddTask(context.Background(), task)
+	require.Error(t, err)
+
+	taskCnt := mgr.RunningTaskSize()
+	require.Equal(t, 1, taskCnt)
+}
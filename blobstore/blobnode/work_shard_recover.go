@@ -23,6 +23,8 @@ import (
 	"sync"
 	"unsafe"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/cubefs/cubefs/blobstore/api/blobnode"
 	"github.com/cubefs/cubefs/blobstore/blobnode/base/workutils"
 	"github.com/cubefs/cubefs/blobstore/blobnode/client"
@@ -50,6 +52,37 @@ var (
 
 const defaultGetConcurrency = 100
 
+// stripe of recovering shards
+const (
+	stripeLocal  = "local"
+	stripeGlobal = "global"
+)
+
+var (
+	shardRecoverMetric = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "blobstore",
+			Subsystem: "blobnode",
+			Name:      "shard_recover_bids",
+			Help:      "blobnode recovered bids by local or global stripe",
+		},
+		[]string{"task_type", "codemode", "stripe"},
+	)
+	localRecoverSavedBytesMetric = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "blobstore",
+			Subsystem: "blobnode",
+			Name:      "local_recover_cross_az_saved_bytes",
+			Help:      "blobnode bytes saved from reading across AZs by local stripe recovery",
+		},
+		[]string{"task_type", "codemode"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(shardRecoverMetric, localRecoverSavedBytesMetric)
+}
+
 type downloadPlan struct {
 	downloadReplicas Vunits
 }
@@ -473,7 +506,9 @@ func (r *ShardRecover) recoverReplicaShards(ctx context.Context, repairIdxs []ui
 			span.Warnf("recover by local stripe failed:%v", err)
 		}
 
-		failBids = r.collectFailBids(failBids, repairIdxs)
+		localFailBids := r.collectFailBids(failBids, repairIdxs)
+		r.reportRecovered(stripeLocal, failBids, localFailBids)
+		failBids = localFailBids
 		if len(failBids) == 0 {
 			return nil
 		}
@@ -487,7 +522,9 @@ func (r *ShardRecover) recoverReplicaShards(ctx context.Context, repairIdxs []ui
 		return err
 	}
 
-	failBids = r.collectFailBids(failBids, repairIdxs)
+	globalFailBids := r.collectFailBids(failBids, repairIdxs)
+	r.reportRecovered(stripeGlobal, failBids, globalFailBids)
+	failBids = globalFailBids
 	if len(failBids) != 0 {
 		span.Errorf("recoverReplicaShards failed: failBids len[%d]", len(failBids))
 		return errBidCanNotRecover
@@ -872,6 +909,40 @@ func AllShardsCanNotDownload(shardDownloadFail error) bool {
 	}
 }
 
+// reportRecovered reports bids recovered by the stripe, a local stripe is placed in one AZ,
+// and the global stripe downloads N shards of which (AZCount-1)/AZCount are in other AZs,
+// so the bytes of these shards are saved from reading across AZs by local recovery.
+func (r *ShardRecover) reportRecovered(stripe string, repairBids, failBids []proto.BlobID) {
+	if len(repairBids) == len(failBids) {
+		return
+	}
+	failed := make(map[proto.BlobID]struct{}, len(failBids))
+	for _, bid := range failBids {
+		failed[bid] = struct{}{}
+	}
+	recovered := make(map[proto.BlobID]struct{}, len(repairBids))
+	for _, bid := range repairBids {
+		if _, ok := failed[bid]; !ok {
+			recovered[bid] = struct{}{}
+		}
+	}
+
+	tactic := r.codeMode.Tactic()
+	crossAZShards := int64(tactic.N * (tactic.AZCount - 1) / tactic.AZCount)
+	var savedBytes int64
+	for _, info := range r.repairBidsReadOnly {
+		if _, ok := recovered[info.Bid]; ok {
+			savedBytes += info.Size * crossAZShards
+		}
+	}
+
+	taskType, codeMode := r.taskType.String(), r.codeMode.String()
+	shardRecoverMetric.WithLabelValues(taskType, codeMode, stripe).Add(float64(len(recovered)))
+	if stripe == stripeLocal && savedBytes > 0 {
+		localRecoverSavedBytesMetric.WithLabelValues(taskType, codeMode).Add(float64(savedBytes))
+	}
+}
+
 func localRepairable(badIdxs []uint8, mode codemode.CodeMode) bool {
 	// localMap use count each az bad uint num
 	localMap := make(map[int]int)
@@ -20,6 +20,8 @@ import (
 	"hash/crc32"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cubefs/cubefs/blobstore/blobnode/base/workutils"
@@ -208,6 +210,44 @@ func TestRecoverLocalReplicaShards(t *testing.T) {
 	testCheckData(t, repair, getter, badi)
 }
 
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, counter.Write(m))
+	return m.GetCounter().GetValue()
+}
+
+func TestRecoverReportStripe(t *testing.T) {
+	mode := codemode.EC6P10L2
+	taskType := proto.TaskTypeShardRepair.String()
+	localBids := shardRecoverMetric.WithLabelValues(taskType, mode.String(), stripeLocal)
+	globalBids := shardRecoverMetric.WithLabelValues(taskType, mode.String(), stripeGlobal)
+	savedBytes := localRecoverSavedBytesMetric.WithLabelValues(taskType, mode.String())
+
+	// one shard lost in local stripe
+	repair, bidInfos, getter, _ := InitMockRepair(mode)
+	local, global, saved := counterValue(t, localBids), counterValue(t, globalBids), counterValue(t, savedBytes)
+	badi := []uint8{0}
+	require.NoError(t, repair.recoverReplicaShards(context.Background(), badi, GetBids(bidInfos)))
+	testCheckData(t, repair, getter, badi)
+	var size int64
+	for _, info := range bidInfos {
+		size += info.Size
+	}
+	require.Equal(t, local+float64(len(bidInfos)), counterValue(t, localBids))
+	require.Equal(t, global, counterValue(t, globalBids))
+	require.Equal(t, saved+float64(size*3), counterValue(t, savedBytes))
+
+	// too many shards lost in local stripe
+	repair, bidInfos, getter, _ = InitMockRepair(mode)
+	local, global, saved = counterValue(t, localBids), counterValue(t, globalBids), counterValue(t, savedBytes)
+	badi = []uint8{0, 1}
+	require.NoError(t, repair.recoverReplicaShards(context.Background(), badi, GetBids(bidInfos)))
+	testCheckData(t, repair, getter, badi)
+	require.Equal(t, local, counterValue(t, localBids))
+	require.Equal(t, global+float64(len(bidInfos)), counterValue(t, globalBids))
+	require.Equal(t, saved, counterValue(t, savedBytes))
+}
+
 func TestRecoverShards(t *testing.T) {
 	ctx := context.Background()
 	repair1, _, getter2, _ := InitMockRepair(codemode.EC15P12)
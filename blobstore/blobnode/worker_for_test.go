@@ -29,6 +29,7 @@ import (
 	api "github.com/cubefs/cubefs/blobstore/api/blobnode"
 	"github.com/cubefs/cubefs/blobstore/blobnode/client"
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	_ "github.com/cubefs/cubefs/blobstore/testing/nolog"
 	"github.com/cubefs/cubefs/blobstore/util/errors"
@@ -218,6 +219,34 @@ func (getter *MockGetter) Delete(ctx context.Context, vuid proto.Vuid, bid proto
 	getter.vunits[vuid].delete(bid)
 }
 
+func (getter *MockGetter) MarkDeleteShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (err error) {
+	getter.mu.Lock()
+	defer getter.mu.Unlock()
+	if err, ok := getter.failVuid[location.Vuid]; ok {
+		return err
+	}
+	return getter.vunits[location.Vuid].markDeleteShard(bid)
+}
+
+func (getter *MockGetter) DeleteShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (err error) {
+	getter.mu.Lock()
+	defer getter.mu.Unlock()
+	if err, ok := getter.failVuid[location.Vuid]; ok {
+		return err
+	}
+	return getter.vunits[location.Vuid].deleteShard(bid)
+}
+
+func (getter *MockGetter) SetChunkReadonly(ctx context.Context, location proto.VunitLocation) (err error) {
+	getter.setVunitStatus(location.Vuid, api.ChunkStatusReadOnly)
+	return
+}
+
+func (getter *MockGetter) SetChunkReadwrite(ctx context.Context, location proto.VunitLocation) (err error) {
+	getter.setVunitStatus(location.Vuid, api.ChunkStatusNormal)
+	return
+}
+
 func (getter *MockGetter) StatShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (si *client.ShardInfo, err error) {
 	getter.mu.Lock()
 	defer getter.mu.Unlock()
@@ -382,6 +411,41 @@ func (m *mockVunit) markDelete(bid proto.BlobID) {
 	m.bidInfos[bid].Flag = api.ShardStatusMarkDelete
 }
 
+func (m *mockVunit) markDeleteShard(bid proto.BlobID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.status == api.ChunkStatusReadOnly {
+		return errcode.ErrReadonlyVUID
+	}
+	info, ok := m.bidInfos[bid]
+	if !ok {
+		return errcode.ErrNoSuchBid
+	}
+	if info.Flag == api.ShardStatusMarkDelete {
+		return errcode.ErrShardMarkDeleted
+	}
+	info.Flag = api.ShardStatusMarkDelete
+	return nil
+}
+
+func (m *mockVunit) deleteShard(bid proto.BlobID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.status == api.ChunkStatusReadOnly {
+		return errcode.ErrReadonlyVUID
+	}
+	info, ok := m.bidInfos[bid]
+	if !ok {
+		return errcode.ErrNoSuchBid
+	}
+	if info.Flag != api.ShardStatusMarkDelete {
+		return errcode.ErrShardNotMarkDelete
+	}
+	delete(m.bidInfos, bid)
+	delete(m.shards, bid)
+	return nil
+}
+
 func (m *mockVunit) recover(bid proto.BlobID) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
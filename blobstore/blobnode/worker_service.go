@@ -50,6 +50,8 @@ type WorkerConfigMeter struct {
 	ShardRepairConcurrency int `json:"shard_repair_concurrency"`
 	// volume inspect concurrency
 	InspectConcurrency int `json:"inspect_concurrency"`
+	// volume transcode concurrency
+	TranscodeConcurrency int `json:"transcode_concurrency"`
 
 	// batch download concurrency of single tasklet
 	DownloadShardConcurrency int `json:"download_shard_concurrency"`
@@ -93,8 +95,9 @@ type WorkerService struct {
 	closer.Closer
 	WorkerConfig
 
-	taskRunnerMgr  *TaskRunnerMgr
-	inspectTaskMgr *InspectTaskMgr
+	taskRunnerMgr    *TaskRunnerMgr
+	inspectTaskMgr   *InspectTaskMgr
+	transcodeTaskMgr *TranscodeTaskMgr
 
 	shardRepairLimit limit.Limiter
 	shardRepairer    *ShardRepairer
@@ -112,6 +115,7 @@ func (cfg *WorkerConfig) checkAndFix() {
 	fixConfigItemInt(&cfg.ManualMigrateConcurrency, 10)
 	fixConfigItemInt(&cfg.ShardRepairConcurrency, 1)
 	fixConfigItemInt(&cfg.InspectConcurrency, 1)
+	fixConfigItemInt(&cfg.TranscodeConcurrency, 1)
 	fixConfigItemInt(&cfg.DownloadShardConcurrency, 10)
 	fixConfigItemInt64(&cfg.Scheduler.ClientTimeoutMs, 1000)
 	fixConfigItemInt64(&cfg.Scheduler.HostSyncIntervalMs, 1000)
@@ -144,6 +148,7 @@ func NewWorkerService(cfg *WorkerConfig, service cmapi.APIService, clusterID pro
 	renewalCli := scheduler.New(&renewalConfig, service, clusterID)
 	taskRunnerMgr := NewTaskRunnerMgr(idc, cfg.WorkerConfigMeter, NewMigrateWorker, renewalCli, schedulerCli)
 	inspectTaskMgr := NewInspectTaskMgr(cfg.InspectConcurrency, blobNodeCli, schedulerCli)
+	transcodeTaskMgr := NewTranscodeTaskMgr(cfg.TranscodeConcurrency, blobNodeCli, schedulerCli)
 
 	shardRepairLimit := count.New(cfg.ShardRepairConcurrency)
 	shardRepairer := NewShardRepairer(blobNodeCli)
@@ -159,10 +164,11 @@ func NewWorkerService(cfg *WorkerConfig, service cmapi.APIService, clusterID pro
 		Closer:       closer.New(),
 		WorkerConfig: *cfg,
 
-		schedulerCli:   schedulerCli,
-		blobNodeCli:    blobNodeCli,
-		taskRunnerMgr:  taskRunnerMgr,
-		inspectTaskMgr: inspectTaskMgr,
+		schedulerCli:     schedulerCli,
+		blobNodeCli:      blobNodeCli,
+		taskRunnerMgr:    taskRunnerMgr,
+		inspectTaskMgr:   inspectTaskMgr,
+		transcodeTaskMgr: transcodeTaskMgr,
 
 		shardRepairLimit: shardRepairLimit,
 		shardRepairer:    shardRepairer,
@@ -228,6 +234,10 @@ func (s *WorkerService) tryAcquireTask() {
 	if s.hasInspectTaskResource() {
 		s.acquireInspectTask()
 	}
+
+	if s.hasTranscodeTaskResource() {
+		s.acquireTranscodeTask()
+	}
 }
 
 func (s *WorkerService) hasTaskRunnerResource() bool {
@@ -246,6 +256,12 @@ func (s *WorkerService) hasInspectTaskResource() bool {
 	return inspectCnt < s.InspectConcurrency
 }
 
+func (s *WorkerService) hasTranscodeTaskResource() bool {
+	transcodeCnt := s.transcodeTaskMgr.RunningTaskSize()
+	log.Infof("transcode running task %d / %d", transcodeCnt, s.TranscodeConcurrency)
+	return transcodeCnt < s.TranscodeConcurrency
+}
+
 // acquire:disk repair & balance & disk drop task
 func (s *WorkerService) acquireTask() {
 	span, ctx := trace.StartSpanFromContext(context.Background(), "acquireTask")
@@ -300,3 +316,30 @@ func (s *WorkerService) acquireInspectTask() {
 
 	span.Infof("acquire inspect task success: taskID[%s] task[%+v]", t.TaskID, t)
 }
+
+// acquire transcode task
+func (s *WorkerService) acquireTranscodeTask() {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "acquireTranscodeTask")
+
+	t, err := s.schedulerCli.AcquireTranscodeTask(ctx)
+	if err != nil {
+		code := rpc.DetectStatusCode(err)
+		if code != errcode.CodeNotingTodo {
+			span.Errorf("acquire transcode task failed: code[%d], err[%v]", code, err)
+		}
+		return
+	}
+
+	if !t.IsValid() {
+		span.Errorf("transcode task is illegal: task[%+v]", t)
+		return
+	}
+
+	err = s.transcodeTaskMgr.AddTask(ctx, t)
+	if err != nil {
+		span.Errorf("add transcode task failed: taskID[%s], err[%v]", t.TaskID, err)
+		return
+	}
+
+	span.Infof("acquire transcode task success: taskID[%s], state[%d]", t.TaskID, t.State)
+}
@@ -64,6 +64,22 @@ func (m *mBlobNodeCli) PutShard(ctx context.Context, location proto.VunitLocatio
 	return
 }
 
+func (m *mBlobNodeCli) MarkDeleteShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (err error) {
+	return
+}
+
+func (m *mBlobNodeCli) DeleteShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (err error) {
+	return
+}
+
+func (m *mBlobNodeCli) SetChunkReadonly(ctx context.Context, location proto.VunitLocation) (err error) {
+	return
+}
+
+func (m *mBlobNodeCli) SetChunkReadwrite(ctx context.Context, location proto.VunitLocation) (err error) {
+	return
+}
+
 type mockScheCli struct {
 	*mocks.MockIScheduler
 
@@ -74,6 +90,8 @@ type mockScheCli struct {
 
 	inspectID  int
 	inspectCnt int
+
+	transcodeCnt int
 }
 
 func (m *mockScheCli) AcquireTask(ctx context.Context, args *scheduler.AcquireArgs) (ret *proto.MigrateTask, err error) {
@@ -123,6 +141,11 @@ func (m *mockScheCli) AcquireInspectTask(ctx context.Context) (ret *proto.Volume
 	return
 }
 
+func (m *mockScheCli) AcquireTranscodeTask(ctx context.Context) (ret *proto.TranscodeTask, err error) {
+	m.transcodeCnt++
+	return &proto.TranscodeTask{}, nil
+}
+
 func newMockWorkService(t *testing.T) (*Service, *mockScheCli) {
 	cli := mocks.NewMockIScheduler(C(t))
 	schedulerCli := &mockScheCli{MockIScheduler: cli}
@@ -133,8 +156,9 @@ func newMockWorkService(t *testing.T) (*Service, *mockScheCli) {
 		Closer: closer.New(),
 		WorkerConfig: WorkerConfig{
 			WorkerConfigMeter: WorkerConfigMeter{
-				MaxTaskRunnerCnt:   100,
-				InspectConcurrency: 1,
+				MaxTaskRunnerCnt:     100,
+				InspectConcurrency:   1,
+				TranscodeConcurrency: 1,
 			},
 			AcquireIntervalMs: 1,
 		},
@@ -143,8 +167,9 @@ func newMockWorkService(t *testing.T) (*Service, *mockScheCli) {
 		schedulerCli:     schedulerCli,
 		blobNodeCli:      blobnodeCli,
 
-		taskRunnerMgr:  NewTaskRunnerMgr("z0", getDefaultConfig().WorkerConfigMeter, NewMockMigrateWorker, schedulerCli, schedulerCli),
-		inspectTaskMgr: NewInspectTaskMgr(1, blobnodeCli, schedulerCli),
+		taskRunnerMgr:    NewTaskRunnerMgr("z0", getDefaultConfig().WorkerConfigMeter, NewMockMigrateWorker, schedulerCli, schedulerCli),
+		inspectTaskMgr:   NewInspectTaskMgr(1, blobnodeCli, schedulerCli),
+		transcodeTaskMgr: NewTranscodeTaskMgr(1, blobnodeCli, schedulerCli),
 	}
 	return &Service{WorkerService: workSvr}, schedulerCli
 }
@@ -185,6 +210,7 @@ func TestWorkerService(t *testing.T) {
 	require.Equal(t, schedulerCli.balanceTaskCnt, len(typeMgr[proto.TaskTypeBalance]))
 	require.Equal(t, schedulerCli.diskDropTaskCnt, len(typeMgr[proto.TaskTypeDiskDrop]))
 	require.Less(t, 2, schedulerCli.inspectCnt)
+	require.Less(t, 2, schedulerCli.transcodeCnt)
 }
 
 func TestNewWorkService(t *testing.T) {
@@ -217,6 +243,7 @@ func TestCfgFix(t *testing.T) {
 	fixConfigItemInt(&cfg.DiskDropConcurrency, 1)
 	fixConfigItemInt(&cfg.ShardRepairConcurrency, 1)
 	fixConfigItemInt(&cfg.InspectConcurrency, 1)
+	fixConfigItemInt(&cfg.TranscodeConcurrency, 1)
 	fixConfigItemInt(&cfg.DownloadShardConcurrency, 10)
 
 	require.Equal(t, 500, cfg.AcquireIntervalMs)
@@ -226,6 +253,7 @@ func TestCfgFix(t *testing.T) {
 	require.Equal(t, 1, cfg.DiskDropConcurrency)
 	require.Equal(t, 1, cfg.ShardRepairConcurrency)
 	require.Equal(t, 1, cfg.InspectConcurrency)
+	require.Equal(t, 1, cfg.TranscodeConcurrency)
 	require.Equal(t, 10, cfg.DownloadShardConcurrency)
 
 	cfg.AcquireIntervalMs = 600
@@ -39,6 +39,7 @@ var (
 		string(proto.TaskTypeVolumeInspect),
 		string(proto.TaskTypeShardRepair),
 		string(proto.TaskTypeBlobDelete),
+		string(proto.TaskTypeBlobReplicate),
 	}
 	BackgroundTaskTypeString = "[" + strings.Join(BackgroundTaskTypes, ", ") + "]"
 )
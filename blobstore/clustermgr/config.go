@@ -15,9 +15,12 @@
 package clustermgr
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 
+	"github.com/cubefs/cubefs/blobstore/api/blobnode"
 	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/configmgr"
@@ -73,6 +76,13 @@ func (s *Service) ConfigSet(c *rpc.Context) {
 		c.RespondError(apierrors.ErrIllegalArguments)
 		return
 	}
+	if args.Key == proto.DataEncryptionKeysConfigKey {
+		if err := s.checkDataEncryptionKeys(ctx, args.Value); err != nil {
+			span.Warnf("invalid data encryption keys: %v", err)
+			c.RespondError(errors.Info(apierrors.ErrIllegalArguments).Detail(err))
+			return
+		}
+	}
 
 	data, err := json.Marshal(args)
 	if err != nil {
@@ -119,3 +129,33 @@ func (s *Service) ConfigDelete(c *rpc.Context) {
 		return
 	}
 }
+
+// checkDataEncryptionKeys checks the new cluster keys, a version of key is never
+// changed, and the versions wrapping the data keys of disks are never removed.
+func (s *Service) checkDataEncryptionKeys(ctx context.Context, value string) error {
+	keys, err := blobnode.ParseClusterKeys([]byte(value))
+	if err != nil {
+		return err
+	}
+	var old *blobnode.ClusterKeys
+	oldValue, err := s.ConfigMgr.Get(ctx, proto.DataEncryptionKeysConfigKey)
+	if err != nil && err != os.ErrNotExist {
+		return err
+	}
+	if err == nil {
+		if old, err = blobnode.ParseClusterKeys([]byte(oldValue)); err != nil {
+			return err
+		}
+		for version := range old.Keys {
+			if _, ok := keys.Keys[version]; ok && !keys.Keeps(old, version) {
+				return fmt.Errorf("key of version %d changed", version)
+			}
+		}
+	}
+	for version, count := range s.DiskMgr.DataKeyVersions() {
+		if _, ok := keys.Keys[version]; !ok {
+			return fmt.Errorf("version %d is still used by %d disks", version, count)
+		}
+	}
+	return nil
+}
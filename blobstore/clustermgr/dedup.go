@@ -0,0 +1,136 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/kvmgr"
+	apierrors "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+)
+
+const maxBlobDedupHashLength = 128
+
+func isValidDedupHash(hash string) bool {
+	return hash != "" && len(hash) <= maxBlobDedupHashLength
+}
+
+// proposeBlobDedup proposes dedup operation which is applied in kv manager,
+// returns the applied blob, or nil if there is no such blob.
+func (s *Service) proposeBlobDedup(ctx context.Context, operType int32, args *kvmgr.BlobDedupCtx) (*clustermgr.BlobDedup, error) {
+	args.PendingKey = uuid.New().String()
+	s.KvMgr.AddPendingBlobDedup(args.PendingKey)
+	defer s.KvMgr.DeletePendingBlobDedup(args.PendingKey)
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.proposeKv(ctx, operType, data); err != nil {
+		return nil, err
+	}
+	return s.KvMgr.LoadPendingBlobDedup(args.PendingKey), nil
+}
+
+func (s *Service) BlobDedupAcquire(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.AcquireBlobDedupArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept BlobDedupAcquire request, args: %+v", args)
+
+	if !isValidDedupHash(args.Hash) {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+
+	blob, err := s.proposeBlobDedup(ctx, kvmgr.OperTypeAcquireBlobDedup, &kvmgr.BlobDedupCtx{Hash: args.Hash})
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	if blob == nil {
+		c.RespondError(apierrors.ErrNotFound)
+		return
+	}
+	c.RespondJSON(blob)
+}
+
+func (s *Service) BlobDedupRegister(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.RegisterBlobDedupArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept BlobDedupRegister request, args: %+v", args)
+
+	if !isValidDedupHash(args.Hash) || args.Vid == proto.InvalidVid || args.Bid == proto.InValidBlobID {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+
+	blob, err := s.proposeBlobDedup(ctx, kvmgr.OperTypeRegisterBlobDedup,
+		&kvmgr.BlobDedupCtx{Hash: args.Hash, Vid: args.Vid, Bid: args.Bid})
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	if blob == nil {
+		span.Errorf("register blob dedup without result, args: %+v", args)
+		c.RespondError(apierrors.ErrCMUnexpect)
+		return
+	}
+	c.RespondJSON(blob)
+}
+
+func (s *Service) BlobDedupRelease(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.ReleaseBlobDedupArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept BlobDedupRelease request, args: %+v", args)
+
+	if args.Vid == proto.InvalidVid {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+
+	blob, err := s.proposeBlobDedup(ctx, kvmgr.OperTypeReleaseBlobDedup,
+		&kvmgr.BlobDedupCtx{Vid: args.Vid, Bid: args.Bid, RefID: args.RefID})
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	ret := &clustermgr.ReleaseBlobDedupRet{}
+	if blob != nil {
+		ret.Refs = blob.Refs
+	}
+	c.RespondJSON(ret)
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+)
+
+func TestService_BlobDedup(t *testing.T) {
+	testService, clean := initServiceWithData()
+	defer clean()
+	cmClient := initTestClusterClient(testService)
+	ctx := newCtx()
+
+	// invalid arguments
+	_, err := cmClient.AcquireBlobDedup(ctx, &clustermgr.AcquireBlobDedupArgs{})
+	require.Error(t, err)
+	_, err = cmClient.RegisterBlobDedup(ctx, &clustermgr.RegisterBlobDedupArgs{Hash: "h", Vid: 1})
+	require.Error(t, err)
+	_, err = cmClient.ReleaseBlobDedup(ctx, &clustermgr.ReleaseBlobDedupArgs{})
+	require.Error(t, err)
+
+	_, err = cmClient.AcquireBlobDedup(ctx, &clustermgr.AcquireBlobDedupArgs{Hash: "h"})
+	require.Error(t, err)
+
+	blob, err := cmClient.RegisterBlobDedup(ctx, &clustermgr.RegisterBlobDedupArgs{Hash: "h", Vid: 1, Bid: 10})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), blob.Refs)
+	// the hash has been registered by another blob
+	blob, err = cmClient.RegisterBlobDedup(ctx, &clustermgr.RegisterBlobDedupArgs{Hash: "h", Vid: 2, Bid: 20})
+	require.NoError(t, err)
+	require.Equal(t, clustermgr.BlobDedup{Hash: "h", Vid: 1, Bid: 10, Refs: 1}, blob)
+
+	blob, err = cmClient.AcquireBlobDedup(ctx, &clustermgr.AcquireBlobDedupArgs{Hash: "h"})
+	require.NoError(t, err)
+	require.Equal(t, clustermgr.BlobDedup{Hash: "h", Vid: 1, Bid: 10, Refs: 2}, blob)
+
+	ret, err := cmClient.ReleaseBlobDedup(ctx, &clustermgr.ReleaseBlobDedupArgs{Vid: 1, Bid: 10, RefID: "req-0"})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), ret.Refs)
+	// redelivered release
+	ret, err = cmClient.ReleaseBlobDedup(ctx, &clustermgr.ReleaseBlobDedupArgs{Vid: 1, Bid: 10, RefID: "req-0"})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), ret.Refs)
+	ret, err = cmClient.ReleaseBlobDedup(ctx, &clustermgr.ReleaseBlobDedupArgs{Vid: 1, Bid: 10, RefID: "req-1"})
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), ret.Refs)
+	_, err = cmClient.AcquireBlobDedup(ctx, &clustermgr.AcquireBlobDedupArgs{Hash: "h"})
+	require.Error(t, err)
+
+	// not registered blob
+	ret, err = cmClient.ReleaseBlobDedup(ctx, &clustermgr.ReleaseBlobDedupArgs{Vid: 2, Bid: 20})
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), ret.Refs)
+}
@@ -526,6 +526,7 @@ func (d *DiskMgr) ListDiskInfo(ctx context.Context, opt *clustermgr.ListOptionAr
 		diskInfo.Free = disk.info.Free
 		diskInfo.Used = disk.info.Used
 		diskInfo.Size = disk.info.Size
+		diskInfo.DataKeyVersion = disk.info.DataKeyVersion
 		disk.lock.RUnlock()
 
 		ret.Disks = append(ret.Disks, diskInfo)
@@ -537,6 +538,19 @@ func (d *DiskMgr) ListDiskInfo(ctx context.Context, opt *clustermgr.ListOptionAr
 	return ret, nil
 }
 
+// DataKeyVersions returns the count of disks not dropped by the version of cluster key wrapping their data keys
+func (d *DiskMgr) DataKeyVersions() map[uint32]int {
+	versions := make(map[uint32]int)
+	for _, disk := range d.getAllDisk() {
+		disk.lock.RLock()
+		if disk.info.Status != proto.DiskStatusDropped && disk.info.DataKeyVersion > 0 {
+			versions[disk.info.DataKeyVersion]++
+		}
+		disk.lock.RUnlock()
+	}
+	return versions
+}
+
 // Stat return disk statistic info of a cluster
 func (d *DiskMgr) Stat(ctx context.Context) *clustermgr.SpaceStatInfo {
 	spaceStatInfo := d.spaceStatInfo.Load().(*clustermgr.SpaceStatInfo)
@@ -701,6 +715,7 @@ func (d *DiskMgr) heartBeatDiskInfo(ctx context.Context, infos []*blobnode.DiskH
 		diskInfo.info.Size = info.Size
 		diskInfo.info.Used = info.Used
 		diskInfo.info.UsedChunkCnt = info.UsedChunkCnt
+		diskInfo.info.DataKeyVersion = info.DataKeyVersion
 		// calculate free and max chunk count
 		diskInfo.info.MaxChunkCnt = info.Size / d.ChunkSize
 		// use the minimum value as free chunk count
@@ -782,6 +797,8 @@ func diskInfoToDiskInfoRecord(info *blobnode.DiskInfo) *normaldb.DiskInfoRecord
 		Free:         info.Free,
 		MaxChunkCnt:  info.MaxChunkCnt,
 		FreeChunkCnt: info.FreeChunkCnt,
+
+		DataKeyVersion: info.DataKeyVersion,
 	}
 }
 
@@ -804,6 +821,8 @@ func diskInfoRecordToDiskInfo(infoDB *normaldb.DiskInfoRecord) *blobnode.DiskInf
 			MaxChunkCnt:  infoDB.MaxChunkCnt,
 			UsedChunkCnt: infoDB.UsedChunkCnt,
 			FreeChunkCnt: infoDB.FreeChunkCnt,
+
+			DataKeyVersion: infoDB.DataKeyVersion,
 		},
 	}
 }
@@ -0,0 +1,139 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/kvmgr"
+	apierrors "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+const maxBlobExpireBatch = 1024
+
+func (s *Service) BlobExpireSet(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.SetBlobExpireArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept BlobExpireSet request, blobs: %d", len(args.Blobs))
+
+	if len(args.Blobs) == 0 || len(args.Blobs) > maxBlobExpireBatch {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	for _, blob := range args.Blobs {
+		if blob.Vid == proto.InvalidVid || blob.Count == 0 || blob.ExpireAt <= 0 {
+			c.RespondError(apierrors.ErrIllegalArguments)
+			return
+		}
+	}
+
+	for idx := range args.Blobs {
+		data, err := json.Marshal(&args.Blobs[idx])
+		if err != nil {
+			c.RespondError(err)
+			return
+		}
+		if err = s.proposeKv(ctx, kvmgr.OperTypeSetBlobExpire, data); err != nil {
+			c.RespondError(err)
+			return
+		}
+	}
+}
+
+func (s *Service) BlobExpireDelete(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.DeleteBlobExpireArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept BlobExpireDelete request, blobs: %d", len(args.Blobs))
+
+	if len(args.Blobs) == 0 || len(args.Blobs) > maxBlobExpireBatch {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+
+	for _, blob := range args.Blobs {
+		if blob.Vid == proto.InvalidVid || blob.ExpireAt <= 0 {
+			c.RespondError(apierrors.ErrIllegalArguments)
+			return
+		}
+	}
+
+	for idx := range args.Blobs {
+		data, err := json.Marshal(&args.Blobs[idx])
+		if err != nil {
+			c.RespondError(err)
+			return
+		}
+		if err = s.proposeKv(ctx, kvmgr.OperTypeDeleteBlobExpire, data); err != nil {
+			c.RespondError(err)
+			return
+		}
+	}
+}
+
+func (s *Service) BlobExpireList(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.ListBlobExpireArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept BlobExpireList request, args: %+v", args)
+
+	if err := s.raftNode.ReadIndex(ctx); err != nil {
+		span.Errorf("list read index error: %v", err)
+		c.RespondError(apierrors.ErrRaftReadIndex)
+		return
+	}
+
+	blobs, marker, err := s.KvMgr.ListBlobExpire(args.ExpireBefore, args.Marker, args.Count)
+	if err == kvmgr.ErrInvalidBlobExpireMarker {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	if err != nil {
+		span.Errorf("list failed, error:%v", err)
+		c.RespondError(errors.Info(apierrors.ErrUnexpected).Detail(err))
+		return
+	}
+	c.RespondJSON(&clustermgr.ListBlobExpireRet{Blobs: blobs, Marker: marker})
+}
+
+func (s *Service) proposeKv(ctx context.Context, operType int32, data []byte) error {
+	span := trace.SpanFromContextSafe(ctx)
+	err := s.raftNode.Propose(ctx, base.EncodeProposeInfo(s.KvMgr.GetModuleName(), operType, data, base.ProposeContext{ReqID: span.TraceID()}))
+	if err != nil {
+		span.Errorf("raft propose failed, error:%v", err)
+		return apierrors.ErrRaftPropose
+	}
+	return nil
+}
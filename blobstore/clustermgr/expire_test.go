@@ -0,0 +1,88 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+func TestService_BlobExpire(t *testing.T) {
+	testService, clean := initServiceWithData()
+	defer clean()
+	cmClient := initTestClusterClient(testService)
+	ctx := newCtx()
+
+	// invalid arguments
+	require.Error(t, cmClient.SetBlobExpire(ctx, &clustermgr.SetBlobExpireArgs{}))
+	require.Error(t, cmClient.SetBlobExpire(ctx, &clustermgr.SetBlobExpireArgs{
+		Blobs: []clustermgr.BlobExpire{{Vid: 1, MinBid: 100, Count: 0, ExpireAt: 100}},
+	}))
+
+	require.NoError(t, cmClient.SetBlobExpire(ctx, &clustermgr.SetBlobExpireArgs{
+		Blobs: []clustermgr.BlobExpire{
+			{Vid: 2, MinBid: 200, Count: 2, Size: 2048, ExpireAt: 300},
+			{Vid: 1, MinBid: 100, Count: 1, Size: 1024, ExpireAt: 100},
+			{Vid: 1, MinBid: 101, Count: 1, Size: 1024, ExpireAt: 200},
+		},
+	}))
+
+	// ordered by expiry time
+	list, err := cmClient.ListBlobExpire(ctx, &clustermgr.ListBlobExpireArgs{Count: 2})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(list.Blobs))
+	require.Equal(t, proto.BlobID(100), list.Blobs[0].MinBid)
+	require.Equal(t, proto.BlobID(101), list.Blobs[1].MinBid)
+	require.NotEmpty(t, list.Marker)
+	list, err = cmClient.ListBlobExpire(ctx, &clustermgr.ListBlobExpireArgs{Marker: list.Marker, Count: 2})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(list.Blobs))
+	require.Equal(t, proto.Vid(2), list.Blobs[0].Vid)
+	require.Empty(t, list.Marker)
+
+	// extend and stop at the blobs expire later
+	require.NoError(t, cmClient.SetBlobExpire(ctx, &clustermgr.SetBlobExpireArgs{
+		Blobs: []clustermgr.BlobExpire{{Vid: 1, MinBid: 100, Count: 1, Size: 1024, ExpireAt: 400}},
+	}))
+	list, err = cmClient.ListBlobExpire(ctx, &clustermgr.ListBlobExpireArgs{ExpireBefore: 300, Count: 10})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(list.Blobs))
+	require.Equal(t, proto.BlobID(101), list.Blobs[0].MinBid)
+	require.Equal(t, proto.BlobID(200), list.Blobs[1].MinBid)
+	require.Empty(t, list.Marker)
+
+	// the expiry reset since listed is kept
+	require.Error(t, cmClient.DeleteBlobExpire(ctx, &clustermgr.DeleteBlobExpireArgs{
+		Blobs: []clustermgr.BlobExpire{{Vid: 1, MinBid: 101}},
+	}))
+	require.NoError(t, cmClient.DeleteBlobExpire(ctx, &clustermgr.DeleteBlobExpireArgs{
+		Blobs: []clustermgr.BlobExpire{
+			{Vid: 1, MinBid: 100, ExpireAt: 100},
+			{Vid: 1, MinBid: 101, ExpireAt: 200},
+			{Vid: 2, MinBid: 200, ExpireAt: 300},
+		},
+	}))
+	list, err = cmClient.ListBlobExpire(ctx, &clustermgr.ListBlobExpireArgs{Count: 10})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(list.Blobs))
+	require.Equal(t, int64(400), list.Blobs[0].ExpireAt)
+
+	_, err = cmClient.ListBlobExpire(ctx, &clustermgr.ListBlobExpireArgs{Marker: "not-exist", Count: 10})
+	require.Error(t, err)
+}
@@ -104,6 +104,46 @@ func NewHandler(service *Service) *rpc.Router {
 
 	rpc.POST("/admin/update/volume", service.AdminUpdateVolume, rpc.OptArgsBody())
 
+	rpc.RegisterArgsParser(&clustermgr.GetTranscodeMappingArgs{}, "json")
+	rpc.RegisterArgsParser(&clustermgr.ListTranscodeMappingArgs{}, "json")
+
+	rpc.POST("/volume/transcode/mapping/set", service.VolumeTranscodeMappingSet, rpc.OptArgsBody())
+
+	rpc.GET("/volume/transcode/mapping/get", service.VolumeTranscodeMappingGet, rpc.OptArgsQuery())
+
+	rpc.GET("/volume/transcode/mapping/list", service.VolumeTranscodeMappingList, rpc.OptArgsQuery())
+
+	//==================blob expire==========================
+	rpc.RegisterArgsParser(&clustermgr.ListBlobExpireArgs{}, "json")
+
+	rpc.POST("/blob/expire/set", service.BlobExpireSet, rpc.OptArgsBody())
+
+	rpc.POST("/blob/expire/delete", service.BlobExpireDelete, rpc.OptArgsBody())
+
+	rpc.GET("/blob/expire/list", service.BlobExpireList, rpc.OptArgsQuery())
+
+	//==================object meta==========================
+	rpc.RegisterArgsParser(&clustermgr.GetObjectMetaArgs{}, "json")
+	rpc.RegisterArgsParser(&clustermgr.ListObjectMetaArgs{}, "json")
+
+	rpc.POST("/object/meta/set", service.ObjectMetaSet, rpc.OptArgsBody())
+
+	rpc.POST("/object/meta/cas", service.ObjectMetaCompareAndSet, rpc.OptArgsBody())
+
+	rpc.GET("/object/meta/get", service.ObjectMetaGet, rpc.OptArgsQuery())
+
+	rpc.POST("/object/meta/delete", service.ObjectMetaDelete, rpc.OptArgsBody())
+
+	rpc.GET("/object/meta/list", service.ObjectMetaList, rpc.OptArgsQuery())
+
+	//==================blob dedup==========================
+
+	rpc.POST("/blob/dedup/acquire", service.BlobDedupAcquire, rpc.OptArgsBody())
+
+	rpc.POST("/blob/dedup/register", service.BlobDedupRegister, rpc.OptArgsBody())
+
+	rpc.POST("/blob/dedup/release", service.BlobDedupRelease, rpc.OptArgsBody())
+
 	//==================chunk==========================
 
 	rpc.POST("/chunk/report", service.ChunkReport, rpc.OptArgsBody())
@@ -141,5 +181,19 @@ func NewHandler(service *Service) *rpc.Router {
 
 	rpc.GET("/kv/list", service.KvList, rpc.OptArgsQuery())
 
+	//==================queue==========================
+	rpc.RegisterArgsParser(&clustermgr.ConsumeMessageArgs{}, "json")
+	rpc.RegisterArgsParser(&clustermgr.StatQueueArgs{}, "json")
+
+	rpc.POST("/queue/produce", service.QueueProduce, rpc.OptArgsBody())
+
+	rpc.GET("/queue/consume", service.QueueConsume, rpc.OptArgsQuery())
+
+	rpc.POST("/queue/register", service.QueueRegisterGroup, rpc.OptArgsBody())
+
+	rpc.POST("/queue/commit", service.QueueCommitOffset, rpc.OptArgsBody())
+
+	rpc.GET("/queue/stat", service.QueueStat, rpc.OptArgsQuery())
+
 	return rpc.DefaultRouter
 }
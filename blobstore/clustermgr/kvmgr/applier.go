@@ -31,6 +31,12 @@ import (
 const (
 	OperTypeSetKv = iota + 1
 	OperTypeDeleteKv
+	OperTypeAcquireBlobDedup
+	OperTypeRegisterBlobDedup
+	OperTypeReleaseBlobDedup
+	OperTypeSetBlobExpire
+	OperTypeDeleteBlobExpire
+	OperTypeCompareAndSetKv
 )
 
 func (t *KvMgr) LoadData(ctx context.Context) error {
@@ -82,6 +88,50 @@ func (t *KvMgr) Apply(ctx context.Context, operTypes []int32, datas [][]byte, co
 				errs[idx] = t.Delete(kvDeleteArgs.Key)
 				wg.Done()
 			})
+
+		case OperTypeCompareAndSetKv:
+			args := &CompareAndSetKvCtx{}
+			err = json.Unmarshal(datas[idx], args)
+			if err != nil {
+				errs[idx] = errors.Info(err, "json unmarshal failed, data: ", datas[idx]).Detail(err)
+				wg.Done()
+				continue
+			}
+			// applied on the task of the key, in order with the other sets and deletes of the key
+			t.taskPool.Run(t.getTaskIdx(args.Key), func() {
+				errs[idx] = t.applyCompareAndSet(args)
+				wg.Done()
+			})
+
+		case OperTypeAcquireBlobDedup, OperTypeRegisterBlobDedup, OperTypeReleaseBlobDedup:
+			args := &BlobDedupCtx{}
+			err = json.Unmarshal(datas[idx], args)
+			if err != nil {
+				errs[idx] = errors.Info(err, "json unmarshal failed, data: ", datas[idx]).Detail(err)
+				wg.Done()
+				continue
+			}
+			operType := tp
+			// all dedup operations are applied in order on the same task,
+			// the hash key and blob key are updated together
+			t.taskPool.Run(t.getTaskIdx(blobDedupBlobKeyPrefix), func() {
+				errs[idx] = t.applyBlobDedup(operType, args)
+				wg.Done()
+			})
+		case OperTypeSetBlobExpire, OperTypeDeleteBlobExpire:
+			blob := &clustermgr.BlobExpire{}
+			err = json.Unmarshal(datas[idx], blob)
+			if err != nil {
+				errs[idx] = errors.Info(err, "json unmarshal failed, data: ", datas[idx]).Detail(err)
+				wg.Done()
+				continue
+			}
+			operType := tp
+			// the blob key and index key are updated together in order on the same task
+			t.taskPool.Run(t.getTaskIdx(blobExpireKeyPrefix), func() {
+				errs[idx] = t.applyBlobExpire(operType, blob)
+				wg.Done()
+			})
 		default:
 			err = errors.New("unsupported operation")
 			return
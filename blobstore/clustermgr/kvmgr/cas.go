@@ -0,0 +1,71 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kvmgr
+
+import (
+	"bytes"
+
+	"github.com/cubefs/cubefs/blobstore/common/kvstore"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// CompareAndSetKvCtx is the proposal to set Value of Key only if the current value is PrevValue,
+// the key must not exist if PrevValue is empty and is deleted if Value is empty. Whether the
+// value is set is stored in pending entry of PendingKey on the proposing node.
+type CompareAndSetKvCtx struct {
+	Key        string `json:"key"`
+	PrevValue  []byte `json:"prev_value,omitempty"`
+	Value      []byte `json:"value,omitempty"`
+	PendingKey string `json:"pending_key"`
+}
+
+// AddPendingCompareAndSet adds pending entry to receive the result of compare and set proposal
+func (t *KvMgr) AddPendingCompareAndSet(key string) {
+	t.pendingEntries.Store(key, false)
+}
+
+// LoadPendingCompareAndSet returns true if the value of compare and set proposal is set
+func (t *KvMgr) LoadPendingCompareAndSet(key string) bool {
+	value, _ := t.pendingEntries.Load(key)
+	set, _ := value.(bool)
+	return set
+}
+
+// DeletePendingCompareAndSet removes the pending entry
+func (t *KvMgr) DeletePendingCompareAndSet(key string) {
+	t.pendingEntries.Delete(key)
+}
+
+func (t *KvMgr) applyCompareAndSet(args *CompareAndSetKvCtx) error {
+	current, err := t.Get(args.Key)
+	if err != nil && err != kvstore.ErrNotFound {
+		return errors.Info(err, "apply compare and set failed, key: ", args.Key).Detail(err)
+	}
+	if !bytes.Equal(current, args.PrevValue) || (err == kvstore.ErrNotFound) != (len(args.PrevValue) == 0) {
+		return nil
+	}
+	if len(args.Value) == 0 {
+		err = t.Delete(args.Key)
+	} else {
+		err = t.Set(args.Key, args.Value)
+	}
+	if err != nil {
+		return errors.Info(err, "apply compare and set failed, key: ", args.Key).Detail(err)
+	}
+	if _, ok := t.pendingEntries.Load(args.PendingKey); ok {
+		t.pendingEntries.Store(args.PendingKey, true)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kvmgr
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/kvstore"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// dedup index of blobs is stored in kv, the hash key points to the blob,
+// and the blob key keeps the references of blob.
+// the blob key is written before and removed after the hash key,
+// so a blob found by hash always has its references.
+//
+//	for example:
+//		blob_dedup_hash-<hash>
+//		blob_dedup_blob-0000000012-00000000000000001024
+//
+// references are changed only in apply of raft, so that concurrent
+// proposals from different leaders never overwrite each other.
+const (
+	blobDedupHashKeyPrefix = "blob_dedup_hash-"
+	blobDedupBlobKeyPrefix = "blob_dedup_blob-"
+)
+
+// BlobDedupCtx is the proposal of blob dedup operations,
+// the result is stored in pending entry of PendingKey on the proposing node.
+type BlobDedupCtx struct {
+	Hash       string       `json:"hash,omitempty"`
+	Vid        proto.Vid    `json:"vid,omitempty"`
+	Bid        proto.BlobID `json:"bid,omitempty"`
+	RefID      string       `json:"ref_id,omitempty"`
+	PendingKey string       `json:"pending_key"`
+}
+
+// blobDedupRecord is the value of blob key,
+// Released keeps the reference ids have been released.
+type blobDedupRecord struct {
+	clustermgr.BlobDedup
+	Released []string `json:"released,omitempty"`
+}
+
+func blobDedupHashKey(hash string) string {
+	return blobDedupHashKeyPrefix + hash
+}
+
+func blobDedupBlobKey(vid proto.Vid, bid proto.BlobID) string {
+	return fmt.Sprintf("%s%010d-%020d", blobDedupBlobKeyPrefix, vid, bid)
+}
+
+// AddPendingBlobDedup adds pending entry to receive the result of dedup proposal
+func (t *KvMgr) AddPendingBlobDedup(key string) {
+	t.pendingEntries.Store(key, (*clustermgr.BlobDedup)(nil))
+}
+
+// LoadPendingBlobDedup returns the applied result of dedup proposal, nil if no such blob
+func (t *KvMgr) LoadPendingBlobDedup(key string) *clustermgr.BlobDedup {
+	value, _ := t.pendingEntries.Load(key)
+	blob, _ := value.(*clustermgr.BlobDedup)
+	return blob
+}
+
+// DeletePendingBlobDedup removes the pending entry
+func (t *KvMgr) DeletePendingBlobDedup(key string) {
+	t.pendingEntries.Delete(key)
+}
+
+func (t *KvMgr) applyBlobDedup(operType int32, args *BlobDedupCtx) error {
+	var (
+		blob *clustermgr.BlobDedup
+		err  error
+	)
+	switch operType {
+	case OperTypeAcquireBlobDedup:
+		blob, err = t.applyAcquireBlobDedup(args)
+	case OperTypeRegisterBlobDedup:
+		blob, err = t.applyRegisterBlobDedup(args)
+	case OperTypeReleaseBlobDedup:
+		blob, err = t.applyReleaseBlobDedup(args)
+	}
+	if err != nil {
+		return errors.Info(err, "apply blob dedup failed, args: ", args).Detail(err)
+	}
+	if _, ok := t.pendingEntries.Load(args.PendingKey); ok {
+		t.pendingEntries.Store(args.PendingKey, blob)
+	}
+	return nil
+}
+
+// applyAcquireBlobDedup increases references of the blob with the hash
+func (t *KvMgr) applyAcquireBlobDedup(args *BlobDedupCtx) (*clustermgr.BlobDedup, error) {
+	hashed, err := t.getBlobDedup(blobDedupHashKey(args.Hash))
+	if err == kvstore.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	blobKey := blobDedupBlobKey(hashed.Vid, hashed.Bid)
+	record, err := t.getBlobDedup(blobKey)
+	if err == kvstore.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record.Refs++
+	if err = t.setBlobDedup(blobKey, record); err != nil {
+		return nil, err
+	}
+	return &record.BlobDedup, nil
+}
+
+// applyRegisterBlobDedup registers the blob with one reference,
+// returns the existing blob if the hash or the blob has been registered
+func (t *KvMgr) applyRegisterBlobDedup(args *BlobDedupCtx) (*clustermgr.BlobDedup, error) {
+	hashed, err := t.getBlobDedup(blobDedupHashKey(args.Hash))
+	if err != nil && err != kvstore.ErrNotFound {
+		return nil, err
+	}
+	if err == nil {
+		record, err := t.getBlobDedup(blobDedupBlobKey(hashed.Vid, hashed.Bid))
+		if err == nil {
+			return &record.BlobDedup, nil
+		}
+		if err != kvstore.ErrNotFound {
+			return nil, err
+		}
+		// overwrite the hash without blob
+	}
+
+	blobKey := blobDedupBlobKey(args.Vid, args.Bid)
+	record, err := t.getBlobDedup(blobKey)
+	if err == nil {
+		return &record.BlobDedup, nil
+	}
+	if err != kvstore.ErrNotFound {
+		return nil, err
+	}
+
+	record = &blobDedupRecord{BlobDedup: clustermgr.BlobDedup{Hash: args.Hash, Vid: args.Vid, Bid: args.Bid, Refs: 1}}
+	if err = t.setBlobDedup(blobKey, record); err != nil {
+		return nil, err
+	}
+	if err = t.setBlobDedup(blobDedupHashKey(args.Hash), &blobDedupRecord{BlobDedup: record.BlobDedup}); err != nil {
+		return nil, err
+	}
+	return &record.BlobDedup, nil
+}
+
+// applyReleaseBlobDedup decreases references of the blob once for each RefID,
+// the dedup record is removed when there are no references.
+func (t *KvMgr) applyReleaseBlobDedup(args *BlobDedupCtx) (*clustermgr.BlobDedup, error) {
+	blobKey := blobDedupBlobKey(args.Vid, args.Bid)
+	record, err := t.getBlobDedup(blobKey)
+	if err == kvstore.ErrNotFound {
+		return &clustermgr.BlobDedup{Vid: args.Vid, Bid: args.Bid}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if args.RefID != "" {
+		for _, id := range record.Released {
+			if id == args.RefID {
+				return &record.BlobDedup, nil
+			}
+		}
+	}
+
+	if record.Refs > 1 {
+		record.Refs--
+		if args.RefID != "" {
+			record.Released = append(record.Released, args.RefID)
+		}
+		if err = t.setBlobDedup(blobKey, record); err != nil {
+			return nil, err
+		}
+		return &record.BlobDedup, nil
+	}
+
+	hashKey := blobDedupHashKey(record.Hash)
+	hashed, err := t.getBlobDedup(hashKey)
+	if err != nil && err != kvstore.ErrNotFound {
+		return nil, err
+	}
+	if err == nil && hashed.Vid == record.Vid && hashed.Bid == record.Bid {
+		if err = t.Delete(hashKey); err != nil {
+			return nil, err
+		}
+	}
+	if err = t.Delete(blobKey); err != nil {
+		return nil, err
+	}
+	record.Refs = 0
+	return &record.BlobDedup, nil
+}
+
+func (t *KvMgr) getBlobDedup(key string) (*blobDedupRecord, error) {
+	value, err := t.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	record := &blobDedupRecord{}
+	if err = json.Unmarshal(value, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (t *KvMgr) setBlobDedup(key string, record *blobDedupRecord) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return t.Set(key, value)
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kvmgr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/kvstore"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// expiry of blobs is stored in kv, the blob key keeps the expiry of blobs,
+// and the index key orders the blobs by expiry time.
+// the numbers are padded to keep the keys in order.
+//
+//	for example:
+//		blob_expire-0000000012-00000000000000001024
+//		blob_expire_at-00000000001700000000-0000000012-00000000000000001024
+//
+// both keys are changed only in apply of raft, so that the index is always
+// consistent with the blob keys.
+const (
+	blobExpireKeyPrefix      = "blob_expire-"
+	blobExpireIndexKeyPrefix = "blob_expire_at-"
+)
+
+var ErrInvalidBlobExpireMarker = errors.New("invalid blob expire marker")
+
+func blobExpireKey(vid proto.Vid, bid proto.BlobID) string {
+	return fmt.Sprintf("%s%010d-%020d", blobExpireKeyPrefix, vid, bid)
+}
+
+func blobExpireIndexKey(blob *clustermgr.BlobExpire) string {
+	return fmt.Sprintf("%s%020d-%010d-%020d", blobExpireIndexKeyPrefix, blob.ExpireAt, blob.Vid, blob.MinBid)
+}
+
+// ListBlobExpire lists the expiry of blobs ordered by expiry time, only blobs
+// expire before expireBefore are listed if it is not zero.
+// nextMarker is empty if there are no more blobs.
+func (t *KvMgr) ListBlobExpire(expireBefore int64, marker string, count int) (blobs []clustermgr.BlobExpire, nextMarker string, err error) {
+	if marker != "" && !strings.HasPrefix(marker, blobExpireIndexKeyPrefix) {
+		return nil, "", ErrInvalidBlobExpireMarker
+	}
+	ret, err := t.List(&clustermgr.ListKvOpts{Prefix: blobExpireIndexKeyPrefix, Marker: marker, Count: count})
+	if err != nil {
+		return nil, "", err
+	}
+
+	blobs = make([]clustermgr.BlobExpire, 0, len(ret.Kvs))
+	for _, kv := range ret.Kvs {
+		blob := clustermgr.BlobExpire{}
+		if err = json.Unmarshal(kv.Value, &blob); err != nil {
+			return nil, "", errors.Info(err, "unmarshal blob expire failed, key: ", kv.Key).Detail(err)
+		}
+		if expireBefore > 0 && blob.ExpireAt > expireBefore {
+			return blobs, "", nil
+		}
+		blobs = append(blobs, blob)
+	}
+	return blobs, ret.Marker, nil
+}
+
+func (t *KvMgr) applyBlobExpire(operType int32, blob *clustermgr.BlobExpire) error {
+	var err error
+	switch operType {
+	case OperTypeSetBlobExpire:
+		err = t.applySetBlobExpire(blob)
+	case OperTypeDeleteBlobExpire:
+		err = t.applyDeleteBlobExpire(blob)
+	}
+	if err != nil {
+		return errors.Info(err, "apply blob expire failed, blob: ", blob).Detail(err)
+	}
+	return nil
+}
+
+// applySetBlobExpire sets or overwrites the expiry of blobs and moves the index key
+func (t *KvMgr) applySetBlobExpire(blob *clustermgr.BlobExpire) error {
+	blobKey := blobExpireKey(blob.Vid, blob.MinBid)
+	old, err := t.getBlobExpire(blobKey)
+	if err != nil && err != kvstore.ErrNotFound {
+		return err
+	}
+	value, err := json.Marshal(blob)
+	if err != nil {
+		return err
+	}
+	if err = t.Set(blobKey, value); err != nil {
+		return err
+	}
+	if old != nil && old.ExpireAt != blob.ExpireAt {
+		if err = t.Delete(blobExpireIndexKey(old)); err != nil {
+			return err
+		}
+	}
+	return t.Set(blobExpireIndexKey(blob), value)
+}
+
+// applyDeleteBlobExpire removes the expiry of blobs only if it still expires at ExpireAt,
+// the expiry reset after it was listed is kept.
+func (t *KvMgr) applyDeleteBlobExpire(blob *clustermgr.BlobExpire) error {
+	blobKey := blobExpireKey(blob.Vid, blob.MinBid)
+	old, err := t.getBlobExpire(blobKey)
+	if err == kvstore.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if old.ExpireAt != blob.ExpireAt {
+		return nil
+	}
+	if err = t.Delete(blobExpireIndexKey(old)); err != nil {
+		return err
+	}
+	return t.Delete(blobKey)
+}
+
+func (t *KvMgr) getBlobExpire(key string) (*clustermgr.BlobExpire, error) {
+	value, err := t.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	blob := &clustermgr.BlobExpire{}
+	if err = json.Unmarshal(value, blob); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
@@ -15,6 +15,8 @@
 package kvmgr
 
 import (
+	"sync"
+
 	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/persistence/kvdb"
@@ -39,6 +41,9 @@ type KvMgr struct {
 	applyConcurrency uint64
 	tbl              *kvdb.KvTable
 	taskPool         *base.TaskDistribution
+
+	// results of dedup proposals applied on this node
+	pendingEntries sync.Map
 }
 
 func NewKvMgr(db *kvdb.KvDB) (*KvMgr, error) {
@@ -197,7 +197,7 @@ func TestKvMgr_Apply(t *testing.T) {
 			datas     [][]byte
 		}{
 			{
-				operTypes: []int32{3},
+				operTypes: []int32{OperTypeReleaseBlobDedup + 1},
 				ctxs:      []base.ProposeContext{{ReqID: span.TraceID()}},
 				datas:     [][]byte{data},
 			},
@@ -220,3 +220,56 @@ func TestKvMgr_Apply(t *testing.T) {
 
 	}
 }
+
+func TestKvMgr_ApplyBlobDedup(t *testing.T) {
+	tmpKvDBPath := "/tmp/tmpKvDBPath" + strconv.Itoa(rand.Intn(1000000000))
+	defer os.RemoveAll(tmpKvDBPath)
+
+	kvDB, _ := kvdb.Open(tmpKvDBPath)
+	kvMgr, err := NewKvMgr(kvDB)
+	require.NoError(t, err)
+	_, ctx := trace.StartSpanFromContext(context.Background(), "")
+
+	apply := func(operType int32, args *BlobDedupCtx) *clustermgr.BlobDedup {
+		args.PendingKey = "pending"
+		kvMgr.AddPendingBlobDedup(args.PendingKey)
+		defer kvMgr.DeletePendingBlobDedup(args.PendingKey)
+		data, err := json.Marshal(args)
+		require.NoError(t, err)
+		require.NoError(t, kvMgr.Apply(ctx, []int32{operType}, [][]byte{data}, nil))
+		return kvMgr.LoadPendingBlobDedup(args.PendingKey)
+	}
+
+	require.Nil(t, apply(OperTypeAcquireBlobDedup, &BlobDedupCtx{Hash: "h"}))
+	blob := apply(OperTypeRegisterBlobDedup, &BlobDedupCtx{Hash: "h", Vid: 1, Bid: 10})
+	require.Equal(t, clustermgr.BlobDedup{Hash: "h", Vid: 1, Bid: 10, Refs: 1}, *blob)
+	blob = apply(OperTypeRegisterBlobDedup, &BlobDedupCtx{Hash: "h", Vid: 2, Bid: 20})
+	require.Equal(t, clustermgr.BlobDedup{Hash: "h", Vid: 1, Bid: 10, Refs: 1}, *blob)
+
+	// concurrent acquires are applied in order
+	operTypes := make([]int32, 0)
+	datas := make([][]byte, 0)
+	for i := 0; i < 10; i++ {
+		data, _ := json.Marshal(&BlobDedupCtx{Hash: "h"})
+		operTypes = append(operTypes, OperTypeAcquireBlobDedup)
+		datas = append(datas, data)
+	}
+	require.NoError(t, kvMgr.Apply(ctx, operTypes, datas, nil))
+	blob = apply(OperTypeAcquireBlobDedup, &BlobDedupCtx{Hash: "h"})
+	require.Equal(t, uint32(12), blob.Refs)
+
+	// released only once with the same ref id
+	blob = apply(OperTypeReleaseBlobDedup, &BlobDedupCtx{Vid: 1, Bid: 10, RefID: "req-0"})
+	require.Equal(t, uint32(11), blob.Refs)
+	blob = apply(OperTypeReleaseBlobDedup, &BlobDedupCtx{Vid: 1, Bid: 10, RefID: "req-0"})
+	require.Equal(t, uint32(11), blob.Refs)
+	for i := 1; i < 11; i++ {
+		blob = apply(OperTypeReleaseBlobDedup, &BlobDedupCtx{Vid: 1, Bid: 10, RefID: fmt.Sprintf("req-%d", i)})
+		require.Equal(t, uint32(11-i), blob.Refs)
+	}
+	blob = apply(OperTypeReleaseBlobDedup, &BlobDedupCtx{Vid: 1, Bid: 10, RefID: "req-11"})
+	require.Equal(t, uint32(0), blob.Refs)
+	require.Nil(t, apply(OperTypeAcquireBlobDedup, &BlobDedupCtx{Hash: "h"}))
+	blob = apply(OperTypeReleaseBlobDedup, &BlobDedupCtx{Vid: 1, Bid: 10, RefID: "req-0"})
+	require.Equal(t, uint32(0), blob.Refs)
+}
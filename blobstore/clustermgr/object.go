@@ -0,0 +1,245 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/kvmgr"
+	apierrors "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/kvstore"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// metadata of objects is stored in kv, bucket has no '/' so keys of one bucket are continuous
+//
+//	for example:
+//		object-bucket/dir/key
+const objectKeyPrefix = "object-"
+
+func objectBucketPrefix(bucket string) string {
+	return objectKeyPrefix + bucket + "/"
+}
+
+func objectKey(bucket, key string) string {
+	return objectBucketPrefix(bucket) + key
+}
+
+func (s *Service) ObjectMetaSet(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.ObjectMeta)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept ObjectMetaSet request, bucket: %s, key: %s", args.Bucket, args.Key)
+
+	if !clustermgr.IsValidObjectName(args.Bucket, args.Key) || len(args.Value) == 0 {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+
+	value, err := json.Marshal(args)
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	data, err := json.Marshal(&clustermgr.SetKvArgs{Key: objectKey(args.Bucket, args.Key), Value: value})
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	if err = s.proposeKv(ctx, kvmgr.OperTypeSetKv, data); err != nil {
+		c.RespondError(err)
+	}
+}
+
+// ObjectMetaCompareAndSet sets the metadata of object only if its current value is PrevValue,
+// both values are stored as the marshaled ObjectMeta
+func (s *Service) ObjectMetaCompareAndSet(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.CompareAndSetObjectMetaArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept ObjectMetaCompareAndSet request, bucket: %s, key: %s", args.Bucket, args.Key)
+
+	if !clustermgr.IsValidObjectName(args.Bucket, args.Key) || len(args.Value) == 0 {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+
+	if err := s.compareAndSetObjectMeta(ctx, args.Bucket, args.Key, args.PrevValue, args.Value); err != nil {
+		c.RespondError(err)
+	}
+}
+
+// compareAndSetObjectMeta sets the value of object only if its current value is prevValue,
+// the object is deleted if value is empty
+func (s *Service) compareAndSetObjectMeta(ctx context.Context, bucket, key string, prevValue, value []byte) (err error) {
+	cas := &kvmgr.CompareAndSetKvCtx{Key: objectKey(bucket, key), PendingKey: uuid.New().String()}
+	if len(prevValue) > 0 {
+		if cas.PrevValue, err = json.Marshal(&clustermgr.ObjectMeta{Bucket: bucket, Key: key, Value: prevValue}); err != nil {
+			return
+		}
+	}
+	if len(value) > 0 {
+		if cas.Value, err = json.Marshal(&clustermgr.ObjectMeta{Bucket: bucket, Key: key, Value: value}); err != nil {
+			return
+		}
+	}
+	data, err := json.Marshal(cas)
+	if err != nil {
+		return
+	}
+
+	s.KvMgr.AddPendingCompareAndSet(cas.PendingKey)
+	defer s.KvMgr.DeletePendingCompareAndSet(cas.PendingKey)
+	if err = s.proposeKv(ctx, kvmgr.OperTypeCompareAndSetKv, data); err != nil {
+		return
+	}
+	if !s.KvMgr.LoadPendingCompareAndSet(cas.PendingKey) {
+		return apierrors.ErrObjectMetaConflict
+	}
+	return nil
+}
+
+func (s *Service) ObjectMetaGet(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.GetObjectMetaArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept ObjectMetaGet request, args: %+v", args)
+
+	if !clustermgr.IsValidObjectName(args.Bucket, args.Key) {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	if err := s.raftNode.ReadIndex(ctx); err != nil {
+		span.Errorf("read index error: %v", err)
+		c.RespondError(apierrors.ErrRaftReadIndex)
+		return
+	}
+
+	value, err := s.KvMgr.Get(objectKey(args.Bucket, args.Key))
+	if err == kvstore.ErrNotFound {
+		c.RespondError(apierrors.ErrNotFound)
+		return
+	}
+	if err != nil {
+		span.Errorf("get object meta failed, error: %v", err)
+		c.RespondError(apierrors.ErrCMUnexpect)
+		return
+	}
+
+	ret := &clustermgr.ObjectMeta{}
+	if err = json.Unmarshal(value, ret); err != nil {
+		span.Errorf("unmarshal object meta failed, error: %v", err)
+		c.RespondError(apierrors.ErrCMUnexpect)
+		return
+	}
+	c.RespondJSON(ret)
+}
+
+func (s *Service) ObjectMetaDelete(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.DeleteObjectMetaArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept ObjectMetaDelete request, args: %+v", args)
+
+	if !clustermgr.IsValidObjectName(args.Bucket, args.Key) {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	if len(args.PrevValue) > 0 {
+		if err := s.compareAndSetObjectMeta(ctx, args.Bucket, args.Key, args.PrevValue, nil); err != nil {
+			c.RespondError(err)
+		}
+		return
+	}
+
+	data, err := json.Marshal(&clustermgr.DeleteKvArgs{Key: objectKey(args.Bucket, args.Key)})
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	if err = s.proposeKv(ctx, kvmgr.OperTypeDeleteKv, data); err != nil {
+		c.RespondError(err)
+	}
+}
+
+func (s *Service) ObjectMetaList(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.ListObjectMetaArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept ObjectMetaList request, args: %+v", args)
+
+	if !clustermgr.IsValidObjectName(args.Bucket, "-") {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	if err := s.raftNode.ReadIndex(ctx); err != nil {
+		span.Errorf("list read index error: %v", err)
+		c.RespondError(apierrors.ErrRaftReadIndex)
+		return
+	}
+
+	bucketPrefix := objectBucketPrefix(args.Bucket)
+	opts := &clustermgr.ListKvOpts{Prefix: bucketPrefix + args.Prefix, Count: args.Count}
+	if args.Marker != "" {
+		opts.Marker = bucketPrefix + args.Marker
+	}
+	kvs, err := s.KvMgr.List(opts)
+	if err != nil {
+		span.Errorf("list failed, error:%v", err)
+		c.RespondError(errors.Info(apierrors.ErrUnexpected).Detail(err))
+		return
+	}
+
+	ret := &clustermgr.ListObjectMetaRet{Objects: make([]clustermgr.ObjectMeta, 0, len(kvs.Kvs))}
+	for _, kv := range kvs.Kvs {
+		meta := clustermgr.ObjectMeta{}
+		if err = json.Unmarshal(kv.Value, &meta); err != nil {
+			span.Errorf("unmarshal object meta %s failed, error:%v", kv.Key, err)
+			c.RespondError(apierrors.ErrCMUnexpect)
+			return
+		}
+		ret.Objects = append(ret.Objects, meta)
+	}
+	if kvs.Marker != "" {
+		ret.Marker = kvs.Marker[len(bucketPrefix):]
+	}
+	c.RespondJSON(ret)
+}
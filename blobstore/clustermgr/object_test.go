@@ -0,0 +1,90 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	apierrors "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+)
+
+func TestService_ObjectMeta(t *testing.T) {
+	testService, clean := initServiceWithData()
+	defer clean()
+	cmClient := initTestClusterClient(testService)
+	ctx := newCtx()
+
+	// invalid arguments
+	require.Error(t, cmClient.SetObjectMeta(ctx, &clustermgr.ObjectMeta{Bucket: "a/b", Key: "k", Value: []byte("v")}))
+	require.Error(t, cmClient.SetObjectMeta(ctx, &clustermgr.ObjectMeta{Bucket: "b", Key: "", Value: []byte("v")}))
+	require.Error(t, cmClient.SetObjectMeta(ctx, &clustermgr.ObjectMeta{Bucket: "b", Key: "k"}))
+
+	for _, key := range []string{"dir/a", "dir/b", "dir/c", "file"} {
+		require.NoError(t, cmClient.SetObjectMeta(ctx, &clustermgr.ObjectMeta{Bucket: "b", Key: key, Value: []byte(key)}))
+	}
+	require.NoError(t, cmClient.SetObjectMeta(ctx, &clustermgr.ObjectMeta{Bucket: "bb", Key: "dir/a", Value: []byte("v")}))
+
+	meta, err := cmClient.GetObjectMeta(ctx, &clustermgr.GetObjectMetaArgs{Bucket: "b", Key: "dir/a"})
+	require.NoError(t, err)
+	require.Equal(t, []byte("dir/a"), meta.Value)
+	_, err = cmClient.GetObjectMeta(ctx, &clustermgr.GetObjectMetaArgs{Bucket: "b", Key: "not-exist"})
+	require.Error(t, err)
+
+	list, err := cmClient.ListObjectMeta(ctx, &clustermgr.ListObjectMetaArgs{Bucket: "b", Prefix: "dir/", Count: 2})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(list.Objects))
+	require.Equal(t, "dir/b", list.Marker)
+	list, err = cmClient.ListObjectMeta(ctx, &clustermgr.ListObjectMetaArgs{Bucket: "b", Prefix: "dir/", Marker: list.Marker, Count: 2})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(list.Objects))
+	require.Equal(t, "dir/c", list.Objects[0].Key)
+	require.Empty(t, list.Marker)
+
+	// keys of other buckets are not listed
+	list, err = cmClient.ListObjectMeta(ctx, &clustermgr.ListObjectMetaArgs{Bucket: "b", Count: 10})
+	require.NoError(t, err)
+	require.Equal(t, 4, len(list.Objects))
+
+	// compare and set
+	casArgs := &clustermgr.CompareAndSetObjectMetaArgs{Bucket: "b", Key: "file", PrevValue: []byte("other"), Value: []byte("v1")}
+	require.Equal(t, apierrors.CodeObjectMetaConflict, rpc.DetectStatusCode(cmClient.CompareAndSetObjectMeta(ctx, casArgs)))
+	casArgs.PrevValue = []byte("file")
+	require.NoError(t, cmClient.CompareAndSetObjectMeta(ctx, casArgs))
+	require.Equal(t, apierrors.CodeObjectMetaConflict, rpc.DetectStatusCode(cmClient.CompareAndSetObjectMeta(ctx, casArgs)))
+	casArgs = &clustermgr.CompareAndSetObjectMetaArgs{Bucket: "b", Key: "new", Value: []byte("v1")}
+	require.NoError(t, cmClient.CompareAndSetObjectMeta(ctx, casArgs))
+	require.Equal(t, apierrors.CodeObjectMetaConflict, rpc.DetectStatusCode(cmClient.CompareAndSetObjectMeta(ctx, casArgs)))
+	meta, err = cmClient.GetObjectMeta(ctx, &clustermgr.GetObjectMetaArgs{Bucket: "b", Key: "file"})
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), meta.Value)
+	require.NoError(t, cmClient.DeleteObjectMeta(ctx, &clustermgr.DeleteObjectMetaArgs{Bucket: "b", Key: "new"}))
+
+	// compare and delete
+	delArgs := &clustermgr.DeleteObjectMetaArgs{Bucket: "b", Key: "file", PrevValue: []byte("file")}
+	require.Equal(t, apierrors.CodeObjectMetaConflict, rpc.DetectStatusCode(cmClient.DeleteObjectMeta(ctx, delArgs)))
+	delArgs.PrevValue = []byte("v1")
+	require.NoError(t, cmClient.DeleteObjectMeta(ctx, delArgs))
+	require.Equal(t, apierrors.CodeObjectMetaConflict, rpc.DetectStatusCode(cmClient.DeleteObjectMeta(ctx, delArgs)))
+	_, err = cmClient.GetObjectMeta(ctx, &clustermgr.GetObjectMetaArgs{Bucket: "b", Key: "file"})
+	require.Error(t, err)
+
+	require.NoError(t, cmClient.DeleteObjectMeta(ctx, &clustermgr.DeleteObjectMetaArgs{Bucket: "b", Key: "dir/a"}))
+	_, err = cmClient.GetObjectMeta(ctx, &clustermgr.GetObjectMetaArgs{Bucket: "b", Key: "dir/a"})
+	require.Error(t, err)
+}
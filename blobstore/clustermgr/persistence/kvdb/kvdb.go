@@ -17,9 +17,11 @@ package kvdb
 import "github.com/cubefs/cubefs/blobstore/common/kvstore"
 
 var (
-	kvCF  = "keyValue"
-	kvCFs = []string{
+	kvCF    = "keyValue"
+	queueCF = "queue"
+	kvCFs   = []string{
 		kvCF,
+		queueCF,
 	}
 )
 
@@ -0,0 +1,182 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kvdb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/kvstore"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// keys of queue table, topic is ended with a zero byte to avoid the prefix conflict
+//
+//	message:         m{topic}\x00{offset}
+//	last offset:     t{topic}
+//	consumer offset: o{topic}\x00{group}
+const (
+	queueMsgKeyPrefix    = 'm'
+	queueTopicKeyPrefix  = 't'
+	queueOffsetKeyPrefix = 'o'
+	queueTopicDelimiter  = 0
+)
+
+type QueueTable struct {
+	tbl kvstore.KVTable
+}
+
+func OpenQueueTable(db kvstore.KVStore) (*QueueTable, error) {
+	if db == nil {
+		return nil, errors.New("open queue table failed: db is nil")
+	}
+	return &QueueTable{db.Table(queueCF)}, nil
+}
+
+// GetLastOffset returns the offset of the latest produced message, 0 means no message produced
+func (q *QueueTable) GetLastOffset(topic string) (uint64, error) {
+	value, err := q.tbl.Get(encodeQueueTopicKey(topic))
+	if err == kvstore.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+// PutMessages puts messages and the last offset of topic in batch
+func (q *QueueTable) PutMessages(topic string, msgs []*clustermgr.QueueMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	batch := q.tbl.NewWriteBatch()
+	defer batch.Destroy()
+
+	for _, msg := range msgs {
+		value, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		batch.PutCF(q.tbl.GetCf(), encodeQueueMsgKey(topic, msg.Offset), value)
+	}
+	batch.PutCF(q.tbl.GetCf(), encodeQueueTopicKey(topic), encodeQueueOffset(msgs[len(msgs)-1].Offset))
+	return q.tbl.DoBatch(batch)
+}
+
+// ListMessages returns at most count messages from offset
+func (q *QueueTable) ListMessages(topic string, offset uint64, count int) ([]*clustermgr.QueueMessage, error) {
+	iter := q.tbl.NewIterator(nil)
+	defer iter.Close()
+
+	prefix := encodeQueueMsgPrefix(topic)
+	ret := make([]*clustermgr.QueueMessage, 0, count)
+	for iter.Seek(encodeQueueMsgKey(topic, offset)); count > 0 && iter.ValidForPrefix(prefix); iter.Next() {
+		if err := iter.Err(); err != nil {
+			return nil, errors.Info(err, "queue table iterate failed")
+		}
+		msg := &clustermgr.QueueMessage{}
+		err := json.Unmarshal(iter.Value().Data(), msg)
+		iter.Key().Free()
+		iter.Value().Free()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, msg)
+		count--
+	}
+	return ret, nil
+}
+
+// GetFirstOffset returns the offset of the oldest message, 0 means no message in topic
+func (q *QueueTable) GetFirstOffset(topic string) (uint64, error) {
+	msgs, err := q.ListMessages(topic, 0, 1)
+	if err != nil || len(msgs) == 0 {
+		return 0, err
+	}
+	return msgs[0].Offset, nil
+}
+
+// TrimMessages deletes the messages not larger than offset
+func (q *QueueTable) TrimMessages(topic string, offset uint64) error {
+	return q.tbl.DeleteRange(encodeQueueMsgKey(topic, 0), encodeQueueMsgKey(topic, offset+1))
+}
+
+func (q *QueueTable) SetConsumeOffset(topic, group string, offset uint64) error {
+	return q.tbl.Put(kvstore.KV{Key: encodeQueueOffsetKey(topic, group), Value: encodeQueueOffset(offset)})
+}
+
+// GetConsumeOffset returns the committed offset of group, 0 means nothing committed
+func (q *QueueTable) GetConsumeOffset(topic, group string) (uint64, error) {
+	value, err := q.tbl.Get(encodeQueueOffsetKey(topic, group))
+	if err == kvstore.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+// ListConsumeOffsets returns the committed offsets of all groups of topic
+func (q *QueueTable) ListConsumeOffsets(topic string) (map[string]uint64, error) {
+	iter := q.tbl.NewIterator(nil)
+	defer iter.Close()
+
+	prefix := encodeQueueOffsetKey(topic, "")
+	ret := make(map[string]uint64)
+	for iter.Seek(prefix); iter.ValidForPrefix(prefix); iter.Next() {
+		if err := iter.Err(); err != nil {
+			return nil, errors.Info(err, "queue table iterate failed")
+		}
+		group := string(iter.Key().Data()[len(prefix):])
+		ret[group] = binary.BigEndian.Uint64(iter.Value().Data())
+		iter.Key().Free()
+		iter.Value().Free()
+	}
+	return ret, nil
+}
+
+func encodeQueueTopicKey(topic string) []byte {
+	key := make([]byte, 0, 1+len(topic))
+	key = append(key, queueTopicKeyPrefix)
+	return append(key, topic...)
+}
+
+func encodeQueueMsgPrefix(topic string) []byte {
+	key := make([]byte, 0, 2+len(topic)+8)
+	key = append(key, queueMsgKeyPrefix)
+	key = append(key, topic...)
+	return append(key, queueTopicDelimiter)
+}
+
+func encodeQueueMsgKey(topic string, offset uint64) []byte {
+	return append(encodeQueueMsgPrefix(topic), encodeQueueOffset(offset)...)
+}
+
+func encodeQueueOffsetKey(topic, group string) []byte {
+	key := make([]byte, 0, 2+len(topic)+len(group))
+	key = append(key, queueOffsetKeyPrefix)
+	key = append(key, topic...)
+	key = append(key, queueTopicDelimiter)
+	return append(key, group...)
+}
+
+func encodeQueueOffset(offset uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, offset)
+	return b
+}
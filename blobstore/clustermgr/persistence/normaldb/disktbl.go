@@ -59,6 +59,8 @@ type DiskInfoRecord struct {
 	Size         int64            `json:"size"`
 	Used         int64            `json:"used"`
 	Free         int64            `json:"free"`
+	// version of cluster key wrapping the data key of disk
+	DataKeyVersion uint32 `json:"data_key_version,omitempty"`
 }
 
 type DiskTable struct {
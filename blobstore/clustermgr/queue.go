@@ -0,0 +1,170 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/queuemgr"
+	apierrors "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+func (s *Service) QueueProduce(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.ProduceMessageArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept QueueProduce request, topic: %s, count: %d", args.Topic, len(args.Msgs))
+
+	if args.Topic == "" || len(args.Msgs) == 0 {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+
+	data, err := json.Marshal(&queuemgr.ProduceArgs{Topic: args.Topic, Time: time.Now().Unix(), Msgs: args.Msgs})
+	if err != nil {
+		span.Errorf("marshal failed, error:%v", err)
+		c.RespondError(err)
+		return
+	}
+	err = s.raftNode.Propose(ctx, base.EncodeProposeInfo(s.QueueMgr.GetModuleName(), queuemgr.OperTypeProduce, data, base.ProposeContext{ReqID: span.TraceID()}))
+	if err != nil {
+		span.Errorf("raft propose failed, error:%v", err)
+		c.RespondError(apierrors.ErrRaftPropose)
+	}
+}
+
+func (s *Service) QueueConsume(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.ConsumeMessageArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept QueueConsume request, args: %+v", args)
+
+	if args.Topic == "" || args.Group == "" {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	if err := s.raftNode.ReadIndex(ctx); err != nil {
+		span.Errorf("read index error: %v", err)
+		c.RespondError(apierrors.ErrRaftReadIndex)
+		return
+	}
+
+	ret, err := s.QueueMgr.Consume(args)
+	if err != nil {
+		span.Errorf("consume failed, error:%v", err)
+		c.RespondError(errors.Info(apierrors.ErrUnexpected).Detail(err))
+		return
+	}
+	c.RespondJSON(ret)
+}
+
+func (s *Service) QueueRegisterGroup(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.RegisterQueueGroupArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept QueueRegisterGroup request, args: %+v", args)
+
+	if args.Topic == "" || args.Group == "" {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		span.Errorf("marshal failed, error:%v", err)
+		c.RespondError(err)
+		return
+	}
+	err = s.raftNode.Propose(ctx, base.EncodeProposeInfo(s.QueueMgr.GetModuleName(), queuemgr.OperTypeRegisterGroup, data, base.ProposeContext{ReqID: span.TraceID()}))
+	if err != nil {
+		span.Errorf("raft propose failed, error:%v", err)
+		c.RespondError(apierrors.ErrRaftPropose)
+	}
+}
+
+func (s *Service) QueueCommitOffset(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.CommitQueueOffsetArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept QueueCommitOffset request, args: %+v", args)
+
+	if args.Topic == "" || args.Group == "" || args.Offset == 0 {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		span.Errorf("marshal failed, error:%v", err)
+		c.RespondError(err)
+		return
+	}
+	err = s.raftNode.Propose(ctx, base.EncodeProposeInfo(s.QueueMgr.GetModuleName(), queuemgr.OperTypeCommitOffset, data, base.ProposeContext{ReqID: span.TraceID()}))
+	if err != nil {
+		span.Errorf("raft propose failed, error:%v", err)
+		c.RespondError(apierrors.ErrRaftPropose)
+	}
+}
+
+func (s *Service) QueueStat(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.StatQueueArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept QueueStat request, args: %+v", args)
+
+	if args.Topic == "" {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	if err := s.raftNode.ReadIndex(ctx); err != nil {
+		span.Errorf("read index error: %v", err)
+		c.RespondError(apierrors.ErrRaftReadIndex)
+		return
+	}
+
+	ret, err := s.QueueMgr.Stat(args.Topic)
+	if err != nil {
+		span.Errorf("stat queue failed, error:%v", err)
+		c.RespondError(errors.Info(apierrors.ErrUnexpected).Detail(err))
+		return
+	}
+	c.RespondJSON(ret)
+}
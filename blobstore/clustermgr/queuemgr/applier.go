@@ -0,0 +1,90 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package queuemgr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+const (
+	OperTypeProduce = iota + 1
+	OperTypeCommitOffset
+	OperTypeRegisterGroup
+)
+
+func (q *QueueMgr) LoadData(ctx context.Context) error {
+	return nil
+}
+
+func (q *QueueMgr) GetModuleName() string {
+	return q.module
+}
+
+func (q *QueueMgr) SetModuleName(module string) {
+	q.module = module
+}
+
+// Apply applies operations one by one, the offsets of messages depend on the order of operations
+func (q *QueueMgr) Apply(ctx context.Context, operTypes []int32, datas [][]byte, contexts []base.ProposeContext) error {
+	span := trace.SpanFromContextSafe(ctx)
+	for idx, tp := range operTypes {
+		var err error
+		switch tp {
+		case OperTypeProduce:
+			args := &ProduceArgs{}
+			if err = json.Unmarshal(datas[idx], args); err != nil {
+				return errors.Info(err, "json unmarshal failed, data: ", datas[idx]).Detail(err)
+			}
+			err = q.produce(ctx, args)
+
+		case OperTypeCommitOffset:
+			args := &clustermgr.CommitQueueOffsetArgs{}
+			if err = json.Unmarshal(datas[idx], args); err != nil {
+				return errors.Info(err, "json unmarshal failed, data: ", datas[idx]).Detail(err)
+			}
+			err = q.commit(ctx, args)
+
+		case OperTypeRegisterGroup:
+			args := &clustermgr.RegisterQueueGroupArgs{}
+			if err = json.Unmarshal(datas[idx], args); err != nil {
+				return errors.Info(err, "json unmarshal failed, data: ", datas[idx]).Detail(err)
+			}
+			err = q.registerGroup(ctx, args)
+
+		default:
+			return errors.New("unsupported operation")
+		}
+		if err != nil {
+			span.Error(fmt.Sprintf("operation type: %d, apply failed => ", tp), errors.Detail(err))
+			return errors.Info(err, "apply queue operation failed").Detail(err)
+		}
+	}
+	return nil
+}
+
+func (q *QueueMgr) Flush(ctx context.Context) error {
+	return nil
+}
+
+func (q *QueueMgr) NotifyLeaderChange(ctx context.Context, leader uint64, host string) {
+	// Do nothing.
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package queuemgr
+
+import (
+	"context"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/persistence/kvdb"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+)
+
+const moduleName = "queue manager"
+
+var (
+	defaultConsumeCount = 100
+	maxConsumeCount     = 1000
+)
+
+// ProduceArgs is the propose data of produce, the time is set by leader
+// so that all members keep the same messages
+type ProduceArgs struct {
+	Topic string   `json:"topic"`
+	Time  int64    `json:"time"`
+	Msgs  [][]byte `json:"msgs"`
+}
+
+type QueueMgrAPI interface {
+	Consume(args *clustermgr.ConsumeMessageArgs) (ret *clustermgr.ConsumeMessageRet, err error)
+	Stat(topic string) (ret *clustermgr.QueueStat, err error)
+}
+
+// QueueMgr is an embedded message queue replicated by raft,
+// messages of a topic are appended in order and trimmed after consumed by all groups
+type QueueMgr struct {
+	module string
+	tbl    *kvdb.QueueTable
+}
+
+func NewQueueMgr(db *kvdb.KvDB) (*QueueMgr, error) {
+	tbl, err := kvdb.OpenQueueTable(db)
+	if err != nil {
+		return nil, err
+	}
+	return &QueueMgr{
+		module: moduleName,
+		tbl:    tbl,
+	}, nil
+}
+
+func (q *QueueMgr) Consume(args *clustermgr.ConsumeMessageArgs) (*clustermgr.ConsumeMessageRet, error) {
+	if args.Count <= 0 {
+		args.Count = defaultConsumeCount
+	}
+	if args.Count > maxConsumeCount {
+		args.Count = maxConsumeCount
+	}
+	offset := args.Offset
+	if offset == 0 {
+		committed, err := q.tbl.GetConsumeOffset(args.Topic, args.Group)
+		if err != nil {
+			return nil, err
+		}
+		offset = committed + 1
+	}
+	msgs, err := q.tbl.ListMessages(args.Topic, offset, args.Count)
+	if err != nil {
+		return nil, err
+	}
+	return &clustermgr.ConsumeMessageRet{Msgs: msgs}, nil
+}
+
+func (q *QueueMgr) Stat(topic string) (*clustermgr.QueueStat, error) {
+	first, err := q.tbl.GetFirstOffset(topic)
+	if err != nil {
+		return nil, err
+	}
+	last, err := q.tbl.GetLastOffset(topic)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := q.tbl.ListConsumeOffsets(topic)
+	if err != nil {
+		return nil, err
+	}
+	return &clustermgr.QueueStat{
+		Topic:       topic,
+		FirstOffset: first,
+		LastOffset:  last,
+		Groups:      groups,
+	}, nil
+}
+
+func (q *QueueMgr) produce(ctx context.Context, args *ProduceArgs) error {
+	last, err := q.tbl.GetLastOffset(args.Topic)
+	if err != nil {
+		return err
+	}
+	msgs := make([]*clustermgr.QueueMessage, len(args.Msgs))
+	for i, value := range args.Msgs {
+		msgs[i] = &clustermgr.QueueMessage{
+			Offset: last + uint64(i) + 1,
+			Time:   args.Time,
+			Value:  value,
+		}
+	}
+	trace.SpanFromContextSafe(ctx).Debugf("produce %d messages of topic %s after offset %d", len(msgs), args.Topic, last)
+	return q.tbl.PutMessages(args.Topic, msgs)
+}
+
+// registerGroup keeps the group as nothing committed if it is not registered,
+// so that messages are not trimmed before the group consumes them
+func (q *QueueMgr) registerGroup(ctx context.Context, args *clustermgr.RegisterQueueGroupArgs) error {
+	groups, err := q.tbl.ListConsumeOffsets(args.Topic)
+	if err != nil {
+		return err
+	}
+	if _, ok := groups[args.Group]; ok {
+		return nil
+	}
+	trace.SpanFromContextSafe(ctx).Infof("register group %s of topic %s", args.Group, args.Topic)
+	return q.tbl.SetConsumeOffset(args.Topic, args.Group, 0)
+}
+
+// commit sets the consumed offset of group, the offset never goes back,
+// and messages consumed by all registered groups are trimmed, a group
+// registered but not committed yet keeps all messages
+func (q *QueueMgr) commit(ctx context.Context, args *clustermgr.CommitQueueOffsetArgs) error {
+	span := trace.SpanFromContextSafe(ctx)
+	committed, err := q.tbl.GetConsumeOffset(args.Topic, args.Group)
+	if err != nil {
+		return err
+	}
+	if args.Offset <= committed {
+		span.Debugf("ignore commit of topic %s group %s, offset %d committed %d", args.Topic, args.Group, args.Offset, committed)
+		return nil
+	}
+	if err = q.tbl.SetConsumeOffset(args.Topic, args.Group, args.Offset); err != nil {
+		return err
+	}
+
+	groups, err := q.tbl.ListConsumeOffsets(args.Topic)
+	if err != nil {
+		return err
+	}
+	trimOffset := args.Offset
+	for _, offset := range groups {
+		if offset < trimOffset {
+			trimOffset = offset
+		}
+	}
+	if trimOffset == 0 {
+		return nil
+	}
+	span.Debugf("trim messages of topic %s until offset %d", args.Topic, trimOffset)
+	return q.tbl.TrimMessages(args.Topic, trimOffset)
+}
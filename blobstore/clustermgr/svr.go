@@ -37,6 +37,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/clustermgr/persistence/normaldb"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/persistence/raftdb"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/persistence/volumedb"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/queuemgr"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/scopemgr"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/servicemgr"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/volumemgr"
@@ -127,6 +128,7 @@ type Service struct {
 	DiskMgr   *diskmgr.DiskMgr
 	VolumeMgr *volumemgr.VolumeMgr
 	KvMgr     *kvmgr.KvMgr
+	QueueMgr  *queuemgr.QueueMgr
 
 	dbs map[string]base.SnapshotDB
 	// status indicate service's current state, like normal/snapshot
@@ -228,6 +230,11 @@ func New(cfg *Config) (*Service, error) {
 		log.Fatalf("new kvMgr failed, error: %v", errors.Detail(err))
 	}
 
+	queueMgr, err := queuemgr.NewQueueMgr(kvDB)
+	if err != nil {
+		log.Fatalf("new queueMgr failed, error: %v", errors.Detail(err))
+	}
+
 	configMgr, err := configmgr.New(kvMgr, cfg.ClusterCfg)
 	if err != nil {
 		log.Fatalf("new configMg failed, error: %v", err)
@@ -241,6 +248,7 @@ func New(cfg *Config) (*Service, error) {
 	}
 
 	service.KvMgr = kvMgr
+	service.QueueMgr = queueMgr
 	service.VolumeMgr = volumeMgr
 	service.ConfigMgr = configMgr
 	service.DiskMgr = diskMgr
@@ -0,0 +1,197 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
+	"github.com/cubefs/cubefs/blobstore/clustermgr/kvmgr"
+	apierrors "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/kvstore"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// transcode mapping is stored in kv, the vid is padded to keep the keys in order
+//
+//	for example:
+//		transcode_mapping-0000000012
+const transcodeMappingKeyPrefix = "transcode_mapping-"
+
+func transcodeMappingKey(vid proto.Vid) string {
+	return fmt.Sprintf("%s%010d", transcodeMappingKeyPrefix, vid)
+}
+
+func (s *Service) getTranscodeMapping(vid proto.Vid) (*clustermgr.TranscodeMapping, error) {
+	value, err := s.KvMgr.Get(transcodeMappingKey(vid))
+	if err != nil {
+		return nil, err
+	}
+	mapping := &clustermgr.TranscodeMapping{}
+	if err = json.Unmarshal(value, mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+func (s *Service) isTranscoded(vid proto.Vid) bool {
+	_, err := s.getTranscodeMapping(vid)
+	return err == nil
+}
+
+func (s *Service) VolumeTranscodeMappingSet(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.TranscodeMapping)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept VolumeTranscodeMappingSet request, args: %+v", args)
+
+	if !args.CodeMode.IsValid() || !args.DstCodeMode.IsValid() || args.Vid == args.DstVid ||
+		(args.Status != clustermgr.TranscodeMappingStatusCopying && args.Status != clustermgr.TranscodeMappingStatusDone) {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	if err := s.raftNode.ReadIndex(ctx); err != nil {
+		span.Errorf("read index error: %v", err)
+		c.RespondError(apierrors.ErrRaftReadIndex)
+		return
+	}
+
+	src, err := s.VolumeMgr.GetVolumeInfo(ctx, args.Vid)
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	dst, err := s.VolumeMgr.GetVolumeInfo(ctx, args.DstVid)
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	// the source volume must be locked to stop new writes
+	if src.CodeMode != args.CodeMode || dst.CodeMode != args.DstCodeMode || src.Status != proto.VolumeStatusLock {
+		span.Warnf("volumes not match the mapping, src: %+v, dst: %+v", src.VolumeInfoBase, dst.VolumeInfoBase)
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	// the destination volume can not be a transcoded one
+	if s.isTranscoded(args.DstVid) {
+		span.Warnf("destination volume %d has been transcoded", args.DstVid)
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	// the mapping can not be changed once the reads are redirected
+	if old, err := s.getTranscodeMapping(args.Vid); err == nil && old.Done() && old.DstVid != args.DstVid {
+		span.Warnf("volume %d has been transcoded into %d", args.Vid, old.DstVid)
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+
+	args.UpdateTime = time.Now().Unix()
+	value, err := json.Marshal(args)
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	data, err := json.Marshal(&clustermgr.SetKvArgs{Key: transcodeMappingKey(args.Vid), Value: value})
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	err = s.raftNode.Propose(ctx, base.EncodeProposeInfo(s.KvMgr.GetModuleName(), kvmgr.OperTypeSetKv, data, base.ProposeContext{ReqID: span.TraceID()}))
+	if err != nil {
+		span.Errorf("raft propose failed, error:%v", err)
+		c.RespondError(apierrors.ErrRaftPropose)
+	}
+}
+
+func (s *Service) VolumeTranscodeMappingGet(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.GetTranscodeMappingArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept VolumeTranscodeMappingGet request, args: %v", args)
+
+	if err := s.raftNode.ReadIndex(ctx); err != nil {
+		span.Errorf("read index error: %v", err)
+		c.RespondError(apierrors.ErrRaftReadIndex)
+		return
+	}
+	mapping, err := s.getTranscodeMapping(args.Vid)
+	if err == kvstore.ErrNotFound {
+		c.RespondError(apierrors.ErrNotFound)
+		return
+	}
+	if err != nil {
+		span.Errorf("get transcode mapping failed, vid: %d, error: %v", args.Vid, err)
+		c.RespondError(apierrors.ErrCMUnexpect)
+		return
+	}
+	c.RespondJSON(mapping)
+}
+
+func (s *Service) VolumeTranscodeMappingList(c *rpc.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	args := new(clustermgr.ListTranscodeMappingArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	span.Debugf("accept VolumeTranscodeMappingList request, args: %+v", args)
+
+	if err := s.raftNode.ReadIndex(ctx); err != nil {
+		span.Errorf("list read index error: %v", err)
+		c.RespondError(apierrors.ErrRaftReadIndex)
+		return
+	}
+
+	opts := &clustermgr.ListKvOpts{Prefix: transcodeMappingKeyPrefix, Count: args.Count}
+	if args.Marker != proto.InvalidVid {
+		opts.Marker = transcodeMappingKey(args.Marker)
+	}
+	kvs, err := s.KvMgr.List(opts)
+	if err != nil {
+		span.Errorf("list failed, error:%v", err)
+		c.RespondError(errors.Info(apierrors.ErrUnexpected).Detail(err))
+		return
+	}
+
+	ret := &clustermgr.ListTranscodeMappingRet{Mappings: make([]*clustermgr.TranscodeMapping, 0, len(kvs.Kvs))}
+	for _, kv := range kvs.Kvs {
+		mapping := &clustermgr.TranscodeMapping{}
+		if err = json.Unmarshal(kv.Value, mapping); err != nil {
+			span.Errorf("unmarshal transcode mapping %s failed, error:%v", kv.Key, err)
+			c.RespondError(apierrors.ErrCMUnexpect)
+			return
+		}
+		ret.Mappings = append(ret.Mappings, mapping)
+	}
+	if kvs.Marker != "" && len(ret.Mappings) > 0 {
+		ret.Marker = ret.Mappings[len(ret.Mappings)-1].Vid
+	}
+	c.RespondJSON(ret)
+}
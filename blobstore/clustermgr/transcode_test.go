@@ -0,0 +1,101 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package clustermgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+func TestService_VolumeTranscodeMapping(t *testing.T) {
+	testService, clean := initServiceWithData()
+	defer clean()
+	cmClient := initTestClusterClient(testService)
+	ctx := newCtx()
+
+	mapping := &clustermgr.TranscodeMapping{
+		Vid:         proto.Vid(1),
+		CodeMode:    codemode.EC15P12,
+		DstVid:      proto.Vid(2),
+		DstCodeMode: codemode.EC15P12,
+		Status:      clustermgr.TranscodeMappingStatusCopying,
+	}
+
+	// source volume is not locked
+	require.Error(t, cmClient.SetTranscodeMapping(ctx, mapping))
+	require.NoError(t, cmClient.LockVolume(ctx, &clustermgr.LockVolumeArgs{Vid: 1}))
+
+	// code mode not match
+	mapping.DstCodeMode = codemode.EC6P6
+	require.Error(t, cmClient.SetTranscodeMapping(ctx, mapping))
+	mapping.DstCodeMode = codemode.EC15P12
+	// invalid status
+	mapping.Status = 0
+	require.Error(t, cmClient.SetTranscodeMapping(ctx, mapping))
+
+	mapping.Status = clustermgr.TranscodeMappingStatusCopying
+	require.NoError(t, cmClient.SetTranscodeMapping(ctx, mapping))
+	ret, err := cmClient.GetTranscodeMapping(ctx, &clustermgr.GetTranscodeMappingArgs{Vid: 1})
+	require.NoError(t, err)
+	require.Equal(t, proto.Vid(2), ret.DstVid)
+	require.False(t, ret.Done())
+
+	mapping.Status = clustermgr.TranscodeMappingStatusDone
+	require.NoError(t, cmClient.SetTranscodeMapping(ctx, mapping))
+	ret, err = cmClient.GetTranscodeMapping(ctx, &clustermgr.GetTranscodeMappingArgs{Vid: 1})
+	require.NoError(t, err)
+	require.True(t, ret.Done())
+
+	// done mapping can not be changed
+	mapping.DstVid = 3
+	require.Error(t, cmClient.SetTranscodeMapping(ctx, mapping))
+
+	// transcoded volume can not be the destination
+	require.NoError(t, cmClient.LockVolume(ctx, &clustermgr.LockVolumeArgs{Vid: 3}))
+	require.Error(t, cmClient.SetTranscodeMapping(ctx, &clustermgr.TranscodeMapping{
+		Vid: 3, CodeMode: codemode.EC15P12, DstVid: 1, DstCodeMode: codemode.EC15P12,
+		Status: clustermgr.TranscodeMappingStatusCopying,
+	}))
+	require.NoError(t, cmClient.SetTranscodeMapping(ctx, &clustermgr.TranscodeMapping{
+		Vid: 3, CodeMode: codemode.EC15P12, DstVid: 4, DstCodeMode: codemode.EC15P12,
+		Status: clustermgr.TranscodeMappingStatusCopying,
+	}))
+
+	_, err = cmClient.GetTranscodeMapping(ctx, &clustermgr.GetTranscodeMappingArgs{Vid: 5})
+	require.Error(t, err)
+
+	// list mappings
+	list, err := cmClient.ListTranscodeMapping(ctx, &clustermgr.ListTranscodeMappingArgs{Count: 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(list.Mappings))
+	require.Equal(t, proto.Vid(1), list.Mappings[0].Vid)
+	require.Equal(t, proto.Vid(1), list.Marker)
+	list, err = cmClient.ListTranscodeMapping(ctx, &clustermgr.ListTranscodeMappingArgs{Marker: list.Marker, Count: 10})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(list.Mappings))
+	require.Equal(t, proto.Vid(3), list.Mappings[0].Vid)
+	require.Equal(t, proto.InvalidVid, list.Marker)
+
+	// transcoded volume keeps locked
+	require.NoError(t, cmClient.UnlockVolume(ctx, &clustermgr.UnlockVolumeArgs{Vid: 1}))
+	vol, err := cmClient.GetVolumeInfo(ctx, &clustermgr.GetVolumeArgs{Vid: 1})
+	require.NoError(t, err)
+	require.Equal(t, proto.VolumeStatusLock, vol.Status)
+}
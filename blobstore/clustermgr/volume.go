@@ -253,6 +253,12 @@ func (s *Service) VolumeUnlock(c *rpc.Context) {
 	}
 	span.Debugf("accept VolumeUnlock request, args: %v", args)
 
+	// a transcoded volume keeps locked, it should never be written again
+	if s.isTranscoded(args.Vid) {
+		span.Warnf("volume %d is transcoded and keeps locked", args.Vid)
+		c.Respond()
+		return
+	}
 	c.RespondError(s.VolumeMgr.UnlockVolume(ctx, args.Vid))
 }
 
@@ -315,21 +315,30 @@ func (c *Tactic) LocalStripe(index int) (localStripe []int, n, m int) {
 		return nil, 0, 0
 	}
 
-	n, m, l := c.N/c.AZCount, c.M/c.AZCount, c.L/c.AZCount
-	var azIdx int
-	if index < c.N {
-		azIdx = index / n
-	} else if index < c.N+c.M {
-		azIdx = (index - c.N) / m
-	} else if index < c.N+c.M+c.L {
-		azIdx = (index - c.N - c.M) / l
-	} else {
+	azIdx := c.AZIndex(index)
+	if azIdx < 0 {
 		return nil, 0, 0
 	}
-
 	return c.LocalStripeInAZ(azIdx)
 }
 
+// AZIndex returns the az index of shard index, returns -1 if index is out of range
+func (c *Tactic) AZIndex(index int) int {
+	n, m, l := c.N/c.AZCount, c.M/c.AZCount, c.L/c.AZCount
+	switch {
+	case index < 0:
+		return -1
+	case index < c.N:
+		return index / n
+	case index < c.N+c.M:
+		return (index - c.N) / m
+	case index < c.N+c.M+c.L:
+		return (index - c.N - c.M) / l
+	default:
+		return -1
+	}
+}
+
 // LocalStripeInAZ get local stripe in az index
 func (c *Tactic) LocalStripeInAZ(azIndex int) (localStripe []int, n, m int) {
 	if c.L == 0 {
@@ -192,6 +192,32 @@ func TestLocalStripe(t *testing.T) {
 	}
 }
 
+func TestAZIndex(t *testing.T) {
+	cases := []struct {
+		mode  CodeMode
+		index int
+		az    int
+	}{
+		{EC6P6, -1, -1},
+		{EC6P6, 0, 0},
+		{EC6P6, 2, 1},
+		{EC6P6, 6, 0},
+		{EC6P6, 11, 2},
+		{EC6P6, 12, -1},
+		{EC6P10L2, 2, 0},
+		{EC6P10L2, 3, 1},
+		{EC6P10L2, 10, 0},
+		{EC6P10L2, 11, 1},
+		{EC6P10L2, 16, 0},
+		{EC6P10L2, 17, 1},
+		{EC6P10L2, 18, -1},
+	}
+	for _, cs := range cases {
+		tactic := cs.mode.Tactic()
+		require.Equal(t, cs.az, tactic.AZIndex(cs.index), cs)
+	}
+}
+
 func TestLocalStripeInAZ(t *testing.T) {
 	cases := []struct {
 		mode    CodeMode
@@ -0,0 +1,57 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package consul
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/consul/api"
+
+	"github.com/cubefs/cubefs/blobstore/common/discovery"
+)
+
+var (
+	_ discovery.Discovery = (*consulDiscovery)(nil)
+	_ discovery.Registrar = (*Client)(nil)
+)
+
+type consulDiscovery struct {
+	client *api.Client
+}
+
+// NewDiscovery returns discovery with passing health checks of consul services.
+func NewDiscovery(client *api.Client) discovery.Discovery {
+	return &consulDiscovery{client: client}
+}
+
+func (d *consulDiscovery) Hosts(ctx context.Context, service string) ([]string, error) {
+	services, _, err := d.client.Health().Service(service, "", true, (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, 0, len(services))
+	for _, s := range services {
+		address := s.Service.Address
+		if address == "" {
+			address = s.Node.Address
+		}
+		hosts = append(hosts, fmt.Sprintf("http://%s:%d", address, s.Service.Port))
+	}
+	if len(hosts) == 0 {
+		return nil, discovery.ErrNoAvailableHost
+	}
+	return hosts, nil
+}
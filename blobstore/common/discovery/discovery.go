@@ -0,0 +1,89 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package discovery defines the backend of service discovery.
+// Built-in backends are clustermgr service manager, DNS SRV records and consul.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/util/log"
+)
+
+// ErrNoAvailableHost no available host of the service
+var ErrNoAvailableHost = errors.New("discovery: no available host")
+
+// Discovery discovers hosts of service.
+type Discovery interface {
+	// Hosts returns available hosts of service,
+	// host is with scheme, like http://127.0.0.1:9500.
+	Hosts(ctx context.Context, service string) ([]string, error)
+}
+
+// Registrar keeps the registered service node alive until closed.
+type Registrar interface {
+	Close()
+}
+
+// Watch gets hosts of service every interval, calls onChange if hosts
+// were changed compared with the last hosts, returns after stop closed.
+func Watch(d Discovery, service string, hosts []string, interval time.Duration,
+	stop <-chan struct{}, onChange func(hosts []string)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := sortedCopy(hosts)
+	for {
+		select {
+		case <-ticker.C:
+			newHosts, err := d.Hosts(context.Background(), service)
+			if err != nil {
+				log.Warnf("update hosts of %s failed: %v", service, err)
+				continue
+			}
+			newHosts = sortedCopy(newHosts)
+			if IsChanged(last, newHosts) {
+				last = newHosts
+				onChange(sortedCopy(newHosts))
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// IsChanged returns true if the two host sets are different.
+func IsChanged(a, b []string) bool {
+	if len(a) != len(b) {
+		return true
+	}
+	a, b = sortedCopy(a), sortedCopy(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedCopy(hosts []string) []string {
+	sorted := make([]string, len(hosts))
+	copy(sorted, hosts)
+	sort.Strings(sorted)
+	return sorted
+}
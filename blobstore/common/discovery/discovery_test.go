@@ -0,0 +1,109 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockResolver struct {
+	lookups []string
+	addrs   []*net.SRV
+	err     error
+}
+
+func (r *mockResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	r.lookups = append(r.lookups, "_"+service+"._"+proto+"."+name)
+	return "", r.addrs, r.err
+}
+
+type mockDiscovery struct {
+	sync.Mutex
+	hosts []string
+	err   error
+}
+
+func (d *mockDiscovery) Hosts(ctx context.Context, service string) ([]string, error) {
+	d.Lock()
+	defer d.Unlock()
+	return d.hosts, d.err
+}
+
+func (d *mockDiscovery) set(hosts []string, err error) {
+	d.Lock()
+	d.hosts, d.err = hosts, err
+	d.Unlock()
+}
+
+func TestDiscoveryDNS(t *testing.T) {
+	ctx := context.Background()
+	resolver := &mockResolver{err: errors.New("no such host")}
+	d := NewDNS("blobstore.local", resolver)
+
+	_, err := d.Hosts(ctx, "ACCESS")
+	require.Error(t, err)
+	require.Equal(t, []string{"_access._tcp.blobstore.local"}, resolver.lookups)
+
+	resolver.err = nil
+	_, err = d.Hosts(ctx, "access")
+	require.ErrorIs(t, err, ErrNoAvailableHost)
+
+	resolver.addrs = []*net.SRV{
+		{Target: "access-1.blobstore.local.", Port: 9500},
+		{Target: ".", Port: 9500},
+		{Target: "10.0.0.2", Port: 9501},
+	}
+	hosts, err := d.Hosts(ctx, "access")
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://access-1.blobstore.local:9500", "http://10.0.0.2:9501"}, hosts)
+}
+
+func TestDiscoveryWatch(t *testing.T) {
+	require.False(t, IsChanged(nil, []string{}))
+	require.False(t, IsChanged([]string{"b", "a"}, []string{"a", "b"}))
+	require.True(t, IsChanged([]string{"a"}, []string{"a", "b"}))
+	require.True(t, IsChanged([]string{"a", "c"}, []string{"a", "b"}))
+
+	d := &mockDiscovery{hosts: []string{"b", "a"}}
+	changes := make(chan []string, 8)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		Watch(d, "access", []string{"a", "b"}, 10*time.Millisecond, stop, func(hosts []string) {
+			changes <- hosts
+		})
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, 0, len(changes))
+
+	d.set(nil, errors.New("unavailable"))
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, 0, len(changes))
+
+	d.set([]string{"c", "a"}, nil)
+	require.Equal(t, []string{"a", "c"}, <-changes)
+
+	close(stop)
+	<-done
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// SRVResolver looks up SRV records, *net.Resolver implements it.
+type SRVResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+type dnsDiscovery struct {
+	domain   string
+	resolver SRVResolver
+}
+
+// NewDNS returns discovery with DNS SRV records,
+// hosts of service are looked up from _<service>._tcp.<domain>.
+// The default resolver is used if resolver is nil.
+func NewDNS(domain string, resolver SRVResolver) Discovery {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &dnsDiscovery{domain: domain, resolver: resolver}
+}
+
+func (d *dnsDiscovery) Hosts(ctx context.Context, service string) ([]string, error) {
+	_, addrs, err := d.resolver.LookupSRV(ctx, strings.ToLower(service), "tcp", d.domain)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		target := strings.TrimSuffix(addr.Target, ".")
+		if target == "" {
+			continue
+		}
+		hosts = append(hosts, fmt.Sprintf("http://%s", net.JoinHostPort(target, fmt.Sprint(addr.Port))))
+	}
+	if len(hosts) == 0 {
+		return nil, ErrNoAvailableHost
+	}
+	return hosts, nil
+}
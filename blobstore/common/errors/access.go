@@ -21,6 +21,7 @@ const (
 	CodeAccessServiceDiscovery = 551 // service discovery for access api client
 	CodeAccessLimited          = 552 // read write limited for access api client
 	CodeAccessExceedSize       = 553 // exceed max size
+	CodeAccessObjectDisabled   = 554 // named object is disabled
 )
 
 // errro of access
@@ -30,4 +31,5 @@ var (
 	ErrAccessServiceDiscovery = Error(CodeAccessServiceDiscovery)
 	ErrAccessLimited          = Error(CodeAccessLimited)
 	ErrAccessExceedSize       = Error(CodeAccessExceedSize)
+	ErrAccessObjectDisabled   = Error(CodeAccessObjectDisabled)
 )
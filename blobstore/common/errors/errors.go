@@ -53,6 +53,7 @@ var errCodeMap = map[int]string{
 	CodeAccessServiceDiscovery: "access client service discovery disconnect",
 	CodeAccessLimited:          "access limited",
 	CodeAccessExceedSize:       "access exceed object size",
+	CodeAccessObjectDisabled:   "access named object disabled",
 
 	// clustermgr
 	CodeCMUnexpect:                   "cm: unexpected error",
@@ -84,6 +85,7 @@ var errCodeMap = map[int]string{
 	CodeNotSupportIdle:               "list volume v2 not support idle status",
 	CodeDiskIsDropping:               "dropping disk not allow change state or set readonly",
 	CodeRejectDeleteSystemConfig:     "reject delete system config",
+	CodeObjectMetaConflict:           "object meta changed by others",
 	CodeRegisterServiceInvalidParams: "register service params is invalid",
 
 	// scheduler
@@ -93,6 +95,7 @@ var errCodeMap = map[int]string{
 	CodeNoAvaliableVolume: "this codemode has no avaliable volume",
 	CodeAllocBidFromCm:    "alloc bid from clustermgr error",
 	CodeClusterIDNotMatch: "clusterId not match",
+	CodeReplicateDisabled: "blob replicate is not enabled",
 
 	// blobnode
 	CodeInvalidParam:   "blobnode: invalid params",
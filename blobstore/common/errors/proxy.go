@@ -18,10 +18,12 @@ const (
 	CodeNoAvaliableVolume = 801
 	CodeAllocBidFromCm    = 802
 	CodeClusterIDNotMatch = 803
+	CodeReplicateDisabled = 804
 )
 
 var (
 	ErrNoAvaliableVolume = Error(CodeNoAvaliableVolume)
 	ErrAllocBidFromCm    = Error(CodeAllocBidFromCm)
 	ErrClusterIDNotMatch = Error(CodeClusterIDNotMatch)
+	ErrReplicateDisabled = Error(CodeReplicateDisabled)
 )
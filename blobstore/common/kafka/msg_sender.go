@@ -29,6 +29,12 @@ type MsgProducer interface {
 	SendMessages(topic string, msgs [][]byte) (err error)
 }
 
+// KeyedMsgProducer sends the messages of the same key to the same partition,
+// so they are consumed in the order sent
+type KeyedMsgProducer interface {
+	SendKeyedMessage(topic string, key, msg []byte) (err error)
+}
+
 type ProducerCfg struct {
 	BrokerList []string `json:"broker_list"`
 	Topic      string   `json:"topic"`
@@ -72,6 +78,18 @@ func (p *Producer) SendMessage(topic string, msg []byte) (err error) {
 	return err
 }
 
+// SendKeyedMessage sends message to the partition hashed by key
+func (p *Producer) SendKeyedMessage(topic string, key, msg []byte) (err error) {
+	m := &sarama.ProducerMessage{
+		Topic:     topic,
+		Key:       sarama.ByteEncoder(key),
+		Timestamp: time.Now(),
+		Value:     sarama.ByteEncoder(msg),
+	}
+	_, _, err = p.SyncProducer.SendMessage(m)
+	return err
+}
+
 func (p *Producer) SendMessages(topic string, msgs [][]byte) (err error) {
 	sendMsgs := make([]*sarama.ProducerMessage, len(msgs))
 	for idx, msg := range msgs {
@@ -33,7 +33,7 @@ func TestSendMessage(t *testing.T) {
 	metadataResponse.AddTopicPartition(testTopic, 0, leader.BrokerID(), nil, nil, nil, sarama.ErrNoError)
 	seedBroker.Returns(metadataResponse)
 
-	for i := 0; i < 3; i++ {
+	for i := 0; i < 4; i++ {
 		prodSuccess := new(sarama.ProduceResponse)
 		prodSuccess.AddTopicPartition(testTopic, 0, sarama.ErrNoError)
 		leader.Returns(prodSuccess)
@@ -48,4 +48,7 @@ func TestSendMessage(t *testing.T) {
 
 	err = cli.SendMessages(testTopic, [][]byte{[]byte(msg), []byte(msg)})
 	require.NoError(t, err)
+
+	err = cli.SendKeyedMessage(testTopic, []byte("key"), []byte(msg))
+	require.NoError(t, err)
 }
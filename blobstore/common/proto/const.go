@@ -23,6 +23,13 @@ const (
 	ServiceNameBlobNode  = "BLOBNODE"
 	ServiceNameProxy     = "PROXY"
 	ServiceNameScheduler = "SCHEDULER"
+	ServiceNameAccess    = "ACCESS"
+)
+
+// message queue backends of blob delete and shard repair messages
+const (
+	MQBackendKafka      = "kafka"
+	MQBackendClusterMgr = "clustermgr"
 )
 
 type DiskStatus uint8
@@ -106,11 +113,17 @@ const (
 	CodeModeConfigKey    = "code_mode"
 	VolumeReserveSizeKey = "volume_reserve_size"
 	VolumeChunkSizeKey   = "volume_chunk_size"
+
+	// DataEncryptionKeysConfigKey cluster keys to wrap data keys of blobnode disks
+	DataEncryptionKeysConfigKey = "data_encryption_keys"
+	// BlobDedupConfigKey switch of blob deduplication in cluster,
+	// references of deduplicated blobs are honored once it has been set
+	BlobDedupConfigKey = "blob_dedup"
 )
 
 func IsSysConfigKey(key string) bool {
 	switch key {
-	case VolumeChunkSizeKey, VolumeReserveSizeKey, CodeModeConfigKey:
+	case VolumeChunkSizeKey, VolumeReserveSizeKey, CodeModeConfigKey, DataEncryptionKeysConfigKey, BlobDedupConfigKey:
 		return true
 	default:
 		return false
@@ -15,6 +15,8 @@
 package proto
 
 import (
+	"fmt"
+
 	"github.com/cubefs/cubefs/blobstore/util/errors"
 )
 
@@ -61,6 +63,19 @@ type DeleteMsg struct {
 	Time          int64           `json:"time"`
 	ReqId         string          `json:"req_id"`
 	BlobDelStages BlobDeleteStage `json:"blob_del_stages"`
+	// Seq is the index of blob in the delete request
+	Seq int `json:"seq,omitempty"`
+	// DedupReleased the reference of blob has been released in dedup index
+	DedupReleased bool `json:"dedup_released,omitempty"`
+}
+
+// DedupRefID returns the id of reference released by this message,
+// the same blob may be deleted more than once in one request.
+func (msg *DeleteMsg) DedupRefID() string {
+	if msg.ReqId == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s-%d", msg.ReqId, msg.Seq)
 }
 
 func (msg *DeleteMsg) IsValid() bool {
@@ -101,3 +116,30 @@ func (msg *ShardRepairMsg) IsValid() bool {
 	}
 	return true
 }
+
+// ReplicateOp is the operation of blob replicate message
+type ReplicateOp string
+
+// operations of blob replicate message
+const (
+	ReplicateOpPut    ReplicateOp = "put"
+	ReplicateOpDelete ReplicateOp = "delete"
+)
+
+// ReplicateMsg is the change log of locations to replicate to another cluster,
+// Location is hex encoded location of access.
+type ReplicateMsg struct {
+	ClusterID ClusterID   `json:"cluster_id"`
+	Op        ReplicateOp `json:"op"`
+	Location  string      `json:"location"`
+	Retry     int         `json:"retry"`
+	Time      int64       `json:"time"`
+	ReqId     string      `json:"req_id"`
+}
+
+func (msg *ReplicateMsg) IsValid() bool {
+	if msg.Op != ReplicateOpPut && msg.Op != ReplicateOpDelete {
+		return false
+	}
+	return msg.Location != ""
+}
@@ -43,12 +43,15 @@ const (
 	TaskTypeVolumeInspect TaskType = "volume_inspect"
 	TaskTypeShardRepair   TaskType = "shard_repair"
 	TaskTypeBlobDelete    TaskType = "blob_delete"
+	TaskTypeTranscode     TaskType = "transcode"
+	TaskTypeBlobReplicate TaskType = "blob_replicate"
 )
 
 func (t TaskType) Valid() bool {
 	switch t {
 	case TaskTypeDiskRepair, TaskTypeBalance, TaskTypeDiskDrop, TaskTypeManualMigrate,
-		TaskTypeVolumeInspect, TaskTypeShardRepair, TaskTypeBlobDelete:
+		TaskTypeVolumeInspect, TaskTypeShardRepair, TaskTypeBlobDelete, TaskTypeTranscode,
+		TaskTypeBlobReplicate:
 		return true
 	default:
 		return false
@@ -189,6 +192,62 @@ func (inspect *VolumeInspectRet) Err() error {
 	return errors.New(inspect.InspectErrStr)
 }
 
+type TranscodeState uint8
+
+const (
+	TranscodeStateInited   TranscodeState = iota + 1 // waiting for the source volume to be locked
+	TranscodeStatePrepared                           // copying the blobs into the destination volume
+	TranscodeStateCopied                             // reads are redirected, cleaning the source volume
+	TranscodeStateFinished
+)
+
+// TranscodeTask re-encodes all the blobs of the source volume into the destination
+// volume of another code mode, the blob ids are kept unchanged.
+type TranscodeTask struct {
+	TaskID string         `json:"task_id"`
+	State  TranscodeState `json:"state"`
+
+	SrcVid      Vid               `json:"src_vid"`
+	SrcCodeMode codemode.CodeMode `json:"src_code_mode"`
+	Sources     []VunitLocation   `json:"sources"`
+
+	DstVid       Vid               `json:"dst_vid"`
+	DstCodeMode  codemode.CodeMode `json:"dst_code_mode"`
+	Destinations []VunitLocation   `json:"destinations"`
+
+	BlobCnt  int64 `json:"blob_cnt"`  // blobs transcoded by the worker
+	DataSize int64 `json:"data_size"` // source data shards size transcoded by the worker
+
+	Ctime     string `json:"ctime"`
+	MTime     string `json:"mtime"`
+	StateTime int64  `json:"state_time"` // unix second of the last state change
+
+	WorkerRedoCnt uint8 `json:"worker_redo_cnt"`
+}
+
+func (t *TranscodeTask) IsValid() bool {
+	return t.SrcCodeMode.IsValid() && t.DstCodeMode.IsValid() &&
+		t.SrcVid != t.DstVid &&
+		len(t.Sources) == t.SrcCodeMode.GetShardNum() && CheckVunitLocations(t.Sources) &&
+		len(t.Destinations) == t.DstCodeMode.GetShardNum() && CheckVunitLocations(t.Destinations)
+}
+
+// TranscodeRet result of a transcode task stage run by worker.
+type TranscodeRet struct {
+	TaskID   string         `json:"task_id"`
+	State    TranscodeState `json:"state"` // the state of the task when it was acquired
+	BlobCnt  int64          `json:"blob_cnt"`
+	DataSize int64          `json:"data_size"`
+	ErrStr   string         `json:"err_str"`
+}
+
+func (ret *TranscodeRet) Err() error {
+	if len(ret.ErrStr) == 0 {
+		return nil
+	}
+	return errors.New(ret.ErrStr)
+}
+
 type ShardRepairTask struct {
 	Bid      BlobID            `json:"bid"`
 	CodeMode codemode.CodeMode `json:"code_mode"`
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/cubefs/cubefs/blobstore/proxy/mq (interfaces: BlobDeleteHandler,ShardRepairHandler,Producer)
+// Source: github.com/cubefs/cubefs/blobstore/proxy/mq (interfaces: BlobDeleteHandler,ShardRepairHandler,BlobReplicateHandler,Producer)
 
 // Package mock is a generated GoMock package.
 package mock
@@ -86,6 +86,43 @@ func (mr *MockShardRepairHandlerMockRecorder) SendShardRepairMsg(arg0, arg1 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendShardRepairMsg", reflect.TypeOf((*MockShardRepairHandler)(nil).SendShardRepairMsg), arg0, arg1)
 }
 
+// MockBlobReplicateHandler is a mock of BlobReplicateHandler interface.
+type MockBlobReplicateHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobReplicateHandlerMockRecorder
+}
+
+// MockBlobReplicateHandlerMockRecorder is the mock recorder for MockBlobReplicateHandler.
+type MockBlobReplicateHandlerMockRecorder struct {
+	mock *MockBlobReplicateHandler
+}
+
+// NewMockBlobReplicateHandler creates a new mock instance.
+func NewMockBlobReplicateHandler(ctrl *gomock.Controller) *MockBlobReplicateHandler {
+	mock := &MockBlobReplicateHandler{ctrl: ctrl}
+	mock.recorder = &MockBlobReplicateHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobReplicateHandler) EXPECT() *MockBlobReplicateHandlerMockRecorder {
+	return m.recorder
+}
+
+// SendReplicateMsg mocks base method.
+func (m *MockBlobReplicateHandler) SendReplicateMsg(arg0 context.Context, arg1 *proxy.ReplicateArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendReplicateMsg", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendReplicateMsg indicates an expected call of SendReplicateMsg.
+func (mr *MockBlobReplicateHandlerMockRecorder) SendReplicateMsg(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendReplicateMsg", reflect.TypeOf((*MockBlobReplicateHandler)(nil).SendReplicateMsg), arg0, arg1)
+}
+
 // MockProducer is a mock of Producer interface.
 type MockProducer struct {
 	ctrl     *gomock.Controller
@@ -109,6 +146,20 @@ func (m *MockProducer) EXPECT() *MockProducerMockRecorder {
 	return m.recorder
 }
 
+// SendKeyedMessage mocks base method.
+func (m *MockProducer) SendKeyedMessage(arg0 string, arg1, arg2 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendKeyedMessage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendKeyedMessage indicates an expected call of SendKeyedMessage.
+func (mr *MockProducerMockRecorder) SendKeyedMessage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendKeyedMessage", reflect.TypeOf((*MockProducer)(nil).SendKeyedMessage), arg0, arg1, arg2)
+}
+
 // SendMessage mocks base method.
 func (m *MockProducer) SendMessage(arg0 string, arg1 []byte) error {
 	m.ctrl.T.Helper()
@@ -79,3 +79,36 @@ func (s *Service) SendDeleteMessage(c *rpc.Context) {
 
 	c.Respond()
 }
+
+// SendReplicateMessage send replicate message to kafka
+// 1. message from access after put or delete of location
+func (s *Service) SendReplicateMessage(c *rpc.Context) {
+	span := trace.SpanFromContextSafe(c.Request.Context())
+	ctx := trace.ContextWithSpan(c.Request.Context(), span)
+
+	args := new(api.ReplicateArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	span.Infof("accept SendReplicateMessage request, args: %v", args)
+	if args.ClusterID != s.ClusterID {
+		span.Errorf("clusterID not match: info[%+v], self clusterID[%d]", args, s.ClusterID)
+		c.RespondError(errcode.ErrClusterIDNotMatch)
+		return
+	}
+	if s.blobReplicateMgr == nil {
+		c.RespondError(errcode.ErrReplicateDisabled)
+		return
+	}
+
+	err := s.blobReplicateMgr.SendReplicateMsg(ctx, args)
+	if err != nil {
+		span.Errorf("send replicate message failed: %+v", err)
+		c.RespondError(err)
+		return
+	}
+
+	c.Respond()
+}
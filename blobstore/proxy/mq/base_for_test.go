@@ -33,6 +33,12 @@ func newProducer(tb testing.TB) Producer {
 		}
 		return nil
 	})
+	producer.EXPECT().SendKeyedMessage(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(topic string, key, msg []byte) (err error) {
+		if topic == "priority" {
+			return ErrSendMessage
+		}
+		return nil
+	})
 	producer.EXPECT().SendMessages(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(topic string, msgs [][]byte) (err error) {
 		if len(msgs) == 2 {
 			return ErrSendMessage
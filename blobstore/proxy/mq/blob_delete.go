@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/api/proxy"
 	"github.com/cubefs/cubefs/blobstore/common/kafka"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
@@ -34,11 +35,13 @@ type BlobDeleteHandler interface {
 // Producer is used to send messages to kafka
 type Producer interface {
 	kafka.MsgProducer
+	kafka.KeyedMsgProducer
 }
 
 // BlobDeleteConfig is blob delete config
 type BlobDeleteConfig struct {
 	Topic        string            `json:"topic"`
+	Backend      string            `json:"backend"`
 	MsgSenderCfg kafka.ProducerCfg `json:"msg_sender_cfg"`
 }
 
@@ -49,8 +52,8 @@ type blobDeleteMgr struct {
 }
 
 // NewBlobDeleteMgr returns blob delete manager to handle delete message
-func NewBlobDeleteMgr(cfg BlobDeleteConfig) (*blobDeleteMgr, error) {
-	delMsgSender, err := kafka.NewProducer(&cfg.MsgSenderCfg)
+func NewBlobDeleteMgr(cfg BlobDeleteConfig, cmcli clustermgr.APIQueue) (*blobDeleteMgr, error) {
+	delMsgSender, err := NewProducer(cfg.Backend, &cfg.MsgSenderCfg, cmcli)
 	if err != nil {
 		return nil, err
 	}
@@ -66,13 +69,14 @@ func (d *blobDeleteMgr) SendDeleteMsg(ctx context.Context, info *proxy.DeleteArg
 	span := trace.SpanFromContextSafe(ctx)
 
 	msgs := make([][]byte, 0, len(info.Blobs))
-	for _, blobInfo := range info.Blobs {
+	for idx, blobInfo := range info.Blobs {
 		msg := proto.DeleteMsg{
 			ClusterID: info.ClusterID,
 			Vid:       blobInfo.Vid,
 			Bid:       blobInfo.Bid,
 			Time:      time.Now().Unix(),
 			ReqId:     span.TraceID(),
+			Seq:       idx,
 		}
 
 		msgByte, err := json.Marshal(msg)
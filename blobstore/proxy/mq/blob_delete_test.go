@@ -63,7 +63,7 @@ func TestNewBlobDeleteMgr(t *testing.T) {
 	mgr, err := NewBlobDeleteMgr(BlobDeleteConfig{
 		Topic:        "my_topic",
 		MsgSenderCfg: kafka.ProducerCfg{BrokerList: []string{seedBroker.Addr()}},
-	})
+	}, nil)
 	require.NoError(t, err)
 
 	info := &proxy.DeleteArgs{
@@ -82,6 +82,6 @@ func TestNewBlobDeleteMgr(t *testing.T) {
 	_, err = NewBlobDeleteMgr(BlobDeleteConfig{
 		Topic:        "",
 		MsgSenderCfg: kafka.ProducerCfg{},
-	})
+	}, nil)
 	require.Error(t, err)
 }
@@ -0,0 +1,94 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/api/proxy"
+	"github.com/cubefs/cubefs/blobstore/common/kafka"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+)
+
+// BlobReplicateHandler stream http handler
+type BlobReplicateHandler interface {
+	SendReplicateMsg(ctx context.Context, info *proxy.ReplicateArgs) error
+}
+
+// BlobReplicateConfig is blob replicate config
+type BlobReplicateConfig struct {
+	Topic        string            `json:"topic"`
+	Backend      string            `json:"backend"`
+	MsgSenderCfg kafka.ProducerCfg `json:"msg_sender_cfg"`
+}
+
+// blobReplicateMgr is blob replicate manager
+type blobReplicateMgr struct {
+	topic              string
+	replicateMsgSender Producer
+}
+
+// NewBlobReplicateMgr returns blob replicate manager to handle replicate message
+func NewBlobReplicateMgr(cfg BlobReplicateConfig, cmcli clustermgr.APIQueue) (*blobReplicateMgr, error) {
+	replicateMsgSender, err := NewProducer(cfg.Backend, &cfg.MsgSenderCfg, cmcli)
+	if err != nil {
+		return nil, err
+	}
+
+	return &blobReplicateMgr{
+		topic:              cfg.Topic,
+		replicateMsgSender: replicateMsgSender,
+	}, nil
+}
+
+// SendReplicateMsg sends replicate message to kafka
+func (r *blobReplicateMgr) SendReplicateMsg(ctx context.Context, info *proxy.ReplicateArgs) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	msg := proto.ReplicateMsg{
+		ClusterID: info.ClusterID,
+		Op:        info.Op,
+		Location:  info.Location,
+		Time:      time.Now().Unix(),
+		ReqId:     span.TraceID(),
+	}
+	if !msg.IsValid() {
+		return proto.ErrInvalidMsg
+	}
+
+	msgByte, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: mgs [%+v], err:[%w]", msg, err)
+	}
+
+	// the put and delete of a location are replayed in order
+	key := info.Key
+	if key == "" {
+		key = info.Location
+	}
+	now := time.Now()
+	err = r.replicateMsgSender.SendKeyedMessage(r.topic, []byte(key), msgByte)
+	if err != nil {
+		return fmt.Errorf("send replicate message: topic[%s], info[%+v], err[%w]", r.topic, info, err)
+	}
+
+	span.Debugf("send replicate message success: topic[%s], info[%+v], spend[%+v(100ns)]", r.topic, info, int64(time.Since(now)/100))
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/proxy"
+	"github.com/cubefs/cubefs/blobstore/common/kafka"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+func TestBlobReplicateMgr_sendReplicateMsg(t *testing.T) {
+	mgr := &blobReplicateMgr{
+		topic:              "replicate",
+		replicateMsgSender: newProducer(t),
+	}
+	testCases := []struct {
+		args *proxy.ReplicateArgs
+		err  error
+	}{
+		{
+			args: &proxy.ReplicateArgs{ClusterID: 1, Op: proto.ReplicateOpPut, Location: "0102"},
+			err:  nil,
+		},
+		{
+			args: &proxy.ReplicateArgs{ClusterID: 1, Op: proto.ReplicateOpDelete, Location: "0102"},
+			err:  nil,
+		},
+		{
+			args: &proxy.ReplicateArgs{ClusterID: 1, Op: "get", Location: "0102"},
+			err:  proto.ErrInvalidMsg,
+		},
+		{
+			args: &proxy.ReplicateArgs{ClusterID: 1, Op: proto.ReplicateOpPut},
+			err:  proto.ErrInvalidMsg,
+		},
+	}
+	for _, tc := range testCases {
+		err := mgr.SendReplicateMsg(context.Background(), tc.args)
+		require.True(t, errors.Is(err, tc.err))
+	}
+
+	mgr.topic = "priority"
+	err := mgr.SendReplicateMsg(context.Background(), testCases[0].args)
+	require.ErrorIs(t, err, ErrSendMessage)
+}
+
+func TestNewBlobReplicateMgr(t *testing.T) {
+	_, err := NewBlobReplicateMgr(BlobReplicateConfig{
+		Topic:        "",
+		MsgSenderCfg: kafka.ProducerCfg{},
+	}, nil)
+	require.Error(t, err)
+
+	seedBroker, leader := NewBrokers(t)
+
+	mgr, err := NewBlobReplicateMgr(BlobReplicateConfig{
+		Topic:        "my_topic",
+		MsgSenderCfg: kafka.ProducerCfg{BrokerList: []string{seedBroker.Addr()}},
+	}, nil)
+	require.NoError(t, err)
+
+	info := &proxy.ReplicateArgs{ClusterID: 1, Op: proto.ReplicateOpPut, Location: "0102"}
+	for i := 0; i < 10; i++ {
+		err := mgr.SendReplicateMsg(context.Background(), info)
+		require.NoError(t, err)
+	}
+
+	leader.Close()
+	seedBroker.Close()
+}
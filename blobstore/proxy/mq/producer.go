@@ -0,0 +1,46 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package mq
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/kafka"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+// ErrIllegalBackend illegal message queue backend
+var ErrIllegalBackend = errors.New("illegal mq backend")
+
+// NewProducer returns the message producer of backend, kafka is the default backend
+func NewProducer(backend string, cfg *kafka.ProducerCfg, cmcli clustermgr.APIQueue) (Producer, error) {
+	switch backend {
+	case "", proto.MQBackendKafka:
+		producer, err := kafka.NewProducer(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return producer, nil
+	case proto.MQBackendClusterMgr:
+		if cmcli == nil {
+			return nil, ErrIllegalBackend
+		}
+		return clustermgr.NewQueueProducer(cmcli, time.Duration(cfg.TimeoutMs)*time.Millisecond), nil
+	default:
+		return nil, ErrIllegalBackend
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package mq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/api/proxy"
+	"github.com/cubefs/cubefs/blobstore/common/kafka"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func TestNewProducer(t *testing.T) {
+	_, err := NewProducer("not-exist", &kafka.ProducerCfg{}, nil)
+	require.ErrorIs(t, err, ErrIllegalBackend)
+	_, err = NewProducer(proto.MQBackendClusterMgr, &kafka.ProducerCfg{}, nil)
+	require.ErrorIs(t, err, ErrIllegalBackend)
+	_, err = NewProducer("", &kafka.ProducerCfg{}, nil)
+	require.Error(t, err)
+
+	produced := make(map[string][][]byte)
+	cmcli := mocks.NewMockClientAPI(gomock.NewController(t))
+	cmcli.EXPECT().ProduceMessage(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, args *clustermgr.ProduceMessageArgs) error {
+			if args.Topic == "priority" {
+				return ErrSendMessage
+			}
+			produced[args.Topic] = append(produced[args.Topic], args.Msgs...)
+			return nil
+		})
+
+	deleteMgr, err := NewBlobDeleteMgr(BlobDeleteConfig{
+		Topic:        "delete",
+		Backend:      proto.MQBackendClusterMgr,
+		MsgSenderCfg: kafka.ProducerCfg{TimeoutMs: 1000},
+	}, cmcli)
+	require.NoError(t, err)
+	err = deleteMgr.SendDeleteMsg(context.Background(), &proxy.DeleteArgs{
+		Blobs: []proxy.BlobDelete{{Vid: 1, Bid: 1000}, {Vid: 1, Bid: 1001}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(produced["delete"]))
+
+	repairMgr, err := NewShardRepairMgr(ShardRepairConfig{
+		Topic:         "repair",
+		PriorityTopic: "priority",
+		Backend:       proto.MQBackendClusterMgr,
+	}, cmcli)
+	require.NoError(t, err)
+	err = repairMgr.SendShardRepairMsg(context.Background(), &proxy.ShardRepairArgs{Vid: 1, Bid: 1000, BadIdxes: []uint8{0}})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(produced["repair"]))
+	err = repairMgr.SendShardRepairMsg(context.Background(), &proxy.ShardRepairArgs{Vid: 1, Bid: 1000, BadIdxes: []uint8{0, 1}})
+	require.ErrorIs(t, err, ErrSendMessage)
+}
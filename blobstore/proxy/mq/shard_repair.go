@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/api/proxy"
 	"github.com/cubefs/cubefs/blobstore/common/kafka"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
@@ -35,12 +36,13 @@ type ShardRepairHandler interface {
 type ShardRepairConfig struct {
 	Topic         string            `json:"topic"`
 	PriorityTopic string            `json:"priority_topic"`
+	Backend       string            `json:"backend"`
 	MsgSenderCfg  kafka.ProducerCfg `json:"msg_sender_cfg"`
 }
 
 // NewShardRepairMgr returns shard repair manager
-func NewShardRepairMgr(cfg ShardRepairConfig) (*shardRepairMgr, error) {
-	shardRepairMsgSender, err := kafka.NewProducer(&cfg.MsgSenderCfg)
+func NewShardRepairMgr(cfg ShardRepairConfig, cmcli clustermgr.APIQueue) (*shardRepairMgr, error) {
+	shardRepairMsgSender, err := NewProducer(cfg.Backend, &cfg.MsgSenderCfg, cmcli)
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +69,7 @@ func TestNewShardRepairMgr(t *testing.T) {
 	_, err := NewShardRepairMgr(ShardRepairConfig{
 		Topic:        "",
 		MsgSenderCfg: kafka.ProducerCfg{},
-	})
+	}, nil)
 	require.Error(t, err)
 
 	seedBroker, leader := NewBrokers(t)
@@ -78,7 +78,7 @@ func TestNewShardRepairMgr(t *testing.T) {
 		Topic:         "my_topic",
 		PriorityTopic: "my_topic",
 		MsgSenderCfg:  kafka.ProducerCfg{BrokerList: []string{seedBroker.Addr()}},
-	})
+	}, nil)
 	require.NoError(t, err)
 
 	info := &proxy.ShardRepairArgs{
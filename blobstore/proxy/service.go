@@ -57,6 +57,8 @@ type MQConfig struct {
 	BlobDeleteTopic          string            `json:"blob_delete_topic"`
 	ShardRepairTopic         string            `json:"shard_repair_topic"`
 	ShardRepairPriorityTopic string            `json:"shard_repair_priority_topic"`
+	BlobReplicateTopic       string            `json:"blob_replicate_topic"` // optional, disabled if empty
+	Backend                  string            `json:"backend"`              // kafka or clustermgr, default is kafka
 	MsgSender                kafka.ProducerCfg `json:"msg_sender"`
 	Version                  string            `json:"version"`
 }
@@ -78,6 +80,15 @@ type Config struct {
 func (c *Config) blobDeleteCfg() mq.BlobDeleteConfig {
 	return mq.BlobDeleteConfig{
 		Topic:        c.MQ.BlobDeleteTopic,
+		Backend:      c.MQ.Backend,
+		MsgSenderCfg: c.MQ.MsgSender,
+	}
+}
+
+func (c *Config) blobReplicateCfg() mq.BlobReplicateConfig {
+	return mq.BlobReplicateConfig{
+		Topic:        c.MQ.BlobReplicateTopic,
+		Backend:      c.MQ.Backend,
 		MsgSenderCfg: c.MQ.MsgSender,
 	}
 }
@@ -86,6 +97,7 @@ func (c *Config) shardRepairCfg() mq.ShardRepairConfig {
 	return mq.ShardRepairConfig{
 		Topic:         c.MQ.ShardRepairTopic,
 		PriorityTopic: c.MQ.ShardRepairPriorityTopic,
+		Backend:       c.MQ.Backend,
 		MsgSenderCfg:  c.MQ.MsgSender,
 	}
 }
@@ -96,6 +108,8 @@ type Service struct {
 	// mq
 	shardRepairMgr mq.ShardRepairHandler
 	blobDeleteMgr  mq.BlobDeleteHandler
+	// blobReplicateMgr is nil if blob replicate is disabled
+	blobReplicateMgr mq.BlobReplicateHandler
 	// allocator
 	volumeMgr alloc.VolumeMgr
 	// cacher
@@ -136,14 +150,21 @@ func New(cfg Config, cmcli clustermgr.APIProxy) *Service {
 	}
 
 	// mq
-	blobDeleteMgr, err := mq.NewBlobDeleteMgr(cfg.blobDeleteCfg())
+	blobDeleteMgr, err := mq.NewBlobDeleteMgr(cfg.blobDeleteCfg(), cmcli)
 	if err != nil {
 		log.Fatalf("fail to new blobDeleteMgr, error: %s", err.Error())
 	}
-	shardRepairMgr, err := mq.NewShardRepairMgr(cfg.shardRepairCfg())
+	shardRepairMgr, err := mq.NewShardRepairMgr(cfg.shardRepairCfg(), cmcli)
 	if err != nil {
 		log.Fatalf("fail to new shardRepairMgr, error: %s", err.Error())
 	}
+	var blobReplicateMgr mq.BlobReplicateHandler
+	if cfg.MQ.BlobReplicateTopic != "" {
+		blobReplicateMgr, err = mq.NewBlobReplicateMgr(cfg.blobReplicateCfg(), cmcli)
+		if err != nil {
+			log.Fatalf("fail to new blobReplicateMgr, error: %s", err.Error())
+		}
+	}
 
 	// allocator
 	volumeMgr, err := alloc.NewVolumeMgr(context.Background(), cfg.BlobConfig, cfg.VolConfig, cmcli)
@@ -174,6 +195,8 @@ func New(cfg Config, cmcli clustermgr.APIProxy) *Service {
 		cacher:         cacher,
 		shardRepairMgr: shardRepairMgr,
 		blobDeleteMgr:  blobDeleteMgr,
+
+		blobReplicateMgr: blobReplicateMgr,
 	}
 }
 
@@ -204,6 +227,10 @@ func NewHandler(service *Service) *rpc.Router {
 	// request body: json
 	router.Handle(http.MethodPost, "/deletemsg", service.SendDeleteMessage, rpc.OptArgsBody())
 
+	// POST /replicatemsg
+	// request body: json
+	router.Handle(http.MethodPost, "/replicatemsg", service.SendReplicateMessage, rpc.OptArgsBody())
+
 	// GET /cache/volume/{vid}?flush={flush}&version={version}
 	// response body: json
 	router.Handle(http.MethodGet, "/cache/volume/:vid", service.GetCacheVolume, rpc.OptArgsURI(), rpc.OptArgsQuery())
@@ -224,12 +251,20 @@ func (c *Config) checkAndFix() (err error) {
 	if c.MQ.BlobDeleteTopic == c.MQ.ShardRepairTopic || c.MQ.BlobDeleteTopic == c.MQ.ShardRepairPriorityTopic {
 		return ErrIllegalTopic
 	}
+	if topic := c.MQ.BlobReplicateTopic; topic != "" &&
+		(topic == c.MQ.BlobDeleteTopic || topic == c.MQ.ShardRepairTopic || topic == c.MQ.ShardRepairPriorityTopic) {
+		return ErrIllegalTopic
+	}
 	defaulter.Equal(&c.HeartbeatIntervalS, defaultHeartbeatIntervalS)
 	defaulter.Equal(&c.HeartbeatTicks, defaultHeartbeatTicks)
 	defaulter.Equal(&c.ExpiresTicks, defaultExpiresTicks)
 	defaulter.LessOrEqual(&c.Clustermgr.Config.ClientTimeoutMs, defaultTimeoutMS)
 	defaulter.LessOrEqual(&c.MQ.MsgSender.TimeoutMs, defaultTimeoutMS)
-	if c.MQ.Version != "" {
+	defaulter.Empty(&c.MQ.Backend, proto.MQBackendKafka)
+	if c.MQ.Backend != proto.MQBackendKafka && c.MQ.Backend != proto.MQBackendClusterMgr {
+		return mq.ErrIllegalBackend
+	}
+	if c.MQ.Backend == proto.MQBackendKafka && c.MQ.Version != "" {
 		kafkaVersion, err := sarama.ParseKafkaVersion(c.MQ.Version)
 		if err != nil {
 			return ErrIllegalKafka
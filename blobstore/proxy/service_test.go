@@ -34,6 +34,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/proxy/allocator"
 	"github.com/cubefs/cubefs/blobstore/proxy/mock"
+	"github.com/cubefs/cubefs/blobstore/proxy/mq"
 	_ "github.com/cubefs/cubefs/blobstore/testing/nolog"
 	"github.com/cubefs/cubefs/blobstore/util/errors"
 )
@@ -78,6 +79,15 @@ func newMockService(t *testing.T) *Service {
 			return nil
 		})
 
+	blobReplicateMgr := mock.NewMockBlobReplicateHandler(ctr)
+	blobReplicateMgr.EXPECT().SendReplicateMsg(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, info *proxy.ReplicateArgs) error {
+			if info.Op == proto.ReplicateOpDelete {
+				return errors.New("fake send replicate message failed")
+			}
+			return nil
+		})
+
 	volumeMgr := mock.NewMockVolumeMgr(ctr)
 	volumeMgr.EXPECT().Alloc(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
 		func(ctx context.Context, args *proxy.AllocVolsArgs) (allocVols []proxy.AllocRet, err error) {
@@ -147,6 +157,8 @@ func newMockService(t *testing.T) *Service {
 		blobDeleteMgr:  blobDeleteMgr,
 		volumeMgr:      volumeMgr,
 		cacher:         cacher,
+
+		blobReplicateMgr: blobReplicateMgr,
 	}
 }
 
@@ -258,6 +270,36 @@ func TestService_MQ(t *testing.T) {
 		err := cli.PostWith(ctx, proxyServer.URL+"/repairmsg", nil, tc.args)
 		require.Equal(t, tc.code, rpc.DetectStatusCode(err))
 	}
+
+	replicateCases := []struct {
+		args proxy.ReplicateArgs
+		code int
+	}{
+		{
+			args: proxy.ReplicateArgs{ClusterID: 1, Op: proto.ReplicateOpPut, Location: "0102"},
+			code: 200,
+		},
+		{
+			args: proxy.ReplicateArgs{ClusterID: 2, Op: proto.ReplicateOpPut, Location: "0102"},
+			code: 803,
+		},
+		{
+			args: proxy.ReplicateArgs{ClusterID: 1, Op: proto.ReplicateOpDelete, Location: "0102"},
+			code: 500,
+		},
+	}
+	for _, tc := range replicateCases {
+		err := cli.PostWith(ctx, proxyServer.URL+"/replicatemsg", nil, tc.args)
+		require.Equal(t, tc.code, rpc.DetectStatusCode(err))
+	}
+
+	// blob replicate is disabled
+	svc := newMockService(t)
+	svc.blobReplicateMgr = nil
+	server := httptest.NewServer(NewHandler(svc))
+	defer server.Close()
+	err := cli.PostWith(ctx, server.URL+"/replicatemsg", nil, replicateCases[0].args)
+	require.Equal(t, errcode.CodeReplicateDisabled, rpc.DetectStatusCode(err))
 }
 
 func TestService_Allocator(t *testing.T) {
@@ -418,6 +460,8 @@ func TestConfigFix(t *testing.T) {
 		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test", ShardRepairPriorityTopic: "test3"}}, err: ErrIllegalTopic},
 		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test"}}, err: ErrIllegalTopic},
 		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test3"}}, err: nil},
+		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test3", Backend: "x"}}, err: mq.ErrIllegalBackend},
+		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test3", Backend: "clustermgr"}}, err: nil},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,171 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package base
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/kafka"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
+)
+
+// interval of polling the queue when there is no message or an error occurred
+var queuePollInterval = time.Second
+
+// MsgQueue define the interface of message queue backend
+type MsgQueue interface {
+	KafkaConsumer
+	NewMsgSender(cfg *kafka.ProducerCfg) (IProducer, error)
+}
+
+type clusterMgrQueue struct {
+	cli clustermgr.APIQueue
+}
+
+// NewClusterMgrQueue returns the message queue embedded in clustermgr
+func NewClusterMgrQueue(cli clustermgr.APIQueue) MsgQueue {
+	return &clusterMgrQueue{cli: cli}
+}
+
+// NewMsgSender returns message sender of the embedded queue
+func (q *clusterMgrQueue) NewMsgSender(cfg *kafka.ProducerCfg) (IProducer, error) {
+	return &msgSender{
+		topic:    cfg.Topic,
+		producer: clustermgr.NewQueueProducer(q.cli, time.Duration(cfg.TimeoutMs)*time.Millisecond),
+	}, nil
+}
+
+// StartKafkaConsumer registers the group and starts consuming the topic of the embedded queue, the offset
+// is committed after messages consumed successfully and messages will be consumed again if failed.
+func (q *clusterMgrQueue) StartKafkaConsumer(cfg KafkaConsumerCfg, fn func(msg []*sarama.ConsumerMessage,
+	consumerPause ConsumerPause) bool) (GroupConsumer, error) {
+	group := fmt.Sprintf("%s-%s", proto.ServiceNameScheduler, cfg.Topic)
+	span, ctx := trace.StartSpanFromContext(context.Background(), group)
+	if err := q.cli.RegisterQueueGroup(ctx, &clustermgr.RegisterQueueGroupArgs{Topic: cfg.Topic, Group: group}); err != nil {
+		span.Errorf("register queue group failed: group[%s], err[%+v]", group, err)
+		return nil, err
+	}
+
+	consumer := &queueConsumer{
+		cli:          q.cli,
+		topic:        cfg.Topic,
+		group:        group,
+		maxBatchSize: cfg.MaxBatchSize,
+		maxWait:      time.Second * time.Duration(cfg.MaxWaitTimeS),
+		consumeFn:    fn,
+		span:         span,
+		Closer:       closer.New(),
+	}
+	go consumer.run(ctx)
+
+	span.Infof("start queue consumer: group[%s]", group)
+	return consumer, nil
+}
+
+type queueConsumer struct {
+	cli          clustermgr.APIQueue
+	topic        string
+	group        string
+	maxBatchSize int
+	maxWait      time.Duration
+	consumeFn    func(msg []*sarama.ConsumerMessage, consumerPause ConsumerPause) bool
+	span         trace.Span
+
+	closer.Closer
+}
+
+func (c *queueConsumer) Stop() {
+	c.Close()
+	c.span.Infof("stop queue consumer: group[%s]", c.group)
+}
+
+func (c *queueConsumer) run(ctx context.Context) {
+	var (
+		msgs       = make([]*sarama.ConsumerMessage, 0, c.maxBatchSize)
+		nextOffset uint64 // zero means consume from the committed offset
+		batchStart time.Time
+	)
+	for {
+		select {
+		case <-c.Done():
+			return
+		default:
+		}
+
+		ret, err := c.cli.ConsumeMessage(ctx, &clustermgr.ConsumeMessageArgs{
+			Topic:  c.topic,
+			Group:  c.group,
+			Offset: nextOffset,
+			Count:  c.maxBatchSize - len(msgs),
+		})
+		if err != nil {
+			c.span.Errorf("consume message failed and try again: topic[%s], err[%+v]", c.topic, err)
+			c.wait()
+			continue
+		}
+		for _, m := range ret.Msgs {
+			c.span.Debugf("Message claimed: value[%s], topic[%s], offset[%d]", string(m.Value), c.topic, m.Offset)
+			if len(msgs) == 0 {
+				batchStart = time.Now()
+			}
+			msgs = append(msgs, &sarama.ConsumerMessage{
+				Topic:     c.topic,
+				Offset:    int64(m.Offset),
+				Value:     m.Value,
+				Timestamp: time.Unix(m.Time, 0),
+			})
+			nextOffset = m.Offset + 1
+		}
+		if len(msgs) == 0 || (len(msgs) < c.maxBatchSize && time.Since(batchStart) < c.maxWait) {
+			c.wait()
+			continue
+		}
+
+		// the batch is full, or the time come
+		lastMsg := msgs[len(msgs)-1]
+		success := c.consumeFn(msgs, c)
+		msgs = msgs[:0]
+		if !success {
+			c.span.Warnf("message not consume and try again: topic[%s], offset[%d]", lastMsg.Topic, lastMsg.Offset)
+			nextOffset = 0
+			c.wait()
+			continue
+		}
+		err = c.cli.CommitQueueOffset(ctx, &clustermgr.CommitQueueOffsetArgs{
+			Topic:  c.topic,
+			Group:  c.group,
+			Offset: uint64(lastMsg.Offset),
+		})
+		if err != nil {
+			c.span.Errorf("commit offset failed: topic[%s], offset[%d], err[%+v]", c.topic, lastMsg.Offset, err)
+		}
+	}
+}
+
+func (c *queueConsumer) wait() {
+	t := time.NewTimer(queuePollInterval)
+	defer t.Stop()
+	select {
+	case <-c.Done():
+	case <-t.C:
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package base
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/kafka"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+// memQueue is a single topic queue in memory
+type memQueue struct {
+	sync.Mutex
+	msgs      []*clustermgr.QueueMessage
+	committed uint64
+}
+
+func (q *memQueue) produce(_ context.Context, args *clustermgr.ProduceMessageArgs) error {
+	q.Lock()
+	defer q.Unlock()
+	for _, m := range args.Msgs {
+		q.msgs = append(q.msgs, &clustermgr.QueueMessage{Offset: uint64(len(q.msgs) + 1), Time: time.Now().Unix(), Value: m})
+	}
+	return nil
+}
+
+func (q *memQueue) consume(_ context.Context, args *clustermgr.ConsumeMessageArgs) (clustermgr.ConsumeMessageRet, error) {
+	q.Lock()
+	defer q.Unlock()
+	offset := args.Offset
+	if offset == 0 {
+		offset = q.committed + 1
+	}
+	ret := clustermgr.ConsumeMessageRet{}
+	for i := offset; i <= uint64(len(q.msgs)) && len(ret.Msgs) < args.Count; i++ {
+		ret.Msgs = append(ret.Msgs, q.msgs[i-1])
+	}
+	return ret, nil
+}
+
+func (q *memQueue) commit(_ context.Context, args *clustermgr.CommitQueueOffsetArgs) error {
+	q.Lock()
+	defer q.Unlock()
+	q.committed = args.Offset
+	return nil
+}
+
+func (q *memQueue) getCommitted() uint64 {
+	q.Lock()
+	defer q.Unlock()
+	return q.committed
+}
+
+func TestClusterMgrQueue(t *testing.T) {
+	queuePollInterval = 10 * time.Millisecond
+
+	mq := &memQueue{}
+	cli := mocks.NewMockClientAPI(gomock.NewController(t))
+	cli.EXPECT().ProduceMessage(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(mq.produce)
+	cli.EXPECT().ConsumeMessage(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(mq.consume)
+	cli.EXPECT().RegisterQueueGroup(gomock.Any(), gomock.Any()).Return(nil)
+	cli.EXPECT().CommitQueueOffset(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(mq.commit)
+
+	queue := NewClusterMgrQueue(cli)
+	sender, err := queue.NewMsgSender(&kafka.ProducerCfg{Topic: "test", TimeoutMs: 1000})
+	require.NoError(t, err)
+	require.NoError(t, sender.SendMessage([]byte("msg1")))
+	require.NoError(t, sender.SendMessages([][]byte{[]byte("msg2"), []byte("msg3")}))
+
+	var (
+		mu       sync.Mutex
+		consumed []string
+		failOnce = true
+	)
+	consumer, err := queue.StartKafkaConsumer(KafkaConsumerCfg{
+		TaskType:     proto.TaskTypeBlobDelete,
+		Topic:        "test",
+		MaxBatchSize: 2,
+		MaxWaitTimeS: 1,
+	}, func(msgs []*sarama.ConsumerMessage, _ ConsumerPause) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if failOnce {
+			failOnce = false
+			return false
+		}
+		for _, msg := range msgs {
+			consumed = append(consumed, string(msg.Value))
+		}
+		return true
+	})
+	require.NoError(t, err)
+	defer consumer.Stop()
+
+	require.Eventually(t, func() bool { return mq.getCommitted() == 3 }, 5*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	require.Equal(t, []string{"msg1", "msg2", "msg3"}, consumed)
+	mu.Unlock()
+
+	failedCli := mocks.NewMockClientAPI(gomock.NewController(t))
+	failedCli.EXPECT().ProduceMessage(gomock.Any(), gomock.Any()).Return(errors.New("produce failed"))
+	sender, err = NewClusterMgrQueue(failedCli).NewMsgSender(&kafka.ProducerCfg{Topic: "test"})
+	require.NoError(t, err)
+	require.Error(t, sender.SendMessage([]byte("msg4")))
+
+	failedCli.EXPECT().RegisterQueueGroup(gomock.Any(), gomock.Any()).Return(errors.New("register failed"))
+	_, err = NewClusterMgrQueue(failedCli).StartKafkaConsumer(KafkaConsumerCfg{Topic: "test"}, nil)
+	require.Error(t, err)
+}
@@ -170,7 +170,8 @@ func (cg *KafkaConsumerGroup) Stop() {
 	cg.span.Infof("stop kafka consumer: group[%s]", cg.group)
 }
 
-func NewKafkaConsumer(brokers []string) KafkaConsumer {
+// NewKafkaConsumer returns the message queue of kafka
+func NewKafkaConsumer(brokers []string) MsgQueue {
 	return &kafkaClient{
 		brokers: brokers,
 	}
@@ -45,3 +45,8 @@ func (sender *msgSender) SendMessage(msg []byte) error {
 func (sender *msgSender) SendMessages(msgs [][]byte) error {
 	return sender.producer.SendMessages(sender.topic, msgs)
 }
+
+// NewMsgSender returns message sender of kafka
+func (cli *kafkaClient) NewMsgSender(cfg *kafka.ProducerCfg) (IProducer, error) {
+	return NewMsgSender(cfg)
+}
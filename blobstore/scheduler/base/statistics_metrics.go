@@ -315,6 +315,70 @@ func NewCounter(clusterID proto.ClusterID, taskType string, kind string) prometh
 	return shardCntCounter
 }
 
+// NewExpiredBytesCounter returns counter of bytes of expired blobs handed over to delete
+func NewExpiredBytesCounter(clusterID proto.ClusterID) prometheus.Counter {
+	labels := map[string]string{
+		"cluster_id": fmt.Sprintf("%d", clusterID),
+	}
+	expiredBytesCounter := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Subsystem:   "blob_expire",
+		Name:        "deleted_bytes",
+		Help:        "bytes of expired blobs to be deleted",
+		ConstLabels: labels,
+	})
+	if err := prometheus.Register(expiredBytesCounter); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(prometheus.Counter)
+		}
+		panic(err)
+	}
+	return expiredBytesCounter
+}
+
+// NewReplicateLagGauge returns gauge of seconds the replicated change log lags behind
+func NewReplicateLagGauge(clusterID proto.ClusterID) prometheus.Gauge {
+	labels := map[string]string{
+		"cluster_id": fmt.Sprintf("%d", clusterID),
+	}
+	replicateLagGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Subsystem:   "blob_replicate",
+		Name:        "lag_seconds",
+		Help:        "seconds of the latest replicated change log lags behind",
+		ConstLabels: labels,
+	})
+	if err := prometheus.Register(replicateLagGauge); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(prometheus.Gauge)
+		}
+		panic(err)
+	}
+	return replicateLagGauge
+}
+
+// NewShardRepairPlanCounter returns shard repair counter partitioned by
+// the stripe planned to reconstruct bad shards, local or global
+func NewShardRepairPlanCounter(clusterID proto.ClusterID) *prometheus.CounterVec {
+	labels := map[string]string{
+		"cluster_id": fmt.Sprintf("%d", clusterID),
+	}
+	planCounter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Subsystem:   "shard_repair",
+		Name:        "plan",
+		Help:        "shard repair tasks by planned stripe",
+		ConstLabels: labels,
+	}, []string{"plan"})
+	if err := prometheus.Register(planCounter); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(*prometheus.CounterVec)
+		}
+		panic(err)
+	}
+	return planCounter
+}
+
 // ErrorStats error stats
 type ErrorStats struct {
 	lock        sync.Mutex
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/cubefs/cubefs/blobstore/scheduler/base (interfaces: KafkaConsumer,GroupConsumer,IProducer)
+// Source: github.com/cubefs/cubefs/blobstore/scheduler/base (interfaces: KafkaConsumer,MsgQueue,GroupConsumer,IProducer)
 
 // Package scheduler is a generated GoMock package.
 package scheduler
@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	sarama "github.com/Shopify/sarama"
+	kafka "github.com/cubefs/cubefs/blobstore/common/kafka"
 	base "github.com/cubefs/cubefs/blobstore/scheduler/base"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -50,6 +51,59 @@ func (mr *MockKafkaConsumerMockRecorder) StartKafkaConsumer(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartKafkaConsumer", reflect.TypeOf((*MockKafkaConsumer)(nil).StartKafkaConsumer), arg0, arg1)
 }
 
+// MockMsgQueue is a mock of MsgQueue interface.
+type MockMsgQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockMsgQueueMockRecorder
+}
+
+// MockMsgQueueMockRecorder is the mock recorder for MockMsgQueue.
+type MockMsgQueueMockRecorder struct {
+	mock *MockMsgQueue
+}
+
+// NewMockMsgQueue creates a new mock instance.
+func NewMockMsgQueue(ctrl *gomock.Controller) *MockMsgQueue {
+	mock := &MockMsgQueue{ctrl: ctrl}
+	mock.recorder = &MockMsgQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMsgQueue) EXPECT() *MockMsgQueueMockRecorder {
+	return m.recorder
+}
+
+// NewMsgSender mocks base method.
+func (m *MockMsgQueue) NewMsgSender(arg0 *kafka.ProducerCfg) (base.IProducer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewMsgSender", arg0)
+	ret0, _ := ret[0].(base.IProducer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewMsgSender indicates an expected call of NewMsgSender.
+func (mr *MockMsgQueueMockRecorder) NewMsgSender(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewMsgSender", reflect.TypeOf((*MockMsgQueue)(nil).NewMsgSender), arg0)
+}
+
+// StartKafkaConsumer mocks base method.
+func (m *MockMsgQueue) StartKafkaConsumer(arg0 base.KafkaConsumerCfg, arg1 func([]*sarama.ConsumerMessage, base.ConsumerPause) bool) (base.GroupConsumer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartKafkaConsumer", arg0, arg1)
+	ret0, _ := ret[0].(base.GroupConsumer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartKafkaConsumer indicates an expected call of StartKafkaConsumer.
+func (mr *MockMsgQueueMockRecorder) StartKafkaConsumer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartKafkaConsumer", reflect.TypeOf((*MockMsgQueue)(nil).StartKafkaConsumer), arg0, arg1)
+}
+
 // MockGroupConsumer is a mock of GroupConsumer interface.
 type MockGroupConsumer struct {
 	ctrl     *gomock.Controller
@@ -180,12 +180,24 @@ type BlobDeleteConfig struct {
 	SafeDelayTimeH  int64            `json:"safe_delay_time_h"`
 	DeleteHourRange HourRange        `json:"delete_hour_range"`
 	DeleteLog       recordlog.Config `json:"delete_log"`
+
+	// scan expired blobs every ExpireIntervalS seconds
+	ExpireIntervalS int `json:"expire_interval_s"`
+	ExpireBatchSize int `json:"expire_batch_size"`
 }
 
 func (cfg *BlobDeleteConfig) topics() []string {
 	return []string{cfg.Kafka.TopicNormal, cfg.Kafka.TopicFailed}
 }
 
+func (cfg *BlobDeleteConfig) normalProducerConfig() *kafka.ProducerCfg {
+	return &kafka.ProducerCfg{
+		BrokerList: cfg.Kafka.BrokerList,
+		Topic:      cfg.Kafka.TopicNormal,
+		TimeoutMs:  cfg.Kafka.FailMsgSenderTimeoutMs,
+	}
+}
+
 func (cfg *BlobDeleteConfig) failedProducerConfig() *kafka.ProducerCfg {
 	return &kafka.ProducerCfg{
 		BrokerList: cfg.Kafka.BrokerList,
@@ -201,6 +213,7 @@ type BlobDeleteMgr struct {
 	taskPool        *taskpool.TaskPool
 	clusterTopology IClusterTopology
 	blobnodeCli     client.BlobnodeAPI
+	clusterMgrCli   client.ClusterMgrAPI
 
 	delSuccessCounter      prometheus.Counter
 	delSuccessCounterByMin *counter.Counter
@@ -208,7 +221,7 @@ type BlobDeleteMgr struct {
 	delFailCounterByMin    *counter.Counter
 	errStatsDistribution   *base.ErrorStats
 
-	kafkaConsumerClient base.KafkaConsumer
+	kafkaConsumerClient base.MsgQueue
 	consumers           []base.GroupConsumer
 	safeDelayTime       time.Duration
 	punishTime          time.Duration
@@ -227,9 +240,10 @@ func NewBlobDeleteMgr(
 	clusterTopology IClusterTopology,
 	switchMgr *taskswitch.SwitchMgr,
 	blobnodeCli client.BlobnodeAPI,
-	kafkaClient base.KafkaConsumer,
+	clusterMgrCli client.ClusterMgrAPI,
+	kafkaClient base.MsgQueue,
 ) (*BlobDeleteMgr, error) {
-	failMsgSender, err := base.NewMsgSender(cfg.failedProducerConfig())
+	failMsgSender, err := kafkaClient.NewMsgSender(cfg.failedProducerConfig())
 	if err != nil {
 		return nil, err
 	}
@@ -251,6 +265,7 @@ func NewBlobDeleteMgr(
 		taskPool:               &tp,
 		clusterTopology:        clusterTopology,
 		blobnodeCli:            blobnodeCli,
+		clusterMgrCli:          clusterMgrCli,
 		delSuccessCounter:      base.NewCounter(cfg.ClusterID, "delete", base.KindSuccess),
 		delFailCounter:         base.NewCounter(cfg.ClusterID, "delete", base.KindFailed),
 		errStatsDistribution:   base.NewErrorStats(),
@@ -506,6 +521,18 @@ func (mgr *BlobDeleteMgr) consume(item *delBlobRet, consumerPause base.ConsumerP
 		return
 	}
 
+	referenced, err := mgr.releaseBlobDedup(item.ctx, item.delMsg)
+	if err != nil {
+		item.status = DeleteStatusFailed
+		item.err = err
+		return
+	}
+	if referenced {
+		span.Infof("blob is referenced by others and keep it: vid[%d], bid[%d]", item.delMsg.Vid, item.delMsg.Bid)
+		item.status = DeleteStatusDone
+		return
+	}
+
 	span.Debugf("start delete msg[%+v]", item.delMsg)
 	if err := mgr.deleteWithCheckVolConsistency(item.ctx, item.delMsg); err != nil {
 		item.status = DeleteStatusFailed
@@ -521,8 +548,33 @@ func (mgr *BlobDeleteMgr) consume(item *delBlobRet, consumerPause base.ConsumerP
 	item.status = DeleteStatusDone
 }
 
+// releaseBlobDedup releases the reference of blob in the dedup index,
+// returns true if the blob is still referenced by other locations.
+// the blob is not registered if dedup is disabled, and zero reference is returned.
+// the release is keyed by the delete message, redelivered message takes no effect.
+func (mgr *BlobDeleteMgr) releaseBlobDedup(ctx context.Context, msg *proto.DeleteMsg) (bool, error) {
+	if msg.DedupReleased {
+		return false, nil
+	}
+	refs, err := mgr.clusterMgrCli.ReleaseBlobDedup(ctx, msg.Vid, msg.Bid, msg.DedupRefID())
+	if err != nil {
+		return false, err
+	}
+	msg.DedupReleased = true
+	return refs > 0, nil
+}
+
 func (mgr *BlobDeleteMgr) deleteWithCheckVolConsistency(ctx context.Context, msg *proto.DeleteMsg) error {
-	return DoubleCheckedRun(ctx, mgr.clusterTopology, msg.Vid, func(info *client.VolumeInfoSimple) (*client.VolumeInfoSimple, error) {
+	vid := msg.Vid
+	// the blobs of a transcoded volume are deleted in the destination volume,
+	// and the source volume keeps readonly while copying, so delete it later
+	if mapping, ok := mgr.clusterTopology.GetTranscodeMapping(msg.Vid); ok {
+		if !mapping.Done() {
+			return errVolumeTranscoding
+		}
+		vid = mapping.DstVid
+	}
+	return DoubleCheckedRun(ctx, mgr.clusterTopology, vid, func(info *client.VolumeInfoSimple) (*client.VolumeInfoSimple, error) {
 		return mgr.deleteBlob(ctx, info, msg)
 	})
 }
@@ -44,8 +44,11 @@ func newBlobDeleteMgr(t *testing.T) *BlobDeleteMgr {
 	ctr := gomock.NewController(t)
 	clusterMgrCli := NewMockClusterMgrAPI(ctr)
 	clusterMgrCli.EXPECT().GetConfig(any, any).AnyTimes().Return("", nil)
+	clusterMgrCli.EXPECT().ReleaseBlobDedup(any, any, any, any).AnyTimes().Return(uint32(0), nil)
 
 	clusterTopology := NewMockClusterTopology(ctr)
+
+	clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 	clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 		func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 			return &client.VolumeInfoSimple{Vid: vid}, nil
@@ -76,6 +79,7 @@ func newBlobDeleteMgr(t *testing.T) *BlobDeleteMgr {
 		clusterTopology: clusterTopology,
 		punishTime:      time.Duration(defaultMessagePunishTimeM) * time.Minute,
 		blobnodeCli:     blobnodeCli,
+		clusterMgrCli:   clusterMgrCli,
 		failMsgSender:   producer,
 
 		delSuccessCounter:    base.NewCounter(1, "delete", base.KindSuccess),
@@ -120,6 +124,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// consume success
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 				return &client.VolumeInfoSimple{
@@ -144,6 +149,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// consume failed
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 				return &client.VolumeInfoSimple{Vid: vid, VunitLocations: []proto.VunitLocation{{Vuid: 1}}}, nil
@@ -172,6 +178,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// consume cancel
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 				return &client.VolumeInfoSimple{
@@ -208,6 +215,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// consume success
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 				return &client.VolumeInfoSimple{
@@ -233,6 +241,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// has mark deleted and not send request to blobnode
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
@@ -262,6 +271,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// has deleted and not send request to blobnode
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
@@ -291,6 +301,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// delete protected
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 				return &client.VolumeInfoSimple{
@@ -318,6 +329,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// delete protected and cancel
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 				return &client.VolumeInfoSimple{
@@ -350,6 +362,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// blobnode delete failed
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 				return &client.VolumeInfoSimple{Vid: vid, VunitLocations: []proto.VunitLocation{{Vuid: 1}}}, nil
@@ -375,6 +388,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// blobnode return ErrDiskBroken
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 				return &client.VolumeInfoSimple{Vid: vid, VunitLocations: []proto.VunitLocation{{Vuid: 1}}}, nil
@@ -409,6 +423,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// blobnode return ErrDiskBroken, and clusterTopology update not eql
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 				return &client.VolumeInfoSimple{
@@ -462,6 +477,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// has broken disk and not send requests to blobnode
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 				return &client.VolumeInfoSimple{
@@ -485,6 +501,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		// message punished and consume success
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 				return &client.VolumeInfoSimple{
@@ -525,6 +542,7 @@ func TestBlobDeleteConsume(t *testing.T) {
 		start := time.Now()
 		oldClusterTopology := mgr.clusterTopology
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(
 			func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
 				return &client.VolumeInfoSimple{
@@ -560,6 +578,51 @@ func TestBlobDeleteConsume(t *testing.T) {
 	}
 }
 
+func TestBlobDeleteDedup(t *testing.T) {
+	ctr := gomock.NewController(t)
+	ctx := context.Background()
+	mgr := newBlobDeleteMgr(t)
+	commonCloser := closer.New()
+	defer commonCloser.Close()
+
+	msg := &proto.DeleteMsg{Bid: 1, Vid: 1, ReqId: "dedup", Seq: 2}
+	require.Equal(t, "dedup-2", msg.DedupRefID())
+	require.Equal(t, "", (&proto.DeleteMsg{Bid: 1, Vid: 1}).DedupRefID())
+
+	clusterMgrCli := NewMockClusterMgrAPI(ctr)
+	mgr.clusterMgrCli = clusterMgrCli
+
+	// referenced by others
+	clusterMgrCli.EXPECT().ReleaseBlobDedup(any, proto.Vid(1), proto.BlobID(1), "dedup-2").Return(uint32(1), nil)
+	oldBlobNode := mgr.blobnodeCli
+	mgr.blobnodeCli = NewMockBlobnodeAPI(ctr)
+	msgByte, _ := json.Marshal(msg)
+	success := mgr.Consume([]*sarama.ConsumerMessage{{Value: msgByte}}, commonCloser)
+	require.True(t, success)
+	mgr.blobnodeCli = oldBlobNode
+
+	// release failed, the message is failed and retried
+	clusterMgrCli.EXPECT().ReleaseBlobDedup(any, any, any, any).Return(uint32(0), errMock)
+	_, err := mgr.releaseBlobDedup(ctx, msg)
+	require.ErrorIs(t, err, errMock)
+	require.False(t, msg.DedupReleased)
+
+	clusterMgrCli.EXPECT().ReleaseBlobDedup(any, any, any, any).Return(uint32(0), errMock)
+	msgByte, _ = json.Marshal(msg)
+	success = mgr.Consume([]*sarama.ConsumerMessage{{Value: msgByte}}, commonCloser)
+	require.True(t, success)
+
+	clusterMgrCli.EXPECT().ReleaseBlobDedup(any, any, any, any).Return(uint32(0), nil)
+	referenced, err := mgr.releaseBlobDedup(ctx, msg)
+	require.NoError(t, err)
+	require.False(t, referenced)
+	require.True(t, msg.DedupReleased)
+	// released already
+	referenced, err = mgr.releaseBlobDedup(ctx, msg)
+	require.NoError(t, err)
+	require.False(t, referenced)
+}
+
 // comment temporary
 func TestNewDeleteMgr(t *testing.T) {
 	ctr := gomock.NewController(t)
@@ -595,15 +658,18 @@ func TestNewDeleteMgr(t *testing.T) {
 	clusterMgrCli.EXPECT().SetConsumeOffset(any, any, any, any).AnyTimes().Return(nil)
 
 	clusterTopology := NewMockClusterTopology(ctr)
+
+	clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 	blobnodeCli := NewMockBlobnodeAPI(ctr)
 	switchMgr := taskswitch.NewSwitchMgr(clusterMgrCli)
 
-	kafkaClient := NewMockKafkaConsumer(ctr)
+	kafkaClient := NewMockMsgQueue(ctr)
 	consumer := NewMockGroupConsumer(ctr)
 	consumer.EXPECT().Stop().AnyTimes().Return()
 	kafkaClient.EXPECT().StartKafkaConsumer(any, any).AnyTimes().Return(consumer, nil)
+	kafkaClient.EXPECT().NewMsgSender(any).Return(NewMockProducer(ctr), nil)
 
-	mgr, err := NewBlobDeleteMgr(blobCfg, clusterTopology, switchMgr, blobnodeCli, kafkaClient)
+	mgr, err := NewBlobDeleteMgr(blobCfg, clusterTopology, switchMgr, blobnodeCli, clusterMgrCli, kafkaClient)
 	require.NoError(t, err)
 	require.False(t, mgr.Enabled())
 	// run task
@@ -695,6 +761,8 @@ func TestDeleteBlob(t *testing.T) {
 		mgr.blobnodeCli = blobnodeCli
 
 		clusterTopology := NewMockClusterTopology(ctr)
+
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().UpdateVolume(any).Return(volume, ErrFrequentlyUpdate)
 		mgr.clusterTopology = clusterTopology
 
@@ -711,6 +779,8 @@ func TestDeleteBlob(t *testing.T) {
 		mgr.blobnodeCli = blobnodeCli
 
 		clusterTopology := NewMockClusterTopology(ctr)
+
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().UpdateVolume(any).Return(volume, nil)
 		mgr.clusterTopology = clusterTopology
 
@@ -729,6 +799,8 @@ func TestDeleteBlob(t *testing.T) {
 		mgr.blobnodeCli = blobnodeCli
 
 		clusterTopology := NewMockClusterTopology(ctr)
+
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		newVolume := MockGenVolInfo(proto.Vid(1), codemode.EC3P3, proto.VolumeStatusActive)
 		newVolume.VunitLocations[5].Vuid += 1
 		clusterTopology.EXPECT().UpdateVolume(any).Return(newVolume, nil)
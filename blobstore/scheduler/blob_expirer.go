@@ -0,0 +1,147 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/scheduler/base"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
+)
+
+// IBlobExpirer define the interface of blob expire manager
+type IBlobExpirer interface {
+	Run()
+	Close()
+}
+
+// BlobExpireMgr scans the expiry of blobs in clustermgr and hands the expired blobs over to
+// blob delete by sending delete messages, the delete time of message is the expiry time so the
+// blobs are deleted after safe delay time from the expiry.
+type BlobExpireMgr struct {
+	closer.Closer
+	clusterID     proto.ClusterID
+	clusterMgrCli client.ClusterMgrBlobExpireAPI
+	msgSender     base.IProducer
+	interval      time.Duration
+	batchSize     int
+
+	expiredBytesCounter prometheus.Counter
+}
+
+// NewBlobExpireMgr returns blob expire manager
+func NewBlobExpireMgr(cfg *BlobDeleteConfig, clusterMgrCli client.ClusterMgrBlobExpireAPI,
+	kafkaClient base.MsgQueue) (*BlobExpireMgr, error) {
+	msgSender, err := kafkaClient.NewMsgSender(cfg.normalProducerConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &BlobExpireMgr{
+		Closer:              closer.New(),
+		clusterID:           cfg.ClusterID,
+		clusterMgrCli:       clusterMgrCli,
+		msgSender:           msgSender,
+		interval:            time.Duration(cfg.ExpireIntervalS) * time.Second,
+		batchSize:           cfg.ExpireBatchSize,
+		expiredBytesCounter: base.NewExpiredBytesCounter(cfg.ClusterID),
+	}, nil
+}
+
+// Run runs expire task
+func (mgr *BlobExpireMgr) Run() {
+	go mgr.runTask()
+}
+
+func (mgr *BlobExpireMgr) runTask() {
+	t := time.NewTicker(mgr.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			span, ctx := trace.StartSpanFromContext(context.Background(), "blob_expire")
+			if err := mgr.expire(ctx, time.Now().Unix()); err != nil {
+				span.Errorf("expire blobs failed: err[%+v]", err)
+			}
+		case <-mgr.Done():
+			return
+		}
+	}
+}
+
+func (mgr *BlobExpireMgr) expire(ctx context.Context, now int64) error {
+	marker := ""
+	for {
+		blobs, nextMarker, err := mgr.clusterMgrCli.ListExpiredBlobs(ctx, now, marker, mgr.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(blobs) > 0 {
+			if err = mgr.expireBlobs(ctx, blobs); err != nil {
+				return err
+			}
+		}
+		if nextMarker == "" {
+			return nil
+		}
+		marker = nextMarker
+	}
+}
+
+// expireBlobs sends delete messages before removing the expiry,
+// the messages may be sent again if failed to remove the expiry
+func (mgr *BlobExpireMgr) expireBlobs(ctx context.Context, blobs []cmapi.BlobExpire) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	var msgs [][]byte
+	size := uint64(0)
+	for _, blob := range blobs {
+		for i := uint32(0); i < blob.Count; i++ {
+			b, err := json.Marshal(&proto.DeleteMsg{
+				ClusterID: mgr.clusterID,
+				Bid:       blob.MinBid + proto.BlobID(i),
+				Vid:       blob.Vid,
+				Time:      blob.ExpireAt,
+				ReqId:     span.TraceID(),
+				Seq:       len(msgs),
+			})
+			if err != nil {
+				return err
+			}
+			msgs = append(msgs, b)
+		}
+		size += blob.Size
+	}
+
+	if err := mgr.msgSender.SendMessages(msgs); err != nil {
+		span.Errorf("send delete messages failed: count[%d], err[%+v]", len(msgs), err)
+		return err
+	}
+	if err := mgr.clusterMgrCli.DeleteBlobExpire(ctx, blobs); err != nil {
+		span.Errorf("delete blob expire failed: count[%d], err[%+v]", len(blobs), err)
+		return err
+	}
+	mgr.expiredBytesCounter.Add(float64(size))
+	span.Infof("expired blobs: count[%d], size[%d]", len(msgs), size)
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+func newBlobExpireMgr(t *testing.T, clusterMgrCli *MockClusterMgrAPI, sender *MockProducer) *BlobExpireMgr {
+	kafkaClient := NewMockMsgQueue(gomock.NewController(t))
+	kafkaClient.EXPECT().NewMsgSender(any).Return(sender, nil)
+	mgr, err := NewBlobExpireMgr(&BlobDeleteConfig{ClusterID: 1, ExpireIntervalS: 1, ExpireBatchSize: 2}, clusterMgrCli, kafkaClient)
+	require.NoError(t, err)
+	return mgr
+}
+
+func TestBlobExpireMgr(t *testing.T) {
+	ctr := gomock.NewController(t)
+	ctx := context.Background()
+
+	pages := map[string][]cmapi.BlobExpire{
+		"": {
+			{Vid: 1, MinBid: 100, Count: 2, Size: 2048, ExpireAt: 10},
+			{Vid: 1, MinBid: 200, Count: 1, Size: 100, ExpireAt: 20},
+		},
+		"marker": {{Vid: 2, MinBid: 300, Count: 1, Size: 1024, ExpireAt: 30}},
+	}
+	clusterMgrCli := NewMockClusterMgrAPI(ctr)
+	clusterMgrCli.EXPECT().ListExpiredBlobs(any, any, any, any).AnyTimes().DoAndReturn(
+		func(_ context.Context, expireBefore int64, marker string, count int) ([]cmapi.BlobExpire, string, error) {
+			require.Equal(t, int64(100), expireBefore)
+			require.Equal(t, 2, count)
+			if marker == "" {
+				return pages[marker], "marker", nil
+			}
+			return pages[marker], "", nil
+		})
+
+	var deleted []cmapi.BlobExpire
+	clusterMgrCli.EXPECT().DeleteBlobExpire(any, any).AnyTimes().DoAndReturn(
+		func(_ context.Context, blobs []cmapi.BlobExpire) error {
+			deleted = append(deleted, blobs...)
+			return nil
+		})
+
+	var msgs []proto.DeleteMsg
+	sender := NewMockProducer(ctr)
+	sender.EXPECT().SendMessages(any).AnyTimes().DoAndReturn(func(bs [][]byte) error {
+		for _, b := range bs {
+			msg := proto.DeleteMsg{}
+			require.NoError(t, json.Unmarshal(b, &msg))
+			msgs = append(msgs, msg)
+		}
+		return nil
+	})
+
+	mgr := newBlobExpireMgr(t, clusterMgrCli, sender)
+	require.NoError(t, mgr.expire(ctx, 100))
+	require.Equal(t, 3, len(deleted))
+	require.Equal(t, 4, len(msgs))
+	require.Equal(t, proto.BlobID(101), msgs[1].Bid)
+	require.Equal(t, int64(10), msgs[1].Time)
+	require.Equal(t, proto.ClusterID(1), msgs[3].ClusterID)
+	require.Equal(t, proto.Vid(2), msgs[3].Vid)
+
+	// the expiry is kept if failed to send messages
+	failedCli := NewMockClusterMgrAPI(ctr)
+	failedCli.EXPECT().ListExpiredBlobs(any, any, any, any).Return(pages[""], "", nil)
+	failedSender := NewMockProducer(ctr)
+	failedSender.EXPECT().SendMessages(any).Return(errMock)
+	mgr = newBlobExpireMgr(t, failedCli, failedSender)
+	require.ErrorIs(t, mgr.expire(ctx, 100), errMock)
+
+	failedCli.EXPECT().ListExpiredBlobs(any, any, any, any).Return(nil, "", errMock)
+	require.ErrorIs(t, mgr.expire(ctx, 100), errMock)
+
+	mgr.Run()
+	mgr.Close()
+}
@@ -0,0 +1,544 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/counter"
+	"github.com/cubefs/cubefs/blobstore/common/kafka"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/taskswitch"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/scheduler/base"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
+	"github.com/cubefs/cubefs/blobstore/util/taskpool"
+)
+
+type replicateStatus int
+
+// blob replicate status
+const (
+	ReplicateStatusDone = replicateStatus(iota)
+	ReplicateStatusFailed
+	ReplicateStatusUnexpect
+	ReplicateStatusUndo
+)
+
+type replicateRet struct {
+	status   replicateStatus
+	msg      *proto.ReplicateMsg
+	location access.Location
+	ctx      context.Context
+	err      error
+}
+
+// BlobReplicateConfig is blob replicate config, blobs of this cluster are
+// replicated to the replica cluster by its access, and the translation of
+// locations is stored in kv of the replica clustermgr.
+type BlobReplicateConfig struct {
+	ClusterID proto.ClusterID
+	Kafka     BlobReplicateKafkaConfig
+
+	// when the message retry times is greater than this, it will punish for a period of time before consumption
+	MessagePunishThreshold int `json:"message_punish_threshold"`
+	MessagePunishTimeM     int `json:"message_punish_time_m"`
+	// the message is dropped if it is still failed after retry MaxRetry times
+	MaxRetry int `json:"max_retry"`
+
+	TaskPoolSize   int `json:"task_pool_size"`
+	MaxBatchSize   int `json:"max_batch_size"`
+	BatchIntervalS int `json:"batch_interval_s"`
+
+	// replication is disabled if replica access hosts are not configured
+	SourceAccessHosts  []string     `json:"source_access_hosts"`
+	ReplicaAccessHosts []string     `json:"replica_access_hosts"`
+	ReplicaClusterMgr  cmapi.Config `json:"replica_clustermgr"`
+}
+
+// Enabled returns true if the replica cluster is configured
+func (cfg *BlobReplicateConfig) Enabled() bool {
+	return len(cfg.ReplicaAccessHosts) > 0
+}
+
+func (cfg *BlobReplicateConfig) topics() []string {
+	return []string{cfg.Kafka.TopicNormal, cfg.Kafka.TopicFailed}
+}
+
+func (cfg *BlobReplicateConfig) failedProducerConfig() *kafka.ProducerCfg {
+	return &kafka.ProducerCfg{
+		BrokerList: cfg.Kafka.BrokerList,
+		Topic:      cfg.Kafka.TopicFailed,
+		TimeoutMs:  cfg.Kafka.FailMsgSenderTimeoutMs,
+	}
+}
+
+// BlobReplicateMgr is blob replicate manager, it consumes change log of locations
+// and replays them in the replica cluster.
+type BlobReplicateMgr struct {
+	closer.Closer
+	taskSwitch    *taskswitch.TaskSwitch
+	taskPool      *taskpool.TaskPool
+	sourceAccess  access.API
+	replicaAccess access.API
+	replicaCli    cmapi.APIBlobReplica
+
+	// protects read-modify-write of translations
+	translationLock sync.Mutex
+
+	successCounter       prometheus.Counter
+	successCounterByMin  *counter.Counter
+	failCounter          prometheus.Counter
+	failCounterByMin     *counter.Counter
+	errStatsDistribution *base.ErrorStats
+	lagGauge             prometheus.Gauge
+
+	kafkaConsumerClient base.MsgQueue
+	consumers           []base.GroupConsumer
+	punishTime          time.Duration
+	failMsgSender       base.IProducer
+
+	cfg *BlobReplicateConfig
+}
+
+// NewBlobReplicateMgr returns blob replicate manager
+func NewBlobReplicateMgr(
+	cfg *BlobReplicateConfig,
+	switchMgr *taskswitch.SwitchMgr,
+	sourceAccess access.API,
+	replicaAccess access.API,
+	replicaCli cmapi.APIBlobReplica,
+	kafkaClient base.MsgQueue,
+) (*BlobReplicateMgr, error) {
+	failMsgSender, err := kafkaClient.NewMsgSender(cfg.failedProducerConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	taskSwitch, err := switchMgr.AddSwitch(proto.TaskTypeBlobReplicate.String())
+	if err != nil {
+		return nil, err
+	}
+
+	tp := taskpool.New(cfg.TaskPoolSize, cfg.TaskPoolSize)
+
+	return &BlobReplicateMgr{
+		taskSwitch:    taskSwitch,
+		taskPool:      &tp,
+		sourceAccess:  sourceAccess,
+		replicaAccess: replicaAccess,
+		replicaCli:    replicaCli,
+
+		successCounter:       base.NewCounter(cfg.ClusterID, "replicate", base.KindSuccess),
+		successCounterByMin:  &counter.Counter{},
+		failCounter:          base.NewCounter(cfg.ClusterID, "replicate", base.KindFailed),
+		failCounterByMin:     &counter.Counter{},
+		errStatsDistribution: base.NewErrorStats(),
+		lagGauge:             base.NewReplicateLagGauge(cfg.ClusterID),
+
+		kafkaConsumerClient: kafkaClient,
+		punishTime:          time.Duration(cfg.MessagePunishTimeM) * time.Minute,
+		failMsgSender:       failMsgSender,
+		cfg:                 cfg,
+		Closer:              closer.New(),
+	}, nil
+}
+
+func (mgr *BlobReplicateMgr) Run() {
+	go mgr.runTask()
+}
+
+func (mgr *BlobReplicateMgr) Close() {
+	mgr.Closer.Close()
+	mgr.stopConsumer()
+}
+
+func (mgr *BlobReplicateMgr) runTask() {
+	t := time.NewTicker(time.Second)
+	span := trace.SpanFromContextSafe(context.Background())
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			if !mgr.taskSwitch.Enabled() {
+				mgr.stopConsumer()
+				continue
+			}
+			if err := mgr.startConsumer(); err != nil {
+				span.Errorf("run consumer failed: err[%+v]", err)
+				mgr.stopConsumer()
+			}
+		case <-mgr.Done():
+			return
+		}
+	}
+}
+
+func (mgr *BlobReplicateMgr) startConsumer() error {
+	if mgr.consumerRunning() {
+		return nil
+	}
+	for _, topic := range mgr.cfg.topics() {
+		consumer, err := mgr.kafkaConsumerClient.StartKafkaConsumer(base.KafkaConsumerCfg{
+			TaskType:     proto.TaskTypeBlobReplicate,
+			Topic:        topic,
+			MaxBatchSize: mgr.cfg.MaxBatchSize,
+			MaxWaitTimeS: mgr.cfg.BatchIntervalS,
+		}, mgr.Consume)
+		if err != nil {
+			return err
+		}
+		mgr.consumers = append(mgr.consumers, consumer)
+	}
+	return nil
+}
+
+func (mgr *BlobReplicateMgr) stopConsumer() {
+	if !mgr.consumerRunning() {
+		return
+	}
+	for _, consumer := range mgr.consumers {
+		consumer.Stop()
+	}
+	mgr.consumers = nil
+}
+
+func (mgr *BlobReplicateMgr) consumerRunning() bool {
+	return mgr.consumers != nil
+}
+
+// Enabled returns true if replicate task switch is enable, otherwise returns false
+func (mgr *BlobReplicateMgr) Enabled() bool {
+	return mgr.taskSwitch.Enabled()
+}
+
+// GetTaskStats returns task stats
+func (mgr *BlobReplicateMgr) GetTaskStats() (success [counter.SLOT]int, failed [counter.SLOT]int) {
+	return mgr.successCounterByMin.Show(), mgr.failCounterByMin.Show()
+}
+
+// GetErrorStats returns error stats
+func (mgr *BlobReplicateMgr) GetErrorStats() (errStats []string, totalErrCnt uint64) {
+	statsResult, totalErrCnt := mgr.errStatsDistribution.Stats()
+	return base.FormatPrint(statsResult), totalErrCnt
+}
+
+// Consume consume kafka message: if message is not consume will return false, otherwise return true.
+// messages of the same location are replayed in order, and different locations are replayed concurrently.
+func (mgr *BlobReplicateMgr) Consume(msgs []*sarama.ConsumerMessage, consumerPause base.ConsumerPause) bool {
+	items, tracePrefix := mgr.preProcessMsg(msgs)
+	defer mgr.recordAllResult(items)
+
+	span, ctx := trace.StartSpanFromContextWithTraceID(context.Background(), "BlobReplicateConsume", tracePrefix)
+	span.Infof("start replicate msgs len[%d], topic[%s], partition[%d], offset[%d]", len(msgs), msgs[0].Topic, msgs[0].Partition, msgs[0].Offset)
+
+	keys := make([]string, 0, len(items))
+	groups := make(map[string][]*replicateRet)
+	for idx := range items {
+		item := &items[idx]
+		if item.err != nil {
+			item.ctx = ctx
+			continue
+		}
+		key := item.location.ReplicaKey()
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], item)
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		mgr.taskPool.Run(func() {
+			for _, item := range group {
+				mgr.handleOneMsg(ctx, item, consumerPause)
+			}
+			wg.Done()
+		})
+	}
+	wg.Wait()
+
+	for _, v := range items {
+		if v.status == ReplicateStatusUndo {
+			return false
+		}
+	}
+	return true
+}
+
+func (mgr *BlobReplicateMgr) preProcessMsg(msgs []*sarama.ConsumerMessage) (ret []replicateRet, batchTraceId string) {
+	ret = make([]replicateRet, len(msgs))
+	firstValidMsg := true
+
+	for idx, msg := range msgs {
+		err := json.Unmarshal(msg.Value, &ret[idx].msg)
+		if err != nil {
+			ret[idx].err = err
+			ret[idx].status = ReplicateStatusUnexpect
+			continue
+		}
+		if !ret[idx].msg.IsValid() || ret[idx].msg.ClusterID != mgr.cfg.ClusterID {
+			ret[idx].err = proto.ErrInvalidMsg
+			ret[idx].status = ReplicateStatusUnexpect
+			continue
+		}
+		location, err := access.DecodeLocationFromHex(ret[idx].msg.Location)
+		if err != nil {
+			ret[idx].err = err
+			ret[idx].status = ReplicateStatusUnexpect
+			continue
+		}
+		ret[idx].location = location
+		if firstValidMsg {
+			firstValidMsg = false
+			batchTraceId = ret[idx].msg.ReqId
+		}
+	}
+	return ret, batchTraceId
+}
+
+func (mgr *BlobReplicateMgr) handleOneMsg(ctx context.Context, item *replicateRet, consumerPause base.ConsumerPause) {
+	span := trace.SpanFromContextSafe(ctx)
+	_, ctx1 := trace.StartSpanFromContextWithTraceID(ctx, span.OperationName(), span.TraceID()+"_"+item.msg.ReqId)
+	item.ctx = ctx1
+	mgr.consume(item, consumerPause)
+}
+
+func (mgr *BlobReplicateMgr) consume(item *replicateRet, consumerPause base.ConsumerPause) {
+	// quick exit if consumer is pause
+	select {
+	case <-consumerPause.Done():
+		item.status = ReplicateStatusUndo
+		return
+	default:
+	}
+	span := trace.SpanFromContextSafe(item.ctx)
+
+	// if message retry times is greater than MessagePunishThreshold while sleep MessagePunishTimeM minutes
+	if item.msg.Retry >= mgr.cfg.MessagePunishThreshold {
+		span.Warnf("punish message for a while: until[%+v], sleep[%+v], retry[%d]",
+			time.Now().Add(mgr.punishTime), mgr.punishTime, item.msg.Retry)
+		if ok := sleep(mgr.punishTime, consumerPause); !ok {
+			item.status = ReplicateStatusUndo
+			return
+		}
+	}
+
+	span.Debugf("start replicate msg[%+v]", item.msg)
+	var err error
+	switch item.msg.Op {
+	case proto.ReplicateOpPut:
+		err = mgr.replicatePut(item.ctx, &item.location)
+	case proto.ReplicateOpDelete:
+		err = mgr.replicateDelete(item.ctx, &item.location)
+	}
+	if err != nil {
+		item.status = ReplicateStatusFailed
+		item.err = err
+		return
+	}
+
+	mgr.lagGauge.Set(time.Since(time.Unix(item.msg.Time, 0)).Seconds())
+	item.status = ReplicateStatusDone
+}
+
+// replicatePut copies data of location to the replica cluster once, the put
+// replayed again is skipped if the location has been replicated or deleted.
+func (mgr *BlobReplicateMgr) replicatePut(ctx context.Context, location *access.Location) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	key := location.ReplicaKey()
+	translation, err := mgr.replicaCli.GetBlobReplica(ctx, key)
+	if err != nil && rpc.DetectStatusCode(err) != http.StatusNotFound {
+		return fmt.Errorf("get translation: err[%w]", err)
+	}
+	if err == nil && (translation.Deleted || len(translation.Replicas) > 0) {
+		span.Infof("location has been replicated or deleted: key[%s], deleted[%v]", key, translation.Deleted)
+		return nil
+	}
+
+	body, err := mgr.sourceAccess.Get(ctx, &access.GetArgs{Location: *location, ReadSize: location.Size})
+	if err != nil {
+		return fmt.Errorf("get source: err[%w]", err)
+	}
+	defer body.Close()
+
+	replica, _, err := mgr.replicaAccess.Put(ctx, &access.PutArgs{Size: int64(location.Size), Body: body})
+	if err != nil {
+		return fmt.Errorf("put replica: err[%w]", err)
+	}
+
+	translated := false
+	err = mgr.updateTranslation(ctx, key, func(translation *cmapi.BlobReplica) {
+		// replicated by the put replayed concurrently, or deleted meanwhile
+		if translation.Deleted || len(translation.Replicas) > 0 {
+			return
+		}
+		translation.Replicas = append(translation.Replicas, replica.HexString())
+		translated = true
+	})
+	if err != nil || !translated {
+		if _, derr := mgr.replicaAccess.Delete(ctx, &access.DeleteArgs{Locations: []access.Location{replica}}); derr != nil {
+			span.Warnf("delete untranslated replica failed: replica[%+v], err[%+v]", replica, derr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("set translation: err[%w]", err)
+	}
+	span.Debugf("replicate location: key[%s], replica[%+v], translated[%v]", key, replica, translated)
+	return nil
+}
+
+// replicateDelete records the tombstone of location, then deletes all of its
+// replicas in the replica cluster and removes them from the translation.
+func (mgr *BlobReplicateMgr) replicateDelete(ctx context.Context, location *access.Location) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	key := location.ReplicaKey()
+	var hexReplicas []string
+	err := mgr.updateTranslation(ctx, key, func(translation *cmapi.BlobReplica) {
+		translation.Deleted = true
+		hexReplicas = append(hexReplicas, translation.Replicas...)
+	})
+	if err != nil {
+		return fmt.Errorf("set tombstone: err[%w]", err)
+	}
+	if len(hexReplicas) == 0 {
+		return nil
+	}
+
+	replicas := make([]access.Location, 0, len(hexReplicas))
+	for _, hexReplica := range hexReplicas {
+		replica, err := access.DecodeLocationFromHex(hexReplica)
+		if err != nil {
+			span.Errorf("invalid replica will be removed: key[%s], replica[%s], err[%+v]", key, hexReplica, err)
+			continue
+		}
+		replicas = append(replicas, replica)
+	}
+	if len(replicas) > 0 {
+		if _, err = mgr.replicaAccess.Delete(ctx, &access.DeleteArgs{Locations: replicas}); err != nil {
+			return fmt.Errorf("delete replicas: err[%w]", err)
+		}
+	}
+
+	deleted := make(map[string]struct{}, len(hexReplicas))
+	for _, hexReplica := range hexReplicas {
+		deleted[hexReplica] = struct{}{}
+	}
+	err = mgr.updateTranslation(ctx, key, func(translation *cmapi.BlobReplica) {
+		remain := translation.Replicas[:0]
+		for _, hexReplica := range translation.Replicas {
+			if _, ok := deleted[hexReplica]; !ok {
+				remain = append(remain, hexReplica)
+			}
+		}
+		translation.Replicas = remain
+	})
+	if err != nil {
+		return fmt.Errorf("update translation: err[%w]", err)
+	}
+	span.Debugf("delete replicas: key[%s], replicas[%v]", key, hexReplicas)
+	return nil
+}
+
+// updateTranslation modifies translation of key, the translation without replica is
+// deleted unless it is the tombstone of a deleted location
+func (mgr *BlobReplicateMgr) updateTranslation(ctx context.Context, key string, modify func(*cmapi.BlobReplica)) error {
+	mgr.translationLock.Lock()
+	defer mgr.translationLock.Unlock()
+
+	translation, err := mgr.replicaCli.GetBlobReplica(ctx, key)
+	if err != nil && rpc.DetectStatusCode(err) != http.StatusNotFound {
+		return err
+	}
+	translation.Key = key
+	modify(&translation)
+	if len(translation.Replicas) == 0 && !translation.Deleted {
+		if err != nil { // not found
+			return nil
+		}
+		return mgr.replicaCli.DeleteBlobReplica(ctx, key)
+	}
+	return mgr.replicaCli.SetBlobReplica(ctx, &translation)
+}
+
+func (mgr *BlobReplicateMgr) recordAllResult(rets []replicateRet) {
+	for _, ret := range rets {
+		ctx := ret.ctx
+		msg := ret.msg
+		span := trace.SpanFromContextSafe(ctx)
+
+		switch ret.status {
+		case ReplicateStatusDone:
+			span.Debugf("replicate success: op[%s], location[%s]", msg.Op, msg.Location)
+			mgr.successCounterByMin.Add()
+			mgr.successCounter.Inc()
+
+		case ReplicateStatusFailed:
+			mgr.failCounter.Inc()
+			mgr.failCounterByMin.Add()
+			mgr.errStatsDistribution.AddFail(ret.err)
+			if msg.Retry >= mgr.cfg.MaxRetry {
+				span.Errorf("replicate failed and drop msg: msg[%+v], err[%+v]", msg, ret.err)
+				continue
+			}
+
+			span.Warnf("replicate failed and send msg to fail queue: op[%s], location[%s], retry[%d], err[%+v]",
+				msg.Op, msg.Location, msg.Retry, ret.err)
+			base.InsistOn(ctx, "replicator send2FailQueue", func() error {
+				return mgr.send2FailQueue(ctx, msg)
+			})
+		case ReplicateStatusUnexpect:
+			span.Warnf("unexpected result will ignore: msg[%+v], err[%+v]", msg, ret.err)
+		case ReplicateStatusUndo:
+			span.Warnf("replicate message unconsume: msg[%+v]", msg)
+		default:
+			// do nothing
+		}
+	}
+}
+
+func (mgr *BlobReplicateMgr) send2FailQueue(ctx context.Context, msg *proto.ReplicateMsg) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	msg.Retry++
+	b, err := json.Marshal(msg)
+	if err != nil {
+		// just panic if marsh fail
+		span.Panicf("send to fail queue msg json.Marshal failed: msg[%+v], err[%+v]", msg, err)
+	}
+
+	err = mgr.failMsgSender.SendMessage(b)
+	if err != nil {
+		return fmt.Errorf("send message: err[%w]", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/counter"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/taskswitch"
+	"github.com/cubefs/cubefs/blobstore/scheduler/base"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
+	"github.com/cubefs/cubefs/blobstore/util/taskpool"
+)
+
+type mockReplicaCluster struct {
+	putErr       error
+	nextBid      proto.BlobID
+	translations map[string]cmapi.BlobReplica
+	deleted      []access.Location
+}
+
+func newMockBlobReplicateMgr(t *testing.T, replica *mockReplicaCluster, failed *[]proto.ReplicateMsg) *BlobReplicateMgr {
+	ctr := gomock.NewController(t)
+	clusterMgrCli := NewMockClusterMgrAPI(ctr)
+	clusterMgrCli.EXPECT().GetConfig(any, any).AnyTimes().Return("", nil)
+	switchMgr := taskswitch.NewSwitchMgr(clusterMgrCli)
+	taskSwitch, err := switchMgr.AddSwitch(proto.TaskTypeBlobReplicate.String())
+	require.NoError(t, err)
+
+	sourceAccess := mocks.NewMockAccessAPI(ctr)
+	sourceAccess.EXPECT().Get(any, any).AnyTimes().DoAndReturn(
+		func(_ context.Context, args *access.GetArgs) (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(make([]byte, args.ReadSize))), nil
+		})
+
+	replicaAccess := mocks.NewMockAccessAPI(ctr)
+	replicaAccess.EXPECT().Put(any, any).AnyTimes().DoAndReturn(
+		func(_ context.Context, args *access.PutArgs) (access.Location, access.HashSumMap, error) {
+			if replica.putErr != nil {
+				return access.Location{}, nil, replica.putErr
+			}
+			n, err := io.Copy(ioutil.Discard, args.Body)
+			require.NoError(t, err)
+			require.Equal(t, args.Size, n)
+			replica.nextBid++
+			return access.Location{
+				ClusterID: 2,
+				Size:      uint64(n),
+				Blobs:     []access.SliceInfo{{MinBid: replica.nextBid, Vid: 1, Count: 1}},
+			}, nil, nil
+		})
+	replicaAccess.EXPECT().Delete(any, any).AnyTimes().DoAndReturn(
+		func(_ context.Context, args *access.DeleteArgs) ([]access.Location, error) {
+			replica.deleted = append(replica.deleted, args.Locations...)
+			return nil, nil
+		})
+
+	replicaCli := mocks.NewMockClientAPI(ctr)
+	replicaCli.EXPECT().GetBlobReplica(any, any).AnyTimes().DoAndReturn(
+		func(_ context.Context, key string) (cmapi.BlobReplica, error) {
+			translation, ok := replica.translations[key]
+			if !ok {
+				return translation, errcode.ErrNotFound
+			}
+			return translation, nil
+		})
+	replicaCli.EXPECT().SetBlobReplica(any, any).AnyTimes().DoAndReturn(
+		func(_ context.Context, args *cmapi.BlobReplica) error {
+			replica.translations[args.Key] = *args
+			return nil
+		})
+	replicaCli.EXPECT().DeleteBlobReplica(any, any).AnyTimes().DoAndReturn(
+		func(_ context.Context, key string) error {
+			delete(replica.translations, key)
+			return nil
+		})
+
+	producer := NewMockProducer(ctr)
+	producer.EXPECT().SendMessage(any).AnyTimes().DoAndReturn(
+		func(b []byte) error {
+			var msg proto.ReplicateMsg
+			require.NoError(t, json.Unmarshal(b, &msg))
+			*failed = append(*failed, msg)
+			return nil
+		})
+	tp := taskpool.New(2, 2)
+
+	return &BlobReplicateMgr{
+		taskSwitch:    taskSwitch,
+		taskPool:      &tp,
+		sourceAccess:  sourceAccess,
+		replicaAccess: replicaAccess,
+		replicaCli:    replicaCli,
+
+		successCounter:       base.NewCounter(1, "replicate", base.KindSuccess),
+		successCounterByMin:  &counter.Counter{},
+		failCounter:          base.NewCounter(1, "replicate", base.KindFailed),
+		failCounterByMin:     &counter.Counter{},
+		errStatsDistribution: base.NewErrorStats(),
+		lagGauge:             base.NewReplicateLagGauge(1),
+
+		failMsgSender: producer,
+		Closer:        closer.New(),
+		cfg: &BlobReplicateConfig{
+			ClusterID:              1,
+			MessagePunishThreshold: defaultMessagePunishThreshold,
+			MaxRetry:               defaultReplicateMaxRetry,
+		},
+	}
+}
+
+func replicateKafkaMsgs(t *testing.T, msgs ...proto.ReplicateMsg) []*sarama.ConsumerMessage {
+	kafkaMsgs := make([]*sarama.ConsumerMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		b, err := json.Marshal(msg)
+		require.NoError(t, err)
+		kafkaMsgs = append(kafkaMsgs, &sarama.ConsumerMessage{Value: b})
+	}
+	return kafkaMsgs
+}
+
+func TestBlobReplicateConsume(t *testing.T) {
+	replica := &mockReplicaCluster{translations: make(map[string]cmapi.BlobReplica)}
+	var failed []proto.ReplicateMsg
+	mgr := newMockBlobReplicateMgr(t, replica, &failed)
+	consumerPause := closer.New()
+	defer consumerPause.Close()
+
+	location := access.Location{
+		ClusterID: 1,
+		Size:      1024,
+		BlobSize:  1024,
+		Crc:       1,
+		Blobs:     []access.SliceInfo{{MinBid: 100, Vid: 10, Count: 1}},
+	}
+	key := location.ReplicaKey()
+	putMsg := proto.ReplicateMsg{ClusterID: 1, Op: proto.ReplicateOpPut, Location: location.HexString()}
+	delMsg := proto.ReplicateMsg{ClusterID: 1, Op: proto.ReplicateOpDelete, Location: location.HexString()}
+	{
+		// invalid messages are ignored
+		kafkaMsgs := replicateKafkaMsgs(t,
+			proto.ReplicateMsg{},
+			proto.ReplicateMsg{ClusterID: 2, Op: proto.ReplicateOpPut, Location: location.HexString()},
+			proto.ReplicateMsg{ClusterID: 1, Op: proto.ReplicateOpPut, Location: "xxx"},
+		)
+		kafkaMsgs = append(kafkaMsgs, &sarama.ConsumerMessage{Value: []byte("123")})
+		require.True(t, mgr.Consume(kafkaMsgs, consumerPause))
+		require.Equal(t, 0, len(replica.translations))
+	}
+	{
+		// messages of the same location are replayed in order, and the redelivered put is skipped
+		require.True(t, mgr.Consume(replicateKafkaMsgs(t, putMsg, putMsg), consumerPause))
+		require.Equal(t, 1, len(replica.translations[key].Replicas))
+		require.Equal(t, proto.BlobID(1), replica.nextBid)
+
+		require.True(t, mgr.Consume(replicateKafkaMsgs(t, delMsg), consumerPause))
+		require.True(t, replica.translations[key].Deleted)
+		require.Equal(t, 0, len(replica.translations[key].Replicas))
+		require.Equal(t, 1, len(replica.deleted))
+		require.Equal(t, proto.BlobID(1), replica.deleted[0].Blobs[0].MinBid)
+
+		// the tombstone skips the put and delete replayed again
+		require.True(t, mgr.Consume(replicateKafkaMsgs(t, putMsg, delMsg), consumerPause))
+		require.Equal(t, proto.BlobID(1), replica.nextBid)
+		require.Equal(t, 1, len(replica.deleted))
+		require.Equal(t, 0, len(failed))
+	}
+	{
+		// all replicas left by former failures are deleted
+		other := location
+		other.Blobs = []access.SliceInfo{{MinBid: 200, Vid: 10, Count: 1}}
+		replica.translations[other.ReplicaKey()] = cmapi.BlobReplica{
+			Key:      other.ReplicaKey(),
+			Replicas: []string{location.HexString(), other.HexString()},
+		}
+		require.True(t, mgr.Consume(replicateKafkaMsgs(t,
+			proto.ReplicateMsg{ClusterID: 1, Op: proto.ReplicateOpDelete, Location: other.HexString()}), consumerPause))
+		require.True(t, replica.translations[other.ReplicaKey()].Deleted)
+		require.Equal(t, 0, len(replica.translations[other.ReplicaKey()].Replicas))
+		require.Equal(t, 3, len(replica.deleted))
+
+		// location has not been replicated
+		notPut := location
+		notPut.Blobs = []access.SliceInfo{{MinBid: 300, Vid: 10, Count: 1}}
+		require.True(t, mgr.Consume(replicateKafkaMsgs(t,
+			proto.ReplicateMsg{ClusterID: 1, Op: proto.ReplicateOpDelete, Location: notPut.HexString()}), consumerPause))
+		require.True(t, replica.translations[notPut.ReplicaKey()].Deleted)
+		require.Equal(t, 3, len(replica.deleted))
+		require.Equal(t, 0, len(failed))
+	}
+	{
+		// failed message is sent to fail queue, and dropped after max retry
+		replica.putErr = errcode.ErrAccessServiceDiscovery
+		failedLoc := location
+		failedLoc.Blobs = []access.SliceInfo{{MinBid: 400, Vid: 10, Count: 1}}
+		putMsg := proto.ReplicateMsg{ClusterID: 1, Op: proto.ReplicateOpPut, Location: failedLoc.HexString()}
+		retried := putMsg
+		retried.Retry = defaultReplicateMaxRetry
+		require.True(t, mgr.Consume(replicateKafkaMsgs(t, putMsg), consumerPause))
+		require.Equal(t, 1, len(failed))
+		require.Equal(t, 1, failed[0].Retry)
+
+		mgr.cfg.MessagePunishThreshold = defaultReplicateMaxRetry + 1
+		require.True(t, mgr.Consume(replicateKafkaMsgs(t, retried), consumerPause))
+		require.Equal(t, 1, len(failed))
+		_, ok := replica.translations[failedLoc.ReplicaKey()]
+		require.False(t, ok)
+	}
+	{
+		// message is not consumed if consumer is paused
+		pause := closer.New()
+		pause.Close()
+		require.False(t, mgr.Consume(replicateKafkaMsgs(t, putMsg), pause))
+	}
+}
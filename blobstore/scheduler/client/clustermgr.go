@@ -82,6 +82,26 @@ type ClusterMgrTaskAPI interface {
 	SetConsumeOffset(taskType proto.TaskType, topic string, partition int32, offset int64) (err error)
 }
 
+type ClusterMgrTranscodeAPI interface {
+	AddTranscodeTask(ctx context.Context, value *proto.TranscodeTask) (err error)
+	UpdateTranscodeTask(ctx context.Context, value *proto.TranscodeTask) (err error)
+	DeleteTranscodeTask(ctx context.Context, taskID string) (err error)
+	ListAllTranscodeTasks(ctx context.Context) (tasks []*proto.TranscodeTask, err error)
+	SetTranscodeMapping(ctx context.Context, mapping *cmapi.TranscodeMapping) (err error)
+	ListAllTranscodeMappings(ctx context.Context) (mappings []*cmapi.TranscodeMapping, err error)
+}
+
+// ClusterMgrBlobExpireAPI define the interface of clustermgr used by blob expire
+type ClusterMgrBlobExpireAPI interface {
+	ListExpiredBlobs(ctx context.Context, expireBefore int64, marker string, count int) (blobs []cmapi.BlobExpire, nextMarker string, err error)
+	DeleteBlobExpire(ctx context.Context, blobs []cmapi.BlobExpire) (err error)
+}
+
+// ClusterMgrBlobDedupAPI define the interface of clustermgr used by blob delete
+type ClusterMgrBlobDedupAPI interface {
+	ReleaseBlobDedup(ctx context.Context, vid proto.Vid, bid proto.BlobID, refID string) (refs uint32, err error)
+}
+
 // ClusterMgrAPI define the interface of clustermgr used by scheduler
 type ClusterMgrAPI interface {
 	ClusterMgrConfigAPI
@@ -89,6 +109,9 @@ type ClusterMgrAPI interface {
 	ClusterMgrDiskAPI
 	ClusterMgrServiceAPI
 	ClusterMgrTaskAPI
+	ClusterMgrTranscodeAPI
+	ClusterMgrBlobExpireAPI
+	ClusterMgrBlobDedupAPI
 }
 
 // migrate task key
@@ -123,6 +146,13 @@ type ClusterMgrAPI interface {
 //	for example:
 //		blob_delete-consume_offset-blob_delete-1
 //		shard_repair-consume_offset-shard_repair-2
+//
+// transcode task key
+//  - - - - - - - - - - - - - - - - - - - - - - -
+//  |  task_type  |  src volume_id  | random_id |
+//  - - - - - - - - - - - - - - - - - - - - - - -
+//	for example:
+//		transcode-18-cbkgq9qc605btusi7gj0
 
 const (
 	_delimiter           = "-"
@@ -179,6 +209,11 @@ type ConsumeOffset struct {
 	Offset    int64  `json:"offset"`
 }
 
+// GenTranscodeTaskID return uniq transcode task id
+func GenTranscodeTaskID(volumeID proto.Vid) string {
+	return fmt.Sprintf("%s%d%s%s", GenMigrateTaskPrefix(proto.TaskTypeTranscode), volumeID, _delimiter, xid.New().String())
+}
+
 func genVolumeInspectCheckpointKey() string {
 	return proto.TaskTypeVolumeInspect.String() + _delimiter + _checkPoint
 }
@@ -192,6 +227,8 @@ type VolumeInfoSimple struct {
 	Vid            proto.Vid             `json:"vid"`
 	CodeMode       codemode.CodeMode     `json:"code_mode"`
 	Status         proto.VolumeStatus    `json:"status"`
+	Free           uint64                `json:"free"`
+	Used           uint64                `json:"used"`
 	VunitLocations []proto.VunitLocation `json:"vunit_locations"`
 }
 
@@ -227,6 +264,8 @@ func (vol *VolumeInfoSimple) set(info *cmapi.VolumeInfo) {
 	vol.Vid = info.Vid
 	vol.CodeMode = info.CodeMode
 	vol.Status = info.Status
+	vol.Free = info.Free
+	vol.Used = info.Used
 	vol.VunitLocations = make([]proto.VunitLocation, len(info.Units))
 
 	// check volume info
@@ -376,6 +415,11 @@ type IClusterManager interface {
 	DeleteKV(ctx context.Context, key string) (err error)
 	SetKV(ctx context.Context, key string, value []byte) (err error)
 	ListKV(ctx context.Context, args *cmapi.ListKvOpts) (ret cmapi.ListKvRet, err error)
+	SetTranscodeMapping(ctx context.Context, args *cmapi.TranscodeMapping) (err error)
+	ListTranscodeMapping(ctx context.Context, args *cmapi.ListTranscodeMappingArgs) (ret cmapi.ListTranscodeMappingRet, err error)
+	ListBlobExpire(ctx context.Context, args *cmapi.ListBlobExpireArgs) (ret cmapi.ListBlobExpireRet, err error)
+	DeleteBlobExpire(ctx context.Context, args *cmapi.DeleteBlobExpireArgs) (err error)
+	ReleaseBlobDedup(ctx context.Context, args *cmapi.ReleaseBlobDedupArgs) (ret cmapi.ReleaseBlobDedupRet, err error)
 }
 
 // clustermgrClient clustermgr client
@@ -975,3 +1019,96 @@ func (c *clustermgrClient) SetConsumeOffset(taskType proto.TaskType, topic strin
 	}
 	return c.client.SetKV(context.Background(), genConsumerOffsetKey(taskType, topic, partition), consumeOffsetBytes)
 }
+
+// AddTranscodeTask adds transcode task
+func (c *clustermgrClient) AddTranscodeTask(ctx context.Context, value *proto.TranscodeTask) (err error) {
+	value.Ctime = time.Now().String()
+	value.MTime = value.Ctime
+	return c.setTask(ctx, value.TaskID, value)
+}
+
+// UpdateTranscodeTask updates transcode task
+func (c *clustermgrClient) UpdateTranscodeTask(ctx context.Context, value *proto.TranscodeTask) (err error) {
+	value.MTime = time.Now().String()
+	return c.setTask(ctx, value.TaskID, value)
+}
+
+// DeleteTranscodeTask deletes transcode task
+func (c *clustermgrClient) DeleteTranscodeTask(ctx context.Context, taskID string) (err error) {
+	return c.client.DeleteKV(ctx, taskID)
+}
+
+// ListAllTranscodeTasks returns all transcode tasks
+func (c *clustermgrClient) ListAllTranscodeTasks(ctx context.Context) (tasks []*proto.TranscodeTask, err error) {
+	span := trace.SpanFromContextSafe(ctx)
+	marker := defaultListTaskMarker
+	for {
+		ret, err := c.client.ListKV(ctx, &cmapi.ListKvOpts{
+			Prefix: GenMigrateTaskPrefix(proto.TaskTypeTranscode),
+			Count:  defaultListTaskNum,
+			Marker: marker,
+		})
+		if err != nil {
+			span.Errorf("list transcode task failed: err[%+v]", err)
+			return nil, err
+		}
+		for _, v := range ret.Kvs {
+			var task *proto.TranscodeTask
+			if err = json.Unmarshal(v.Value, &task); err != nil {
+				span.Errorf("unmarshal transcode task failed: err[%+v]", err)
+				return nil, err
+			}
+			tasks = append(tasks, task)
+		}
+		marker = ret.Marker
+		if marker == defaultListTaskMarker {
+			break
+		}
+	}
+	return
+}
+
+// SetTranscodeMapping sets the transcode mapping of the volume
+func (c *clustermgrClient) SetTranscodeMapping(ctx context.Context, mapping *cmapi.TranscodeMapping) (err error) {
+	return c.client.SetTranscodeMapping(ctx, mapping)
+}
+
+// ListAllTranscodeMappings returns all transcode mappings
+func (c *clustermgrClient) ListAllTranscodeMappings(ctx context.Context) (mappings []*cmapi.TranscodeMapping, err error) {
+	marker := proto.InvalidVid
+	for {
+		ret, err := c.client.ListTranscodeMapping(ctx, &cmapi.ListTranscodeMappingArgs{Marker: marker, Count: defaultListTaskNum})
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, ret.Mappings...)
+		marker = ret.Marker
+		if marker == proto.InvalidVid {
+			break
+		}
+	}
+	return
+}
+
+// ListExpiredBlobs returns blobs expired before expireBefore, nextMarker is empty if there are no more blobs
+func (c *clustermgrClient) ListExpiredBlobs(ctx context.Context, expireBefore int64, marker string, count int) (blobs []cmapi.BlobExpire, nextMarker string, err error) {
+	ret, err := c.client.ListBlobExpire(ctx, &cmapi.ListBlobExpireArgs{ExpireBefore: expireBefore, Marker: marker, Count: count})
+	if err != nil {
+		return nil, "", err
+	}
+	return ret.Blobs, ret.Marker, nil
+}
+
+// DeleteBlobExpire removes the expiry of blobs if it has not been reset since listed
+func (c *clustermgrClient) DeleteBlobExpire(ctx context.Context, blobs []cmapi.BlobExpire) (err error) {
+	return c.client.DeleteBlobExpire(ctx, &cmapi.DeleteBlobExpireArgs{Blobs: blobs})
+}
+
+// ReleaseBlobDedup releases one reference of blob once with refID, returns the remaining references
+func (c *clustermgrClient) ReleaseBlobDedup(ctx context.Context, vid proto.Vid, bid proto.BlobID, refID string) (refs uint32, err error) {
+	ret, err := c.client.ReleaseBlobDedup(ctx, &cmapi.ReleaseBlobDedupArgs{Vid: vid, Bid: bid, RefID: refID})
+	if err != nil {
+		return 0, err
+	}
+	return ret.Refs, nil
+}
@@ -52,6 +52,20 @@ func (mr *MockClusterManagerMockRecorder) AllocVolumeUnit(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllocVolumeUnit", reflect.TypeOf((*MockClusterManager)(nil).AllocVolumeUnit), arg0, arg1)
 }
 
+// DeleteBlobExpire mocks base method.
+func (m *MockClusterManager) DeleteBlobExpire(arg0 context.Context, arg1 *clustermgr.DeleteBlobExpireArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlobExpire", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBlobExpire indicates an expected call of DeleteBlobExpire.
+func (mr *MockClusterManagerMockRecorder) DeleteBlobExpire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlobExpire", reflect.TypeOf((*MockClusterManager)(nil).DeleteBlobExpire), arg0, arg1)
+}
+
 // DeleteKV mocks base method.
 func (m *MockClusterManager) DeleteKV(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -155,6 +169,21 @@ func (mr *MockClusterManagerMockRecorder) GetVolumeInfo(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVolumeInfo", reflect.TypeOf((*MockClusterManager)(nil).GetVolumeInfo), arg0, arg1)
 }
 
+// ListBlobExpire mocks base method.
+func (m *MockClusterManager) ListBlobExpire(arg0 context.Context, arg1 *clustermgr.ListBlobExpireArgs) (clustermgr.ListBlobExpireRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlobExpire", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.ListBlobExpireRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlobExpire indicates an expected call of ListBlobExpire.
+func (mr *MockClusterManagerMockRecorder) ListBlobExpire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlobExpire", reflect.TypeOf((*MockClusterManager)(nil).ListBlobExpire), arg0, arg1)
+}
+
 // ListDisk mocks base method.
 func (m *MockClusterManager) ListDisk(arg0 context.Context, arg1 *clustermgr.ListOptionArgs) (clustermgr.ListDiskRet, error) {
 	m.ctrl.T.Helper()
@@ -200,6 +229,21 @@ func (mr *MockClusterManagerMockRecorder) ListKV(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKV", reflect.TypeOf((*MockClusterManager)(nil).ListKV), arg0, arg1)
 }
 
+// ListTranscodeMapping mocks base method.
+func (m *MockClusterManager) ListTranscodeMapping(arg0 context.Context, arg1 *clustermgr.ListTranscodeMappingArgs) (clustermgr.ListTranscodeMappingRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTranscodeMapping", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.ListTranscodeMappingRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTranscodeMapping indicates an expected call of ListTranscodeMapping.
+func (mr *MockClusterManagerMockRecorder) ListTranscodeMapping(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTranscodeMapping", reflect.TypeOf((*MockClusterManager)(nil).ListTranscodeMapping), arg0, arg1)
+}
+
 // ListVolume mocks base method.
 func (m *MockClusterManager) ListVolume(arg0 context.Context, arg1 *clustermgr.ListVolumeArgs) (clustermgr.ListVolumes, error) {
 	m.ctrl.T.Helper()
@@ -258,6 +302,21 @@ func (mr *MockClusterManagerMockRecorder) RegisterService(arg0, arg1, arg2, arg3
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterService", reflect.TypeOf((*MockClusterManager)(nil).RegisterService), arg0, arg1, arg2, arg3, arg4)
 }
 
+// ReleaseBlobDedup mocks base method.
+func (m *MockClusterManager) ReleaseBlobDedup(arg0 context.Context, arg1 *clustermgr.ReleaseBlobDedupArgs) (clustermgr.ReleaseBlobDedupRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseBlobDedup", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.ReleaseBlobDedupRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseBlobDedup indicates an expected call of ReleaseBlobDedup.
+func (mr *MockClusterManagerMockRecorder) ReleaseBlobDedup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseBlobDedup", reflect.TypeOf((*MockClusterManager)(nil).ReleaseBlobDedup), arg0, arg1)
+}
+
 // ReleaseVolumeUnit mocks base method.
 func (m *MockClusterManager) ReleaseVolumeUnit(arg0 context.Context, arg1 *clustermgr.ReleaseVolumeUnitArgs) error {
 	m.ctrl.T.Helper()
@@ -300,6 +359,20 @@ func (mr *MockClusterManagerMockRecorder) SetKV(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetKV", reflect.TypeOf((*MockClusterManager)(nil).SetKV), arg0, arg1, arg2)
 }
 
+// SetTranscodeMapping mocks base method.
+func (m *MockClusterManager) SetTranscodeMapping(arg0 context.Context, arg1 *clustermgr.TranscodeMapping) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTranscodeMapping", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTranscodeMapping indicates an expected call of SetTranscodeMapping.
+func (mr *MockClusterManagerMockRecorder) SetTranscodeMapping(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTranscodeMapping", reflect.TypeOf((*MockClusterManager)(nil).SetTranscodeMapping), arg0, arg1)
+}
+
 // UnlockVolume mocks base method.
 func (m *MockClusterManager) UnlockVolume(arg0 context.Context, arg1 *clustermgr.UnlockVolumeArgs) error {
 	m.ctrl.T.Helper()
@@ -65,6 +65,20 @@ func (mr *MockClusterMgrAPIMockRecorder) AddMigratingDisk(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMigratingDisk", reflect.TypeOf((*MockClusterMgrAPI)(nil).AddMigratingDisk), arg0, arg1)
 }
 
+// AddTranscodeTask mocks base method.
+func (m *MockClusterMgrAPI) AddTranscodeTask(arg0 context.Context, arg1 *proto.TranscodeTask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTranscodeTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTranscodeTask indicates an expected call of AddTranscodeTask.
+func (mr *MockClusterMgrAPIMockRecorder) AddTranscodeTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTranscodeTask", reflect.TypeOf((*MockClusterMgrAPI)(nil).AddTranscodeTask), arg0, arg1)
+}
+
 // AllocVolumeUnit mocks base method.
 func (m *MockClusterMgrAPI) AllocVolumeUnit(arg0 context.Context, arg1 proto.Vuid) (*client.AllocVunitInfo, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +94,20 @@ func (mr *MockClusterMgrAPIMockRecorder) AllocVolumeUnit(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllocVolumeUnit", reflect.TypeOf((*MockClusterMgrAPI)(nil).AllocVolumeUnit), arg0, arg1)
 }
 
+// DeleteBlobExpire mocks base method.
+func (m *MockClusterMgrAPI) DeleteBlobExpire(arg0 context.Context, arg1 []clustermgr.BlobExpire) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlobExpire", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBlobExpire indicates an expected call of DeleteBlobExpire.
+func (mr *MockClusterMgrAPIMockRecorder) DeleteBlobExpire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlobExpire", reflect.TypeOf((*MockClusterMgrAPI)(nil).DeleteBlobExpire), arg0, arg1)
+}
+
 // DeleteMigrateTask mocks base method.
 func (m *MockClusterMgrAPI) DeleteMigrateTask(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -108,6 +136,20 @@ func (mr *MockClusterMgrAPIMockRecorder) DeleteMigratingDisk(arg0, arg1, arg2 in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMigratingDisk", reflect.TypeOf((*MockClusterMgrAPI)(nil).DeleteMigratingDisk), arg0, arg1, arg2)
 }
 
+// DeleteTranscodeTask mocks base method.
+func (m *MockClusterMgrAPI) DeleteTranscodeTask(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTranscodeTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTranscodeTask indicates an expected call of DeleteTranscodeTask.
+func (mr *MockClusterMgrAPIMockRecorder) DeleteTranscodeTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTranscodeTask", reflect.TypeOf((*MockClusterMgrAPI)(nil).DeleteTranscodeTask), arg0, arg1)
+}
+
 // GetConfig mocks base method.
 func (m *MockClusterMgrAPI) GetConfig(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -258,6 +300,36 @@ func (mr *MockClusterMgrAPIMockRecorder) ListAllMigrateTasksByDiskID(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllMigrateTasksByDiskID", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListAllMigrateTasksByDiskID), arg0, arg1, arg2)
 }
 
+// ListAllTranscodeMappings mocks base method.
+func (m *MockClusterMgrAPI) ListAllTranscodeMappings(arg0 context.Context) ([]*clustermgr.TranscodeMapping, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllTranscodeMappings", arg0)
+	ret0, _ := ret[0].([]*clustermgr.TranscodeMapping)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllTranscodeMappings indicates an expected call of ListAllTranscodeMappings.
+func (mr *MockClusterMgrAPIMockRecorder) ListAllTranscodeMappings(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllTranscodeMappings", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListAllTranscodeMappings), arg0)
+}
+
+// ListAllTranscodeTasks mocks base method.
+func (m *MockClusterMgrAPI) ListAllTranscodeTasks(arg0 context.Context) ([]*proto.TranscodeTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllTranscodeTasks", arg0)
+	ret0, _ := ret[0].([]*proto.TranscodeTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllTranscodeTasks indicates an expected call of ListAllTranscodeTasks.
+func (mr *MockClusterMgrAPIMockRecorder) ListAllTranscodeTasks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllTranscodeTasks", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListAllTranscodeTasks), arg0)
+}
+
 // ListBrokenDisks mocks base method.
 func (m *MockClusterMgrAPI) ListBrokenDisks(arg0 context.Context) ([]*client.DiskInfoSimple, error) {
 	m.ctrl.T.Helper()
@@ -318,6 +390,22 @@ func (mr *MockClusterMgrAPIMockRecorder) ListDropDisks(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDropDisks", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListDropDisks), arg0)
 }
 
+// ListExpiredBlobs mocks base method.
+func (m *MockClusterMgrAPI) ListExpiredBlobs(arg0 context.Context, arg1 int64, arg2 string, arg3 int) ([]clustermgr.BlobExpire, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredBlobs", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]clustermgr.BlobExpire)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListExpiredBlobs indicates an expected call of ListExpiredBlobs.
+func (mr *MockClusterMgrAPIMockRecorder) ListExpiredBlobs(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredBlobs", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListExpiredBlobs), arg0, arg1, arg2, arg3)
+}
+
 // ListMigrateTasks mocks base method.
 func (m *MockClusterMgrAPI) ListMigrateTasks(arg0 context.Context, arg1 proto.TaskType, arg2 *clustermgr.ListKvOpts) ([]*proto.MigrateTask, string, error) {
 	m.ctrl.T.Helper()
@@ -408,6 +496,21 @@ func (mr *MockClusterMgrAPIMockRecorder) Register(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockClusterMgrAPI)(nil).Register), arg0, arg1)
 }
 
+// ReleaseBlobDedup mocks base method.
+func (m *MockClusterMgrAPI) ReleaseBlobDedup(arg0 context.Context, arg1 proto.Vid, arg2 proto.BlobID, arg3 string) (uint32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseBlobDedup", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(uint32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseBlobDedup indicates an expected call of ReleaseBlobDedup.
+func (mr *MockClusterMgrAPIMockRecorder) ReleaseBlobDedup(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseBlobDedup", reflect.TypeOf((*MockClusterMgrAPI)(nil).ReleaseBlobDedup), arg0, arg1, arg2, arg3)
+}
+
 // ReleaseVolumeUnit mocks base method.
 func (m *MockClusterMgrAPI) ReleaseVolumeUnit(arg0 context.Context, arg1 proto.Vuid, arg2 proto.DiskID) error {
 	m.ctrl.T.Helper()
@@ -478,6 +581,20 @@ func (mr *MockClusterMgrAPIMockRecorder) SetDiskRepairing(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDiskRepairing", reflect.TypeOf((*MockClusterMgrAPI)(nil).SetDiskRepairing), arg0, arg1)
 }
 
+// SetTranscodeMapping mocks base method.
+func (m *MockClusterMgrAPI) SetTranscodeMapping(arg0 context.Context, arg1 *clustermgr.TranscodeMapping) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTranscodeMapping", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTranscodeMapping indicates an expected call of SetTranscodeMapping.
+func (mr *MockClusterMgrAPIMockRecorder) SetTranscodeMapping(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTranscodeMapping", reflect.TypeOf((*MockClusterMgrAPI)(nil).SetTranscodeMapping), arg0, arg1)
+}
+
 // SetVolumeInspectCheckPoint mocks base method.
 func (m *MockClusterMgrAPI) SetVolumeInspectCheckPoint(arg0 context.Context, arg1 proto.Vid) error {
 	m.ctrl.T.Helper()
@@ -520,6 +637,20 @@ func (mr *MockClusterMgrAPIMockRecorder) UpdateMigrateTask(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMigrateTask", reflect.TypeOf((*MockClusterMgrAPI)(nil).UpdateMigrateTask), arg0, arg1)
 }
 
+// UpdateTranscodeTask mocks base method.
+func (m *MockClusterMgrAPI) UpdateTranscodeTask(arg0 context.Context, arg1 *proto.TranscodeTask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTranscodeTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTranscodeTask indicates an expected call of UpdateTranscodeTask.
+func (mr *MockClusterMgrAPIMockRecorder) UpdateTranscodeTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTranscodeTask", reflect.TypeOf((*MockClusterMgrAPI)(nil).UpdateTranscodeTask), arg0, arg1)
+}
+
 // UpdateVolume mocks base method.
 func (m *MockClusterMgrAPI) UpdateVolume(arg0 context.Context, arg1, arg2 proto.Vuid, arg3 proto.DiskID) error {
 	m.ctrl.T.Helper()
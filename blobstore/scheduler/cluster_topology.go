@@ -24,6 +24,7 @@ import (
 
 	"golang.org/x/sync/singleflight"
 
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/common/trace"
@@ -52,6 +53,7 @@ type IClusterTopology interface {
 	GetIDCDisks(idc string) (disks []*client.DiskInfoSimple)
 	MaxFreeChunksDisk(idc string) *client.DiskInfoSimple
 	IsBrokenDisk(diskID proto.DiskID) bool
+	GetTranscodeMapping(vid proto.Vid) (*cmapi.TranscodeMapping, bool)
 	IVolumeCache
 	closer.Closer
 }
@@ -174,6 +176,8 @@ type ClusterTopologyMgr struct {
 	clusterTopology *ClusterTopology
 	brokenDisks     *sync.Map
 	volumeCache     IVolumeCache
+	// transcode mappings of volumes, loaded on all of the nodes
+	transcodeMappings *sync.Map
 
 	cfg *clusterTopologyConfig
 
@@ -192,9 +196,10 @@ func NewClusterTopologyMgr(topologyClient client.ClusterMgrAPI, cfg *clusterTopo
 			idcMap:  make(map[string]*IDC),
 			diskMap: make(map[string][]*client.DiskInfoSimple),
 		},
-		brokenDisks:  &sync.Map{},
-		cfg:          cfg,
-		taskStatsMgr: base.NewClusterTopologyStatisticsMgr(cfg.ClusterID, cfg.FreeChunkCounterBuckets),
+		brokenDisks:       &sync.Map{},
+		transcodeMappings: &sync.Map{},
+		cfg:               cfg,
+		taskStatsMgr:      base.NewClusterTopologyStatisticsMgr(cfg.ClusterID, cfg.FreeChunkCounterBuckets),
 	}
 	go mgr.loopUpdate()
 	return mgr
@@ -204,11 +209,13 @@ func (m *ClusterTopologyMgr) loopUpdate() {
 	t := time.NewTicker(m.cfg.UpdateInterval)
 	defer t.Stop()
 
+	m.loadTranscodeMappings()
 	for {
 		select {
 		case <-t.C:
 			m.loadNormalDisks()
 			m.loadBrokenDisks()
+			m.loadTranscodeMappings()
 		case <-m.Closer.Done():
 			return
 		}
@@ -260,6 +267,30 @@ func (m *ClusterTopologyMgr) loadBrokenDisks() {
 	m.brokenDisks = newBrokenDisks
 }
 
+func (m *ClusterTopologyMgr) loadTranscodeMappings() {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "loadTranscodeMappings")
+
+	mappings, err := m.clusterMgrCli.ListAllTranscodeMappings(ctx)
+	if err != nil {
+		span.Errorf("list transcode mappings failed: err[%+v]", err)
+		return
+	}
+	newMappings := &sync.Map{}
+	for _, mapping := range mappings {
+		newMappings.Store(mapping.Vid, mapping)
+	}
+	m.transcodeMappings = newMappings
+}
+
+// GetTranscodeMapping returns the transcode mapping if the volume is transcoded
+func (m *ClusterTopologyMgr) GetTranscodeMapping(vid proto.Vid) (*cmapi.TranscodeMapping, bool) {
+	mapping, ok := m.transcodeMappings.Load(vid)
+	if !ok {
+		return nil, false
+	}
+	return mapping.(*cmapi.TranscodeMapping), true
+}
+
 func (m *ClusterTopologyMgr) IsBrokenDisk(diskID proto.DiskID) bool {
 	_, broken := m.brokenDisks.Load(diskID)
 	return broken
@@ -22,6 +22,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/scheduler/base"
 	"github.com/cubefs/cubefs/blobstore/scheduler/client"
@@ -110,6 +111,8 @@ func TestNewClusterTopologyMgr(t *testing.T) {
 	clusterMgrCli.EXPECT().ListClusterDisks(any).AnyTimes().Return([]*client.DiskInfoSimple{testDisk1}, nil)
 	clusterMgrCli.EXPECT().ListBrokenDisks(any).AnyTimes().Return([]*client.DiskInfoSimple{testDisk2}, nil)
 	clusterMgrCli.EXPECT().ListRepairingDisks(any).AnyTimes().Return([]*client.DiskInfoSimple{testDisk2}, nil)
+	clusterMgrCli.EXPECT().ListAllTranscodeMappings(any).AnyTimes().Return(
+		[]*cmapi.TranscodeMapping{{Vid: proto.Vid(2), DstVid: proto.Vid(3), Status: cmapi.TranscodeMappingStatusDone}}, nil)
 	clusterMgrCli.EXPECT().ListVolume(any, any, any).Times(3).Return(nil, defaultMarker, errMock)
 	clusterMgrCli.EXPECT().GetVolumeInfo(any, any).Return(nil, errMock)
 	clusterMgrCli.EXPECT().GetVolumeInfo(any, any).DoAndReturn(
@@ -137,6 +140,14 @@ func TestNewClusterTopologyMgr(t *testing.T) {
 	require.True(t, mgr.IsBrokenDisk(testDisk2.DiskID))
 	require.False(t, mgr.IsBrokenDisk(testDisk1.DiskID))
 
+	// transcode mappings
+	topology.loadTranscodeMappings()
+	mapping, ok := mgr.GetTranscodeMapping(proto.Vid(2))
+	require.True(t, ok)
+	require.Equal(t, proto.Vid(3), mapping.DstVid)
+	_, ok = mgr.GetTranscodeMapping(proto.Vid(1))
+	require.False(t, ok)
+
 	// update volume
 	err := mgr.LoadVolumes()
 	require.ErrorIs(t, err, errMock)
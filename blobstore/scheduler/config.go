@@ -44,6 +44,11 @@ const (
 	defaultInspectBatch      = 1000
 	defaultInspectTimeoutMs  = 10000
 
+	defaultTranscodeTaskLimit      = 1
+	defaultTranscodeCheckIntervalS = 10
+	defaultTranscodeTimeoutS       = 3600
+	defaultTranscodeSyncDelayS     = 300
+
 	defaultTaskPoolSize           = 10
 	defaultDeleteHourRangeTo      = 24
 	defaultMessagePunishThreshold = 3
@@ -51,6 +56,9 @@ const (
 	defaultSlowDownTimeS          = 3
 	defaultDeleteLogChunkSize     = uint(29)
 	defaultDeleteDelayH           = int64(72)
+	defaultExpireIntervalS        = 60
+	defaultExpireBatchSize        = 1000
+	maxExpireBatchSize            = 1024
 	defaultDeleteNoDelay          = int64(0)
 	defaultMaxBatchSize           = 10
 	defaultBatchIntervalSec       = 2
@@ -65,6 +73,10 @@ const (
 
 	defaultBlobDeleteNormalTopic = "blob_delete"
 	defaultBlobDeleteFailedTopic = "blob_delete_failed"
+
+	defaultBlobReplicateNormalTopic = "blob_replicate"
+	defaultBlobReplicateFailedTopic = "blob_replicate_failed"
+	defaultReplicateMaxRetry        = 10
 )
 
 // Config service config
@@ -83,17 +95,20 @@ type Config struct {
 	Blobnode   blobnode.Config   `json:"blobnode"`
 	Scheduler  scheduler.Config  `json:"scheduler"`
 
-	Balance       BalanceMgrConfig    `json:"balance"`
-	DiskDrop      MigrateConfig       `json:"disk_drop"`
-	DiskRepair    MigrateConfig       `json:"disk_repair"`
-	ManualMigrate MigrateConfig       `json:"manual_migrate"`
-	VolumeInspect VolumeInspectMgrCfg `json:"volume_inspect"`
-	TaskLog       recordlog.Config    `json:"task_log"`
+	Balance         BalanceMgrConfig      `json:"balance"`
+	DiskDrop        MigrateConfig         `json:"disk_drop"`
+	DiskRepair      MigrateConfig         `json:"disk_repair"`
+	ManualMigrate   MigrateConfig         `json:"manual_migrate"`
+	VolumeInspect   VolumeInspectMgrCfg   `json:"volume_inspect"`
+	VolumeTranscode VolumeTranscodeMgrCfg `json:"volume_transcode"`
+	TaskLog         recordlog.Config      `json:"task_log"`
 
 	Kafka       KafkaConfig       `json:"kafka"`
 	ShardRepair ShardRepairConfig `json:"shard_repair"`
 	BlobDelete  BlobDeleteConfig  `json:"blob_delete"`
 
+	BlobReplicate BlobReplicateConfig `json:"blob_replicate"`
+
 	ServiceRegister ServiceRegisterConfig `json:"service_register"`
 }
 
@@ -122,11 +137,21 @@ type BlobDeleteKafkaConfig struct {
 	TopicFailed            string
 }
 
+// BlobReplicateKafkaConfig is kafka config of blob replicate
+type BlobReplicateKafkaConfig struct {
+	BrokerList             []string
+	FailMsgSenderTimeoutMs int64
+	TopicNormal            string
+	TopicFailed            string
+}
+
 type Topics struct {
-	ShardRepair       []string `json:"shard_repair"`
-	ShardRepairFailed string   `json:"shard_repair_failed"`
-	BlobDelete        string   `json:"blob_delete"`
-	BlobDeleteFailed  string   `json:"blob_delete_failed"`
+	ShardRepair         []string `json:"shard_repair"`
+	ShardRepairFailed   string   `json:"shard_repair_failed"`
+	BlobDelete          string   `json:"blob_delete"`
+	BlobDeleteFailed    string   `json:"blob_delete_failed"`
+	BlobReplicate       string   `json:"blob_replicate"`
+	BlobReplicateFailed string   `json:"blob_replicate_failed"`
 }
 
 // KafkaConfig kafka config
@@ -135,6 +160,7 @@ type KafkaConfig struct {
 	Topics                 Topics   `json:"topics"`
 	FailMsgSenderTimeoutMs int64    `json:"fail_msg_sender_timeout_ms"`
 	Version                string   `json:"version"`
+	Backend                string   `json:"backend"` // kafka or clustermgr, default is kafka
 }
 
 type Services struct {
@@ -193,10 +219,12 @@ func (c *Config) fixConfig() (err error) {
 	c.fixDiskRepairConfig()
 	c.fixManualMigrateConfig()
 	c.fixInspectConfig()
+	c.fixTranscodeConfig()
 	c.fixShardRepairConfig()
 	if err := c.fixBlobDeleteConfig(); err != nil {
 		return err
 	}
+	c.fixBlobReplicateConfig()
 	c.fixRegisterConfig()
 	return nil
 }
@@ -214,10 +242,20 @@ func (c *Config) fixKafkaConfig() (err error) {
 	defaulter.Empty(&c.Kafka.Topics.BlobDelete, defaultBlobDeleteNormalTopic)
 	defaulter.Empty(&c.Kafka.Topics.BlobDeleteFailed, defaultBlobDeleteFailedTopic)
 	defaulter.Empty(&c.Kafka.Topics.ShardRepairFailed, defaultShardRepairFailedTopic)
+	defaulter.Empty(&c.Kafka.Topics.BlobReplicate, defaultBlobReplicateNormalTopic)
+	defaulter.Empty(&c.Kafka.Topics.BlobReplicateFailed, defaultBlobReplicateFailedTopic)
 	defaulter.LessOrEqual(&c.Kafka.FailMsgSenderTimeoutMs, defaultClientTimeoutMs)
 	if len(c.Kafka.Topics.ShardRepair) == 0 {
 		c.Kafka.Topics.ShardRepair = []string{defaultShardRepairNormalTopic, defaultShardRepairPriorityTopic}
 	}
+	defaulter.Empty(&c.Kafka.Backend, proto.MQBackendKafka)
+	switch c.Kafka.Backend {
+	case proto.MQBackendKafka:
+	case proto.MQBackendClusterMgr:
+		return nil
+	default:
+		return errIllegalMQBackend
+	}
 	if c.Kafka.Version != "" {
 		kafkaVersion, err := sarama.ParseKafkaVersion(c.Kafka.Version)
 		if err != nil {
@@ -261,6 +299,19 @@ func (c *Config) fixInspectConfig() {
 	defaulter.LessOrEqual(&c.VolumeInspect.InspectIntervalS, defaultInspectIntervalS)
 }
 
+func (c *Config) fixTranscodeConfig() {
+	defaulter.LessOrEqual(&c.VolumeTranscode.TaskLimit, defaultTranscodeTaskLimit)
+	defaulter.LessOrEqual(&c.VolumeTranscode.CheckIntervalS, defaultTranscodeCheckIntervalS)
+	defaulter.LessOrEqual(&c.VolumeTranscode.ListVolStep, defaultListVolStep)
+	defaulter.LessOrEqual(&c.VolumeTranscode.TimeoutS, defaultTranscodeTimeoutS)
+	defaulter.LessOrEqual(&c.VolumeTranscode.SyncDelayS, defaultTranscodeSyncDelayS)
+	// the mappings are updated with the topology in scheduler
+	if minDelayS := 2 * c.TopologyUpdateIntervalMin * 60; c.VolumeTranscode.SyncDelayS < minDelayS {
+		c.VolumeTranscode.SyncDelayS = minDelayS
+	}
+	defaulter.Less(&c.VolumeTranscode.FreeReserveRate, 0)
+}
+
 func (c *Config) fixShardRepairConfig() {
 	c.ShardRepair.ClusterID = c.ClusterID
 	defaulter.LessOrEqual(&c.ShardRepair.TaskPoolSize, defaultTaskPoolSize)
@@ -290,6 +341,11 @@ func (c *Config) fixBlobDeleteConfig() error {
 	defaulter.Less(&c.BlobDelete.SafeDelayTimeH, defaultDeleteNoDelay)
 	defaulter.Equal(&c.BlobDelete.MaxBatchSize, defaultMaxBatchSize)
 	defaulter.Equal(&c.BlobDelete.BatchIntervalS, defaultBatchIntervalSec)
+	defaulter.LessOrEqual(&c.BlobDelete.ExpireIntervalS, defaultExpireIntervalS)
+	defaulter.LessOrEqual(&c.BlobDelete.ExpireBatchSize, defaultExpireBatchSize)
+	if c.BlobDelete.ExpireBatchSize > maxExpireBatchSize {
+		c.BlobDelete.ExpireBatchSize = maxExpireBatchSize
+	}
 	c.BlobDelete.Kafka.BrokerList = c.Kafka.BrokerList
 	c.BlobDelete.Kafka.FailMsgSenderTimeoutMs = c.Kafka.FailMsgSenderTimeoutMs
 	c.BlobDelete.Kafka.TopicNormal = c.Kafka.Topics.BlobDelete
@@ -297,6 +353,20 @@ func (c *Config) fixBlobDeleteConfig() error {
 	return nil
 }
 
+func (c *Config) fixBlobReplicateConfig() {
+	c.BlobReplicate.ClusterID = c.ClusterID
+	defaulter.LessOrEqual(&c.BlobReplicate.TaskPoolSize, defaultTaskPoolSize)
+	defaulter.LessOrEqual(&c.BlobReplicate.MessagePunishThreshold, defaultMessagePunishThreshold)
+	defaulter.LessOrEqual(&c.BlobReplicate.MessagePunishTimeM, defaultMessagePunishTimeM)
+	defaulter.LessOrEqual(&c.BlobReplicate.MaxRetry, defaultReplicateMaxRetry)
+	defaulter.Equal(&c.BlobReplicate.MaxBatchSize, defaultMaxBatchSize)
+	defaulter.Equal(&c.BlobReplicate.BatchIntervalS, defaultBatchIntervalSec)
+	c.BlobReplicate.Kafka.BrokerList = c.Kafka.BrokerList
+	c.BlobReplicate.Kafka.FailMsgSenderTimeoutMs = c.Kafka.FailMsgSenderTimeoutMs
+	c.BlobReplicate.Kafka.TopicNormal = c.Kafka.Topics.BlobReplicate
+	c.BlobReplicate.Kafka.TopicFailed = c.Kafka.Topics.BlobReplicateFailed
+}
+
 func (c *Config) fixRegisterConfig() {
 	defaulter.LessOrEqual(&c.ServiceRegister.TickInterval, defaultTickInterval)
 	defaulter.LessOrEqual(&c.ServiceRegister.HeartbeatTicks, defaultHeartbeatTicks)
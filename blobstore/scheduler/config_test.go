@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/cubefs/cubefs/blobstore/common/kafka"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
 )
 
 func TestConfigCheckAndFix(t *testing.T) {
@@ -46,6 +47,15 @@ func TestConfigCheckAndFix(t *testing.T) {
 	err = cfg.fixConfig()
 	require.Error(t, err, errInvalidKafka)
 
+	cfg.Kafka.Backend = "not-exist"
+	err = cfg.fixConfig()
+	require.Error(t, err, errInvalidKafka)
+
+	cfg.Kafka.Backend = proto.MQBackendClusterMgr
+	err = cfg.fixConfig() // ok, kafka version is ignored
+	require.NoError(t, err)
+
+	cfg.Kafka.Backend = proto.MQBackendKafka
 	cfg.Kafka.Version = "0.10.0.0"
 	err = cfg.fixConfig() // ok
 	require.NoError(t, err)
@@ -57,6 +67,8 @@ func TestConfigCheckAndFix(t *testing.T) {
 	require.Equal(t, "127.0.0.1:9800", cfg.Leader())
 	require.Nil(t, cfg.Follower())
 	require.Equal(t, defaultDeleteDelayH, cfg.BlobDelete.SafeDelayTimeH)
+	require.Equal(t, []string{defaultBlobReplicateNormalTopic, defaultBlobReplicateFailedTopic}, cfg.BlobReplicate.topics())
+	require.Equal(t, defaultReplicateMaxRetry, cfg.BlobReplicate.MaxRetry)
 	require.Equal(t, sarama.V2_1_0_0, kafka.DefaultKafkaVersion)
 	cfg.Services.Members[2] = "127.0.0.1:9880"
 	require.Equal(t, "127.0.0.1:9880", cfg.Follower()[0])
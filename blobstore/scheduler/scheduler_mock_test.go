@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/cubefs/cubefs/blobstore/scheduler (interfaces: ITaskRunner,IVolumeCache,MMigrator,IVolumeInspector,IClusterTopology)
+// Source: github.com/cubefs/cubefs/blobstore/scheduler (interfaces: ITaskRunner,IVolumeCache,MMigrator,IVolumeInspector,IVolumeTranscoder,IClusterTopology,IBlobExpirer)
 
 // Package scheduler is a generated GoMock package.
 package scheduler
@@ -8,7 +8,9 @@ import (
 	context "context"
 	reflect "reflect"
 
+	clustermgr "github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	scheduler "github.com/cubefs/cubefs/blobstore/api/scheduler"
+	codemode "github.com/cubefs/cubefs/blobstore/common/codemode"
 	proto "github.com/cubefs/cubefs/blobstore/common/proto"
 	client "github.com/cubefs/cubefs/blobstore/scheduler/client"
 	gomock "github.com/golang/mock/gomock"
@@ -736,6 +738,170 @@ func (mr *MockVolumeInspectorMockRecorder) Run() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockVolumeInspector)(nil).Run))
 }
 
+// MockVolumeTranscoder is a mock of IVolumeTranscoder interface.
+type MockVolumeTranscoder struct {
+	ctrl     *gomock.Controller
+	recorder *MockVolumeTranscoderMockRecorder
+}
+
+// MockVolumeTranscoderMockRecorder is the mock recorder for MockVolumeTranscoder.
+type MockVolumeTranscoderMockRecorder struct {
+	mock *MockVolumeTranscoder
+}
+
+// NewMockVolumeTranscoder creates a new mock instance.
+func NewMockVolumeTranscoder(ctrl *gomock.Controller) *MockVolumeTranscoder {
+	mock := &MockVolumeTranscoder{ctrl: ctrl}
+	mock.recorder = &MockVolumeTranscoderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVolumeTranscoder) EXPECT() *MockVolumeTranscoderMockRecorder {
+	return m.recorder
+}
+
+// AcquireTranscode mocks base method.
+func (m *MockVolumeTranscoder) AcquireTranscode(arg0 context.Context) (*proto.TranscodeTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireTranscode", arg0)
+	ret0, _ := ret[0].(*proto.TranscodeTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireTranscode indicates an expected call of AcquireTranscode.
+func (mr *MockVolumeTranscoderMockRecorder) AcquireTranscode(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireTranscode", reflect.TypeOf((*MockVolumeTranscoder)(nil).AcquireTranscode), arg0)
+}
+
+// AddTranscodeTask mocks base method.
+func (m *MockVolumeTranscoder) AddTranscodeTask(arg0 context.Context, arg1 proto.Vid, arg2 codemode.CodeMode) (*proto.TranscodeTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTranscodeTask", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*proto.TranscodeTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTranscodeTask indicates an expected call of AddTranscodeTask.
+func (mr *MockVolumeTranscoderMockRecorder) AddTranscodeTask(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTranscodeTask", reflect.TypeOf((*MockVolumeTranscoder)(nil).AddTranscodeTask), arg0, arg1, arg2)
+}
+
+// Close mocks base method.
+func (m *MockVolumeTranscoder) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockVolumeTranscoderMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockVolumeTranscoder)(nil).Close))
+}
+
+// CompleteTranscode mocks base method.
+func (m *MockVolumeTranscoder) CompleteTranscode(arg0 context.Context, arg1 *proto.TranscodeRet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTranscode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteTranscode indicates an expected call of CompleteTranscode.
+func (mr *MockVolumeTranscoderMockRecorder) CompleteTranscode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTranscode", reflect.TypeOf((*MockVolumeTranscoder)(nil).CompleteTranscode), arg0, arg1)
+}
+
+// Done mocks base method.
+func (m *MockVolumeTranscoder) Done() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Done")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Done indicates an expected call of Done.
+func (mr *MockVolumeTranscoderMockRecorder) Done() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockVolumeTranscoder)(nil).Done))
+}
+
+// Enabled mocks base method.
+func (m *MockVolumeTranscoder) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockVolumeTranscoderMockRecorder) Enabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockVolumeTranscoder)(nil).Enabled))
+}
+
+// GetTaskStats mocks base method.
+func (m *MockVolumeTranscoder) GetTaskStats() (int, [20]int, [20]int) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskStats")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].([20]int)
+	ret2, _ := ret[2].([20]int)
+	return ret0, ret1, ret2
+}
+
+// GetTaskStats indicates an expected call of GetTaskStats.
+func (mr *MockVolumeTranscoderMockRecorder) GetTaskStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskStats", reflect.TypeOf((*MockVolumeTranscoder)(nil).GetTaskStats))
+}
+
+// Load mocks base method.
+func (m *MockVolumeTranscoder) Load() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockVolumeTranscoderMockRecorder) Load() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockVolumeTranscoder)(nil).Load))
+}
+
+// QueryTranscodeTask mocks base method.
+func (m *MockVolumeTranscoder) QueryTranscodeTask(arg0 context.Context, arg1 string) (*proto.TranscodeTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryTranscodeTask", arg0, arg1)
+	ret0, _ := ret[0].(*proto.TranscodeTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryTranscodeTask indicates an expected call of QueryTranscodeTask.
+func (mr *MockVolumeTranscoderMockRecorder) QueryTranscodeTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryTranscodeTask", reflect.TypeOf((*MockVolumeTranscoder)(nil).QueryTranscodeTask), arg0, arg1)
+}
+
+// Run mocks base method.
+func (m *MockVolumeTranscoder) Run() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run")
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockVolumeTranscoderMockRecorder) Run() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockVolumeTranscoder)(nil).Run))
+}
+
 // MockClusterTopology is a mock of IClusterTopology interface.
 type MockClusterTopology struct {
 	ctrl     *gomock.Controller
@@ -813,6 +979,21 @@ func (mr *MockClusterTopologyMockRecorder) GetIDCs() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIDCs", reflect.TypeOf((*MockClusterTopology)(nil).GetIDCs))
 }
 
+// GetTranscodeMapping mocks base method.
+func (m *MockClusterTopology) GetTranscodeMapping(arg0 proto.Vid) (*clustermgr.TranscodeMapping, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTranscodeMapping", arg0)
+	ret0, _ := ret[0].(*clustermgr.TranscodeMapping)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetTranscodeMapping indicates an expected call of GetTranscodeMapping.
+func (mr *MockClusterTopologyMockRecorder) GetTranscodeMapping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTranscodeMapping", reflect.TypeOf((*MockClusterTopology)(nil).GetTranscodeMapping), arg0)
+}
+
 // GetVolume mocks base method.
 func (m *MockClusterTopology) GetVolume(arg0 proto.Vid) (*client.VolumeInfoSimple, error) {
 	m.ctrl.T.Helper()
@@ -884,3 +1065,50 @@ func (mr *MockClusterTopologyMockRecorder) UpdateVolume(arg0 interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVolume", reflect.TypeOf((*MockClusterTopology)(nil).UpdateVolume), arg0)
 }
+
+// MockBlobExpirer is a mock of IBlobExpirer interface.
+type MockBlobExpirer struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobExpirerMockRecorder
+}
+
+// MockBlobExpirerMockRecorder is the mock recorder for MockBlobExpirer.
+type MockBlobExpirerMockRecorder struct {
+	mock *MockBlobExpirer
+}
+
+// NewMockBlobExpirer creates a new mock instance.
+func NewMockBlobExpirer(ctrl *gomock.Controller) *MockBlobExpirer {
+	mock := &MockBlobExpirer{ctrl: ctrl}
+	mock.recorder = &MockBlobExpirerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobExpirer) EXPECT() *MockBlobExpirerMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockBlobExpirer) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockBlobExpirerMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockBlobExpirer)(nil).Close))
+}
+
+// Run mocks base method.
+func (m *MockBlobExpirer) Run() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run")
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockBlobExpirerMockRecorder) Run() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockBlobExpirer)(nil).Run))
+}
@@ -43,12 +43,16 @@ type Service struct {
 	diskRepairMgr IDisKMigrator
 	manualMigMgr  IManualMigrator
 	inspectMgr    IVolumeInspector
+	transcodeMgr  IVolumeTranscoder
+	expireMgr     IBlobExpirer
 
-	shardRepairMgr  ITaskRunner
-	blobDeleteMgr   ITaskRunner
-	clusterTopology IClusterTopology
-	volumeUpdater   client.IVolumeUpdater
-	kafkaMonitors   []*base.KafkaTopicMonitor
+	shardRepairMgr ITaskRunner
+	blobDeleteMgr  ITaskRunner
+	// nil if blob replicate is not configured
+	blobReplicateMgr ITaskRunner
+	clusterTopology  IClusterTopology
+	volumeUpdater    client.IVolumeUpdater
+	kafkaMonitors    []*base.KafkaTopicMonitor
 
 	clusterMgrCli client.ClusterMgrAPI
 }
@@ -194,6 +198,74 @@ func (svr *Service) HTTPInspectComplete(c *rpc.Context) {
 	c.Respond()
 }
 
+// HTTPTranscodeTaskAdd adds volume transcode task
+func (svr *Service) HTTPTranscodeTaskAdd(c *rpc.Context) {
+	args := new(api.AddTranscodeArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if !args.Valid() {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	task, err := svr.transcodeMgr.AddTranscodeTask(c.Request.Context(), args.Vid, args.CodeMode)
+	if err != nil {
+		c.RespondError(rpc.Error2HTTPError(err))
+		return
+	}
+	c.RespondJSON(task)
+}
+
+// HTTPTranscodeAcquire acquire transcode task
+func (svr *Service) HTTPTranscodeAcquire(c *rpc.Context) {
+	ctx := c.Request.Context()
+
+	task, _ := svr.transcodeMgr.AcquireTranscode(ctx)
+	if task != nil {
+		c.RespondJSON(task)
+		return
+	}
+	c.RespondError(errcode.ErrNothingTodo)
+}
+
+// HTTPTranscodeComplete complete transcode task
+func (svr *Service) HTTPTranscodeComplete(c *rpc.Context) {
+	args := new(proto.TranscodeRet)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if !client.ValidMigrateTask(proto.TaskTypeTranscode, args.TaskID) {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	ctx := c.Request.Context()
+	c.RespondError(rpc.Error2HTTPError(svr.transcodeMgr.CompleteTranscode(ctx, args)))
+}
+
+// HTTPTranscodeTaskDetail returns transcode task detail.
+func (svr *Service) HTTPTranscodeTaskDetail(c *rpc.Context) {
+	args := new(api.TranscodeTaskDetailArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if !client.ValidMigrateTask(proto.TaskTypeTranscode, args.ID) {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	task, err := svr.transcodeMgr.QueryTranscodeTask(c.Request.Context(), args.ID)
+	if err != nil {
+		c.RespondError(rpc.NewError(http.StatusNotFound, "NotFound", err))
+		return
+	}
+	c.RespondJSON(task)
+}
+
 // HTTPTaskRenewal renewal task
 func (svr *Service) HTTPTaskRenewal(c *rpc.Context) {
 	args := new(api.TaskRenewalArgs)
@@ -321,6 +393,19 @@ func (svr *Service) HTTPStats(c *rpc.Context) {
 		ErrStats:      repairErrStats,
 	}
 
+	// stats blob replicate tasks
+	if svr.blobReplicateMgr != nil {
+		replicateSuccessCounter, replicateFailedCounter := svr.blobReplicateMgr.GetTaskStats()
+		replicateErrStats, replicateTotalErrCnt := svr.blobReplicateMgr.GetErrorStats()
+		taskStats.BlobReplicate = &api.RunnerStat{
+			Enable:        svr.blobReplicateMgr.Enabled(),
+			SuccessPerMin: fmt.Sprint(replicateSuccessCounter),
+			FailedPerMin:  fmt.Sprint(replicateFailedCounter),
+			TotalErrCnt:   replicateTotalErrCnt,
+			ErrStats:      replicateErrStats,
+		}
+	}
+
 	if !svr.leader {
 		c.RespondJSON(taskStats)
 		return
@@ -365,6 +450,15 @@ func (svr *Service) HTTPStats(c *rpc.Context) {
 		TimeOutPerMin:  fmt.Sprint(timeout),
 	}
 
+	// stats transcode tasks
+	transcoding, finished, timeout := svr.transcodeMgr.GetTaskStats()
+	taskStats.Transcode = &api.TranscodeTasksStat{
+		Enable:         svr.transcodeMgr.Enabled(),
+		RunningCnt:     transcoding,
+		FinishedPerMin: fmt.Sprint(finished),
+		TimeOutPerMin:  fmt.Sprint(timeout),
+	}
+
 	c.RespondJSON(taskStats)
 }
 
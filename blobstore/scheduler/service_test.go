@@ -25,6 +25,7 @@ import (
 
 	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	"github.com/cubefs/cubefs/blobstore/common/counter"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
@@ -55,6 +56,7 @@ func newMockService(t *testing.T) *Service {
 	manualMgr := NewMockMigrater(ctr)
 	balanceMgr := NewMockMigrater(ctr)
 	inspectorMgr := NewMockVolumeInspector(ctr)
+	transcodeMgr := NewMockVolumeTranscoder(ctr)
 	clusterTopology := NewMockClusterTopology(ctr)
 
 	// return disk repair task
@@ -118,6 +120,14 @@ func newMockService(t *testing.T) *Service {
 	// complete inspect task
 	inspectorMgr.EXPECT().CompleteInspect(any, any).Return()
 
+	// transcode task
+	transcodeMgr.EXPECT().AddTranscodeTask(any, any, any).Return(&proto.TranscodeTask{}, nil)
+	transcodeMgr.EXPECT().AcquireTranscode(any).Return(&proto.TranscodeTask{}, nil)
+	transcodeMgr.EXPECT().AcquireTranscode(any).Return(nil, errMock)
+	transcodeMgr.EXPECT().CompleteTranscode(any, any).Return(nil)
+	transcodeMgr.EXPECT().QueryTranscodeTask(any, any).Return(&proto.TranscodeTask{}, nil)
+	transcodeMgr.EXPECT().QueryTranscodeTask(any, any).Return(nil, errMock)
+
 	// volume update
 	clusterTopology.EXPECT().UpdateVolume(any).Return(&client.VolumeInfoSimple{}, nil)
 	clusterTopology.EXPECT().UpdateVolume(any).Return(nil, errMock)
@@ -140,6 +150,8 @@ func newMockService(t *testing.T) *Service {
 	manualMgr.EXPECT().Stats().Return(api.MigrateTasksStat{})
 	inspectorMgr.EXPECT().GetTaskStats().Return([counter.SLOT]int{}, [counter.SLOT]int{})
 	inspectorMgr.EXPECT().Enabled().Return(true)
+	transcodeMgr.EXPECT().GetTaskStats().Return(0, [counter.SLOT]int{}, [counter.SLOT]int{})
+	transcodeMgr.EXPECT().Enabled().Return(true)
 
 	// task detail
 	balanceMgr.EXPECT().QueryTask(any, any).Return(nil, nil)
@@ -166,6 +178,7 @@ func newMockService(t *testing.T) *Service {
 		manualMigMgr:  manualMgr,
 		diskRepairMgr: diskRepairMgr,
 		inspectMgr:    inspectorMgr,
+		transcodeMgr:  transcodeMgr,
 
 		shardRepairMgr:  shardRepairMgr,
 		blobDeleteMgr:   blobDeleteMgr,
@@ -248,6 +261,25 @@ func TestServiceAPI(t *testing.T) {
 	// complete inspect task
 	require.NoError(t, cli.CompleteInspectTask(ctx, &proto.VolumeInspectRet{}))
 
+	// transcode task
+	_, err = cli.AddTranscodeTask(ctx, &api.AddTranscodeArgs{})
+	require.Equal(t, 400, rpc.DetectStatusCode(err))
+	_, err = cli.AddTranscodeTask(ctx, &api.AddTranscodeArgs{Vid: volumeID, CodeMode: codemode.EC6P6})
+	require.NoError(t, err)
+	_, err = cli.AcquireTranscodeTask(ctx)
+	require.NoError(t, err)
+	_, err = cli.AcquireTranscodeTask(ctx)
+	require.Error(t, err)
+	err = cli.CompleteTranscodeTask(ctx, &proto.TranscodeRet{TaskID: "task_id"})
+	require.Equal(t, 400, rpc.DetectStatusCode(err))
+	require.NoError(t, cli.CompleteTranscodeTask(ctx, &proto.TranscodeRet{TaskID: client.GenTranscodeTaskID(volumeID)}))
+	_, err = cli.DetailTranscodeTask(ctx, &api.TranscodeTaskDetailArgs{ID: "task_id"})
+	require.Error(t, err)
+	_, err = cli.DetailTranscodeTask(ctx, &api.TranscodeTaskDetailArgs{ID: client.GenTranscodeTaskID(volumeID)})
+	require.NoError(t, err)
+	_, err = cli.DetailTranscodeTask(ctx, &api.TranscodeTaskDetailArgs{ID: client.GenTranscodeTaskID(volumeID)})
+	require.Equal(t, 404, rpc.DetectStatusCode(err))
+
 	// volume update
 	require.NoError(t, cli.UpdateVolume(ctx, schedulerServer.URL, proto.Vid(1)))
 	require.Error(t, cli.UpdateVolume(ctx, schedulerServer.URL, proto.Vid(1)))
@@ -19,12 +19,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/singleflight"
 
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	"github.com/cubefs/cubefs/blobstore/common/counter"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/kafka"
@@ -56,6 +58,12 @@ const (
 	ShardRepair = "shard_repair"
 )
 
+// shard repair plan
+const (
+	repairPlanLocal  = "local"
+	repairPlanGlobal = "global"
+)
+
 // ErrBlobnodeServiceUnavailable worker service unavailable
 var ErrBlobnodeServiceUnavailable = errors.New("blobnode service unavailable")
 
@@ -99,7 +107,7 @@ type ShardRepairMgr struct {
 	taskSwitch      *taskswitch.TaskSwitch
 	clusterTopology IClusterTopology
 
-	kafkaConsumerClient base.KafkaConsumer
+	kafkaConsumerClient base.MsgQueue
 	consumers           []base.GroupConsumer
 	failMsgSender       base.IProducer
 	punishTime          time.Duration
@@ -112,6 +120,7 @@ type ShardRepairMgr struct {
 	repairFailedCounter     prometheus.Counter
 	repairFailedCounterMin  *counter.Counter
 	errStatsDistribution    *base.ErrorStats
+	repairPlanCounter       *prometheus.CounterVec
 
 	group             singleflight.Group
 	orphanShardLogger recordlog.Encoder
@@ -126,7 +135,7 @@ func NewShardRepairMgr(
 	switchMgr *taskswitch.SwitchMgr,
 	blobnodeCli client.BlobnodeAPI,
 	clusterMgrCli client.ClusterMgrAPI,
-	kafkaClient base.KafkaConsumer,
+	kafkaClient base.MsgQueue,
 ) (*ShardRepairMgr, error) {
 	taskSwitch, err := switchMgr.AddSwitch(proto.TaskTypeShardRepair.String())
 	if err != nil {
@@ -136,7 +145,7 @@ func NewShardRepairMgr(
 	workerSelector := selector.MakeSelector(60*1000, func() (hosts []string, err error) {
 		return clusterMgrCli.GetService(context.Background(), proto.ServiceNameBlobNode, cfg.ClusterID)
 	})
-	failMsgSender, err := base.NewMsgSender(cfg.failedProducerConfig())
+	failMsgSender, err := kafkaClient.NewMsgSender(cfg.failedProducerConfig())
 	if err != nil {
 		return nil, err
 	}
@@ -164,6 +173,7 @@ func NewShardRepairMgr(
 		errStatsDistribution:    base.NewErrorStats(),
 		repairSuccessCounterMin: &counter.Counter{},
 		repairFailedCounterMin:  &counter.Counter{},
+		repairPlanCounter:       base.NewShardRepairPlanCounter(cfg.ClusterID),
 
 		cfg:    cfg,
 		Closer: closer.New(),
@@ -338,6 +348,15 @@ func (mgr *ShardRepairMgr) consume(ctx context.Context, repairMsg *proto.ShardRe
 			return shardRepairRet{status: ShardRepairStatusUndo}
 		}
 	}
+	// the blobs of a transcoded volume are in the destination volume, and the
+	// source volume is still read while copying, so repair it later
+	if mapping, ok := mgr.clusterTopology.GetTranscodeMapping(repairMsg.Vid); ok {
+		if !mapping.Done() {
+			return shardRepairRet{status: ShardRepairStatusFailed, err: errVolumeTranscoding}
+		}
+		span.Warnf("volume is transcoded and skip repair: vid[%d], bid[%d]", repairMsg.Vid, repairMsg.Bid)
+		return shardRepairRet{status: ShardRepairStatusDone}
+	}
 	jobKey := fmt.Sprintf("%d:%d:%s", repairMsg.Vid, repairMsg.Bid, repairMsg.BadIdx)
 	_, err, _ := mgr.group.Do(jobKey, func() (ret interface{}, e error) {
 		e = mgr.repairWithCheckVolConsistency(ctx, repairMsg)
@@ -389,11 +408,11 @@ func (mgr *ShardRepairMgr) repairShard(ctx context.Context, volInfo *client.Volu
 
 	span.Infof("repair shard: msg[%+v], vol info[%+v]", repairMsg, volInfo)
 
-	hosts := mgr.blobnodeSelector.GetRandomN(1)
-	if len(hosts) == 0 {
+	workerHost, plan := mgr.selectRepairWorker(volInfo, repairMsg.BadIdx)
+	if workerHost == "" {
 		return volInfo, ErrBlobnodeServiceUnavailable
 	}
-	workerHost := hosts[0]
+	span.Debugf("repair shard on worker[%s] with %s stripe", workerHost, plan)
 
 	task := proto.ShardRepairTask{
 		Bid:      repairMsg.Bid,
@@ -405,6 +424,7 @@ func (mgr *ShardRepairMgr) repairShard(ctx context.Context, volInfo *client.Volu
 
 	err := mgr.blobnodeCli.RepairShard(ctx, workerHost, task)
 	if err == nil {
+		mgr.repairPlanCounter.WithLabelValues(plan).Inc()
 		return volInfo, nil
 	}
 
@@ -415,6 +435,69 @@ func (mgr *ShardRepairMgr) repairShard(ctx context.Context, volInfo *client.Volu
 	return volInfo, err
 }
 
+// selectRepairWorker returns the worker to repair bad shards among the workers
+// of blobnodeSelector. If bad shards can be reconstructed by the local stripe,
+// an eligible host in that stripe is preferred, so the worker reads shards in
+// the same AZ only.
+func (mgr *ShardRepairMgr) selectRepairWorker(volInfo *client.VolumeInfoSimple, badIdxs []uint8) (string, string) {
+	// all the workers in random order
+	workers := mgr.blobnodeSelector.GetRandomN(math.MaxInt32)
+	if len(workers) == 0 {
+		return "", repairPlanGlobal
+	}
+
+	if stripe, ok := localRepairStripe(volInfo.CodeMode, badIdxs); ok {
+		bads := make(map[int]struct{}, len(badIdxs))
+		for _, idx := range badIdxs {
+			bads[int(idx)] = struct{}{}
+		}
+		hosts := make(map[string]struct{}, len(stripe))
+		for _, idx := range stripe {
+			if _, ok := bads[idx]; ok || idx >= len(volInfo.VunitLocations) {
+				continue
+			}
+			location := volInfo.VunitLocations[idx]
+			if location.Host == "" || mgr.clusterTopology.IsBrokenDisk(location.DiskID) {
+				continue
+			}
+			hosts[location.Host] = struct{}{}
+		}
+		for _, worker := range workers {
+			if _, ok := hosts[worker]; ok {
+				return worker, repairPlanLocal
+			}
+		}
+	}
+	return workers[0], repairPlanGlobal
+}
+
+// localRepairStripe returns indexes of the local stripe if all bad shards
+// are in the same AZ and can be reconstructed by its local parity.
+func localRepairStripe(mode codemode.CodeMode, badIdxs []uint8) ([]int, bool) {
+	if !mode.IsValid() {
+		return nil, false
+	}
+	tactic := mode.Tactic()
+	if tactic.L == 0 || tactic.AZCount == 0 || len(badIdxs) == 0 {
+		return nil, false
+	}
+	if len(badIdxs) > tactic.L/tactic.AZCount {
+		return nil, false
+	}
+
+	az := tactic.AZIndex(int(badIdxs[0]))
+	if az < 0 {
+		return nil, false
+	}
+	for _, idx := range badIdxs[1:] {
+		if tactic.AZIndex(int(idx)) != az {
+			return nil, false
+		}
+	}
+	stripe, _, _ := tactic.LocalStripeInAZ(az)
+	return stripe, true
+}
+
 func (mgr *ShardRepairMgr) saveOrphanShard(ctx context.Context, repairMsg *proto.ShardRepairMsg) {
 	span := trace.SpanFromContextSafe(ctx)
 
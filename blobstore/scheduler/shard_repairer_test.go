@@ -17,7 +17,9 @@ package scheduler
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"testing"
 	"time"
@@ -26,6 +28,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	"github.com/cubefs/cubefs/blobstore/common/counter"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
@@ -43,6 +46,8 @@ func newShardRepairMgr(t *testing.T) *ShardRepairMgr {
 	ctr := gomock.NewController(t)
 
 	clusterTopology := NewMockClusterTopology(ctr)
+
+	clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 	clusterTopology.EXPECT().GetVolume(any).AnyTimes().Return(&client.VolumeInfoSimple{}, nil)
 	clusterTopology.EXPECT().UpdateVolume(any).AnyTimes().Return(&client.VolumeInfoSimple{}, nil)
 
@@ -54,7 +59,7 @@ func newShardRepairMgr(t *testing.T) *ShardRepairMgr {
 
 	sender := NewMockProducer(ctr)
 	sender.EXPECT().SendMessage(any).AnyTimes().Return(nil)
-	kafkaClient := NewMockKafkaConsumer(ctr)
+	kafkaClient := NewMockMsgQueue(ctr)
 	consumer := NewMockGroupConsumer(ctr)
 	consumer.EXPECT().Stop().AnyTimes().Return()
 	kafkaClient.EXPECT().StartKafkaConsumer(any, any).AnyTimes().Return(consumer, nil)
@@ -82,6 +87,7 @@ func newShardRepairMgr(t *testing.T) *ShardRepairMgr {
 		errStatsDistribution:    base.NewErrorStats(),
 		repairSuccessCounterMin: &counter.Counter{},
 		repairFailedCounterMin:  &counter.Counter{},
+		repairPlanCounter:       base.NewShardRepairPlanCounter(1),
 		cfg: &ShardRepairConfig{
 			MessagePunishThreshold: defaultMessagePunishThreshold,
 		},
@@ -182,6 +188,24 @@ func TestConsumerShardRepairMsg(t *testing.T) {
 		ret := mgr.consume(ctx, msg, consuming)
 		require.Equal(t, ShardRepairStatusUndo, ret.status)
 	}
+	{
+		// volume is transcoding and repair failed, then skipped once transcoded
+		oldTopology := mgr.clusterTopology
+		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).Return(&cmapi.TranscodeMapping{
+			Vid: 1, DstVid: 2, Status: cmapi.TranscodeMappingStatusCopying,
+		}, true)
+		clusterTopology.EXPECT().GetTranscodeMapping(any).Return(&cmapi.TranscodeMapping{
+			Vid: 1, DstVid: 2, Status: cmapi.TranscodeMappingStatusDone,
+		}, true)
+		mgr.clusterTopology = clusterTopology
+		ret := mgr.consume(ctx, msg, commonCloser)
+		require.Equal(t, ShardRepairStatusFailed, ret.status)
+		require.ErrorIs(t, ret.err, errVolumeTranscoding)
+		ret = mgr.consume(ctx, msg, commonCloser)
+		require.Equal(t, ShardRepairStatusDone, ret.status)
+		mgr.clusterTopology = oldTopology
+	}
 	{
 		// message punished and consume success
 		msg := &proto.ShardRepairMsg{Bid: 1, Vid: 1, ReqId: "123456", BadIdx: []uint8{0, 1}, Retry: defaultMessagePunishThreshold}
@@ -229,6 +253,8 @@ func TestNewShardRepairMgr(t *testing.T) {
 	}
 
 	clusterTopology := NewMockClusterTopology(ctr)
+
+	clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 	clusterTopology.EXPECT().GetVolume(any).AnyTimes().Return(&client.VolumeInfoSimple{}, nil)
 	clusterTopology.EXPECT().UpdateVolume(any).AnyTimes().Return(&client.VolumeInfoSimple{}, nil)
 
@@ -244,10 +270,11 @@ func TestNewShardRepairMgr(t *testing.T) {
 	clusterCli.EXPECT().GetConsumeOffset(any, any, any).AnyTimes().Return(int64(0), nil)
 	clusterCli.EXPECT().SetConsumeOffset(any, any, any, any).AnyTimes().Return(nil)
 
-	kafkaClient := NewMockKafkaConsumer(ctr)
+	kafkaClient := NewMockMsgQueue(ctr)
 	consumer := NewMockGroupConsumer(ctr)
 	consumer.EXPECT().Stop().AnyTimes().Return()
 	kafkaClient.EXPECT().StartKafkaConsumer(any, any).AnyTimes().Return(consumer, nil)
+	kafkaClient.EXPECT().NewMsgSender(any).Return(NewMockProducer(ctr), nil)
 
 	mgr, err := NewShardRepairMgr(cfg, clusterTopology, switchMgr, blobnode, clusterCli, kafkaClient)
 	require.NoError(t, err)
@@ -298,6 +325,8 @@ func TestTryRepair(t *testing.T) {
 		mgr.blobnodeCli = blobnode
 
 		clusterTopology := NewMockClusterTopology(ctr)
+
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().UpdateVolume(any).Return(volume, ErrFrequentlyUpdate)
 		mgr.clusterTopology = clusterTopology
 
@@ -313,6 +342,8 @@ func TestTryRepair(t *testing.T) {
 		mgr.blobnodeCli = blobnode
 
 		clusterTopology := NewMockClusterTopology(ctr)
+
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		clusterTopology.EXPECT().UpdateVolume(any).Return(volume, nil)
 		mgr.clusterTopology = clusterTopology
 
@@ -329,6 +360,8 @@ func TestTryRepair(t *testing.T) {
 		mgr.blobnodeCli = blobnode
 
 		clusterTopology := NewMockClusterTopology(ctr)
+
+		clusterTopology.EXPECT().GetTranscodeMapping(any).AnyTimes().Return(nil, false)
 		newVolume := MockGenVolInfo(proto.Vid(1), codemode.EC3P3, proto.VolumeStatusActive)
 		newVolume.VunitLocations[5].Vuid += 1
 		clusterTopology.EXPECT().UpdateVolume(any).Return(newVolume, nil)
@@ -340,3 +373,84 @@ func TestTryRepair(t *testing.T) {
 		require.True(t, doneVolume.EqualWith(newVolume))
 	}
 }
+
+func TestShardRepairLocalStripe(t *testing.T) {
+	for _, cs := range []struct {
+		mode   codemode.CodeMode
+		bads   []uint8
+		stripe []int
+		local  bool
+	}{
+		{mode: codemode.EC3P3, bads: []uint8{0}},
+		{mode: codemode.EC6P10L2, bads: []uint8{}},
+		{mode: codemode.EC6P10L2, bads: []uint8{0}, stripe: []int{0, 1, 2, 6, 7, 8, 9, 10, 16}, local: true},
+		{mode: codemode.EC6P10L2, bads: []uint8{17}, stripe: []int{3, 4, 5, 11, 12, 13, 14, 15, 17}, local: true},
+		{mode: codemode.EC6P10L2, bads: []uint8{0, 1}},
+		{mode: codemode.EC6P10L2, bads: []uint8{0, 3}},
+		{mode: codemode.EC6P10L2, bads: []uint8{18}},
+		{mode: codemode.EC6P3L3, bads: []uint8{2}, stripe: []int{2, 3, 7, 10}, local: true},
+	} {
+		stripe, local := localRepairStripe(cs.mode, cs.bads)
+		require.Equal(t, cs.local, local, "%s %v", cs.mode, cs.bads)
+		if cs.local {
+			require.ElementsMatch(t, cs.stripe, stripe, "%s %v", cs.mode, cs.bads)
+		}
+	}
+}
+
+func TestShardRepairSelectWorker(t *testing.T) {
+	ctr := gomock.NewController(t)
+	mgr := newShardRepairMgr(t)
+	volume := MockGenVolInfo(proto.Vid(1), codemode.EC6P10L2, proto.VolumeStatusActive)
+	for idx := range volume.VunitLocations {
+		volume.VunitLocations[idx].Host = fmt.Sprintf("http://127.0.0.%d:8889", idx)
+	}
+	brokenDisk := volume.VunitLocations[1].DiskID
+	clusterTopology := NewMockClusterTopology(ctr)
+	clusterTopology.EXPECT().IsBrokenDisk(any).AnyTimes().DoAndReturn(func(diskID proto.DiskID) bool {
+		return diskID == brokenDisk
+	})
+	mgr.clusterTopology = clusterTopology
+
+	// only the workers of the selector are eligible, hosts 6 and 7 of the local stripe are not
+	workers := []string{"http://127.0.0.1:9600"}
+	localHosts := make(map[string]struct{})
+	for _, idx := range []int{2, 8, 9, 10, 16} {
+		localHosts[volume.VunitLocations[idx].Host] = struct{}{}
+		workers = append(workers, volume.VunitLocations[idx].Host)
+	}
+	workers = append(workers, volume.VunitLocations[1].Host, volume.VunitLocations[3].Host)
+	selector := mocks.NewMockSelector(ctr)
+	selector.EXPECT().GetRandomN(any).AnyTimes().DoAndReturn(func(int) []string {
+		shuffled := append([]string{}, workers...)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		return shuffled
+	})
+	mgr.blobnodeSelector = selector
+	for i := 0; i < 20; i++ {
+		host, plan := mgr.selectRepairWorker(volume, []uint8{0})
+		require.Equal(t, repairPlanLocal, plan)
+		require.Contains(t, localHosts, host)
+	}
+
+	host, plan := mgr.selectRepairWorker(volume, []uint8{0, 3})
+	require.Equal(t, repairPlanGlobal, plan)
+	require.Contains(t, workers, host)
+
+	// no eligible worker in the local stripe
+	selector = mocks.NewMockSelector(ctr)
+	selector.EXPECT().GetRandomN(any).Return([]string{"http://127.0.0.1:9600"})
+	mgr.blobnodeSelector = selector
+	host, plan = mgr.selectRepairWorker(volume, []uint8{0})
+	require.Equal(t, repairPlanGlobal, plan)
+	require.Equal(t, "http://127.0.0.1:9600", host)
+
+	selector = mocks.NewMockSelector(ctr)
+	selector.EXPECT().GetRandomN(any).Times(2).Return(nil)
+	mgr.blobnodeSelector = selector
+	host, _ = mgr.selectRepairWorker(volume, []uint8{0, 1})
+	require.Equal(t, "", host)
+	_, err := mgr.repairShard(context.Background(), MockGenVolInfo(proto.Vid(1), codemode.EC3P3, proto.VolumeStatusActive),
+		&proto.ShardRepairMsg{Bid: 1, Vid: 1, BadIdx: []uint8{0}})
+	require.ErrorIs(t, err, ErrBlobnodeServiceUnavailable)
+}
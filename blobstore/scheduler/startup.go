@@ -23,6 +23,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/cubefs/cubefs/blobstore/api/access"
 	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
 	"github.com/cubefs/cubefs/blobstore/cmd"
@@ -48,6 +49,7 @@ var (
 	errInvalidLeader    = errors.New("invalid leader")
 	errInvalidNodeID    = errors.New("invalid node_id")
 	errInvalidKafka     = errors.New("invalid kafka")
+	errIllegalMQBackend = errors.New("illegal mq backend")
 )
 
 var (
@@ -120,18 +122,30 @@ func NewService(conf *Config) (svr *Service, err error) {
 	topologyMgr := NewClusterTopologyMgr(clusterMgrCli, topoConf)
 
 	kafkaClient := base.NewKafkaConsumer(conf.Kafka.BrokerList)
+	if conf.Kafka.Backend == proto.MQBackendClusterMgr {
+		kafkaClient = base.NewClusterMgrQueue(cmapi.New(&conf.ClusterMgr))
+	}
 	shardRepairMgr, err := NewShardRepairMgr(&conf.ShardRepair, topologyMgr, switchMgr, blobnodeCli, clusterMgrCli, kafkaClient)
 	if err != nil {
 		log.Errorf("new shard repair mgr: cfg[%+v], err[%w]", conf.ShardRepair, err)
 		return nil, err
 	}
 
-	deleteMgr, err := NewBlobDeleteMgr(&conf.BlobDelete, topologyMgr, switchMgr, blobnodeCli, kafkaClient)
+	deleteMgr, err := NewBlobDeleteMgr(&conf.BlobDelete, topologyMgr, switchMgr, blobnodeCli, clusterMgrCli, kafkaClient)
 	if err != nil {
 		log.Errorf("new blob delete mgr: cfg[%+v], err[%w]", conf.BlobDelete, err)
 		return nil, err
 	}
 
+	if conf.BlobReplicate.Enabled() {
+		replicateMgr, err := newBlobReplicateMgr(&conf.BlobReplicate, switchMgr, kafkaClient)
+		if err != nil {
+			log.Errorf("new blob replicate mgr: cfg[%+v], err[%w]", conf.BlobReplicate, err)
+			return nil, err
+		}
+		svr.blobReplicateMgr = replicateMgr
+	}
+
 	svr.shardRepairMgr = shardRepairMgr
 	svr.blobDeleteMgr = deleteMgr
 	svr.clusterTopology = topologyMgr
@@ -150,10 +164,12 @@ func NewService(conf *Config) (svr *Service, err error) {
 		return
 	}
 
-	err = svr.NewKafkaMonitor(conf.ClusterID)
-	if err != nil {
-		log.Errorf("run kafka monitor failed: err[%w]", err)
-		return nil, err
+	if conf.Kafka.Backend == proto.MQBackendKafka {
+		err = svr.NewKafkaMonitor(conf.ClusterID)
+		if err != nil {
+			log.Errorf("run kafka monitor failed: err[%w]", err)
+			return nil, err
+		}
 	}
 
 	// all migrate manager
@@ -191,11 +207,25 @@ func NewService(conf *Config) (svr *Service, err error) {
 	}
 	inspectMgr := NewVolumeInspectMgr(clusterMgrCli, mqProxy, inspectorTaskSwitch, &conf.VolumeInspect)
 
+	transcodeTaskSwitch, err := switchMgr.AddSwitch(proto.TaskTypeTranscode.String())
+	if err != nil {
+		return nil, err
+	}
+	transcodeMgr := NewVolumeTranscodeMgr(clusterMgrCli, transcodeTaskSwitch, &conf.VolumeTranscode)
+
+	expireMgr, err := NewBlobExpireMgr(&conf.BlobDelete, clusterMgrCli, kafkaClient)
+	if err != nil {
+		log.Errorf("new blob expire mgr: cfg[%+v], err[%w]", conf.BlobDelete, err)
+		return nil, err
+	}
+
 	svr.balanceMgr = balanceMgr
 	svr.diskDropMgr = diskDropMgr
 	svr.manualMigMgr = manualMigMgr
 	svr.diskRepairMgr = diskRepairMgr
 	svr.inspectMgr = inspectMgr
+	svr.transcodeMgr = transcodeMgr
+	svr.expireMgr = expireMgr
 
 	err = svr.waitAndLoad()
 	if err != nil {
@@ -228,6 +258,9 @@ func (svr *Service) load() (err error) {
 	if err = svr.manualMigMgr.Load(); err != nil {
 		return
 	}
+	if err = svr.transcodeMgr.Load(); err != nil {
+		return
+	}
 
 	return
 }
@@ -252,6 +285,8 @@ func (svr *Service) Run() {
 	svr.diskDropMgr.Run()
 	svr.manualMigMgr.Run()
 	svr.inspectMgr.Run()
+	svr.transcodeMgr.Run()
+	svr.expireMgr.Run()
 }
 
 // RunTask run shard repair and blob delete tasks
@@ -262,9 +297,27 @@ func (svr *Service) RunTask() error {
 	}
 	svr.blobDeleteMgr.Run()
 	svr.shardRepairMgr.Run()
+	if svr.blobReplicateMgr != nil {
+		svr.blobReplicateMgr.Run()
+	}
 	return nil
 }
 
+func newBlobReplicateMgr(cfg *BlobReplicateConfig, switchMgr *taskswitch.SwitchMgr,
+	kafkaClient base.MsgQueue,
+) (*BlobReplicateMgr, error) {
+	// keep log level of scheduler
+	sourceAccess, err := access.New(access.Config{PriorityAddrs: cfg.SourceAccessHosts, LogLevel: log.GetOutputLevel()})
+	if err != nil {
+		return nil, err
+	}
+	replicaAccess, err := access.New(access.Config{PriorityAddrs: cfg.ReplicaAccessHosts, LogLevel: log.GetOutputLevel()})
+	if err != nil {
+		return nil, err
+	}
+	return NewBlobReplicateMgr(cfg, switchMgr, sourceAccess, replicaAccess, cmapi.New(&cfg.ReplicaClusterMgr), kafkaClient)
+}
+
 func (svr *Service) NewKafkaMonitor(clusterID proto.ClusterID) error {
 	// blob delete
 	brokerList := conf.Kafka.BrokerList
@@ -272,6 +325,13 @@ func (svr *Service) NewKafkaMonitor(clusterID proto.ClusterID) error {
 		return err
 	}
 
+	// blob replicate
+	if conf.BlobReplicate.Enabled() {
+		if err := svr.newMonitor(proto.TaskTypeBlobReplicate, clusterID, conf.BlobReplicate.topics(), brokerList); err != nil {
+			return err
+		}
+	}
+
 	// shard repair
 	return svr.newMonitor(proto.TaskTypeShardRepair, clusterID, conf.ShardRepair.topics(), brokerList)
 }
@@ -342,6 +402,9 @@ func (svr *Service) Close() {
 	log.Infof("stop scheduler service")
 	svr.blobDeleteMgr.Close()
 	svr.shardRepairMgr.Close()
+	if svr.blobReplicateMgr != nil {
+		svr.blobReplicateMgr.Close()
+	}
 	if !svr.leader {
 		return
 	}
@@ -351,6 +414,8 @@ func (svr *Service) Close() {
 	svr.diskDropMgr.Close()
 	svr.manualMigMgr.Close()
 	svr.inspectMgr.Close()
+	svr.transcodeMgr.Close()
+	svr.expireMgr.Close()
 }
 
 // NewHandler returns app server handler
@@ -358,6 +423,7 @@ func NewHandler(service *Service) *rpc.Router {
 	rpc.RegisterArgsParser(&api.AcquireArgs{}, "json")
 	rpc.RegisterArgsParser(&api.DiskMigratingStatsArgs{}, "json")
 	rpc.RegisterArgsParser(&api.MigrateTaskDetailArgs{}, "json")
+	rpc.RegisterArgsParser(&api.TranscodeTaskDetailArgs{}, "json")
 
 	// rpc http svr interface
 	rpc.GET(api.PathTaskAcquire, service.HTTPTaskAcquire, rpc.OptArgsQuery())
@@ -369,6 +435,11 @@ func NewHandler(service *Service) *rpc.Router {
 	rpc.GET(api.PathInspectAcquire, service.HTTPInspectAcquire)
 	rpc.POST(api.PathInspectComplete, service.HTTPInspectComplete, rpc.OptArgsBody())
 
+	rpc.POST(api.PathTranscodeTaskAdd, service.HTTPTranscodeTaskAdd, rpc.OptArgsBody())
+	rpc.GET(api.PathTranscodeAcquire, service.HTTPTranscodeAcquire)
+	rpc.POST(api.PathTranscodeComplete, service.HTTPTranscodeComplete, rpc.OptArgsBody())
+	rpc.GET(api.PathTranscodeTaskDetail, service.HTTPTranscodeTaskDetail, rpc.OptArgsQuery())
+
 	rpc.POST(api.PathTaskReport, service.HTTPTaskReport, rpc.OptArgsBody())
 	rpc.POST(api.PathTaskRenewal, service.HTTPTaskRenewal, rpc.OptArgsBody())
 
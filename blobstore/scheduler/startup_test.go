@@ -101,6 +101,8 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 	manualMgr := NewMockMigrater(ctr)
 	balanceMgr := NewMockMigrater(ctr)
 	inspecterMgr := NewMockVolumeInspector(ctr)
+	transcodeMgr := NewMockVolumeTranscoder(ctr)
+	expireMgr := NewMockBlobExpirer(ctr)
 	clusterTopology := NewMockClusterTopology(ctr)
 	volumeUpdater := NewMockVolumeUpdater(ctr)
 
@@ -109,12 +111,16 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 	diskDropMgr.EXPECT().Close().AnyTimes().Return()
 	manualMgr.EXPECT().Close().AnyTimes().Return()
 	inspecterMgr.EXPECT().Close().AnyTimes().Return()
+	transcodeMgr.EXPECT().Close().AnyTimes().Return()
+	expireMgr.EXPECT().Close().AnyTimes().Return()
 
 	balanceMgr.EXPECT().Run().AnyTimes().Return()
 	diskDropMgr.EXPECT().Run().AnyTimes().Return()
 	diskRepairMgr.EXPECT().Run().AnyTimes().Return()
 	inspecterMgr.EXPECT().Run().AnyTimes().Return()
 	manualMgr.EXPECT().Run().AnyTimes().Return()
+	transcodeMgr.EXPECT().Run().AnyTimes().Return()
+	expireMgr.EXPECT().Run().AnyTimes().Return()
 
 	clusterTopology.EXPECT().LoadVolumes().AnyTimes().Return(nil)
 	shardRepairMgr.EXPECT().Run().AnyTimes().Return()
@@ -126,6 +132,7 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 	diskRepairMgr.EXPECT().Load().AnyTimes().Return(nil)
 	diskDropMgr.EXPECT().Load().AnyTimes().Return(nil)
 	manualMgr.EXPECT().Load().AnyTimes().Return(nil)
+	transcodeMgr.EXPECT().Load().AnyTimes().Return(nil)
 
 	blobDeleteMgr.EXPECT().GetErrorStats().AnyTimes().Return([]string{}, uint64(0))
 	blobDeleteMgr.EXPECT().GetTaskStats().AnyTimes().Return([counter.SLOT]int{}, [counter.SLOT]int{})
@@ -144,6 +151,8 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 	manualMgr.EXPECT().Stats().AnyTimes().Return(api.MigrateTasksStat{})
 	inspecterMgr.EXPECT().GetTaskStats().AnyTimes().Return([counter.SLOT]int{}, [counter.SLOT]int{})
 	inspecterMgr.EXPECT().Enabled().AnyTimes().Return(true)
+	transcodeMgr.EXPECT().GetTaskStats().AnyTimes().Return(0, [counter.SLOT]int{}, [counter.SLOT]int{})
+	transcodeMgr.EXPECT().Enabled().AnyTimes().Return(true)
 
 	volumeUpdater.EXPECT().UpdateFollowerVolumeCache(any, any, any).AnyTimes().Return(nil)
 	volumeUpdater.EXPECT().UpdateLeaderVolumeCache(any, any).AnyTimes().Return(nil)
@@ -164,6 +173,8 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 		manualMigMgr:    manualMgr,
 		diskRepairMgr:   diskRepairMgr,
 		inspectMgr:      inspecterMgr,
+		transcodeMgr:    transcodeMgr,
+		expireMgr:       expireMgr,
 		shardRepairMgr:  shardRepairMgr,
 		blobDeleteMgr:   blobDeleteMgr,
 		clusterTopology: clusterTopology,
@@ -0,0 +1,470 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/counter"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/taskswitch"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
+)
+
+// IVolumeTranscoder define the interface of volume transcode manager
+type IVolumeTranscoder interface {
+	AddTranscodeTask(ctx context.Context, vid proto.Vid, mode codemode.CodeMode) (*proto.TranscodeTask, error)
+	AcquireTranscode(ctx context.Context) (*proto.TranscodeTask, error)
+	CompleteTranscode(ctx context.Context, ret *proto.TranscodeRet) error
+	QueryTranscodeTask(ctx context.Context, taskID string) (*proto.TranscodeTask, error)
+	GetTaskStats() (running int, finished, timeout [counter.SLOT]int)
+	Enabled() bool
+	Load() error
+	Run()
+	closer.Closer
+}
+
+var (
+	errTranscodeTaskNotFound = errors.New("transcode task not found")
+	errTranscodeNotRunning   = errors.New("transcode task is not running in the state")
+	errNoDstVolume           = errors.New("no destination volume available")
+	errVolumeTranscoding     = rpc.NewError(http.StatusConflict, "volume_transcoding", errors.New("volume is transcoding"))
+	errTranscodeIllegalVol   = rpc.NewError(http.StatusBadRequest, "illegal_volume", errors.New("volume can not be transcoded"))
+)
+
+// manager of volumes transcode, the tasks are persisted in clustermgr
+// step1.lock the source volume, select the destination volume and map them in copying
+// step2.worker copies the blobs of the source volume into the destination volume
+// step3.map them in done, the reads of the source volume are redirected to the destination volume
+// step4.worker deletes the blobs in the source volume, which keeps locked forever
+// the scheduler waits SyncDelayS after the mapping is changed, so that all of the
+// access and scheduler services have seen the new mapping before the next step.
+
+// VolumeTranscodeMgrCfg transcode task manager config
+type VolumeTranscodeMgrCfg struct {
+	// max count of the volumes transcoding at the same time
+	TaskLimit      int `json:"task_limit"`
+	CheckIntervalS int `json:"check_interval_s"`
+	ListVolStep    int `json:"list_vol_step"`
+	// worker timeout of a task stage, the stage can be acquired again after timeout
+	TimeoutS int `json:"timeout_s"`
+	// delay after the mapping changed, it should be longer than the mapping
+	// update interval of access and scheduler
+	SyncDelayS int `json:"sync_delay_s"`
+	// percent of the source volume used size reserved in the destination volume
+	FreeReserveRate int `json:"free_reserve_rate"`
+}
+
+type transcodeTaskInfo struct {
+	t           *proto.TranscodeTask
+	acquireTime *time.Time
+}
+
+func (t *transcodeTaskInfo) acquirable(now time.Time, timeout, syncDelay time.Duration) bool {
+	if t.t.State != proto.TranscodeStatePrepared && t.t.State != proto.TranscodeStateCopied {
+		return false
+	}
+	if now.Before(time.Unix(t.t.StateTime, 0).Add(syncDelay)) {
+		return false
+	}
+	return t.acquireTime == nil || t.timeout(now, timeout)
+}
+
+func (t *transcodeTaskInfo) timeout(now time.Time, timeout time.Duration) bool {
+	return t.acquireTime != nil && now.After(t.acquireTime.Add(timeout))
+}
+
+func (t *transcodeTaskInfo) running() bool {
+	return t.t.State == proto.TranscodeStatePrepared || t.t.State == proto.TranscodeStateCopied
+}
+
+// VolumeTranscodeMgr transcode task manager
+type VolumeTranscodeMgr struct {
+	closer.Closer
+	tasks  map[string]*transcodeTaskInfo
+	tasksL sync.Mutex
+
+	taskSwitch    taskswitch.ISwitcher
+	clusterMgrCli client.ClusterMgrAPI
+
+	completeTaskCounter counter.Counter
+	timeoutCounter      counter.Counter
+
+	cfg *VolumeTranscodeMgrCfg
+}
+
+// NewVolumeTranscodeMgr returns transcode task manager
+func NewVolumeTranscodeMgr(clusterMgrCli client.ClusterMgrAPI, taskSwitch taskswitch.ISwitcher,
+	cfg *VolumeTranscodeMgrCfg) *VolumeTranscodeMgr {
+	return &VolumeTranscodeMgr{
+		Closer:        closer.New(),
+		tasks:         make(map[string]*transcodeTaskInfo),
+		taskSwitch:    taskSwitch,
+		clusterMgrCli: clusterMgrCli,
+		cfg:           cfg,
+	}
+}
+
+// Enabled returns true if task switch status
+func (mgr *VolumeTranscodeMgr) Enabled() bool {
+	return mgr.taskSwitch.Enabled()
+}
+
+// Load loads the transcode tasks from clustermgr
+func (mgr *VolumeTranscodeMgr) Load() error {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "transcoder.load")
+	tasks, err := mgr.clusterMgrCli.ListAllTranscodeTasks(ctx)
+	if err != nil {
+		span.Errorf("list transcode tasks failed: err[%+v]", err)
+		return err
+	}
+
+	mgr.tasksL.Lock()
+	defer mgr.tasksL.Unlock()
+	for _, t := range tasks {
+		span.Infof("load transcode task: task_id[%s], state[%d]", t.TaskID, t.State)
+		mgr.tasks[t.TaskID] = &transcodeTaskInfo{t: t}
+	}
+	return nil
+}
+
+// Run run transcode task manager
+func (mgr *VolumeTranscodeMgr) Run() {
+	go mgr.run()
+}
+
+func (mgr *VolumeTranscodeMgr) run() {
+	t := time.NewTicker(time.Duration(mgr.cfg.CheckIntervalS) * time.Second)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			mgr.taskSwitch.WaitEnable()
+			mgr.checkTasks()
+		case <-mgr.Closer.Done():
+			return
+		}
+	}
+}
+
+func (mgr *VolumeTranscodeMgr) checkTasks() {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "transcoder.check")
+	defer span.Finish()
+
+	var inited []*proto.TranscodeTask
+	running := 0
+	now := time.Now()
+	mgr.tasksL.Lock()
+	for taskID, task := range mgr.tasks {
+		switch {
+		case task.running():
+			running++
+			if task.timeout(now, mgr.timeoutDuration()) {
+				span.Warnf("transcode task timeout: task_id[%s], state[%d]", taskID, task.t.State)
+				mgr.timeoutCounter.Add()
+				task.acquireTime = nil
+			}
+		case task.t.State == proto.TranscodeStateInited:
+			t := *task.t
+			inited = append(inited, &t)
+		case task.t.State == proto.TranscodeStateFinished:
+			if err := mgr.clusterMgrCli.DeleteTranscodeTask(ctx, taskID); err != nil {
+				span.Warnf("delete finished transcode task failed: task_id[%s], err[%+v]", taskID, err)
+				continue
+			}
+			delete(mgr.tasks, taskID)
+		}
+	}
+	mgr.tasksL.Unlock()
+
+	// prepare the earliest added tasks first
+	sort.Slice(inited, func(i, j int) bool {
+		return inited[i].StateTime < inited[j].StateTime
+	})
+	for _, task := range inited {
+		if running >= mgr.cfg.TaskLimit {
+			return
+		}
+		if err := mgr.prepareTask(ctx, task); err != nil {
+			span.Warnf("prepare transcode task failed and retry later: task_id[%s], err[%+v]", task.TaskID, err)
+			continue
+		}
+		running++
+	}
+}
+
+// prepareTask locks the source volume and maps it to the selected destination volume
+func (mgr *VolumeTranscodeMgr) prepareTask(ctx context.Context, task *proto.TranscodeTask) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	if err := mgr.clusterMgrCli.LockVolume(ctx, task.SrcVid); err != nil {
+		return err
+	}
+	src, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, task.SrcVid)
+	if err != nil {
+		return err
+	}
+	dst, err := mgr.selectDstVolume(ctx, task, src)
+	if err != nil {
+		return err
+	}
+
+	mapping := &cmapi.TranscodeMapping{
+		Vid:         src.Vid,
+		CodeMode:    src.CodeMode,
+		DstVid:      dst.Vid,
+		DstCodeMode: dst.CodeMode,
+		Status:      cmapi.TranscodeMappingStatusCopying,
+	}
+	// clustermgr rejects the mapping if the source volume has been unlocked by others
+	if err = mgr.clusterMgrCli.SetTranscodeMapping(ctx, mapping); err != nil {
+		return err
+	}
+
+	task.Sources = src.VunitLocations
+	task.DstVid = dst.Vid
+	task.Destinations = dst.VunitLocations
+	task.State = proto.TranscodeStatePrepared
+	task.StateTime = time.Now().Unix()
+	if err = mgr.clusterMgrCli.UpdateTranscodeTask(ctx, task); err != nil {
+		return err
+	}
+
+	mgr.tasksL.Lock()
+	mgr.tasks[task.TaskID] = &transcodeTaskInfo{t: task}
+	mgr.tasksL.Unlock()
+	span.Infof("transcode task prepared: task_id[%s], src vid[%d], dst vid[%d]", task.TaskID, task.SrcVid, task.DstVid)
+	return nil
+}
+
+// selectDstVolume returns a writable volume of the destination code mode which is
+// large enough to hold the blobs of the source volume.
+func (mgr *VolumeTranscodeMgr) selectDstVolume(ctx context.Context, task *proto.TranscodeTask,
+	src *client.VolumeInfoSimple) (*client.VolumeInfoSimple, error) {
+	used := mgr.usedVolumes()
+	need := src.Used + src.Used*uint64(mgr.cfg.FreeReserveRate)/100
+
+	marker := defaultMarker
+	for {
+		vols, next, err := mgr.clusterMgrCli.ListVolume(ctx, marker, mgr.cfg.ListVolStep)
+		if err != nil {
+			return nil, err
+		}
+		for _, vol := range vols {
+			if vol.CodeMode != task.DstCodeMode || !(vol.IsIdle() || vol.IsActive()) {
+				continue
+			}
+			if _, ok := used[vol.Vid]; ok || vol.Free < need {
+				continue
+			}
+			return vol, nil
+		}
+		if len(vols) == 0 || next == defaultMarker {
+			return nil, errNoDstVolume
+		}
+		marker = next
+	}
+}
+
+func (mgr *VolumeTranscodeMgr) usedVolumes() map[proto.Vid]struct{} {
+	mgr.tasksL.Lock()
+	defer mgr.tasksL.Unlock()
+
+	used := make(map[proto.Vid]struct{}, 2*len(mgr.tasks))
+	for _, task := range mgr.tasks {
+		used[task.t.SrcVid] = struct{}{}
+		if task.t.DstVid != proto.InvalidVid {
+			used[task.t.DstVid] = struct{}{}
+		}
+	}
+	return used
+}
+
+// AddTranscodeTask adds transcode task of the volume
+func (mgr *VolumeTranscodeMgr) AddTranscodeTask(ctx context.Context, vid proto.Vid, mode codemode.CodeMode) (*proto.TranscodeTask, error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	if _, ok := mgr.usedVolumes()[vid]; ok {
+		return nil, errVolumeTranscoding
+	}
+	vol, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, vid)
+	if err != nil {
+		return nil, err
+	}
+	// a locked volume may be transcoded or migrating
+	if vol.CodeMode == mode || !(vol.IsIdle() || vol.IsActive()) {
+		span.Warnf("volume can not be transcoded: vid[%d], code mode[%d], status[%d]", vid, vol.CodeMode, vol.Status)
+		return nil, errTranscodeIllegalVol
+	}
+
+	task := &proto.TranscodeTask{
+		TaskID:      client.GenTranscodeTaskID(vid),
+		State:       proto.TranscodeStateInited,
+		SrcVid:      vid,
+		SrcCodeMode: vol.CodeMode,
+		DstCodeMode: mode,
+		StateTime:   time.Now().Unix(),
+	}
+	if err = mgr.clusterMgrCli.AddTranscodeTask(ctx, task); err != nil {
+		span.Errorf("add transcode task failed: task_id[%s], err[%+v]", task.TaskID, err)
+		return nil, err
+	}
+
+	mgr.tasksL.Lock()
+	mgr.tasks[task.TaskID] = &transcodeTaskInfo{t: task}
+	mgr.tasksL.Unlock()
+	span.Infof("add transcode task success: task_id[%s], vid[%d], code mode[%s]", task.TaskID, vid, mode.String())
+	return task, nil
+}
+
+// AcquireTranscode acquire transcode task
+func (mgr *VolumeTranscodeMgr) AcquireTranscode(ctx context.Context) (*proto.TranscodeTask, error) {
+	if !mgr.taskSwitch.Enabled() {
+		return nil, proto.ErrTaskPaused
+	}
+
+	mgr.tasksL.Lock()
+	defer mgr.tasksL.Unlock()
+
+	now := time.Now()
+	for _, task := range mgr.tasks {
+		if task.acquirable(now, mgr.timeoutDuration(), mgr.syncDelay()) {
+			task.acquireTime = &now
+			t := *task.t
+			return &t, nil
+		}
+	}
+	return nil, proto.ErrTaskEmpty
+}
+
+// CompleteTranscode completes the acquired stage of the transcode task
+func (mgr *VolumeTranscodeMgr) CompleteTranscode(ctx context.Context, ret *proto.TranscodeRet) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	mgr.tasksL.Lock()
+	defer mgr.tasksL.Unlock()
+
+	task, ok := mgr.tasks[ret.TaskID]
+	if !ok {
+		span.Warnf("transcode task not found: task_id[%s]", ret.TaskID)
+		return errTranscodeTaskNotFound
+	}
+	if task.acquireTime == nil || task.t.State != ret.State {
+		span.Warnf("transcode task is not running in the state: task_id[%s], state[%d], ret state[%d]",
+			ret.TaskID, task.t.State, ret.State)
+		return errTranscodeNotRunning
+	}
+
+	t := *task.t
+	if err := ret.Err(); err != nil {
+		span.Warnf("transcode task failed and redo: task_id[%s], state[%d], err[%+v]", ret.TaskID, ret.State, err)
+		t.WorkerRedoCnt++
+		mgr.refreshLocations(ctx, &t)
+	} else {
+		switch ret.State {
+		case proto.TranscodeStatePrepared:
+			mapping := &cmapi.TranscodeMapping{
+				Vid:         t.SrcVid,
+				CodeMode:    t.SrcCodeMode,
+				DstVid:      t.DstVid,
+				DstCodeMode: t.DstCodeMode,
+				Status:      cmapi.TranscodeMappingStatusDone,
+			}
+			if err := mgr.clusterMgrCli.SetTranscodeMapping(ctx, mapping); err != nil {
+				span.Errorf("set transcode mapping done failed: task_id[%s], err[%+v]", ret.TaskID, err)
+				return err
+			}
+			t.BlobCnt, t.DataSize = ret.BlobCnt, ret.DataSize
+			t.State = proto.TranscodeStateCopied
+		case proto.TranscodeStateCopied:
+			t.State = proto.TranscodeStateFinished
+		}
+		t.WorkerRedoCnt = 0
+		t.StateTime = time.Now().Unix()
+	}
+	if err := mgr.clusterMgrCli.UpdateTranscodeTask(ctx, &t); err != nil {
+		span.Errorf("update transcode task failed: task_id[%s], err[%+v]", ret.TaskID, err)
+		return err
+	}
+
+	task.t = &t
+	task.acquireTime = nil
+	if t.State == proto.TranscodeStateFinished {
+		mgr.completeTaskCounter.Add()
+	}
+	span.Infof("transcode task completed: task_id[%s], state[%d]", ret.TaskID, t.State)
+	return nil
+}
+
+// refreshLocations updates the volume units which may be migrated by the other tasks
+func (mgr *VolumeTranscodeMgr) refreshLocations(ctx context.Context, task *proto.TranscodeTask) {
+	span := trace.SpanFromContextSafe(ctx)
+	if src, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, task.SrcVid); err == nil {
+		task.Sources = src.VunitLocations
+	} else {
+		span.Warnf("get source volume failed: vid[%d], err[%+v]", task.SrcVid, err)
+	}
+	if dst, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, task.DstVid); err == nil {
+		task.Destinations = dst.VunitLocations
+	} else {
+		span.Warnf("get destination volume failed: vid[%d], err[%+v]", task.DstVid, err)
+	}
+}
+
+// QueryTranscodeTask returns the transcode task
+func (mgr *VolumeTranscodeMgr) QueryTranscodeTask(ctx context.Context, taskID string) (*proto.TranscodeTask, error) {
+	mgr.tasksL.Lock()
+	defer mgr.tasksL.Unlock()
+
+	task, ok := mgr.tasks[taskID]
+	if !ok {
+		return nil, errTranscodeTaskNotFound
+	}
+	t := *task.t
+	return &t, nil
+}
+
+// GetTaskStats return task stats
+func (mgr *VolumeTranscodeMgr) GetTaskStats() (running int, finished, timeout [counter.SLOT]int) {
+	mgr.tasksL.Lock()
+	for _, task := range mgr.tasks {
+		if task.running() {
+			running++
+		}
+	}
+	mgr.tasksL.Unlock()
+	finished = mgr.completeTaskCounter.Show()
+	timeout = mgr.timeoutCounter.Show()
+	return
+}
+
+func (mgr *VolumeTranscodeMgr) timeoutDuration() time.Duration {
+	return time.Duration(mgr.cfg.TimeoutS) * time.Second
+}
+
+func (mgr *VolumeTranscodeMgr) syncDelay() time.Duration {
+	return time.Duration(mgr.cfg.SyncDelayS) * time.Second
+}
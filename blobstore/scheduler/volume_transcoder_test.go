@@ -0,0 +1,279 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/counter"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func newTranscoder(t *testing.T) *VolumeTranscodeMgr {
+	ctr := gomock.NewController(t)
+	clusterMgr := NewMockClusterMgrAPI(ctr)
+	taskSwitch := mocks.NewMockSwitcher(ctr)
+	conf := &VolumeTranscodeMgrCfg{
+		TaskLimit:       1,
+		CheckIntervalS:  1,
+		ListVolStep:     2,
+		TimeoutS:        1,
+		FreeReserveRate: 10,
+	}
+	return NewVolumeTranscodeMgr(clusterMgr, taskSwitch, conf)
+}
+
+func mockTranscodeVolume(vid proto.Vid, mode codemode.CodeMode, status proto.VolumeStatus) *client.VolumeInfoSimple {
+	vol := &client.VolumeInfoSimple{Vid: vid, CodeMode: mode, Status: status, Free: 1000, Used: 100}
+	for i := 0; i < mode.GetShardNum(); i++ {
+		vuid, _ := proto.NewVuid(vid, uint8(i), 1)
+		vol.VunitLocations = append(vol.VunitLocations, proto.VunitLocation{Vuid: vuid, DiskID: proto.DiskID(i + 1)})
+	}
+	return vol
+}
+
+func TestTranscodeAddTask(t *testing.T) {
+	ctx := context.Background()
+	mgr := newTranscoder(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(nil, errMock)
+	_, err := mgr.AddTranscodeTask(ctx, 1, codemode.EC6P6)
+	require.ErrorIs(t, err, errMock)
+
+	// same code mode or locked volume
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(mockTranscodeVolume(1, codemode.EC6P6, proto.VolumeStatusIdle), nil)
+	_, err = mgr.AddTranscodeTask(ctx, 1, codemode.EC6P6)
+	require.ErrorIs(t, err, errTranscodeIllegalVol)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(mockTranscodeVolume(1, codemode.EC3P3, proto.VolumeStatusLock), nil)
+	_, err = mgr.AddTranscodeTask(ctx, 1, codemode.EC6P6)
+	require.ErrorIs(t, err, errTranscodeIllegalVol)
+
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(mockTranscodeVolume(1, codemode.EC3P3, proto.VolumeStatusIdle), nil)
+	cmCli.EXPECT().AddTranscodeTask(any, any).Return(errMock)
+	_, err = mgr.AddTranscodeTask(ctx, 1, codemode.EC6P6)
+	require.ErrorIs(t, err, errMock)
+
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(mockTranscodeVolume(1, codemode.EC3P3, proto.VolumeStatusIdle), nil)
+	cmCli.EXPECT().AddTranscodeTask(any, any).Return(nil)
+	task, err := mgr.AddTranscodeTask(ctx, 1, codemode.EC6P6)
+	require.NoError(t, err)
+	require.Equal(t, proto.TranscodeStateInited, task.State)
+	require.Equal(t, codemode.EC3P3, task.SrcCodeMode)
+
+	// volume is transcoding
+	_, err = mgr.AddTranscodeTask(ctx, 1, codemode.EC6P6)
+	require.ErrorIs(t, err, errVolumeTranscoding)
+
+	ret, err := mgr.QueryTranscodeTask(ctx, task.TaskID)
+	require.NoError(t, err)
+	require.Equal(t, task.TaskID, ret.TaskID)
+	_, err = mgr.QueryTranscodeTask(ctx, "task_id")
+	require.ErrorIs(t, err, errTranscodeTaskNotFound)
+}
+
+func TestTranscodeLoad(t *testing.T) {
+	mgr := newTranscoder(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+
+	cmCli.EXPECT().ListAllTranscodeTasks(any).Return(nil, errMock)
+	require.ErrorIs(t, mgr.Load(), errMock)
+
+	cmCli.EXPECT().ListAllTranscodeTasks(any).Return([]*proto.TranscodeTask{
+		{TaskID: client.GenTranscodeTaskID(1), State: proto.TranscodeStatePrepared, SrcVid: 1, DstVid: 2},
+		{TaskID: client.GenTranscodeTaskID(3), State: proto.TranscodeStateInited, SrcVid: 3},
+	}, nil)
+	require.NoError(t, mgr.Load())
+	running, _, _ := mgr.GetTaskStats()
+	require.Equal(t, 1, running)
+	require.Equal(t, 3, len(mgr.usedVolumes()))
+}
+
+func TestTranscodeCheckTasks(t *testing.T) {
+	ctx := context.Background()
+	mgr := newTranscoder(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(mockTranscodeVolume(1, codemode.EC3P3, proto.VolumeStatusIdle), nil)
+	cmCli.EXPECT().AddTranscodeTask(any, any).Return(nil)
+	task, err := mgr.AddTranscodeTask(ctx, 1, codemode.EC6P6)
+	require.NoError(t, err)
+
+	// lock failed
+	cmCli.EXPECT().LockVolume(any, any).Return(errMock)
+	mgr.checkTasks()
+	require.Equal(t, proto.TranscodeStateInited, mgr.tasks[task.TaskID].t.State)
+
+	// no destination volume
+	cmCli.EXPECT().LockVolume(any, any).Return(nil)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(mockTranscodeVolume(1, codemode.EC3P3, proto.VolumeStatusLock), nil)
+	cmCli.EXPECT().ListVolume(any, any, any).Return([]*client.VolumeInfoSimple{
+		mockTranscodeVolume(1, codemode.EC3P3, proto.VolumeStatusLock),
+		mockTranscodeVolume(2, codemode.EC6P6, proto.VolumeStatusLock),
+	}, proto.Vid(3), nil)
+	cmCli.EXPECT().ListVolume(any, any, any).Return(nil, defaultMarker, nil)
+	mgr.checkTasks()
+	require.Equal(t, proto.TranscodeStateInited, mgr.tasks[task.TaskID].t.State)
+
+	cmCli.EXPECT().LockVolume(any, any).Return(nil)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(mockTranscodeVolume(1, codemode.EC3P3, proto.VolumeStatusLock), nil)
+	cmCli.EXPECT().ListVolume(any, any, any).Return([]*client.VolumeInfoSimple{
+		mockTranscodeVolume(2, codemode.EC6P6, proto.VolumeStatusActive),
+	}, defaultMarker, nil)
+	cmCli.EXPECT().SetTranscodeMapping(any, any).DoAndReturn(
+		func(_ context.Context, mapping *cmapi.TranscodeMapping) error {
+			require.Equal(t, proto.Vid(1), mapping.Vid)
+			require.Equal(t, proto.Vid(2), mapping.DstVid)
+			require.Equal(t, cmapi.TranscodeMappingStatusCopying, mapping.Status)
+			return nil
+		})
+	cmCli.EXPECT().UpdateTranscodeTask(any, any).Return(nil)
+	mgr.checkTasks()
+	prepared := mgr.tasks[task.TaskID].t
+	require.Equal(t, proto.TranscodeStatePrepared, prepared.State)
+	require.Equal(t, proto.Vid(2), prepared.DstVid)
+	require.Equal(t, codemode.EC6P6.GetShardNum(), len(prepared.Destinations))
+
+	// the limit is reached
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(mockTranscodeVolume(3, codemode.EC3P3, proto.VolumeStatusIdle), nil)
+	cmCli.EXPECT().AddTranscodeTask(any, any).Return(nil)
+	_, err = mgr.AddTranscodeTask(ctx, 3, codemode.EC6P6)
+	require.NoError(t, err)
+	mgr.checkTasks()
+
+	// delete finished task
+	mgr.tasks[task.TaskID].t.State = proto.TranscodeStateFinished
+	cmCli.EXPECT().DeleteTranscodeTask(any, any).Return(errMock)
+	cmCli.EXPECT().LockVolume(any, any).Return(errMock)
+	mgr.checkTasks()
+	require.Equal(t, 2, len(mgr.tasks))
+	cmCli.EXPECT().DeleteTranscodeTask(any, any).Return(nil)
+	cmCli.EXPECT().LockVolume(any, any).Return(errMock)
+	mgr.checkTasks()
+	require.Equal(t, 1, len(mgr.tasks))
+}
+
+func TestTranscodeAcquireAndComplete(t *testing.T) {
+	ctx := context.Background()
+	mgr := newTranscoder(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+	taskSwitch := mgr.taskSwitch.(*mocks.MockSwitcher)
+
+	taskSwitch.EXPECT().Enabled().Return(false)
+	_, err := mgr.AcquireTranscode(ctx)
+	require.ErrorIs(t, err, proto.ErrTaskPaused)
+
+	taskSwitch.EXPECT().Enabled().AnyTimes().Return(true)
+	_, err = mgr.AcquireTranscode(ctx)
+	require.ErrorIs(t, err, proto.ErrTaskEmpty)
+
+	taskID := client.GenTranscodeTaskID(1)
+	mgr.tasks[taskID] = &transcodeTaskInfo{t: &proto.TranscodeTask{
+		TaskID: taskID, State: proto.TranscodeStatePrepared,
+		SrcVid: 1, SrcCodeMode: codemode.EC3P3, DstVid: 2, DstCodeMode: codemode.EC6P6,
+		StateTime: time.Now().Unix(),
+	}}
+
+	// not acquired
+	err = mgr.CompleteTranscode(ctx, &proto.TranscodeRet{TaskID: taskID, State: proto.TranscodeStatePrepared})
+	require.ErrorIs(t, err, errTranscodeNotRunning)
+	err = mgr.CompleteTranscode(ctx, &proto.TranscodeRet{TaskID: "task_id"})
+	require.ErrorIs(t, err, errTranscodeTaskNotFound)
+
+	task, err := mgr.AcquireTranscode(ctx)
+	require.NoError(t, err)
+	require.Equal(t, taskID, task.TaskID)
+	_, err = mgr.AcquireTranscode(ctx)
+	require.ErrorIs(t, err, proto.ErrTaskEmpty)
+
+	// worker failed
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(mockTranscodeVolume(1, codemode.EC3P3, proto.VolumeStatusLock), nil)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(nil, errMock)
+	cmCli.EXPECT().UpdateTranscodeTask(any, any).Return(nil)
+	err = mgr.CompleteTranscode(ctx, &proto.TranscodeRet{TaskID: taskID, State: proto.TranscodeStatePrepared, ErrStr: "failed"})
+	require.NoError(t, err)
+	require.Equal(t, uint8(1), mgr.tasks[taskID].t.WorkerRedoCnt)
+	require.Equal(t, codemode.EC3P3.GetShardNum(), len(mgr.tasks[taskID].t.Sources))
+
+	// copied
+	_, err = mgr.AcquireTranscode(ctx)
+	require.NoError(t, err)
+	cmCli.EXPECT().SetTranscodeMapping(any, any).Return(errMock)
+	err = mgr.CompleteTranscode(ctx, &proto.TranscodeRet{TaskID: taskID, State: proto.TranscodeStatePrepared})
+	require.ErrorIs(t, err, errMock)
+	cmCli.EXPECT().SetTranscodeMapping(any, any).DoAndReturn(
+		func(_ context.Context, mapping *cmapi.TranscodeMapping) error {
+			require.True(t, mapping.Done())
+			return nil
+		})
+	cmCli.EXPECT().UpdateTranscodeTask(any, any).Return(nil)
+	err = mgr.CompleteTranscode(ctx, &proto.TranscodeRet{TaskID: taskID, State: proto.TranscodeStatePrepared, BlobCnt: 10, DataSize: 1024})
+	require.NoError(t, err)
+	require.Equal(t, proto.TranscodeStateCopied, mgr.tasks[taskID].t.State)
+	require.Equal(t, uint8(0), mgr.tasks[taskID].t.WorkerRedoCnt)
+	require.Equal(t, int64(10), mgr.tasks[taskID].t.BlobCnt)
+
+	// waiting for the mapping synchronized
+	mgr.cfg.SyncDelayS = 100
+	_, err = mgr.AcquireTranscode(ctx)
+	require.ErrorIs(t, err, proto.ErrTaskEmpty)
+	mgr.cfg.SyncDelayS = 0
+
+	// finished
+	_, err = mgr.AcquireTranscode(ctx)
+	require.NoError(t, err)
+	err = mgr.CompleteTranscode(ctx, &proto.TranscodeRet{TaskID: taskID, State: proto.TranscodeStatePrepared})
+	require.ErrorIs(t, err, errTranscodeNotRunning)
+	cmCli.EXPECT().UpdateTranscodeTask(any, any).Return(nil)
+	err = mgr.CompleteTranscode(ctx, &proto.TranscodeRet{TaskID: taskID, State: proto.TranscodeStateCopied})
+	require.NoError(t, err)
+	require.Equal(t, proto.TranscodeStateFinished, mgr.tasks[taskID].t.State)
+	_, err = mgr.AcquireTranscode(ctx)
+	require.ErrorIs(t, err, proto.ErrTaskEmpty)
+}
+
+func TestTranscodeTaskTimeout(t *testing.T) {
+	mgr := newTranscoder(t)
+	taskSwitch := mgr.taskSwitch.(*mocks.MockSwitcher)
+	taskSwitch.EXPECT().Enabled().AnyTimes().Return(true)
+
+	taskID := client.GenTranscodeTaskID(1)
+	mgr.tasks[taskID] = &transcodeTaskInfo{t: &proto.TranscodeTask{
+		TaskID: taskID, State: proto.TranscodeStateCopied, SrcVid: 1, DstVid: 2,
+	}}
+	_, err := mgr.AcquireTranscode(context.Background())
+	require.NoError(t, err)
+
+	acquired := time.Now().Add(-2 * time.Second)
+	mgr.tasks[taskID].acquireTime = &acquired
+	mgr.checkTasks()
+	require.Nil(t, mgr.tasks[taskID].acquireTime)
+	_, _, timeout := mgr.GetTaskStats()
+	require.Equal(t, 1, timeout[counter.SLOT-1])
+
+	taskSwitch.EXPECT().WaitEnable().AnyTimes().Return()
+	mgr.Run()
+	time.Sleep(1500 * time.Millisecond)
+	mgr.Close()
+}
@@ -51,6 +51,34 @@ func (mr *MockAccessAPIMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAccessAPI)(nil).Delete), arg0, arg1)
 }
 
+// DeleteObject mocks base method.
+func (m *MockAccessAPI) DeleteObject(arg0 context.Context, arg1 *access.ObjectArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteObject", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteObject indicates an expected call of DeleteObject.
+func (mr *MockAccessAPIMockRecorder) DeleteObject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteObject", reflect.TypeOf((*MockAccessAPI)(nil).DeleteObject), arg0, arg1)
+}
+
+// Expire mocks base method.
+func (m *MockAccessAPI) Expire(arg0 context.Context, arg1 *access.ExpireArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expire", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockAccessAPIMockRecorder) Expire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockAccessAPI)(nil).Expire), arg0, arg1)
+}
+
 // Get mocks base method.
 func (m *MockAccessAPI) Get(arg0 context.Context, arg1 *access.GetArgs) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +94,51 @@ func (mr *MockAccessAPIMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAccessAPI)(nil).Get), arg0, arg1)
 }
 
+// GetObject mocks base method.
+func (m *MockAccessAPI) GetObject(arg0 context.Context, arg1 *access.GetObjectArgs) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetObject", arg0, arg1)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetObject indicates an expected call of GetObject.
+func (mr *MockAccessAPIMockRecorder) GetObject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObject", reflect.TypeOf((*MockAccessAPI)(nil).GetObject), arg0, arg1)
+}
+
+// ListExpiring mocks base method.
+func (m *MockAccessAPI) ListExpiring(arg0 context.Context, arg1 *access.ListExpiringArgs) (access.ListExpiringResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiring", arg0, arg1)
+	ret0, _ := ret[0].(access.ListExpiringResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiring indicates an expected call of ListExpiring.
+func (mr *MockAccessAPIMockRecorder) ListExpiring(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiring", reflect.TypeOf((*MockAccessAPI)(nil).ListExpiring), arg0, arg1)
+}
+
+// ListObjects mocks base method.
+func (m *MockAccessAPI) ListObjects(arg0 context.Context, arg1 *access.ListObjectsArgs) (access.ListObjectsResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListObjects", arg0, arg1)
+	ret0, _ := ret[0].(access.ListObjectsResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListObjects indicates an expected call of ListObjects.
+func (mr *MockAccessAPIMockRecorder) ListObjects(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListObjects", reflect.TypeOf((*MockAccessAPI)(nil).ListObjects), arg0, arg1)
+}
+
 // Put mocks base method.
 func (m *MockAccessAPI) Put(arg0 context.Context, arg1 *access.PutArgs) (access.Location, access.HashSumMap, error) {
 	m.ctrl.T.Helper()
@@ -81,3 +154,33 @@ func (mr *MockAccessAPIMockRecorder) Put(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockAccessAPI)(nil).Put), arg0, arg1)
 }
+
+// PutObject mocks base method.
+func (m *MockAccessAPI) PutObject(arg0 context.Context, arg1 *access.PutObjectArgs) (access.ObjectInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutObject", arg0, arg1)
+	ret0, _ := ret[0].(access.ObjectInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutObject indicates an expected call of PutObject.
+func (mr *MockAccessAPIMockRecorder) PutObject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutObject", reflect.TypeOf((*MockAccessAPI)(nil).PutObject), arg0, arg1)
+}
+
+// StatObject mocks base method.
+func (m *MockAccessAPI) StatObject(arg0 context.Context, arg1 *access.ObjectArgs) (access.ObjectInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatObject", arg0, arg1)
+	ret0, _ := ret[0].(access.ObjectInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatObject indicates an expected call of StatObject.
+func (mr *MockAccessAPIMockRecorder) StatObject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatObject", reflect.TypeOf((*MockAccessAPI)(nil).StatObject), arg0, arg1)
+}
@@ -37,6 +37,21 @@ func (m *MockClientAPI) EXPECT() *MockClientAPIMockRecorder {
 	return m.recorder
 }
 
+// AcquireBlobDedup mocks base method.
+func (m *MockClientAPI) AcquireBlobDedup(arg0 context.Context, arg1 *clustermgr.AcquireBlobDedupArgs) (clustermgr.BlobDedup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireBlobDedup", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.BlobDedup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireBlobDedup indicates an expected call of AcquireBlobDedup.
+func (mr *MockClientAPIMockRecorder) AcquireBlobDedup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireBlobDedup", reflect.TypeOf((*MockClientAPI)(nil).AcquireBlobDedup), arg0, arg1)
+}
+
 // AllocBid mocks base method.
 func (m *MockClientAPI) AllocBid(arg0 context.Context, arg1 *clustermgr.BidScopeArgs) (*clustermgr.BidScopeRet, error) {
 	m.ctrl.T.Helper()
@@ -67,6 +82,91 @@ func (mr *MockClientAPIMockRecorder) AllocVolume(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllocVolume", reflect.TypeOf((*MockClientAPI)(nil).AllocVolume), arg0, arg1)
 }
 
+// CommitQueueOffset mocks base method.
+func (m *MockClientAPI) CommitQueueOffset(arg0 context.Context, arg1 *clustermgr.CommitQueueOffsetArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitQueueOffset", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitQueueOffset indicates an expected call of CommitQueueOffset.
+func (mr *MockClientAPIMockRecorder) CommitQueueOffset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitQueueOffset", reflect.TypeOf((*MockClientAPI)(nil).CommitQueueOffset), arg0, arg1)
+}
+
+// CompareAndSetObjectMeta mocks base method.
+func (m *MockClientAPI) CompareAndSetObjectMeta(arg0 context.Context, arg1 *clustermgr.CompareAndSetObjectMetaArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareAndSetObjectMeta", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompareAndSetObjectMeta indicates an expected call of CompareAndSetObjectMeta.
+func (mr *MockClientAPIMockRecorder) CompareAndSetObjectMeta(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareAndSetObjectMeta", reflect.TypeOf((*MockClientAPI)(nil).CompareAndSetObjectMeta), arg0, arg1)
+}
+
+// ConsumeMessage mocks base method.
+func (m *MockClientAPI) ConsumeMessage(arg0 context.Context, arg1 *clustermgr.ConsumeMessageArgs) (clustermgr.ConsumeMessageRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeMessage", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.ConsumeMessageRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeMessage indicates an expected call of ConsumeMessage.
+func (mr *MockClientAPIMockRecorder) ConsumeMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeMessage", reflect.TypeOf((*MockClientAPI)(nil).ConsumeMessage), arg0, arg1)
+}
+
+// DeleteBlobExpire mocks base method.
+func (m *MockClientAPI) DeleteBlobExpire(arg0 context.Context, arg1 *clustermgr.DeleteBlobExpireArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlobExpire", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBlobExpire indicates an expected call of DeleteBlobExpire.
+func (mr *MockClientAPIMockRecorder) DeleteBlobExpire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlobExpire", reflect.TypeOf((*MockClientAPI)(nil).DeleteBlobExpire), arg0, arg1)
+}
+
+// DeleteBlobReplica mocks base method.
+func (m *MockClientAPI) DeleteBlobReplica(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlobReplica", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBlobReplica indicates an expected call of DeleteBlobReplica.
+func (mr *MockClientAPIMockRecorder) DeleteBlobReplica(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlobReplica", reflect.TypeOf((*MockClientAPI)(nil).DeleteBlobReplica), arg0, arg1)
+}
+
+// DeleteObjectMeta mocks base method.
+func (m *MockClientAPI) DeleteObjectMeta(arg0 context.Context, arg1 *clustermgr.DeleteObjectMetaArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteObjectMeta", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteObjectMeta indicates an expected call of DeleteObjectMeta.
+func (mr *MockClientAPIMockRecorder) DeleteObjectMeta(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteObjectMeta", reflect.TypeOf((*MockClientAPI)(nil).DeleteObjectMeta), arg0, arg1)
+}
+
 // DiskInfo mocks base method.
 func (m *MockClientAPI) DiskInfo(arg0 context.Context, arg1 proto.DiskID) (*blobnode.DiskInfo, error) {
 	m.ctrl.T.Helper()
@@ -82,6 +182,21 @@ func (mr *MockClientAPIMockRecorder) DiskInfo(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiskInfo", reflect.TypeOf((*MockClientAPI)(nil).DiskInfo), arg0, arg1)
 }
 
+// GetBlobReplica mocks base method.
+func (m *MockClientAPI) GetBlobReplica(arg0 context.Context, arg1 string) (clustermgr.BlobReplica, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlobReplica", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.BlobReplica)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlobReplica indicates an expected call of GetBlobReplica.
+func (mr *MockClientAPIMockRecorder) GetBlobReplica(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlobReplica", reflect.TypeOf((*MockClientAPI)(nil).GetBlobReplica), arg0, arg1)
+}
+
 // GetConfig mocks base method.
 func (m *MockClientAPI) GetConfig(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -97,6 +212,21 @@ func (mr *MockClientAPIMockRecorder) GetConfig(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfig", reflect.TypeOf((*MockClientAPI)(nil).GetConfig), arg0, arg1)
 }
 
+// GetObjectMeta mocks base method.
+func (m *MockClientAPI) GetObjectMeta(arg0 context.Context, arg1 *clustermgr.GetObjectMetaArgs) (clustermgr.ObjectMeta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetObjectMeta", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.ObjectMeta)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetObjectMeta indicates an expected call of GetObjectMeta.
+func (mr *MockClientAPIMockRecorder) GetObjectMeta(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObjectMeta", reflect.TypeOf((*MockClientAPI)(nil).GetObjectMeta), arg0, arg1)
+}
+
 // GetService mocks base method.
 func (m *MockClientAPI) GetService(arg0 context.Context, arg1 clustermgr.GetServiceArgs) (clustermgr.ServiceInfo, error) {
 	m.ctrl.T.Helper()
@@ -127,6 +257,21 @@ func (mr *MockClientAPIMockRecorder) GetVolumeInfo(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVolumeInfo", reflect.TypeOf((*MockClientAPI)(nil).GetVolumeInfo), arg0, arg1)
 }
 
+// ListBlobExpire mocks base method.
+func (m *MockClientAPI) ListBlobExpire(arg0 context.Context, arg1 *clustermgr.ListBlobExpireArgs) (clustermgr.ListBlobExpireRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlobExpire", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.ListBlobExpireRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlobExpire indicates an expected call of ListBlobExpire.
+func (mr *MockClientAPIMockRecorder) ListBlobExpire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlobExpire", reflect.TypeOf((*MockClientAPI)(nil).ListBlobExpire), arg0, arg1)
+}
+
 // ListDisk mocks base method.
 func (m *MockClientAPI) ListDisk(arg0 context.Context, arg1 *clustermgr.ListOptionArgs) (clustermgr.ListDiskRet, error) {
 	m.ctrl.T.Helper()
@@ -142,6 +287,79 @@ func (mr *MockClientAPIMockRecorder) ListDisk(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDisk", reflect.TypeOf((*MockClientAPI)(nil).ListDisk), arg0, arg1)
 }
 
+// ListObjectMeta mocks base method.
+func (m *MockClientAPI) ListObjectMeta(arg0 context.Context, arg1 *clustermgr.ListObjectMetaArgs) (clustermgr.ListObjectMetaRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListObjectMeta", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.ListObjectMetaRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListObjectMeta indicates an expected call of ListObjectMeta.
+func (mr *MockClientAPIMockRecorder) ListObjectMeta(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListObjectMeta", reflect.TypeOf((*MockClientAPI)(nil).ListObjectMeta), arg0, arg1)
+}
+
+// ListTranscodeMapping mocks base method.
+func (m *MockClientAPI) ListTranscodeMapping(arg0 context.Context, arg1 *clustermgr.ListTranscodeMappingArgs) (clustermgr.ListTranscodeMappingRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTranscodeMapping", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.ListTranscodeMappingRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTranscodeMapping indicates an expected call of ListTranscodeMapping.
+func (mr *MockClientAPIMockRecorder) ListTranscodeMapping(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTranscodeMapping", reflect.TypeOf((*MockClientAPI)(nil).ListTranscodeMapping), arg0, arg1)
+}
+
+// ProduceMessage mocks base method.
+func (m *MockClientAPI) ProduceMessage(arg0 context.Context, arg1 *clustermgr.ProduceMessageArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProduceMessage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProduceMessage indicates an expected call of ProduceMessage.
+func (mr *MockClientAPIMockRecorder) ProduceMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProduceMessage", reflect.TypeOf((*MockClientAPI)(nil).ProduceMessage), arg0, arg1)
+}
+
+// RegisterBlobDedup mocks base method.
+func (m *MockClientAPI) RegisterBlobDedup(arg0 context.Context, arg1 *clustermgr.RegisterBlobDedupArgs) (clustermgr.BlobDedup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterBlobDedup", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.BlobDedup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterBlobDedup indicates an expected call of RegisterBlobDedup.
+func (mr *MockClientAPIMockRecorder) RegisterBlobDedup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterBlobDedup", reflect.TypeOf((*MockClientAPI)(nil).RegisterBlobDedup), arg0, arg1)
+}
+
+// RegisterQueueGroup mocks base method.
+func (m *MockClientAPI) RegisterQueueGroup(arg0 context.Context, arg1 *clustermgr.RegisterQueueGroupArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterQueueGroup", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterQueueGroup indicates an expected call of RegisterQueueGroup.
+func (mr *MockClientAPIMockRecorder) RegisterQueueGroup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterQueueGroup", reflect.TypeOf((*MockClientAPI)(nil).RegisterQueueGroup), arg0, arg1)
+}
+
 // RegisterService mocks base method.
 func (m *MockClientAPI) RegisterService(arg0 context.Context, arg1 clustermgr.ServiceNode, arg2, arg3, arg4 uint32) error {
 	m.ctrl.T.Helper()
@@ -156,6 +374,21 @@ func (mr *MockClientAPIMockRecorder) RegisterService(arg0, arg1, arg2, arg3, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterService", reflect.TypeOf((*MockClientAPI)(nil).RegisterService), arg0, arg1, arg2, arg3, arg4)
 }
 
+// ReleaseBlobDedup mocks base method.
+func (m *MockClientAPI) ReleaseBlobDedup(arg0 context.Context, arg1 *clustermgr.ReleaseBlobDedupArgs) (clustermgr.ReleaseBlobDedupRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseBlobDedup", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.ReleaseBlobDedupRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseBlobDedup indicates an expected call of ReleaseBlobDedup.
+func (mr *MockClientAPIMockRecorder) ReleaseBlobDedup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseBlobDedup", reflect.TypeOf((*MockClientAPI)(nil).ReleaseBlobDedup), arg0, arg1)
+}
+
 // RetainVolume mocks base method.
 func (m *MockClientAPI) RetainVolume(arg0 context.Context, arg1 *clustermgr.RetainVolumeArgs) (clustermgr.RetainVolumes, error) {
 	m.ctrl.T.Helper()
@@ -170,3 +403,60 @@ func (mr *MockClientAPIMockRecorder) RetainVolume(arg0, arg1 interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetainVolume", reflect.TypeOf((*MockClientAPI)(nil).RetainVolume), arg0, arg1)
 }
+
+// SetBlobExpire mocks base method.
+func (m *MockClientAPI) SetBlobExpire(arg0 context.Context, arg1 *clustermgr.SetBlobExpireArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBlobExpire", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBlobExpire indicates an expected call of SetBlobExpire.
+func (mr *MockClientAPIMockRecorder) SetBlobExpire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBlobExpire", reflect.TypeOf((*MockClientAPI)(nil).SetBlobExpire), arg0, arg1)
+}
+
+// SetBlobReplica mocks base method.
+func (m *MockClientAPI) SetBlobReplica(arg0 context.Context, arg1 *clustermgr.BlobReplica) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBlobReplica", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBlobReplica indicates an expected call of SetBlobReplica.
+func (mr *MockClientAPIMockRecorder) SetBlobReplica(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBlobReplica", reflect.TypeOf((*MockClientAPI)(nil).SetBlobReplica), arg0, arg1)
+}
+
+// SetObjectMeta mocks base method.
+func (m *MockClientAPI) SetObjectMeta(arg0 context.Context, arg1 *clustermgr.ObjectMeta) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetObjectMeta", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetObjectMeta indicates an expected call of SetObjectMeta.
+func (mr *MockClientAPIMockRecorder) SetObjectMeta(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetObjectMeta", reflect.TypeOf((*MockClientAPI)(nil).SetObjectMeta), arg0, arg1)
+}
+
+// StatQueue mocks base method.
+func (m *MockClientAPI) StatQueue(arg0 context.Context, arg1 *clustermgr.StatQueueArgs) (clustermgr.QueueStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatQueue", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.QueueStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatQueue indicates an expected call of StatQueue.
+func (mr *MockClientAPIMockRecorder) StatQueue(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatQueue", reflect.TypeOf((*MockClientAPI)(nil).StatQueue), arg0, arg1)
+}
@@ -109,6 +109,20 @@ func (mr *MockProxyClientMockRecorder) SendDeleteMsg(arg0, arg1, arg2 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDeleteMsg", reflect.TypeOf((*MockProxyClient)(nil).SendDeleteMsg), arg0, arg1, arg2)
 }
 
+// SendReplicateMsg mocks base method.
+func (m *MockProxyClient) SendReplicateMsg(arg0 context.Context, arg1 string, arg2 *proxy.ReplicateArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendReplicateMsg", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendReplicateMsg indicates an expected call of SendReplicateMsg.
+func (mr *MockProxyClientMockRecorder) SendReplicateMsg(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendReplicateMsg", reflect.TypeOf((*MockProxyClient)(nil).SendReplicateMsg), arg0, arg1, arg2)
+}
+
 // SendShardRepairMsg mocks base method.
 func (m *MockProxyClient) SendShardRepairMsg(arg0 context.Context, arg1 string, arg2 *proxy.ShardRepairArgs) error {
 	m.ctrl.T.Helper()
@@ -66,6 +66,21 @@ func (mr *MockISchedulerMockRecorder) AcquireTask(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireTask", reflect.TypeOf((*MockIScheduler)(nil).AcquireTask), arg0, arg1)
 }
 
+// AcquireTranscodeTask mocks base method.
+func (m *MockIScheduler) AcquireTranscodeTask(arg0 context.Context) (*proto.TranscodeTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireTranscodeTask", arg0)
+	ret0, _ := ret[0].(*proto.TranscodeTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireTranscodeTask indicates an expected call of AcquireTranscodeTask.
+func (mr *MockISchedulerMockRecorder) AcquireTranscodeTask(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireTranscodeTask", reflect.TypeOf((*MockIScheduler)(nil).AcquireTranscodeTask), arg0)
+}
+
 // AddManualMigrateTask mocks base method.
 func (m *MockIScheduler) AddManualMigrateTask(arg0 context.Context, arg1 *scheduler.AddManualMigrateArgs) error {
 	m.ctrl.T.Helper()
@@ -80,6 +95,21 @@ func (mr *MockISchedulerMockRecorder) AddManualMigrateTask(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddManualMigrateTask", reflect.TypeOf((*MockIScheduler)(nil).AddManualMigrateTask), arg0, arg1)
 }
 
+// AddTranscodeTask mocks base method.
+func (m *MockIScheduler) AddTranscodeTask(arg0 context.Context, arg1 *scheduler.AddTranscodeArgs) (*proto.TranscodeTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTranscodeTask", arg0, arg1)
+	ret0, _ := ret[0].(*proto.TranscodeTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTranscodeTask indicates an expected call of AddTranscodeTask.
+func (mr *MockISchedulerMockRecorder) AddTranscodeTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTranscodeTask", reflect.TypeOf((*MockIScheduler)(nil).AddTranscodeTask), arg0, arg1)
+}
+
 // CancelTask mocks base method.
 func (m *MockIScheduler) CancelTask(arg0 context.Context, arg1 *scheduler.OperateTaskArgs) error {
 	m.ctrl.T.Helper()
@@ -122,6 +152,20 @@ func (mr *MockISchedulerMockRecorder) CompleteTask(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTask", reflect.TypeOf((*MockIScheduler)(nil).CompleteTask), arg0, arg1)
 }
 
+// CompleteTranscodeTask mocks base method.
+func (m *MockIScheduler) CompleteTranscodeTask(arg0 context.Context, arg1 *proto.TranscodeRet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTranscodeTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteTranscodeTask indicates an expected call of CompleteTranscodeTask.
+func (mr *MockISchedulerMockRecorder) CompleteTranscodeTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTranscodeTask", reflect.TypeOf((*MockIScheduler)(nil).CompleteTranscodeTask), arg0, arg1)
+}
+
 // DetailMigrateTask mocks base method.
 func (m *MockIScheduler) DetailMigrateTask(arg0 context.Context, arg1 *scheduler.MigrateTaskDetailArgs) (scheduler.MigrateTaskDetail, error) {
 	m.ctrl.T.Helper()
@@ -137,6 +181,21 @@ func (mr *MockISchedulerMockRecorder) DetailMigrateTask(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetailMigrateTask", reflect.TypeOf((*MockIScheduler)(nil).DetailMigrateTask), arg0, arg1)
 }
 
+// DetailTranscodeTask mocks base method.
+func (m *MockIScheduler) DetailTranscodeTask(arg0 context.Context, arg1 *scheduler.TranscodeTaskDetailArgs) (*proto.TranscodeTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetailTranscodeTask", arg0, arg1)
+	ret0, _ := ret[0].(*proto.TranscodeTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DetailTranscodeTask indicates an expected call of DetailTranscodeTask.
+func (mr *MockISchedulerMockRecorder) DetailTranscodeTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetailTranscodeTask", reflect.TypeOf((*MockIScheduler)(nil).DetailTranscodeTask), arg0, arg1)
+}
+
 // DiskMigratingStats mocks base method.
 func (m *MockIScheduler) DiskMigratingStats(arg0 context.Context, arg1 *scheduler.DiskMigratingStatsArgs) (*scheduler.DiskMigratingStats, error) {
 	m.ctrl.T.Helper()
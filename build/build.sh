@@ -409,6 +409,13 @@ build_preload() {
     CGO_ENABLED=0 go build ${MODFLAGS} -gcflags=all=-trimpath=${SrcPath} -asmflags=all=-trimpath=${SrcPath} -ldflags="${LDFlags}" -o ${BuildBinPath}/cfs-preload ${SrcPath}/preload/*.go && echo "success" || echo "failed"
 }
 
+build_migrate() {
+    pushd $SrcPath >/dev/null
+    echo -n "build cfs-migrate   "
+    CGO_ENABLED=0 go build ${MODFLAGS} -gcflags=all=-trimpath=${SrcPath} -asmflags=all=-trimpath=${SrcPath} -ldflags="${LDFlags}" -o ${BuildBinPath}/cfs-migrate ${SrcPath}/migrate/*.go && echo "success" || echo "failed"
+    popd >/dev/null
+}
+
 build_bcache(){
     pushd $SrcPath >/dev/null
     echo -n "build cfs-blockcache      "
@@ -515,6 +522,9 @@ case "$cmd" in
     "preload")
         build_preload
         ;;
+    "migrate")
+        build_migrate
+        ;;
     "bcache")
         build_bcache
         ;;
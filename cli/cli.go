@@ -40,6 +40,7 @@ func setupCommands(cfg *cmd.Config) *cobra.Command {
 	mc := master.NewMasterClient(cfg.MasterAddr, false)
 	mc.SetTimeout(cfg.Timeout)
 	mc.SetClientIDKey(cfg.ClientIDKey)
+	mc.SetAdminCredential(cfg.AccessKey, cfg.SecretKey)
 	cfsRootCmd := cmd.NewRootCmd(mc)
 	//	var completionCmd = &cobra.Command{
 	//		Use:   "completion",
@@ -0,0 +1,166 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdCapacityUse               = "capacity [COMMAND]"
+	cmdCapacityShort             = "Manage the usage forecast and the capacity policies of the volumes"
+	cmdCapacityPolicySetUse      = "policy-set [VOLUME]"
+	cmdCapacityPolicySetShort    = "Set the capacity policy of a volume"
+	cmdCapacityPolicyDeleteUse   = "policy-delete [VOLUME]"
+	cmdCapacityPolicyDeleteShort = "Delete the capacity policy of a volume"
+	cmdCapacityForecastUse       = "forecast [VOLUME]"
+	cmdCapacityForecastShort     = "Show the usage forecast of a volume, or of all the volumes"
+	cmdCapacityZonesUse          = "zones [ZONE]"
+	cmdCapacityZonesShort        = "Show the usage forecast of the data nodes of the zones"
+)
+
+func newCapacityCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdCapacityUse,
+		Short: cmdCapacityShort,
+	}
+	cmd.AddCommand(
+		newCapacityPolicySetCmd(client),
+		newCapacityPolicyDeleteCmd(client),
+		newCapacityForecastCmd(client),
+		newCapacityZonesCmd(client),
+	)
+	return cmd
+}
+
+func newCapacityPolicySetCmd(client *master.MasterClient) *cobra.Command {
+	policy := &proto.CapacityPolicy{}
+	cmd := &cobra.Command{
+		Use:   cmdCapacityPolicySetUse,
+		Short: cmdCapacityPolicySetShort,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			policy.Vol = args[0]
+			if err = client.AdminAPI().SetCapacityPolicy(policy); err != nil {
+				return
+			}
+			stdout("Capacity policy of volume %v is set\n", policy.Vol)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.Flags().BoolVar(&policy.AutoExpand, "auto-expand", false, "Expand the capacity when an expand trigger is hit")
+	cmd.Flags().Uint64Var(&policy.ExpandStepGB, "expand-step", 0, "Capacity in GB added by an expansion")
+	cmd.Flags().Uint64Var(&policy.MaxCapacityGB, "max-capacity", 0, "Max capacity in GB of the auto expansion")
+	cmd.Flags().Float64Var(&policy.ExpandUsedRatio, "expand-used-ratio", 0, "Expand when the used ratio reaches it, 0 disables")
+	cmd.Flags().Float64Var(&policy.ExpandDaysToFull, "expand-days-to-full", 0, "Expand when the forecast days to full drop to it, 0 disables")
+	cmd.Flags().Float64Var(&policy.AlertUsedRatio, "alert-used-ratio", 0, "Record a warning event when the used ratio reaches it, 0 disables")
+	cmd.Flags().Float64Var(&policy.AlertDaysToFull, "alert-days-to-full", 0, "Record a warning event when the forecast days to full drop to it, 0 disables")
+	cmd.Flags().BoolVar(&policy.EarlyDpCreate, "early-dp-create", false, "Create data partitions ahead of the forecast growth of a day")
+	return cmd
+}
+
+func newCapacityPolicyDeleteCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdCapacityPolicyDeleteUse,
+		Short: cmdCapacityPolicyDeleteShort,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			if err = client.AdminAPI().DeleteCapacityPolicy(args[0]); err != nil {
+				return
+			}
+			stdout("Capacity policy of volume %v is deleted\n", args[0])
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	return cmd
+}
+
+func newCapacityForecastCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdCapacityForecastUse,
+		Short: cmdCapacityForecastShort,
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err     error
+				volName string
+				list    []*proto.VolCapacityStatus
+			)
+			defer func() {
+				errout(err)
+			}()
+			if len(args) > 0 {
+				volName = args[0]
+			}
+			if list, err = client.AdminAPI().GetVolCapacityForecast(volName); err != nil {
+				return
+			}
+			stdout("%v\n", capacityForecastTableHeader)
+			for _, st := range list {
+				stdout("%v\n", formatCapacityForecastTableRow(st.Vol, st.Forecast, formatCapacityPolicyBrief(st.Policy)))
+			}
+		},
+	}
+	return cmd
+}
+
+func newCapacityZonesCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdCapacityZonesUse,
+		Short: cmdCapacityZonesShort,
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err      error
+				zoneName string
+				list     []*proto.ZoneCapacityStatus
+			)
+			defer func() {
+				errout(err)
+			}()
+			if len(args) > 0 {
+				zoneName = args[0]
+			}
+			if list, err = client.AdminAPI().GetZoneCapacityForecast(zoneName); err != nil {
+				return
+			}
+			stdout("%v\n", capacityForecastTableHeader)
+			for _, st := range list {
+				stdout("%v\n", formatCapacityForecastTableRow(st.Zone, st.Forecast, ""))
+			}
+		},
+	}
+	return cmd
+}
@@ -40,6 +40,9 @@ func newClusterCmd(client *master.MasterClient) *cobra.Command {
 		newClusterSetThresholdCmd(client),
 		newClusterSetParasCmd(client),
 		newClusterDisableMpDecommissionCmd(client),
+		newClusterBackupCmd(client),
+		newClusterBackupListCmd(client),
+		newClusterBackupVerifyCmd(client),
 	)
 	return clusterCmd
 }
@@ -56,6 +59,9 @@ const (
 	nodeAutoRepairRateKey         = "autoRepairRate"
 	nodeMaxDpCntLimit             = "maxDpCntLimit"
 	cmdForbidMpDecommission       = "forbid meta partition decommission"
+	cmdClusterBackupShort         = "Back up the master metadata to a file in the metadataBackupDir of the leader master"
+	cmdClusterBackupListShort     = "List the metadata backups of the leader master"
+	cmdClusterBackupVerifyShort   = "Check a metadata backup and diff it against the partitions and nodes reported to the cluster"
 )
 
 func newClusterInfoCmd(client *master.MasterClient) *cobra.Command {
@@ -266,3 +272,87 @@ If 'forbid=true', MetaPartition decommission/migrate and MetaNode decommission i
 	}
 	return cmd
 }
+
+func newClusterBackupCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpBackup + " [FILE]",
+		Short: cmdClusterBackupShort,
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err  error
+				name string
+				info *proto.MetadataBackupInfo
+			)
+			defer func() {
+				errout(err)
+			}()
+			if len(args) > 0 {
+				name = args[0]
+			}
+			if info, err = client.AdminAPI().BackupMetadata(name); err != nil {
+				return
+			}
+			stdout("[Metadata Backup]\n")
+			stdout("%v", formatMetadataBackupInfo(info))
+		},
+	}
+	return cmd
+}
+
+func newClusterBackupListCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpBackupList,
+		Short: cmdClusterBackupListShort,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err   error
+				infos []*proto.MetadataBackupInfo
+			)
+			defer func() {
+				errout(err)
+			}()
+			if infos, err = client.AdminAPI().ListMetadataBackup(); err != nil {
+				return
+			}
+			stdout("%v\n", metadataBackupTableHeader)
+			for _, info := range infos {
+				stdout("%v\n", formatMetadataBackupTableRow(info))
+			}
+		},
+	}
+	return cmd
+}
+
+func newClusterBackupVerifyCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpBackupVerify + " [FILE]",
+		Short: cmdClusterBackupVerifyShort,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err    error
+				report *proto.MetadataBackupVerifyReport
+			)
+			defer func() {
+				errout(err)
+			}()
+			if report, err = client.AdminAPI().VerifyMetadataBackup(args[0]); err != nil {
+				return
+			}
+			stdout("[Metadata Backup]\n")
+			stdout("%v", formatMetadataBackupInfo(report.Backup))
+			stdout("\n[Checked]\n")
+			for _, kind := range sortedKeys(report.Checked) {
+				stdout("  %-16v: %v\n", kind, report.Checked[kind])
+			}
+			stdout("\n%v\n", metadataBackupDiffTableHeader)
+			for _, diff := range report.Diffs {
+				stdout("%v\n", fmt.Sprintf(metadataBackupDiffPattern, diff.Kind, diff.ID, diff.Diff, diff.Detail))
+			}
+			stdout("Total: %v\n", len(report.Diffs))
+		},
+	}
+	return cmd
+}
@@ -43,6 +43,9 @@ const (
 	CliOpGetDiscard           = "get-discard"
 	CliOpSetDiscard           = "set-discard"
 	CliOpForbidMpDecommission = "forbid-mp-decommission"
+	CliOpBackup               = "backup"
+	CliOpBackupList           = "backup-list"
+	CliOpBackupVerify         = "backup-verify"
 
 	// Shorthand format of operation name
 	CliOpDecommissionShortHand = "dec"
@@ -136,6 +139,7 @@ const (
 	CliFlagVersionList        = "verList"
 	CliFlagVersionDel         = "verDel"
 	CliFlagVersionSetStrategy = "verSetStrategy"
+	CliFlagVersionClone       = "verClone"
 )
 
 type MasterOp int
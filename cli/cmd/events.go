@@ -0,0 +1,66 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdEventsUse   = "events"
+	cmdEventsShort = "List the cluster events recorded by the master, newest first"
+)
+
+func newEventsCmd(client *master.MasterClient) *cobra.Command {
+	var (
+		optSince time.Duration
+		filter   proto.ClusterEventFilter
+	)
+	cmd := &cobra.Command{
+		Use:   cmdEventsUse,
+		Short: cmdEventsShort,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err    error
+				events []*proto.ClusterEvent
+			)
+			defer func() {
+				errout(err)
+			}()
+			if optSince > 0 {
+				filter.Start = time.Now().Add(-optSince).Unix()
+			}
+			if events, err = client.AdminAPI().ListEvents(&filter); err != nil {
+				return
+			}
+			stdout("%v\n", clusterEventTableHeader)
+			for _, e := range events {
+				stdout("%v\n", formatClusterEventTableRow(e))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&filter.Type, "type", "", "Event type, one of diskError, dataPartitionReadOnly, missingReplica, decommissionFailed, masterLeaderChange, capacityForecast, volAutoExpand")
+	cmd.Flags().StringVar(&filter.Severity, "severity", "", "Event severity, one of info, warning, critical")
+	cmd.Flags().StringVar(&filter.ObjectID, "object", "", "Object of the events, partition id, node address or address:disk")
+	cmd.Flags().StringVar(&filter.Vol, "vol", "", "Volume of the events")
+	cmd.Flags().DurationVar(&optSince, "since", 0, "Only list the events in this duration, e.g. 30m, 24h")
+	cmd.Flags().IntVar(&filter.Limit, "limit", proto.DefaultEventQueryLimit, "Max number of events to list")
+	return cmd
+}
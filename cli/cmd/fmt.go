@@ -17,6 +17,7 @@ package cmd
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -178,9 +179,83 @@ func formatSimpleVolView(svv *proto.SimpleVolView) string {
 		sb.WriteString(fmt.Sprintf("  CacheHighWater       : %v\n", svv.CacheHighWater))
 		sb.WriteString(fmt.Sprintf("  CacheRule            : %v\n", svv.CacheRule))
 	}
+	if svv.CapacityStatus != nil {
+		sb.WriteString(formatVolCapacityStatus(svv.CapacityStatus))
+	}
+	return sb.String()
+}
+
+func formatDaysToFull(days float64) string {
+	if days < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", days)
+}
+
+func formatGrowthPerDay(growth int64) string {
+	if growth < 0 {
+		return "-" + formatSize(uint64(-growth))
+	}
+	return formatSize(uint64(growth))
+}
+
+func formatCapacityPolicyBrief(policy *proto.CapacityPolicy) string {
+	if policy == nil {
+		return ""
+	}
+	items := make([]string, 0)
+	if policy.AutoExpand {
+		items = append(items, fmt.Sprintf("expand +%vGB up to %vGB at %v/%vd", policy.ExpandStepGB, policy.MaxCapacityGB,
+			policy.ExpandUsedRatio, policy.ExpandDaysToFull))
+	}
+	if policy.AlertUsedRatio > 0 || policy.AlertDaysToFull > 0 {
+		items = append(items, fmt.Sprintf("alert at %v/%vd", policy.AlertUsedRatio, policy.AlertDaysToFull))
+	}
+	if policy.EarlyDpCreate {
+		items = append(items, "early dp create")
+	}
+	return strings.Join(items, ", ")
+}
+
+func formatVolCapacityStatus(st *proto.VolCapacityStatus) string {
+	sb := strings.Builder{}
+	if f := st.Forecast; f != nil {
+		sb.WriteString(fmt.Sprintf("  Used ratio                      : %.2f%%\n", f.UsedRatio*100))
+		sb.WriteString(fmt.Sprintf("  Growth per day                  : %v\n", formatGrowthPerDay(f.GrowthPerDay)))
+		sb.WriteString(fmt.Sprintf("  Days to full                    : %v\n", formatDaysToFull(f.DaysToFull)))
+		sb.WriteString(fmt.Sprintf("  Forecast samples                : %v in %v\n", f.Samples, time.Duration(f.WindowSec)*time.Second))
+	}
+	if st.Policy != nil {
+		sb.WriteString(fmt.Sprintf("  Capacity policy                 : %v\n", formatCapacityPolicyBrief(st.Policy)))
+	}
+	if st.LastExpandTime > 0 {
+		sb.WriteString(fmt.Sprintf("  Last auto expansion             : %v to %v GB\n", formatTime(st.LastExpandTime), st.LastExpandCapacity))
+	}
+	if st.LastDpCreateTime > 0 {
+		sb.WriteString(fmt.Sprintf("  Last early dp creation          : %v %v partitions\n", formatTime(st.LastDpCreateTime), st.LastDpCreateCount))
+	}
+	if st.Alert != "" {
+		sb.WriteString(fmt.Sprintf("  Capacity alert                  : %v\n", st.Alert))
+	}
+	if st.LastErr != "" {
+		sb.WriteString(fmt.Sprintf("  Capacity policy error           : %v\n", st.LastErr))
+	}
 	return sb.String()
 }
 
+var (
+	capacityForecastPattern     = "%-24v    %-12v    %-12v    %-8v    %-12v    %-12v    %v"
+	capacityForecastTableHeader = fmt.Sprintf(capacityForecastPattern, "NAME", "USED", "TOTAL", "RATIO", "GROWTH/DAY", "DAYS TO FULL", "POLICY")
+)
+
+func formatCapacityForecastTableRow(name string, f *proto.CapacityForecast, policy string) string {
+	if f == nil {
+		return fmt.Sprintf(capacityForecastPattern, name, "-", "-", "-", "-", "-", policy)
+	}
+	return fmt.Sprintf(capacityForecastPattern, name, formatSize(f.UsedBytes), formatSize(f.TotalBytes),
+		fmt.Sprintf("%.2f%%", f.UsedRatio*100), formatGrowthPerDay(f.GrowthPerDay), formatDaysToFull(f.DaysToFull), policy)
+}
+
 func formatVolumeStatus(status uint8) string {
 	switch status {
 	case 0:
@@ -230,7 +305,165 @@ func formatUidInfoTableRow(uidInfo *proto.UidSpaceInfo) string {
 
 func formatVerInfoTableRow(verInfo *proto.VolVersionInfo) string {
 	return fmt.Sprintf(volumeVersionPattern,
-		verInfo.Ver, time.UnixMicro(int64(verInfo.Ver)).Local().Format(time.RFC1123), verInfo.Status, "")
+		verInfo.Ver, time.UnixMicro(int64(verInfo.Ver)).Local().Format(time.RFC1123), verInfo.Status, formatVerPolicy(verInfo))
+}
+
+func formatVerPolicy(verInfo *proto.VolVersionInfo) string {
+	if verInfo.Policy == "" {
+		return ""
+	}
+	other := fmt.Sprintf("%v:%v", verInfo.Policy, strings.Join(verInfo.Tiers, ","))
+	if len(verInfo.Labels) > 0 {
+		other += " " + formatSnapshotPolicyLabels(verInfo.Labels)
+	}
+	return other
+}
+
+func formatSnapshotPolicyLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+var (
+	clusterEventPattern     = "%-20v    %-22v    %-8v    %-14v    %-24v    %-12v    %v"
+	clusterEventTableHeader = fmt.Sprintf(clusterEventPattern, "TIME", "TYPE", "SEVERITY", "OBJECT TYPE", "OBJECT", "VOLUME", "MESSAGE")
+)
+
+func formatClusterEventTableRow(e *proto.ClusterEvent) string {
+	return fmt.Sprintf(clusterEventPattern, formatTime(e.Time), e.Type, e.Severity, e.ObjectType, e.ObjectID, e.Vol, e.Message)
+}
+
+var (
+	tenantQosVolPattern     = "  %-24v    %-6v    %-8v    %-12v    %-12v    %-12v    %-12v    %-12v"
+	tenantQosVolTableHeader = fmt.Sprintf(tenantQosVolPattern, "VOLUME", "WEIGHT", "FLOW", "CLIENT", "OBJECTNODE", "ASSIGNED", "CLIENT LIMIT", "NODE LIMIT")
+)
+
+func formatFlowLimit(limit uint64) string {
+	if limit == 0 {
+		return "unlimited"
+	}
+	return formatSize(limit) + "/s"
+}
+
+func formatTenantQosFlowRow(vol string, weight interface{}, flow string, f *proto.TenantQosFlow) string {
+	return fmt.Sprintf(tenantQosVolPattern, vol, weight, flow, formatSize(f.ClientDemand), formatSize(f.ObjectNodeDemand),
+		formatSize(f.Assigned), formatSize(f.ClientLimit), formatSize(f.ObjectNodeLimit))
+}
+
+func formatTenantQosStatus(st *proto.TenantQosStatus) string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Tenant       : %v\n", st.Tenant))
+	sb.WriteString(fmt.Sprintf("Read limit   : %v\n", formatFlowLimit(st.FlowRLimit)))
+	sb.WriteString(fmt.Sprintf("Write limit  : %v\n", formatFlowLimit(st.FlowWLimit)))
+	if st.UpdateTime > 0 {
+		sb.WriteString(fmt.Sprintf("Update time  : %v\n", formatTime(st.UpdateTime)))
+	}
+	sb.WriteString(fmt.Sprintf("%v\n", tenantQosVolTableHeader))
+	sb.WriteString(fmt.Sprintf("%v\n", formatTenantQosFlowRow("TOTAL", "", "read", &st.Read)))
+	sb.WriteString(fmt.Sprintf("%v\n", formatTenantQosFlowRow("TOTAL", "", "write", &st.Write)))
+	for _, v := range st.Vols {
+		sb.WriteString(fmt.Sprintf("%v\n", formatTenantQosFlowRow(v.Vol, v.Weight, "read", &v.Read)))
+		sb.WriteString(fmt.Sprintf("%v\n", formatTenantQosFlowRow(v.Vol, v.Weight, "write", &v.Write)))
+	}
+	return sb.String()
+}
+
+var (
+	metadataBackupPattern         = "%-48v    %-12v    %-20v"
+	metadataBackupTableHeader     = fmt.Sprintf(metadataBackupPattern, "FILE", "SIZE", "TIME")
+	metadataBackupDiffPattern     = "%-16v    %-24v    %-8v    %v"
+	metadataBackupDiffTableHeader = fmt.Sprintf(metadataBackupDiffPattern, "KIND", "ID", "DIFF", "DETAIL")
+)
+
+func formatMetadataBackupTableRow(info *proto.MetadataBackupInfo) string {
+	return fmt.Sprintf(metadataBackupPattern, info.File, formatSize(uint64(info.Size)), formatTime(info.CreateTime))
+}
+
+func formatMetadataBackupInfo(info *proto.MetadataBackupInfo) string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("  File        : %v\n", info.File))
+	sb.WriteString(fmt.Sprintf("  Cluster     : %v\n", info.Cluster))
+	sb.WriteString(fmt.Sprintf("  Applied     : %v\n", info.Applied))
+	sb.WriteString(fmt.Sprintf("  Create time : %v\n", formatTime(info.CreateTime)))
+	sb.WriteString(fmt.Sprintf("  Keys        : %v\n", info.Keys))
+	if info.Size > 0 {
+		sb.WriteString(fmt.Sprintf("  Size        : %v\n", formatSize(uint64(info.Size))))
+	}
+	for _, kind := range sortedKeys(info.Counts) {
+		sb.WriteString(fmt.Sprintf("    %-10v: %v\n", kind, info.Counts[kind]))
+	}
+	return sb.String()
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var (
+	snapshotPolicyPattern     = "%-20v    %-8v    %-40v    %-24v    %v"
+	snapshotPolicyTableHeader = fmt.Sprintf(snapshotPolicyPattern, "NAME", "ENABLE", "TIERS", "LABELS", "VOLUMES")
+)
+
+func formatSnapshotPolicyTableRow(policy *proto.SnapshotPolicy) string {
+	tiers := make([]string, 0, len(policy.Tiers))
+	for _, tier := range policy.Tiers {
+		tiers = append(tiers, fmt.Sprintf("%v(%v,keep %v)", tier.Name, tier.Schedule, tier.Keep))
+	}
+	return fmt.Sprintf(snapshotPolicyPattern, policy.Name, formatEnabledDisabled(policy.Enable),
+		strings.Join(tiers, " "), formatSnapshotPolicyLabels(policy.Labels), strings.Join(policy.Vols, ","))
+}
+
+var (
+	snapshotPolicyStatusPattern     = "%-20v    %-12v    %-8v    %-20v    %-20v    %v"
+	snapshotPolicyStatusTableHeader = fmt.Sprintf(snapshotPolicyStatusPattern, "VOLUME", "TIER", "KEPT", "LAST", "NEXT", "ERROR")
+)
+
+func formatSnapshotPolicyStatusRows(status *proto.SnapshotPolicyVolStatus) []string {
+	lastErr := status.LastErr
+	if lastErr != "" && status.LastErrTime != 0 {
+		lastErr = fmt.Sprintf("%v %v", formatTime(status.LastErrTime), lastErr)
+	}
+	rows := make([]string, 0, len(status.Tiers))
+	for _, tier := range status.Tiers {
+		last, next := "-", "-"
+		if tier.LastTime != 0 {
+			last = formatTime(tier.LastTime)
+		}
+		if tier.NextTime != 0 {
+			next = formatTime(tier.NextTime)
+		}
+		rows = append(rows, fmt.Sprintf(snapshotPolicyStatusPattern, status.VolName, tier.Name, tier.Versions, last, next, lastErr))
+	}
+	if len(rows) == 0 {
+		rows = append(rows, fmt.Sprintf(snapshotPolicyStatusPattern, status.VolName, "-", "-", "-", "-", lastErr))
+	}
+	return rows
+}
+
+var (
+	snapshotDiffPattern     = "%-10v    %-8v    %-12v    %v"
+	snapshotDiffTableHeader = fmt.Sprintf(snapshotDiffPattern, "CHANGE", "TYPE", "INODE", "PATH")
+)
+
+func formatSnapshotDiffEntry(entry proto.SnapshotDiffEntry) string {
+	fileType := "file"
+	if entry.IsDir {
+		fileType = "dir"
+	}
+	path := entry.Path
+	if entry.OldPath != "" {
+		path = fmt.Sprintf("%v -> %v", entry.OldPath, entry.Path)
+	}
+	return fmt.Sprintf(snapshotDiffPattern, entry.Change, fileType, entry.Inode, path)
 }
 
 var (
@@ -749,6 +982,8 @@ func formatDataNodeDetail(dn *proto.DataNodeInfo, rowTable bool) string {
 	sb.WriteString(fmt.Sprintf("  Available           : %v\n", formatSize(dn.AvailableSpace)))
 	sb.WriteString(fmt.Sprintf("  Total               : %v\n", formatSize(dn.Total)))
 	sb.WriteString(fmt.Sprintf("  Zone                : %v\n", dn.ZoneName))
+	sb.WriteString(fmt.Sprintf("  Rack                : %v\n", dn.Rack))
+	sb.WriteString(fmt.Sprintf("  Host                : %v\n", dn.Host))
 	sb.WriteString(fmt.Sprintf("  IsActive            : %v\n", formatNodeStatus(dn.IsActive)))
 	sb.WriteString(fmt.Sprintf("  Report time         : %v\n", formatTimeToString(dn.ReportTime)))
 	sb.WriteString(fmt.Sprintf("  Partition count     : %v\n", dn.DataPartitionCount))
@@ -781,6 +1016,8 @@ func formatMetaNodeDetail(mn *proto.MetaNodeInfo, rowTable bool) string {
 	sb.WriteString(fmt.Sprintf("  Allocated           : %v\n", formatSize(mn.Used)))
 	sb.WriteString(fmt.Sprintf("  Total               : %v\n", formatSize(mn.Total)))
 	sb.WriteString(fmt.Sprintf("  Zone                : %v\n", mn.ZoneName))
+	sb.WriteString(fmt.Sprintf("  Rack                : %v\n", mn.Rack))
+	sb.WriteString(fmt.Sprintf("  Host                : %v\n", mn.Host))
 	sb.WriteString(fmt.Sprintf("  IsActive            : %v\n", formatNodeStatus(mn.IsActive)))
 	sb.WriteString(fmt.Sprintf("  Report time         : %v\n", formatTimeToString(mn.ReportTime)))
 	sb.WriteString(fmt.Sprintf("  Partition count     : %v\n", mn.MetaPartitionCount))
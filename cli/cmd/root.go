@@ -80,6 +80,10 @@ func NewRootCmd(client *master.MasterClient) *CubeFSCmd {
 		newQuotaCmd(client),
 		newDiskCmd(client),
 		newVersionCmd(client),
+		newSnapshotCmd(client),
+		newEventsCmd(client),
+		newTenantQosCmd(client),
+		newCapacityCmd(client),
 	)
 	return cmd
 }
@@ -0,0 +1,378 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdSnapshotUse       = "snapshot [COMMAND]"
+	cmdSnapshotShort     = "Manage volume snapshots"
+	cmdSnapshotDiffUse   = "diff [VOLUME] [FROM VERSEQ] [TO VERSEQ]"
+	cmdSnapshotDiffShort = "list the paths changed between two versions, TO defaults to the current version"
+
+	cmdSnapshotPolicyUse         = "policy [COMMAND]"
+	cmdSnapshotPolicyShort       = "Manage the snapshot policies taking versions of volumes on schedules"
+	cmdSnapshotPolicyCreateShort = "Create a snapshot policy"
+	cmdSnapshotPolicyUpdateShort = "Update the tiers, labels or switch of a snapshot policy"
+	cmdSnapshotPolicyDeleteShort = "Delete a snapshot policy without volumes attached"
+	cmdSnapshotPolicyAttachShort = "Attach a volume to a snapshot policy"
+	cmdSnapshotPolicyDetachShort = "Detach a volume from a snapshot policy"
+	cmdSnapshotPolicyListShort   = "List the snapshot policies"
+	cmdSnapshotPolicyStatusShort = "Show the versions kept and the next schedules of the volumes of a snapshot policy"
+	cmdSnapshotPolicyTierUsage   = "Tier as NAME:SCHEDULE:KEEP, the schedule is \"minute hour day-of-month month day-of-week\" or @hourly, @daily, @weekly, repeatable"
+	cmdSnapshotPolicyLabelUsage  = "Label KEY=VALUE set on the versions taken by the policy, repeatable"
+	cmdSnapshotPolicyEnableUsage = "Enable the policy"
+
+	snapshotDiffPollInterval = 2 * time.Second
+	snapshotDiffPageLimit    = 1000
+)
+
+func newSnapshotCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdSnapshotUse,
+		Short: cmdSnapshotShort,
+		Args:  cobra.MinimumNArgs(0),
+	}
+	cmd.AddCommand(
+		newSnapshotDiffCmd(client),
+		newSnapshotPolicyCmd(client),
+	)
+	return cmd
+}
+
+func newSnapshotDiffCmd(client *master.MasterClient) *cobra.Command {
+	var optTimeout uint32
+	cmd := &cobra.Command{
+		Use:   cmdSnapshotDiffUse,
+		Short: cmdSnapshotDiffShort,
+		Args:  cobra.RangeArgs(2, 3),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err        error
+				fromVerSeq uint64
+				toVerSeq   uint64
+				id         string
+				result     *proto.SnapshotDiffResult
+			)
+			defer func() {
+				errout(err)
+			}()
+			volName := args[0]
+			if fromVerSeq, err = strconv.ParseUint(args[1], 10, 64); err != nil {
+				return
+			}
+			if len(args) == 3 {
+				if toVerSeq, err = strconv.ParseUint(args[2], 10, 64); err != nil {
+					return
+				}
+			}
+			if id, err = client.AdminAPI().CreateSnapshotDiff(volName, fromVerSeq, toVerSeq); err != nil {
+				return
+			}
+
+			deadline := time.Now().Add(time.Duration(optTimeout) * time.Second)
+			for {
+				if result, err = client.AdminAPI().GetSnapshotDiff(volName, id, 0, snapshotDiffPageLimit); err != nil {
+					return
+				}
+				if result.Done {
+					break
+				}
+				if optTimeout > 0 && time.Now().After(deadline) {
+					err = fmt.Errorf("snapshot diff %v not done in %v seconds", id, optTimeout)
+					return
+				}
+				time.Sleep(snapshotDiffPollInterval)
+			}
+			if result.Status != proto.TaskSucceeds {
+				err = fmt.Errorf("snapshot diff %v failed: %v", id, result.Result)
+				return
+			}
+
+			stdout("%v\n", snapshotDiffTableHeader)
+			for {
+				for _, entry := range result.Entries {
+					stdout("%v\n", formatSnapshotDiffEntry(entry))
+				}
+				if result.NextMarker == 0 {
+					break
+				}
+				if result, err = client.AdminAPI().GetSnapshotDiff(volName, id, result.NextMarker, snapshotDiffPageLimit); err != nil {
+					return
+				}
+			}
+			stdout("Total: %v\n", result.Total)
+		},
+	}
+	cmd.Flags().Uint32Var(&optTimeout, "timeout", 0, "Seconds to wait for the diff, 0 waits forever")
+	return cmd
+}
+
+func newSnapshotPolicyCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdSnapshotPolicyUse,
+		Short: cmdSnapshotPolicyShort,
+		Args:  cobra.MinimumNArgs(0),
+	}
+	cmd.AddCommand(
+		newSnapshotPolicyCreateCmd(client),
+		newSnapshotPolicyUpdateCmd(client),
+		newSnapshotPolicyDeleteCmd(client),
+		newSnapshotPolicyAttachCmd(client),
+		newSnapshotPolicyDetachCmd(client),
+		newSnapshotPolicyListCmd(client),
+		newSnapshotPolicyStatusCmd(client),
+	)
+	return cmd
+}
+
+// parseSnapshotPolicyTier parses NAME:SCHEDULE:KEEP, the schedule itself has no colon.
+func parseSnapshotPolicyTier(s string) (tier proto.SnapshotPolicyTier, err error) {
+	first, last := strings.Index(s, ":"), strings.LastIndex(s, ":")
+	if first < 0 || first == last {
+		return tier, fmt.Errorf("invalid tier %q, should be NAME:SCHEDULE:KEEP", s)
+	}
+	tier.Name = s[:first]
+	tier.Schedule = s[first+1 : last]
+	if tier.Keep, err = strconv.Atoi(s[last+1:]); err != nil {
+		return tier, fmt.Errorf("invalid keep of tier %q: %v", s, err)
+	}
+	return
+}
+
+func parseSnapshotPolicyFlags(policy *proto.SnapshotPolicy, cmd *cobra.Command, tiers, labels []string, enable bool) (err error) {
+	if cmd.Flags().Changed("tier") {
+		policy.Tiers = make([]proto.SnapshotPolicyTier, 0, len(tiers))
+		for _, s := range tiers {
+			var tier proto.SnapshotPolicyTier
+			if tier, err = parseSnapshotPolicyTier(s); err != nil {
+				return
+			}
+			policy.Tiers = append(policy.Tiers, tier)
+		}
+	}
+	if cmd.Flags().Changed("label") {
+		policy.Labels = make(map[string]string, len(labels))
+		for _, s := range labels {
+			kv := strings.SplitN(s, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return fmt.Errorf("invalid label %q, should be KEY=VALUE", s)
+			}
+			policy.Labels[kv[0]] = kv[1]
+		}
+	}
+	if cmd.Flags().Changed("enable") {
+		policy.Enable = enable
+	}
+	return
+}
+
+func newSnapshotPolicyCreateCmd(client *master.MasterClient) *cobra.Command {
+	var (
+		optTiers  []string
+		optLabels []string
+		optEnable bool
+	)
+	cmd := &cobra.Command{
+		Use:   CliOpCreate + " [POLICY]",
+		Short: cmdSnapshotPolicyCreateShort,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			policy := &proto.SnapshotPolicy{Name: args[0], Enable: optEnable}
+			if err = parseSnapshotPolicyFlags(policy, cmd, optTiers, optLabels, optEnable); err != nil {
+				return
+			}
+			if err = client.AdminAPI().CreateSnapshotPolicy(policy); err != nil {
+				return
+			}
+			stdout("Snapshot policy %v is created\n", policy.Name)
+		},
+	}
+	cmd.Flags().StringArrayVar(&optTiers, "tier", nil, cmdSnapshotPolicyTierUsage)
+	cmd.Flags().StringArrayVar(&optLabels, "label", nil, cmdSnapshotPolicyLabelUsage)
+	cmd.Flags().BoolVar(&optEnable, "enable", true, cmdSnapshotPolicyEnableUsage)
+	return cmd
+}
+
+func newSnapshotPolicyUpdateCmd(client *master.MasterClient) *cobra.Command {
+	var (
+		optTiers  []string
+		optLabels []string
+		optEnable bool
+	)
+	cmd := &cobra.Command{
+		Use:   CliOpUpdate + " [POLICY]",
+		Short: cmdSnapshotPolicyUpdateShort,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err      error
+				policies []*proto.SnapshotPolicy
+				policy   *proto.SnapshotPolicy
+			)
+			defer func() {
+				errout(err)
+			}()
+			if policies, err = client.AdminAPI().ListSnapshotPolicy(); err != nil {
+				return
+			}
+			for _, p := range policies {
+				if p.Name == args[0] {
+					policy = p
+				}
+			}
+			if policy == nil {
+				err = fmt.Errorf("snapshot policy %v not found", args[0])
+				return
+			}
+			if err = parseSnapshotPolicyFlags(policy, cmd, optTiers, optLabels, optEnable); err != nil {
+				return
+			}
+			if err = client.AdminAPI().UpdateSnapshotPolicy(policy); err != nil {
+				return
+			}
+			stdout("Snapshot policy %v is updated\n", policy.Name)
+		},
+	}
+	cmd.Flags().StringArrayVar(&optTiers, "tier", nil, cmdSnapshotPolicyTierUsage)
+	cmd.Flags().StringArrayVar(&optLabels, "label", nil, cmdSnapshotPolicyLabelUsage)
+	cmd.Flags().BoolVar(&optEnable, "enable", true, cmdSnapshotPolicyEnableUsage)
+	return cmd
+}
+
+func newSnapshotPolicyDeleteCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpDelete + " [POLICY]",
+		Short: cmdSnapshotPolicyDeleteShort,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			if err = client.AdminAPI().DeleteSnapshotPolicy(args[0]); err != nil {
+				return
+			}
+			stdout("Snapshot policy %v is deleted\n", args[0])
+		},
+	}
+	return cmd
+}
+
+func newSnapshotPolicyAttachCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attach [POLICY] [VOLUME]",
+		Short: cmdSnapshotPolicyAttachShort,
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			if err = client.AdminAPI().AttachSnapshotPolicy(args[1], args[0]); err != nil {
+				return
+			}
+			stdout("Volume %v is attached to snapshot policy %v\n", args[1], args[0])
+		},
+	}
+	return cmd
+}
+
+func newSnapshotPolicyDetachCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "detach [POLICY] [VOLUME]",
+		Short: cmdSnapshotPolicyDetachShort,
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			if err = client.AdminAPI().DetachSnapshotPolicy(args[1], args[0]); err != nil {
+				return
+			}
+			stdout("Volume %v is detached from snapshot policy %v\n", args[1], args[0])
+		},
+	}
+	return cmd
+}
+
+func newSnapshotPolicyListCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpList,
+		Short: cmdSnapshotPolicyListShort,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err      error
+				policies []*proto.SnapshotPolicy
+			)
+			defer func() {
+				errout(err)
+			}()
+			if policies, err = client.AdminAPI().ListSnapshotPolicy(); err != nil {
+				return
+			}
+			stdout("%v\n", snapshotPolicyTableHeader)
+			for _, policy := range policies {
+				stdout("%v\n", formatSnapshotPolicyTableRow(policy))
+			}
+		},
+	}
+	return cmd
+}
+
+func newSnapshotPolicyStatusCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     CliOpStatus + " [POLICY]",
+		Short:   cmdSnapshotPolicyStatusShort,
+		Aliases: []string{"status"},
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err    error
+				status *proto.SnapshotPolicyStatus
+			)
+			defer func() {
+				errout(err)
+			}()
+			if status, err = client.AdminAPI().GetSnapshotPolicyStatus(args[0]); err != nil {
+				return
+			}
+			stdout("%v\n", snapshotPolicyTableHeader)
+			stdout("%v\n\n", formatSnapshotPolicyTableRow(status.Policy))
+			stdout("%v\n", snapshotPolicyStatusTableHeader)
+			for _, volStatus := range status.Vols {
+				for _, row := range formatSnapshotPolicyStatusRows(volStatus) {
+					stdout("%v\n", row)
+				}
+			}
+		},
+	}
+	return cmd
+}
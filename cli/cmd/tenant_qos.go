@@ -0,0 +1,149 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/cubefs/cubefs/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdTenantQosUse          = "tenantqos [COMMAND]"
+	cmdTenantQosShort        = "Manage the flow budgets of the tenants shared by their volumes"
+	cmdTenantQosSetUse       = "set [TENANT]"
+	cmdTenantQosSetShort     = "Set the flow budget of a tenant"
+	cmdTenantQosDeleteUse    = "delete [TENANT]"
+	cmdTenantQosDeleteShort  = "Delete the flow budget of a tenant"
+	cmdTenantQosStatusUse    = "status [TENANT]"
+	cmdTenantQosStatusShort  = "Show the demands and the limits of the tenants and their volumes"
+	cmdTenantQosDefaultLimit = 0
+)
+
+func newTenantQosCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdTenantQosUse,
+		Short: cmdTenantQosShort,
+	}
+	cmd.AddCommand(
+		newTenantQosSetCmd(client),
+		newTenantQosDeleteCmd(client),
+		newTenantQosStatusCmd(client),
+	)
+	return cmd
+}
+
+func parseVolWeights(weights []string) (map[string]uint64, error) {
+	res := make(map[string]uint64, len(weights))
+	for _, w := range weights {
+		kv := strings.SplitN(w, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid weight %v, should be vol=weight", w)
+		}
+		weight, err := strconv.ParseUint(kv[1], 10, 64)
+		if err != nil || weight == 0 {
+			return nil, fmt.Errorf("invalid weight %v, should be a positive integer", w)
+		}
+		res[kv[0]] = weight
+	}
+	return res, nil
+}
+
+func newTenantQosSetCmd(client *master.MasterClient) *cobra.Command {
+	var (
+		optReadMB  uint64
+		optWriteMB uint64
+		optWeights []string
+	)
+	cmd := &cobra.Command{
+		Use:   cmdTenantQosSetUse,
+		Short: cmdTenantQosSetShort,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			q := &proto.TenantQos{
+				Tenant:     args[0],
+				FlowRLimit: optReadMB * util.MB,
+				FlowWLimit: optWriteMB * util.MB,
+			}
+			if q.VolWeights, err = parseVolWeights(optWeights); err != nil {
+				return
+			}
+			if err = client.AdminAPI().SetTenantQos(q); err != nil {
+				return
+			}
+			stdout("Tenant qos of %v is set\n", q.Tenant)
+		},
+	}
+	cmd.Flags().Uint64Var(&optReadMB, "read", cmdTenantQosDefaultLimit, "Read flow budget in MB/s, 0 is unlimited")
+	cmd.Flags().Uint64Var(&optWriteMB, "write", cmdTenantQosDefaultLimit, "Write flow budget in MB/s, 0 is unlimited")
+	cmd.Flags().StringSliceVar(&optWeights, "weight", nil, "Weight of a volume in the budget, vol=weight, repeatable, default weight is 1")
+	return cmd
+}
+
+func newTenantQosDeleteCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdTenantQosDeleteUse,
+		Short: cmdTenantQosDeleteShort,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			if err = client.AdminAPI().DeleteTenantQos(args[0]); err != nil {
+				return
+			}
+			stdout("Tenant qos of %v is deleted\n", args[0])
+		},
+	}
+	return cmd
+}
+
+func newTenantQosStatusCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdTenantQosStatusUse,
+		Short: cmdTenantQosStatusShort,
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err    error
+				tenant string
+				status []*proto.TenantQosStatus
+			)
+			defer func() {
+				errout(err)
+			}()
+			if len(args) > 0 {
+				tenant = args[0]
+			}
+			if status, err = client.AdminAPI().GetTenantQosStatus(tenant); err != nil {
+				return
+			}
+			for _, st := range status {
+				stdout("%v", formatTenantQosStatus(st))
+			}
+		},
+	}
+	return cmd
+}
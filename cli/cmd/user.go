@@ -55,6 +55,7 @@ func newUserCreateCmd(client *master.MasterClient) *cobra.Command {
 	var optAccessKey string
 	var optSecretKey string
 	var optUserType string
+	var optRole string
 	var clientIDKey string
 	var optYes bool
 	cmd := &cobra.Command{
@@ -75,6 +76,10 @@ func newUserCreateCmd(client *master.MasterClient) *cobra.Command {
 				err = fmt.Errorf("Invalid user type. ")
 				return
 			}
+			if optRole != "" && !proto.IsValidAdminRole(optRole) {
+				err = fmt.Errorf("Invalid role. ")
+				return
+			}
 
 			// ask user for confirm
 			if !optYes {
@@ -92,12 +97,17 @@ func newUserCreateCmd(client *master.MasterClient) *cobra.Command {
 					displaySecretKey = optSecretKey
 				}
 				displayUserType := userType.String()
+				displayRole := "[by type]"
+				if optRole != "" {
+					displayRole = optRole
+				}
 				fmt.Printf("Create a new CubeFS cluster user\n")
 				stdout("  User ID   : %v\n", userID)
 				stdout("  Password  : %v\n", displayPassword)
 				stdout("  Access Key: %v\n", displayAccessKey)
 				stdout("  Secret Key: %v\n", displaySecretKey)
 				stdout("  Type      : %v\n", displayUserType)
+				stdout("  Role      : %v\n", displayRole)
 				stdout("\nConfirm (yes/no)[yes]: ")
 				var userConfirm string
 				_, _ = fmt.Scanln(&userConfirm)
@@ -113,6 +123,7 @@ func newUserCreateCmd(client *master.MasterClient) *cobra.Command {
 				AccessKey: accessKey,
 				SecretKey: secretKey,
 				Type:      userType,
+				Role:      optRole,
 			}
 			var userInfo *proto.UserInfo
 			if userInfo, err = client.UserAPI().CreateUser(&param, clientIDKey); err != nil {
@@ -129,6 +140,7 @@ func newUserCreateCmd(client *master.MasterClient) *cobra.Command {
 	cmd.Flags().StringVar(&optAccessKey, "access-key", "", "Specify user access key for object storage interface authentication [16 digits & letters]")
 	cmd.Flags().StringVar(&optSecretKey, "secret-key", "", "Specify user secret key for object storage interface authentication [32 digits & letters]")
 	cmd.Flags().StringVar(&optUserType, "user-type", "normal", "Specify user type [normal | admin]")
+	cmd.Flags().StringVar(&optRole, "role", "", "Specify role on master admin api [viewer | operator | admin | volume-owner]")
 	cmd.Flags().StringVar(&clientIDKey, CliFlagClientIDKey, client.ClientIDKey(), CliUsageClientIDKey)
 	cmd.Flags().BoolVarP(&optYes, "yes", "y", false, "Answer yes for all questions")
 	return cmd
@@ -143,6 +155,7 @@ func newUserUpdateCmd(client *master.MasterClient) *cobra.Command {
 	var optAccessKey string
 	var optSecretKey string
 	var optUserType string
+	var optRole string
 	var clientIDKey string
 	var optYes bool
 	cmd := &cobra.Command{
@@ -165,6 +178,10 @@ func newUserUpdateCmd(client *master.MasterClient) *cobra.Command {
 					return
 				}
 			}
+			if optRole != "" && !proto.IsValidAdminRole(optRole) {
+				err = fmt.Errorf("Invalid role ")
+				return
+			}
 
 			if !optYes {
 				displayAccessKey := "[no change]"
@@ -179,11 +196,16 @@ func newUserUpdateCmd(client *master.MasterClient) *cobra.Command {
 				if optUserType != "" {
 					displayUserType = optUserType
 				}
+				displayRole := "[no change]"
+				if optRole != "" {
+					displayRole = optRole
+				}
 				fmt.Printf("Update CubeFS cluster user\n")
 				stdout("  User ID   : %v\n", userID)
 				stdout("  Access Key: %v\n", displayAccessKey)
 				stdout("  Secret Key: %v\n", displaySecretKey)
 				stdout("  Type      : %v\n", displayUserType)
+				stdout("  Role      : %v\n", displayRole)
 				stdout("\nConfirm (yes/no)[yes]: ")
 				var userConfirm string
 				_, _ = fmt.Scanln(&userConfirm)
@@ -192,7 +214,7 @@ func newUserUpdateCmd(client *master.MasterClient) *cobra.Command {
 					return
 				}
 			}
-			if accessKey == "" && secretKey == "" && optUserType == "" {
+			if accessKey == "" && secretKey == "" && optUserType == "" && optRole == "" {
 				err = fmt.Errorf("no update")
 				return
 			}
@@ -201,6 +223,7 @@ func newUserUpdateCmd(client *master.MasterClient) *cobra.Command {
 				AccessKey: accessKey,
 				SecretKey: secretKey,
 				Type:      userType,
+				Role:      optRole,
 			}
 			var userInfo *proto.UserInfo
 			if userInfo, err = client.UserAPI().UpdateUser(&param, clientIDKey); err != nil {
@@ -214,6 +237,7 @@ func newUserUpdateCmd(client *master.MasterClient) *cobra.Command {
 	cmd.Flags().StringVar(&optAccessKey, "access-key", "", "Update user access key")
 	cmd.Flags().StringVar(&optSecretKey, "secret-key", "", "Update user secret key")
 	cmd.Flags().StringVar(&optUserType, "user-type", "", "Update user type [normal | admin]")
+	cmd.Flags().StringVar(&optRole, "role", "", "Update role on master admin api [viewer | operator | admin | volume-owner]")
 	cmd.Flags().StringVar(&clientIDKey, CliFlagClientIDKey, client.ClientIDKey(), CliUsageClientIDKey)
 	cmd.Flags().BoolVarP(&optYes, "yes", "y", false, "Answer yes for all questions")
 	return cmd
@@ -416,6 +440,7 @@ func printUserInfo(userInfo *proto.UserInfo) {
 	stdout("  Access Key : %v\n", userInfo.AccessKey)
 	stdout("  Secret Key : %v\n", userInfo.SecretKey)
 	stdout("  Type       : %v\n", userInfo.UserType)
+	stdout("  Role       : %v\n", userInfo.AdminRole())
 	stdout("  Create Time: %v\n", userInfo.CreateTime)
 	if userInfo.Policy == nil {
 		return
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/cubefs/cubefs/sdk/meta"
 	"github.com/spf13/cobra"
 )
 
@@ -13,6 +17,7 @@ const (
 	cmdVersionDelShort         = "del volume version"
 	cmdVersionListShort        = "list volume version"
 	cmdVersionSetStrategyShort = "set volume version strategy"
+	cmdVersionCloneShort       = "clone a writable volume from volume version"
 )
 
 func newVersionCmd(client *master.MasterClient) *cobra.Command {
@@ -27,6 +32,7 @@ func newVersionCmd(client *master.MasterClient) *cobra.Command {
 		newVersionDelCmd(client),
 		newVersionListCmd(client),
 		newVersionStrategyCmd(client),
+		newVersionCloneCmd(client),
 	)
 	return cmd
 }
@@ -152,3 +158,127 @@ func newVersionStrategyCmd(client *master.MasterClient) *cobra.Command {
 	cmd.Flags().StringVar(&optKeyword, "keyword", "", "Specify keyword of volume name to filter")
 	return cmd
 }
+
+func newVersionCloneCmd(client *master.MasterClient) *cobra.Command {
+	var optOwner string
+	cmd := &cobra.Command{
+		Use:   CliFlagVersionClone + " [VOLUME] [VER SEQ] [CLONE VOLUME]",
+		Short: cmdVersionCloneShort,
+		Args:  cobra.MinimumNArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				volumeName = args[0]
+				cloneName  = args[2]
+				verSeq     uint64
+				err        error
+			)
+			defer func() {
+				errout(err)
+			}()
+			if verSeq, err = strconv.ParseUint(args[1], 10, 64); err != nil {
+				return
+			}
+			if optOwner == "" {
+				var svv *proto.SimpleVolView
+				if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volumeName); err != nil {
+					return
+				}
+				optOwner = svv.Owner
+			}
+			if _, err = client.AdminAPI().CloneVolume(volumeName, verSeq, cloneName, optOwner); err != nil {
+				return
+			}
+			stdout("volume %v is created, now copy the metadata of version %v\n", cloneName, verSeq)
+
+			var src, dst *meta.MetaWrapper
+			if src, err = meta.NewMetaWrapper(&meta.MetaConfig{Volume: volumeName, Masters: client.Nodes(), VerReadSeq: verSeq}); err != nil {
+				return
+			}
+			defer src.Close()
+			if dst, err = meta.NewMetaWrapper(&meta.MetaConfig{Volume: cloneName, Masters: client.Nodes()}); err != nil {
+				return
+			}
+			defer dst.Close()
+			var count int
+			if count, err = cloneVersionTree(src, dst); err != nil {
+				return
+			}
+			stdout("clone volume %v from volume %v version %v successfully, %v inodes copied\n", cloneName, volumeName, verSeq, count)
+		},
+	}
+	cmd.Flags().StringVar(&optOwner, "owner", "", "Specify owner of the clone, the owner of the volume by default")
+	return cmd
+}
+
+// cloneVersionTree copies the directory tree read from src into the root of dst. The extent
+// keys are copied as is, so the clone refers to the extents shared with the source volume.
+func cloneVersionTree(src, dst *meta.MetaWrapper) (count int, err error) {
+	type dirPair struct{ src, dst uint64 }
+	// inodes already copied, for hard links
+	copied := make(map[uint64]uint64)
+	dirs := []dirPair{{proto.RootIno, proto.RootIno}}
+	for len(dirs) > 0 {
+		dir := dirs[0]
+		dirs = dirs[1:]
+		var children []proto.Dentry
+		if children, err = src.ReadDir_ll(dir.src); err != nil {
+			return count, fmt.Errorf("read dir %v: %v", dir.src, err)
+		}
+		for _, child := range children {
+			if ino, ok := copied[child.Inode]; ok && !proto.IsDir(child.Type) {
+				if _, err = dst.Link(dir.dst, child.Name, ino, ""); err != nil {
+					return count, fmt.Errorf("link %v: %v", child.Name, err)
+				}
+				continue
+			}
+			var ino uint64
+			if ino, err = cloneInode(src, dst, dir.dst, child); err != nil {
+				return count, fmt.Errorf("clone %v: %v", child.Name, err)
+			}
+			copied[child.Inode] = ino
+			count++
+			if proto.IsDir(child.Type) {
+				dirs = append(dirs, dirPair{child.Inode, ino})
+			}
+		}
+	}
+	return
+}
+
+func cloneInode(src, dst *meta.MetaWrapper, parent uint64, dentry proto.Dentry) (ino uint64, err error) {
+	var info, newInfo *proto.InodeInfo
+	if info, err = src.InodeGet_ll(dentry.Inode); err != nil {
+		return
+	}
+	if newInfo, err = dst.Create_ll(parent, dentry.Name, info.Mode, info.Uid, info.Gid, info.Target, ""); err != nil {
+		return
+	}
+	ino = newInfo.Inode
+	if proto.IsRegular(info.Mode) {
+		var eks []proto.ExtentKey
+		if _, _, eks, err = src.GetExtents(dentry.Inode); err != nil {
+			return
+		}
+		// the versions of the clone start over
+		for i := range eks {
+			eks[i].SetSeq(0)
+		}
+		if len(eks) > 0 {
+			if err = dst.AppendExtentKeys(ino, eks); err != nil {
+				return
+			}
+		}
+		if err = dst.Truncate(ino, info.Size, ""); err != nil {
+			return
+		}
+	}
+	var xattrs *proto.XAttrInfo
+	if xattrs, err = src.XAttrGetAll_ll(dentry.Inode); err == nil && len(xattrs.XAttrs) > 0 {
+		if err = dst.BatchSetXAttr_ll(ino, xattrs.XAttrs); err != nil {
+			return
+		}
+	}
+	err = dst.Setattr(ino, proto.AttrMode|proto.AttrUid|proto.AttrGid|proto.AttrModifyTime|proto.AttrAccessTime,
+		info.Mode, info.Uid, info.Gid, info.AccessTime.Unix(), info.ModifyTime.Unix())
+	return
+}
@@ -299,6 +299,10 @@ func (d *Dir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.Lo
 	}()
 
 	log.LogDebugf("TRACE Lookup: parent(%v) req(%v)", d.info.Inode, req)
+	if req.Name == SnapshotDirName && d.super.snapshotDirEnabled(d.info.Inode) {
+		resp.EntryValid = LookupValidDuration
+		return &SnapshotRoot{super: d.super, dirIno: d.info.Inode}, nil
+	}
 	log.LogDebugf("TRACE Lookup: parent(%v) path(%v) d.super.bcacheDir(%v)", d.info.Inode, d.getCwd(), d.super.bcacheDir)
 
 	if d.needDentrycache() {
@@ -0,0 +1,357 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fs
+
+import (
+	"context"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cubefs/cubefs/depends/bazil.org/fuse"
+	"github.com/cubefs/cubefs/depends/bazil.org/fuse/fs"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/stream"
+	"github.com/cubefs/cubefs/sdk/meta"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/stat"
+)
+
+const (
+	// SnapshotDirName is the name of the virtual directory listing the versions of a directory.
+	SnapshotDirName = ".snapshot"
+
+	SnapshotDirRoot = "root" // only at the root of the mount
+	SnapshotDirAll  = "all"  // in every directory
+
+	// versions are listed by the time they were frozen in UTC, the version number is accepted as well
+	snapshotNameLayout = "2006-01-02_15-04-05.000000"
+)
+
+// snapshotView reads the volume at a version, the same way as a mount with snapshotReadSeq.
+type snapshotView struct {
+	verSeq uint64
+	mw     *meta.MetaWrapper
+	ec     *stream.ExtentClient
+}
+
+// SnapshotRoot is the .snapshot directory of a directory, its entries are the versions of the volume.
+type SnapshotRoot struct {
+	super  *Super
+	dirIno uint64
+}
+
+// SnapshotDir is a read only directory at a version.
+type SnapshotDir struct {
+	super *Super
+	view  *snapshotView
+	info  *proto.InodeInfo
+}
+
+// SnapshotFile is a read only file or symlink at a version.
+type SnapshotFile struct {
+	super *Super
+	view  *snapshotView
+	info  *proto.InodeInfo
+}
+
+var (
+	_ fs.Node                = (*SnapshotRoot)(nil)
+	_ fs.NodeRequestLookuper = (*SnapshotRoot)(nil)
+	_ fs.HandleReadDirAller  = (*SnapshotRoot)(nil)
+
+	_ fs.Node                = (*SnapshotDir)(nil)
+	_ fs.NodeRequestLookuper = (*SnapshotDir)(nil)
+	_ fs.HandleReadDirAller  = (*SnapshotDir)(nil)
+
+	_ fs.Node           = (*SnapshotFile)(nil)
+	_ fs.NodeOpener     = (*SnapshotFile)(nil)
+	_ fs.HandleReader   = (*SnapshotFile)(nil)
+	_ fs.HandleReleaser = (*SnapshotFile)(nil)
+	_ fs.NodeReadlinker = (*SnapshotFile)(nil)
+)
+
+// snapshotDirEnabled tells whether the directory ino has a .snapshot directory.
+func (s *Super) snapshotDirEnabled(ino uint64) bool {
+	if s.mw.VerReadSeq != 0 || !proto.IsHot(s.volType) {
+		return false
+	}
+	switch s.snapshotDir {
+	case SnapshotDirAll:
+		return true
+	case SnapshotDirRoot:
+		return ino == s.rootIno
+	}
+	return false
+}
+
+// snapshotVersion is a committed version, frozen when the next version was created.
+type snapshotVersion struct {
+	verSeq     uint64
+	frozenTime uint64
+}
+
+// snapshotVersions returns the committed versions readable through the .snapshot directories.
+func (s *Super) snapshotVersions() (vers []snapshotVersion) {
+	verList := s.ec.GetVerMgr()
+	if verList == nil || len(verList.VerList) == 0 {
+		return
+	}
+	// the last version is the one being written
+	for i, ver := range verList.VerList[:len(verList.VerList)-1] {
+		if ver.Status == proto.VersionNormal {
+			vers = append(vers, snapshotVersion{verSeq: ver.Ver, frozenTime: verList.VerList[i+1].Ver})
+		}
+	}
+	return
+}
+
+// getSnapshotView returns the view of the version verSeq, created on first use.
+func (s *Super) getSnapshotView(verSeq uint64) (view *snapshotView, err error) {
+	s.snapshotLock.Lock()
+	defer s.snapshotLock.Unlock()
+	if view = s.snapshotViews[verSeq]; view != nil {
+		return
+	}
+
+	view = &snapshotView{verSeq: verSeq}
+	readSeq := verSeq
+	if readSeq == 0 {
+		// 0 stands for the current version, the first version is asked this way
+		readSeq = math.MaxUint64
+	}
+	metaConfig := &meta.MetaConfig{
+		Volume:     s.volname,
+		Owner:      s.owner,
+		Masters:    strings.Split(s.masters, meta.HostsSeparator),
+		VerReadSeq: readSeq,
+	}
+	if view.mw, err = meta.NewMetaWrapper(metaConfig); err != nil {
+		return nil, errors.Trace(err, "snapshot view NewMetaWrapper failed!")
+	}
+	extentConfig := &stream.ExtentConfig{
+		Volume:            s.volname,
+		Masters:           strings.Split(s.masters, meta.HostsSeparator),
+		VolumeType:        s.volType,
+		VerReadSeq:        readSeq,
+		OnAppendExtentKey: view.mw.AppendExtentKey,
+		OnSplitExtentKey:  view.mw.SplitExtentKey,
+		OnGetExtents:      view.mw.GetExtents,
+		OnTruncate:        view.mw.Truncate,
+		OnEvictIcache:     func(inode uint64) {},
+		DisableMetaCache:  true,
+	}
+	if view.ec, err = stream.NewExtentClient(extentConfig); err != nil {
+		view.mw.Close()
+		return nil, errors.Trace(err, "snapshot view NewExtentClient failed!")
+	}
+	view.mw.VerReadSeq = view.ec.GetReadVer()
+	view.mw.Client = view.ec
+	s.snapshotViews[verSeq] = view
+	log.LogInfof("getSnapshotView: vol(%v) verSeq(%v) readSeq(%v)", s.volname, verSeq, view.mw.VerReadSeq)
+	return
+}
+
+func (s *Super) closeSnapshotViews() {
+	s.snapshotLock.Lock()
+	defer s.snapshotLock.Unlock()
+	for verSeq, view := range s.snapshotViews {
+		view.ec.Close()
+		view.mw.Close()
+		delete(s.snapshotViews, verSeq)
+	}
+}
+
+func snapshotName(frozenTime uint64) string {
+	return time.UnixMicro(int64(frozenTime)).UTC().Format(snapshotNameLayout)
+}
+
+// findSnapshotVersion accepts the name of a version or its number.
+func findSnapshotVersion(vers []snapshotVersion, name string) (verSeq uint64, found bool) {
+	for _, ver := range vers {
+		if snapshotName(ver.frozenTime) == name || strconv.FormatUint(ver.verSeq, 10) == name {
+			return ver.verSeq, true
+		}
+	}
+	return 0, false
+}
+
+// Attr keeps the inode number of .snapshot away from the real inodes.
+func (r *SnapshotRoot) Attr(ctx context.Context, a *fuse.Attr) error {
+	a.Valid = AttrValidDuration
+	a.Inode = math.MaxUint64 - r.dirIno
+	a.Mode = os.ModeDir | 0o555
+	a.Nlink = 2
+	a.BlockSize = DefaultBlksize
+	return nil
+}
+
+func (r *SnapshotRoot) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.LookupResponse) (fs.Node, error) {
+	var err error
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("SnapshotLookup", err, bgTime, 1)
+	}()
+
+	verSeq, found := findSnapshotVersion(r.super.snapshotVersions(), req.Name)
+	if !found {
+		return nil, fuse.ENOENT
+	}
+
+	view, err := r.super.getSnapshotView(verSeq)
+	if err != nil {
+		log.LogErrorf("SnapshotLookup: dir(%v) verSeq(%v) err(%v)", r.dirIno, verSeq, err)
+		return nil, fuse.EIO
+	}
+	info, err := view.mw.InodeGet_ll(r.dirIno)
+	if err != nil {
+		// the directory did not exist at that version
+		return nil, ParseError(err)
+	}
+	resp.EntryValid = LookupValidDuration
+	log.LogDebugf("TRACE SnapshotLookup: dir(%v) name(%v) verSeq(%v)", r.dirIno, req.Name, verSeq)
+	return &SnapshotDir{super: r.super, view: view, info: info}, nil
+}
+
+func (r *SnapshotRoot) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	vers := r.super.snapshotVersions()
+	dirents := make([]fuse.Dirent, 0, len(vers))
+	for _, ver := range vers {
+		dirents = append(dirents, fuse.Dirent{
+			Inode: r.dirIno,
+			Type:  fuse.DT_Dir,
+			Name:  snapshotName(ver.frozenTime),
+		})
+	}
+	return dirents, nil
+}
+
+func (d *SnapshotDir) Attr(ctx context.Context, a *fuse.Attr) error {
+	fillAttr(d.info, a)
+	a.Mode &^= 0o222
+	return nil
+}
+
+func (d *SnapshotDir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.LookupResponse) (fs.Node, error) {
+	var err error
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("SnapshotLookup", err, bgTime, 1)
+	}()
+
+	ino, _, err := d.view.mw.Lookup_ll(d.info.Inode, req.Name)
+	if err != nil {
+		if err != syscall.ENOENT {
+			log.LogErrorf("SnapshotLookup: parent(%v) name(%v) verSeq(%v) err(%v)", d.info.Inode, req.Name, d.view.verSeq, err)
+		}
+		return nil, ParseError(err)
+	}
+	info, err := d.view.mw.InodeGet_ll(ino)
+	if err != nil {
+		log.LogErrorf("SnapshotLookup: parent(%v) name(%v) ino(%v) verSeq(%v) err(%v)", d.info.Inode, req.Name, ino, d.view.verSeq, err)
+		return nil, ParseError(err)
+	}
+	resp.EntryValid = LookupValidDuration
+	if proto.IsDir(info.Mode) {
+		return &SnapshotDir{super: d.super, view: d.view, info: info}, nil
+	}
+	return &SnapshotFile{super: d.super, view: d.view, info: info}, nil
+}
+
+func (d *SnapshotDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	var err error
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("SnapshotReadDirAll", err, bgTime, 1)
+	}()
+
+	children, err := d.view.mw.ReadDir_ll(d.info.Inode)
+	if err != nil {
+		log.LogErrorf("SnapshotReadDirAll: ino(%v) verSeq(%v) err(%v)", d.info.Inode, d.view.verSeq, err)
+		return nil, ParseError(err)
+	}
+	dirents := make([]fuse.Dirent, 0, len(children))
+	for _, child := range children {
+		dirents = append(dirents, fuse.Dirent{
+			Inode: child.Inode,
+			Type:  ParseType(child.Type),
+			Name:  child.Name,
+		})
+	}
+	return dirents, nil
+}
+
+func (f *SnapshotFile) Attr(ctx context.Context, a *fuse.Attr) error {
+	fillAttr(f.info, a)
+	a.Mode &^= 0o222
+	if proto.IsSymlink(f.info.Mode) {
+		a.Size = uint64(len(f.info.Target))
+	}
+	return nil
+}
+
+func (f *SnapshotFile) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenResponse) (fs.Handle, error) {
+	if !req.Flags.IsReadOnly() {
+		return nil, fuse.Errno(syscall.EROFS)
+	}
+	if err := f.view.ec.OpenStream(f.info.Inode); err != nil {
+		log.LogErrorf("SnapshotOpen: ino(%v) verSeq(%v) err(%v)", f.info.Inode, f.view.verSeq, err)
+		return nil, ParseError(err)
+	}
+	// the content of a version never changes
+	resp.Flags |= fuse.OpenKeepCache
+	return f, nil
+}
+
+func (f *SnapshotFile) Read(ctx context.Context, req *fuse.ReadRequest, resp *fuse.ReadResponse) (err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("SnapshotRead", err, bgTime, 1)
+	}()
+
+	size, err := f.view.ec.Read(f.info.Inode, resp.Data[fuse.OutHeaderSize:], int(req.Offset), req.Size)
+	if err != nil && err != io.EOF {
+		log.LogErrorf("SnapshotRead: ino(%v) verSeq(%v) req(%v) err(%v) size(%v)", f.info.Inode, f.view.verSeq, req, err, size)
+		return ParseError(err)
+	}
+	if size > req.Size {
+		log.LogErrorf("SnapshotRead: read size larger than request size, ino(%v) req(%v) size(%v)", f.info.Inode, req, size)
+		return fuse.ERANGE
+	}
+	if size < 0 {
+		size = 0
+	}
+	resp.Data = resp.Data[:size+fuse.OutHeaderSize]
+	return nil
+}
+
+func (f *SnapshotFile) Release(ctx context.Context, req *fuse.ReleaseRequest) error {
+	if err := f.view.ec.CloseStream(f.info.Inode); err != nil {
+		log.LogErrorf("SnapshotRelease: ino(%v) verSeq(%v) err(%v)", f.info.Inode, f.view.verSeq, err)
+	}
+	return nil
+}
+
+func (f *SnapshotFile) Readlink(ctx context.Context, req *fuse.ReadlinkRequest) (string, error) {
+	if !proto.IsSymlink(f.info.Mode) {
+		return "", fuse.Errno(syscall.EINVAL)
+	}
+	return string(f.info.Target), nil
+}
@@ -91,6 +91,10 @@ type Super struct {
 	taskPool      []common.TaskPool
 	closeC        chan struct{}
 	enableVerRead bool
+
+	snapshotDir   string
+	snapshotViews map[uint64]*snapshotView
+	snapshotLock  sync.Mutex
 }
 
 // Functions that Super needs to implement
@@ -163,6 +167,8 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 	s.bcacheFilterFiles = opt.BcacheFilterFiles
 	s.bcacheBatchCnt = opt.BcacheBatchCnt
 	s.closeC = make(chan struct{}, 1)
+	s.snapshotDir = opt.SnapshotDir
+	s.snapshotViews = make(map[uint64]*snapshotView)
 	s.taskPool = []common.TaskPool{common.New(DefaultTaskPoolSize, DefaultTaskPoolSize), common.New(DefaultTaskPoolSize, DefaultTaskPoolSize)}
 
 	if s.mw.EnableSummary {
@@ -232,6 +238,9 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 		OnLoadBcache:      s.bc.Get,
 		OnCacheBcache:     s.bc.Put,
 		OnEvictBcache:     s.bc.Evict,
+		OnDedupExtentKey:  s.mw.DedupExtentKey,
+		OnDedupRegister:   s.mw.DedupRegister,
+		DedupChunkSize:    int(opt.DedupChunkSize),
 
 		DisableMetaCache:             DisableMetaCache,
 		MinWriteAbleDataPartitionCnt: opt.MinWriteAbleDataPartitionCnt,
@@ -719,6 +728,7 @@ func getDelInodes(src []uint64, act []*proto.InodeInfo) []uint64 {
 
 func (s *Super) Close() {
 	close(s.closeC)
+	s.closeSnapshotViews()
 }
 
 func (s *Super) SetTransaction(txMaskStr string, timeout int64, retryNum int64, retryInterval int64) {
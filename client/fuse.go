@@ -804,6 +804,7 @@ func parseMountOption(cfg *config.Config) (*proto.MountOptions, error) {
 		}
 		log.LogDebugf("oonfig.verReadSeq %v opt.VerReadSeq %v", verReadSeq, opt.VerReadSeq)
 	}
+	opt.SnapshotDir = GlobalMountOptions[proto.SnapshotDir].GetString()
 	opt.MetaSendTimeout = GlobalMountOptions[proto.MetaSendTimeout].GetInt64()
 
 	opt.BuffersTotalLimit = GlobalMountOptions[proto.BuffersTotalLimit].GetInt64()
@@ -814,11 +815,20 @@ func parseMountOption(cfg *config.Config) (*proto.MountOptions, error) {
 	opt.MinWriteAbleDataPartitionCnt = int(GlobalMountOptions[proto.MinWriteAbleDataPartitionCnt].GetInt64())
 	opt.FileSystemName = GlobalMountOptions[proto.FileSystemName].GetString()
 	opt.DisableMountSubtype = GlobalMountOptions[proto.DisableMountSubtype].GetBool()
+	opt.DedupChunkSize = GlobalMountOptions[proto.DedupChunkSize].GetInt64()
 
 	if opt.MountPoint == "" || opt.Volname == "" || opt.Owner == "" || opt.Master == "" {
 		return nil, errors.New(fmt.Sprintf("invalid config file: lack of mandatory fields, mountPoint(%v), volName(%v), owner(%v), masterAddr(%v)", opt.MountPoint, opt.Volname, opt.Owner, opt.Master))
 	}
 
+	if opt.SnapshotDir != "" && opt.SnapshotDir != cfs.SnapshotDirRoot && opt.SnapshotDir != cfs.SnapshotDirAll {
+		return nil, errors.New(fmt.Sprintf("invalid fields, SnapshotDir(%v) must be %v or %v", opt.SnapshotDir, cfs.SnapshotDirRoot, cfs.SnapshotDirAll))
+	}
+
+	if opt.DedupChunkSize <= 0 || opt.DedupChunkSize%(4*1024) != 0 {
+		return nil, errors.New(fmt.Sprintf("invalid fields, DedupChunkSize(%v) must be a positive multiple of 4KB", opt.DedupChunkSize))
+	}
+
 	if opt.BuffersTotalLimit < 0 {
 		return nil, errors.New(fmt.Sprintf("invalid fields, BuffersTotalLimit(%v) must larger or equal than 0", opt.BuffersTotalLimit))
 	}
@@ -88,6 +88,7 @@ var (
 	configVersion    = flag.Bool("v", false, "show version")
 	configForeground = flag.Bool("f", false, "run foreground")
 	redirectSTD      = flag.Bool("redirect-std", true, "redirect standard output to file")
+	restoreMetadata  = flag.String("restore-metadata", "", "rebuild the store of the stopped master from the metadata backup file and exit")
 )
 
 func interceptSignal(s common.Server) {
@@ -143,6 +144,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *restoreMetadata != "" {
+		if role := cfg.GetString(ConfigKeyRole); role != RoleMaster {
+			fmt.Printf("Restore metadata failed: role %v is not %v\n", role, RoleMaster)
+			os.Exit(1)
+		}
+		keys, err := master.RestoreMetadataBackup(cfg, *restoreMetadata)
+		if err != nil {
+			fmt.Printf("Restore metadata failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Restore metadata of %v keys from %v\n", keys, *restoreMetadata)
+		os.Exit(0)
+	}
+
 	if !*configForeground {
 		if err := startDaemon(); err != nil {
 			fmt.Printf("Server start failed: %v\n", err)
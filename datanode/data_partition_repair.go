@@ -41,6 +41,7 @@ type DataPartitionRepairTask struct {
 	ExtentsToBeCreated             []*storage.ExtentInfo
 	ExtentsToBeRepaired            []*storage.ExtentInfo
 	LeaderTinyDeleteRecordFileSize int64
+	LeaderTinyDeleteGeneration     uint64 // the records are synced by offset only within the same generation
 	LeaderAddr                     string
 }
 
@@ -132,7 +133,8 @@ func (dp *DataPartition) repair(extentType uint8) {
 }
 
 func (dp *DataPartition) buildDataPartitionRepairTask(repairTasks []*DataPartitionRepairTask, extentType uint8, tinyExtents []uint64, replica []string) (err error) {
-	// get the local extent info
+	// get the local extent info, the generation is loaded first and checked again by the reader
+	generation := dp.extentStore.TinyDeleteGeneration()
 	extents, leaderTinyDeleteRecordFileSize, err := dp.getLocalExtentInfo(extentType, tinyExtents)
 	if err != nil {
 		return err
@@ -141,6 +143,7 @@ func (dp *DataPartition) buildDataPartitionRepairTask(repairTasks []*DataPartiti
 	log.LogInfof("buildDataPartitionRepairTask dp %v, extent type %v, len extent %v, replica size %v", dp.partitionID, extentType, len(extents), len(replica))
 	repairTasks[0] = NewDataPartitionRepairTask(extents, leaderTinyDeleteRecordFileSize, replica[0], replica[0])
 	repairTasks[0].addr = replica[0]
+	repairTasks[0].LeaderTinyDeleteGeneration = generation
 
 	// new repair tasks for the followers
 	for index := 1; index < len(replica); index++ {
@@ -152,6 +155,7 @@ func (dp *DataPartition) buildDataPartitionRepairTask(repairTasks []*DataPartiti
 		log.LogInfof("buildDataPartitionRepairTask dp %v,  add new add %v,  extent type %v", dp.partitionID, replica[index], extentType)
 		repairTasks[index] = NewDataPartitionRepairTask(extents, leaderTinyDeleteRecordFileSize, replica[index], replica[0])
 		repairTasks[index].addr = replica[index]
+		repairTasks[index].LeaderTinyDeleteGeneration = generation
 	}
 
 	return
@@ -0,0 +1,251 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/repl"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// The shards of the erasure-coded extents are stored apart from the data
+// partitions, a node holding shards does not need to host a replica.
+// A shard is kept in <disk>/ec_shards/<partitionID>/<extentID>_<shardIndex>.
+const (
+	EcShardDirName = "ec_shards"
+)
+
+const (
+	ActionEcShardWrite  = "ActionEcShardWrite"
+	ActionEcShardRead   = "ActionEcShardRead"
+	ActionEcShardDelete = "ActionEcShardDelete"
+)
+
+func ecShardFileName(extentID uint64, index int) string {
+	return fmt.Sprintf("%v_%v", extentID, index)
+}
+
+func isEcShardPacket(p *repl.Packet) bool {
+	return p.Opcode == proto.OpEcShardWrite || p.Opcode == proto.OpEcShardRead || p.Opcode == proto.OpEcShardDelete
+}
+
+// NewPacketToEcShard returns a packet addressing one shard of an erasure-coded extent.
+func NewPacketToEcShard(opcode uint8, partitionID, extentID uint64, index int) (p *repl.Packet) {
+	p = new(repl.Packet)
+	p.Opcode = opcode
+	p.PartitionID = partitionID
+	p.ExtentID = extentID
+	p.Magic = proto.ProtoMagic
+	p.ExtentType = proto.NormalExtentType
+	p.ReqID = proto.GenerateRequestID()
+	p.Arg = []byte(strconv.Itoa(index))
+	p.ArgLen = uint32(len(p.Arg))
+	return
+}
+
+func getEcShardIndex(p *repl.Packet) (index int, err error) {
+	if p.ArgLen == 0 || len(p.Arg) < int(p.ArgLen) {
+		return 0, fmt.Errorf("ec shard index not found")
+	}
+	return strconv.Atoi(string(p.Arg[:p.ArgLen]))
+}
+
+func (manager *SpaceManager) findEcShard(partitionID, extentID uint64, index int) (filePath string, err error) {
+	name := ecShardFileName(extentID, index)
+	for _, d := range manager.GetDisks() {
+		filePath = path.Join(d.Path, EcShardDirName, strconv.FormatUint(partitionID, 10), name)
+		if _, err = os.Stat(filePath); err == nil {
+			return
+		}
+	}
+	return "", os.ErrNotExist
+}
+
+// ecShardDisk returns the writable disk with the most available space.
+func (manager *SpaceManager) ecShardDisk() (disk *Disk, err error) {
+	for _, d := range manager.GetDisks() {
+		if d.Status != proto.ReadWrite || !d.CanWrite() {
+			continue
+		}
+		if disk == nil || d.Available > disk.Available {
+			disk = d
+		}
+	}
+	if disk == nil {
+		err = fmt.Errorf("no writable disk for ec shard")
+	}
+	return
+}
+
+// WriteEcShard writes the data of a shard at the given offset, writing at
+// offset zero starts the shard over.
+func (manager *SpaceManager) WriteEcShard(partitionID, extentID uint64, index int, offset int64, data []byte) (err error) {
+	filePath, err := manager.findEcShard(partitionID, extentID, index)
+	if err != nil {
+		var d *Disk
+		if d, err = manager.ecShardDisk(); err != nil {
+			return
+		}
+		dir := path.Join(d.Path, EcShardDirName, strconv.FormatUint(partitionID, 10))
+		if err = os.MkdirAll(dir, 0o755); err != nil {
+			return
+		}
+		filePath = path.Join(dir, ecShardFileName(extentID, index))
+	}
+	flag := os.O_CREATE | os.O_RDWR
+	if offset == 0 {
+		flag |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(filePath, flag, 0o666)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if _, err = f.WriteAt(data, offset); err != nil {
+		return
+	}
+	return f.Sync()
+}
+
+func (manager *SpaceManager) ReadEcShard(partitionID, extentID uint64, index int, offset int64, data []byte) (err error) {
+	filePath, err := manager.findEcShard(partitionID, extentID, index)
+	if err != nil {
+		return
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if _, err = f.ReadAt(data, offset); err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return
+}
+
+func (manager *SpaceManager) DeleteEcShard(partitionID, extentID uint64, index int) (err error) {
+	filePath, err := manager.findEcShard(partitionID, extentID, index)
+	if err != nil {
+		return nil
+	}
+	return os.Remove(filePath)
+}
+
+// DeleteEcShards removes all the shards of the partition held by this node.
+func (manager *SpaceManager) DeleteEcShards(partitionID uint64) {
+	for _, d := range manager.GetDisks() {
+		dir := path.Join(d.Path, EcShardDirName, strconv.FormatUint(partitionID, 10))
+		if err := os.RemoveAll(dir); err != nil {
+			log.LogWarnf("action[DeleteEcShards] dp %v dir %v err %v", partitionID, dir, err)
+		}
+	}
+}
+
+// Handle OpEcShardWrite packet.
+func (s *DataNode) handleEcShardWritePacket(p *repl.Packet) {
+	var err error
+	defer func() {
+		if err != nil {
+			p.PackErrorBody(ActionEcShardWrite, err.Error())
+		} else {
+			p.PacketOkReply()
+		}
+	}()
+	index, err := getEcShardIndex(p)
+	if err != nil {
+		return
+	}
+	if crc32.ChecksumIEEE(p.Data[:p.Size]) != p.CRC {
+		err = fmt.Errorf("ec shard crc mismatch")
+		return
+	}
+	err = s.space.WriteEcShard(p.PartitionID, p.ExtentID, index, p.ExtentOffset, p.Data[:p.Size])
+}
+
+// Handle OpEcShardRead packet.
+func (s *DataNode) handleEcShardReadPacket(p *repl.Packet, connect net.Conn) {
+	var err error
+	defer func() {
+		if err != nil {
+			p.PackErrorBody(ActionEcShardRead, err.Error())
+			p.WriteToConn(connect)
+		}
+	}()
+	index, err := getEcShardIndex(p)
+	if err != nil {
+		return
+	}
+	reply := repl.NewStreamReadResponsePacket(p.ReqID, p.PartitionID, p.ExtentID)
+	reply.Opcode = p.Opcode
+	reply.ExtentOffset = p.ExtentOffset
+	reply.Data = make([]byte, p.Size)
+	if err = s.space.ReadEcShard(p.PartitionID, p.ExtentID, index, p.ExtentOffset, reply.Data); err != nil {
+		return
+	}
+	reply.Size = p.Size
+	reply.CRC = crc32.ChecksumIEEE(reply.Data)
+	if err = reply.WriteToConn(connect); err != nil {
+		return
+	}
+	p.PacketOkReply()
+}
+
+// Handle OpEcShardDelete packet.
+func (s *DataNode) handleEcShardDeletePacket(p *repl.Packet) {
+	var err error
+	defer func() {
+		if err != nil {
+			p.PackErrorBody(ActionEcShardDelete, err.Error())
+		} else {
+			p.PacketOkReply()
+		}
+	}()
+	index, err := getEcShardIndex(p)
+	if err != nil {
+		return
+	}
+	err = s.space.DeleteEcShard(p.PartitionID, p.ExtentID, index)
+}
+
+// sendEcPacket sends the packet to the target and reads back the reply.
+func sendEcPacket(p *repl.Packet, target string) (err error) {
+	var conn *net.TCPConn
+	conn, err = gConnPool.GetConnect(target)
+	if err != nil {
+		return errors.Trace(err, "get connect to %v", target)
+	}
+	defer func() {
+		gConnPool.PutConnect(conn, err != nil)
+	}()
+	if err = p.WriteToConn(conn); err != nil {
+		return errors.Trace(err, "write to %v", target)
+	}
+	if err = p.ReadFromConnWithVer(conn, ecShardTimeoutSec); err != nil {
+		return errors.Trace(err, "read from %v", target)
+	}
+	if p.ResultCode != proto.OpOk {
+		return fmt.Errorf("%v from %v result code %v: %v", p.GetOpMsg(), target, p.ResultCode, string(p.Data[:p.Size]))
+	}
+	return
+}
@@ -0,0 +1,607 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/storage"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/config"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// Write journal. The small synchronous writes, random overwrites applied by
+// raft and small appends, are logged on a fast device and acknowledged once
+// the journal is synced. The extent files are written without sync and are
+// flushed in the background by the checkpoint, which then empties the journal.
+//
+// The journal is made of two files used in turn. A record is logged after its
+// extent is written, the checkpoint switches the writes to the other file,
+// flushes the extents touched by the records of the previous one and truncates
+// it. The records left after a
+// crash are written back to the extents when the partitions are loaded.
+//
+// The records are replayed in the order they were logged, so once an extent
+// has records in the journal every write to it is logged too, otherwise an
+// older record would overwrite the newer data after a crash.
+const (
+	JournalFileNamePrefix = "journal_"
+	JournalFileCount      = 2
+
+	DefaultJournalSize = 4 * util.GB
+	MinJournalSize     = 64 * util.MB
+
+	journalMagic          uint32 = 0xCF10AD01
+	journalFileHeaderSize        = 16 // magic(4) reserved(4) generation(8)
+	journalRecordHeadSize        = 44 // magic(4) crc(4) partition(8) extent(8) offset(8) size(4) writeType(4) dataCrc(4)
+
+	journalMaxRecordSize       = util.BlockSize
+	journalCheckpointInterval  = 5 * time.Second
+	journalCheckpointThreshold = 0.5 // of the file size
+)
+
+var errJournalFull = fmt.Errorf("write journal is full")
+
+type journalRecord struct {
+	partitionID uint64
+	extentID    uint64
+	offset      int64
+	size        int64
+	writeType   int
+	dataCrc     uint32
+}
+
+func (r *journalRecord) marshal(data []byte) (buf []byte) {
+	buf = make([]byte, journalRecordHeadSize+len(data))
+	binary.BigEndian.PutUint32(buf[0:4], journalMagic)
+	binary.BigEndian.PutUint64(buf[8:16], r.partitionID)
+	binary.BigEndian.PutUint64(buf[16:24], r.extentID)
+	binary.BigEndian.PutUint64(buf[24:32], uint64(r.offset))
+	binary.BigEndian.PutUint32(buf[32:36], uint32(r.size))
+	binary.BigEndian.PutUint32(buf[36:40], uint32(r.writeType))
+	binary.BigEndian.PutUint32(buf[40:44], r.dataCrc)
+	copy(buf[journalRecordHeadSize:], data)
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(buf[8:journalRecordHeadSize]))
+	return
+}
+
+func (r *journalRecord) unmarshal(head []byte) (err error) {
+	if binary.BigEndian.Uint32(head[0:4]) != journalMagic ||
+		binary.BigEndian.Uint32(head[4:8]) != crc32.ChecksumIEEE(head[8:journalRecordHeadSize]) {
+		return fmt.Errorf("invalid journal record")
+	}
+	r.partitionID = binary.BigEndian.Uint64(head[8:16])
+	r.extentID = binary.BigEndian.Uint64(head[16:24])
+	r.offset = int64(binary.BigEndian.Uint64(head[24:32]))
+	r.size = int64(binary.BigEndian.Uint32(head[32:36]))
+	r.writeType = int(binary.BigEndian.Uint32(head[36:40]))
+	r.dataCrc = binary.BigEndian.Uint32(head[40:44])
+	if r.size <= 0 || r.size > journalMaxRecordSize {
+		return fmt.Errorf("invalid journal record size %v", r.size)
+	}
+	return
+}
+
+type journalFile struct {
+	fp         *os.File
+	path       string
+	generation uint64
+	size       int64 // protected by writeJournal.mu
+	writeSeq   uint64
+	syncSeq    uint64
+	syncMu     sync.Mutex
+	extents    map[*DataPartition]map[uint64]struct{} // protected by writeJournal.mu
+}
+
+func (f *journalFile) writeHeader(generation uint64) (err error) {
+	header := make([]byte, journalFileHeaderSize)
+	binary.BigEndian.PutUint32(header[0:4], journalMagic)
+	binary.BigEndian.PutUint64(header[8:16], generation)
+	if err = f.fp.Truncate(0); err != nil {
+		return
+	}
+	if _, err = f.fp.WriteAt(header, 0); err != nil {
+		return
+	}
+	if err = f.fp.Sync(); err != nil {
+		return
+	}
+	f.generation = generation
+	f.size = journalFileHeaderSize
+	return
+}
+
+// sync makes sure the records up to seq are on the device, the writers
+// waiting at the same time share one fsync.
+func (f *journalFile) sync(seq uint64) (err error) {
+	f.syncMu.Lock()
+	defer f.syncMu.Unlock()
+	if atomic.LoadUint64(&f.syncSeq) >= seq {
+		return
+	}
+	target := atomic.LoadUint64(&f.writeSeq)
+	if err = f.fp.Sync(); err != nil {
+		return
+	}
+	atomic.StoreUint64(&f.syncSeq, target)
+	return
+}
+
+type journalReplayItem struct {
+	file   *journalFile
+	offset int64
+}
+
+type writeJournal struct {
+	dir      string
+	fileSize int64
+
+	mu      sync.Mutex
+	files   [JournalFileCount]*journalFile
+	active  int
+	started bool
+
+	checkpointMu sync.Mutex
+	checkpointC  chan struct{}
+	stopC        chan struct{}
+
+	replayMu sync.Mutex
+	replays  map[uint64][]journalReplayItem // partition id -> records left by the last run
+}
+
+func (s *DataNode) openJournal(cfg *config.Config) (err error) {
+	dir := cfg.GetString(ConfigKeyJournalPath)
+	if dir == "" {
+		return
+	}
+	size := cfg.GetInt64(ConfigKeyJournalSize)
+	if size <= 0 {
+		size = DefaultJournalSize
+	}
+	if size < MinJournalSize {
+		size = MinJournalSize
+	}
+	if s.journal, err = openWriteJournal(dir, size); err != nil {
+		return fmt.Errorf("open write journal %v: %v", dir, err)
+	}
+	log.LogInfof("action[openJournal] path %v size %v records to replay %v", dir, size, s.journal.replayCount())
+	return
+}
+
+func (s *DataNode) startJournal() {
+	if s.journal == nil {
+		return
+	}
+	if err := s.journal.start(); err != nil {
+		log.LogErrorf("action[startJournal] journal disabled: %v", err)
+		s.journal.close()
+		s.journal = nil
+	}
+}
+
+func (s *DataNode) stopJournal() {
+	if s.journal == nil {
+		return
+	}
+	s.journal.stop()
+}
+
+func openWriteJournal(dir string, fileSize int64) (j *writeJournal, err error) {
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	j = &writeJournal{
+		dir:         dir,
+		fileSize:    fileSize,
+		checkpointC: make(chan struct{}, 1),
+		stopC:       make(chan struct{}),
+		replays:     make(map[uint64][]journalReplayItem),
+	}
+	for i := 0; i < JournalFileCount; i++ {
+		f := &journalFile{path: path.Join(dir, fmt.Sprintf("%v%v", JournalFileNamePrefix, i))}
+		if f.fp, err = os.OpenFile(f.path, os.O_CREATE|os.O_RDWR, 0o666); err != nil {
+			j.close()
+			return nil, err
+		}
+		j.files[i] = f
+	}
+	// the older generation first, a crash may happen in the middle of a checkpoint
+	files := make([]*journalFile, 0, JournalFileCount)
+	for _, f := range j.files {
+		if err = j.scan(f); err != nil {
+			j.close()
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	sort.Slice(files, func(a, b int) bool { return files[a].generation < files[b].generation })
+	for _, f := range files {
+		if err = j.index(f); err != nil {
+			j.close()
+			return nil, err
+		}
+	}
+	return
+}
+
+// scan reads the file header, a file without a valid header holds no record.
+func (j *writeJournal) scan(f *journalFile) (err error) {
+	header := make([]byte, journalFileHeaderSize)
+	if _, err = f.fp.ReadAt(header, 0); err != nil {
+		if err == io.EOF {
+			err = nil
+		}
+		return
+	}
+	if binary.BigEndian.Uint32(header[0:4]) != journalMagic {
+		return
+	}
+	f.generation = binary.BigEndian.Uint64(header[8:16])
+	f.size = journalFileHeaderSize
+	return
+}
+
+// index records the position of the valid records, it stops at the first torn one.
+func (j *writeJournal) index(f *journalFile) (err error) {
+	if f.size == 0 {
+		return
+	}
+	head := make([]byte, journalRecordHeadSize)
+	offset := int64(journalFileHeaderSize)
+	for {
+		if _, err = f.fp.ReadAt(head, offset); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		r := new(journalRecord)
+		if r.unmarshal(head) != nil {
+			return
+		}
+		data := make([]byte, r.size)
+		if _, err = f.fp.ReadAt(data, offset+journalRecordHeadSize); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		if crc32.ChecksumIEEE(data) != r.dataCrc {
+			return
+		}
+		j.replays[r.partitionID] = append(j.replays[r.partitionID], journalReplayItem{file: f, offset: offset})
+		offset += journalRecordHeadSize + r.size
+	}
+}
+
+func (j *writeJournal) replayCount() (cnt int) {
+	j.replayMu.Lock()
+	defer j.replayMu.Unlock()
+	for _, items := range j.replays {
+		cnt += len(items)
+	}
+	return
+}
+
+// replay writes back the records of the partition, it is called when the
+// partition is loaded and before its raft is started.
+func (j *writeJournal) replay(dp *DataPartition) (err error) {
+	j.replayMu.Lock()
+	items := j.replays[dp.partitionID]
+	delete(j.replays, dp.partitionID)
+	j.replayMu.Unlock()
+	if len(items) == 0 {
+		return
+	}
+	head := make([]byte, journalRecordHeadSize)
+	var skipped int
+	for _, item := range items {
+		if _, err = item.file.fp.ReadAt(head, item.offset); err != nil {
+			return
+		}
+		r := new(journalRecord)
+		if err = r.unmarshal(head); err != nil {
+			return
+		}
+		data := make([]byte, r.size)
+		if _, err = item.file.fp.ReadAt(data, item.offset+journalRecordHeadSize); err != nil {
+			return
+		}
+		if err = dp.extentStore.ReplayWrite(r.extentID, r.offset, r.size, data, r.writeType); err != nil {
+			if err != storage.ExtentNotFoundError {
+				return fmt.Errorf("replay extent %v offset %v size %v: %v", r.extentID, r.offset, r.size, err)
+			}
+			skipped++
+			err = nil
+		}
+	}
+	log.LogInfof("action[replayJournal] dp %v replayed %v records, skipped %v of deleted extents",
+		dp.partitionID, len(items)-skipped, skipped)
+	return
+}
+
+// start empties the journal once every partition has been loaded and begins
+// to accept writes.
+func (j *writeJournal) start() (err error) {
+	j.replayMu.Lock()
+	for pid, items := range j.replays {
+		log.LogWarnf("action[startJournal] dp %v not loaded, %v journal records dropped", pid, len(items))
+	}
+	j.replays = make(map[uint64][]journalReplayItem)
+	j.replayMu.Unlock()
+
+	generation := j.files[0].generation
+	if j.files[1].generation > generation {
+		generation = j.files[1].generation
+	}
+	// the file written next always gets the greater generation
+	if err = j.files[0].writeHeader(generation + 1); err != nil {
+		return
+	}
+	if err = j.files[1].writeHeader(generation + 2); err != nil {
+		return
+	}
+	j.mu.Lock()
+	for _, f := range j.files {
+		f.extents = make(map[*DataPartition]map[uint64]struct{})
+	}
+	j.active = 0
+	j.started = true
+	j.mu.Unlock()
+	go j.checkpointScheduler()
+	return
+}
+
+func (j *writeJournal) stop() {
+	j.mu.Lock()
+	started := j.started
+	j.started = false
+	j.mu.Unlock()
+	if started {
+		close(j.stopC)
+		if err := j.checkpoint(); err != nil {
+			log.LogErrorf("action[stopJournal] checkpoint err %v", err)
+		}
+	}
+	j.close()
+}
+
+func (j *writeJournal) close() {
+	for _, f := range j.files {
+		if f != nil && f.fp != nil {
+			f.fp.Close()
+		}
+	}
+}
+
+// append logs the record and returns the file holding it with the sequence to
+// sync. The extent must have been written before, the checkpoint flushing the
+// file then flushes the data of the record.
+func (j *writeJournal) append(dp *DataPartition, r *journalRecord, data []byte) (f *journalFile, seq uint64, err error) {
+	buf := r.marshal(data)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.started {
+		return nil, 0, errJournalFull
+	}
+	f = j.files[j.active]
+	if f.size+int64(len(buf)) > j.fileSize {
+		j.triggerCheckpoint()
+		return nil, 0, errJournalFull
+	}
+	if _, err = f.fp.WriteAt(buf, f.size); err != nil {
+		return nil, 0, err
+	}
+	f.size += int64(len(buf))
+	if float64(f.size) > float64(j.fileSize)*journalCheckpointThreshold {
+		j.triggerCheckpoint()
+	}
+	extents, ok := f.extents[dp]
+	if !ok {
+		extents = make(map[uint64]struct{})
+		f.extents[dp] = extents
+	}
+	extents[r.extentID] = struct{}{}
+	seq = atomic.AddUint64(&f.writeSeq, 1)
+	return
+}
+
+// hasRecords returns true if records of the extent are left in the journal.
+func (j *writeJournal) hasRecords(dp *DataPartition, extentID uint64) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, f := range j.files {
+		if _, ok := f.extents[dp][extentID]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (j *writeJournal) triggerCheckpoint() {
+	select {
+	case j.checkpointC <- struct{}{}:
+	default:
+	}
+}
+
+func (j *writeJournal) checkpointScheduler() {
+	ticker := time.NewTicker(journalCheckpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-j.stopC:
+			return
+		case <-ticker.C:
+		case <-j.checkpointC:
+		}
+		if err := j.checkpoint(); err != nil {
+			log.LogErrorf("action[journalCheckpoint] dir %v err %v", j.dir, err)
+		}
+	}
+}
+
+// checkpoint flushes the extents written through the journal and empties it.
+func (j *writeJournal) checkpoint() (err error) {
+	j.checkpointMu.Lock()
+	defer j.checkpointMu.Unlock()
+
+	j.mu.Lock()
+	standby := j.files[1-j.active]
+	j.mu.Unlock()
+	// the previous checkpoint failed, its file must be flushed first
+	if standby.size > journalFileHeaderSize {
+		if err = j.flush(standby); err != nil {
+			return
+		}
+	}
+
+	j.mu.Lock()
+	cur := j.files[j.active]
+	if cur.size <= journalFileHeaderSize {
+		j.mu.Unlock()
+		return
+	}
+	j.active = 1 - j.active
+	j.mu.Unlock()
+	return j.flush(cur)
+}
+
+func (j *writeJournal) flush(f *journalFile) (err error) {
+	var cnt int
+	for dp, extents := range f.extents {
+		if dp.dataNode.space.Partition(dp.partitionID) != dp {
+			// the partition has been deleted
+			continue
+		}
+		for extentID := range extents {
+			if err = dp.extentStore.SyncExtent(extentID); err != nil {
+				if os.IsNotExist(err) || errors.Is(err, os.ErrClosed) {
+					err = nil
+					continue
+				}
+				return fmt.Errorf("sync dp %v extent %v: %v", dp.partitionID, extentID, err)
+			}
+			cnt++
+		}
+	}
+	size := f.size
+	j.mu.Lock()
+	generation := j.files[j.active].generation + 1
+	j.mu.Unlock()
+	if err = f.writeHeader(generation); err != nil {
+		return
+	}
+	j.mu.Lock()
+	f.extents = make(map[*DataPartition]map[uint64]struct{})
+	j.mu.Unlock()
+	log.LogDebugf("action[journalCheckpoint] %v flushed %v extents, %v bytes", f.path, cnt, size)
+	return
+}
+
+// usage returns the bytes taken in the active file.
+func (j *writeJournal) usage() (generation uint64, used int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	f := j.files[j.active]
+	return f.generation, f.size
+}
+
+func (dp *DataPartition) journal() *writeJournal {
+	if dp.dataNode == nil {
+		return nil
+	}
+	return dp.dataNode.journal
+}
+
+// journalWrite writes the data to the extent through the write journal if it
+// is enabled, otherwise directly. The small synchronous writes are logged, and
+// so is any write to an extent which still has records in the journal.
+func (dp *DataPartition) journalWrite(extentID uint64, offset, size int64, data []byte, crc uint32, writeType int, isSync bool) (status uint8, err error) {
+	store := dp.extentStore
+	j := dp.journal()
+	if j == nil || size <= 0 {
+		return store.Write(extentID, offset, size, data, crc, writeType, isSync)
+	}
+	if (!isSync || size > journalMaxRecordSize) && !j.hasRecords(dp, extentID) {
+		return store.Write(extentID, offset, size, data, crc, writeType, isSync)
+	}
+	// the extent is written first, a failed write is never replayed
+	if status, err = store.Write(extentID, offset, size, data, crc, writeType, false); err != nil {
+		return
+	}
+	var (
+		f   *journalFile
+		seq uint64
+	)
+	for off := int64(0); off < size; off += journalMaxRecordSize {
+		n := size - off
+		if n > journalMaxRecordSize {
+			n = journalMaxRecordSize
+		}
+		r := &journalRecord{
+			partitionID: dp.partitionID,
+			extentID:    extentID,
+			offset:      offset + off,
+			size:        n,
+			writeType:   writeType,
+			dataCrc:     crc32.ChecksumIEEE(data[off : off+n]),
+		}
+		if f, seq, err = j.append(dp, r, data[off:off+n]); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		if err != errJournalFull {
+			log.LogWarnf("action[journalWrite] dp %v extent %v journal err %v", dp.partitionID, extentID, err)
+		}
+		// the records of the extent must not be replayed over the data
+		if j.hasRecords(dp, extentID) {
+			return status, j.checkpoint()
+		}
+		return status, store.SyncExtent(extentID)
+	}
+	if isSync {
+		err = f.sync(seq)
+	}
+	return
+}
+
+// checkpointJournal flushes the journal, so that no record of the partition
+// is replayed after a crash.
+func (dp *DataPartition) checkpointJournal() (err error) {
+	if j := dp.journal(); j != nil {
+		return j.checkpoint()
+	}
+	return
+}
+
+func (dp *DataPartition) replayJournal() (err error) {
+	if j := dp.journal(); j != nil {
+		return j.replay(dp)
+	}
+	return
+}
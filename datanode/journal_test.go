@@ -0,0 +1,122 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"hash/crc32"
+	"os"
+	"testing"
+
+	"github.com/cubefs/cubefs/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalRecord(t *testing.T) {
+	data := []byte("journal record data")
+	r := &journalRecord{
+		partitionID: 1,
+		extentID:    1025,
+		offset:      4096,
+		size:        int64(len(data)),
+		writeType:   storage.RandomWriteType,
+		dataCrc:     crc32.ChecksumIEEE(data),
+	}
+	buf := r.marshal(data)
+	require.Equal(t, journalRecordHeadSize+len(data), len(buf))
+
+	got := new(journalRecord)
+	require.NoError(t, got.unmarshal(buf[:journalRecordHeadSize]))
+	require.Equal(t, r, got)
+	require.Equal(t, data, buf[journalRecordHeadSize:])
+
+	buf[10]++
+	require.Error(t, got.unmarshal(buf[:journalRecordHeadSize]))
+}
+
+func appendJournalRecord(t *testing.T, f *journalFile, r *journalRecord, data []byte) {
+	r.size = int64(len(data))
+	r.dataCrc = crc32.ChecksumIEEE(data)
+	buf := r.marshal(data)
+	_, err := f.fp.WriteAt(buf, f.size)
+	require.NoError(t, err)
+	f.size += int64(len(buf))
+}
+
+func TestJournalReopen(t *testing.T) {
+	dir, err := os.MkdirTemp("", "journal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	j, err := openWriteJournal(dir, MinJournalSize)
+	require.NoError(t, err)
+	require.Equal(t, 0, j.replayCount())
+	require.NoError(t, j.files[0].writeHeader(5))
+	require.NoError(t, j.files[1].writeHeader(4))
+
+	// the file of generation 4 is being flushed when the node crashes
+	appendJournalRecord(t, j.files[1], &journalRecord{partitionID: 1, extentID: 1025}, []byte("old"))
+	appendJournalRecord(t, j.files[0], &journalRecord{partitionID: 1, extentID: 1025}, []byte("new"))
+	appendJournalRecord(t, j.files[0], &journalRecord{partitionID: 2, extentID: 1, writeType: storage.AppendWriteType}, []byte("tiny"))
+	// a torn record at the tail
+	torn := (&journalRecord{partitionID: 2, extentID: 1, size: 4, dataCrc: 1}).marshal([]byte("torn"))
+	_, err = j.files[0].fp.WriteAt(torn[:journalRecordHeadSize+2], j.files[0].size)
+	require.NoError(t, err)
+	j.close()
+
+	j, err = openWriteJournal(dir, MinJournalSize)
+	require.NoError(t, err)
+	defer j.close()
+	require.Equal(t, 3, j.replayCount())
+	items := j.replays[1]
+	require.Len(t, items, 2)
+	require.Equal(t, j.files[1], items[0].file)
+	require.Equal(t, j.files[0], items[1].file)
+	require.Len(t, j.replays[2], 1)
+
+	require.NoError(t, j.start())
+	defer j.stop()
+	require.Equal(t, 0, j.replayCount())
+	generation, used := j.usage()
+	require.Equal(t, uint64(6), generation)
+	require.Equal(t, int64(journalFileHeaderSize), used)
+	require.Equal(t, uint64(7), j.files[1].generation)
+}
+
+func TestJournalHasRecords(t *testing.T) {
+	dir, err := os.MkdirTemp("", "journal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	j, err := openWriteJournal(dir, MinJournalSize)
+	require.NoError(t, err)
+	defer j.close()
+	require.NoError(t, j.start())
+	defer close(j.stopC)
+
+	dp := &DataPartition{partitionID: 1}
+	require.False(t, j.hasRecords(dp, 1025))
+	data := []byte("record")
+	_, _, err = j.append(dp, &journalRecord{partitionID: 1, extentID: 1025, size: int64(len(data))}, data)
+	require.NoError(t, err)
+	require.True(t, j.hasRecords(dp, 1025))
+	require.False(t, j.hasRecords(dp, 1026))
+	require.False(t, j.hasRecords(&DataPartition{partitionID: 2}, 1025))
+
+	// switched by a checkpoint, the records are kept until the file is flushed
+	j.mu.Lock()
+	j.active = 1 - j.active
+	j.mu.Unlock()
+	require.True(t, j.hasRecords(dp, 1025))
+}
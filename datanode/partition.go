@@ -28,6 +28,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/cubefs/cubefs/blobstore/common/ec"
 	raftProto "github.com/cubefs/cubefs/depends/tiglabs/raft/proto"
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/raftstore"
@@ -68,6 +69,10 @@ type DataPartitionMetadata struct {
 	StopRecover             bool
 	VerList                 []*proto.VolVersionInfo
 	ApplyID                 uint64
+	EcDataNum               int
+	EcParityNum             int
+	EcHosts                 []string
+	EnableDedup             bool
 }
 
 func (md *DataPartitionMetadata) Validate() (err error) {
@@ -138,6 +143,12 @@ type DataPartition struct {
 	recoverErrCnt              uint64 // donot reset, if reach max err cnt, delete this dp
 
 	diskErrCnt uint64 // number of disk io errors while reading or writing
+
+	// erasure coding of sealed extents, nil if the partition is replicated only
+	ecIndex   *ecIndex
+	ecEncoder ec.Encoder
+
+	tinyCompact *tinyCompactor
 }
 
 func (dp *DataPartition) IsForbidden() bool {
@@ -148,6 +159,22 @@ func (dp *DataPartition) SetForbidden(status bool) {
 	dp.config.Forbidden = status
 }
 
+// IsDedup returns true if extents of the partition may be shared by inodes,
+// such extents are never overwritten in place.
+func (dp *DataPartition) IsDedup() bool {
+	return dp.config.EnableDedup
+}
+
+// EnableDedup marks the partition as dedup, it is persisted since dedup
+// of volume can not be disabled.
+func (dp *DataPartition) EnableDedup() (err error) {
+	if dp.config.EnableDedup {
+		return
+	}
+	dp.config.EnableDedup = true
+	return dp.PersistMetadata()
+}
+
 func CreateDataPartition(dpCfg *dataPartitionCfg, disk *Disk, request *proto.CreateDataPartitionRequest) (dp *DataPartition, err error) {
 	if dp, err = newDataPartition(dpCfg, disk, true); err != nil {
 		return
@@ -242,6 +269,10 @@ func LoadDataPartition(partitionDir string, disk *Disk) (dp *DataPartition, err
 		ReplicaNum:    meta.ReplicaNum,
 		Peers:         meta.Peers,
 		Hosts:         meta.Hosts,
+		EcDataNum:     meta.EcDataNum,
+		EcParityNum:   meta.EcParityNum,
+		EcHosts:       meta.EcHosts,
+		EnableDedup:   meta.EnableDedup,
 		RaftStore:     disk.space.GetRaftStore(),
 		NodeID:        disk.space.GetNodeID(),
 		ClusterID:     disk.space.GetClusterID(),
@@ -249,6 +280,11 @@ func LoadDataPartition(partitionDir string, disk *Disk) (dp *DataPartition, err
 	if dp, err = newDataPartition(dpCfg, disk, false); err != nil {
 		return
 	}
+	// the writes acknowledged by the journal before raft replays its log
+	if err = dp.replayJournal(); err != nil {
+		log.LogErrorf("action[replayJournal] dp %v err %v", dp.partitionID, err)
+		return
+	}
 	dp.stopRecover = meta.StopRecover
 	dp.metaAppliedID = meta.ApplyID
 	dp.computeUsage()
@@ -316,6 +352,7 @@ func newDataPartition(dpCfg *dataPartitionCfg, disk *Disk, isCreate bool) (dp *D
 		verSeq:                  dpCfg.VerSeq,
 		DataPartitionCreateType: dpCfg.CreateType,
 		volVersionInfoList:      &proto.VolVersionInfoList{},
+		tinyCompact:             newTinyCompactor(),
 	}
 	atomic.StoreUint64(&partition.recoverErrCnt, 0)
 	log.LogInfof("action[newDataPartition] dp %v replica num %v", partitionID, dpCfg.ReplicaNum)
@@ -332,10 +369,20 @@ func newDataPartition(dpCfg *dataPartitionCfg, disk *Disk, isCreate bool) (dp *D
 			partition.partitionID, partition.appliedID, err)
 		return
 	}
+	if err = partition.initEc(); err != nil {
+		log.LogErrorf("action[newDataPartition] dp %v init erasure coding failed %v", partitionID, err)
+		return
+	}
 	disk.AttachDataPartition(partition)
 	dp = partition
 	go partition.statusUpdateScheduler()
 	go partition.startEvict()
+	if partition.isEcPartition() {
+		go partition.ecScheduler()
+	}
+	if partition.isNormalType() {
+		go partition.tinyCompactScheduler()
+	}
 	if isCreate {
 		if err = dp.getVerListFromMaster(); err != nil {
 			log.LogErrorf("action[newDataPartition] vol %v dp %v loadFromMaster verList failed err %v", dp.volumeID, dp.partitionID, err)
@@ -628,6 +675,10 @@ func (dp *DataPartition) PersistMetadata() (err error) {
 		StopRecover:             dp.stopRecover,
 		VerList:                 dp.volVersionInfoList.VerList,
 		ApplyID:                 dp.appliedID,
+		EcDataNum:               dp.config.EcDataNum,
+		EcParityNum:             dp.config.EcParityNum,
+		EcHosts:                 dp.config.EcHosts,
+		EnableDedup:             dp.config.EnableDedup,
 	}
 
 	if metaData, err = json.Marshal(md); err != nil {
@@ -948,6 +999,13 @@ func (dp *DataPartition) doStreamFixTinyDeleteRecord(repairTask *DataPartitionRe
 	if localTinyDeleteFileSize >= repairTask.LeaderTinyDeleteRecordFileSize {
 		return
 	}
+	// the offsets mean nothing across a tiny extent reset, the generations meet once the reset is done on all replicas
+	generation := dp.extentStore.TinyDeleteGeneration()
+	if generation != repairTask.LeaderTinyDeleteGeneration {
+		log.LogWarnf(ActionSyncTinyDeleteRecord+" skip PartitionID(%v) generation(%v) leaderGeneration(%v)",
+			dp.partitionID, generation, repairTask.LeaderTinyDeleteGeneration)
+		return
+	}
 
 	if repairTask.LeaderTinyDeleteRecordFileSize-localTinyDeleteFileSize < MinTinyExtentDeleteRecordSyncSize {
 		return
@@ -958,7 +1016,7 @@ func (dp *DataPartition) doStreamFixTinyDeleteRecord(repairTask *DataPartitionRe
 			dp.partitionID, localTinyDeleteFileSize, repairTask.LeaderTinyDeleteRecordFileSize, repairTask.LeaderAddr, err)
 	}()
 
-	p := repl.NewPacketToReadTinyDeleteRecord(dp.partitionID, localTinyDeleteFileSize)
+	p := repl.NewPacketToReadTinyDeleteRecord(dp.partitionID, localTinyDeleteFileSize, generation)
 	if conn, err = dp.getRepairConn(repairTask.LeaderAddr); err != nil {
 		return
 	}
@@ -0,0 +1,662 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/ec"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/repl"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// Erasure coding of hot data partitions.
+//
+// Extents are written through the replicas as usual. Once a normal extent is
+// full or has not been modified for a while, the leader seals it: the extent
+// is cut into stripes of EcDataNum units, every stripe is encoded with
+// EcParityNum parity units, and the units are written to the shard holders
+// (EcHosts). Writes to the extent are fenced while it is being sealed, and the
+// seal is applied through raft, so every replica records the extent in its ec
+// index and punches its data out in order with the random writes. Sealed
+// extents are read-only, they are served from the data shards, and
+// reconstructed from the remaining ones when shards are missing.
+const (
+	EcIndexFileName     = "EC_INDEX"
+	TempEcIndexFileName = ".ec_index"
+
+	ecStripeUnitSize   = util.MB
+	ecSealIdleTime     = 3600 // seconds an extent stays untouched before being sealed
+	ecScheduleInterval = 5 * time.Minute
+	ecSealBatchCount   = 16
+	ecCheckInterval    = 24 * 3600 // seconds between two checks of the shards of an extent
+	ecCheckBatchCount  = 16
+	ecShardTimeoutSec  = 30
+
+	ecWriteWaitInterval = 10 * time.Millisecond
+)
+
+var (
+	ErrEcExtentSealed   = errors.New("extent is sealed by erasure coding")
+	ErrEcShardNotEnough = errors.New("not enough erasure-coded shards")
+)
+
+// ecExtentInfo describes a sealed extent.
+type ecExtentInfo struct {
+	ExtentID uint64     `json:"eid"`
+	Size     int64      `json:"size"`
+	Crcs     [][]uint32 `json:"crcs"` // crc of the units, indexed by stripe then shard
+	SealTime int64      `json:"seal"`
+}
+
+type ecIndex struct {
+	sync.RWMutex
+	dir     string
+	extents map[uint64]*ecExtentInfo
+	checked map[uint64]int64 // last time the shards of an extent were checked
+
+	sealing map[uint64]bool         // extents being sealed, new writes are refused
+	writing map[uint64]int          // writes in progress of an extent
+	writes  map[*repl.Packet]uint64 // extent of the write packets in progress
+}
+
+func loadEcIndex(dir string) (idx *ecIndex, err error) {
+	idx = &ecIndex{
+		dir:     dir,
+		extents: make(map[uint64]*ecExtentInfo),
+		checked: make(map[uint64]int64),
+		sealing: make(map[uint64]bool),
+		writing: make(map[uint64]int),
+		writes:  make(map[*repl.Packet]uint64),
+	}
+	data, err := os.ReadFile(path.Join(dir, EcIndexFileName))
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return
+	}
+	infos := make([]*ecExtentInfo, 0)
+	if err = json.Unmarshal(data, &infos); err != nil {
+		return
+	}
+	for _, info := range infos {
+		idx.extents[info.ExtentID] = info
+	}
+	return
+}
+
+func (idx *ecIndex) get(extentID uint64) *ecExtentInfo {
+	idx.RLock()
+	defer idx.RUnlock()
+	return idx.extents[extentID]
+}
+
+func (idx *ecIndex) list() (infos []*ecExtentInfo) {
+	idx.RLock()
+	defer idx.RUnlock()
+	infos = make([]*ecExtentInfo, 0, len(idx.extents))
+	for _, info := range idx.extents {
+		infos = append(infos, info)
+	}
+	return
+}
+
+func (idx *ecIndex) add(info *ecExtentInfo) error {
+	idx.Lock()
+	defer idx.Unlock()
+	idx.extents[info.ExtentID] = info
+	return idx.persist()
+}
+
+func (idx *ecIndex) remove(extentID uint64) (info *ecExtentInfo, err error) {
+	idx.Lock()
+	defer idx.Unlock()
+	if info = idx.extents[extentID]; info == nil {
+		return
+	}
+	delete(idx.extents, extentID)
+	delete(idx.checked, extentID)
+	err = idx.persist()
+	return
+}
+
+func (idx *ecIndex) needCheck(extentID uint64, now int64) bool {
+	idx.RLock()
+	defer idx.RUnlock()
+	return now-idx.checked[extentID] >= ecCheckInterval
+}
+
+func (idx *ecIndex) setChecked(extentID uint64, now int64) {
+	idx.Lock()
+	defer idx.Unlock()
+	if _, ok := idx.extents[extentID]; ok {
+		idx.checked[extentID] = now
+	}
+}
+
+// resetChecked makes the shards of all extents checked again.
+func (idx *ecIndex) resetChecked() {
+	idx.Lock()
+	defer idx.Unlock()
+	idx.checked = make(map[uint64]int64)
+}
+
+// beginWrite refuses the write if the extent is sealed or being sealed,
+// otherwise the write is counted until endWrite.
+func (idx *ecIndex) beginWrite(p *repl.Packet) error {
+	idx.Lock()
+	defer idx.Unlock()
+	if idx.extents[p.ExtentID] != nil || idx.sealing[p.ExtentID] {
+		return ErrEcExtentSealed
+	}
+	if _, ok := idx.writes[p]; !ok {
+		idx.writes[p] = p.ExtentID
+		idx.writing[p.ExtentID]++
+	}
+	return nil
+}
+
+func (idx *ecIndex) endWrite(p *repl.Packet) {
+	idx.Lock()
+	defer idx.Unlock()
+	extentID, ok := idx.writes[p]
+	if !ok {
+		return
+	}
+	delete(idx.writes, p)
+	idx.writing[extentID]--
+	if idx.writing[extentID] <= 0 {
+		delete(idx.writing, extentID)
+	}
+}
+
+// beginSeal fences the new writes of the extent.
+func (idx *ecIndex) beginSeal(extentID uint64) bool {
+	idx.Lock()
+	defer idx.Unlock()
+	if idx.extents[extentID] != nil || idx.sealing[extentID] {
+		return false
+	}
+	idx.sealing[extentID] = true
+	return true
+}
+
+func (idx *ecIndex) endSeal(extentID uint64) {
+	idx.Lock()
+	defer idx.Unlock()
+	delete(idx.sealing, extentID)
+}
+
+// waitWrites waits for the writes of the extent accepted before the fence.
+func (idx *ecIndex) waitWrites(extentID uint64, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		idx.RLock()
+		n := idx.writing[extentID]
+		idx.RUnlock()
+		if n == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("extent %v still has %v writes in progress", extentID, n)
+		}
+		time.Sleep(ecWriteWaitInterval)
+	}
+}
+
+func (idx *ecIndex) persist() (err error) {
+	infos := make([]*ecExtentInfo, 0, len(idx.extents))
+	for _, info := range idx.extents {
+		infos = append(infos, info)
+	}
+	data, err := json.Marshal(infos)
+	if err != nil {
+		return
+	}
+	tmpFile := path.Join(idx.dir, TempEcIndexFileName)
+	f, err := os.OpenFile(tmpFile, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o666)
+	if err != nil {
+		return
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	f.Close()
+	if err != nil {
+		os.Remove(tmpFile)
+		return
+	}
+	return os.Rename(tmpFile, path.Join(idx.dir, EcIndexFileName))
+}
+
+func newEcEncoder(dataNum, parityNum int) (ec.Encoder, error) {
+	return ec.NewEncoder(ec.Config{CodeMode: codemode.Tactic{
+		N:         dataNum,
+		M:         parityNum,
+		AZCount:   1,
+		PutQuorum: dataNum + parityNum,
+	}})
+}
+
+func (dp *DataPartition) isEcPartition() bool {
+	return dp.config.EcDataNum > 0
+}
+
+func (dp *DataPartition) initEc() (err error) {
+	if !dp.isEcPartition() {
+		return
+	}
+	if len(dp.config.EcHosts) != dp.config.EcDataNum+dp.config.EcParityNum {
+		return fmt.Errorf("ec hosts %v mismatch with ec %v+%v",
+			dp.config.EcHosts, dp.config.EcDataNum, dp.config.EcParityNum)
+	}
+	if dp.ecEncoder, err = newEcEncoder(dp.config.EcDataNum, dp.config.EcParityNum); err != nil {
+		return
+	}
+	dp.ecIndex, err = loadEcIndex(dp.path)
+	return
+}
+
+func (dp *DataPartition) isEcExtent(extentID uint64) bool {
+	if dp.ecIndex == nil || storage.IsTinyExtent(extentID) {
+		return false
+	}
+	return dp.ecIndex.get(extentID) != nil
+}
+
+// isEcWrite returns true if the packet modifies a normal extent of the erasure-coded partition.
+func (dp *DataPartition) isEcWrite(p *repl.Packet) bool {
+	if dp.ecIndex == nil || storage.IsTinyExtent(p.ExtentID) {
+		return false
+	}
+	return p.IsNormalWriteOperation() || p.IsRandomWrite() || p.IsSnapshotModWriteAppendOperation() ||
+		p.Opcode == proto.OpTryWriteAppend || p.Opcode == proto.OpSyncTryWriteAppend
+}
+
+func (dp *DataPartition) ecStripeSize() int64 {
+	return int64(dp.config.EcDataNum) * ecStripeUnitSize
+}
+
+func (dp *DataPartition) ecScheduler() {
+	ticker := time.NewTicker(ecScheduleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-dp.stopC:
+			return
+		case <-ticker.C:
+			if !dp.isLeader || dp.partitionStatus == proto.Unavailable {
+				continue
+			}
+			dp.sealEcExtents()
+			dp.checkEcShards()
+		}
+	}
+}
+
+// sealEcExtents seals the extents which are full or idle.
+func (dp *DataPartition) sealEcExtents() {
+	extents, _, err := dp.extentStore.GetAllWatermarks(storage.NormalExtentFilter())
+	if err != nil {
+		log.LogWarnf("action[sealEcExtents] dp %v get watermarks err %v", dp.partitionID, err)
+		return
+	}
+	now := time.Now().Unix()
+	sealed := 0
+	for _, ei := range extents {
+		if sealed >= ecSealBatchCount {
+			return
+		}
+		if ei.Size == 0 || ei.SnapshotDataOff > util.ExtentSize || dp.isEcExtent(ei.FileID) {
+			continue
+		}
+		if ei.Size < util.ExtentSize && now-ei.ModifyTime < ecSealIdleTime {
+			continue
+		}
+		if err = dp.sealEcExtent(ei.FileID); err != nil {
+			log.LogWarnf("action[sealEcExtents] dp %v extent %v size %v err %v", dp.partitionID, ei.FileID, ei.Size, err)
+			continue
+		}
+		sealed++
+	}
+}
+
+// sealEcExtent writes the full stripes of the extent to the shard holders,
+// then seals the extent on every replica through raft.
+func (dp *DataPartition) sealEcExtent(extentID uint64) (err error) {
+	if !dp.ecIndex.beginSeal(extentID) {
+		return fmt.Errorf("extent %v is sealed or being sealed", extentID)
+	}
+	defer dp.ecIndex.endSeal(extentID)
+	// the writes accepted before the fence are replied after all replicas have written them
+	if err = dp.ecIndex.waitWrites(extentID, ecShardTimeoutSec*time.Second); err != nil {
+		return
+	}
+	ei, err := dp.extentStore.Watermark(extentID)
+	if err != nil {
+		return
+	}
+	size := int64(ei.Size)
+
+	dataNum, parityNum := dp.config.EcDataNum, dp.config.EcParityNum
+	stripeSize := dp.ecStripeSize()
+	stripes := int((size + stripeSize - 1) / stripeSize)
+	info := &ecExtentInfo{
+		ExtentID: extentID,
+		Size:     size,
+		Crcs:     make([][]uint32, stripes),
+		SealTime: time.Now().Unix(),
+	}
+
+	buf := make([]byte, stripeSize)
+	shards := make([][]byte, dataNum+parityNum)
+	for i := 0; i < dataNum; i++ {
+		shards[i] = buf[i*ecStripeUnitSize : (i+1)*ecStripeUnitSize]
+	}
+	for i := dataNum; i < len(shards); i++ {
+		shards[i] = make([]byte, ecStripeUnitSize)
+	}
+	for s := 0; s < stripes; s++ {
+		offset := int64(s) * stripeSize
+		readSize := util.Min(int(stripeSize), int(size-offset))
+		for i := readSize; i < len(buf); i++ {
+			buf[i] = 0
+		}
+		dp.disk.limitRead.Run(readSize, func() {
+			_, err = dp.extentStore.Read(extentID, offset, int64(readSize), buf[:readSize], false)
+		})
+		if err != nil {
+			return
+		}
+		if err = dp.ecEncoder.Encode(shards); err != nil {
+			return
+		}
+		info.Crcs[s] = make([]uint32, len(shards))
+		for i, shard := range shards {
+			info.Crcs[s][i] = crc32.ChecksumIEEE(shard)
+			if err = dp.writeEcShard(i, extentID, int64(s)*ecStripeUnitSize, shard); err != nil {
+				return
+			}
+		}
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+	cmd, err := MarshalRaftCmd(&RaftCmdItem{Op: uint32(proto.OpEcExtentSeal), V: data})
+	if err != nil {
+		return
+	}
+	resp, err := dp.Put(nil, cmd)
+	if err != nil {
+		return
+	}
+	if status, _ := resp.(uint8); status != proto.OpOk {
+		return fmt.Errorf("apply seal of extent %v status %v", extentID, status)
+	}
+	log.LogInfof("action[sealEcExtent] dp %v extent %v size %v stripes %v sealed", dp.partitionID, extentID, size, stripes)
+	return
+}
+
+// fsmEcSeal applies the seal proposed by the leader, the replica keeps the
+// data of the extent if it can not be sealed.
+func (dp *DataPartition) fsmEcSeal(data []byte, index uint64) (status uint8) {
+	info := &ecExtentInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		log.LogErrorf("action[fsmEcSeal] dp %v apply id %v unmarshal err %v", dp.partitionID, index, err)
+		return proto.OpErr
+	}
+	if err := dp.applyEcSeal(info); err != nil {
+		log.LogWarnf("action[fsmEcSeal] dp %v apply id %v extent %v err %v", dp.partitionID, index, info.ExtentID, err)
+		return proto.OpErr
+	}
+	return proto.OpOk
+}
+
+// updateEcHosts replaces the shard holders, the shards missing on the new
+// holders are rebuilt by the next checks.
+func (dp *DataPartition) updateEcHosts(hosts []string) (err error) {
+	if !dp.isEcPartition() {
+		return fmt.Errorf("dp %v is not erasure coded", dp.partitionID)
+	}
+	if len(hosts) != dp.config.EcDataNum+dp.config.EcParityNum {
+		return fmt.Errorf("ec hosts %v mismatch with ec %v+%v", hosts, dp.config.EcDataNum, dp.config.EcParityNum)
+	}
+	dp.config.EcHosts = append([]string(nil), hosts...)
+	if err = dp.PersistMetadata(); err != nil {
+		return
+	}
+	dp.ecIndex.resetChecked()
+	return
+}
+
+// applyEcSeal records the sealed extent and releases its local data.
+func (dp *DataPartition) applyEcSeal(info *ecExtentInfo) (err error) {
+	if dp.ecIndex == nil {
+		return fmt.Errorf("dp %v is not erasure coded", dp.partitionID)
+	}
+	if dp.isEcExtent(info.ExtentID) {
+		return
+	}
+	ei, err := dp.extentStore.Watermark(info.ExtentID)
+	if err != nil {
+		return
+	}
+	if int64(ei.Size) != info.Size {
+		return fmt.Errorf("extent %v size %v mismatch with sealed size %v", info.ExtentID, ei.Size, info.Size)
+	}
+	if err = dp.ecIndex.add(info); err != nil {
+		return
+	}
+	dp.disk.limitWrite.Run(0, func() {
+		if perr := dp.extentStore.PunchExtentData(info.ExtentID); perr != nil {
+			log.LogWarnf("action[applyEcSeal] dp %v extent %v punch err %v", dp.partitionID, info.ExtentID, perr)
+		}
+	})
+	return
+}
+
+// releaseEcExtent forgets an extent deleted from the store, the leader also
+// removes its shards.
+func (dp *DataPartition) releaseEcExtent(extentID uint64) {
+	if !dp.isEcExtent(extentID) || dp.extentStore.HasExtent(extentID) {
+		return
+	}
+	info, err := dp.ecIndex.remove(extentID)
+	if err != nil {
+		log.LogWarnf("action[releaseEcExtent] dp %v extent %v err %v", dp.partitionID, extentID, err)
+	}
+	if info == nil || !dp.isLeader {
+		return
+	}
+	go func() {
+		for i := range dp.config.EcHosts {
+			if err := dp.deleteEcShard(i, extentID); err != nil {
+				log.LogWarnf("action[releaseEcExtent] dp %v extent %v shard %v err %v", dp.partitionID, extentID, i, err)
+			}
+		}
+	}()
+}
+
+// ecRead reads the data of a sealed extent from its data shards.
+func (dp *DataPartition) ecRead(extentID uint64, offset, size int64, data []byte) (crc uint32, err error) {
+	info := dp.ecIndex.get(extentID)
+	if info == nil {
+		return 0, storage.ExtentNotFoundError
+	}
+	if offset < 0 || offset+size > info.Size {
+		return 0, storage.ParameterMismatchError
+	}
+	stripeSize := dp.ecStripeSize()
+	for pos := int64(0); pos < size; {
+		cur := offset + pos
+		stripe, inStripe := cur/stripeSize, cur%stripeSize
+		index := int(inStripe / ecStripeUnitSize)
+		unitOffset := inStripe % ecStripeUnitSize
+		length := util.Min(int(ecStripeUnitSize-unitOffset), int(size-pos))
+		if err = dp.readEcUnit(extentID, index, stripe*ecStripeUnitSize+unitOffset, data[pos:pos+int64(length)]); err != nil {
+			return
+		}
+		pos += int64(length)
+	}
+	crc = crc32.ChecksumIEEE(data[:size])
+	return
+}
+
+// readEcUnit reads a range of a data shard, the range is rebuilt from the
+// other shards at the same offset if the shard is not available.
+func (dp *DataPartition) readEcUnit(extentID uint64, index int, offset int64, data []byte) (err error) {
+	if err = dp.readEcShard(index, extentID, offset, data); err == nil {
+		return
+	}
+	log.LogWarnf("action[readEcUnit] dp %v extent %v shard %v offset %v degraded read, err %v",
+		dp.partitionID, extentID, index, offset, err)
+
+	dataNum := dp.config.EcDataNum
+	shards := make([][]byte, dataNum+dp.config.EcParityNum)
+	bad := []int{index}
+	got := 0
+	for i := range shards {
+		if i == index {
+			continue
+		}
+		if got >= dataNum {
+			bad = append(bad, i)
+			continue
+		}
+		shard := make([]byte, len(data))
+		if rerr := dp.readEcShard(i, extentID, offset, shard); rerr != nil {
+			bad = append(bad, i)
+			continue
+		}
+		shards[i] = shard
+		got++
+	}
+	if got < dataNum {
+		return ErrEcShardNotEnough
+	}
+	if err = dp.ecEncoder.ReconstructData(shards, bad); err != nil {
+		return
+	}
+	copy(data, shards[index])
+	return
+}
+
+// checkEcShards verifies the shards of the sealed extents, and rebuilds the
+// missing or corrupted ones.
+func (dp *DataPartition) checkEcShards() {
+	now := time.Now().Unix()
+	checked := 0
+	for _, info := range dp.ecIndex.list() {
+		if checked >= ecCheckBatchCount {
+			return
+		}
+		if !dp.ecIndex.needCheck(info.ExtentID, now) {
+			continue
+		}
+		checked++
+		if err := dp.repairEcExtent(info); err != nil {
+			log.LogWarnf("action[checkEcShards] dp %v extent %v err %v", dp.partitionID, info.ExtentID, err)
+			continue
+		}
+		dp.ecIndex.setChecked(info.ExtentID, now)
+	}
+}
+
+func (dp *DataPartition) repairEcExtent(info *ecExtentInfo) (err error) {
+	parityNum := dp.config.EcParityNum
+	for s, crcs := range info.Crcs {
+		offset := int64(s) * ecStripeUnitSize
+		shards := make([][]byte, len(crcs))
+		bad := make([]int, 0)
+		for i := range shards {
+			shard := make([]byte, ecStripeUnitSize)
+			if rerr := dp.readEcShard(i, info.ExtentID, offset, shard); rerr != nil || crc32.ChecksumIEEE(shard) != crcs[i] {
+				bad = append(bad, i)
+				continue
+			}
+			shards[i] = shard
+		}
+		if len(bad) == 0 {
+			continue
+		}
+		if len(bad) > parityNum {
+			return ErrEcShardNotEnough
+		}
+		if err = dp.ecEncoder.Reconstruct(shards, bad); err != nil {
+			return
+		}
+		for _, i := range bad {
+			if err = dp.writeEcShard(i, info.ExtentID, offset, shards[i]); err != nil {
+				return
+			}
+		}
+		log.LogInfof("action[repairEcExtent] dp %v extent %v stripe %v rebuilt shards %v",
+			dp.partitionID, info.ExtentID, s, bad)
+	}
+	return
+}
+
+func (dp *DataPartition) writeEcShard(index int, extentID uint64, offset int64, data []byte) error {
+	host := dp.config.EcHosts[index]
+	if host == dp.dataNode.localServerAddr {
+		return dp.dataNode.space.WriteEcShard(dp.partitionID, extentID, index, offset, data)
+	}
+	p := NewPacketToEcShard(proto.OpEcShardWrite, dp.partitionID, extentID, index)
+	p.ExtentOffset = offset
+	p.Data = data
+	p.Size = uint32(len(data))
+	p.CRC = crc32.ChecksumIEEE(data)
+	return sendEcPacket(p, host)
+}
+
+func (dp *DataPartition) readEcShard(index int, extentID uint64, offset int64, data []byte) (err error) {
+	host := dp.config.EcHosts[index]
+	if host == dp.dataNode.localServerAddr {
+		return dp.dataNode.space.ReadEcShard(dp.partitionID, extentID, index, offset, data)
+	}
+	p := NewPacketToEcShard(proto.OpEcShardRead, dp.partitionID, extentID, index)
+	p.ExtentOffset = offset
+	p.Size = uint32(len(data))
+	if err = sendEcPacket(p, host); err != nil {
+		return
+	}
+	if int(p.Size) != len(data) || crc32.ChecksumIEEE(p.Data[:p.Size]) != p.CRC {
+		return fmt.Errorf("ec shard %v of extent %v from %v is broken", index, extentID, host)
+	}
+	copy(data, p.Data)
+	return
+}
+
+func (dp *DataPartition) deleteEcShard(index int, extentID uint64) error {
+	host := dp.config.EcHosts[index]
+	if host == dp.dataNode.localServerAddr {
+		return dp.dataNode.space.DeleteEcShard(dp.partitionID, extentID, index)
+	}
+	return sendEcPacket(NewPacketToEcShard(proto.OpEcShardDelete, dp.partitionID, extentID, index), host)
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/repl"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEcIndex(t *testing.T) {
+	dir := t.TempDir()
+	idx, err := loadEcIndex(dir)
+	require.NoError(t, err)
+	require.Len(t, idx.list(), 0)
+
+	info := &ecExtentInfo{ExtentID: 1025, Size: 4096, Crcs: [][]uint32{{1, 2, 3}}}
+	require.NoError(t, idx.add(info))
+	require.NoError(t, idx.add(&ecExtentInfo{ExtentID: 1026, Size: 8192}))
+	require.True(t, idx.needCheck(1025, ecCheckInterval))
+	idx.setChecked(1025, ecCheckInterval)
+	require.False(t, idx.needCheck(1025, ecCheckInterval))
+
+	loaded, err := loadEcIndex(dir)
+	require.NoError(t, err)
+	require.Len(t, loaded.list(), 2)
+	require.Equal(t, info, loaded.get(1025))
+
+	removed, err := loaded.remove(1025)
+	require.NoError(t, err)
+	require.Equal(t, info, removed)
+	removed, err = loaded.remove(1025)
+	require.NoError(t, err)
+	require.Nil(t, removed)
+
+	loaded, err = loadEcIndex(dir)
+	require.NoError(t, err)
+	require.Nil(t, loaded.get(1025))
+	require.NotNil(t, loaded.get(1026))
+}
+
+// Writes are refused once the extent is being sealed, and the seal waits for the accepted ones.
+func TestEcIndexWriteFence(t *testing.T) {
+	idx, err := loadEcIndex(t.TempDir())
+	require.NoError(t, err)
+
+	p := repl.NewPacket()
+	p.ExtentID = 1025
+	require.NoError(t, idx.beginWrite(p))
+	require.NoError(t, idx.beginWrite(p))
+	require.True(t, idx.beginSeal(1025))
+	require.False(t, idx.beginSeal(1025))
+
+	other := repl.NewPacket()
+	other.ExtentID = 1025
+	require.Equal(t, ErrEcExtentSealed, idx.beginWrite(other))
+	require.Error(t, idx.waitWrites(1025, 50*time.Millisecond))
+
+	idx.endWrite(p)
+	idx.endWrite(p)
+	idx.endWrite(other)
+	require.NoError(t, idx.waitWrites(1025, time.Second))
+
+	require.NoError(t, idx.add(&ecExtentInfo{ExtentID: 1025, Size: 4096}))
+	idx.endSeal(1025)
+	require.Equal(t, ErrEcExtentSealed, idx.beginWrite(other))
+	require.False(t, idx.beginSeal(1025))
+}
+
+// A range of a data unit can be rebuilt from the same range of the other units.
+func TestEcUnitRangeReconstruct(t *testing.T) {
+	const unitSize = 4096
+	dataNum, parityNum := 4, 2
+	encoder, err := newEcEncoder(dataNum, parityNum)
+	require.NoError(t, err)
+
+	shards := make([][]byte, dataNum+parityNum)
+	for i := range shards {
+		shards[i] = make([]byte, unitSize)
+		if i < dataNum {
+			_, err = rand.Read(shards[i])
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, encoder.Encode(shards))
+
+	offset, length := 1000, 1500
+	lost := 1
+	sub := make([][]byte, len(shards))
+	bad := []int{lost, 3}
+	for i := range shards {
+		if i == lost || i == 3 {
+			continue
+		}
+		sub[i] = append([]byte(nil), shards[i][offset:offset+length]...)
+	}
+	require.NoError(t, encoder.ReconstructData(sub, bad))
+	require.Equal(t, shards[lost][offset:offset+length], sub[lost])
+	require.Equal(t, shards[3][offset:offset+length], sub[3])
+}
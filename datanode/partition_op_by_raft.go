@@ -252,6 +252,15 @@ func (dp *DataPartition) ApplyRandomWrite(command []byte, raftApplyID uint64) (r
 	}
 	log.LogDebugf("[ApplyRandomWrite] ApplyID(%v) Partition(%v)_Extent(%v)_ExtentOffset(%v)_Size(%v)",
 		raftApplyID, dp.partitionID, opItem.extentID, opItem.offset, opItem.size)
+	// sealed by an entry applied before, the data of the extent has been punched out
+	if dp.isEcExtent(opItem.extentID) {
+		log.LogWarnf("[ApplyRandomWrite] ApplyID(%v) Partition(%v)_Extent(%v) is sealed", raftApplyID, dp.partitionID, opItem.extentID)
+		respStatus = proto.OpArgMismatchErr
+		if opItem.opcode == proto.OpTryWriteAppend || opItem.opcode == proto.OpSyncTryWriteAppend {
+			respStatus = proto.OpTryOtherExtent
+		}
+		return
+	}
 
 	for i := 0; i < 20; i++ {
 		dp.disk.allocCheckLimit(proto.FlowWriteType, uint32(opItem.size))
@@ -276,7 +285,7 @@ func (dp *DataPartition) ApplyRandomWrite(command []byte, raftApplyID uint64) (r
 		}
 
 		dp.disk.limitWrite.Run(int(opItem.size), func() {
-			respStatus, err = dp.ExtentStore().Write(opItem.extentID, opItem.offset, opItem.size, opItem.data, opItem.crc, writeType, syncWrite)
+			respStatus, err = dp.journalWrite(opItem.extentID, opItem.offset, opItem.size, opItem.data, opItem.crc, writeType, syncWrite)
 		})
 		if err == nil {
 			break
@@ -49,6 +49,10 @@ type dataPartitionCfg struct {
 	VerSeq        uint64 `json:"ver_seq"`
 	CreateType    int
 	Forbidden     bool
+	EcDataNum     int      `json:"ec_data_num"`
+	EcParityNum   int      `json:"ec_parity_num"`
+	EcHosts       []string `json:"ec_hosts"`
+	EnableDedup   bool     `json:"enable_dedup"`
 }
 
 func (dp *DataPartition) raftPort() (heartbeat, replica int, err error) {
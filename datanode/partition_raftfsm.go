@@ -51,6 +51,10 @@ func (dp *DataPartition) Apply(command []byte, index uint64) (resp interface{},
 			dp.fsmVersionOp(opItem)
 			return
 		}
+		if opItem.Op == uint32(proto.OpEcExtentSeal) {
+			resp = dp.fsmEcSeal(opItem.V, index)
+			return
+		}
 		return
 	}
 	if index > dp.metaAppliedID {
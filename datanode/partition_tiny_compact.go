@@ -0,0 +1,371 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/depends/tiglabs/raft"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/repl"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// Tiny extent compaction. The leader picks the tiny extents whose data is
+// mostly punched and stops appending to them. The meta nodes find the keys
+// still pointing at these extents and ask the leader to relocate them, the
+// live range is copied into another tiny extent and the key is swapped by the
+// meta partition. Once nothing is left on the leader the extent is truncated
+// on every replica and its records are dropped from the tiny delete file.
+const (
+	tinyCompactInterval     = 10 * time.Minute
+	tinyCompactMinSize      = 64 * util.MB
+	tinyCompactGarbageRatio = 0.7
+	tinyCompactMaxExtents   = 8
+	tinyCompactTimeout      = 24 * time.Hour
+	tinyCompactTimeoutSec   = 30
+)
+
+const (
+	ActionTinyExtentRelocate = "ActionTinyExtentRelocate"
+	ActionTinyExtentReset    = "ActionTinyExtentReset"
+)
+
+var ErrTinyExtentNotCompacting = fmt.Errorf("tiny extent is not compacting")
+
+type tinyCompactor struct {
+	sync.RWMutex
+	extents   map[uint64]int64 // extent id -> compaction start time
+	reclaimed uint64
+}
+
+func newTinyCompactor() *tinyCompactor {
+	return &tinyCompactor{extents: make(map[uint64]int64)}
+}
+
+func (c *tinyCompactor) isCompacting(extentID uint64) bool {
+	c.RLock()
+	defer c.RUnlock()
+	_, ok := c.extents[extentID]
+	return ok
+}
+
+func (c *tinyCompactor) list() (ids []uint64) {
+	c.RLock()
+	defer c.RUnlock()
+	ids = make([]uint64, 0, len(c.extents))
+	for id := range c.extents {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return
+}
+
+func (c *tinyCompactor) reclaimedSize() uint64 {
+	return atomic.LoadUint64(&c.reclaimed)
+}
+
+// tinyCompactingExtents returns the tiny extents under compaction, it is
+// reported to the master by the leader only.
+func (dp *DataPartition) tinyCompactingExtents() []uint64 {
+	if !dp.isLeader {
+		return nil
+	}
+	return dp.tinyCompact.list()
+}
+
+func (dp *DataPartition) tinyCompactScheduler() {
+	ticker := time.NewTicker(tinyCompactInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-dp.stopC:
+			return
+		case <-ticker.C:
+			if !dp.isLeader || dp.partitionStatus == proto.Unavailable {
+				dp.releaseTinyCompacting()
+				continue
+			}
+			dp.finishTinyCompaction()
+			dp.startTinyCompaction()
+		}
+	}
+}
+
+// startTinyCompaction reserves the fragmented tiny extents.
+func (dp *DataPartition) startTinyCompaction() {
+	store := dp.extentStore
+	c := dp.tinyCompact
+	for id := uint64(storage.TinyExtentStartID); id < storage.TinyExtentStartID+storage.TinyExtentCount; id++ {
+		c.RLock()
+		_, ok := c.extents[id]
+		cnt := len(c.extents)
+		c.RUnlock()
+		if cnt >= tinyCompactMaxExtents {
+			return
+		}
+		if ok {
+			continue
+		}
+		size, used, err := store.TinyExtentUsage(id)
+		if err != nil || size < tinyCompactMinSize || float64(size-used) < float64(size)*tinyCompactGarbageRatio {
+			continue
+		}
+		if !store.ReserveTinyExtent(id) {
+			continue
+		}
+		c.Lock()
+		c.extents[id] = time.Now().Unix()
+		c.Unlock()
+		log.LogInfof("action[startTinyCompaction] dp %v extent %v size %v used %v", dp.partitionID, id, size, used)
+	}
+}
+
+// finishTinyCompaction resets the tiny extents whose data has all been moved.
+func (dp *DataPartition) finishTinyCompaction() {
+	store := dp.extentStore
+	c := dp.tinyCompact
+	now := time.Now().Unix()
+	for _, id := range c.list() {
+		_, used, err := store.TinyExtentUsage(id)
+		if err != nil {
+			continue
+		}
+		if used > 0 {
+			c.RLock()
+			start := c.extents[id]
+			c.RUnlock()
+			if now-start > int64(tinyCompactTimeout/time.Second) {
+				log.LogWarnf("action[finishTinyCompaction] dp %v extent %v timeout, used %v", dp.partitionID, id, used)
+				dp.releaseTinyExtent(id)
+			}
+			continue
+		}
+		if err = dp.resetTinyExtentOnReplicas(id); err != nil {
+			log.LogWarnf("action[finishTinyCompaction] dp %v extent %v err %v", dp.partitionID, id, err)
+			continue
+		}
+		dp.releaseTinyExtent(id)
+	}
+}
+
+func (dp *DataPartition) releaseTinyExtent(extentID uint64) {
+	c := dp.tinyCompact
+	c.Lock()
+	delete(c.extents, extentID)
+	c.Unlock()
+	dp.extentStore.SendToAvailableTinyExtentC(extentID)
+}
+
+// releaseTinyCompacting gives the reserved extents back once the replica is no longer the leader.
+func (dp *DataPartition) releaseTinyCompacting() {
+	for _, id := range dp.tinyCompact.list() {
+		dp.releaseTinyExtent(id)
+	}
+}
+
+func (dp *DataPartition) resetTinyExtentOnReplicas(extentID uint64) (err error) {
+	// every attempt takes a new generation, the replicas reset by a failed
+	// attempt don't sync delete records with the others until the next one
+	generation := uint64(time.Now().UnixNano())
+	if local := dp.extentStore.TinyDeleteGeneration(); generation <= local {
+		generation = local + 1
+	}
+	// the followers first, the leader keeps the extent reserved until all replicas are reset
+	for _, host := range dp.getReplicaCopy() {
+		if host == dp.dataNode.localServerAddr {
+			continue
+		}
+		if err = sendTinyCompactPacket(NewPacketToTinyExtentReset(dp.partitionID, extentID, generation), host); err != nil {
+			return
+		}
+	}
+	return dp.resetTinyExtent(extentID, generation)
+}
+
+func (dp *DataPartition) resetTinyExtent(extentID, generation uint64) (err error) {
+	// the journal must not write back the old data into the extent
+	if err = dp.checkpointJournal(); err != nil {
+		return
+	}
+	released, err := dp.extentStore.ResetTinyExtent(extentID, generation)
+	if err != nil {
+		return
+	}
+	atomic.AddUint64(&dp.tinyCompact.reclaimed, uint64(released))
+	log.LogInfof("action[resetTinyExtent] dp %v extent %v generation %v released %v", dp.partitionID, extentID, generation, released)
+	return
+}
+
+// relocateTinyExtentKey copies the range of the key into another tiny extent
+// on all replicas and returns the new key.
+func (dp *DataPartition) relocateTinyExtentKey(ek *proto.ExtentKey) (newEk *proto.ExtentKey, err error) {
+	if !dp.tinyCompact.isCompacting(ek.ExtentId) {
+		return nil, ErrTinyExtentNotCompacting
+	}
+
+	store := dp.extentStore
+	data := make([]byte, ek.Size)
+	crc, err := store.Read(ek.ExtentId, int64(ek.ExtentOffset), int64(ek.Size), data, false)
+	if err != nil {
+		return
+	}
+
+	// the target is taken out of the available channel, nothing else appends to it meanwhile
+	target, err := store.GetAvailableTinyExtent()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			store.SendToBrokenTinyExtentC(target)
+		} else {
+			store.SendToAvailableTinyExtentC(target)
+		}
+	}()
+	offset, err := store.GetTinyExtentOffset(target)
+	if err != nil {
+		return
+	}
+	if _, err = store.Write(target, offset, int64(ek.Size), data, crc, storage.AppendWriteType, true); err != nil {
+		return
+	}
+	for _, host := range dp.getReplicaCopy() {
+		if host == dp.dataNode.localServerAddr {
+			continue
+		}
+		p := dp.newPacketToTinyRelocateWrite(target, offset, data, crc)
+		if err = sendTinyCompactPacket(p, host); err != nil {
+			// the copy is referenced by nobody
+			store.MarkDelete(target, offset, int64(ek.Size))
+			return
+		}
+	}
+
+	newEk = &proto.ExtentKey{
+		FileOffset:   ek.FileOffset,
+		PartitionId:  ek.PartitionId,
+		ExtentId:     target,
+		ExtentOffset: uint64(offset),
+		Size:         ek.Size,
+	}
+	log.LogDebugf("action[relocateTinyExtentKey] dp %v ek %v relocated to %v", dp.partitionID, ek, newEk)
+	return
+}
+
+func (dp *DataPartition) newPacketToTinyRelocateWrite(extentID uint64, offset int64, data []byte, crc uint32) (p *repl.Packet) {
+	p = new(repl.Packet)
+	p.Opcode = proto.OpSyncWrite
+	p.ExtentType = proto.TinyExtentType
+	p.PartitionID = dp.partitionID
+	p.ExtentID = extentID
+	p.ExtentOffset = offset
+	p.Magic = proto.ProtoMagic
+	p.ReqID = proto.GenerateRequestID()
+	p.VerSeq = atomic.LoadUint64(&dp.verSeq)
+	p.Data = data
+	p.Size = uint32(len(data))
+	p.CRC = crc
+	return
+}
+
+func NewPacketToTinyExtentReset(partitionID, extentID, generation uint64) (p *repl.Packet) {
+	p = new(repl.Packet)
+	p.Opcode = proto.OpTinyExtentReset
+	p.ExtentType = proto.TinyExtentType
+	p.PartitionID = partitionID
+	p.ExtentID = extentID
+	p.KernelOffset = generation // the new generation of the tiny delete file
+	p.Magic = proto.ProtoMagic
+	p.ReqID = proto.GenerateRequestID()
+	return
+}
+
+// Handle OpTinyExtentRelocate packet.
+func (s *DataNode) handleTinyExtentRelocatePacket(p *repl.Packet) {
+	var err error
+	defer func() {
+		if err != nil {
+			p.PackErrorBody(ActionTinyExtentRelocate, err.Error())
+		}
+	}()
+	partition := p.Object.(*DataPartition)
+	if _, isLeader := partition.IsRaftLeader(); !isLeader {
+		err = raft.ErrNotLeader
+		return
+	}
+	ek := new(proto.ExtentKey)
+	if err = json.Unmarshal(p.Data[:p.Size], ek); err != nil {
+		return
+	}
+	newEk, err := partition.relocateTinyExtentKey(ek)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(newEk)
+	if err != nil {
+		return
+	}
+	p.PacketOkWithBody(data)
+}
+
+// Handle OpTinyExtentReset packet.
+func (s *DataNode) handleTinyExtentResetPacket(p *repl.Packet) {
+	var err error
+	defer func() {
+		if err != nil {
+			p.PackErrorBody(ActionTinyExtentReset, err.Error())
+		} else {
+			p.PacketOkReply()
+		}
+	}()
+	partition := p.Object.(*DataPartition)
+	if p.KernelOffset == 0 {
+		err = fmt.Errorf("tiny extent reset without generation")
+		return
+	}
+	err = partition.resetTinyExtent(p.ExtentID, p.KernelOffset)
+}
+
+// sendTinyCompactPacket sends the packet to the replica and reads back the reply.
+func sendTinyCompactPacket(p *repl.Packet, target string) (err error) {
+	var conn *net.TCPConn
+	conn, err = gConnPool.GetConnect(target)
+	if err != nil {
+		return errors.Trace(err, "get connect to %v", target)
+	}
+	defer func() {
+		gConnPool.PutConnect(conn, err != nil)
+	}()
+	if err = p.WriteToConn(conn); err != nil {
+		return errors.Trace(err, "write to %v", target)
+	}
+	if err = p.ReadFromConnWithVer(conn, tinyCompactTimeoutSec); err != nil {
+		return errors.Trace(err, "read from %v", target)
+	}
+	if p.ResultCode != proto.OpOk {
+		return fmt.Errorf("%v from %v result code %v: %v", p.GetOpMsg(), target, p.ResultCode, string(p.Data[:p.Size]))
+	}
+	return
+}
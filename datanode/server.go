@@ -79,6 +79,8 @@ const (
 	ConfigKeyPort          = "port"            // int
 	ConfigKeyMasterAddr    = "masterAddr"      // array
 	ConfigKeyZone          = "zoneName"        // string
+	ConfigKeyRack          = "rackName"        // string
+	ConfigKeyHost          = "hostName"        // string
 	ConfigKeyDisks         = "disks"           // array
 	ConfigKeyRaftDir       = "raftDir"         // string
 	ConfigKeyRaftHeartbeat = "raftHeartbeat"   // string
@@ -100,6 +102,10 @@ const (
 	CfgMetricsDegrade = "metricsDegrade" // int
 
 	CfgDiskRdonlySpace = "diskRdonlySpace" // int
+
+	// write journal on a fast device, disabled if the path is empty
+	ConfigKeyJournalPath = "journalPath" // string
+	ConfigKeyJournalSize = "journalSize" // int, bytes of each journal file
 	// smux Config
 	ConfigKeyEnableSmuxClient  = "enableSmuxConnPool" // bool
 	ConfigKeySmuxPortShift     = "smuxPortShift"      // int
@@ -130,6 +136,8 @@ type DataNode struct {
 	space           *SpaceManager
 	port            string
 	zoneName        string
+	rackName        string
+	hostName        string
 	clusterID       string
 	localIP         string
 	bindIp          bool
@@ -180,6 +188,8 @@ type DataNode struct {
 	cpuSamplerDone          chan struct{}
 
 	diskUnavailablePartitionErrorCount uint64 // disk status becomes unavailable when disk error partition count reaches this value
+
+	journal *writeJournal // nil if no journal is configured
 }
 
 type verOp2Phase struct {
@@ -285,6 +295,7 @@ func doShutdown(server common.Server) {
 	s.closeMetrics()
 	close(s.stopC)
 	s.space.Stop()
+	s.stopJournal()
 	s.stopUpdateNodeInfo()
 	s.stopTCPService()
 	s.stopRaftServer()
@@ -344,6 +355,9 @@ func (s *DataNode) parseConfig(cfg *config.Config) (err error) {
 	if s.zoneName == "" {
 		s.zoneName = DefaultZoneName
 	}
+	// the failure domains of the node for the replica placement
+	s.rackName = cfg.GetString(ConfigKeyRack)
+	s.hostName = cfg.GetString(ConfigKeyHost)
 	s.metricsDegrade = cfg.GetInt64(CfgMetricsDegrade)
 
 	s.serviceIDKey = cfg.GetString(ConfigServiceIDKey)
@@ -360,6 +374,7 @@ func (s *DataNode) parseConfig(cfg *config.Config) (err error) {
 	log.LogDebugf("action[parseConfig] load masterAddrs(%v).", MasterClient.Nodes())
 	log.LogDebugf("action[parseConfig] load port(%v).", s.port)
 	log.LogDebugf("action[parseConfig] load zoneName(%v).", s.zoneName)
+	log.LogDebugf("action[parseConfig] load rackName(%v) hostName(%v).", s.rackName, s.hostName)
 	return
 }
 
@@ -416,6 +431,11 @@ func (s *DataNode) startSpaceManager(cfg *config.Config) (err error) {
 		}
 	}
 
+	// the journal records are replayed while the partitions are loaded
+	if err = s.openJournal(cfg); err != nil {
+		return
+	}
+
 	var wg sync.WaitGroup
 	for _, d := range paths {
 		log.LogDebugf("action[startSpaceManager] load disk raw config(%v).", d)
@@ -457,6 +477,7 @@ func (s *DataNode) startSpaceManager(cfg *config.Config) (err error) {
 	}
 
 	wg.Wait()
+	s.startJournal()
 	// start async sample
 	s.space.StartDiskSample()
 	s.updateQosLimit() // load from config
@@ -530,8 +551,8 @@ func (s *DataNode) register(cfg *config.Config) {
 
 			// register this data node on the master
 			var nodeID uint64
-			if nodeID, err = MasterClient.NodeAPI().AddDataNodeWithAuthNode(fmt.Sprintf("%s:%v", LocalIP, s.port),
-				s.zoneName, s.serviceIDKey); err != nil {
+			if nodeID, err = MasterClient.NodeAPI().AddDataNodeWithLocation(fmt.Sprintf("%s:%v", LocalIP, s.port),
+				s.zoneName, s.rackName, s.hostName, s.serviceIDKey); err != nil {
 				log.LogErrorf("action[registerToMaster] cannot register this node to master[%v] err(%v).",
 					masterAddr, err)
 				timer.Reset(2 * time.Second)
@@ -635,6 +656,7 @@ func (s *DataNode) registerHandler() {
 	http.HandleFunc("/setDiskBad", s.setDiskBadAPI)
 	http.HandleFunc("/setDiskQos", s.setDiskQos)
 	http.HandleFunc("/getDiskQos", s.getDiskQos)
+	http.HandleFunc("/journal", s.getJournalAPI)
 }
 
 func (s *DataNode) startTCPService() (err error) {
@@ -117,6 +117,26 @@ func (s *DataNode) getRaftStatus(w http.ResponseWriter, r *http.Request) {
 	s.buildSuccessResp(w, raftStatus)
 }
 
+func (s *DataNode) getJournalAPI(w http.ResponseWriter, r *http.Request) {
+	j := s.journal
+	if j == nil {
+		s.buildFailureResp(w, http.StatusNotFound, "write journal is not enabled")
+		return
+	}
+	generation, used := j.usage()
+	s.buildSuccessResp(w, &struct {
+		Path       string `json:"path"`
+		FileSize   int64  `json:"fileSize"`
+		Generation uint64 `json:"generation"`
+		Used       int64  `json:"used"`
+	}{
+		Path:       j.dir,
+		FileSize:   j.fileSize,
+		Generation: generation,
+		Used:       used,
+	})
+}
+
 func (s *DataNode) getPartitionsAPI(w http.ResponseWriter, r *http.Request) {
 	partitions := make([]interface{}, 0)
 	s.space.RangePartitions(func(dp *DataPartition) bool {
@@ -197,6 +217,8 @@ func (s *DataNode) getPartitionAPI(w http.ResponseWriter, r *http.Request) {
 		FileCount            int                   `json:"fileCount"`
 		Replicas             []string              `json:"replicas"`
 		TinyDeleteRecordSize int64                 `json:"tinyDeleteRecordSize"`
+		TinyCompacting       []uint64              `json:"tinyCompacting"`
+		TinyReclaimedSize    uint64                `json:"tinyReclaimedSize"`
 		RaftStatus           *raft.Status          `json:"raftStatus"`
 	}{
 		VolName:              partition.volumeID,
@@ -209,6 +231,8 @@ func (s *DataNode) getPartitionAPI(w http.ResponseWriter, r *http.Request) {
 		FileCount:            len(files),
 		Replicas:             partition.Replicas(),
 		TinyDeleteRecordSize: tinyDeleteRecordSize,
+		TinyCompacting:       partition.tinyCompact.list(),
+		TinyReclaimedSize:    partition.tinyCompact.reclaimedSize(),
 		RaftStatus:           raftSt,
 	}
 
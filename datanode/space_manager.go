@@ -398,6 +398,10 @@ func (manager *SpaceManager) CreatePartition(request *proto.CreateDataPartitionR
 		VerSeq:        request.VerSeq,
 		CreateType:    request.CreateType,
 		Forbidden:     false,
+		EcDataNum:     request.EcDataNum,
+		EcParityNum:   request.EcParityNum,
+		EcHosts:       request.EcHosts,
+		EnableDedup:   request.EnableDedup,
 	}
 	log.LogInfof("action[CreatePartition] dp %v dpCfg.Peers %v request.Members %v",
 		dpCfg.PartitionID, dpCfg.Peers, request.Members)
@@ -421,6 +425,8 @@ func (manager *SpaceManager) CreatePartition(request *proto.CreateDataPartitionR
 
 // DeletePartition deletes a partition based on the partition id.
 func (manager *SpaceManager) DeletePartition(dpID uint64) {
+	// erasure-coded shards may be held by a node without any replica
+	manager.DeleteEcShards(dpID)
 	manager.partitionMutex.Lock()
 
 	dp := manager.partitions[dpID]
@@ -468,6 +474,8 @@ func (s *DataNode) buildHeartBeatResponse(response *proto.DataNodeHeartbeatRespo
 			ExtentCount:                partition.GetExtentCount(),
 			NeedCompare:                true,
 			DecommissionRepairProgress: partition.decommissionRepairProgress,
+			TinyCompacting:             partition.tinyCompactingExtents(),
+			TinyReclaimedSize:          partition.tinyCompact.reclaimedSize(),
 		}
 		log.LogDebugf("action[Heartbeats] dpid(%v), status(%v) total(%v) used(%v) leader(%v) isLeader(%v).", vr.PartitionID, vr.PartitionStatus, vr.Total, vr.Used, leaderAddr, vr.IsLeader)
 		response.PartitionReports = append(response.PartitionReports, vr)
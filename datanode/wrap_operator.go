@@ -37,7 +37,10 @@ import (
 	"github.com/cubefs/cubefs/util/log"
 )
 
-var ErrForbiddenDataPartition = errors.New("the data partition is forbidden")
+var (
+	ErrForbiddenDataPartition = errors.New("the data partition is forbidden")
+	ErrDedupExtentOverwrite   = errors.New("extents of dedup volume can not be overwritten in place")
+)
 
 func (s *DataNode) getPacketTpLabels(p *repl.Packet) map[string]string {
 	labels := make(map[string]string)
@@ -182,6 +185,18 @@ func (s *DataNode) OperatePacket(p *repl.Packet, c net.Conn) (err error) {
 		s.handleUpdateVerPacket(p)
 	case proto.OpStopDataPartitionRepair:
 		s.handlePacketToStopDataPartitionRepair(p)
+	case proto.OpUpdateDataPartitionEcHosts:
+		s.handlePacketToUpdateEcHosts(p)
+	case proto.OpEcShardWrite:
+		s.handleEcShardWritePacket(p)
+	case proto.OpEcShardRead:
+		s.handleEcShardReadPacket(p, c)
+	case proto.OpEcShardDelete:
+		s.handleEcShardDeletePacket(p)
+	case proto.OpTinyExtentRelocate:
+		s.handleTinyExtentRelocatePacket(p)
+	case proto.OpTinyExtentReset:
+		s.handleTinyExtentResetPacket(p)
 	default:
 		p.PackErrorBody(repl.ErrorUnknownOp.Error(), repl.ErrorUnknownOp.Error()+strconv.Itoa(int(p.Opcode)))
 	}
@@ -476,6 +491,20 @@ func (s *DataNode) checkVolumeForbidden(volNames []string) {
 	})
 }
 
+func (s *DataNode) checkVolumeDedup(volNames []string) {
+	s.space.RangePartitions(func(partition *DataPartition) bool {
+		for _, volName := range volNames {
+			if volName == partition.volumeID {
+				if err := partition.EnableDedup(); err != nil {
+					log.LogErrorf("action[checkVolumeDedup] dp(%v) enable dedup err(%v)", partition.partitionID, err)
+				}
+				return true
+			}
+		}
+		return true
+	})
+}
+
 func (s *DataNode) checkDecommissionDisks(decommissionDisks []string) {
 	decommissionDiskSet := util.NewSet()
 	for _, disk := range decommissionDisks {
@@ -529,6 +558,8 @@ func (s *DataNode) handleHeartbeatPacket(p *repl.Packet) {
 
 			// set volume forbidden
 			s.checkVolumeForbidden(request.ForbiddenVols)
+			// set volume dedup
+			s.checkVolumeDedup(request.DedupVols)
 			// set decommission disks
 			s.checkDecommissionDisks(request.DecommissionDisks)
 			s.diskQosEnableFromMaster = request.EnableDiskQos
@@ -692,6 +723,9 @@ func (s *DataNode) handleMarkDeletePacket(p *repl.Packet, c net.Conn) {
 				log.LogErrorf("action[handleMarkDeletePacket]: failed to mark delete extent(%v), %v", p.ExtentID, err)
 			}
 		})
+		if err == nil {
+			partition.releaseEcExtent(p.ExtentID)
+		}
 	}
 }
 
@@ -728,6 +762,7 @@ func (s *DataNode) handleBatchMarkDeletePacket(p *repl.Packet, c net.Conn) {
 				if err != nil {
 					return
 				}
+				partition.releaseEcExtent(ext.ExtentId)
 			} else {
 				log.LogInfof("delete limiter reach(%v), remote (%v) try again.", deleteLimiteRater.Limit(), c.RemoteAddr().String())
 				err = storage.TryAgainError
@@ -776,7 +811,7 @@ func (s *DataNode) handleWritePacket(p *repl.Packet) {
 		partition.disk.allocCheckLimit(proto.IopsWriteType, 1)
 
 		if writable := partition.disk.limitWrite.TryRun(int(p.Size), func() {
-			_, err = store.Write(p.ExtentID, p.ExtentOffset, int64(p.Size), p.Data, p.CRC, storage.AppendWriteType, p.IsSyncWrite())
+			_, err = partition.journalWrite(p.ExtentID, p.ExtentOffset, int64(p.Size), p.Data, p.CRC, storage.AppendWriteType, p.IsSyncWrite())
 		}); !writable {
 			err = storage.TryAgainError
 			return
@@ -798,7 +833,7 @@ func (s *DataNode) handleWritePacket(p *repl.Packet) {
 		partition.disk.allocCheckLimit(proto.IopsWriteType, 1)
 
 		if writable := partition.disk.limitWrite.TryRun(int(p.Size), func() {
-			_, err = store.Write(p.ExtentID, p.ExtentOffset, int64(p.Size), p.Data, p.CRC, storage.AppendWriteType, p.IsSyncWrite())
+			_, err = partition.journalWrite(p.ExtentID, p.ExtentOffset, int64(p.Size), p.Data, p.CRC, storage.AppendWriteType, p.IsSyncWrite())
 		}); !writable {
 			err = storage.TryAgainError
 			return
@@ -847,7 +882,8 @@ func (s *DataNode) handleWritePacket(p *repl.Packet) {
 
 func (s *DataNode) handleRandomWritePacket(p *repl.Packet) {
 	var (
-		err error
+		err         error
+		dedupReject bool
 
 		metricPartitionIOLabels map[string]string
 		partitionIOMetric       *exporter.TimePointCount
@@ -858,6 +894,10 @@ func (s *DataNode) handleRandomWritePacket(p *repl.Packet) {
 			p.Opcode, p.VerSeq, p.PartitionID, p.ResultCode, p.ExtentID, err)
 		if err != nil {
 			p.PackErrorBody(ActionWrite, err.Error())
+		} else if dedupReject {
+			// the client writes the range to a new extent instead
+			p.PackErrorBody(ActionWrite, ErrDedupExtentOverwrite.Error())
+			p.ResultCode = proto.OpTryOtherExtent
 		} else {
 			// avoid rsp pack ver info into package which client need do more work to read buffer
 			if p.Opcode == proto.OpRandomWriteVer || p.Opcode == proto.OpSyncRandomWriteVer {
@@ -878,6 +918,11 @@ func (s *DataNode) handleRandomWritePacket(p *repl.Packet) {
 		err = ErrForbiddenDataPartition
 		return
 	}
+	// extents of dedup volume may be shared by inodes
+	if partition.IsDedup() && p.IsRandomWrite() && !storage.IsTinyExtent(p.ExtentID) {
+		dedupReject = true
+		return
+	}
 	log.LogDebugf("action[handleRandomWritePacket opcod %v seq %v dpid %v dpseq %v extid %v", p.Opcode, p.VerSeq, p.PartitionID, partition.verSeq, p.ExtentID)
 	// cache or preload partition not support raft and repair.
 	if !partition.isNormalType() {
@@ -1011,6 +1056,10 @@ func (s *DataNode) extentRepairReadPacket(p *repl.Packet, connect net.Conn, isRe
 		partition.Disk().allocCheckLimit(proto.FlowReadType, currReadSize)
 
 		partition.disk.limitRead.Run(int(currReadSize), func() {
+			if partition.isEcExtent(reply.ExtentID) {
+				reply.CRC, err = partition.ecRead(reply.ExtentID, offset, int64(currReadSize), reply.Data)
+				return
+			}
 			reply.CRC, err = store.Read(reply.ExtentID, offset, int64(currReadSize), reply.Data, isRepairRead)
 		})
 		if !shallDegrade {
@@ -1189,6 +1238,11 @@ func (s *DataNode) handlePacketToReadTinyDeleteRecordFile(p *repl.Packet, connec
 	}()
 	partition := p.Object.(*DataPartition)
 	store := partition.ExtentStore()
+	generation := p.KernelOffset
+	if generation != store.TinyDeleteGeneration() {
+		err = fmt.Errorf(ActionStreamReadTinyDeleteRecord+" generation(%v) mismatch local(%v)", generation, store.TinyDeleteGeneration())
+		return
+	}
 	localTinyDeleteFileSize, err := store.LoadTinyDeleteFileOffset()
 	if err != nil {
 		return
@@ -1206,6 +1260,9 @@ func (s *DataNode) handlePacketToReadTinyDeleteRecordFile(p *repl.Packet, connec
 		reply.Data = make([]byte, currReadSize)
 		reply.ExtentOffset = offset
 		reply.CRC, err = store.ReadTinyDeleteRecords(offset, int64(currReadSize), reply.Data)
+		if err == nil && generation != store.TinyDeleteGeneration() {
+			err = fmt.Errorf("generation(%v) changed to (%v)", generation, store.TinyDeleteGeneration())
+		}
 		if err != nil {
 			err = fmt.Errorf(ActionStreamReadTinyDeleteRecord+" localTinyDeleteRecordSize(%v) offset(%v)"+
 				" currReadSize(%v) err(%v)", localTinyDeleteFileSize, offset, currReadSize, err)
@@ -1587,3 +1644,40 @@ func (s *DataNode) handlePacketToStopDataPartitionRepair(p *repl.Packet) {
 	dp.StopDecommissionRecover(request.Stop)
 	log.LogInfof("action[handlePacketToStopDataPartitionRepair] %v stop %v success", request.PartitionId, request.Stop)
 }
+
+func (s *DataNode) handlePacketToUpdateEcHosts(p *repl.Packet) {
+	task := &proto.AdminTask{}
+	err := json.Unmarshal(p.Data, task)
+	defer func() {
+		if err != nil {
+			p.PackErrorBody(ActionUpdateEcHosts, err.Error())
+		} else {
+			p.PacketOkReply()
+		}
+	}()
+	if err != nil {
+		return
+	}
+	request := &proto.UpdateDataPartitionEcHostsRequest{}
+	if task.OpCode != proto.OpUpdateDataPartitionEcHosts {
+		err = fmt.Errorf("action[handlePacketToUpdateEcHosts] illegal opcode ")
+		log.LogWarnf("action[handlePacketToUpdateEcHosts] illegal opcode ")
+		return
+	}
+
+	bytes, _ := json.Marshal(task.Request)
+	p.AddMesgLog(string(bytes))
+	if err = json.Unmarshal(bytes, request); err != nil {
+		return
+	}
+	dp := s.space.Partition(request.PartitionId)
+	if dp == nil {
+		err = proto.ErrDataPartitionNotExists
+		log.LogWarnf("action[handlePacketToUpdateEcHosts] cannot find dp %v", request.PartitionId)
+		return
+	}
+	if err = dp.updateEcHosts(request.EcHosts); err != nil {
+		return
+	}
+	log.LogInfof("action[handlePacketToUpdateEcHosts] dp %v ec hosts %v", request.PartitionId, request.EcHosts)
+}
@@ -30,10 +30,19 @@ func (s *DataNode) Post(p *repl.Packet) error {
 		p.NeedReply = false
 	}
 	s.cleanupPkt(p)
+	s.releaseEcWrite(p)
 	s.addMetrics(p)
 	return nil
 }
 
+func (s *DataNode) releaseEcWrite(p *repl.Packet) {
+	partition, ok := p.Object.(*DataPartition)
+	if !ok || partition.ecIndex == nil {
+		return
+	}
+	partition.ecIndex.endWrite(p)
+}
+
 func (s *DataNode) cleanupPkt(p *repl.Packet) {
 	if p.IsMasterCommand() {
 		return
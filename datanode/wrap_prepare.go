@@ -35,7 +35,7 @@ func (s *DataNode) Prepare(p *repl.Packet) (err error) {
 			p.AfterPre = true
 		}
 	}()
-	if p.IsMasterCommand() {
+	if p.IsMasterCommand() || isEcShardPacket(p) {
 		return
 	}
 	atomic.AddUint64(&s.metricsCnt, 1)
@@ -91,6 +91,12 @@ func (s *DataNode) checkPartition(p *repl.Packet) (err error) {
 		return
 	}
 	p.Object = dp
+	// counted until posted, so that sealing waits for it
+	if dp.isEcWrite(p) {
+		if err = dp.ecIndex.beginWrite(p); err != nil {
+			return
+		}
+	}
 	if p.IsNormalWriteOperation() || p.IsCreateExtentOperation() {
 		if dp.Available() <= 0 {
 			err = storage.NoSpaceError
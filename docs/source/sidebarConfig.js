@@ -135,6 +135,7 @@ module.exports = [
                 ]
             },
             'tools/blobstore-cli.md',
+            'tools/migrate.md',
         ]
     },
     {
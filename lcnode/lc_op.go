@@ -180,3 +180,35 @@ func (l *LcNode) opSnapshotVerDel(conn net.Conn, p *proto.Packet) (err error) {
 
 	return
 }
+
+func (l *LcNode) opSnapshotDiff(conn net.Conn, p *proto.Packet) (err error) {
+	go func() {
+		p.PacketOkReply()
+		if err := p.WriteToConn(conn); err != nil {
+			log.LogErrorf("ack master response: %s", err.Error())
+		}
+	}()
+	data := p.Data
+	var (
+		req       = &proto.SnapshotDiffTaskRequest{}
+		resp      = &proto.SnapshotDiffTaskResponse{}
+		adminTask = &proto.AdminTask{
+			Request: req,
+		}
+	)
+
+	decoder := json.NewDecoder(bytes.NewBuffer(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(adminTask); err != nil {
+		resp.Status = proto.TaskFailed
+		resp.Result = err.Error()
+		adminTask.Response = resp
+		l.respondToMaster(adminTask)
+		return
+	}
+
+	l.startSnapshotDiff(adminTask)
+	l.respondToMaster(adminTask)
+
+	return
+}
@@ -26,3 +26,10 @@ type MetaWrapper interface {
 	ReadDirLimit_ll(parentID uint64, from string, limit uint64) ([]proto.Dentry, error)
 	Close() error
 }
+
+// SnapshotDiffMetaWrapper reads the tree of a volume at a given version.
+type SnapshotDiffMetaWrapper interface {
+	ReadDirLimit_ll(parentID uint64, from string, limit uint64) ([]proto.Dentry, error)
+	InodeVerDiff_ll(fromVerSeq, toVerSeq uint64) ([]proto.InodeVerDiff, error)
+	Close() error
+}
@@ -51,12 +51,16 @@ type LcNode struct {
 	control          common.Control
 	lcScanners       map[string]*LcScanner
 	snapshotScanners map[string]*SnapshotScanner
+
+	snapshotDiffScanners map[string]*SnapshotDiffScanner
 }
 
 func NewServer() *LcNode {
 	return &LcNode{
 		lcScanners:       make(map[string]*LcScanner),
 		snapshotScanners: make(map[string]*SnapshotScanner),
+
+		snapshotDiffScanners: make(map[string]*SnapshotDiffScanner),
 	}
 }
 
@@ -336,6 +340,8 @@ func (l *LcNode) handlePacket(conn net.Conn, p *proto.Packet, remoteAddr string)
 		err = l.opLcScan(conn, p)
 	case proto.OpLcNodeSnapshotVerDel:
 		err = l.opSnapshotVerDel(conn, p)
+	case proto.OpLcNodeSnapshotDiff:
+		err = l.opSnapshotDiff(conn, p)
 	default:
 		err = fmt.Errorf("%s unknown Opcode: %d, reqId: %d", remoteAddr,
 			p.Opcode, p.GetReqID())
@@ -370,4 +376,8 @@ func (l *LcNode) stopScanners() {
 		s.Stop()
 		delete(l.snapshotScanners, s.ID)
 	}
+	for _, s := range l.snapshotDiffScanners {
+		s.Stop()
+		delete(l.snapshotDiffScanners, s.ID)
+	}
 }
@@ -0,0 +1,317 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package lcnode
+
+import (
+	"errors"
+	"path"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/meta"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	snapshotDiffPutRetry         = 3
+	snapshotDiffPutRetryInterval = time.Second
+)
+
+var errSnapshotDiffStopped = errors.New("snapshot diff stopped")
+
+// SnapshotDiffScanner lists the paths changed between two versions of a volume.
+// Both trees are walked to resolve the paths of the inodes, the metanodes tell
+// which of the inodes present in both versions have been modified.
+type SnapshotDiffScanner struct {
+	ID        string
+	Volume    string
+	fromMw    SnapshotDiffMetaWrapper
+	toMw      SnapshotDiffMetaWrapper
+	lcnode    *LcNode
+	adminTask *proto.AdminTask
+	diffReq   *proto.SnapshotDiffTaskRequest
+	stopC     chan bool
+}
+
+type snapshotDiffNode struct {
+	path  string
+	isDir bool
+}
+
+func NewSnapshotDiffScanner(adminTask *proto.AdminTask, l *LcNode) (*SnapshotDiffScanner, error) {
+	request := adminTask.Request.(*proto.SnapshotDiffTaskRequest)
+	var (
+		fromMw, toMw *meta.MetaWrapper
+		err          error
+	)
+	if fromMw, err = meta.NewMetaWrapper(&meta.MetaConfig{
+		Volume:     request.Task.VolName,
+		Masters:    l.masters,
+		VerReadSeq: request.Task.FromVerSeq,
+	}); err != nil {
+		return nil, err
+	}
+	if toMw, err = meta.NewMetaWrapper(&meta.MetaConfig{
+		Volume:     request.Task.VolName,
+		Masters:    l.masters,
+		VerReadSeq: request.Task.ToVerSeq,
+	}); err != nil {
+		fromMw.Close()
+		return nil, err
+	}
+
+	scanner := &SnapshotDiffScanner{
+		ID:        request.Task.Id,
+		Volume:    request.Task.VolName,
+		fromMw:    fromMw,
+		toMw:      toMw,
+		lcnode:    l,
+		adminTask: adminTask,
+		diffReq:   request,
+		stopC:     make(chan bool),
+	}
+	return scanner, nil
+}
+
+func (l *LcNode) startSnapshotDiff(adminTask *proto.AdminTask) (err error) {
+	request := adminTask.Request.(*proto.SnapshotDiffTaskRequest)
+	log.LogInfof("startSnapshotDiff: diff task(%v) received!", request.Task)
+	response := &proto.SnapshotDiffTaskResponse{}
+	adminTask.Response = response
+
+	l.scannerMutex.Lock()
+	if _, ok := l.snapshotDiffScanners[request.Task.Id]; ok {
+		log.LogInfof("startSnapshotDiff: diff task(%v) is already running!", request.Task)
+		l.scannerMutex.Unlock()
+		return
+	}
+
+	var scanner *SnapshotDiffScanner
+	scanner, err = NewSnapshotDiffScanner(adminTask, l)
+	if err != nil {
+		log.LogErrorf("startSnapshotDiff: NewSnapshotDiffScanner err(%v)", err)
+		t := time.Now()
+		response.ID = request.Task.Id
+		response.LcNode = l.localServerAddr
+		response.SnapshotDiffTask = request.Task
+		response.EndTime = &t
+		response.Done = true
+		response.Status = proto.TaskFailed
+		response.Result = err.Error()
+		l.scannerMutex.Unlock()
+		return
+	}
+	l.snapshotDiffScanners[scanner.ID] = scanner
+	l.scannerMutex.Unlock()
+
+	go scanner.Start()
+	return
+}
+
+func (s *SnapshotDiffScanner) Stop() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.LogErrorf("SnapshotDiffScanner Stop err:%v", r)
+		}
+	}()
+	close(s.stopC)
+	s.fromMw.Close()
+	s.toMw.Close()
+	log.LogDebugf("snapshot diff scanner(%v) stopped", s.ID)
+}
+
+func (s *SnapshotDiffScanner) Start() {
+	response := s.adminTask.Response.(*proto.SnapshotDiffTaskResponse)
+	t := time.Now()
+	response.StartTime = &t
+
+	entries, err := s.diff()
+	if err == nil {
+		err = s.putPages(entries)
+	}
+
+	end := time.Now()
+	response.ID = s.ID
+	response.LcNode = s.lcnode.localServerAddr
+	response.SnapshotDiffTask = s.diffReq.Task
+	response.EndTime = &end
+	response.Done = true
+	if err != nil {
+		response.Status = proto.TaskFailed
+		response.Result = err.Error()
+	} else {
+		response.Status = proto.TaskSucceeds
+		response.Total = len(entries)
+	}
+	if err == errSnapshotDiffStopped {
+		return
+	}
+
+	s.lcnode.scannerMutex.Lock()
+	s.Stop()
+	delete(s.lcnode.snapshotDiffScanners, s.ID)
+	s.lcnode.scannerMutex.Unlock()
+
+	s.lcnode.respondToMaster(s.adminTask)
+	log.LogInfof("snapshot diff completed for task(%v), entries(%v) err(%v)", s.diffReq.Task, len(entries), err)
+}
+
+// putPages puts the entries to the master page by page, the entries are kept by
+// the master in its store instead of the memory.
+func (s *SnapshotDiffScanner) putPages(entries []proto.SnapshotDiffEntry) (err error) {
+	for page := 0; page*proto.SnapshotDiffPageSize < len(entries); page++ {
+		end := (page + 1) * proto.SnapshotDiffPageSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		diffPage := &proto.SnapshotDiffPage{
+			ID:      s.ID,
+			VolName: s.Volume,
+			LcNode:  s.lcnode.localServerAddr,
+			Page:    page,
+			Entries: entries[page*proto.SnapshotDiffPageSize : end],
+		}
+		for i := 0; i < snapshotDiffPutRetry; i++ {
+			select {
+			case <-s.stopC:
+				return errSnapshotDiffStopped
+			default:
+			}
+			if err = s.lcnode.mc.NodeAPI().PutSnapshotDiffPage(diffPage); err == nil {
+				break
+			}
+			log.LogWarnf("snapshot diff(%v): put page %v err(%v)", s.ID, page, err)
+			time.Sleep(snapshotDiffPutRetryInterval)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (s *SnapshotDiffScanner) diff() (entries []proto.SnapshotDiffEntry, err error) {
+	var fromTree, toTree map[uint64]snapshotDiffNode
+	if fromTree, err = s.walkVersionTree(s.fromMw); err != nil {
+		log.LogErrorf("snapshot diff(%v): walk version %v err(%v)", s.ID, s.diffReq.Task.FromVerSeq, err)
+		return
+	}
+	if toTree, err = s.walkVersionTree(s.toMw); err != nil {
+		log.LogErrorf("snapshot diff(%v): walk version %v err(%v)", s.ID, s.diffReq.Task.ToVerSeq, err)
+		return
+	}
+	var inoDiffs []proto.InodeVerDiff
+	if inoDiffs, err = s.toMw.InodeVerDiff_ll(s.diffReq.Task.FromVerSeq, s.diffReq.Task.ToVerSeq); err != nil {
+		log.LogErrorf("snapshot diff(%v): InodeVerDiff_ll err(%v)", s.ID, err)
+		return
+	}
+	modified := make(map[uint64]bool)
+	for _, d := range inoDiffs {
+		if d.Change == proto.VerDiffModified {
+			modified[d.Inode] = true
+		}
+	}
+	entries = diffVersionTrees(fromTree, toTree, modified)
+	return
+}
+
+// walkVersionTree maps the inodes reachable from the root to their paths,
+// a hard linked inode keeps the smallest of its paths.
+func (s *SnapshotDiffScanner) walkVersionTree(mw SnapshotDiffMetaWrapper) (tree map[uint64]snapshotDiffNode, err error) {
+	tree = map[uint64]snapshotDiffNode{proto.RootIno: {path: "/", isDir: true}}
+	dirs := []uint64{proto.RootIno}
+	for len(dirs) > 0 {
+		select {
+		case <-s.stopC:
+			return nil, errSnapshotDiffStopped
+		default:
+		}
+
+		parent := dirs[0]
+		dirs = dirs[1:]
+		parentPath := tree[parent].path
+		marker := ""
+		for {
+			var children []proto.Dentry
+			children, err = mw.ReadDirLimit_ll(parent, marker, uint64(defaultReadDirLimit))
+			if err == syscall.ENOENT {
+				err = nil
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			childrenNr := len(children)
+			if marker != "" && childrenNr > 0 && children[0].Name == marker {
+				children = children[1:]
+			}
+			for _, child := range children {
+				node := snapshotDiffNode{
+					path:  path.Join(parentPath, child.Name),
+					isDir: proto.IsDir(child.Type),
+				}
+				if old, ok := tree[child.Inode]; ok {
+					if node.path < old.path {
+						tree[child.Inode] = node
+					}
+					continue
+				}
+				tree[child.Inode] = node
+				if node.isDir {
+					dirs = append(dirs, child.Inode)
+				}
+			}
+			if childrenNr < defaultReadDirLimit {
+				break
+			}
+			marker = children[len(children)-1].Name
+		}
+	}
+	return
+}
+
+// diffVersionTrees compares the paths of the inodes in the two versions, the
+// inodes found in both versions are renamed if the path changed and modified
+// if reported so. Modified directories are left out, their changed children
+// are listed anyway.
+func diffVersionTrees(fromTree, toTree map[uint64]snapshotDiffNode, modified map[uint64]bool) (entries []proto.SnapshotDiffEntry) {
+	for ino, to := range toTree {
+		from, ok := fromTree[ino]
+		if !ok {
+			entries = append(entries, proto.SnapshotDiffEntry{Path: to.path, Change: proto.VerDiffCreated, Inode: ino, IsDir: to.isDir})
+			continue
+		}
+		if from.path != to.path {
+			entries = append(entries, proto.SnapshotDiffEntry{Path: to.path, OldPath: from.path, Change: proto.VerDiffRenamed, Inode: ino, IsDir: to.isDir})
+		}
+		if modified[ino] && !to.isDir {
+			entries = append(entries, proto.SnapshotDiffEntry{Path: to.path, Change: proto.VerDiffModified, Inode: ino})
+		}
+	}
+	for ino, from := range fromTree {
+		if _, ok := toTree[ino]; !ok {
+			entries = append(entries, proto.SnapshotDiffEntry{Path: from.path, Change: proto.VerDiffDeleted, Inode: ino, IsDir: from.isDir})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].Change < entries[j].Change
+	})
+	return
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package lcnode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffVersionTrees(t *testing.T) {
+	fromTree := map[uint64]snapshotDiffNode{
+		1:  {path: "/", isDir: true},
+		10: {path: "/a", isDir: true},
+		11: {path: "/a/keep"},
+		12: {path: "/a/old"},
+		13: {path: "/a/gone"},
+		14: {path: "/a/edit"},
+	}
+	toTree := map[uint64]snapshotDiffNode{
+		1:  {path: "/", isDir: true},
+		10: {path: "/a", isDir: true},
+		11: {path: "/a/keep"},
+		12: {path: "/a/new"},
+		14: {path: "/a/edit"},
+		15: {path: "/a/born"},
+	}
+	modified := map[uint64]bool{1: true, 10: true, 12: true, 14: true}
+
+	entries := diffVersionTrees(fromTree, toTree, modified)
+	require.Equal(t, []proto.SnapshotDiffEntry{
+		{Path: "/a/born", Change: proto.VerDiffCreated, Inode: 15},
+		{Path: "/a/edit", Change: proto.VerDiffModified, Inode: 14},
+		{Path: "/a/gone", Change: proto.VerDiffDeleted, Inode: 13},
+		{Path: "/a/new", Change: proto.VerDiffModified, Inode: 12},
+		{Path: "/a/new", OldPath: "/a/old", Change: proto.VerDiffRenamed, Inode: 12},
+	}, entries)
+}
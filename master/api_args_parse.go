@@ -165,7 +165,7 @@ func parseRequestForUpdateMetaNode(r *http.Request) (nodeAddr string, id uint64,
 	return
 }
 
-func parseRequestForAddNode(r *http.Request) (nodeAddr, zoneName string, err error) {
+func parseRequestForAddNode(r *http.Request) (nodeAddr, zoneName, rackName, hostName string, err error) {
 	if err = r.ParseForm(); err != nil {
 		return
 	}
@@ -175,6 +175,8 @@ func parseRequestForAddNode(r *http.Request) (nodeAddr, zoneName string, err err
 	if zoneName = r.FormValue(zoneNameKey); zoneName == "" {
 		zoneName = DefaultZoneName
 	}
+	rackName = r.FormValue(rackNameKey)
+	hostName = r.FormValue(hostNameKey)
 	return
 }
 
@@ -388,6 +390,7 @@ type updateVolReq struct {
 	followerRead            bool
 	authenticate            bool
 	enablePosixAcl          bool
+	enableDedup             bool
 	enableTransaction       proto.TxOpMask
 	txTimeout               int64
 	txConflictRetryNum      int64
@@ -489,6 +492,17 @@ func parseVolUpdateReq(r *http.Request, vol *Vol, req *updateVolReq) (err error)
 		return
 	}
 
+	if req.enableDedup, err = extractBoolWithDefault(r, enableDedupKey, vol.enableDedup); err != nil {
+		return
+	}
+	if req.enableDedup && !proto.IsHot(vol.VolType) {
+		return fmt.Errorf("dedup is only supported by hot volume")
+	}
+	// extents shared before can still be overwritten in place once disabled
+	if vol.enableDedup && !req.enableDedup {
+		return fmt.Errorf("dedup can not be disabled once enabled")
+	}
+
 	var txMask proto.TxOpMask
 	if txMask, err = parseTxMask(r, vol.enableTransaction); err != nil {
 		return
@@ -664,6 +678,8 @@ type createVolReq struct {
 	volType                              int
 	enablePosixAcl                       bool
 	DpReadOnlyWhenVolFull                bool
+	ecDataNum                            uint8
+	ecParityNum                          uint8
 	enableTransaction                    proto.TxOpMask
 	enableQuota                          bool
 	txTimeout                            int64
@@ -673,6 +689,9 @@ type createVolReq struct {
 	clientReqPeriod, clientHitTriggerCnt uint32
 	// cold vol args
 	coldArgs coldVolArgs
+	// clone source args
+	cloneSource string
+	cloneVerSeq uint64
 }
 
 func checkCacheAction(action int) error {
@@ -755,6 +774,18 @@ func parseRequestToCreateVol(r *http.Request, req *createVolReq) (err error) {
 	}
 	req.dpReplicaNum = uint8(parsedDpReplicaNum)
 
+	var ecDataNum, ecParityNum int
+	if ecDataNum, err = extractUintWithDefault(r, ecDataNumKey, 0); err != nil {
+		return
+	}
+	if ecParityNum, err = extractUintWithDefault(r, ecParityNumKey, 0); err != nil {
+		return
+	}
+	if ecDataNum > math.MaxUint8 || ecParityNum > math.MaxUint8 {
+		return fmt.Errorf("invalid arg ecDataNum[%v] ecParityNum[%v]", ecDataNum, ecParityNum)
+	}
+	req.ecDataNum, req.ecParityNum = uint8(ecDataNum), uint8(ecParityNum)
+
 	if req.dpSize, err = extractUintWithDefault(r, dataPartitionSizeKey, 120); err != nil {
 		return
 	}
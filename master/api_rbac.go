@@ -0,0 +1,537 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/compressor"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+	"github.com/gorilla/mux"
+)
+
+// The admin requests are signed with the keys of a master user, see
+// proto.AdminRequestSignature. With rbacEnable set, the role of the user
+// decides which routes it may call, the unsigned requests get the role set by
+// rbacAnonymousRole. Every request on a route beyond viewing is written to the
+// admin audit log, whether the check is enabled or not.
+type apiAccess uint8
+
+const (
+	apiAccessOpen     apiAccess = iota // called by the nodes and the clients
+	apiAccessUserKey                   // open, the secret key is replied to its owner and admins only
+	apiAccessViewer                    // read only
+	apiAccessVolume                    // operators, and the owner of the volume
+	apiAccessOperator                  // operations on nodes, partitions and volumes
+	apiAccessAdmin                     // cluster settings, raft members and users
+)
+
+const (
+	adminAuditModule   = "adminAudit"
+	adminAuditReplyMax = 4096
+
+	adminRoleKey = "_admin_role_key"
+)
+
+var apiAccessMap = map[string]apiAccess{
+	exporter.PromHandlerPattern: apiAccessOpen,
+	proto.AdminClusterAPI:       apiAccessAdmin,
+	proto.AdminUserAPI:          apiAccessAdmin,
+
+	// cluster management APIs
+	proto.AdminGetMasterApiList:             apiAccessViewer,
+	proto.AdminSetApiQpsLimit:               apiAccessOperator,
+	proto.AdminGetApiQpsLimit:               apiAccessViewer,
+	proto.AdminRemoveApiQpsLimit:            apiAccessOperator,
+	proto.AdminGetIP:                        apiAccessOpen,
+	proto.AdminGetCluster:                   apiAccessOpen,
+	proto.AdminSetClusterInfo:               apiAccessAdmin,
+	proto.AdminGetMonitorPushAddr:           apiAccessOpen,
+	proto.AdminClusterFreeze:                apiAccessAdmin,
+	proto.AdminClusterForbidMpDecommission:  apiAccessOperator,
+	proto.AddRaftNode:                       apiAccessAdmin,
+	proto.RemoveRaftNode:                    apiAccessAdmin,
+	proto.RaftStatus:                        apiAccessViewer,
+	proto.AdminClusterStat:                  apiAccessViewer,
+	proto.AdminSetCheckDataReplicasEnable:   apiAccessOperator,
+	proto.AdminSetConfig:                    apiAccessAdmin,
+	proto.AdminGetConfig:                    apiAccessViewer,
+	proto.AdminUpdateDecommissionLimit:      apiAccessOperator,
+	proto.AdminQueryDecommissionLimit:       apiAccessViewer,
+	proto.AdminQueryDecommissionToken:       apiAccessViewer,
+	proto.AdminSetFileStats:                 apiAccessOperator,
+	proto.AdminGetFileStats:                 apiAccessViewer,
+	proto.AdminSetClusterUuidEnable:         apiAccessAdmin,
+	proto.AdminGetClusterUuid:               apiAccessViewer,
+	proto.AdminGenerateClusterUuid:          apiAccessAdmin,
+	proto.AdminGetClusterValue:              apiAccessViewer,
+	proto.AdminUpdateDecommissionDiskFactor: apiAccessOperator,
+	proto.AdminQueryDecommissionDiskLimit:   apiAccessViewer,
+	proto.AdminEnableAutoDecommissionDisk:   apiAccessOperator,
+	proto.AdminQueryAutoDecommissionDisk:    apiAccessViewer,
+	proto.AdminChangeMasterLeader:           apiAccessAdmin,
+	proto.AdminOpFollowerPartitionsRead:     apiAccessOperator,
+	proto.GetTopologyView:                   apiAccessViewer,
+	proto.GetPlacementView:                  apiAccessViewer,
+
+	// volume management APIs
+	proto.AdminCreateVol:         apiAccessVolume,
+	proto.AdminGetVol:            apiAccessOpen,
+	proto.AdminDeleteVol:         apiAccessVolume,
+	proto.AdminUpdateVol:         apiAccessVolume,
+	proto.AdminVolShrink:         apiAccessVolume,
+	proto.AdminVolExpand:         apiAccessVolume,
+	proto.AdminVolForbidden:      apiAccessOperator,
+	proto.AdminVolEnableAuditLog: apiAccessOperator,
+	proto.AdminListVols:          apiAccessViewer,
+	proto.ClientVol:              apiAccessOpen,
+	proto.ClientVolStat:          apiAccessOpen,
+	proto.AdminCreateVersion:     apiAccessVolume,
+	proto.AdminDelVersion:        apiAccessVolume,
+	proto.AdminGetVersionInfo:    apiAccessOpen,
+	proto.AdminGetAllVersionInfo: apiAccessOpen,
+	proto.AdminGetVolVer:         apiAccessOpen,
+	proto.AdminSetVerStrategy:    apiAccessVolume,
+	proto.AdminCloneVol:          apiAccessVolume,
+	proto.AdminSnapshotDiff:      apiAccessVolume,
+	proto.AdminGetSnapshotDiff:   apiAccessVolume,
+
+	proto.AdminCreateSnapshotPolicy: apiAccessOperator,
+	proto.AdminUpdateSnapshotPolicy: apiAccessOperator,
+	proto.AdminDeleteSnapshotPolicy: apiAccessOperator,
+	proto.AdminAttachSnapshotPolicy: apiAccessVolume,
+	proto.AdminDetachSnapshotPolicy: apiAccessVolume,
+	proto.AdminListSnapshotPolicy:   apiAccessViewer,
+	proto.AdminSnapshotPolicyStatus: apiAccessViewer,
+
+	proto.AdminMetadataBackup:       apiAccessAdmin,
+	proto.AdminListMetadataBackup:   apiAccessViewer,
+	proto.AdminVerifyMetadataBackup: apiAccessViewer,
+	proto.AdminListEvents:           apiAccessViewer,
+	proto.SetBucketLifecycle:        apiAccessOperator,
+	proto.GetBucketLifecycle:        apiAccessOpen,
+	proto.DeleteBucketLifecycle:     apiAccessVolume,
+	proto.QuotaCreate:               apiAccessVolume,
+	proto.QuotaUpdate:               apiAccessVolume,
+	proto.QuotaDelete:               apiAccessVolume,
+	proto.QuotaList:                 apiAccessOpen,
+	proto.QuotaGet:                  apiAccessOpen,
+	proto.QuotaListAll:              apiAccessViewer,
+
+	// capacity forecast and policy APIs
+	proto.AdminSetCapacityPolicy:       apiAccessOperator,
+	proto.AdminDeleteCapacityPolicy:    apiAccessOperator,
+	proto.AdminGetVolCapacityForecast:  apiAccessViewer,
+	proto.AdminGetZoneCapacityForecast: apiAccessViewer,
+
+	// node and task APIs
+	proto.AddLcNode:               apiAccessOpen,
+	proto.AdminLcNode:             apiAccessOperator,
+	proto.GetDataNodeTaskResponse: apiAccessOpen,
+	proto.GetMetaNodeTaskResponse: apiAccessOpen,
+	proto.GetLcNodeTaskResponse:   apiAccessOpen,
+	proto.PutLcNodeSnapshotDiff:   apiAccessOpen,
+
+	// meta partition management APIs
+	proto.AdminLoadMetaPartition:          apiAccessOperator,
+	proto.AdminDecommissionMetaPartition:  apiAccessOperator,
+	proto.AdminChangeMetaPartitionLeader:  apiAccessOperator,
+	proto.AdminBalanceMetaPartitionLeader: apiAccessOperator,
+	proto.ClientMetaPartitions:            apiAccessOpen,
+	proto.ClientMetaPartition:             apiAccessOpen,
+	proto.AdminCreateMetaPartition:        apiAccessVolume,
+	proto.AdminAddMetaReplica:             apiAccessOperator,
+	proto.AdminDeleteMetaReplica:          apiAccessOperator,
+	proto.AdminDiagnoseMetaPartition:      apiAccessViewer,
+
+	// qos APIs
+	proto.QosUpload:              apiAccessOpen,
+	proto.QosGetStatus:           apiAccessViewer,
+	proto.QosGetClientsLimitInfo: apiAccessViewer,
+	proto.QosUpdate:              apiAccessOperator,
+	proto.QosUpdateZoneLimit:     apiAccessOperator,
+	proto.QosGetZoneLimitInfo:    apiAccessViewer,
+	proto.QosUpdateMasterLimit:   apiAccessOperator,
+	proto.QosUpdateMagnify:       apiAccessOperator,
+	proto.QosUpdateClientParam:   apiAccessOperator,
+	proto.QosSetTenant:           apiAccessOperator,
+	proto.QosDeleteTenant:        apiAccessOperator,
+	proto.QosGetTenantStatus:     apiAccessViewer,
+	proto.QosObjectNodeReport:    apiAccessOpen,
+
+	// data partition management APIs
+	proto.AdminGetDataPartition:                     apiAccessOpen,
+	proto.AdminCreateDataPartition:                  apiAccessVolume,
+	proto.AdminCreatePreLoadDataPartition:           apiAccessOpen,
+	proto.AdminDataPartitionChangeLeader:            apiAccessOperator,
+	proto.AdminLoadDataPartition:                    apiAccessOperator,
+	proto.AdminDecommissionDataPartition:            apiAccessOperator,
+	proto.AdminDiagnoseDataPartition:                apiAccessViewer,
+	proto.ClientDataPartitions:                      apiAccessOpen,
+	proto.AdminResetDataPartitionDecommissionStatus: apiAccessOperator,
+	proto.AdminQueryDataPartitionDecommissionStatus: apiAccessViewer,
+	proto.AdminAddDataReplica:                       apiAccessOperator,
+	proto.AdminDeleteDataReplica:                    apiAccessOperator,
+	proto.AdminPutDataPartitions:                    apiAccessOpen,
+	proto.AdminSetDpRdOnly:                          apiAccessOperator,
+	proto.AdminSetDpDiscard:                         apiAccessOperator,
+	proto.AdminGetDiscardDp:                         apiAccessViewer,
+
+	// meta node management APIs
+	proto.AddMetaNode:               apiAccessOpen,
+	proto.DecommissionMetaNode:      apiAccessOperator,
+	proto.MigrateMetaNode:           apiAccessOperator,
+	proto.GetMetaNode:               apiAccessOpen,
+	proto.AdminSetMetaNodeThreshold: apiAccessOperator,
+	proto.AdminUpdateMetaNode:       apiAccessOperator,
+
+	// data node management APIs
+	proto.AddDataNode:                        apiAccessOpen,
+	proto.DecommissionDataNode:               apiAccessOperator,
+	proto.QueryDataNodeDecoProgress:          apiAccessViewer,
+	proto.MigrateDataNode:                    apiAccessOperator,
+	proto.CancelDecommissionDataNode:         apiAccessOperator,
+	proto.QueryDataNodeDecoFailedDps:         apiAccessViewer,
+	proto.GetDataNode:                        apiAccessOpen,
+	proto.AdminUpdateDataNode:                apiAccessOperator,
+	proto.AdminGetInvalidNodes:               apiAccessViewer,
+	proto.DecommissionDisk:                   apiAccessOperator,
+	proto.RecommissionDisk:                   apiAccessOperator,
+	proto.RestoreStoppedAutoDecommissionDisk: apiAccessOperator,
+	proto.QueryDiskDecoProgress:              apiAccessViewer,
+	proto.MarkDecoDiskFixed:                  apiAccessOperator,
+	proto.CancelDecommissionDisk:             apiAccessOperator,
+	proto.QueryDecommissionDiskDecoFailedDps: apiAccessViewer,
+	proto.QueryBadDisks:                      apiAccessViewer,
+	proto.QueryAllDecommissionDisk:           apiAccessViewer,
+	proto.QueryDisableDisk:                   apiAccessViewer,
+
+	// node set and zone APIs
+	proto.AdminSetNodeInfo:               apiAccessAdmin,
+	proto.AdminGetNodeInfo:               apiAccessViewer,
+	proto.AdminGetIsDomainOn:             apiAccessViewer,
+	proto.AdminGetAllNodeSetGrpInfo:      apiAccessViewer,
+	proto.AdminGetNodeSetGrpInfo:         apiAccessViewer,
+	proto.AdminUpdateNodeSetCapcity:      apiAccessOperator,
+	proto.AdminUpdateNodeSetId:           apiAccessOperator,
+	proto.AdminUpdateNodeSetNodeSelector: apiAccessOperator,
+	proto.AdminUpdateDomainDataUseRatio:  apiAccessOperator,
+	proto.AdminUpdateZoneExcludeRatio:    apiAccessOperator,
+	proto.AdminSetNodeRdOnly:             apiAccessOperator,
+	proto.UpdateZone:                     apiAccessOperator,
+	proto.GetAllZones:                    apiAccessViewer,
+	proto.GetAllNodeSets:                 apiAccessViewer,
+	proto.GetNodeSet:                     apiAccessViewer,
+	proto.UpdateNodeSet:                  apiAccessOperator,
+
+	// user management APIs, the key lookups serve the object nodes signed by an admin
+	proto.UserCreate:          apiAccessAdmin,
+	proto.UserDelete:          apiAccessAdmin,
+	proto.UserUpdate:          apiAccessAdmin,
+	proto.UserUpdatePolicy:    apiAccessAdmin,
+	proto.UserRemovePolicy:    apiAccessAdmin,
+	proto.UserDeleteVolPolicy: apiAccessAdmin,
+	proto.UserGetAKInfo:       apiAccessUserKey,
+	proto.UserGetInfo:         apiAccessUserKey,
+	proto.UserList:            apiAccessAdmin,
+	proto.UserTransferVol:     apiAccessAdmin,
+	proto.UsersOfVol:          apiAccessViewer,
+
+	// s3 qos APIs
+	proto.S3QoSSet:    apiAccessOperator,
+	proto.S3QoSGet:    apiAccessOpen,
+	proto.S3QoSDelete: apiAccessOperator,
+}
+
+// apiAccessOf returns the access needed by the request, a route missing from
+// apiAccessMap is kept for admins.
+func apiAccessOf(r *http.Request) apiAccess {
+	switch r.URL.Path {
+	case proto.AdminACL, proto.AdminUid:
+		// the clients check their ip and uid on mounting
+		switch r.URL.Query().Get(OperateKey) {
+		case strconv.Itoa(util.AclCheckIP):
+			return apiAccessOpen
+		case strconv.Itoa(util.AclListIP):
+			return apiAccessViewer
+		default:
+			return apiAccessVolume
+		}
+	}
+	if access, ok := apiAccessMap[r.URL.Path]; ok {
+		return access
+	}
+	return apiAccessAdmin
+}
+
+// authenticateAdminRequest returns the user which signed the request, nil for
+// an unsigned request.
+func (m *Server) authenticateAdminRequest(r *http.Request) (userInfo *proto.UserInfo, err error) {
+	accessKey := r.Header.Get(proto.AdminAccessKeyHeader)
+	if accessKey == "" {
+		return nil, nil
+	}
+	date := r.Header.Get(proto.AdminDateHeader)
+	sec, err := strconv.ParseInt(date, 10, 64)
+	if err != nil {
+		return nil, proto.ErrInvalidAdminSignature
+	}
+	nonce := r.Header.Get(proto.AdminNonceHeader)
+	if nonce == "" {
+		return nil, proto.ErrInvalidAdminSignature
+	}
+	if skew := time.Since(time.Unix(sec, 0)); skew > proto.AdminSignatureExpire || skew < -proto.AdminSignatureExpire {
+		return nil, proto.ErrInvalidAdminSignature
+	}
+	if userInfo, err = m.user.getKeyInfo(accessKey); err != nil {
+		return nil, proto.ErrInvalidAdminSignature
+	}
+	var body []byte
+	if r.Body != nil {
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return nil, proto.ErrReadBodyError
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	userInfo.Mu.RLock()
+	secretKey := userInfo.SecretKey
+	userInfo.Mu.RUnlock()
+	signature := proto.AdminRequestSignature(secretKey, r.Method, r.URL.Path, r.URL.Query(), date, nonce, body)
+	if !hmac.Equal([]byte(signature), []byte(r.Header.Get(proto.AdminSignatureHeader))) {
+		return nil, proto.ErrInvalidAdminSignature
+	}
+	if !m.adminReplay.add(signature, time.Unix(sec, 0).Add(proto.AdminSignatureExpire)) {
+		log.LogWarnf("action[authenticateAdminRequest] replayed request, remote[%v] ak[%v] path[%v]",
+			r.RemoteAddr, accessKey, r.URL.Path)
+		return nil, proto.ErrInvalidAdminSignature
+	}
+	return userInfo, nil
+}
+
+// adminReplayCache keeps the signatures of the admin requests until they
+// expire, a signature seen twice is a replayed request.
+type adminReplayCache struct {
+	sync.Mutex
+	signatures map[string]time.Time
+	nextPurge  time.Time
+}
+
+func (c *adminReplayCache) add(signature string, expire time.Time) bool {
+	c.Lock()
+	defer c.Unlock()
+	now := time.Now()
+	if c.signatures == nil {
+		c.signatures = make(map[string]time.Time)
+	}
+	if now.After(c.nextPurge) {
+		for sign, exp := range c.signatures {
+			if now.After(exp) {
+				delete(c.signatures, sign)
+			}
+		}
+		c.nextPurge = now.Add(time.Minute)
+	}
+	if _, ok := c.signatures[signature]; ok {
+		return false
+	}
+	c.signatures[signature] = expire
+	return true
+}
+
+// replyUserInfo replies the user info, the secret key is stripped unless the
+// caller is the user itself or an admin.
+func (m *Server) replyUserInfo(w http.ResponseWriter, r *http.Request, userInfo *proto.UserInfo) {
+	if m.config.RBACEnable {
+		caller, _ := r.Context().Value(proto.UserInfoKey).(*proto.UserInfo)
+		role, _ := r.Context().Value(adminRoleKey).(string)
+		if role != proto.AdminRoleAdmin && (caller == nil || caller.UserID != userInfo.UserID) {
+			userInfo.Mu.RLock()
+			stripped := &proto.UserInfo{
+				UserID:      userInfo.UserID,
+				AccessKey:   userInfo.AccessKey,
+				Policy:      userInfo.Policy,
+				UserType:    userInfo.UserType,
+				CreateTime:  userInfo.CreateTime,
+				Description: userInfo.Description,
+				Role:        userInfo.Role,
+			}
+			userInfo.Mu.RUnlock()
+			userInfo = stripped
+		}
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(userInfo))
+}
+
+// ownsVolume checks the volume named by the request belongs to the user, or
+// the volume to create is owned by the user.
+func (m *Server) ownsVolume(r *http.Request, userID string) bool {
+	if r.URL.Path == proto.AdminCreateVol {
+		return r.URL.Query().Get(volOwnerKey) == userID
+	}
+	if r.URL.Path == proto.AdminCloneVol && r.URL.Query().Get(volOwnerKey) != userID {
+		return false
+	}
+	vol, err := m.cluster.getVol(r.URL.Query().Get(nameKey))
+	if err != nil {
+		return false
+	}
+	return vol.Owner == userID
+}
+
+func (m *Server) checkAdminAccess(r *http.Request, access apiAccess, userInfo *proto.UserInfo, role string) error {
+	if !m.config.RBACEnable {
+		return nil
+	}
+	switch access {
+	case apiAccessOpen, apiAccessUserKey:
+		return nil
+	case apiAccessViewer:
+		if role != "" {
+			return nil
+		}
+	case apiAccessVolume:
+		if role == proto.AdminRoleOperator || role == proto.AdminRoleAdmin {
+			return nil
+		}
+		if role == proto.AdminRoleVolumeOwner && userInfo != nil && m.ownsVolume(r, userInfo.UserID) {
+			return nil
+		}
+	case apiAccessOperator:
+		if role == proto.AdminRoleOperator || role == proto.AdminRoleAdmin {
+			return nil
+		}
+	case apiAccessAdmin:
+		if role == proto.AdminRoleAdmin {
+			return nil
+		}
+	}
+	return proto.ErrNoPermission
+}
+
+func (m *Server) registerRBACMiddleware(router *mux.Router) {
+	var interceptor mux.MiddlewareFunc = func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				access := apiAccessOf(r)
+				if access == apiAccessOpen {
+					next.ServeHTTP(w, r)
+					return
+				}
+				start := time.Now()
+				userInfo, err := m.authenticateAdminRequest(r)
+				user, role := "-", m.config.RBACAnonymousRole
+				if userInfo != nil {
+					user, role = userInfo.UserID, userInfo.AdminRole()
+				}
+				if err == nil {
+					err = m.checkAdminAccess(r, access, userInfo, role)
+				}
+				if err != nil {
+					log.LogWarnf("action[rbacInterceptor] reject request, remote[%v] user[%v] role[%v] path[%v] err[%v]",
+						r.RemoteAddr, user, role, r.URL.Path, err)
+					if access != apiAccessViewer && access != apiAccessUserKey {
+						m.auditAdminRequest(r, user, role, err.Error(), start)
+					}
+					sendErrReply(w, r, newErrHTTPReply(err))
+					return
+				}
+				if userInfo != nil {
+					r = r.WithContext(context.WithValue(r.Context(), proto.UserInfoKey, userInfo))
+				}
+				r = r.WithContext(context.WithValue(r.Context(), adminRoleKey, role))
+				if access == apiAccessViewer || access == apiAccessUserKey {
+					next.ServeHTTP(w, r)
+					return
+				}
+				aw := &auditResponseWriter{ResponseWriter: w}
+				next.ServeHTTP(aw, r)
+				m.auditAdminRequest(r, user, role, aw.result(), start)
+			})
+	}
+	router.Use(interceptor)
+}
+
+// auditResponseWriter keeps the head of the reply to tell the result of the call.
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status int
+	reply  bytes.Buffer
+}
+
+func (w *auditResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if left := adminAuditReplyMax - w.reply.Len(); left > 0 {
+		if len(b) > left {
+			w.reply.Write(b[:left])
+		} else {
+			w.reply.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditResponseWriter) result() string {
+	if w.status != 0 && w.status != http.StatusOK {
+		return fmt.Sprintf("status %v", w.status)
+	}
+	data := w.reply.Bytes()
+	if encoding := w.Header().Get(proto.HeaderContentEncoding); encoding != "" {
+		var err error
+		if data, err = compressor.New(encoding).Decompress(data); err != nil {
+			return "ok"
+		}
+	}
+	reply := &proto.HTTPReply{}
+	if err := json.Unmarshal(data, reply); err != nil || reply.Code == proto.ErrCodeSuccess {
+		return "ok"
+	}
+	return fmt.Sprintf("code %v: %v", reply.Code, reply.Msg)
+}
+
+// auditAdminRequest writes the entry of an admin call:
+// TIME TIME_ZONE, REMOTE, USER, ROLE, PATH, QUERY, RESULT, LATENCY
+func (m *Server) auditAdminRequest(r *http.Request, user, role, result string, start time.Time) {
+	if m.adminAudit == nil {
+		return
+	}
+	remote := r.RemoteAddr
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		remote = forwarded
+	}
+	if role == "" {
+		role = "-"
+	}
+	now := time.Now()
+	zone, _ := now.Zone()
+	m.adminAudit.AddLog(fmt.Sprintf("%s %s, %s, %s, %s, %s, %s, %s, %v us", now.Format("2006-01-02 15:04:05"), zone,
+		remote, user, role, r.URL.Path, r.URL.RawQuery, result, time.Since(start).Microseconds()))
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func TestApiAccessOf(t *testing.T) {
+	cases := []struct {
+		uri    string
+		access apiAccess
+	}{
+		{proto.AddDataNode + "?addr=127.0.0.1:17310", apiAccessOpen},
+		{proto.ClientDataPartitions + "?name=vol", apiAccessOpen},
+		{proto.AdminListVols, apiAccessViewer},
+		{proto.UserGetAKInfo + "?ak=ak", apiAccessUserKey},
+		{proto.AdminVolExpand + "?name=vol", apiAccessVolume},
+		{proto.DecommissionDataNode + "?addr=127.0.0.1:17310", apiAccessOperator},
+		{proto.AdminSetConfig, apiAccessAdmin},
+		{proto.AdminACL + "?op=3", apiAccessOpen},
+		{proto.AdminACL + "?op=0", apiAccessViewer},
+		{proto.AdminACL + "?op=1", apiAccessVolume},
+		{"/unknown/route", apiAccessAdmin},
+	}
+	for _, c := range cases {
+		if access := apiAccessOf(httptest.NewRequest("GET", c.uri, nil)); access != c.access {
+			t.Errorf("access of %v expect %v but %v", c.uri, c.access, access)
+		}
+	}
+}
+
+func TestCheckAdminAccess(t *testing.T) {
+	m := &Server{config: &clusterConfig{RBACEnable: true}}
+	r := httptest.NewRequest("GET", proto.AdminSetConfig, nil)
+	cases := []struct {
+		access  apiAccess
+		role    string
+		allowed bool
+	}{
+		{apiAccessOpen, "", true},
+		{apiAccessUserKey, "", true},
+		{apiAccessViewer, "", false},
+		{apiAccessViewer, proto.AdminRoleViewer, true},
+		{apiAccessViewer, proto.AdminRoleVolumeOwner, true},
+		{apiAccessOperator, proto.AdminRoleViewer, false},
+		{apiAccessOperator, proto.AdminRoleOperator, true},
+		{apiAccessVolume, proto.AdminRoleOperator, true},
+		{apiAccessAdmin, proto.AdminRoleOperator, false},
+		{apiAccessAdmin, proto.AdminRoleAdmin, true},
+	}
+	for _, c := range cases {
+		err := m.checkAdminAccess(r, c.access, nil, c.role)
+		if (err == nil) != c.allowed {
+			t.Errorf("role %v on access %v expect allowed %v but err %v", c.role, c.access, c.allowed, err)
+		}
+	}
+
+	m.config.RBACEnable = false
+	if err := m.checkAdminAccess(r, apiAccessAdmin, nil, ""); err != nil {
+		t.Errorf("rbac disabled but err %v", err)
+	}
+}
+
+func TestAdminReplayCache(t *testing.T) {
+	c := &adminReplayCache{}
+	expire := time.Now().Add(proto.AdminSignatureExpire)
+	if !c.add("sign", expire) || c.add("sign", expire) {
+		t.Errorf("the signature seen twice should be rejected")
+	}
+	if !c.add("other", expire) {
+		t.Errorf("the other signature should be accepted")
+	}
+
+	// the expired signatures are purged
+	c.add("expired", time.Now().Add(-time.Second))
+	c.nextPurge = time.Time{}
+	c.add("new", expire)
+	if _, ok := c.signatures["expired"]; ok {
+		t.Errorf("the expired signature is not purged")
+	}
+}
+
+func TestReplyUserInfo(t *testing.T) {
+	m := &Server{config: &clusterConfig{RBACEnable: true}}
+	target := &proto.UserInfo{UserID: "u1", AccessKey: "ak1", SecretKey: "sk1"}
+	cases := []struct {
+		caller *proto.UserInfo
+		role   string
+		secret bool
+	}{
+		{nil, proto.AdminRoleViewer, false},
+		{&proto.UserInfo{UserID: "u2"}, proto.AdminRoleOperator, false},
+		{&proto.UserInfo{UserID: "u1"}, proto.AdminRoleVolumeOwner, true},
+		{&proto.UserInfo{UserID: "root"}, proto.AdminRoleAdmin, true},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", proto.UserGetAKInfo+"?ak=ak1", nil)
+		ctx := context.WithValue(r.Context(), adminRoleKey, c.role)
+		if c.caller != nil {
+			ctx = context.WithValue(ctx, proto.UserInfoKey, c.caller)
+		}
+		w := httptest.NewRecorder()
+		m.replyUserInfo(w, r.WithContext(ctx), target)
+		reply := &struct {
+			Data *proto.UserInfo `json:"data"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), reply); err != nil {
+			t.Fatalf("decode reply %v: %v", w.Body.String(), err)
+		}
+		if (reply.Data.SecretKey == "sk1") != c.secret || reply.Data.AccessKey != "ak1" {
+			t.Errorf("caller %v role %v expect secret %v but %+v", c.caller, c.role, c.secret, reply.Data)
+		}
+	}
+}
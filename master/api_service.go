@@ -342,6 +342,7 @@ func (m *Server) getTopology(w http.ResponseWriter, r *http.Request) {
 				nsView.DataNodes = append(nsView.DataNodes, proto.NodeView{
 					ID: dataNode.ID, Addr: dataNode.Addr,
 					DomainAddr: dataNode.DomainAddr, IsActive: dataNode.isActive, IsWritable: dataNode.isWriteAble(),
+					Rack: dataNode.Rack, Host: dataNode.Host,
 				})
 				return true
 			})
@@ -350,6 +351,7 @@ func (m *Server) getTopology(w http.ResponseWriter, r *http.Request) {
 				nsView.MetaNodes = append(nsView.MetaNodes, proto.NodeView{
 					ID: metaNode.ID, Addr: metaNode.Addr,
 					DomainAddr: metaNode.DomainAddr, IsActive: metaNode.IsActive, IsWritable: metaNode.isWritable(),
+					Rack: metaNode.Rack, Host: metaNode.Host,
 				})
 				return true
 			})
@@ -2131,6 +2133,7 @@ func (m *Server) updateVol(w http.ResponseWriter, r *http.Request) {
 	newArgs.dpSelectorName = req.dpSelectorName
 	newArgs.dpSelectorParm = req.dpSelectorParm
 	newArgs.enablePosixAcl = req.enablePosixAcl
+	newArgs.enableDedup = req.enableDedup
 	newArgs.enableTransaction = req.enableTransaction
 	newArgs.txTimeout = req.txTimeout
 	newArgs.txConflictRetryNum = req.txConflictRetryNum
@@ -2244,6 +2247,97 @@ func (m *Server) volShrink(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
+func (m *Server) setCapacityPolicy(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		body   []byte
+		policy = &proto.CapacityPolicy{}
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminSetCapacityPolicy))
+	defer func() {
+		doStatAndMetric(proto.AdminSetCapacityPolicy, metric, err, map[string]string{exporter.Vol: policy.Vol})
+	}()
+
+	if body, err = io.ReadAll(r.Body); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = json.Unmarshal(body, policy); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = checkCapacityPolicy(policy); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if _, err = m.cluster.getVol(policy.Vol); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.capacityMgr.setPolicy(policy); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("set capacity policy of vol %v successfully", policy.Vol)))
+}
+
+func (m *Server) deleteCapacityPolicy(w http.ResponseWriter, r *http.Request) {
+	var (
+		err  error
+		name string
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminDeleteCapacityPolicy))
+	defer func() {
+		doStatAndMetric(proto.AdminDeleteCapacityPolicy, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.capacityMgr.deletePolicy(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("delete capacity policy of vol %v successfully", name)))
+}
+
+// getVolCapacityForecast returns the forecast and policy of the vol given by name, or of all the vols.
+func (m *Server) getVolCapacityForecast(w http.ResponseWriter, r *http.Request) {
+	var (
+		err  error
+		name string
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminGetVolCapacityForecast))
+	defer func() {
+		doStatAndMetric(proto.AdminGetVolCapacityForecast, metric, err, nil)
+	}()
+
+	if name = r.FormValue(nameKey); name == "" {
+		sendOkReply(w, r, newSuccessHTTPReply(m.cluster.capacityMgr.listVolStatus()))
+		return
+	}
+	if _, err = m.cluster.getVol(name); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	list := make([]*proto.VolCapacityStatus, 0, 1)
+	if status := m.cluster.capacityMgr.getVolStatus(name); status != nil {
+		list = append(list, status)
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(list))
+}
+
+func (m *Server) getZoneCapacityForecast(w http.ResponseWriter, r *http.Request) {
+	var err error
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminGetZoneCapacityForecast))
+	defer func() {
+		doStatAndMetric(proto.AdminGetZoneCapacityForecast, metric, err, nil)
+	}()
+
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.capacityMgr.listZoneStatus(r.FormValue(zoneNameKey))))
+}
+
 func (m *Server) checkCreateReq(req *createVolReq) (err error) {
 	if !proto.IsHot(req.volType) && !proto.IsCold(req.volType) {
 		return fmt.Errorf("vol type %d is illegal", req.volType)
@@ -2269,8 +2363,13 @@ func (m *Server) checkCreateReq(req *createVolReq) (err error) {
 		if req.dpReplicaNum > 3 {
 			return fmt.Errorf("hot vol's replicaNum should be 1 to 3, received replicaNum is[%v]", req.dpReplicaNum)
 		}
-		return nil
-	} else if proto.IsCold(req.volType) {
+		return checkEcShardNum(req.ecDataNum, req.ecParityNum)
+	}
+
+	if req.ecDataNum != 0 || req.ecParityNum != 0 {
+		return fmt.Errorf("erasure coding is only supported by hot vol")
+	}
+	if proto.IsCold(req.volType) {
 		if req.dpReplicaNum > 16 {
 			return fmt.Errorf("cold vol's replicaNum should less then 17, received replicaNum is[%v]", req.dpReplicaNum)
 		}
@@ -2340,6 +2439,17 @@ func (m *Server) checkCreateReq(req *createVolReq) (err error) {
 	return nil
 }
 
+func checkEcShardNum(dataNum, parityNum uint8) error {
+	if dataNum == 0 && parityNum == 0 {
+		return nil
+	}
+	if dataNum < 2 || dataNum > maxEcDataNum || parityNum < 1 || parityNum > maxEcParityNum {
+		return fmt.Errorf("ecDataNum should be 2 to %d and ecParityNum 1 to %d, received [%v] [%v]",
+			maxEcDataNum, maxEcParityNum, dataNum, parityNum)
+	}
+	return nil
+}
+
 func (m *Server) createVol(w http.ResponseWriter, r *http.Request) {
 	req := &createVolReq{}
 	vol := &Vol{}
@@ -2380,6 +2490,102 @@ func (m *Server) createVol(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
+func (m *Server) setTenantQos(w http.ResponseWriter, r *http.Request) {
+	var (
+		err  error
+		body []byte
+		q    = &proto.TenantQos{}
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.QosSetTenant))
+	defer func() {
+		doStatAndMetric(proto.QosSetTenant, metric, err, nil)
+	}()
+
+	if body, err = io.ReadAll(r.Body); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = json.Unmarshal(body, q); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = checkTenantQos(q); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.tenantQosMgr.setTenant(q); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("set qos of tenant %v successfully", q.Tenant)))
+}
+
+func (m *Server) deleteTenantQos(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		tenant string
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.QosDeleteTenant))
+	defer func() {
+		doStatAndMetric(proto.QosDeleteTenant, metric, err, nil)
+	}()
+
+	if tenant = r.FormValue(tenantKey); tenant == "" {
+		err = keyNotFound(tenantKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.tenantQosMgr.deleteTenant(tenant); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("delete qos of tenant %v successfully", tenant)))
+}
+
+func (m *Server) getTenantQosStatus(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		status []*proto.TenantQosStatus
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.QosGetTenantStatus))
+	defer func() {
+		doStatAndMetric(proto.QosGetTenantStatus, metric, err, nil)
+	}()
+
+	if status, err = m.cluster.tenantQosMgr.getStatus(r.FormValue(tenantKey)); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(status))
+}
+
+func (m *Server) objectNodeQosReport(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		body   []byte
+		report = &proto.ObjectNodeQosReport{}
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.QosObjectNodeReport))
+	defer func() {
+		doStatAndMetric(proto.QosObjectNodeReport, metric, err, nil)
+	}()
+
+	if body, err = io.ReadAll(r.Body); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = json.Unmarshal(body, report); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if report.Addr == "" {
+		err = keyNotFound(addrKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.tenantQosMgr.handleObjectNodeReport(report)))
+}
+
 func (m *Server) qosUpload(w http.ResponseWriter, r *http.Request) {
 	var (
 		err   error
@@ -2456,6 +2662,7 @@ func (m *Server) getVolSimpleInfo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	volView := newSimpleView(vol)
+	volView.CapacityStatus = m.cluster.capacityMgr.getVolStatus(name)
 
 	sendOkReply(w, r, newSuccessHTTPReply(volView))
 }
@@ -2480,6 +2687,8 @@ func newSimpleView(vol *Vol) (view *proto.SimpleVolView) {
 		ZoneName:                vol.zoneName,
 		DpReplicaNum:            vol.dpReplicaNum,
 		MpReplicaNum:            vol.mpReplicaNum,
+		EcDataNum:               vol.ecDataNum,
+		EcParityNum:             vol.ecParityNum,
 		InodeCount:              volInodeCount,
 		DentryCount:             volDentryCount,
 		MaxMetaPartitionID:      maxPartitionID,
@@ -2487,6 +2696,7 @@ func newSimpleView(vol *Vol) (view *proto.SimpleVolView) {
 		Capacity:                vol.Capacity,
 		FollowerRead:            vol.FollowerRead,
 		EnablePosixAcl:          vol.enablePosixAcl,
+		EnableDedup:             vol.enableDedup,
 		EnableQuota:             vol.enableQuota,
 		EnableTransaction:       proto.GetMaskString(vol.enableTransaction),
 		TxTimeout:               vol.txTimeout,
@@ -2521,6 +2731,8 @@ func newSimpleView(vol *Vol) (view *proto.SimpleVolView) {
 		LatestVer:               vol.VersionMgr.getLatestVer(),
 		Forbidden:               vol.Forbidden,
 		EnableAuditLog:          vol.EnableAuditLog,
+		CloneSource:             vol.CloneSource,
+		CloneVerSeq:             vol.CloneVerSeq,
 	}
 
 	vol.uidSpaceManager.RLock()
@@ -2563,6 +2775,8 @@ func (m *Server) addDataNode(w http.ResponseWriter, r *http.Request) {
 	var (
 		nodeAddr  string
 		zoneName  string
+		rackName  string
+		hostName  string
 		id        uint64
 		err       error
 		nodesetId uint64
@@ -2572,7 +2786,7 @@ func (m *Server) addDataNode(w http.ResponseWriter, r *http.Request) {
 		doStatAndMetric(proto.AddDataNode, metric, err, nil)
 	}()
 
-	if nodeAddr, zoneName, err = parseRequestForAddNode(r); err != nil {
+	if nodeAddr, zoneName, rackName, hostName, err = parseRequestForAddNode(r); err != nil {
 		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
 		return
 	}
@@ -2589,7 +2803,7 @@ func (m *Server) addDataNode(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	if id, err = m.cluster.addDataNode(nodeAddr, zoneName, nodesetId); err != nil {
+	if id, err = m.cluster.addDataNode(nodeAddr, zoneName, rackName, hostName, nodesetId); err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
@@ -2626,6 +2840,8 @@ func (m *Server) getDataNode(w http.ResponseWriter, r *http.Request) {
 		AvailableSpace:            dataNode.AvailableSpace,
 		ID:                        dataNode.ID,
 		ZoneName:                  dataNode.ZoneName,
+		Rack:                      dataNode.Rack,
+		Host:                      dataNode.Host,
 		Addr:                      dataNode.Addr,
 		DomainAddr:                dataNode.DomainAddr,
 		ReportTime:                dataNode.ReportTime,
@@ -4060,6 +4276,8 @@ func (m *Server) addMetaNode(w http.ResponseWriter, r *http.Request) {
 	var (
 		nodeAddr  string
 		zoneName  string
+		rackName  string
+		hostName  string
 		id        uint64
 		err       error
 		nodesetId uint64
@@ -4069,7 +4287,7 @@ func (m *Server) addMetaNode(w http.ResponseWriter, r *http.Request) {
 		doStatAndMetric(proto.AddMetaNode, metric, err, nil)
 	}()
 
-	if nodeAddr, zoneName, err = parseRequestForAddNode(r); err != nil {
+	if nodeAddr, zoneName, rackName, hostName, err = parseRequestForAddNode(r); err != nil {
 		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
 		return
 	}
@@ -4086,7 +4304,7 @@ func (m *Server) addMetaNode(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	if id, err = m.cluster.addMetaNode(nodeAddr, zoneName, nodesetId); err != nil {
+	if id, err = m.cluster.addMetaNode(nodeAddr, zoneName, rackName, hostName, nodesetId); err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
@@ -4176,6 +4394,8 @@ func (m *Server) getMetaNode(w http.ResponseWriter, r *http.Request) {
 		IsActive:                  metaNode.IsActive,
 		IsWriteAble:               metaNode.isWritable(),
 		ZoneName:                  metaNode.ZoneName,
+		Rack:                      metaNode.Rack,
+		Host:                      metaNode.Host,
 		MaxMemAvailWeight:         metaNode.MaxMemAvailWeight,
 		Total:                     metaNode.Total,
 		Used:                      metaNode.Used,
@@ -5146,6 +5366,439 @@ func (m *Server) getVolVer(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(info))
 }
 
+func (m *Server) cloneVol(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		name      string
+		cloneName string
+		owner     string
+		verSeq    uint64
+		vol       *Vol
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminCloneVol))
+	defer func() {
+		doStatAndMetric(proto.AdminCloneVol, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, err = parseVolName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if cloneName = r.FormValue(cloneNameKey); !volNameRegexp.MatchString(cloneName) {
+		err = fmt.Errorf("invalid arg %v [%v]", cloneNameKey, cloneName)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if owner, err = extractOwner(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if verSeq, err = extractUint64(r, verSeqKey); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if vol, err = m.cluster.cloneVol(name, cloneName, owner, verSeq); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVersionOpError, Msg: err.Error()})
+		return
+	}
+
+	if err = m.associateVolWithUser(owner, cloneName); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(newSimpleView(vol)))
+}
+
+func (m *Server) createSnapshotDiff(w http.ResponseWriter, r *http.Request) {
+	var (
+		err        error
+		name       string
+		fromVerSeq uint64
+		toVerSeq   uint64
+		id         string
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminSnapshotDiff))
+	defer func() {
+		doStatAndMetric(proto.AdminSnapshotDiff, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, err = parseVolName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if fromVerSeq, err = extractPositiveUint64(r, fromVerSeqKey); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if toVerSeq, err = extractUint64(r, toVerSeqKey); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if id, err = m.cluster.snapshotDiffMgr.createSnapshotDiff(name, fromVerSeq, toVerSeq); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVersionOpError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(id))
+}
+
+func (m *Server) getSnapshotDiff(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		name   string
+		id     string
+		marker int
+		limit  int
+		result *proto.SnapshotDiffResult
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminGetSnapshotDiff))
+	defer func() {
+		doStatAndMetric(proto.AdminGetSnapshotDiff, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, err = parseVolName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if id = r.FormValue(idKey); id == "" {
+		err = keyNotFound(idKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if marker, err = extractUint(r, markerKey); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if limit, err = extractUint(r, Limit); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if result, err = m.cluster.snapshotDiffMgr.getResult(name, id, marker, limit); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVersionOpError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(result))
+}
+
+func parseSnapshotPolicy(r *http.Request) (policy *proto.SnapshotPolicy, err error) {
+	var body []byte
+	if body, err = io.ReadAll(r.Body); err != nil {
+		return
+	}
+	policy = &proto.SnapshotPolicy{}
+	if err = json.Unmarshal(body, policy); err != nil {
+		return
+	}
+	return
+}
+
+func (m *Server) createSnapshotPolicy(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		policy *proto.SnapshotPolicy
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminCreateSnapshotPolicy))
+	defer func() {
+		doStatAndMetric(proto.AdminCreateSnapshotPolicy, metric, err, nil)
+	}()
+
+	if policy, err = parseSnapshotPolicy(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.snapshotPolicyMgr.createPolicy(policy); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVersionOpError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("create snapshot policy %v successfully", policy.Name)))
+}
+
+func (m *Server) updateSnapshotPolicy(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		policy *proto.SnapshotPolicy
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminUpdateSnapshotPolicy))
+	defer func() {
+		doStatAndMetric(proto.AdminUpdateSnapshotPolicy, metric, err, nil)
+	}()
+
+	if policy, err = parseSnapshotPolicy(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.snapshotPolicyMgr.updatePolicy(policy); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVersionOpError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("update snapshot policy %v successfully", policy.Name)))
+}
+
+func (m *Server) deleteSnapshotPolicy(w http.ResponseWriter, r *http.Request) {
+	var (
+		err        error
+		policyName string
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminDeleteSnapshotPolicy))
+	defer func() {
+		doStatAndMetric(proto.AdminDeleteSnapshotPolicy, metric, err, nil)
+	}()
+
+	if policyName = r.FormValue(policyKey); policyName == "" {
+		err = keyNotFound(policyKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.snapshotPolicyMgr.deletePolicy(policyName); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVersionOpError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("delete snapshot policy %v successfully", policyName)))
+}
+
+func (m *Server) attachSnapshotPolicy(w http.ResponseWriter, r *http.Request) {
+	var (
+		err        error
+		name       string
+		policyName string
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminAttachSnapshotPolicy))
+	defer func() {
+		doStatAndMetric(proto.AdminAttachSnapshotPolicy, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, err = parseVolName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if policyName = r.FormValue(policyKey); policyName == "" {
+		err = keyNotFound(policyKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.snapshotPolicyMgr.attachVol(policyName, name); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVersionOpError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("attach vol %v to snapshot policy %v successfully", name, policyName)))
+}
+
+func (m *Server) detachSnapshotPolicy(w http.ResponseWriter, r *http.Request) {
+	var (
+		err        error
+		name       string
+		policyName string
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminDetachSnapshotPolicy))
+	defer func() {
+		doStatAndMetric(proto.AdminDetachSnapshotPolicy, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, err = parseVolName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if policyName = r.FormValue(policyKey); policyName == "" {
+		err = keyNotFound(policyKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.snapshotPolicyMgr.detachVol(policyName, name); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVersionOpError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("detach vol %v from snapshot policy %v successfully", name, policyName)))
+}
+
+func (m *Server) listSnapshotPolicy(w http.ResponseWriter, r *http.Request) {
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminListSnapshotPolicy))
+	defer func() {
+		doStatAndMetric(proto.AdminListSnapshotPolicy, metric, nil, nil)
+	}()
+
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.snapshotPolicyMgr.listPolicies()))
+}
+
+func (m *Server) getSnapshotPolicyStatus(w http.ResponseWriter, r *http.Request) {
+	var (
+		err        error
+		policyName string
+		status     *proto.SnapshotPolicyStatus
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminSnapshotPolicyStatus))
+	defer func() {
+		doStatAndMetric(proto.AdminSnapshotPolicyStatus, metric, err, nil)
+	}()
+
+	if policyName = r.FormValue(policyKey); policyName == "" {
+		err = keyNotFound(policyKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if status, err = m.cluster.snapshotPolicyMgr.getStatus(policyName); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVersionOpError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(status))
+}
+
+func (m *Server) backupMetadata(w http.ResponseWriter, r *http.Request) {
+	var (
+		err  error
+		info *proto.MetadataBackupInfo
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminMetadataBackup))
+	defer func() {
+		doStatAndMetric(proto.AdminMetadataBackup, metric, err, nil)
+	}()
+
+	if info, err = m.cluster.backupMetadata(r.FormValue(fileKey)); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(info))
+}
+
+func (m *Server) listMetadataBackup(w http.ResponseWriter, r *http.Request) {
+	var (
+		err   error
+		infos []*proto.MetadataBackupInfo
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminListMetadataBackup))
+	defer func() {
+		doStatAndMetric(proto.AdminListMetadataBackup, metric, err, nil)
+	}()
+
+	if infos, err = m.cluster.listMetadataBackups(); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(infos))
+}
+
+func (m *Server) verifyMetadataBackup(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		name   string
+		report *proto.MetadataBackupVerifyReport
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminVerifyMetadataBackup))
+	defer func() {
+		doStatAndMetric(proto.AdminVerifyMetadataBackup, metric, err, nil)
+	}()
+
+	if name = r.FormValue(fileKey); name == "" {
+		err = keyNotFound(fileKey)
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if report, err = m.cluster.verifyMetadataBackup(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(report))
+}
+
+func (m *Server) listEvents(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		filter *proto.ClusterEventFilter
+	)
+
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminListEvents))
+	defer func() {
+		doStatAndMetric(proto.AdminListEvents, metric, err, nil)
+	}()
+
+	if filter, err = parseEventFilter(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.eventMgr.list(filter)))
+}
+
+func parseEventFilter(r *http.Request) (filter *proto.ClusterEventFilter, err error) {
+	filter = &proto.ClusterEventFilter{
+		Type:     r.FormValue(eventTypeKey),
+		Severity: r.FormValue(severityKey),
+		ObjectID: r.FormValue(objectKey),
+		Vol:      r.FormValue(nameKey),
+		Limit:    proto.DefaultEventQueryLimit,
+	}
+	if filter.Severity != "" && !proto.IsValidEventSeverity(filter.Severity) {
+		return nil, unmatchedKey(severityKey)
+	}
+	if value := r.FormValue(startKey); value != "" {
+		if filter.Start, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, unmatchedKey(startKey)
+		}
+	}
+	if value := r.FormValue(endKey); value != "" {
+		if filter.End, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, unmatchedKey(endKey)
+		}
+	}
+	if value := r.FormValue(Limit); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit <= 0 {
+			return nil, unmatchedKey(Limit)
+		}
+		if filter.Limit > proto.MaxEventQueryLimit {
+			filter.Limit = proto.MaxEventQueryLimit
+		}
+	}
+	return
+}
+
+// putSnapshotDiffPage persists a page of the entries of a snapshot diff job, the lcnode
+// running the job waits for the reply before putting the next page.
+func (m *Server) putSnapshotDiffPage(w http.ResponseWriter, r *http.Request) {
+	var (
+		err  error
+		body []byte
+		page = &proto.SnapshotDiffPage{}
+	)
+	if body, err = io.ReadAll(r.Body); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = json.Unmarshal(body, page); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.snapshotDiffMgr.putPage(page); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVersionOpError, Msg: err.Error()})
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("put page %v of snapshot diff %v", page.Page, page.ID)))
+}
+
 func genRespMessage(data []byte, req *proto.APIAccessReq, ts int64, key []byte) (message string, err error) {
 	var (
 		jresp []byte
@@ -120,7 +120,7 @@ func (m *Server) getUserAKInfo(w http.ResponseWriter, r *http.Request) {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
-	sendOkReply(w, r, newSuccessHTTPReply(userInfo))
+	m.replyUserInfo(w, r, userInfo)
 }
 
 func (m *Server) getUserInfo(w http.ResponseWriter, r *http.Request) {
@@ -142,7 +142,7 @@ func (m *Server) getUserInfo(w http.ResponseWriter, r *http.Request) {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
-	sendOkReply(w, r, newSuccessHTTPReply(userInfo))
+	m.replyUserInfo(w, r, userInfo)
 }
 
 func (m *Server) updateUserPolicy(w http.ResponseWriter, r *http.Request) {
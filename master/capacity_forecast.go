@@ -0,0 +1,527 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	capacitySampleInterval = 10 * time.Minute
+	// the forecast fits the samples of the last week
+	capacitySampleWindow = 7 * 24 * time.Hour
+	// one hour of samples at least to forecast
+	minCapacityForecastSamples = 6
+	// the early creation of data partitions covers the forecast growth of a day
+	earlyDpCreateHorizon = 24 * time.Hour
+	secondsPerDay        = 24 * 60 * 60
+)
+
+type usageSample struct {
+	time int64
+	used uint64
+}
+
+// usageSeries keeps the usage samples of a volume or zone in the sample window.
+// The samples are taken by the leader and kept in memory, a new leader starts a new series.
+type usageSeries struct {
+	samples []usageSample
+}
+
+func (s *usageSeries) add(now int64, used uint64) {
+	if n := len(s.samples); n > 0 && now-s.samples[n-1].time > int64(2*capacitySampleInterval/time.Second) {
+		// missed samples, e.g. another master was the leader, the series is not continuous
+		s.samples = s.samples[:0]
+	}
+	s.samples = append(s.samples, usageSample{time: now, used: used})
+	start := now - int64(capacitySampleWindow/time.Second)
+	i := 0
+	for i < len(s.samples) && s.samples[i].time < start {
+		i++
+	}
+	if i > 0 {
+		s.samples = append(s.samples[:0], s.samples[i:]...)
+	}
+}
+
+// usageGrowthPerSec fits a line to the samples by least squares and returns its slope in bytes per second.
+func usageGrowthPerSec(samples []usageSample) float64 {
+	n := float64(len(samples))
+	if n < 2 {
+		return 0
+	}
+	base := samples[0].time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x := float64(s.time - base)
+		y := float64(s.used)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+func forecastUsage(samples []usageSample, total uint64, now int64) *proto.CapacityForecast {
+	f := &proto.CapacityForecast{TotalBytes: total, DaysToFull: -1, Samples: len(samples), UpdateTime: now}
+	if len(samples) == 0 {
+		return f
+	}
+	f.UsedBytes = samples[len(samples)-1].used
+	f.WindowSec = samples[len(samples)-1].time - samples[0].time
+	if total > 0 {
+		f.UsedRatio = fixedPoint(float64(f.UsedBytes)/float64(total), 4)
+	}
+	if len(samples) < minCapacityForecastSamples {
+		return f
+	}
+	growth := usageGrowthPerSec(samples) * secondsPerDay
+	f.GrowthPerDay = int64(growth)
+	if growth > 0 && total > 0 {
+		if f.UsedBytes >= total {
+			f.DaysToFull = 0
+		} else {
+			f.DaysToFull = fixedPoint(float64(total-f.UsedBytes)/growth, 2)
+		}
+	}
+	return f
+}
+
+// capacityTriggered returns whether the usage ratio or the forecast days to full reach the thresholds, zero disables a threshold.
+func capacityTriggered(f *proto.CapacityForecast, usedRatio, daysToFull float64) bool {
+	if usedRatio > 0 && f.UsedRatio >= usedRatio {
+		return true
+	}
+	return daysToFull > 0 && f.DaysToFull >= 0 && f.DaysToFull <= daysToFull
+}
+
+func nextExpandCapacity(policy *proto.CapacityPolicy, capacity uint64) (newCapacity uint64, ok bool) {
+	if capacity >= policy.MaxCapacityGB {
+		return capacity, false
+	}
+	newCapacity = capacity + policy.ExpandStepGB
+	if newCapacity > policy.MaxCapacityGB {
+		newCapacity = policy.MaxCapacityGB
+	}
+	return newCapacity, true
+}
+
+// earlyDpCount returns the number of data partitions to create so that the free space of the writable ones
+// covers the forecast growth of the horizon.
+func earlyDpCount(growthPerDay int64, writableFree, dpSize uint64) int {
+	if growthPerDay <= 0 || dpSize == 0 {
+		return 0
+	}
+	need := uint64(float64(growthPerDay) * earlyDpCreateHorizon.Hours() / 24)
+	if need <= writableFree {
+		return 0
+	}
+	count := (need - writableFree + dpSize - 1) / dpSize
+	if count > maxNumberOfDataPartitionsForExpansion {
+		count = maxNumberOfDataPartitionsForExpansion
+	}
+	return int(count)
+}
+
+func checkCapacityPolicy(policy *proto.CapacityPolicy) error {
+	if policy.Vol == "" {
+		return fmt.Errorf("vol cannot be empty")
+	}
+	for _, ratio := range []float64{policy.ExpandUsedRatio, policy.AlertUsedRatio} {
+		if ratio < 0 || ratio > 1 {
+			return fmt.Errorf("used ratio %v should be in [0, 1]", ratio)
+		}
+	}
+	if policy.ExpandDaysToFull < 0 || policy.AlertDaysToFull < 0 {
+		return fmt.Errorf("days to full cannot be negative")
+	}
+	if policy.AutoExpand {
+		if policy.ExpandStepGB == 0 || policy.MaxCapacityGB == 0 {
+			return fmt.Errorf("expandStepGB and maxCapacityGB are required by auto expansion")
+		}
+		if policy.ExpandUsedRatio == 0 && policy.ExpandDaysToFull == 0 {
+			return fmt.Errorf("expandUsedRatio or expandDaysToFull is required by auto expansion")
+		}
+	}
+	return nil
+}
+
+type capacityManager struct {
+	sync.RWMutex
+	cluster    *Cluster
+	policies   map[string]*proto.CapacityPolicy
+	volSeries  map[string]*usageSeries
+	zoneSeries map[string]*usageSeries
+	volStatus  map[string]*proto.VolCapacityStatus
+	zoneStatus map[string]*proto.ZoneCapacityStatus
+}
+
+func newCapacityManager(c *Cluster) *capacityManager {
+	return &capacityManager{
+		cluster:    c,
+		policies:   make(map[string]*proto.CapacityPolicy),
+		volSeries:  make(map[string]*usageSeries),
+		zoneSeries: make(map[string]*usageSeries),
+		volStatus:  make(map[string]*proto.VolCapacityStatus),
+		zoneStatus: make(map[string]*proto.ZoneCapacityStatus),
+	}
+}
+
+func (mgr *capacityManager) putPolicy(policy *proto.CapacityPolicy) {
+	mgr.Lock()
+	mgr.policies[policy.Vol] = policy
+	mgr.Unlock()
+}
+
+// reset drops the policies, the sampled usage is kept.
+func (mgr *capacityManager) reset() {
+	mgr.Lock()
+	mgr.policies = make(map[string]*proto.CapacityPolicy)
+	mgr.Unlock()
+}
+
+func (mgr *capacityManager) setPolicy(policy *proto.CapacityPolicy) (err error) {
+	if err = checkCapacityPolicy(policy); err != nil {
+		return
+	}
+	if _, err = mgr.cluster.getVol(policy.Vol); err != nil {
+		return
+	}
+	cp := *policy
+	cp.UTime = time.Now().Unix()
+	mgr.Lock()
+	defer mgr.Unlock()
+	if err = mgr.cluster.syncPutCapacityPolicy(&cp); err != nil {
+		return
+	}
+	mgr.policies[cp.Vol] = &cp
+	log.LogInfof("action[setCapacityPolicy] vol %v policy %+v", cp.Vol, cp)
+	return
+}
+
+func (mgr *capacityManager) deletePolicy(volName string) (err error) {
+	mgr.Lock()
+	defer mgr.Unlock()
+	policy, ok := mgr.policies[volName]
+	if !ok {
+		return fmt.Errorf("capacity policy of vol %v not found", volName)
+	}
+	if err = mgr.cluster.syncDeleteCapacityPolicy(policy); err != nil {
+		return
+	}
+	delete(mgr.policies, volName)
+	log.LogInfof("action[deleteCapacityPolicy] vol %v", volName)
+	return
+}
+
+func (mgr *capacityManager) getPolicy(volName string) *proto.CapacityPolicy {
+	mgr.RLock()
+	defer mgr.RUnlock()
+	return mgr.policies[volName]
+}
+
+// getVolStatus returns the forecast and the policy of the vol, nil if neither exists.
+func (mgr *capacityManager) getVolStatus(volName string) *proto.VolCapacityStatus {
+	mgr.RLock()
+	defer mgr.RUnlock()
+	var status proto.VolCapacityStatus
+	if st, ok := mgr.volStatus[volName]; ok {
+		status = *st
+	}
+	status.Vol = volName
+	status.Policy = mgr.policies[volName]
+	if status.Forecast == nil && status.Policy == nil {
+		return nil
+	}
+	return &status
+}
+
+func (mgr *capacityManager) listVolStatus() (list []*proto.VolCapacityStatus) {
+	mgr.RLock()
+	names := make([]string, 0, len(mgr.volStatus))
+	for name := range mgr.volStatus {
+		names = append(names, name)
+	}
+	for name := range mgr.policies {
+		if _, ok := mgr.volStatus[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	mgr.RUnlock()
+	sort.Strings(names)
+	list = make([]*proto.VolCapacityStatus, 0, len(names))
+	for _, name := range names {
+		if st := mgr.getVolStatus(name); st != nil {
+			list = append(list, st)
+		}
+	}
+	return
+}
+
+func (mgr *capacityManager) listZoneStatus(zoneName string) (list []*proto.ZoneCapacityStatus) {
+	mgr.RLock()
+	defer mgr.RUnlock()
+	list = make([]*proto.ZoneCapacityStatus, 0, len(mgr.zoneStatus))
+	for name, st := range mgr.zoneStatus {
+		if zoneName != "" && name != zoneName {
+			continue
+		}
+		list = append(list, st)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Zone < list[j].Zone })
+	return
+}
+
+// sample adds a usage sample to the series and returns the new forecast.
+func (mgr *capacityManager) sample(series map[string]*usageSeries, name string, used, total uint64, now int64) *proto.CapacityForecast {
+	s, ok := series[name]
+	if !ok {
+		s = &usageSeries{}
+		series[name] = s
+	}
+	s.add(now, used)
+	return forecastUsage(s.samples, total, now)
+}
+
+func (mgr *capacityManager) process() {
+	now := time.Now().Unix()
+	vols := mgr.cluster.allVols()
+	for name, vol := range vols {
+		used, total := vol.totalUsedSpace(), vol.capacity()*util.GB
+		mgr.Lock()
+		f := mgr.sample(mgr.volSeries, name, used, total, now)
+		status, ok := mgr.volStatus[name]
+		if !ok {
+			status = &proto.VolCapacityStatus{Vol: name}
+			mgr.volStatus[name] = status
+		}
+		status.Forecast = f
+		policy := mgr.policies[name]
+		mgr.Unlock()
+		if policy != nil {
+			mgr.applyPolicy(vol, policy, f)
+		}
+	}
+
+	mgr.removeDeletedVolPolicies()
+
+	zones := mgr.cluster.t.getAllZones()
+	mgr.Lock()
+	defer mgr.Unlock()
+	for name := range mgr.volSeries {
+		if _, ok := vols[name]; !ok {
+			delete(mgr.volSeries, name)
+			delete(mgr.volStatus, name)
+		}
+	}
+	zoneNames := make(map[string]bool, len(zones))
+	for _, zone := range zones {
+		var used, total uint64
+		zone.dataNodes.Range(func(key, value interface{}) bool {
+			node := value.(*DataNode)
+			total += node.Total
+			used += node.Used
+			return true
+		})
+		zoneNames[zone.name] = true
+		mgr.zoneStatus[zone.name] = &proto.ZoneCapacityStatus{
+			Zone:     zone.name,
+			Forecast: mgr.sample(mgr.zoneSeries, zone.name, used, total, now),
+		}
+	}
+	for name := range mgr.zoneSeries {
+		if !zoneNames[name] {
+			delete(mgr.zoneSeries, name)
+			delete(mgr.zoneStatus, name)
+		}
+	}
+}
+
+// removeDeletedVolPolicies deletes the policies of the vols which no longer exist.
+func (mgr *capacityManager) removeDeletedVolPolicies() {
+	mgr.RLock()
+	names := make([]string, 0)
+	for name := range mgr.policies {
+		if _, err := mgr.cluster.getVol(name); err != nil {
+			names = append(names, name)
+		}
+	}
+	mgr.RUnlock()
+	for _, name := range names {
+		if err := mgr.deletePolicy(name); err != nil {
+			log.LogWarnf("action[capacityPolicy] delete policy of deleted vol %v err %v", name, err)
+		}
+	}
+}
+
+func (mgr *capacityManager) updateVolStatus(volName string, update func(status *proto.VolCapacityStatus)) {
+	mgr.Lock()
+	defer mgr.Unlock()
+	if status, ok := mgr.volStatus[volName]; ok {
+		update(status)
+	}
+}
+
+// applyPolicy raises the alert, expands the capacity and creates the data partitions of the vol by its policy.
+func (mgr *capacityManager) applyPolicy(vol *Vol, policy *proto.CapacityPolicy, f *proto.CapacityForecast) {
+	c := mgr.cluster
+	alert := ""
+	if capacityTriggered(f, policy.AlertUsedRatio, policy.AlertDaysToFull) {
+		alert = fmt.Sprintf("vol %v used %v of %v GB (%.2f%%), growth %v per day, %v days to full",
+			vol.Name, formatCapacityBytes(f.UsedBytes), vol.capacity(), f.UsedRatio*100,
+			formatCapacityBytes(uint64(maxInt64(f.GrowthPerDay, 0))), f.DaysToFull)
+		log.LogWarnf("action[capacityPolicy] %v", alert)
+		c.recordEvent(proto.EventCapacityForecast, proto.EventSeverityWarning, proto.EventObjectVol, vol.Name, vol.Name, alert)
+	}
+	mgr.updateVolStatus(vol.Name, func(status *proto.VolCapacityStatus) { status.Alert = alert })
+
+	if policy.AutoExpand && capacityTriggered(f, policy.ExpandUsedRatio, policy.ExpandDaysToFull) {
+		mgr.autoExpand(vol, policy)
+	}
+
+	if policy.EarlyDpCreate && proto.IsHot(vol.VolType) && !c.DisableAutoAllocate && !c.cfg.DisableAutoCreate && !vol.Forbidden {
+		mgr.earlyCreateDataPartitions(vol, f)
+	}
+}
+
+func (mgr *capacityManager) autoExpand(vol *Vol, policy *proto.CapacityPolicy) {
+	c := mgr.cluster
+	oldCapacity := vol.capacity()
+	newCapacity, ok := nextExpandCapacity(policy, oldCapacity)
+	if !ok {
+		msg := fmt.Sprintf("vol %v needs expansion but reached the max capacity %v GB of the policy", vol.Name, policy.MaxCapacityGB)
+		log.LogWarnf("action[capacityPolicy] %v", msg)
+		c.recordEvent(proto.EventVolAutoExpand, proto.EventSeverityWarning, proto.EventObjectVol, vol.Name, vol.Name, msg)
+		return
+	}
+	newArgs := getVolVarargs(vol)
+	newArgs.capacity = newCapacity
+	if err := c.updateVol(vol.Name, util.CalcAuthKey(vol.Owner), newArgs); err != nil {
+		log.LogErrorf("action[capacityPolicy] vol %v expand capacity from %v GB to %v GB err %v", vol.Name, oldCapacity, newCapacity, err)
+		mgr.updateVolStatus(vol.Name, func(status *proto.VolCapacityStatus) { status.LastErr = err.Error() })
+		return
+	}
+	msg := fmt.Sprintf("vol %v capacity is expanded from %v GB to %v GB", vol.Name, oldCapacity, newCapacity)
+	log.LogInfof("action[capacityPolicy] %v", msg)
+	c.recordEvent(proto.EventVolAutoExpand, proto.EventSeverityInfo, proto.EventObjectVol, vol.Name, vol.Name, msg)
+	mgr.updateVolStatus(vol.Name, func(status *proto.VolCapacityStatus) {
+		status.LastExpandTime = time.Now().Unix()
+		status.LastExpandCapacity = newCapacity
+		status.LastErr = ""
+	})
+}
+
+func (mgr *capacityManager) earlyCreateDataPartitions(vol *Vol, f *proto.CapacityForecast) {
+	if time.Since(vol.dataPartitions.lastAutoCreateTime) < capacitySampleInterval {
+		return
+	}
+	var writableFree uint64
+	for _, dp := range vol.cloneDataPartitionMap() {
+		if dp.Status == proto.ReadWrite && dp.total > dp.used {
+			writableFree += dp.total - dp.used
+		}
+	}
+	count := earlyDpCount(f.GrowthPerDay, writableFree, vol.dataPartitionSize)
+	if count == 0 {
+		return
+	}
+	vol.dataPartitions.lastAutoCreateTime = time.Now()
+	log.LogInfof("action[capacityPolicy] vol %v growth %v per day, writable free %v, create %v data partitions",
+		vol.Name, f.GrowthPerDay, writableFree, count)
+	if err := mgr.cluster.batchCreateDataPartition(vol, count, false); err != nil {
+		log.LogErrorf("action[capacityPolicy] vol %v create %v data partitions err %v", vol.Name, count, err)
+		mgr.updateVolStatus(vol.Name, func(status *proto.VolCapacityStatus) { status.LastErr = err.Error() })
+		return
+	}
+	mgr.updateVolStatus(vol.Name, func(status *proto.VolCapacityStatus) {
+		status.LastDpCreateTime = time.Now().Unix()
+		status.LastDpCreateCount = count
+	})
+}
+
+func formatCapacityBytes(size uint64) string {
+	if size >= util.GB {
+		return fmt.Sprintf("%.2f GB", float64(size)/float64(util.GB))
+	}
+	return fmt.Sprintf("%.2f MB", float64(size)/float64(util.MB))
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (c *Cluster) scheduleToForecastCapacity() {
+	go func() {
+		ticker := time.NewTicker(capacitySampleInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if c.partition != nil && c.partition.IsRaftLeader() {
+				c.capacityMgr.process()
+			}
+		}
+	}()
+}
+
+func (c *Cluster) syncPutCapacityPolicy(policy *proto.CapacityPolicy) (err error) {
+	return c.syncCapacityPolicy(opSyncPutCapacityPolicy, policy)
+}
+
+func (c *Cluster) syncDeleteCapacityPolicy(policy *proto.CapacityPolicy) (err error) {
+	return c.syncCapacityPolicy(opSyncDeleteCapacityPolicy, policy)
+}
+
+func (c *Cluster) syncCapacityPolicy(opType uint32, policy *proto.CapacityPolicy) (err error) {
+	metadata := new(RaftCmd)
+	metadata.Op = opType
+	metadata.K = capacityPolicyPrefix + policy.Vol
+	if metadata.V, err = json.Marshal(policy); err != nil {
+		return errors.New(err.Error())
+	}
+	return c.submit(metadata)
+}
+
+func (c *Cluster) loadCapacityPolicies() (err error) {
+	result, err := c.fsm.store.SeekForPrefix([]byte(capacityPolicyPrefix))
+	if err != nil {
+		return fmt.Errorf("action[loadCapacityPolicies],err:%v", err.Error())
+	}
+	c.capacityMgr.reset()
+	for _, value := range result {
+		policy := &proto.CapacityPolicy{}
+		if err = json.Unmarshal(value, policy); err != nil {
+			return fmt.Errorf("action[loadCapacityPolicies],value:%v,unmarshal err:%v", string(value), err)
+		}
+		c.capacityMgr.putPolicy(policy)
+		log.LogInfof("action[loadCapacityPolicies],vol[%v]", policy.Vol)
+	}
+	return
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+)
+
+func TestUsageSeries(t *testing.T) {
+	interval := int64(capacitySampleInterval / time.Second)
+	s := &usageSeries{}
+	now := int64(1700000000)
+	for i := 0; i < 10; i++ {
+		s.add(now+int64(i)*interval, uint64(i))
+	}
+	if len(s.samples) != 10 {
+		t.Errorf("expect 10 samples, got %v", len(s.samples))
+	}
+	// a gap restarts the series
+	s.add(now+20*interval, 100)
+	if len(s.samples) != 1 || s.samples[0].used != 100 {
+		t.Errorf("expect the series restarted, got %v", s.samples)
+	}
+	// the samples out of the window are dropped
+	s = &usageSeries{}
+	count := int(capacitySampleWindow/capacitySampleInterval) + 10
+	for i := 0; i < count; i++ {
+		s.add(now+int64(i)*interval, uint64(i))
+	}
+	if len(s.samples) != count-9 {
+		t.Errorf("expect %v samples, got %v", count-9, len(s.samples))
+	}
+}
+
+func TestForecastUsage(t *testing.T) {
+	interval := int64(capacitySampleInterval / time.Second)
+	now := int64(1700000000)
+	growthPerSample := uint64(10 * util.GB)
+	samples := make([]usageSample, 0)
+	for i := 0; i < 12; i++ {
+		samples = append(samples, usageSample{time: now + int64(i)*interval, used: 100*util.GB + uint64(i)*growthPerSample})
+	}
+	total := uint64(1000 * util.GB)
+	f := forecastUsage(samples, total, now)
+	samplesPerDay := int64(secondsPerDay) / interval
+	if f.GrowthPerDay != samplesPerDay*int64(growthPerSample) {
+		t.Errorf("expect growth %v, got %v", samplesPerDay*int64(growthPerSample), f.GrowthPerDay)
+	}
+	used := 100*util.GB + 11*growthPerSample
+	if f.UsedBytes != used {
+		t.Errorf("expect used %v, got %v", used, f.UsedBytes)
+	}
+	expectDays := fixedPoint(float64(total-used)/float64(f.GrowthPerDay), 2)
+	if f.DaysToFull != expectDays {
+		t.Errorf("expect days to full %v, got %v", expectDays, f.DaysToFull)
+	}
+
+	// not enough samples to forecast
+	f = forecastUsage(samples[:minCapacityForecastSamples-1], total, now)
+	if f.DaysToFull != -1 || f.GrowthPerDay != 0 {
+		t.Errorf("expect no forecast, got %+v", f)
+	}
+
+	// shrinking usage never gets full
+	for i := range samples {
+		samples[i].used = 500*util.GB - uint64(i)*util.GB
+	}
+	f = forecastUsage(samples, total, now)
+	if f.DaysToFull != -1 || f.GrowthPerDay >= 0 {
+		t.Errorf("expect shrinking forecast, got %+v", f)
+	}
+}
+
+func TestCapacityPolicyTriggers(t *testing.T) {
+	f := &proto.CapacityForecast{UsedRatio: 0.85, DaysToFull: 5}
+	if !capacityTriggered(f, 0.8, 0) || capacityTriggered(f, 0.9, 0) {
+		t.Errorf("used ratio trigger is wrong")
+	}
+	if !capacityTriggered(f, 0, 7) || capacityTriggered(f, 0, 3) {
+		t.Errorf("days to full trigger is wrong")
+	}
+	if capacityTriggered(&proto.CapacityForecast{UsedRatio: 0.5, DaysToFull: -1}, 0.8, 7) {
+		t.Errorf("no growth should not trigger")
+	}
+
+	policy := &proto.CapacityPolicy{ExpandStepGB: 100, MaxCapacityGB: 250}
+	if c, ok := nextExpandCapacity(policy, 100); !ok || c != 200 {
+		t.Errorf("expect 200, got %v %v", c, ok)
+	}
+	if c, ok := nextExpandCapacity(policy, 200); !ok || c != 250 {
+		t.Errorf("expect 250, got %v %v", c, ok)
+	}
+	if _, ok := nextExpandCapacity(policy, 250); ok {
+		t.Errorf("expect no expansion at the max capacity")
+	}
+
+	dpSize := uint64(120 * util.GB)
+	if n := earlyDpCount(int64(100*util.GB), 200*util.GB, dpSize); n != 0 {
+		t.Errorf("expect 0, got %v", n)
+	}
+	if n := earlyDpCount(int64(500*util.GB), 200*util.GB, dpSize); n != 3 {
+		t.Errorf("expect 3, got %v", n)
+	}
+	if n := earlyDpCount(-int64(util.GB), 0, dpSize); n != 0 {
+		t.Errorf("expect 0, got %v", n)
+	}
+
+	for _, p := range []*proto.CapacityPolicy{
+		{},
+		{Vol: "vol", AlertUsedRatio: 1.5},
+		{Vol: "vol", AlertDaysToFull: -1},
+		{Vol: "vol", AutoExpand: true, ExpandUsedRatio: 0.8},
+		{Vol: "vol", AutoExpand: true, ExpandStepGB: 10, MaxCapacityGB: 100},
+	} {
+		if checkCapacityPolicy(p) == nil {
+			t.Errorf("expect invalid policy %+v", p)
+		}
+	}
+	if err := checkCapacityPolicy(&proto.CapacityPolicy{Vol: "vol", AutoExpand: true, ExpandStepGB: 10,
+		MaxCapacityGB: 100, ExpandDaysToFull: 7}); err != nil {
+		t.Errorf("expect valid policy, got %v", err)
+	}
+}
@@ -98,6 +98,11 @@ type Cluster struct {
 	lcNodes                      sync.Map
 	lcMgr                        *lifecycleManager
 	snapshotMgr                  *snapshotDelManager
+	snapshotDiffMgr              *snapshotDiffManager
+	snapshotPolicyMgr            *snapshotPolicyManager
+	eventMgr                     *clusterEventManager
+	tenantQosMgr                 *tenantQosManager
+	capacityMgr                  *capacityManager
 	DecommissionDiskFactor       float64
 	S3ApiQosQuota                *sync.Map // (api,uid,limtType) -> limitQuota
 }
@@ -351,6 +356,11 @@ func newCluster(name string, leaderInfo *LeaderInfo, fsm *MetadataFsm, partition
 	c.lcMgr.cluster = c
 	c.snapshotMgr = newSnapshotManager()
 	c.snapshotMgr.cluster = c
+	c.snapshotDiffMgr = newSnapshotDiffManager(c)
+	c.snapshotPolicyMgr = newSnapshotPolicyManager(c)
+	c.eventMgr = newClusterEventManager(c)
+	c.tenantQosMgr = newTenantQosManager(c)
+	c.capacityMgr = newCapacityManager(c)
 	c.S3ApiQosQuota = new(sync.Map)
 	return
 }
@@ -376,6 +386,11 @@ func (c *Cluster) scheduleTask() {
 	c.scheduleToCheckDataReplicas()
 	c.scheduleToLcScan()
 	c.scheduleToSnapshotDelVerScan()
+	c.scheduleToSnapshotPolicy()
+	c.scheduleToSnapshotDiff()
+	c.scheduleToClusterEvent()
+	c.scheduleToAllocTenantQos()
+	c.scheduleToForecastCapacity()
 	c.scheduleToBadDisk()
 }
 
@@ -665,6 +680,9 @@ func (c *Cluster) checkDataNodeHeartbeat() {
 			if vol.Forbidden {
 				hbReq.ForbiddenVols = append(hbReq.ForbiddenVols, vol.Name)
 			}
+			if vol.enableDedup {
+				hbReq.DedupVols = append(hbReq.DedupVols, vol.Name)
+			}
 		}
 		tasks = append(tasks, task)
 		return true
@@ -693,6 +711,9 @@ func (c *Cluster) checkMetaNodeHeartbeat() {
 			if !vol.EnableAuditLog {
 				hbReq.DisableAuditVols = append(hbReq.DisableAuditVols, vol.Name)
 			}
+			if vol.enableDedup {
+				hbReq.DedupVols = append(hbReq.DedupVols, vol.Name)
+			}
 
 			spaceInfo := vol.uidSpaceManager.getSpaceOp()
 			hbReq.UidLimitInfo = append(hbReq.UidLimitInfo, spaceInfo...)
@@ -956,7 +977,7 @@ func (c *Cluster) updateMetaNodeBaseInfo(nodeAddr string, id uint64) (err error)
 	return
 }
 
-func (c *Cluster) addMetaNode(nodeAddr, zoneName string, nodesetId uint64) (id uint64, err error) {
+func (c *Cluster) addMetaNode(nodeAddr, zoneName, rackName, hostName string, nodesetId uint64) (id uint64, err error) {
 	c.mnMutex.Lock()
 	defer c.mnMutex.Unlock()
 
@@ -966,10 +987,17 @@ func (c *Cluster) addMetaNode(nodeAddr, zoneName string, nodesetId uint64) (id u
 		if nodesetId > 0 && nodesetId != metaNode.ID {
 			return metaNode.ID, fmt.Errorf("addr already in nodeset [%v]", nodeAddr)
 		}
+		if updateNodeLocation(&metaNode.Rack, &metaNode.Host, rackName, hostName) {
+			if err = c.syncUpdateMetaNode(metaNode); err != nil {
+				return metaNode.ID, err
+			}
+			log.LogInfof("action[addMetaNode] metanode[%v] rack[%v] host[%v]", nodeAddr, metaNode.Rack, metaNode.Host)
+		}
 		return metaNode.ID, nil
 	}
 
 	metaNode = newMetaNode(nodeAddr, zoneName, c.Name)
+	metaNode.Rack, metaNode.Host = rackName, hostName
 	zone, err := c.t.getZone(zoneName)
 	if err != nil {
 		zone = c.t.putZoneIfAbsent(newZone(zoneName))
@@ -1020,7 +1048,7 @@ errHandler:
 	return
 }
 
-func (c *Cluster) addDataNode(nodeAddr, zoneName string, nodesetId uint64) (id uint64, err error) {
+func (c *Cluster) addDataNode(nodeAddr, zoneName, rackName, hostName string, nodesetId uint64) (id uint64, err error) {
 	c.dnMutex.Lock()
 	defer c.dnMutex.Unlock()
 	var dataNode *DataNode
@@ -1029,10 +1057,17 @@ func (c *Cluster) addDataNode(nodeAddr, zoneName string, nodesetId uint64) (id u
 		if nodesetId > 0 && nodesetId != dataNode.NodeSetID {
 			return dataNode.ID, fmt.Errorf("addr already in nodeset [%v]", nodeAddr)
 		}
+		if updateNodeLocation(&dataNode.Rack, &dataNode.Host, rackName, hostName) {
+			if err = c.syncUpdateDataNode(dataNode); err != nil {
+				return dataNode.ID, err
+			}
+			log.LogInfof("action[addDataNode] datanode[%v] rack[%v] host[%v]", nodeAddr, dataNode.Rack, dataNode.Host)
+		}
 		return dataNode.ID, nil
 	}
 
 	dataNode = newDataNode(nodeAddr, zoneName, c.Name)
+	dataNode.Rack, dataNode.Host = rackName, hostName
 	dataNode.DpCntLimit = newDpCountLimiter(&c.cfg.MaxDpCntLimit)
 	zone, err := c.t.getZone(zoneName)
 	if err != nil {
@@ -1348,6 +1383,10 @@ func (c *Cluster) markDeleteVol(name, authKey string, force bool) (err error) {
 		}
 	}
 
+	if clones := c.volClonesOf(name, 0); len(clones) > 0 {
+		return fmt.Errorf("vol %s is the clone source of %v, deletion not permitted ! ", name, clones)
+	}
+
 	if proto.IsCold(vol.VolType) && vol.totalUsedSpace() > 0 && !force {
 		return fmt.Errorf("ec-vol can't be deleted if ec used size not equal 0, now(%d)", vol.totalUsedSpace())
 	}
@@ -1507,6 +1546,11 @@ func (c *Cluster) createDataPartition(volName string, preload *DataPartitionPreL
 	dp = newDataPartition(partitionID, dpReplicaNum, volName, vol.ID, proto.GetDpType(vol.VolType, isPreload), partitionTTL)
 	dp.Hosts = targetHosts
 	dp.Peers = targetPeers
+	if !isPreload && vol.ecDataNum > 0 {
+		if err = c.allocEcHosts(vol, dp, zoneName); err != nil {
+			goto errHandler
+		}
+	}
 
 	log.LogInfof("action[createDataPartition] partitionID [%v] get host [%v]", partitionID, targetHosts)
 
@@ -1583,6 +1627,9 @@ func (c *Cluster) syncCreateDataPartitionToDataNode(host string, size uint64, dp
 		return
 	}
 	task := dp.createTaskToCreateDataPartition(host, size, peers, hosts, createType, partitionType, dataNode.getDecommissionedDisks())
+	if vol, e := c.getVol(dp.VolName); e == nil && vol.enableDedup {
+		task.Request.(*proto.CreateDataPartitionRequest).EnableDedup = true
+	}
 	var resp *proto.Packet
 	if resp, err = dataNode.TaskManager.syncSendAdminTask(task); err != nil {
 		// data node is not alive or other process error
@@ -1706,6 +1753,83 @@ func (c *Cluster) chooseZoneNormal(zones []*Zone, excludeNodeSets []uint64, excl
 	return
 }
 
+// allocEcHosts chooses the data nodes holding the erasure-coded shards of the
+// data partition, the shard holders do not need to host a replica.
+func (c *Cluster) allocEcHosts(vol *Vol, dp *DataPartition, zoneName string) (err error) {
+	shardNum := int(vol.ecDataNum) + int(vol.ecParityNum)
+	zoneNum := c.decideZoneNum(vol.crossZone)
+	if dp.EcHosts, _, err = c.getHostFromNormalZone(TypeDataPartition, nil, nil, nil,
+		shardNum, zoneNum, zoneName); err != nil {
+		return
+	}
+	dp.EcDataNum = vol.ecDataNum
+	dp.EcParityNum = vol.ecParityNum
+	log.LogInfof("action[allocEcHosts] vol[%v] partitionID[%v] ec[%v+%v] hosts[%v]",
+		vol.Name, dp.PartitionID, dp.EcDataNum, dp.EcParityNum, dp.EcHosts)
+	return
+}
+
+// migrateEcShards moves the erasure-coded shards held by the data node to other nodes,
+// the replicas rebuild the shards missing on the new holders.
+func (c *Cluster) migrateEcShards(dataNode *DataNode) (err error) {
+	for _, vol := range c.allVols() {
+		for _, dp := range vol.dataPartitions.partitions {
+			index := -1
+			for i, host := range dp.EcHosts {
+				if host == dataNode.Addr {
+					index = i
+					break
+				}
+			}
+			if index < 0 {
+				continue
+			}
+			if err = c.migrateEcShard(vol, dp, index, dataNode.DecommissionDstAddr); err != nil {
+				log.LogWarnf("action[migrateEcShards] vol[%v] partitionID[%v] node[%v] err[%v]",
+					vol.Name, dp.PartitionID, dataNode.Addr, err)
+				return
+			}
+		}
+	}
+	return
+}
+
+func (c *Cluster) migrateEcShard(vol *Vol, dp *DataPartition, index int, dstAddr string) (err error) {
+	dp.RLock()
+	ecHosts := append([]string(nil), dp.EcHosts...)
+	hosts := append([]string(nil), dp.Hosts...)
+	dp.RUnlock()
+
+	var newHosts []string
+	if dstAddr != "" && !contains(ecHosts, dstAddr) {
+		newHosts = []string{dstAddr}
+	} else if newHosts, _, err = c.getHostFromNormalZone(TypeDataPartition, nil, nil, ecHosts,
+		1, 1, vol.zoneName); err != nil {
+		return
+	}
+	oldHost := ecHosts[index]
+	ecHosts[index] = newHosts[0]
+	// the replicas must know the new holder before the shards are rebuilt on it
+	for _, host := range hosts {
+		var dataNode *DataNode
+		if dataNode, err = c.dataNode(host); err != nil {
+			return
+		}
+		if _, err = dataNode.TaskManager.syncSendAdminTask(dp.createTaskToUpdateEcHosts(host, ecHosts)); err != nil {
+			return
+		}
+	}
+	dp.Lock()
+	dp.EcHosts = ecHosts
+	dp.Unlock()
+	if err = c.syncUpdateDataPartition(dp); err != nil {
+		return
+	}
+	log.LogInfof("action[migrateEcShard] vol[%v] partitionID[%v] shard[%v] from[%v] to[%v]",
+		vol.Name, dp.PartitionID, index, oldHost, newHosts[0])
+	return
+}
+
 func (c *Cluster) getHostFromNormalZone(nodeType uint32, excludeZones []string, excludeNodeSets []uint64,
 	excludeHosts []string, replicaNum int,
 	zoneNum int, specifiedZone string) (hosts []string, peers []proto.Peer, err error,
@@ -2303,7 +2427,7 @@ func (c *Cluster) migrateDataPartition(srcAddr, targetAddr string, dp *DataParti
 
 	if targetAddr != "" {
 		targetHosts = []string{targetAddr}
-	} else if targetHosts, _, err = ns.getAvailDataNodeHosts(dp.Hosts, 1); err != nil {
+	} else if targetHosts, _, err = ns.getAvailDataNodeHostToReplace(dp.Hosts, srcAddr); err != nil {
 		if _, ok := c.vols[dp.VolName]; !ok {
 			log.LogWarnf("clusterID[%v] partitionID:%v  on node:%v offline failed,PersistenceHosts:[%v]",
 				c.Name, dp.PartitionID, srcAddr, dp.Hosts)
@@ -3179,7 +3303,12 @@ func (c *Cluster) createVol(req *createVolReq) (vol *Vol, err error) {
 		log.LogError("init dataPartition error in verMgr init", err.Error())
 	}
 
-	if err = vol.initMetaPartitions(c, req.mpCount); err != nil {
+	if vol.CloneSource != "" {
+		err = vol.initCloneMetaPartitions(c)
+	} else {
+		err = vol.initMetaPartitions(c, req.mpCount)
+	}
+	if err != nil {
 
 		vol.Status = proto.VolStatusMarkDelete
 		if e := vol.deleteVolFromStore(c); e != nil {
@@ -3258,6 +3387,8 @@ func (c *Cluster) doCreateVol(req *createVolReq) (vol *Vol, err error) {
 		DataPartitionSize:       dataPartitionSize,
 		Capacity:                uint64(req.capacity),
 		DpReplicaNum:            req.dpReplicaNum,
+		EcDataNum:               req.ecDataNum,
+		EcParityNum:             req.ecParityNum,
 		ReplicaNum:              defaultReplicaNum,
 		FollowerRead:            req.followerRead,
 		Authenticate:            req.authenticate,
@@ -3292,6 +3423,9 @@ func (c *Cluster) doCreateVol(req *createVolReq) (vol *Vol, err error) {
 		FlowWlimit:   req.qosLimitArgs.flowWVal,
 
 		DpReadOnlyWhenVolFull: req.DpReadOnlyWhenVolFull,
+
+		CloneSource: req.cloneSource,
+		CloneVerSeq: req.cloneVerSeq,
 	}
 
 	log.LogInfof("[doCreateVol] volView, %v", vv)
@@ -3864,6 +3998,11 @@ func (c *Cluster) TryDecommissionDataNode(dataNode *DataNode) {
 		return
 	}
 	log.LogDebugf("action[TryDecommissionDataNode] dataNode [%s]  prepare to decommission", dataNode.Addr)
+	// the shards may be held without a replica on the node
+	if err = c.migrateEcShards(dataNode); err != nil {
+		log.LogWarnf("action[TryDecommissionDataNode] dataNode [%s] migrate ec shards err %v", dataNode.Addr, err)
+		return
+	}
 	var partitions []*DataPartition
 	disks := dataNode.getDisks(c)
 	for _, disk := range disks {
@@ -0,0 +1,310 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultEventKeepCount      = 10000
+	defaultEventKeepDays       = 30
+	defaultEventDedupSec       = 600
+	eventRecordQueueSize       = 1024
+	eventRetentionInterval     = 10 * time.Minute
+	eventRetentionDeleteBatch  = 128
+	eventDedupCleanupThreshold = 4096
+)
+
+// clusterEventManager keeps the events recorded by the leader, the events are persisted
+// through raft so that they survive leader changes, and sent to the configured sinks.
+type clusterEventManager struct {
+	cluster *Cluster
+	sync.RWMutex
+	events   []*proto.ClusterEvent // ordered by id
+	lastID   uint64
+	lastSeen map[string]int64 // type and object of the recent events, to suppress the duplicated ones
+	recordCh chan *proto.ClusterEvent
+	sinks    []*eventSinkWorker
+}
+
+func newClusterEventManager(c *Cluster) *clusterEventManager {
+	return &clusterEventManager{
+		cluster:  c,
+		events:   make([]*proto.ClusterEvent, 0),
+		lastSeen: make(map[string]int64),
+		recordCh: make(chan *proto.ClusterEvent, eventRecordQueueSize),
+	}
+}
+
+func eventDedupKey(e *proto.ClusterEvent) string {
+	return e.Type + keySeparator + e.ObjectType + keySeparator + e.ObjectID
+}
+
+// shouldRecord tells whether the event is the first one of its type and object in the dedup window.
+func (mgr *clusterEventManager) shouldRecord(e *proto.ClusterEvent, dedupSec int64) bool {
+	mgr.Lock()
+	defer mgr.Unlock()
+	key := eventDedupKey(e)
+	if last, ok := mgr.lastSeen[key]; ok && e.Time-last < dedupSec {
+		return false
+	}
+	if len(mgr.lastSeen) >= eventDedupCleanupThreshold {
+		for k, t := range mgr.lastSeen {
+			if e.Time-t >= dedupSec {
+				delete(mgr.lastSeen, k)
+			}
+		}
+	}
+	mgr.lastSeen[key] = e.Time
+	return true
+}
+
+func (mgr *clusterEventManager) allocID(now time.Time) uint64 {
+	mgr.Lock()
+	defer mgr.Unlock()
+	id := uint64(now.UnixMicro())
+	if id <= mgr.lastID {
+		id = mgr.lastID + 1
+	}
+	mgr.lastID = id
+	return id
+}
+
+func (mgr *clusterEventManager) putEvent(e *proto.ClusterEvent) {
+	mgr.Lock()
+	defer mgr.Unlock()
+	if e.ID > mgr.lastID {
+		mgr.lastID = e.ID
+	}
+	n := len(mgr.events)
+	if n == 0 || mgr.events[n-1].ID < e.ID {
+		mgr.events = append(mgr.events, e)
+		return
+	}
+	i := sort.Search(n, func(i int) bool { return mgr.events[i].ID >= e.ID })
+	if mgr.events[i].ID == e.ID {
+		mgr.events[i] = e
+		return
+	}
+	mgr.events = append(mgr.events, nil)
+	copy(mgr.events[i+1:], mgr.events[i:])
+	mgr.events[i] = e
+}
+
+func (mgr *clusterEventManager) removeEvents(ids map[uint64]bool) {
+	mgr.Lock()
+	defer mgr.Unlock()
+	kept := mgr.events[:0]
+	for _, e := range mgr.events {
+		if !ids[e.ID] {
+			kept = append(kept, e)
+		}
+	}
+	for i := len(kept); i < len(mgr.events); i++ {
+		mgr.events[i] = nil
+	}
+	mgr.events = kept
+}
+
+func (mgr *clusterEventManager) reset() {
+	mgr.Lock()
+	defer mgr.Unlock()
+	mgr.events = make([]*proto.ClusterEvent, 0)
+	mgr.lastSeen = make(map[string]int64)
+}
+
+// list returns the newest events matching the filter, newest first.
+func (mgr *clusterEventManager) list(filter *proto.ClusterEventFilter) []*proto.ClusterEvent {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = proto.DefaultEventQueryLimit
+	}
+	mgr.RLock()
+	defer mgr.RUnlock()
+	events := make([]*proto.ClusterEvent, 0)
+	for i := len(mgr.events) - 1; i >= 0 && len(events) < limit; i-- {
+		if filter.Match(mgr.events[i]) {
+			events = append(events, mgr.events[i])
+		}
+	}
+	return events
+}
+
+// expiredEvents returns the events beyond the count limit or older than the age limit.
+func (mgr *clusterEventManager) expiredEvents(now time.Time, keepCount int, keepDays int) []*proto.ClusterEvent {
+	mgr.RLock()
+	defer mgr.RUnlock()
+	minTime := now.Add(-time.Duration(keepDays) * 24 * time.Hour).Unix()
+	expired := make([]*proto.ClusterEvent, 0)
+	for i, e := range mgr.events {
+		if len(mgr.events)-i <= keepCount && e.Time >= minTime {
+			break
+		}
+		expired = append(expired, e)
+	}
+	return expired
+}
+
+func (c *Cluster) eventKeepCount() int {
+	if c.cfg.EventKeepCount <= 0 {
+		return defaultEventKeepCount
+	}
+	return c.cfg.EventKeepCount
+}
+
+func (c *Cluster) eventKeepDays() int {
+	if c.cfg.EventKeepDays <= 0 {
+		return defaultEventKeepDays
+	}
+	return c.cfg.EventKeepDays
+}
+
+func (c *Cluster) eventDedupSec() int64 {
+	if c.cfg.EventDedupSec <= 0 {
+		return defaultEventDedupSec
+	}
+	return c.cfg.EventDedupSec
+}
+
+// recordEvent queues an event to be persisted and sent to the sinks, it never blocks the caller.
+// Only the leader records events, the same type of event on the same object is recorded once in the dedup window.
+func (c *Cluster) recordEvent(typ, severity, objectType, objectID, volName, msg string) {
+	mgr := c.eventMgr
+	if mgr == nil || c.partition == nil || !c.partition.IsRaftLeader() {
+		return
+	}
+	e := &proto.ClusterEvent{
+		Cluster:    c.Name,
+		Type:       typ,
+		Severity:   severity,
+		Time:       time.Now().Unix(),
+		ObjectType: objectType,
+		ObjectID:   objectID,
+		Vol:        volName,
+		Message:    msg,
+	}
+	if !mgr.shouldRecord(e, c.eventDedupSec()) {
+		return
+	}
+	select {
+	case mgr.recordCh <- e:
+	default:
+		log.LogWarnf("action[recordEvent] event queue is full, drop event type[%v] object[%v:%v]", typ, objectType, objectID)
+	}
+}
+
+func (c *Cluster) persistEvent(e *proto.ClusterEvent) (err error) {
+	e.ID = c.eventMgr.allocID(time.Unix(e.Time, 0))
+	if err = c.syncPutEvent(e); err != nil {
+		return
+	}
+	c.eventMgr.putEvent(e)
+	return
+}
+
+func (c *Cluster) scheduleToClusterEvent() {
+	mgr := c.eventMgr
+	mgr.sinks = newEventSinkWorkers(c.cfg)
+	for _, w := range mgr.sinks {
+		go w.run()
+	}
+	go func() {
+		ticker := time.NewTicker(eventRetentionInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case e := <-mgr.recordCh:
+				if c.partition == nil || !c.partition.IsRaftLeader() {
+					continue
+				}
+				if err := c.persistEvent(e); err != nil {
+					log.LogErrorf("action[persistEvent] type[%v] object[%v:%v] err[%v]", e.Type, e.ObjectType, e.ObjectID, err)
+					continue
+				}
+				for _, w := range mgr.sinks {
+					w.send(e)
+				}
+			case <-ticker.C:
+				if c.partition != nil && c.partition.IsRaftLeader() {
+					c.cleanExpiredEvents()
+				}
+			}
+		}
+	}()
+}
+
+func (c *Cluster) cleanExpiredEvents() {
+	expired := c.eventMgr.expiredEvents(time.Now(), c.eventKeepCount(), c.eventKeepDays())
+	for len(expired) > 0 {
+		n := len(expired)
+		if n > eventRetentionDeleteBatch {
+			n = eventRetentionDeleteBatch
+		}
+		cmds := make(map[string]*RaftCmd, n)
+		ids := make(map[uint64]bool, n)
+		for _, e := range expired[:n] {
+			cmd := &RaftCmd{Op: opSyncDeleteEvent, K: eventKey(e.ID)}
+			cmds[cmd.K] = cmd
+			ids[e.ID] = true
+		}
+		if err := c.syncBatchCommitCmd(cmds); err != nil {
+			log.LogErrorf("action[cleanExpiredEvents] delete %v events err[%v]", n, err)
+			return
+		}
+		c.eventMgr.removeEvents(ids)
+		log.LogInfof("action[cleanExpiredEvents] deleted %v events", n)
+		expired = expired[n:]
+	}
+}
+
+func eventKey(id uint64) string {
+	return eventPrefix + fmt.Sprintf("%020d", id)
+}
+
+func (c *Cluster) syncPutEvent(e *proto.ClusterEvent) (err error) {
+	metadata := new(RaftCmd)
+	metadata.Op = opSyncPutEvent
+	metadata.K = eventKey(e.ID)
+	if metadata.V, err = json.Marshal(e); err != nil {
+		return errors.New(err.Error())
+	}
+	return c.submit(metadata)
+}
+
+func (c *Cluster) loadClusterEvents() (err error) {
+	result, err := c.fsm.store.SeekForPrefix([]byte(eventPrefix))
+	if err != nil {
+		return fmt.Errorf("action[loadClusterEvents],err:%v", err.Error())
+	}
+	c.eventMgr.reset()
+	for _, value := range result {
+		e := &proto.ClusterEvent{}
+		if err = json.Unmarshal(value, e); err != nil {
+			return fmt.Errorf("action[loadClusterEvents],value:%v,unmarshal err:%v", string(value), err)
+		}
+		c.eventMgr.putEvent(e)
+	}
+	log.LogInfof("action[loadClusterEvents] load %v events", len(result))
+	return
+}
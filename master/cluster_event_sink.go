@@ -0,0 +1,177 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	eventSinkQueueSize  = 1024
+	eventSinkRetryTimes = 3
+	eventSinkRetryDelay = time.Second
+	eventWebhookTimeout = 10 * time.Second
+)
+
+// eventSink delivers the cluster events to an external system.
+type eventSink interface {
+	Name() string
+	Send(e *proto.ClusterEvent) error
+}
+
+// eventSinkBuilder builds the sink from the master config, it returns nil if the sink is not configured.
+type eventSinkBuilder func(cfg *clusterConfig) eventSink
+
+var eventSinkBuilders = make(map[string]eventSinkBuilder)
+
+func registerEventSink(name string, builder eventSinkBuilder) {
+	eventSinkBuilders[name] = builder
+}
+
+func init() {
+	registerEventSink("webhook", newWebhookEventSink)
+	registerEventSink("kafka", newKafkaEventSink)
+}
+
+// eventSinkWorker sends the events to a sink in the background, so a slow sink
+// delays neither the event recording nor the other sinks.
+type eventSinkWorker struct {
+	sink  eventSink
+	queue chan *proto.ClusterEvent
+}
+
+func newEventSinkWorkers(cfg *clusterConfig) (workers []*eventSinkWorker) {
+	names := make([]string, 0, len(eventSinkBuilders))
+	for name := range eventSinkBuilders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sink := eventSinkBuilders[name](cfg)
+		if sink == nil {
+			continue
+		}
+		log.LogInfof("action[newEventSinkWorkers] event sink[%v] enabled", sink.Name())
+		workers = append(workers, &eventSinkWorker{sink: sink, queue: make(chan *proto.ClusterEvent, eventSinkQueueSize)})
+	}
+	return
+}
+
+func (w *eventSinkWorker) send(e *proto.ClusterEvent) {
+	select {
+	case w.queue <- e:
+	default:
+		log.LogWarnf("action[eventSinkSend] sink[%v] queue is full, drop event[%v]", w.sink.Name(), e.ID)
+	}
+}
+
+func (w *eventSinkWorker) run() {
+	for e := range w.queue {
+		var err error
+		for i := 0; i < eventSinkRetryTimes; i++ {
+			if err = w.sink.Send(e); err == nil {
+				break
+			}
+			time.Sleep(eventSinkRetryDelay)
+		}
+		if err != nil {
+			log.LogErrorf("action[eventSinkSend] sink[%v] event[%v] err[%v]", w.sink.Name(), e.ID, err)
+		}
+	}
+}
+
+// webhookEventSink posts every event as a json object to an http endpoint.
+type webhookEventSink struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookEventSink(cfg *clusterConfig) eventSink {
+	if cfg.EventWebhookUrl == "" {
+		return nil
+	}
+	return &webhookEventSink{url: cfg.EventWebhookUrl, client: &http.Client{Timeout: eventWebhookTimeout}}
+}
+
+func (s *webhookEventSink) Name() string {
+	return "webhook"
+}
+
+func (s *webhookEventSink) Send(e *proto.ClusterEvent) (err error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook %v returns status %v", s.url, resp.StatusCode)
+	}
+	return
+}
+
+// kafkaEventSink produces every event as a json message keyed by the object id,
+// so the events of the same object keep their order.
+type kafkaEventSink struct {
+	brokers  []string
+	topic    string
+	producer sarama.SyncProducer
+}
+
+func newKafkaEventSink(cfg *clusterConfig) eventSink {
+	if len(cfg.EventKafkaBrokers) == 0 || cfg.EventKafkaTopic == "" {
+		return nil
+	}
+	return &kafkaEventSink{brokers: cfg.EventKafkaBrokers, topic: cfg.EventKafkaTopic}
+}
+
+func (s *kafkaEventSink) Name() string {
+	return "kafka"
+}
+
+func (s *kafkaEventSink) Send(e *proto.ClusterEvent) (err error) {
+	if s.producer == nil {
+		config := sarama.NewConfig()
+		config.Producer.RequiredAcks = sarama.WaitForAll
+		config.Producer.Return.Successes = true
+		if s.producer, err = sarama.NewSyncProducer(s.brokers, config); err != nil {
+			return
+		}
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_, _, err = s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: s.topic,
+		Key:   sarama.StringEncoder(e.ObjectType + keySeparator + e.ObjectID),
+		Value: sarama.ByteEncoder(data),
+	})
+	return
+}
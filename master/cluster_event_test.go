@@ -0,0 +1,84 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func TestClusterEventDedup(t *testing.T) {
+	mgr := newClusterEventManager(nil)
+	e := &proto.ClusterEvent{Type: proto.EventMissingReplica, ObjectType: proto.EventObjectDataPartition, ObjectID: "1", Time: 1000}
+	if !mgr.shouldRecord(e, 600) {
+		t.Errorf("first event should be recorded")
+	}
+	e2 := *e
+	e2.Time = 1300
+	if mgr.shouldRecord(&e2, 600) {
+		t.Errorf("duplicated event in the window should be suppressed")
+	}
+	e3 := e2
+	e3.ObjectID = "2"
+	if !mgr.shouldRecord(&e3, 600) {
+		t.Errorf("event on another object should be recorded")
+	}
+	e2.Time = 1600
+	if !mgr.shouldRecord(&e2, 600) {
+		t.Errorf("event after the window should be recorded")
+	}
+}
+
+func TestClusterEventListAndRetention(t *testing.T) {
+	mgr := newClusterEventManager(nil)
+	now := time.Unix(100*24*3600, 0)
+	types := []string{proto.EventDiskError, proto.EventMissingReplica, proto.EventDataPartitionRdOnly}
+	for i := 0; i < 10; i++ {
+		mgr.putEvent(&proto.ClusterEvent{
+			ID:       uint64(i + 1),
+			Type:     types[i%len(types)],
+			Severity: proto.EventSeverityWarning,
+			Time:     now.Add(-time.Duration(10-i) * 24 * time.Hour).Unix(),
+		})
+	}
+	if id := mgr.allocID(time.Unix(0, 0)); id != 11 {
+		t.Errorf("alloc id %v, expect 11", id)
+	}
+
+	events := mgr.list(&proto.ClusterEventFilter{Type: proto.EventDiskError, Limit: 2})
+	if len(events) != 2 || events[0].Type != proto.EventDiskError || events[0].ID <= events[1].ID {
+		t.Errorf("list %v, expect the 2 newest disk errors", events)
+	}
+	events = mgr.list(&proto.ClusterEventFilter{Start: now.Add(-3 * 24 * time.Hour).Unix()})
+	if len(events) != 3 {
+		t.Errorf("list %v events since 3 days, expect 3", len(events))
+	}
+
+	// keep 8 events, and the ones of the last 5 days
+	expired := mgr.expiredEvents(now, 8, 5)
+	if len(expired) != 5 || expired[0].ID != 1 || expired[4].ID != 5 {
+		t.Errorf("expired %v, expect events 1-5", expired)
+	}
+	ids := make(map[uint64]bool)
+	for _, e := range expired {
+		ids[e.ID] = true
+	}
+	mgr.removeEvents(ids)
+	if events = mgr.list(&proto.ClusterEventFilter{}); len(events) != 5 || events[4].ID != 6 {
+		t.Errorf("events after retention %v, expect 6-10", events)
+	}
+}
@@ -139,7 +139,7 @@ func (c *Cluster) migrateMetaPartition(srcAddr, targetAddr string, mp *MetaParti
 		newPeers = []proto.Peer{{
 			Addr: targetAddr,
 		}}
-	} else if _, newPeers, err = ns.getAvailMetaNodeHosts(oldHosts, 1); err != nil {
+	} else if _, newPeers, err = ns.getAvailMetaNodeHostToReplace(oldHosts, srcAddr); err != nil {
 		if _, ok := c.vols[mp.volName]; !ok {
 			log.LogWarnf("[migrateMetaPartition] clusterID[%v] partitionID:%v  on node:[%v]",
 				c.Name, mp.PartitionID, mp.Hosts)
@@ -1017,6 +1017,7 @@ func (c *Cluster) handleDataNodeHeartbeatResp(nodeAddr string, resp *proto.DataN
 	dataNode.CpuUtil.Store(resp.CpuUtil)
 	dataNode.SetIoUtils(resp.IoUtils)
 
+	c.recordNewBadDisks(dataNode, resp.BadDisks)
 	dataNode.updateNodeMetric(resp)
 	if err = c.t.putDataNode(dataNode); err != nil {
 		log.LogErrorf("action[handleDataNodeHeartbeatResp] dataNode[%v],zone[%v],node set[%v], err[%v]", dataNode.Addr, dataNode.ZoneName, dataNode.NodeSetID, err)
@@ -1031,6 +1032,19 @@ errHandler:
 	return
 }
 
+func (c *Cluster) recordNewBadDisks(dataNode *DataNode, badDisks []string) {
+	dataNode.RLock()
+	oldBadDisks := dataNode.BadDisks
+	dataNode.RUnlock()
+	for _, disk := range badDisks {
+		if contains(oldBadDisks, disk) {
+			continue
+		}
+		c.recordEvent(proto.EventDiskError, proto.EventSeverityCritical, proto.EventObjectDisk, dataNode.Addr+":"+disk,
+			"", fmt.Sprintf("disk %v on dataNode %v is reported bad", disk, dataNode.Addr))
+	}
+}
+
 func (c *Cluster) adjustDataNode(dataNode *DataNode) {
 	c.dnMutex.Lock()
 	defer c.dnMutex.Unlock()
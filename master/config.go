@@ -49,6 +49,16 @@ const (
 	disableAutoCreate                   = "disableAutoCreate"
 	cfgMonitorPushAddr                  = "monitorPushAddr"
 	intervalToScanS3Expiration          = "intervalToScanS3Expiration"
+	cfgPlacementDomain                  = "placementDomain"
+	cfgRBACEnable                       = "rbacEnable"
+	cfgRBACAnonymousRole                = "rbacAnonymousRole"
+	cfgMetadataBackupDir                = "metadataBackupDir"
+	cfgEventKeepCount                   = "eventKeepCount"
+	cfgEventKeepDays                    = "eventKeepDays"
+	cfgEventDedupSec                    = "eventDedupSec"
+	cfgEventWebhookUrl                  = "eventWebhookUrl"
+	cfgEventKafkaBrokers                = "eventKafkaBrokers"
+	cfgEventKafkaTopic                  = "eventKafkaTopic"
 
 	cfgVolForceDeletion           = "volForceDeletion"
 	cfgVolDeletionDentryThreshold = "volDeletionDentryThreshold"
@@ -140,6 +150,16 @@ type clusterConfig struct {
 	MonitorPushAddr                     string
 	IntervalToScanS3Expiration          int64
 	MaxConcurrentLcNodes                uint64
+	PlacementDomain                     string // failure domain the replicas are spread across, empty to disable
+	RBACEnable                          bool   // check the role of the caller of the admin api
+	RBACAnonymousRole                   string // role of the unsigned requests, empty to reject them
+	MetadataBackupDir                   string // where the metadata backups are written, empty to disable
+	EventKeepCount                      int    // max number of the cluster events kept
+	EventKeepDays                       int    // cluster events older than this are deleted
+	EventDedupSec                       int64  // same event on the same object is recorded once in this window
+	EventWebhookUrl                     string
+	EventKafkaBrokers                   []string
+	EventKafkaTopic                     string
 
 	volForceDeletion           bool   // when delete a volume, ignore it's dentry count or not
 	volDeletionDentryThreshold uint64 // in case of volForceDeletion is set to false, define the dentry count threshold to allow volume deletion
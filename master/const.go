@@ -67,6 +67,8 @@ const (
 	akKey                      = "ak"
 	keywordsKey                = "keywords"
 	zoneNameKey                = "zoneName"
+	rackNameKey                = "rackName"
+	hostNameKey                = "hostName"
 	nodesetIdKey               = "nodesetId"
 	crossZoneKey               = "crossZone"
 	normalZonesFirstKey        = "normalZonesFirst"
@@ -92,6 +94,7 @@ const (
 	forceKey                   = "force"
 	raftForceDelKey            = "raftForceDel"
 	enablePosixAclKey          = "enablePosixAcl"
+	enableDedupKey             = "enableDedup"
 	enableTxMaskKey            = "enableTxMask"
 	txTimeoutKey               = "txTimeout"
 	txConflictRetryNumKey      = "txConflictRetryNum"
@@ -129,9 +132,22 @@ const (
 	ignoreDiscardKey           = "ignoreDiscard"
 	ClientIDKey                = "clientIDKey"
 	verSeqKey                  = "verSeq"
+	cloneNameKey               = "cloneName"
+	fromVerSeqKey              = "fromVerSeq"
+	toVerSeqKey                = "toVerSeq"
+	markerKey                  = "marker"
+	policyKey                  = "policy"
+	fileKey                    = "file"
+	eventTypeKey               = "type"
+	severityKey                = "severity"
+	objectKey                  = "object"
+	endKey                     = "end"
+	tenantKey                  = "tenant"
 	Periodic                   = "periodic"
 	DecommissionType           = "decommissionType"
 	decommissionDiskFactor     = "decommissionDiskFactor"
+	ecDataNumKey               = "ecDataNum"
+	ecParityNumKey             = "ecParityNum"
 )
 
 const (
@@ -184,6 +200,8 @@ const (
 	intervalToLoadDataPartition                  = 12 * 60 * 60
 	defaultInitDataPartitionCnt                  = 10
 	maxInitDataPartitionCnt                      = 200
+	maxEcDataNum                                 = 16
+	maxEcParityNum                               = 8
 	volExpansionRatio                            = 0.1
 	maxNumberOfDataPartitionsForExpansion        = 100
 	EmptyCrcValue                         uint32 = 4045511210
@@ -284,6 +302,21 @@ const (
 	opSyncDeleteQuota  uint32 = 0x42
 	opSyncMulitVersion uint32 = 0x53
 
+	opSyncPutSnapshotPolicy    uint32 = 0x54
+	opSyncDeleteSnapshotPolicy uint32 = 0x55
+
+	opSyncPutEvent    uint32 = 0x56
+	opSyncDeleteEvent uint32 = 0x57
+
+	opSyncPutTenantQos    uint32 = 0x58
+	opSyncDeleteTenantQos uint32 = 0x59
+
+	opSyncPutCapacityPolicy    uint32 = 0x5A
+	opSyncDeleteCapacityPolicy uint32 = 0x5B
+
+	opSyncPutSnapshotDiff    uint32 = 0x5C
+	opSyncDeleteSnapshotDiff uint32 = 0x5D
+
 	opSyncS3QosSet    uint32 = 0x60
 	opSyncS3QosDelete uint32 = 0x61
 )
@@ -339,6 +372,13 @@ const (
 	lcNodePrefix     = keySeparator + lcNodeAcronym + keySeparator
 	lcConfPrefix     = keySeparator + lcConfigurationAcronym + keySeparator
 	S3QoSPrefix      = keySeparator + S3QoS + keySeparator
+
+	snapshotPolicyPrefix = keySeparator + "snapPolicy" + keySeparator
+	eventPrefix          = keySeparator + "event" + keySeparator
+	tenantQosPrefix      = keySeparator + "tenantQos" + keySeparator
+	capacityPolicyPrefix = keySeparator + "capacityPolicy" + keySeparator
+	snapshotDiffPrefix   = keySeparator + "snapDiff" + keySeparator
+	diffPagePrefix       = keySeparator + "snapDiffPage" + keySeparator
 )
 
 // selector enum
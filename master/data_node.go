@@ -33,6 +33,8 @@ type DataNode struct {
 	AvailableSpace            uint64
 	ID                        uint64
 	ZoneName                  string `json:"Zone"`
+	Rack                      string // failure domain labels, see placement.go
+	Host                      string
 	Addr                      string
 	DomainAddr                string
 	ReportTime                time.Time
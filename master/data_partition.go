@@ -17,6 +17,7 @@ package master
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -75,6 +76,9 @@ type DataPartition struct {
 	RecoverStartTime               time.Time
 	RecoverLastConsumeTime         time.Duration
 	DecommissionWaitTimes          int
+	EcDataNum                      uint8
+	EcParityNum                    uint8
+	EcHosts                        []string // holders of the erasure-coded shards, indexed by shard
 }
 
 type DataPartitionPreLoad struct {
@@ -283,11 +287,24 @@ func (partition *DataPartition) createTaskToCreateDataPartition(addr string, dat
 	task = proto.NewAdminTask(proto.OpCreateDataPartition, addr, newCreateDataPartitionRequest(
 		partition.VolName, partition.PartitionID, int(partition.ReplicaNum),
 		peers, int(dataPartitionSize), leaderSize, hosts, createType,
-		partitionType, decommissionedDisks, partition.VerSeq))
+		partitionType, decommissionedDisks, partition.VerSeq, int(partition.EcDataNum), int(partition.EcParityNum),
+		partition.EcHosts))
 	partition.resetTaskID(task)
 	return
 }
 
+// ecShardOnlyHosts returns the holders of erasure-coded shards which do not
+// host a replica, they still have to drop the shards of a deleted partition.
+func (partition *DataPartition) ecShardOnlyHosts() (hosts []string) {
+	for _, host := range partition.EcHosts {
+		if contains(partition.Hosts, host) || contains(hosts, host) {
+			continue
+		}
+		hosts = append(hosts, host)
+	}
+	return
+}
+
 func (partition *DataPartition) createTaskToDeleteDataPartition(addr string) (task *proto.AdminTask) {
 	task = proto.NewAdminTask(proto.OpDeleteDataPartition, addr, newDeleteDataPartitionRequest(partition.PartitionID))
 	partition.resetTaskID(task)
@@ -451,6 +468,12 @@ func (partition *DataPartition) convertToDataPartitionResponse() (dpr *proto.Dat
 	dpr.LeaderAddr = partition.getLeaderAddr()
 	dpr.IsRecover = partition.isRecover
 	dpr.IsDiscard = partition.IsDiscard
+	for _, replica := range partition.Replicas {
+		if replica.IsLeader {
+			dpr.TinyCompacting = replica.TinyCompacting
+			break
+		}
+	}
 
 	return
 }
@@ -712,6 +735,10 @@ func (partition *DataPartition) updateMetric(vr *proto.DataPartitionReport, data
 	if !partition.hasHost(dataNode.Addr) {
 		return
 	}
+	// the clones still refer to the tiny extents of their source, its keys must not be moved
+	if len(vr.TinyCompacting) > 0 && len(c.volClonesOf(partition.VolName, 0)) > 0 {
+		vr.TinyCompacting = nil
+	}
 	partition.Lock()
 	defer partition.Unlock()
 	replica, err := partition.getReplica(dataNode.Addr)
@@ -732,6 +759,8 @@ func (partition *DataPartition) updateMetric(vr *proto.DataPartitionReport, data
 	}
 	replica.NeedsToCompare = vr.NeedCompare
 	replica.DecommissionRepairProgress = vr.DecommissionRepairProgress
+	replica.TinyCompacting = vr.TinyCompacting
+	replica.TinyReclaimedSize = vr.TinyReclaimedSize
 	if replica.DiskPath != vr.DiskPath && vr.DiskPath != "" {
 		oldDiskPath := replica.DiskPath
 		replica.DiskPath = vr.DiskPath
@@ -996,6 +1025,9 @@ func (partition *DataPartition) buildDpInfo(c *Cluster) *proto.DataPartitionInfo
 		IsDiscard:                partition.IsDiscard,
 		SingleDecommissionStatus: partition.GetSpecialReplicaDecommissionStep(),
 		Forbidden:                forbidden,
+		EcDataNum:                partition.EcDataNum,
+		EcParityNum:              partition.EcParityNum,
+		EcHosts:                  partition.EcHosts,
 	}
 }
 
@@ -1258,6 +1290,11 @@ errHandler:
 		partition.isRecover, partition.GetSpecialReplicaDecommissionStep(), partition.DecommissionNeedRollback)
 	Warn(c.Name, msg)
 	log.LogWarnf("action[decommissionDataPartition] %s", msg)
+	if partition.IsDecommissionFailed() {
+		c.recordEvent(proto.EventDecommissionFailed, proto.EventSeverityCritical, proto.EventObjectDataPartition,
+			strconv.FormatUint(partition.PartitionID, 10), partition.VolName,
+			fmt.Sprintf("decommission from %v to %v failed after %v retries: %v", srcAddr, targetAddr, partition.DecommissionRetry, err))
+	}
 	return false
 }
 
@@ -1493,6 +1530,12 @@ func (partition *DataPartition) createTaskToStopDataPartitionRepair(addr string,
 	return
 }
 
+func (partition *DataPartition) createTaskToUpdateEcHosts(addr string, ecHosts []string) (task *proto.AdminTask) {
+	task = proto.NewAdminTask(proto.OpUpdateDataPartitionEcHosts, addr, newUpdateDataPartitionEcHostsRequest(partition.PartitionID, ecHosts))
+	partition.resetTaskID(task)
+	return
+}
+
 func (partition *DataPartition) TryAcquireDecommissionToken(c *Cluster) bool {
 	var (
 		zone            *Zone
@@ -1524,7 +1567,7 @@ func (partition *DataPartition) TryAcquireDecommissionToken(c *Cluster) bool {
 				partition.PartitionID, err.Error())
 			goto errHandler
 		}
-		targetHosts, _, err = ns.getAvailDataNodeHosts(partition.Hosts, 1)
+		targetHosts, _, err = ns.getAvailDataNodeHostToReplace(partition.Hosts, partition.DecommissionSrcAddr)
 		if err != nil {
 			log.LogWarnf("action[TryAcquireDecommissionToken] dp %v choose from src nodeset failed:%v",
 				partition.PartitionID, err.Error())
@@ -30,6 +30,19 @@ func (partition *DataPartition) checkStatus(clusterName string, needLog bool, dp
 	partition.Lock()
 	defer partition.Unlock()
 	var liveReplicas []*DataReplica
+	oldStatus := partition.Status
+	defer func() {
+		// the partitions turned read-only by full or forbidden vols are expected, not worth an event
+		if oldStatus == proto.ReadWrite && partition.Status != proto.ReadWrite && !shouldDpInhibitWriteByVolFull && !forbiddenVol {
+			status, severity := "read-only", proto.EventSeverityWarning
+			if partition.Status == proto.Unavailable {
+				status, severity = "unavailable", proto.EventSeverityCritical
+			}
+			c.recordEvent(proto.EventDataPartitionRdOnly, severity, proto.EventObjectDataPartition,
+				strconv.FormatUint(partition.PartitionID, 10), partition.VolName,
+				fmt.Sprintf("status changed to %v, live replicas %v of %v", status, len(liveReplicas), partition.ReplicaNum))
+		}
+	}()
 
 	if proto.IsNormalDp(partition.PartitionType) {
 		liveReplicas = partition.getLiveReplicasFromHosts(dpTimeOutSec)
@@ -169,7 +182,8 @@ func (partition *DataPartition) checkLeader(clusterID string, timeOut int64) {
 }
 
 // Check if there is any missing replica for a data partition.
-func (partition *DataPartition) checkMissingReplicas(clusterID, leaderAddr string, dataPartitionMissSec, dataPartitionWarnInterval int64) {
+func (partition *DataPartition) checkMissingReplicas(c *Cluster, dataPartitionMissSec, dataPartitionWarnInterval int64) {
+	clusterID, leaderAddr := c.Name, c.leaderInfo.addr
 	partition.Lock()
 	defer partition.Unlock()
 
@@ -195,6 +209,9 @@ func (partition *DataPartition) checkMissingReplicas(clusterID, leaderAddr strin
 					clusterID, partition.PartitionID, replica.Addr, dataPartitionMissSec, replica.ReportTime, lastReportTime, isActive)
 				// msg = msg + fmt.Sprintf(" decommissionDataPartitionURL is http://%v/dataPartition/decommission?id=%v&addr=%v", leaderAddr, partition.PartitionID, replica.Addr)
 				Warn(clusterID, msg)
+				c.recordEvent(proto.EventMissingReplica, proto.EventSeverityWarning, proto.EventObjectDataPartition,
+					strconv.FormatUint(partition.PartitionID, 10), partition.VolName,
+					fmt.Sprintf("replica on %v is missing, last report time %v", replica.Addr, replica.ReportTime))
 				if WarnMetrics != nil {
 					WarnMetrics.WarnMissingDp(clusterID, replica.Addr, partition.PartitionID, true)
 				}
@@ -241,6 +258,9 @@ func (partition *DataPartition) checkMissingReplicas(clusterID, leaderAddr strin
 				"miss time  > :%v  but server not exsit So Migrate", clusterID, partition.PartitionID, addr, dataPartitionMissSec)
 			msg = msg + fmt.Sprintf(" decommissionDataPartitionURL is http://%v/dataPartition/decommission?id=%v&addr=%v", leaderAddr, partition.PartitionID, addr)
 			Warn(clusterID, msg)
+			c.recordEvent(proto.EventMissingReplica, proto.EventSeverityWarning, proto.EventObjectDataPartition,
+				strconv.FormatUint(partition.PartitionID, 10), partition.VolName,
+				fmt.Sprintf("replica on %v is missing and the node is not reporting it", addr))
 		}
 	}
 }
@@ -270,7 +290,8 @@ func (partition *DataPartition) hasMissingDataPartition(addr string) (isMissing
 	return
 }
 
-func (partition *DataPartition) checkDiskError(clusterID, leaderAddr string) {
+func (partition *DataPartition) checkDiskError(c *Cluster) {
+	clusterID, leaderAddr := c.Name, c.leaderInfo.addr
 	diskErrorAddrs := make(map[string]string, 0)
 
 	partition.Lock()
@@ -300,6 +321,8 @@ func (partition *DataPartition) checkDiskError(clusterID, leaderAddr string) {
 		msg := fmt.Sprintf("action[%v],clusterID[%v],partitionID:%v  On :%v  Disk Error,So Remove it From RocksDBHost, decommissionDiskURL is http://%v/disk/decommission?addr=%v&disk=%v",
 			checkDataPartitionDiskErr, clusterID, partition.PartitionID, addr, leaderAddr, addr, diskPath)
 		Warn(clusterID, msg)
+		c.recordEvent(proto.EventDiskError, proto.EventSeverityCritical, proto.EventObjectDisk, addr+":"+diskPath,
+			partition.VolName, fmt.Sprintf("replica of data partition %v is unavailable", partition.PartitionID))
 	}
 
 	return
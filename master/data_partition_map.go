@@ -39,6 +39,7 @@ type DataPartitionMap struct {
 	lastAutoCreateTime     time.Time
 	volName                string
 	readMutex              sync.RWMutex
+	sharedFrom             *DataPartitionMap // partitions of the clone source, shared read only
 }
 
 func newDataPartitionMap(volName string) (dpMap *DataPartitionMap) {
@@ -223,6 +224,15 @@ func (dpMap *DataPartitionMap) getDataPartitionsView(minPartitionID uint64) (dpR
 		dpResps = append(dpResps, dpResp)
 	}
 
+	// the clone reads the extents it shares with its source but never writes them
+	if dpMap.sharedFrom != nil {
+		for _, dpResp := range dpMap.sharedFrom.getDataPartitionsView(minPartitionID) {
+			dpResp.Status = proto.ReadOnly
+			dpResp.IsShared = true
+			dpResps = append(dpResps, dpResp)
+		}
+	}
+
 	return
 }
 
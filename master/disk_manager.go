@@ -16,9 +16,11 @@ package master
 
 import (
 	"fmt"
+	"strconv"
 	"sync/atomic"
 	"time"
 
+	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util/log"
 )
 
@@ -97,6 +99,9 @@ func (c *Cluster) checkDiskRecoveryProgress() {
 					partition.SetDecommissionStatus(DecommissionFail)
 					Warn(c.Name, fmt.Sprintf("action[checkDiskRecoveryProgress]clusterID[%v],partitionID[%v]  recovered timeout %s",
 						c.Name, partitionID, time.Now().Sub(partition.RecoverStartTime).String()))
+					c.recordEvent(proto.EventDecommissionFailed, proto.EventSeverityCritical, proto.EventObjectDataPartition,
+						strconv.FormatUint(partitionID, 10), partition.VolName,
+						fmt.Sprintf("recovery of the new replica on %v timed out", partition.DecommissionDstAddr))
 				} else {
 					newBadDpIds = append(newBadDpIds, partitionID)
 				}
@@ -109,6 +114,9 @@ func (c *Cluster) checkDiskRecoveryProgress() {
 					partition.DecommissionNeedRollback = true
 					partition.SetDecommissionStatus(DecommissionFail)
 					Warn(c.Name, fmt.Sprintf("action[checkDiskRecoveryProgress]clusterID[%v],partitionID[%v] has recovered failed", c.Name, partitionID))
+					c.recordEvent(proto.EventDecommissionFailed, proto.EventSeverityCritical, proto.EventObjectDataPartition,
+						strconv.FormatUint(partitionID, 10), partition.VolName,
+						fmt.Sprintf("new replica on %v is unavailable", partition.DecommissionDstAddr))
 				} else {
 					partition.SetDecommissionStatus(DecommissionSuccess) // can be readonly or readwrite
 					Warn(c.Name, fmt.Sprintf("action[checkDiskRecoveryProgress]clusterID[%v],partitionID[%v] has recovered success", c.Name, partitionID))
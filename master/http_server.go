@@ -41,6 +41,7 @@ func (m *Server) startHTTPService(modulename string, cfg *config.Config) {
 	if m.cluster.authenticate {
 		m.registerAuthenticationMiddleware(router)
 	}
+	m.registerRBACMiddleware(router)
 	exporter.InitWithRouter(modulename, cfg, router, m.port)
 	addr := fmt.Sprintf(":%s", m.port)
 	if m.bindIp {
@@ -385,6 +386,9 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.GetTopologyView).
 		HandlerFunc(m.getTopology)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.GetPlacementView).
+		HandlerFunc(m.getPlacementView)
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.AdminListVols).
 		HandlerFunc(m.listVols)
@@ -414,6 +418,66 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminSetVerStrategy).
 		HandlerFunc(m.SetVerStrategy)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminCloneVol).
+		HandlerFunc(m.cloneVol)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminSnapshotDiff).
+		HandlerFunc(m.createSnapshotDiff)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminGetSnapshotDiff).
+		HandlerFunc(m.getSnapshotDiff)
+	router.NewRoute().Methods(http.MethodPost).
+		Path(proto.AdminCreateSnapshotPolicy).
+		HandlerFunc(m.createSnapshotPolicy)
+	router.NewRoute().Methods(http.MethodPost).
+		Path(proto.AdminUpdateSnapshotPolicy).
+		HandlerFunc(m.updateSnapshotPolicy)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminDeleteSnapshotPolicy).
+		HandlerFunc(m.deleteSnapshotPolicy)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminAttachSnapshotPolicy).
+		HandlerFunc(m.attachSnapshotPolicy)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminDetachSnapshotPolicy).
+		HandlerFunc(m.detachSnapshotPolicy)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminListSnapshotPolicy).
+		HandlerFunc(m.listSnapshotPolicy)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminSnapshotPolicyStatus).
+		HandlerFunc(m.getSnapshotPolicyStatus)
+
+	// metadata backup APIs
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminMetadataBackup).
+		HandlerFunc(m.backupMetadata)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminListMetadataBackup).
+		HandlerFunc(m.listMetadataBackup)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminVerifyMetadataBackup).
+		HandlerFunc(m.verifyMetadataBackup)
+
+	// cluster event APIs
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminListEvents).
+		HandlerFunc(m.listEvents)
+
+	// capacity forecast and policy APIs
+	router.NewRoute().Methods(http.MethodPost).
+		Path(proto.AdminSetCapacityPolicy).
+		HandlerFunc(m.setCapacityPolicy)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminDeleteCapacityPolicy).
+		HandlerFunc(m.deleteCapacityPolicy)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminGetVolCapacityForecast).
+		HandlerFunc(m.getVolCapacityForecast)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.AdminGetZoneCapacityForecast).
+		HandlerFunc(m.getZoneCapacityForecast)
 
 	// S3 lifecycle configuration APIS
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
@@ -442,6 +506,9 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.GetLcNodeTaskResponse).
 		HandlerFunc(m.handleLcNodeTaskResponse)
+	router.NewRoute().Methods(http.MethodPost).
+		Path(proto.PutLcNodeSnapshotDiff).
+		HandlerFunc(m.putSnapshotDiffPage)
 
 	// meta partition management APIs
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
@@ -489,6 +556,18 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet).
 		Path(proto.QosUpdateClientParam).
 		HandlerFunc(m.QosUpdateClientParam)
+	router.NewRoute().Methods(http.MethodPost).
+		Path(proto.QosSetTenant).
+		HandlerFunc(m.setTenantQos)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.QosDeleteTenant).
+		HandlerFunc(m.deleteTenantQos)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.QosGetTenantStatus).
+		HandlerFunc(m.getTenantQosStatus)
+	router.NewRoute().Methods(http.MethodPost).
+		Path(proto.QosObjectNodeReport).
+		HandlerFunc(m.objectNodeQosReport)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminCreateMetaPartition).
 		HandlerFunc(m.createMetaPartition)
@@ -756,6 +835,11 @@ func (m *Server) registerHandler(router *mux.Router, model string, schema *graph
 
 	gHandler := graphql.HTTPHandler(schema)
 	router.NewRoute().Name(model).Methods(http.MethodGet, http.MethodPost).Path(model).HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		// the signer of the request is the user once rbac is enabled
+		if _, ok := request.Context().Value(proto.UserInfoKey).(*proto.UserInfo); ok && m.config.RBACEnable {
+			gHandler.ServeHTTP(writer, request)
+			return
+		}
 		userID := request.Header.Get(proto.UserKey)
 		if userID == "" {
 			ErrResponse(writer, fmt.Errorf("not found [%s] in header", proto.UserKey))
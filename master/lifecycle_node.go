@@ -84,3 +84,13 @@ func (lcNode *LcNode) createSnapshotVerDelTask(masterAddr string, sTask *proto.S
 	task = proto.NewAdminTaskEx(proto.OpLcNodeSnapshotVerDel, lcNode.Addr, request, request.Task.Id)
 	return
 }
+
+func (lcNode *LcNode) createSnapshotDiffTask(masterAddr string, dTask *proto.SnapshotDiffTask) (task *proto.AdminTask) {
+	request := &proto.SnapshotDiffTaskRequest{
+		MasterAddr: masterAddr,
+		LcNodeAddr: lcNode.Addr,
+		Task:       dTask,
+	}
+	task = proto.NewAdminTaskEx(proto.OpLcNodeSnapshotDiff, lcNode.Addr, request, request.Task.Id)
+	return
+}
@@ -51,6 +51,9 @@ func (c *Cluster) handleLcNodeTaskResponse(nodeAddr string, task *proto.AdminTas
 	case proto.OpLcNodeSnapshotVerDel:
 		response := task.Response.(*proto.SnapshotVerDelTaskResponse)
 		err = c.handleLcNodeSnapshotScanResp(task.OperatorAddr, response)
+	case proto.OpLcNodeSnapshotDiff:
+		response := task.Response.(*proto.SnapshotDiffTaskResponse)
+		c.snapshotDiffMgr.handleResponse(response)
 	default:
 		err = fmt.Errorf(fmt.Sprintf("lc unknown operate code %v", task.OpCode))
 		goto errHandler
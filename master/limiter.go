@@ -193,6 +193,7 @@ type ServerFactorLimit struct {
 	Name           string
 	Type           uint32
 	Total          uint64
+	TenantLimit    uint64 // share of the tenant qos budget, 0 if the vol is not under tenant qos
 	Buffer         uint64 // flowbuffer add with preallocate buffer equal with flowtotal
 	CliUsed        uint64
 	CliNeed        uint64
@@ -252,6 +253,7 @@ type QosCtrlManager struct {
 	serverFactorLimitMap map[uint32]*ServerFactorLimit // vol qos data for iops w/r and flow w/r
 	defaultClientCnt     uint32
 	qosEnable            bool
+	tenantQosEnable      bool // limited by the tenant qos even if the vol qos is disabled
 	ClientReqPeriod      uint32
 	ClientHitTriggerCnt  uint32
 	vol                  *Vol
@@ -317,6 +319,35 @@ func (qosManager *QosCtrlManager) getQosLimit(factorTYpe uint32) uint64 {
 	return qosManager.serverFactorLimitMap[factorTYpe].Total
 }
 
+func (qosManager *QosCtrlManager) isEnabled() bool {
+	return qosManager.qosEnable || qosManager.tenantQosEnable
+}
+
+// setTenantLimit caps the flow limits of the vol with its share of the tenant budget, a zero limit removes the cap.
+func (qosManager *QosCtrlManager) setTenantLimit(flowRLimit, flowWLimit uint64) {
+	qosManager.Lock()
+	defer qosManager.Unlock()
+	qosManager.tenantQosEnable = flowRLimit > 0 || flowWLimit > 0
+	qosManager.serverFactorLimitMap[proto.FlowReadType].TenantLimit = flowRLimit
+	qosManager.serverFactorLimitMap[proto.FlowWriteType].TenantLimit = flowWLimit
+}
+
+// clientDemand returns the flow used and needed by all the clients at the last check.
+func (qosManager *QosCtrlManager) clientDemand(factorType uint32) uint64 {
+	qosManager.RLock()
+	defer qosManager.RUnlock()
+	serverLimit := qosManager.serverFactorLimitMap[factorType]
+	return serverLimit.CliUsed + serverLimit.CliNeed
+}
+
+// limitTotal is the limit of the vol, capped by the tenant share if any.
+func (serverLimit *ServerFactorLimit) limitTotal() uint64 {
+	if serverLimit.TenantLimit > 0 && serverLimit.TenantLimit < serverLimit.Total {
+		return serverLimit.TenantLimit
+	}
+	return serverLimit.Total
+}
+
 func (qosManager *QosCtrlManager) initClientQosInfo(clientID uint64, host string) (limitRsp2Client *proto.LimitRsp2Client, err error) {
 	log.QosWriteDebugf("action[initClientQosInfo] vol %v clientID %v Host %v", qosManager.vol.Name, clientID, host)
 	clientInitInfo := proto.NewClientReportLimitInfo()
@@ -330,7 +361,7 @@ func (qosManager *QosCtrlManager) initClientQosInfo(clientID uint64, host string
 
 	limitRsp2Client = proto.NewLimitRsp2Client()
 	limitRsp2Client.ID = clientID
-	limitRsp2Client.Enable = qosManager.qosEnable
+	limitRsp2Client.Enable = qosManager.isEnabled()
 
 	factorType := proto.IopsReadType
 
@@ -341,8 +372,8 @@ func (qosManager *QosCtrlManager) initClientQosInfo(clientID uint64, host string
 		var initLimit uint64
 		serverLimit := qosManager.serverFactorLimitMap[factorType]
 
-		if qosManager.qosEnable {
-			initLimit = serverLimit.Total / uint64(cliCnt)
+		if qosManager.isEnabled() {
+			initLimit = serverLimit.limitTotal() / uint64(cliCnt)
 
 			if serverLimit.Buffer > initLimit {
 				serverLimit.Buffer -= initLimit
@@ -522,7 +553,7 @@ func (qosManager *QosCtrlManager) init(cluster *Cluster, host string) (limit *pr
 
 func (qosManager *QosCtrlManager) HandleClientQosReq(reqClientInfo *proto.ClientReportLimitInfo, clientID uint64) (limitRsp *proto.LimitRsp2Client, err error) {
 	log.QosWriteDebugf("action[HandleClientQosReq] vol [%v] reqClientInfo from [%v], enable [%v]",
-		qosManager.vol.Name, clientID, qosManager.qosEnable)
+		qosManager.vol.Name, clientID, qosManager.isEnabled())
 
 	qosManager.RLock()
 	clientInfo, lastExist := qosManager.cliInfoMgrMap[clientID]
@@ -534,12 +565,12 @@ func (qosManager *QosCtrlManager) HandleClientQosReq(reqClientInfo *proto.Client
 	qosManager.RUnlock()
 
 	limitRsp = proto.NewLimitRsp2Client()
-	limitRsp.Enable = qosManager.qosEnable
+	limitRsp.Enable = qosManager.isEnabled()
 	limitRsp.ID = reqClientInfo.ID
 	limitRsp.ReqPeriod = qosManager.ClientReqPeriod
 	limitRsp.HitTriggerCnt = uint8(qosManager.ClientHitTriggerCnt)
 
-	if !qosManager.qosEnable {
+	if !qosManager.isEnabled() {
 		clientInfo.Cli = reqClientInfo
 		limitRsp.FactorMap = reqClientInfo.FactorMap
 		clientInfo.Assign = limitRsp
@@ -612,15 +643,16 @@ func (qosManager *QosCtrlManager) updateServerLimitByClientsInfo(factorType uint
 	serverLimit.CliNeed = cliSum.Need
 	qosManager.RUnlock()
 
-	if !qosManager.qosEnable {
+	if !qosManager.isEnabled() {
 		return
 	}
 
+	total := serverLimit.limitTotal()
 	serverLimit.Buffer = 0
 	nextStageUse = cliSum.Used
 	nextStageNeed = cliSum.Need
-	if serverLimit.Total >= nextStageUse {
-		serverLimit.Buffer = serverLimit.Total - nextStageUse
+	if total >= nextStageUse {
+		serverLimit.Buffer = total - nextStageUse
 		log.QosWriteDebugf("action[updateServerLimitByClientsInfo] vol [%v] reset server buffer [%v] all clients nextStageUse [%v]",
 			qosManager.vol.Name, serverLimit.Buffer, nextStageUse)
 		if nextStageNeed > serverLimit.Buffer {
@@ -638,10 +670,10 @@ func (qosManager *QosCtrlManager) updateServerLimitByClientsInfo(factorType uint
 		}
 	} else { // usage large than limitation
 		log.QosWriteDebugf("action[updateServerLimitByClientsInfo] vol[%v] type [%v] clients needs [%v] plus overuse [%v],get nextStageNeed [%v]",
-			qosManager.vol.Name, proto.QosTypeString(factorType), nextStageNeed, nextStageUse-serverLimit.Total,
-			nextStageNeed+nextStageUse-serverLimit.Total)
-		nextStageNeed += nextStageUse - serverLimit.Total
-		nextStageUse = serverLimit.Total
+			qosManager.vol.Name, proto.QosTypeString(factorType), nextStageNeed, nextStageUse-total,
+			nextStageNeed+nextStageUse-total)
+		nextStageNeed += nextStageUse - total
+		nextStageUse = total
 	}
 
 	serverLimit.Allocated = nextStageUse
@@ -658,15 +690,15 @@ func (qosManager *QosCtrlManager) updateServerLimitByClientsInfo(factorType uint
 		lastMagnify := serverLimit.LastMagnify
 		lastLimitRatio := serverLimit.LimitRate
 		// master assigned limit and buffer not be used as expected,we need adjust the gap
-		if serverLimit.CliUsed < serverLimit.Total {
-			if serverLimit.LimitRate > -10.0 && serverLimit.LastMagnify < serverLimit.Total*10 {
-				serverLimit.LastMagnify += uint64(float64(serverLimit.Total-serverLimit.CliUsed) * 0.1)
+		if serverLimit.CliUsed < total {
+			if serverLimit.LimitRate > -10.0 && serverLimit.LastMagnify < total*10 {
+				serverLimit.LastMagnify += uint64(float64(total-serverLimit.CliUsed) * 0.1)
 			}
 		} else {
 			if serverLimit.LastMagnify > 0 {
 				var magnify uint64
-				if serverLimit.LastMagnify > (serverLimit.CliUsed - serverLimit.Total) {
-					magnify = serverLimit.CliUsed - serverLimit.Total
+				if serverLimit.LastMagnify > (serverLimit.CliUsed - total) {
+					magnify = serverLimit.CliUsed - total
 				} else {
 					magnify = serverLimit.LastMagnify
 				}
@@ -687,7 +719,7 @@ func (qosManager *QosCtrlManager) updateServerLimitByClientsInfo(factorType uint
 
 func (qosManager *QosCtrlManager) assignClientsNewQos(factorType uint32) {
 	qosManager.RLock()
-	if !qosManager.qosEnable {
+	if !qosManager.isEnabled() {
 		return
 	}
 	serverLimit := qosManager.serverFactorLimitMap[factorType]
@@ -751,7 +783,7 @@ func (vol *Vol) checkQos() {
 		// calc all clients and get real used and need value , used value should less then total
 		vol.qosManager.updateServerLimitByClientsInfo(factorType)
 		// update client assign info by result above
-		if !vol.qosManager.qosEnable {
+		if !vol.qosManager.isEnabled() {
 			continue
 		}
 
@@ -52,6 +52,8 @@ func (m *Server) handleLeaderChange(leader uint64) {
 			m.loadMetadata()
 			m.cluster.metaReady = true
 			m.metaReady = true
+			m.cluster.recordEvent(cfsProto.EventMasterLeaderChange, cfsProto.EventSeverityWarning, cfsProto.EventObjectMaster,
+				m.leaderInfo.addr, "", fmt.Sprintf("leader is changed from %v to %v", oldLeaderAddr, m.leaderInfo.addr))
 		}
 		m.cluster.checkDataNodeHeartbeat()
 		m.cluster.checkMetaNodeHeartbeat()
@@ -226,6 +228,36 @@ func (m *Server) loadMetadata() {
 	}
 	log.LogInfo("action[loadLcConfs] end")
 
+	log.LogInfo("action[loadSnapshotPolicies] begin")
+	if err = m.cluster.loadSnapshotPolicies(); err != nil {
+		panic(err)
+	}
+	log.LogInfo("action[loadSnapshotPolicies] end")
+
+	log.LogInfo("action[loadClusterEvents] begin")
+	if err = m.cluster.loadClusterEvents(); err != nil {
+		panic(err)
+	}
+	log.LogInfo("action[loadClusterEvents] end")
+
+	log.LogInfo("action[loadTenantQos] begin")
+	if err = m.cluster.loadTenantQos(); err != nil {
+		panic(err)
+	}
+	log.LogInfo("action[loadTenantQos] end")
+
+	log.LogInfo("action[loadCapacityPolicies] begin")
+	if err = m.cluster.loadCapacityPolicies(); err != nil {
+		panic(err)
+	}
+	log.LogInfo("action[loadCapacityPolicies] end")
+
+	log.LogInfo("action[loadSnapshotDiffs] begin")
+	if err = m.cluster.loadSnapshotDiffs(); err != nil {
+		panic(err)
+	}
+	log.LogInfo("action[loadSnapshotDiffs] end")
+
 	log.LogInfo("action[loadLcNodes] begin")
 	if err = m.cluster.loadLcNodes(); err != nil {
 		panic(err)
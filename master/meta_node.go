@@ -29,6 +29,8 @@ type MetaNode struct {
 	ID                        uint64
 	Addr                      string
 	DomainAddr                string
+	Rack                      string // failure domain labels, see placement.go
+	Host                      string
 	IsActive                  bool
 	Sender                    *AdminTaskManager `graphql:"-"`
 	ZoneName                  string            `json:"Zone"`
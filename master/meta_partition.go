@@ -17,6 +17,7 @@ package master
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -75,6 +76,9 @@ type MetaPartition struct {
 	EqualCheckPass   bool
 	VerSeq           uint64
 	heartBeatDone    bool
+	// the replicas of a clone copy the metadata of the version from the partition cloneSrcID
+	cloneSrcID  uint64
+	cloneVerSeq uint64
 
 	sync.RWMutex
 }
@@ -532,7 +536,8 @@ func (mp *MetaPartition) shouldReportMissingReplica(addr string, interval int64)
 	// return false
 }
 
-func (mp *MetaPartition) reportMissingReplicas(clusterID, leaderAddr string, seconds, interval int64) {
+func (mp *MetaPartition) reportMissingReplicas(c *Cluster, seconds, interval int64) {
+	clusterID, leaderAddr := c.Name, c.leaderInfo.addr
 	mp.Lock()
 	defer mp.Unlock()
 	for _, replica := range mp.Replicas {
@@ -550,6 +555,9 @@ func (mp *MetaPartition) reportMissingReplicas(clusterID, leaderAddr string, sec
 					"miss time > :%v  vlocLastRepostTime:%v   dnodeLastReportTime:%v  nodeisActive:%v",
 					clusterID, mp.volName, mp.PartitionID, replica.Addr, seconds, replica.ReportTime, lastReportTime, isActive)
 				Warn(clusterID, msg)
+				c.recordEvent(proto.EventMissingReplica, proto.EventSeverityWarning, proto.EventObjectMetaPartition,
+					strconv.FormatUint(mp.PartitionID, 10), mp.volName,
+					fmt.Sprintf("replica on %v is missing, last report time %v", replica.Addr, replica.ReportTime))
 				// msg = fmt.Sprintf("decommissionMetaPartitionURL is http://%v/dataPartition/decommission?id=%v&addr=%v", leaderAddr, mp.PartitionID, replica.Addr)
 				// Warn(clusterID, msg)
 				if WarnMetrics != nil {
@@ -571,6 +579,9 @@ func (mp *MetaPartition) reportMissingReplicas(clusterID, leaderAddr string, sec
 				"miss time  > %v ",
 				clusterID, mp.volName, mp.PartitionID, addr, defaultMetaPartitionTimeOutSec)
 			Warn(clusterID, msg)
+			c.recordEvent(proto.EventMissingReplica, proto.EventSeverityWarning, proto.EventObjectMetaPartition,
+				strconv.FormatUint(mp.PartitionID, 10), mp.volName,
+				fmt.Sprintf("replica on %v is missing and the node is not reporting it", addr))
 			msg = fmt.Sprintf("decommissionMetaPartitionURL is http://%v/dataPartition/decommission?id=%v&addr=%v", leaderAddr, mp.PartitionID, addr)
 			Warn(clusterID, msg)
 		}
@@ -601,12 +612,14 @@ func (mp *MetaPartition) buildNewMetaPartitionTasks(specifyAddrs []string, peers
 	hosts := make([]string, 0)
 
 	req := &proto.CreateMetaPartitionRequest{
-		Start:       mp.Start,
-		End:         mp.End,
-		PartitionID: mp.PartitionID,
-		Members:     peers,
-		VolName:     volName,
-		VerSeq:      mp.VerSeq,
+		Start:               mp.Start,
+		End:                 mp.End,
+		PartitionID:         mp.PartitionID,
+		Members:             peers,
+		VolName:             volName,
+		VerSeq:              mp.VerSeq,
+		CloneSrcPartitionID: mp.cloneSrcID,
+		CloneVerSeq:         mp.cloneVerSeq,
 	}
 	if specifyAddrs == nil {
 		hosts = mp.Hosts
@@ -0,0 +1,624 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	raftstore "github.com/cubefs/cubefs/raftstore/raftstore_db"
+	"github.com/cubefs/cubefs/util/config"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// A metadata backup is a text file of JSON lines: the header, one RaftCmd per key
+// of the master RocksDB, then the trailer with the number of keys and the crc32 of
+// the lines before it.
+const (
+	metadataBackupMagic     = "cubefs-master-metadata"
+	metadataBackupVersion   = 1
+	metadataBackupSuffix    = ".backup"
+	metadataBackupBatchSize = 1000
+)
+
+type metadataBackupHeader struct {
+	Magic      string
+	Version    int
+	Cluster    string
+	Applied    uint64
+	CreateTime int64
+}
+
+type metadataBackupTrailer struct {
+	Keys int
+	Crc  uint32
+}
+
+type metadataBackupLine struct {
+	Header  *metadataBackupHeader  `json:",omitempty"`
+	Cmd     *RaftCmd               `json:",omitempty"`
+	Trailer *metadataBackupTrailer `json:",omitempty"`
+}
+
+type metadataBackupWriter struct {
+	w    *bufio.Writer
+	crc  hash.Hash32
+	keys int
+}
+
+func newMetadataBackupWriter(w io.Writer) *metadataBackupWriter {
+	return &metadataBackupWriter{w: bufio.NewWriter(w), crc: crc32.NewIEEE()}
+}
+
+func (bw *metadataBackupWriter) writeLine(line *metadataBackupLine) (err error) {
+	var data []byte
+	if data, err = json.Marshal(line); err != nil {
+		return
+	}
+	data = append(data, '\n')
+	if line.Trailer == nil {
+		bw.crc.Write(data)
+	}
+	_, err = bw.w.Write(data)
+	return
+}
+
+func (bw *metadataBackupWriter) writeHeader(header *metadataBackupHeader) error {
+	return bw.writeLine(&metadataBackupLine{Header: header})
+}
+
+func (bw *metadataBackupWriter) writeKey(k string, v []byte) error {
+	cmd := &RaftCmd{K: k, V: v}
+	cmd.setOpType()
+	bw.keys++
+	return bw.writeLine(&metadataBackupLine{Cmd: cmd})
+}
+
+func (bw *metadataBackupWriter) close() (err error) {
+	if err = bw.writeLine(&metadataBackupLine{Trailer: &metadataBackupTrailer{Keys: bw.keys, Crc: bw.crc.Sum32()}}); err != nil {
+		return
+	}
+	return bw.w.Flush()
+}
+
+// readMetadataBackup calls fn on every key of the backup, the backup is only known to be
+// complete once it returns without error.
+func readMetadataBackup(r io.Reader, fn func(cmd *RaftCmd) error) (header *metadataBackupHeader, err error) {
+	var (
+		reader  = bufio.NewReader(r)
+		crc     = crc32.NewIEEE()
+		keys    int
+		trailer *metadataBackupTrailer
+	)
+	for {
+		var data []byte
+		if data, err = reader.ReadBytes('\n'); err == io.EOF && len(data) == 0 {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("read backup: %v", err)
+		}
+		if trailer != nil {
+			return nil, fmt.Errorf("data after the trailer of the backup")
+		}
+		line := &metadataBackupLine{}
+		if err = json.Unmarshal(data, line); err != nil {
+			return nil, fmt.Errorf("line %v of the backup: %v", keys+1, err)
+		}
+		switch {
+		case line.Header != nil:
+			if header != nil {
+				return nil, fmt.Errorf("duplicated header of the backup")
+			}
+			header = line.Header
+			if header.Magic != metadataBackupMagic || header.Version != metadataBackupVersion {
+				return nil, fmt.Errorf("not a metadata backup of version %v", metadataBackupVersion)
+			}
+			crc.Write(data)
+		case line.Cmd != nil:
+			if header == nil {
+				return nil, fmt.Errorf("no header in the backup")
+			}
+			crc.Write(data)
+			keys++
+			if fn != nil {
+				if err = fn(line.Cmd); err != nil {
+					return nil, err
+				}
+			}
+		case line.Trailer != nil:
+			trailer = line.Trailer
+		}
+	}
+	err = nil
+	if header == nil || trailer == nil {
+		return nil, fmt.Errorf("the backup is truncated")
+	}
+	if trailer.Keys != keys || trailer.Crc != crc.Sum32() {
+		return nil, fmt.Errorf("the backup is corrupted, keys %v crc %v, expect keys %v crc %v", keys, crc.Sum32(), trailer.Keys, trailer.Crc)
+	}
+	return
+}
+
+func metadataBackupKind(key string) string {
+	keyArr := strings.Split(key, keySeparator)
+	if len(keyArr) < 2 || keyArr[1] == "" {
+		return key
+	}
+	return keyArr[1]
+}
+
+func checkMetadataBackupName(name string) error {
+	if name == "" || name != path.Base(name) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid backup file name %q", name)
+	}
+	return nil
+}
+
+// backupMetadata writes all the keys of a RocksDB snapshot of the metadata to the backup
+// directory, the backup is consistent with the applied index of the snapshot.
+func (c *Cluster) backupMetadata(name string) (info *proto.MetadataBackupInfo, err error) {
+	dir := c.cfg.MetadataBackupDir
+	if dir == "" {
+		return nil, fmt.Errorf("%v is not configured", cfgMetadataBackupDir)
+	}
+	if name == "" {
+		name = fmt.Sprintf("%v_%v%v", c.Name, time.Now().Format("20060102150405"), metadataBackupSuffix)
+	}
+	if err = checkMetadataBackupName(name); err != nil {
+		return
+	}
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return
+	}
+	filePath := path.Join(dir, name)
+	if _, err = os.Stat(filePath); err == nil {
+		return nil, fmt.Errorf("backup %v already exists", name)
+	}
+
+	snapshot := c.fsm.store.RocksDBSnapshot()
+	defer c.fsm.store.ReleaseSnapshot(snapshot)
+	iterator := c.fsm.store.Iterator(snapshot)
+	defer iterator.Close()
+
+	info = &proto.MetadataBackupInfo{
+		File:       name,
+		Cluster:    c.Name,
+		CreateTime: time.Now().Unix(),
+		Counts:     make(map[string]int),
+	}
+	iterator.Seek([]byte(applied))
+	if iterator.Valid() && string(iterator.Key().Data()) == applied {
+		if info.Applied, err = strconv.ParseUint(string(iterator.Value().Data()), 10, 64); err != nil {
+			return nil, fmt.Errorf("parse applied index: %v", err)
+		}
+	}
+
+	tmpPath := filePath + ".tmp"
+	var file *os.File
+	if file, err = os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600); err != nil {
+		return
+	}
+	defer func() {
+		file.Close()
+		if err != nil {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	bw := newMetadataBackupWriter(file)
+	if err = bw.writeHeader(&metadataBackupHeader{
+		Magic:      metadataBackupMagic,
+		Version:    metadataBackupVersion,
+		Cluster:    c.Name,
+		Applied:    info.Applied,
+		CreateTime: info.CreateTime,
+	}); err != nil {
+		return
+	}
+	for iterator.SeekToFirst(); iterator.Valid(); iterator.Next() {
+		key := string(iterator.Key().Data())
+		if key == applied {
+			continue
+		}
+		value := iterator.Value().Data()
+		if err = bw.writeKey(key, append([]byte(nil), value...)); err != nil {
+			return
+		}
+		info.Counts[metadataBackupKind(key)]++
+	}
+	if err = iterator.Err(); err != nil {
+		return
+	}
+	if err = bw.close(); err != nil {
+		return
+	}
+	if err = file.Sync(); err != nil {
+		return
+	}
+	if err = os.Rename(tmpPath, filePath); err != nil {
+		return
+	}
+	info.Keys = bw.keys
+	if stat, err1 := os.Stat(filePath); err1 == nil {
+		info.Size = stat.Size()
+	}
+	log.LogWarnf("action[backupMetadata] cluster %v backup %v applied %v keys %v", c.Name, filePath, info.Applied, info.Keys)
+	return
+}
+
+func (c *Cluster) openMetadataBackup(name string) (file *os.File, err error) {
+	if c.cfg.MetadataBackupDir == "" {
+		return nil, fmt.Errorf("%v is not configured", cfgMetadataBackupDir)
+	}
+	if err = checkMetadataBackupName(name); err != nil {
+		return
+	}
+	return os.Open(path.Join(c.cfg.MetadataBackupDir, name))
+}
+
+func (c *Cluster) listMetadataBackups() (infos []*proto.MetadataBackupInfo, err error) {
+	infos = make([]*proto.MetadataBackupInfo, 0)
+	if c.cfg.MetadataBackupDir == "" {
+		return nil, fmt.Errorf("%v is not configured", cfgMetadataBackupDir)
+	}
+	entries, err := os.ReadDir(c.cfg.MetadataBackupDir)
+	if os.IsNotExist(err) {
+		return infos, nil
+	} else if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), metadataBackupSuffix) {
+			continue
+		}
+		info := &proto.MetadataBackupInfo{File: entry.Name()}
+		if stat, err1 := entry.Info(); err1 == nil {
+			info.Size = stat.Size()
+			info.CreateTime = stat.ModTime().Unix()
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].CreateTime < infos[j].CreateTime })
+	return
+}
+
+type metadataBackupMetaPartition struct {
+	hosts      []string
+	start, end uint64
+}
+
+// metadataBackupState is the part of the metadata checked against the reports of the nodes.
+type metadataBackupState struct {
+	vols           map[string]uint64
+	dataPartitions map[uint64][]string
+	metaPartitions map[uint64]*metadataBackupMetaPartition
+	dataNodes      map[string]bool
+	metaNodes      map[string]bool
+}
+
+func newMetadataBackupState() *metadataBackupState {
+	return &metadataBackupState{
+		vols:           make(map[string]uint64),
+		dataPartitions: make(map[uint64][]string),
+		metaPartitions: make(map[uint64]*metadataBackupMetaPartition),
+		dataNodes:      make(map[string]bool),
+		metaNodes:      make(map[string]bool),
+	}
+}
+
+func splitHosts(hosts string) []string {
+	if hosts == "" {
+		return nil
+	}
+	return strings.Split(hosts, underlineSeparator)
+}
+
+func (s *metadataBackupState) add(cmd *RaftCmd) (err error) {
+	switch {
+	case strings.HasPrefix(cmd.K, volPrefix):
+		vv := &volValue{}
+		if err = json.Unmarshal(cmd.V, vv); err != nil {
+			return
+		}
+		if vv.Status != proto.VolStatusMarkDelete {
+			s.vols[vv.Name] = vv.ID
+		}
+	case strings.HasPrefix(cmd.K, dataPartitionPrefix):
+		dpv := &dataPartitionValue{}
+		if err = json.Unmarshal(cmd.V, dpv); err != nil {
+			return
+		}
+		s.dataPartitions[dpv.PartitionID] = splitHosts(dpv.Hosts)
+	case strings.HasPrefix(cmd.K, metaPartitionPrefix):
+		mpv := &metaPartitionValue{}
+		if err = json.Unmarshal(cmd.V, mpv); err != nil {
+			return
+		}
+		s.metaPartitions[mpv.PartitionID] = &metadataBackupMetaPartition{hosts: splitHosts(mpv.Hosts), start: mpv.Start, end: mpv.End}
+	case strings.HasPrefix(cmd.K, dataNodePrefix):
+		dnv := &dataNodeValue{}
+		if err = json.Unmarshal(cmd.V, dnv); err != nil {
+			return
+		}
+		s.dataNodes[dnv.Addr] = true
+	case strings.HasPrefix(cmd.K, metaNodePrefix):
+		mnv := &metaNodeValue{}
+		if err = json.Unmarshal(cmd.V, mnv); err != nil {
+			return
+		}
+		s.metaNodes[mnv.Addr] = true
+	}
+	return
+}
+
+// liveMetadataState collects the vols and nodes of the cluster, with the partition replicas
+// reported alive by the nodes.
+func (c *Cluster) liveMetadataState() *metadataBackupState {
+	s := newMetadataBackupState()
+	for name, vol := range c.allVols() {
+		if vol.Status == proto.VolStatusMarkDelete {
+			continue
+		}
+		s.vols[name] = vol.ID
+		for _, dp := range vol.dataPartitions.clonePartitions() {
+			dp.RLock()
+			hosts := make([]string, 0, len(dp.Replicas))
+			for _, replica := range dp.liveReplicas(defaultDataPartitionTimeOutSec) {
+				hosts = append(hosts, replica.Addr)
+			}
+			dp.RUnlock()
+			s.dataPartitions[dp.PartitionID] = hosts
+		}
+		for _, mp := range vol.cloneMetaPartitionMap() {
+			mp.RLock()
+			s.metaPartitions[mp.PartitionID] = &metadataBackupMetaPartition{
+				hosts: mp.getLiveReplicasAddr(mp.getLiveReplicas()),
+				start: mp.Start,
+				end:   mp.End,
+			}
+			mp.RUnlock()
+		}
+	}
+	c.dataNodes.Range(func(key, value interface{}) bool {
+		s.dataNodes[key.(string)] = true
+		return true
+	})
+	c.metaNodes.Range(func(key, value interface{}) bool {
+		s.metaNodes[key.(string)] = true
+		return true
+	})
+	return s
+}
+
+func sameHosts(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, h := range a {
+		set[h] = true
+	}
+	for _, h := range b {
+		if !set[h] {
+			return false
+		}
+	}
+	return true
+}
+
+// diffMetadataState lists the differences between the backup and the live state, sorted by kind and id.
+func diffMetadataState(backup, live *metadataBackupState) (diffs []proto.MetadataBackupDiff) {
+	diffs = make([]proto.MetadataBackupDiff, 0)
+	add := func(kind, id, diff, detail string) {
+		diffs = append(diffs, proto.MetadataBackupDiff{Kind: kind, ID: id, Diff: diff, Detail: detail})
+	}
+	for name, id := range backup.vols {
+		if liveID, ok := live.vols[name]; !ok {
+			add("vol", name, proto.MetadataBackupDiffMissing, "")
+		} else if liveID != id {
+			add("vol", name, proto.MetadataBackupDiffMissing, fmt.Sprintf("vol id %v in the backup, %v in the cluster", id, liveID))
+		}
+	}
+	for name := range live.vols {
+		if _, ok := backup.vols[name]; !ok {
+			add("vol", name, proto.MetadataBackupDiffExtra, "")
+		}
+	}
+	for id, hosts := range backup.dataPartitions {
+		liveHosts, ok := live.dataPartitions[id]
+		if !ok {
+			add("dataPartition", strconv.FormatUint(id, 10), proto.MetadataBackupDiffMissing, "")
+		} else if !sameHosts(hosts, liveHosts) {
+			add("dataPartition", strconv.FormatUint(id, 10), proto.MetadataBackupDiffHosts,
+				fmt.Sprintf("backup %v, reported %v", hosts, liveHosts))
+		}
+	}
+	for id := range live.dataPartitions {
+		if _, ok := backup.dataPartitions[id]; !ok {
+			add("dataPartition", strconv.FormatUint(id, 10), proto.MetadataBackupDiffExtra, "")
+		}
+	}
+	for id, mp := range backup.metaPartitions {
+		liveMp, ok := live.metaPartitions[id]
+		if !ok {
+			add("metaPartition", strconv.FormatUint(id, 10), proto.MetadataBackupDiffMissing, "")
+			continue
+		}
+		if mp.start != liveMp.start || mp.end != liveMp.end {
+			add("metaPartition", strconv.FormatUint(id, 10), proto.MetadataBackupDiffRange,
+				fmt.Sprintf("backup [%v,%v], cluster [%v,%v]", mp.start, mp.end, liveMp.start, liveMp.end))
+		}
+		if !sameHosts(mp.hosts, liveMp.hosts) {
+			add("metaPartition", strconv.FormatUint(id, 10), proto.MetadataBackupDiffHosts,
+				fmt.Sprintf("backup %v, reported %v", mp.hosts, liveMp.hosts))
+		}
+	}
+	for id := range live.metaPartitions {
+		if _, ok := backup.metaPartitions[id]; !ok {
+			add("metaPartition", strconv.FormatUint(id, 10), proto.MetadataBackupDiffExtra, "")
+		}
+	}
+	diffNodes := func(kind string, backupNodes, liveNodes map[string]bool) {
+		for addr := range backupNodes {
+			if !liveNodes[addr] {
+				add(kind, addr, proto.MetadataBackupDiffMissing, "")
+			}
+		}
+		for addr := range liveNodes {
+			if !backupNodes[addr] {
+				add(kind, addr, proto.MetadataBackupDiffExtra, "")
+			}
+		}
+	}
+	diffNodes("dataNode", backup.dataNodes, live.dataNodes)
+	diffNodes("metaNode", backup.metaNodes, live.metaNodes)
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Kind != diffs[j].Kind {
+			return diffs[i].Kind < diffs[j].Kind
+		}
+		return diffs[i].ID < diffs[j].ID
+	})
+	return
+}
+
+// verifyMetadataBackup checks the backup is complete, then diffs it against the partitions
+// and nodes reported to the cluster.
+func (c *Cluster) verifyMetadataBackup(name string) (report *proto.MetadataBackupVerifyReport, err error) {
+	var (
+		file   *os.File
+		header *metadataBackupHeader
+		backup = newMetadataBackupState()
+		counts = make(map[string]int)
+		keys   int
+	)
+	if file, err = c.openMetadataBackup(name); err != nil {
+		return
+	}
+	defer file.Close()
+	if header, err = readMetadataBackup(file, func(cmd *RaftCmd) error {
+		keys++
+		counts[metadataBackupKind(cmd.K)]++
+		return backup.add(cmd)
+	}); err != nil {
+		return
+	}
+	if header.Cluster != c.Name {
+		return nil, fmt.Errorf("backup %v is of cluster %v", name, header.Cluster)
+	}
+
+	live := c.liveMetadataState()
+	report = &proto.MetadataBackupVerifyReport{
+		Backup: &proto.MetadataBackupInfo{
+			File:       name,
+			Cluster:    header.Cluster,
+			Applied:    header.Applied,
+			CreateTime: header.CreateTime,
+			Keys:       keys,
+			Counts:     counts,
+		},
+		Checked: map[string]int{
+			"vol":           len(live.vols),
+			"dataPartition": len(live.dataPartitions),
+			"metaPartition": len(live.metaPartitions),
+			"dataNode":      len(live.dataNodes),
+			"metaNode":      len(live.metaNodes),
+		},
+		Diffs: diffMetadataState(backup, live),
+	}
+	return
+}
+
+func isEmptyDir(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	return len(entries) == 0, err
+}
+
+// RestoreMetadataBackup rebuilds the store of a stopped master from the backup. It runs
+// offline on every master of the raft group with the same backup and the config of the
+// master, whose storeDir and walDir must be empty. The masters then start a new raft log
+// on top of the restored metadata.
+func RestoreMetadataBackup(cfg *config.Config, backupFile string) (keys int, err error) {
+	var (
+		clusterName = cfg.GetString(ClusterName)
+		storeDir    = cfg.GetString(StoreDir)
+		walDir      = cfg.GetString(WalDir)
+		header      *metadataBackupHeader
+		file        *os.File
+		empty       bool
+	)
+	if storeDir == "" || walDir == "" || clusterName == "" {
+		return 0, fmt.Errorf("%v, one of (walDir,storeDir,clusterName) is null", proto.ErrInvalidCfg)
+	}
+	for _, dir := range []string{storeDir, raftstore.GetRocksDBStoreRecoveryDir(storeDir), walDir} {
+		if empty, err = isEmptyDir(dir); err != nil {
+			return
+		}
+		if !empty {
+			return 0, fmt.Errorf("%v is not empty, move it away before restoring", dir)
+		}
+	}
+
+	if file, err = os.Open(backupFile); err != nil {
+		return
+	}
+	defer file.Close()
+	// check the whole backup before writing anything
+	if header, err = readMetadataBackup(file, nil); err != nil {
+		return
+	}
+	if header.Cluster != clusterName {
+		return 0, fmt.Errorf("backup of cluster %v can't be restored to cluster %v", header.Cluster, clusterName)
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+
+	var store *raftstore.RocksDBStore
+	if store, err = raftstore.NewRocksDBStore(storeDir, LRUCacheSize, WriteBufferSize); err != nil {
+		return
+	}
+	defer store.Close()
+	batch := make(map[string][]byte, metadataBackupBatchSize)
+	if _, err = readMetadataBackup(file, func(cmd *RaftCmd) (err error) {
+		batch[cmd.K] = cmd.V
+		keys++
+		if len(batch) >= metadataBackupBatchSize {
+			err = store.BatchPut(batch, false)
+			batch = make(map[string][]byte, metadataBackupBatchSize)
+		}
+		return
+	}); err != nil {
+		return
+	}
+	if err = store.BatchPut(batch, true); err != nil {
+		return
+	}
+	return keys, store.Flush()
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func writeTestMetadataBackup(t *testing.T, kvs map[string]string) []byte {
+	buf := new(bytes.Buffer)
+	bw := newMetadataBackupWriter(buf)
+	if err := bw.writeHeader(&metadataBackupHeader{Magic: metadataBackupMagic, Version: metadataBackupVersion, Cluster: "test", Applied: 10}); err != nil {
+		t.Fatalf("write header err %v", err)
+	}
+	for k, v := range kvs {
+		if err := bw.writeKey(k, []byte(v)); err != nil {
+			t.Fatalf("write key err %v", err)
+		}
+	}
+	if err := bw.close(); err != nil {
+		t.Fatalf("close err %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestMetadataBackupReadWrite(t *testing.T) {
+	kvs := map[string]string{
+		volPrefix + "1":           `{"ID":1,"Name":"vol1"}`,
+		dataPartitionPrefix + "2": `{"PartitionID":2,"Hosts":"a_b_c"}`,
+		maxCommonIDKey:            "100",
+	}
+	data := writeTestMetadataBackup(t, kvs)
+
+	read := make(map[string]string)
+	header, err := readMetadataBackup(bytes.NewReader(data), func(cmd *RaftCmd) error {
+		read[cmd.K] = string(cmd.V)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("read backup err %v", err)
+		return
+	}
+	if header.Cluster != "test" || header.Applied != 10 {
+		t.Errorf("header %v", header)
+	}
+	if len(read) != len(kvs) {
+		t.Errorf("read %v keys, expect %v", len(read), len(kvs))
+	}
+	for k, v := range kvs {
+		if read[k] != v {
+			t.Errorf("key %v value %v, expect %v", k, read[k], v)
+		}
+	}
+
+	// truncated and modified backups are rejected
+	if _, err = readMetadataBackup(bytes.NewReader(data[:len(data)-10]), nil); err == nil {
+		t.Errorf("truncated backup should be rejected")
+	}
+	corrupted := bytes.Replace(data, []byte(maxCommonIDKey), []byte(maxCommonIDKey+"x"), 1)
+	if _, err = readMetadataBackup(bytes.NewReader(corrupted), nil); err == nil {
+		t.Errorf("corrupted backup should be rejected")
+	}
+}
+
+func TestDiffMetadataState(t *testing.T) {
+	backup := newMetadataBackupState()
+	backup.vols["vol1"] = 1
+	backup.vols["vol2"] = 2
+	backup.dataPartitions[1] = []string{"a", "b", "c"}
+	backup.dataPartitions[2] = []string{"a", "b", "c"}
+	backup.metaPartitions[1] = &metadataBackupMetaPartition{hosts: []string{"a", "b", "c"}, start: 0, end: 100}
+	backup.dataNodes["a"] = true
+
+	live := newMetadataBackupState()
+	live.vols["vol1"] = 1
+	live.vols["vol3"] = 3
+	live.dataPartitions[1] = []string{"c", "b", "a"}
+	live.dataPartitions[2] = []string{"a", "b"}
+	live.dataPartitions[3] = []string{"a", "b", "c"}
+	live.metaPartitions[1] = &metadataBackupMetaPartition{hosts: []string{"a", "b", "c"}, start: 0, end: 200}
+	live.dataNodes["a"] = true
+	live.metaNodes["d"] = true
+
+	expect := []proto.MetadataBackupDiff{
+		{Kind: "dataPartition", ID: "2", Diff: proto.MetadataBackupDiffHosts},
+		{Kind: "dataPartition", ID: "3", Diff: proto.MetadataBackupDiffExtra},
+		{Kind: "metaNode", ID: "d", Diff: proto.MetadataBackupDiffExtra},
+		{Kind: "metaPartition", ID: "1", Diff: proto.MetadataBackupDiffRange},
+		{Kind: "vol", ID: "vol2", Diff: proto.MetadataBackupDiffMissing},
+		{Kind: "vol", ID: "vol3", Diff: proto.MetadataBackupDiffExtra},
+	}
+	diffs := diffMetadataState(backup, live)
+	if len(diffs) != len(expect) {
+		t.Errorf("diffs %v, expect %v", diffs, expect)
+		return
+	}
+	for i := range expect {
+		if diffs[i].Kind != expect[i].Kind || diffs[i].ID != expect[i].ID || diffs[i].Diff != expect[i].Diff {
+			t.Errorf("diff %v is %v, expect %v", i, diffs[i], expect[i])
+		}
+	}
+}
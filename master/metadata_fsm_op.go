@@ -155,6 +155,9 @@ type dataPartitionValue struct {
 	RecoverLastConsumeTime         float64
 	Forbidden                      bool
 	DecommissionWaitTimes          int
+	EcDataNum                      uint8
+	EcParityNum                    uint8
+	EcHosts                        string
 }
 
 func (dpv *dataPartitionValue) Restore(c *Cluster) (dp *DataPartition) {
@@ -184,6 +187,11 @@ func (dpv *dataPartitionValue) Restore(c *Cluster) (dp *DataPartition) {
 	dp.RecoverStartTime = time.Unix(dpv.RecoverStartTime, 0)
 	dp.RecoverLastConsumeTime = time.Duration(dpv.RecoverLastConsumeTime) * time.Second
 	dp.DecommissionWaitTimes = dpv.DecommissionWaitTimes
+	dp.EcDataNum = dpv.EcDataNum
+	dp.EcParityNum = dpv.EcParityNum
+	if dpv.EcHosts != "" {
+		dp.EcHosts = strings.Split(dpv.EcHosts, underlineSeparator)
+	}
 	for _, rv := range dpv.Replicas {
 		if !contains(dp.Hosts, rv.Addr) {
 			continue
@@ -227,6 +235,9 @@ func newDataPartitionValue(dp *DataPartition) (dpv *dataPartitionValue) {
 		RecoverStartTime:               dp.RecoverStartTime.Unix(),
 		RecoverLastConsumeTime:         dp.RecoverLastConsumeTime.Seconds(),
 		DecommissionWaitTimes:          dp.DecommissionWaitTimes,
+		EcDataNum:                      dp.EcDataNum,
+		EcParityNum:                    dp.EcParityNum,
+		EcHosts:                        strings.Join(dp.EcHosts, underlineSeparator),
 	}
 	for _, replica := range dp.Replicas {
 		rv := &replicaValue{Addr: replica.Addr, DiskPath: replica.DiskPath}
@@ -247,6 +258,8 @@ type volValue struct {
 	FollowerRead          bool
 	Authenticate          bool
 	DpReadOnlyWhenVolFull bool
+	EcDataNum             uint8
+	EcParityNum           uint8
 
 	CrossZone       bool
 	DomainOn        bool
@@ -274,6 +287,7 @@ type volValue struct {
 
 	EnablePosixAcl bool
 	EnableQuota    bool
+	EnableDedup    bool
 
 	EnableTransaction       bsProto.TxOpMask
 	TxTimeout               int64
@@ -288,6 +302,8 @@ type volValue struct {
 	ClientReqPeriod, ClientHitTriggerCnt                   uint32
 	Forbidden                                              bool
 	EnableAuditLog                                         bool
+	CloneSource                                            string
+	CloneVerSeq                                            uint64
 }
 
 func (v *volValue) Bytes() (raw []byte, err error) {
@@ -301,6 +317,8 @@ func newVolValue(vol *Vol) (vv *volValue) {
 		Name:                    vol.Name,
 		ReplicaNum:              vol.mpReplicaNum,
 		DpReplicaNum:            vol.dpReplicaNum,
+		EcDataNum:               vol.ecDataNum,
+		EcParityNum:             vol.ecParityNum,
 		Status:                  vol.Status,
 		DataPartitionSize:       vol.dataPartitionSize,
 		Capacity:                vol.Capacity,
@@ -319,6 +337,7 @@ func newVolValue(vol *Vol) (vv *volValue) {
 		DpSelectorParm:          vol.dpSelectorParm,
 		DefaultPriority:         vol.defaultPriority,
 		EnablePosixAcl:          vol.enablePosixAcl,
+		EnableDedup:             vol.enableDedup,
 		EnableQuota:             vol.enableQuota,
 		EnableTransaction:       vol.enableTransaction,
 		TxTimeout:               vol.txTimeout,
@@ -351,6 +370,8 @@ func newVolValue(vol *Vol) (vv *volValue) {
 		DpReadOnlyWhenVolFull: vol.DpReadOnlyWhenVolFull,
 		Forbidden:             vol.Forbidden,
 		EnableAuditLog:        vol.EnableAuditLog,
+		CloneSource:           vol.CloneSource,
+		CloneVerSeq:           vol.CloneVerSeq,
 	}
 
 	return
@@ -369,6 +390,8 @@ type dataNodeValue struct {
 	NodeSetID                uint64
 	Addr                     string
 	ZoneName                 string
+	Rack                     string
+	Host                     string
 	RdOnly                   bool
 	DecommissionedDisks      []string
 	DecommissionStatus       uint32
@@ -388,6 +411,8 @@ func newDataNodeValue(dataNode *DataNode) *dataNodeValue {
 		NodeSetID:                dataNode.NodeSetID,
 		Addr:                     dataNode.Addr,
 		ZoneName:                 dataNode.ZoneName,
+		Rack:                     dataNode.Rack,
+		Host:                     dataNode.Host,
 		RdOnly:                   dataNode.RdOnly,
 		DecommissionedDisks:      dataNode.getDecommissionedDisks(),
 		DecommissionStatus:       atomic.LoadUint32(&dataNode.DecommissionStatus),
@@ -407,6 +432,8 @@ type metaNodeValue struct {
 	NodeSetID uint64
 	Addr      string
 	ZoneName  string
+	Rack      string
+	Host      string
 	RdOnly    bool
 }
 
@@ -416,6 +443,8 @@ func newMetaNodeValue(metaNode *MetaNode) *metaNodeValue {
 		NodeSetID: metaNode.NodeSetID,
 		Addr:      metaNode.Addr,
 		ZoneName:  metaNode.ZoneName,
+		Rack:      metaNode.Rack,
+		Host:      metaNode.Host,
 		RdOnly:    metaNode.RdOnly,
 	}
 }
@@ -1362,6 +1391,8 @@ func (c *Cluster) loadDataNodes() (err error) {
 		dataNode.ID = dnv.ID
 		dataNode.NodeSetID = dnv.NodeSetID
 		dataNode.RdOnly = dnv.RdOnly
+		dataNode.Rack = dnv.Rack
+		dataNode.Host = dnv.Host
 		for _, disk := range dnv.DecommissionedDisks {
 			dataNode.addDecommissionedDisk(disk)
 		}
@@ -1406,6 +1437,8 @@ func (c *Cluster) loadMetaNodes() (err error) {
 		metaNode.ID = mnv.ID
 		metaNode.NodeSetID = mnv.NodeSetID
 		metaNode.RdOnly = mnv.RdOnly
+		metaNode.Rack = mnv.Rack
+		metaNode.Host = mnv.Host
 
 		oldmn, ok := c.metaNodes.Load(metaNode.Addr)
 		if ok {
@@ -1471,6 +1504,9 @@ func (c *Cluster) loadVols() (err error) {
 		c.putVol(vol)
 		log.LogInfof("action[loadVols],vol[%v]", vol.Name)
 	}
+	for _, vol := range c.copyVols() {
+		c.linkVolClone(vol)
+	}
 	return
 }
 
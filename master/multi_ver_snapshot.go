@@ -197,6 +197,11 @@ func (verMgr *VolVersionManager) SetVerStrategy(strategy proto.VolumeVerStrategy
 		strategy.KeepVerCnt, MaxSnapshotCount, strategy.GetPeriodic(), 24*7, strategy.Enable)
 
 	if strategy.Enable == true {
+		if verMgr.c != nil {
+			if policy := verMgr.c.snapshotPolicyMgr.policyOfVol(verMgr.vol.Name); policy != "" {
+				return fmt.Errorf("SetVerStrategy.vol %v is attached to snapshot policy %v", verMgr.vol.Name, policy)
+			}
+		}
 		if strategy.KeepVerCnt > MaxSnapshotCount || strategy.GetPeriodic() > 24*7 || strategy.KeepVerCnt < 0 || strategy.GetPeriodic() < 0 {
 			return fmt.Errorf("SetVerStrategy.vol %v keepCnt %v need in [1-%v], peroidic %v need in [1-%v] not qualified",
 				verMgr.vol.Name, strategy.KeepVerCnt, MaxSnapshotCount, strategy.GetPeriodic(), 24*7)
@@ -567,6 +572,14 @@ func (verMgr *VolVersionManager) initVer2PhaseTask(verSeq uint64, op uint8) (ver
 			log.LogErrorf("action[VolVersionManager.initVer2PhaseTask] vol %v op %v verSeq %v is uncommitted", verMgr.vol.Name, op, verSeq)
 			return nil, fmt.Errorf("version alreay be deleted"), op
 		}
+		if clones := verMgr.c.volClonesOf(verMgr.vol.Name, verSeq); len(clones) > 0 {
+			log.LogErrorf("action[VolVersionManager.initVer2PhaseTask] vol %v op %v verSeq %v is pinned by clones %v", verMgr.vol.Name, op, verSeq, clones)
+			return nil, fmt.Errorf("version pinned by clones %v", clones), op
+		}
+		if jobs := verMgr.c.snapshotDiffMgr.jobsOf(verMgr.vol.Name, verSeq); len(jobs) > 0 {
+			log.LogErrorf("action[VolVersionManager.initVer2PhaseTask] vol %v op %v verSeq %v is pinned by snapshot diff %v", verMgr.vol.Name, op, verSeq, jobs)
+			return nil, fmt.Errorf("version pinned by snapshot diff %v", jobs), op
+		}
 
 		verMgr.prepareCommit.op = op
 		verMgr.prepareCommit.prepareInfo = &proto.VolVersionInfo{
@@ -605,7 +605,7 @@ func (ns *nodeSet) getAvailMetaNodeHosts(excludeHosts []string, replicaNum int)
 	// we need a read lock to block the modify of node selector
 	ns.metaNodeSelectorLock.RLock()
 	defer ns.metaNodeSelectorLock.RUnlock()
-	return ns.metaNodeSelector.Select(ns, excludeHosts, replicaNum)
+	return ns.selectSpreadHosts(ns.metaNodeSelector, MetaNodeType, excludeHosts, excludeHosts, replicaNum)
 }
 
 func (ns *nodeSet) getAvailDataNodeHosts(excludeHosts []string, replicaNum int) (hosts []string, peers []proto.Peer, err error) {
@@ -614,5 +614,5 @@ func (ns *nodeSet) getAvailDataNodeHosts(excludeHosts []string, replicaNum int)
 	// we need a read lock to block the modify of node selector
 	ns.dataNodeSelectorLock.Lock()
 	defer ns.dataNodeSelectorLock.Unlock()
-	return ns.dataNodeSelector.Select(ns, excludeHosts, replicaNum)
+	return ns.selectSpreadHosts(ns.dataNodeSelector, DataNodeType, excludeHosts, excludeHosts, replicaNum)
 }
@@ -546,3 +546,48 @@ func TestBenchStrawNodeSelector(t *testing.T) {
 	selector = NewStrawNodeSelector(MetaNodeType)
 	metaNodeSelectorBench(t, selector)
 }
+
+func TestSelectSpreadHosts(t *testing.T) {
+	ns := prepareMetaNodesForBench(6, 100*util.GB, 0)
+	ns.placementDomain = PlacementDomainRack
+	racks := make(map[string]string)
+	ns.metaNodes.Range(func(key, value interface{}) bool {
+		node := value.(*MetaNode)
+		node.Rack = fmt.Sprintf("rack%v", node.ID%3)
+		racks[node.Addr] = node.Rack
+		return true
+	})
+
+	hosts, peers, err := ns.getAvailMetaNodeHosts(nil, 3)
+	if err != nil {
+		t.Errorf("failed to select meta nodes %v", err)
+		return
+	}
+	if len(hosts) != 3 || len(peers) != 3 {
+		t.Errorf("expected 3 hosts, got %v", hosts)
+		return
+	}
+	used := make(map[string]bool)
+	for _, host := range hosts {
+		if used[racks[host]] {
+			t.Errorf("hosts %v share rack %v", hosts, racks[host])
+			return
+		}
+		used[racks[host]] = true
+	}
+
+	// the new replica takes the rack left by the replaced one
+	newHosts, _, err := ns.getAvailMetaNodeHostToReplace(hosts, hosts[0])
+	if err != nil {
+		t.Errorf("failed to select meta node %v", err)
+		return
+	}
+	if contains(hosts, newHosts[0]) || racks[newHosts[0]] != racks[hosts[0]] {
+		t.Errorf("replacing %v in %v got %v", hosts[0], hosts, newHosts[0])
+	}
+
+	// no rack is left, the replicas share one rather than failing
+	if hosts, _, err = ns.getAvailMetaNodeHosts(nil, 5); err != nil || len(hosts) != 5 {
+		t.Errorf("failed to select meta nodes %v err %v", hosts, err)
+	}
+}
@@ -31,7 +31,8 @@ import (
 
 func newCreateDataPartitionRequest(volName string, ID uint64, replicaNum int, members []proto.Peer,
 	dataPartitionSize, leaderSize int, hosts []string, createType int, partitionType int,
-	decommissionedDisks []string, verSeq uint64) (req *proto.CreateDataPartitionRequest) {
+	decommissionedDisks []string, verSeq uint64, ecDataNum, ecParityNum int, ecHosts []string,
+) (req *proto.CreateDataPartitionRequest) {
 	req = &proto.CreateDataPartitionRequest{
 		PartitionTyp:        partitionType,
 		PartitionId:         ID,
@@ -44,6 +45,9 @@ func newCreateDataPartitionRequest(volName string, ID uint64, replicaNum int, me
 		LeaderSize:          leaderSize,
 		DecommissionedDisks: decommissionedDisks,
 		VerSeq:              verSeq,
+		EcDataNum:           ecDataNum,
+		EcParityNum:         ecParityNum,
+		EcHosts:             ecHosts,
 	}
 	return
 }
@@ -86,6 +90,14 @@ func newStopDataPartitionRepairRequest(ID uint64, stop bool) (req *proto.StopDat
 	return
 }
 
+func newUpdateDataPartitionEcHostsRequest(ID uint64, ecHosts []string) (req *proto.UpdateDataPartitionEcHostsRequest) {
+	req = &proto.UpdateDataPartitionEcHostsRequest{
+		PartitionId: ID,
+		EcHosts:     ecHosts,
+	}
+	return
+}
+
 func unmarshalTaskResponse(task *proto.AdminTask) (err error) {
 	bytes, err := json.Marshal(task.Response)
 	if err != nil {
@@ -117,6 +129,8 @@ func unmarshalTaskResponse(task *proto.AdminTask) (err error) {
 		response = &proto.LcNodeRuleTaskResponse{}
 	case proto.OpLcNodeSnapshotVerDel:
 		response = &proto.SnapshotVerDelTaskResponse{}
+	case proto.OpLcNodeSnapshotDiff:
+		response = &proto.SnapshotDiffTaskResponse{}
 
 	default:
 		log.LogError(fmt.Sprintf("unknown operate code(%v)", task.OpCode))
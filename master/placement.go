@@ -0,0 +1,267 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"net"
+	"net/http"
+	"sort"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// The nodes register the rack and the host they run on besides the zone. With
+// placementDomain configured, the replicas placed in a node set are spread
+// across the racks, or the hosts, so that losing one of them loses at most one
+// replica. A node without rack label stands for a rack of its own host, a node
+// without host label is identified by its ip. The spreading is a preference,
+// the replicas still share a failure domain if no other node is writable.
+const (
+	PlacementDomainHost = "host"
+	PlacementDomainRack = "rack"
+)
+
+func isValidPlacementDomain(level string) bool {
+	switch level {
+	case "", PlacementDomainHost, PlacementDomainRack:
+		return true
+	default:
+		return false
+	}
+}
+
+// updateNodeLocation sets the labels reported by a registering node, an empty
+// label keeps the one known.
+func updateNodeLocation(rack, host *string, rackName, hostName string) (changed bool) {
+	if rackName != "" && rackName != *rack {
+		*rack = rackName
+		changed = true
+	}
+	if hostName != "" && hostName != *host {
+		*host = hostName
+		changed = true
+	}
+	return
+}
+
+// placementDomainOf returns the failure domain of the node at the given level.
+func placementDomainOf(level, addr, rack, host string) string {
+	if host == "" {
+		if ip, _, err := net.SplitHostPort(addr); err == nil {
+			host = ip
+		} else {
+			host = addr
+		}
+	}
+	if level == PlacementDomainRack && rack != "" {
+		return PlacementDomainRack + "/" + rack
+	}
+	return PlacementDomainHost + "/" + host
+}
+
+func nodePlacementDomain(level string, node interface{}) string {
+	switch n := node.(type) {
+	case *DataNode:
+		return placementDomainOf(level, n.Addr, n.Rack, n.Host)
+	case *MetaNode:
+		return placementDomainOf(level, n.Addr, n.Rack, n.Host)
+	default:
+		panic("unknown node type")
+	}
+}
+
+// selectSpreadHosts picks the replicas one by one. The nodes sharing a failure
+// domain with spreadHosts or with the replicas already picked are left out as
+// long as the selector finds another node.
+func (ns *nodeSet) selectSpreadHosts(selector NodeSelector, nodeType NodeType, excludeHosts, spreadHosts []string,
+	replicaNum int) (newHosts []string, peers []proto.Peer, err error) {
+	if ns.placementDomain == "" || replicaNum == 0 {
+		return selector.Select(ns, excludeHosts, replicaNum)
+	}
+	nodes := ns.getNodes(nodeType)
+	used := make(map[string]bool)
+	for _, host := range spreadHosts {
+		if node, ok := nodes.Load(host); ok {
+			used[nodePlacementDomain(ns.placementDomain, node)] = true
+		}
+	}
+	exclude := append(make([]string, 0, len(excludeHosts)+replicaNum), excludeHosts...)
+	orderHosts := make([]string, 0, replicaNum)
+	peers = make([]proto.Peer, 0, replicaNum)
+	for i := 0; i < replicaNum; i++ {
+		spreadExclude := append(make([]string, 0, len(exclude)), exclude...)
+		nodes.Range(func(key, value interface{}) bool {
+			if used[nodePlacementDomain(ns.placementDomain, value)] {
+				spreadExclude = append(spreadExclude, key.(string))
+			}
+			return true
+		})
+		hosts, newPeers, err := selector.Select(ns, spreadExclude, 1)
+		if err != nil {
+			log.LogWarnf("action[selectSpreadHosts] nodeset[%v] no writable node out of failure domains %v, err %v",
+				ns.ID, used, err)
+			if hosts, newPeers, err = selector.Select(ns, exclude, 1); err != nil {
+				return nil, nil, err
+			}
+		}
+		host := hosts[0]
+		exclude = append(exclude, host)
+		orderHosts = append(orderHosts, host)
+		peers = append(peers, newPeers...)
+		if node, ok := nodes.Load(host); ok {
+			used[nodePlacementDomain(ns.placementDomain, node)] = true
+		}
+	}
+	if newHosts, err = reshuffleHosts(orderHosts); err != nil {
+		return nil, nil, err
+	}
+	return
+}
+
+func excludeHost(hosts []string, addr string) (left []string) {
+	left = make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		if host != addr {
+			left = append(left, host)
+		}
+	}
+	return
+}
+
+// getAvailDataNodeHostToReplace picks the node replacing srcAddr, it is spread
+// across the failure domains of the other replicas only.
+func (ns *nodeSet) getAvailDataNodeHostToReplace(hosts []string, srcAddr string) (newHosts []string, peers []proto.Peer, err error) {
+	ns.nodeSelectLock.Lock()
+	defer ns.nodeSelectLock.Unlock()
+	ns.dataNodeSelectorLock.Lock()
+	defer ns.dataNodeSelectorLock.Unlock()
+	return ns.selectSpreadHosts(ns.dataNodeSelector, DataNodeType, hosts, excludeHost(hosts, srcAddr), 1)
+}
+
+func (ns *nodeSet) getAvailMetaNodeHostToReplace(hosts []string, srcAddr string) (newHosts []string, peers []proto.Peer, err error) {
+	ns.nodeSelectLock.Lock()
+	defer ns.nodeSelectLock.Unlock()
+	ns.metaNodeSelectorLock.RLock()
+	defer ns.metaNodeSelectorLock.RUnlock()
+	return ns.selectSpreadHosts(ns.metaNodeSelector, MetaNodeType, hosts, excludeHost(hosts, srcAddr), 1)
+}
+
+func (c *Cluster) placementLevel() string {
+	if c.cfg.PlacementDomain == "" {
+		return PlacementDomainHost
+	}
+	return c.cfg.PlacementDomain
+}
+
+// placementViolations returns the failure domains holding more than one of the hosts.
+func placementViolations(hosts []string, domainOf func(addr string) (string, bool)) (violations map[string][]string) {
+	domains := make(map[string][]string)
+	for _, host := range hosts {
+		if domain, ok := domainOf(host); ok {
+			domains[domain] = append(domains[domain], host)
+		}
+	}
+	for domain, addrs := range domains {
+		if len(addrs) < 2 {
+			continue
+		}
+		if violations == nil {
+			violations = make(map[string][]string)
+		}
+		violations[domain] = addrs
+	}
+	return
+}
+
+func (c *Cluster) getPlacementView() (view *proto.PlacementView) {
+	level := c.placementLevel()
+	view = &proto.PlacementView{
+		Level:      level,
+		DataNodes:  make(map[string][]string),
+		MetaNodes:  make(map[string][]string),
+		Violations: make([]*proto.PlacementViolation, 0),
+	}
+	c.dataNodes.Range(func(key, value interface{}) bool {
+		domain := nodePlacementDomain(level, value)
+		view.DataNodes[domain] = append(view.DataNodes[domain], key.(string))
+		return true
+	})
+	c.metaNodes.Range(func(key, value interface{}) bool {
+		domain := nodePlacementDomain(level, value)
+		view.MetaNodes[domain] = append(view.MetaNodes[domain], key.(string))
+		return true
+	})
+	dataNodeDomain := func(addr string) (string, bool) {
+		node, ok := c.dataNodes.Load(addr)
+		if !ok {
+			return "", false
+		}
+		return nodePlacementDomain(level, node), true
+	}
+	metaNodeDomain := func(addr string) (string, bool) {
+		node, ok := c.metaNodes.Load(addr)
+		if !ok {
+			return "", false
+		}
+		return nodePlacementDomain(level, node), true
+	}
+
+	for _, vol := range c.allVols() {
+		for _, dp := range vol.dataPartitions.clonePartitions() {
+			dp.RLock()
+			hosts := append([]string{}, dp.Hosts...)
+			dp.RUnlock()
+			for domain, addrs := range placementViolations(hosts, dataNodeDomain) {
+				view.Violations = append(view.Violations, &proto.PlacementViolation{
+					PartitionID: dp.PartitionID, PartitionType: "data", VolName: vol.Name, Domain: domain, Hosts: addrs,
+				})
+			}
+		}
+		for _, mp := range vol.cloneMetaPartitionMap() {
+			mp.RLock()
+			hosts := append([]string{}, mp.Hosts...)
+			mp.RUnlock()
+			for domain, addrs := range placementViolations(hosts, metaNodeDomain) {
+				view.Violations = append(view.Violations, &proto.PlacementViolation{
+					PartitionID: mp.PartitionID, PartitionType: "meta", VolName: vol.Name, Domain: domain, Hosts: addrs,
+				})
+			}
+		}
+	}
+	for _, addrs := range view.DataNodes {
+		sort.Strings(addrs)
+	}
+	for _, addrs := range view.MetaNodes {
+		sort.Strings(addrs)
+	}
+	sort.Slice(view.Violations, func(i, j int) bool {
+		if view.Violations[i].PartitionType != view.Violations[j].PartitionType {
+			return view.Violations[i].PartitionType < view.Violations[j].PartitionType
+		}
+		return view.Violations[i].PartitionID < view.Violations[j].PartitionID
+	})
+	return
+}
+
+// View the failure domains and the partitions whose replicas share one.
+func (m *Server) getPlacementView(w http.ResponseWriter, r *http.Request) {
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.GetPlacementView))
+	defer func() {
+		doStatAndMetric(proto.GetPlacementView, metric, nil, nil)
+	}()
+	sendOkReply(w, r, newSuccessHTTPReply(m.cluster.getPlacementView()))
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/raftstore"
 	"github.com/cubefs/cubefs/raftstore/raftstore_db"
+	"github.com/cubefs/cubefs/util/auditlog"
 	"github.com/cubefs/cubefs/util/config"
 	"github.com/cubefs/cubefs/util/cryptoutil"
 	"github.com/cubefs/cubefs/util/errors"
@@ -128,6 +129,8 @@ type Server struct {
 	reverseProxy    *httputil.ReverseProxy
 	metaReady       bool
 	apiServer       *http.Server
+	adminAudit      *auditlog.Audit
+	adminReplay     adminReplayCache
 }
 
 // NewServer creates a new server
@@ -167,6 +170,12 @@ func (m *Server) Start(cfg *config.Config) (err error) {
 		m.cluster.initAuthentication(cfg)
 	}
 
+	if m.logDir != "" {
+		if m.adminAudit, err = auditlog.NewAudit(m.logDir, adminAuditModule, auditlog.DefaultAuditLogSize); err != nil {
+			return fmt.Errorf("action[Start] init admin audit log failed, err: %v", err)
+		}
+	}
+
 	m.cluster.scheduleTask()
 	m.startHTTPService(ModuleName, cfg)
 	exporter.RegistConsul(m.clusterName, ModuleName, cfg)
@@ -190,6 +199,9 @@ func (m *Server) Shutdown() {
 		}
 	}
 	stat.CloseStat()
+	if m.adminAudit != nil {
+		m.adminAudit.Stop()
+	}
 
 	// stop raftServer first
 	if m.fsm != nil {
@@ -240,6 +252,32 @@ func (m *Server) checkConfig(cfg *config.Config) (err error) {
 	syslog.Printf("get disableAutoCreate cfg %v", m.config.DisableAutoCreate)
 
 	m.config.faultDomain = cfg.GetBoolWithDefault(faultDomain, false)
+	m.config.PlacementDomain = cfg.GetString(cfgPlacementDomain)
+	if !isValidPlacementDomain(m.config.PlacementDomain) {
+		return fmt.Errorf("%v,err:unknown placementDomain %v", proto.ErrInvalidCfg, m.config.PlacementDomain)
+	}
+	syslog.Printf("placementDomain[%v]\n", m.config.PlacementDomain)
+	m.config.RBACEnable = cfg.GetBoolWithDefault(cfgRBACEnable, false)
+	m.config.RBACAnonymousRole = proto.AdminRoleViewer
+	if role, ok := cfg.CheckAndGetString(cfgRBACAnonymousRole); ok {
+		if role != "" && role != proto.AdminRoleViewer && role != proto.AdminRoleOperator {
+			return fmt.Errorf("%v,err:invalid rbacAnonymousRole %v", proto.ErrInvalidCfg, role)
+		}
+		m.config.RBACAnonymousRole = role
+	}
+	syslog.Printf("rbacEnable[%v],rbacAnonymousRole[%v]\n", m.config.RBACEnable, m.config.RBACAnonymousRole)
+	m.config.MetadataBackupDir = cfg.GetString(cfgMetadataBackupDir)
+	m.config.EventKeepCount = cfg.GetIntWithDefault(cfgEventKeepCount, defaultEventKeepCount)
+	m.config.EventKeepDays = cfg.GetIntWithDefault(cfgEventKeepDays, defaultEventKeepDays)
+	m.config.EventDedupSec = cfg.GetInt64WithDefault(cfgEventDedupSec, defaultEventDedupSec)
+	m.config.EventWebhookUrl = cfg.GetString(cfgEventWebhookUrl)
+	m.config.EventKafkaBrokers = cfg.GetStringSlice(cfgEventKafkaBrokers)
+	m.config.EventKafkaTopic = cfg.GetString(cfgEventKafkaTopic)
+	if len(m.config.EventKafkaBrokers) > 0 && m.config.EventKafkaTopic == "" {
+		return fmt.Errorf("%v,err:%v is required by %v", proto.ErrInvalidCfg, cfgEventKafkaTopic, cfgEventKafkaBrokers)
+	}
+	syslog.Printf("eventKeepCount[%v],eventKeepDays[%v],eventWebhookUrl[%v],eventKafkaBrokers[%v]\n",
+		m.config.EventKeepCount, m.config.EventKeepDays, m.config.EventWebhookUrl, m.config.EventKafkaBrokers)
 	m.config.heartbeatPort = cfg.GetInt64(heartbeatPortKey)
 	m.config.replicaPort = cfg.GetInt64(replicaPortKey)
 	if m.config.heartbeatPort <= 1024 {
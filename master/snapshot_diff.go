@@ -0,0 +1,403 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	snapshotDiffCheckInterval    = time.Minute
+	snapshotDiffResultExpiration = time.Hour
+	snapshotDiffTaskTimeout      = time.Hour * 6
+	defaultSnapshotDiffPageLimit = 1000
+	maxSnapshotDiffPageLimit     = 10000
+)
+
+// snapshotDiffManager keeps the snapshot diff jobs run by the lcnodes. The jobs and the pages
+// of their entries are persisted by raft, only the jobs are kept in memory and the entries are
+// read from the store page by page. The versions compared by a running job can not be deleted.
+type snapshotDiffManager struct {
+	sync.RWMutex
+	cluster *Cluster
+	jobs    map[string]*proto.SnapshotDiffTaskResponse
+}
+
+func newSnapshotDiffManager(c *Cluster) *snapshotDiffManager {
+	return &snapshotDiffManager{
+		cluster: c,
+		jobs:    make(map[string]*proto.SnapshotDiffTaskResponse),
+	}
+}
+
+func (m *snapshotDiffManager) reset() {
+	m.Lock()
+	defer m.Unlock()
+	m.jobs = make(map[string]*proto.SnapshotDiffTaskResponse)
+}
+
+// createSnapshotDiff dispatches the job listing the paths changed in the volume
+// between the versions fromVerSeq and toVerSeq, toVerSeq 0 stands for the current version.
+func (m *snapshotDiffManager) createSnapshotDiff(volName string, fromVerSeq, toVerSeq uint64) (id string, err error) {
+	var vol *Vol
+	if vol, err = m.cluster.getVol(volName); err != nil {
+		return
+	}
+	if toVerSeq != 0 && fromVerSeq >= toVerSeq {
+		return "", fmt.Errorf("from version %v should be older than to version %v", fromVerSeq, toVerSeq)
+	}
+	node := m.cluster.pickActiveLcNode()
+	if node == nil {
+		return "", fmt.Errorf("no active lcnode")
+	}
+
+	task := &proto.SnapshotDiffTask{
+		Id:         fmt.Sprintf("%s:%d:%d:%d", volName, fromVerSeq, toVerSeq, time.Now().UnixNano()),
+		VolName:    volName,
+		FromVerSeq: fromVerSeq,
+		ToVerSeq:   toVerSeq,
+	}
+	t := time.Now()
+	job := &proto.SnapshotDiffTaskResponse{
+		ID:               task.Id,
+		LcNode:           node.Addr,
+		StartTime:        &t,
+		UpdateTime:       &t,
+		SnapshotDiffTask: task,
+	}
+
+	// the job pins the versions before they are checked, a version deleted
+	// in the meantime is either found deleting here or refused to delete
+	m.Lock()
+	m.jobs[task.Id] = job
+	m.Unlock()
+	defer func() {
+		if err != nil {
+			m.Lock()
+			delete(m.jobs, task.Id)
+			m.Unlock()
+		}
+	}()
+	if err = vol.VersionMgr.checkCloneVer(fromVerSeq); err != nil {
+		return
+	}
+	if toVerSeq != 0 {
+		if err = vol.VersionMgr.checkCloneVer(toVerSeq); err != nil {
+			return
+		}
+	}
+	if err = m.cluster.syncPutSnapshotDiff(job); err != nil {
+		return
+	}
+
+	m.cluster.addLcNodeTasks([]*proto.AdminTask{node.createSnapshotDiffTask(m.cluster.masterAddr(), task)})
+	log.LogInfof("action[createSnapshotDiff] add snapshot diff task(%v) to lcnode(%v)", *task, node.Addr)
+	return task.Id, nil
+}
+
+// jobsOf returns the running jobs comparing the version verSeq of the volume.
+func (m *snapshotDiffManager) jobsOf(volName string, verSeq uint64) (ids []string) {
+	m.RLock()
+	defer m.RUnlock()
+	for id, job := range m.jobs {
+		task := job.SnapshotDiffTask
+		if job.Done || time.Since(*job.StartTime) > snapshotDiffTaskTimeout || task.VolName != volName {
+			continue
+		}
+		if task.FromVerSeq == verSeq || task.ToVerSeq == verSeq {
+			ids = append(ids, id)
+		}
+	}
+	return
+}
+
+func checkSnapshotDiffPage(job *proto.SnapshotDiffTaskResponse, page *proto.SnapshotDiffPage) error {
+	if job.Done {
+		return fmt.Errorf("snapshot diff task %v is done", job.ID)
+	}
+	if job.LcNode != page.LcNode || job.SnapshotDiffTask.VolName != page.VolName {
+		return fmt.Errorf("snapshot diff task %v of vol %v is run by %v", job.ID, job.SnapshotDiffTask.VolName, job.LcNode)
+	}
+	// a page put again is retried by the lcnode
+	if page.Page < 0 || page.Page > job.Pages {
+		return fmt.Errorf("snapshot diff task %v expects page %v, got %v", job.ID, job.Pages, page.Page)
+	}
+	if len(page.Entries) == 0 || len(page.Entries) > proto.SnapshotDiffPageSize {
+		return fmt.Errorf("snapshot diff page should have 1 to %v entries, got %v", proto.SnapshotDiffPageSize, len(page.Entries))
+	}
+	return nil
+}
+
+// putPage persists a page of the entries along with the count of the pages of the job.
+func (m *snapshotDiffManager) putPage(page *proto.SnapshotDiffPage) (err error) {
+	m.Lock()
+	defer m.Unlock()
+	pending, ok := m.jobs[page.ID]
+	if !ok {
+		return fmt.Errorf("snapshot diff task %v is unknown or expired", page.ID)
+	}
+	if err = checkSnapshotDiffPage(pending, page); err != nil {
+		return
+	}
+	job := *pending
+	if page.Page == job.Pages {
+		job.Pages++
+	}
+	t := time.Now()
+	job.UpdateTime = &t
+	if err = m.cluster.syncPutSnapshotDiffPage(&job, page); err != nil {
+		return
+	}
+	m.jobs[page.ID] = &job
+	return
+}
+
+func (m *snapshotDiffManager) handleResponse(resp *proto.SnapshotDiffTaskResponse) {
+	m.Lock()
+	defer m.Unlock()
+	pending, ok := m.jobs[resp.ID]
+	if !ok {
+		log.LogWarnf("action[handleSnapshotDiffResp] task(%v) is unknown or expired", resp.ID)
+		return
+	}
+	t := time.Now()
+	if !resp.Done {
+		// the lcnode acknowledged the task
+		pending.UpdateTime = &t
+		return
+	}
+	if pending.Done {
+		return
+	}
+	job := *pending
+	job.UpdateTime = &t
+	job.EndTime = resp.EndTime
+	job.Done = true
+	job.Status = resp.Status
+	job.Result = resp.Result
+	job.Total = resp.Total
+	if job.Status == proto.TaskSucceeds && job.Total > job.Pages*proto.SnapshotDiffPageSize {
+		job.Status = proto.TaskFailed
+		job.Result = fmt.Sprintf("%v entries reported but only %v pages put", job.Total, job.Pages)
+	}
+	if err := m.cluster.syncPutSnapshotDiff(&job); err != nil {
+		log.LogErrorf("action[handleSnapshotDiffResp] persist task(%v) err(%v)", resp.ID, err)
+		return
+	}
+	m.jobs[resp.ID] = &job
+}
+
+// getResult returns the page of the entries after marker. A task not done
+// after snapshotDiffTaskTimeout is reported failed.
+func (m *snapshotDiffManager) getResult(volName, id string, marker, limit int) (result *proto.SnapshotDiffResult, err error) {
+	m.RLock()
+	job, ok := m.jobs[id]
+	m.RUnlock()
+	if !ok || job.SnapshotDiffTask.VolName != volName {
+		return nil, fmt.Errorf("snapshot diff task %v of vol %v not found", id, volName)
+	}
+	if limit <= 0 {
+		limit = defaultSnapshotDiffPageLimit
+	} else if limit > maxSnapshotDiffPageLimit {
+		limit = maxSnapshotDiffPageLimit
+	}
+
+	result = &proto.SnapshotDiffResult{
+		ID:         job.ID,
+		VolName:    job.SnapshotDiffTask.VolName,
+		FromVerSeq: job.SnapshotDiffTask.FromVerSeq,
+		ToVerSeq:   job.SnapshotDiffTask.ToVerSeq,
+		Done:       job.Done,
+		Status:     job.Status,
+		Result:     job.Result,
+	}
+	if !job.Done && time.Since(*job.StartTime) > snapshotDiffTaskTimeout {
+		result.Done = true
+		result.Status = proto.TaskFailed
+		result.Result = fmt.Sprintf("lcnode %v did not report in %v", job.LcNode, snapshotDiffTaskTimeout)
+	}
+	// the entries are listed once the job succeeded
+	if !job.Done || job.Status != proto.TaskSucceeds {
+		return
+	}
+	result.Total = job.Total
+	if marker < 0 || marker > job.Total {
+		return nil, fmt.Errorf("marker %v out of range [0, %v]", marker, job.Total)
+	}
+	end := marker + limit
+	if end < job.Total {
+		result.NextMarker = end
+	} else {
+		end = job.Total
+	}
+	for page := marker / proto.SnapshotDiffPageSize; page*proto.SnapshotDiffPageSize < end; page++ {
+		var entries []proto.SnapshotDiffEntry
+		if entries, err = m.cluster.loadSnapshotDiffPage(id, page); err != nil {
+			return nil, err
+		}
+		from := page * proto.SnapshotDiffPageSize
+		lo, hi := marker-from, end-from
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(entries) {
+			hi = len(entries)
+		}
+		if lo < hi {
+			result.Entries = append(result.Entries, entries[lo:hi]...)
+		}
+	}
+	return
+}
+
+// deleteOldResults deletes the expired jobs along with their pages.
+func (m *snapshotDiffManager) deleteOldResults() {
+	var expired []*proto.SnapshotDiffTaskResponse
+	m.RLock()
+	for _, job := range m.jobs {
+		if job.Done && time.Since(*job.UpdateTime) > snapshotDiffResultExpiration {
+			expired = append(expired, job)
+			continue
+		}
+		if !job.Done && time.Since(*job.StartTime) > snapshotDiffTaskTimeout+snapshotDiffResultExpiration {
+			log.LogWarnf("action[deleteOldResults] snapshot diff task not done: %v", job.ID)
+			expired = append(expired, job)
+		}
+	}
+	m.RUnlock()
+
+	for _, job := range expired {
+		if err := m.cluster.syncDeleteSnapshotDiff(job); err != nil {
+			log.LogWarnf("action[deleteOldResults] delete snapshot diff result %v err %v", job.ID, err)
+			continue
+		}
+		m.Lock()
+		delete(m.jobs, job.ID)
+		m.Unlock()
+		log.LogDebugf("action[deleteOldResults] delete snapshot diff result: %v", job.ID)
+	}
+}
+
+func (c *Cluster) scheduleToSnapshotDiff() {
+	go func() {
+		ticker := time.NewTicker(snapshotDiffCheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if c.partition != nil && c.partition.IsRaftLeader() {
+				c.snapshotDiffMgr.deleteOldResults()
+			}
+		}
+	}()
+}
+
+func snapshotDiffPageKey(id string, page int) string {
+	return fmt.Sprintf("%s%s%s%010d", diffPagePrefix, id, keySeparator, page)
+}
+
+func (c *Cluster) syncPutSnapshotDiff(job *proto.SnapshotDiffTaskResponse) (err error) {
+	metadata := new(RaftCmd)
+	metadata.Op = opSyncPutSnapshotDiff
+	metadata.K = snapshotDiffPrefix + job.ID
+	if metadata.V, err = json.Marshal(job); err != nil {
+		return errors.New(err.Error())
+	}
+	return c.submit(metadata)
+}
+
+// syncPutSnapshotDiffPage puts the page and the job in one raft command, so that
+// the pages of the job are always counted.
+func (c *Cluster) syncPutSnapshotDiffPage(job *proto.SnapshotDiffTaskResponse, page *proto.SnapshotDiffPage) (err error) {
+	jobCmd := &RaftCmd{Op: opSyncPutSnapshotDiff, K: snapshotDiffPrefix + job.ID}
+	if jobCmd.V, err = json.Marshal(job); err != nil {
+		return errors.New(err.Error())
+	}
+	pageCmd := &RaftCmd{Op: opSyncPutSnapshotDiff, K: snapshotDiffPageKey(job.ID, page.Page)}
+	if pageCmd.V, err = json.Marshal(page.Entries); err != nil {
+		return errors.New(err.Error())
+	}
+	return c.syncBatchCommitCmd(map[string]*RaftCmd{jobCmd.K: jobCmd, pageCmd.K: pageCmd})
+}
+
+func (c *Cluster) syncDeleteSnapshotDiff(job *proto.SnapshotDiffTaskResponse) (err error) {
+	cmds := make(map[string]*RaftCmd, job.Pages+1)
+	key := snapshotDiffPrefix + job.ID
+	cmds[key] = &RaftCmd{Op: opSyncDeleteSnapshotDiff, K: key}
+	for page := 0; page < job.Pages; page++ {
+		key = snapshotDiffPageKey(job.ID, page)
+		cmds[key] = &RaftCmd{Op: opSyncDeleteSnapshotDiff, K: key}
+	}
+	return c.syncBatchCommitCmd(cmds)
+}
+
+func (c *Cluster) loadSnapshotDiffPage(id string, page int) (entries []proto.SnapshotDiffEntry, err error) {
+	value, err := c.fsm.store.Get(snapshotDiffPageKey(id, page))
+	if err != nil {
+		return nil, fmt.Errorf("action[loadSnapshotDiffPage] task %v page %v err:%v", id, page, err)
+	}
+	data, _ := value.([]byte)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("action[loadSnapshotDiffPage] task %v page %v not found", id, page)
+	}
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("action[loadSnapshotDiffPage] task %v page %v unmarshal err:%v", id, page, err)
+	}
+	return
+}
+
+func (c *Cluster) loadSnapshotDiffs() (err error) {
+	result, err := c.fsm.store.SeekForPrefix([]byte(snapshotDiffPrefix))
+	if err != nil {
+		return fmt.Errorf("action[loadSnapshotDiffs],err:%v", err.Error())
+	}
+	c.snapshotDiffMgr.reset()
+	for _, value := range result {
+		job := &proto.SnapshotDiffTaskResponse{}
+		if err = json.Unmarshal(value, job); err != nil {
+			return fmt.Errorf("action[loadSnapshotDiffs],value:%v,unmarshal err:%v", string(value), err)
+		}
+		c.snapshotDiffMgr.jobs[job.ID] = job
+		log.LogInfof("action[loadSnapshotDiffs],task[%v] done %v pages %v", job.ID, job.Done, job.Pages)
+	}
+	return
+}
+
+// pickActiveLcNode returns the active lcnode running the least snapshot tasks.
+func (c *Cluster) pickActiveLcNode() (node *LcNode) {
+	c.snapshotMgr.lcNodeStatus.RLock()
+	defer c.snapshotMgr.lcNodeStatus.RUnlock()
+	minCount := -1
+	c.lcNodes.Range(func(addr, value interface{}) bool {
+		lcNode := value.(*LcNode)
+		lcNode.RLock()
+		active := lcNode.IsActive
+		lcNode.RUnlock()
+		if !active {
+			return true
+		}
+		count := c.snapshotMgr.lcNodeStatus.WorkingCount[lcNode.Addr]
+		if minCount < 0 || count < minCount {
+			node, minCount = lcNode, count
+		}
+		return true
+	})
+	return
+}
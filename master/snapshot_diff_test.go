@@ -0,0 +1,86 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func TestSnapshotDiffPinsVersions(t *testing.T) {
+	m := newSnapshotDiffManager(nil)
+	start := time.Now()
+	m.jobs["running"] = &proto.SnapshotDiffTaskResponse{
+		ID: "running", StartTime: &start,
+		SnapshotDiffTask: &proto.SnapshotDiffTask{Id: "running", VolName: "diffVol", FromVerSeq: 1, ToVerSeq: 3},
+	}
+	m.jobs["done"] = &proto.SnapshotDiffTaskResponse{
+		ID: "done", StartTime: &start, Done: true,
+		SnapshotDiffTask: &proto.SnapshotDiffTask{Id: "done", VolName: "diffVol", FromVerSeq: 2},
+	}
+	expired := start.Add(-snapshotDiffTaskTimeout - time.Minute)
+	m.jobs["timeout"] = &proto.SnapshotDiffTaskResponse{
+		ID: "timeout", StartTime: &expired,
+		SnapshotDiffTask: &proto.SnapshotDiffTask{Id: "timeout", VolName: "diffVol", FromVerSeq: 4},
+	}
+
+	for _, c := range []struct {
+		vol    string
+		verSeq uint64
+		pinned bool
+	}{
+		{"diffVol", 1, true},
+		{"diffVol", 3, true},
+		{"diffVol", 2, false},
+		{"diffVol", 4, false},
+		{"otherVol", 1, false},
+	} {
+		if jobs := m.jobsOf(c.vol, c.verSeq); (len(jobs) > 0) != c.pinned {
+			t.Errorf("vol %v version %v pinned by %v, expect pinned %v", c.vol, c.verSeq, jobs, c.pinned)
+		}
+	}
+}
+
+func TestSnapshotDiffCheckPage(t *testing.T) {
+	job := &proto.SnapshotDiffTaskResponse{
+		ID: "diffTask", LcNode: "lcnode1", Pages: 1,
+		SnapshotDiffTask: &proto.SnapshotDiffTask{Id: "diffTask", VolName: "diffVol", FromVerSeq: 1},
+	}
+	page := func(lcNode string, n, entries int) *proto.SnapshotDiffPage {
+		return &proto.SnapshotDiffPage{ID: "diffTask", VolName: "diffVol", LcNode: lcNode, Page: n, Entries: make([]proto.SnapshotDiffEntry, entries)}
+	}
+
+	if err := checkSnapshotDiffPage(job, page("lcnode1", 1, 10)); err != nil {
+		t.Errorf("next page err %v", err)
+	}
+	if err := checkSnapshotDiffPage(job, page("lcnode1", 0, 10)); err != nil {
+		t.Errorf("retried page err %v", err)
+	}
+	if err := checkSnapshotDiffPage(job, page("lcnode1", 2, 10)); err == nil {
+		t.Errorf("page out of order should fail")
+	}
+	if err := checkSnapshotDiffPage(job, page("lcnode2", 1, 10)); err == nil {
+		t.Errorf("page from other lcnode should fail")
+	}
+	if err := checkSnapshotDiffPage(job, page("lcnode1", 1, proto.SnapshotDiffPageSize+1)); err == nil {
+		t.Errorf("page too large should fail")
+	}
+	job.Done = true
+	if err := checkSnapshotDiffPage(job, page("lcnode1", 1, 10)); err == nil {
+		t.Errorf("page of done task should fail")
+	}
+}
@@ -0,0 +1,637 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	snapshotPolicyCheckInterval = time.Minute
+	// the schedules are looked up this far ahead, a schedule like "0 0 29 2 *" fires once in 4 years
+	snapshotCronSearchLimit = 5 * 366 * 24 * time.Hour
+)
+
+// snapshotCron is a parsed "minute hour day-of-month month day-of-week" schedule,
+// every field is a bitmask of the values it matches.
+type snapshotCron struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+var snapshotCronMacros = map[string]string{
+	"@hourly": "0 * * * *",
+	"@daily":  "0 0 * * *",
+	"@weekly": "0 0 * * 0",
+}
+
+func parseSnapshotCron(spec string) (sc *snapshotCron, err error) {
+	if macro, ok := snapshotCronMacros[strings.TrimSpace(spec)]; ok {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule %q should have 5 fields: minute hour day-of-month month day-of-week", spec)
+	}
+	sc = &snapshotCron{
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	masks := [5]*uint64{&sc.minute, &sc.hour, &sc.dom, &sc.month, &sc.dow}
+	for i, field := range fields {
+		if *masks[i], err = parseSnapshotCronField(field, bounds[i][0], bounds[i][1]); err != nil {
+			return nil, fmt.Errorf("schedule %q: %v", spec, err)
+		}
+	}
+	// both 0 and 7 stand for sunday
+	if sc.dow&(1<<7) != 0 {
+		sc.dow |= 1
+	}
+	return
+}
+
+func parseSnapshotCronField(field string, min, max int) (mask uint64, err error) {
+	for _, item := range strings.Split(field, ",") {
+		var (
+			rangePart = item
+			step      = 1
+			lo, hi    int
+		)
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			rangePart = item[:idx]
+			if step, err = strconv.Atoi(item[idx+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", item)
+			}
+		}
+		switch {
+		case rangePart == "*":
+			lo, hi = min, max
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid range %q", item)
+			}
+			if hi, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, fmt.Errorf("invalid range %q", item)
+			}
+		default:
+			if lo, err = strconv.Atoi(rangePart); err != nil {
+				return 0, fmt.Errorf("invalid value %q", item)
+			}
+			hi = lo
+			if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range [%v-%v]", item, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return
+}
+
+func (sc *snapshotCron) dayMatches(t time.Time) bool {
+	domMatch := sc.dom&(1<<uint(t.Day())) != 0
+	dowMatch := sc.dow&(1<<uint(t.Weekday())) != 0
+	// like cron, a day matches either restricted field when both are restricted
+	if sc.domStar || sc.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// next returns the first time matching the schedule after t, the zero time if there is none.
+func (sc *snapshotCron) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(snapshotCronSearchLimit)
+	for t.Before(limit) {
+		if sc.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !sc.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if sc.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if sc.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func checkSnapshotPolicy(policy *proto.SnapshotPolicy) (err error) {
+	if policy.Name == "" {
+		return fmt.Errorf("policy name is empty")
+	}
+	if len(policy.Tiers) == 0 {
+		return fmt.Errorf("policy %v has no tier", policy.Name)
+	}
+	keep := 0
+	names := make(map[string]bool)
+	for _, tier := range policy.Tiers {
+		if tier.Name == "" {
+			return fmt.Errorf("policy %v has a tier without name", policy.Name)
+		}
+		if names[tier.Name] {
+			return fmt.Errorf("policy %v has duplicated tier %v", policy.Name, tier.Name)
+		}
+		names[tier.Name] = true
+		if tier.Keep <= 0 {
+			return fmt.Errorf("tier %v should keep at least 1 version", tier.Name)
+		}
+		if _, err = parseSnapshotCron(tier.Schedule); err != nil {
+			return fmt.Errorf("tier %v: %v", tier.Name, err)
+		}
+		keep += tier.Keep
+	}
+	if keep > MaxSnapshotCount {
+		return fmt.Errorf("policy %v keeps %v versions, more than %v", policy.Name, keep, MaxSnapshotCount)
+	}
+	for k := range policy.Labels {
+		if k == "" {
+			return fmt.Errorf("policy %v has a label without key", policy.Name)
+		}
+	}
+	return
+}
+
+// snapshotPolicyVersion is a committed version taken by a snapshot policy.
+type snapshotPolicyVersion struct {
+	ver        uint64
+	frozenTime int64 // unix micro, the version holds the data written before it
+	status     uint8
+	tiers      []string
+}
+
+// policyVersions returns the committed versions taken by the policy, the oldest first.
+// The last entry of the list is the version being written.
+func policyVersions(list []*proto.VolVersionInfo, policyName string) (versions []*snapshotPolicyVersion) {
+	for i := 0; i < len(list)-1; i++ {
+		if list[i].Policy != policyName {
+			continue
+		}
+		versions = append(versions, &snapshotPolicyVersion{
+			ver:        list[i].Ver,
+			frozenTime: int64(list[i+1].Ver),
+			status:     list[i].Status,
+			tiers:      list[i].Tiers,
+		})
+	}
+	return
+}
+
+func (v *snapshotPolicyVersion) inTier(tier string) bool {
+	for _, name := range v.tiers {
+		if name == tier {
+			return true
+		}
+	}
+	return false
+}
+
+// expiredPolicyVersions returns the versions no tier of the policy keeps anymore,
+// a version taken for several tiers expires once it is out of all of them.
+func expiredPolicyVersions(list []*proto.VolVersionInfo, policy *proto.SnapshotPolicy) (expired []uint64) {
+	versions := policyVersions(list, policy.Name)
+	kept := make(map[uint64]bool)
+	for _, tier := range policy.Tiers {
+		cnt := 0
+		for i := len(versions) - 1; i >= 0 && cnt < tier.Keep; i-- {
+			if versions[i].status != proto.VersionNormal || !versions[i].inTier(tier.Name) {
+				continue
+			}
+			kept[versions[i].ver] = true
+			cnt++
+		}
+	}
+	for _, v := range versions {
+		if v.status == proto.VersionNormal && !kept[v.ver] {
+			expired = append(expired, v.ver)
+		}
+	}
+	return
+}
+
+// dueTiers returns the tiers whose next schedule since their last version is not after now.
+// A tier without version is scheduled from the time the policy was updated.
+func dueTiers(list []*proto.VolVersionInfo, policy *proto.SnapshotPolicy, now time.Time) (due []string) {
+	versions := policyVersions(list, policy.Name)
+	for _, tier := range policy.Tiers {
+		sc, err := parseSnapshotCron(tier.Schedule)
+		if err != nil {
+			continue
+		}
+		base := time.Unix(policy.UTime, 0)
+		for i := len(versions) - 1; i >= 0; i-- {
+			if versions[i].status == proto.VersionNormal && versions[i].inTier(tier.Name) {
+				base = time.UnixMicro(versions[i].frozenTime)
+				break
+			}
+		}
+		if next := sc.next(base); !next.IsZero() && !next.After(now) {
+			due = append(due, tier.Name)
+		}
+	}
+	return
+}
+
+type snapshotPolicyVolErr struct {
+	err  string
+	time int64
+}
+
+// snapshotPolicyManager takes and deletes the versions of the volumes attached to
+// the snapshot policies. The policies are persisted by raft, the last errors are
+// only kept in the memory of the leader.
+type snapshotPolicyManager struct {
+	sync.RWMutex
+	cluster  *Cluster
+	policies map[string]*proto.SnapshotPolicy
+	volErrs  map[string]*snapshotPolicyVolErr
+}
+
+func newSnapshotPolicyManager(c *Cluster) *snapshotPolicyManager {
+	return &snapshotPolicyManager{
+		cluster:  c,
+		policies: make(map[string]*proto.SnapshotPolicy),
+		volErrs:  make(map[string]*snapshotPolicyVolErr),
+	}
+}
+
+func copySnapshotPolicy(policy *proto.SnapshotPolicy) *proto.SnapshotPolicy {
+	cp := *policy
+	cp.Tiers = append([]proto.SnapshotPolicyTier(nil), policy.Tiers...)
+	cp.Vols = append([]string(nil), policy.Vols...)
+	if policy.Labels != nil {
+		cp.Labels = make(map[string]string, len(policy.Labels))
+		for k, v := range policy.Labels {
+			cp.Labels[k] = v
+		}
+	}
+	return &cp
+}
+
+func (m *snapshotPolicyManager) putPolicy(policy *proto.SnapshotPolicy) {
+	m.Lock()
+	m.policies[policy.Name] = policy
+	m.Unlock()
+}
+
+func (m *snapshotPolicyManager) reset() {
+	m.Lock()
+	defer m.Unlock()
+	m.policies = make(map[string]*proto.SnapshotPolicy)
+	m.volErrs = make(map[string]*snapshotPolicyVolErr)
+}
+
+func (m *snapshotPolicyManager) createPolicy(policy *proto.SnapshotPolicy) (err error) {
+	if err = checkSnapshotPolicy(policy); err != nil {
+		return
+	}
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.policies[policy.Name]; ok {
+		return fmt.Errorf("snapshot policy %v already exists", policy.Name)
+	}
+	policy = copySnapshotPolicy(policy)
+	policy.Vols = nil
+	policy.UTime = time.Now().Unix()
+	if err = m.cluster.syncPutSnapshotPolicy(policy); err != nil {
+		return
+	}
+	m.policies[policy.Name] = policy
+	log.LogInfof("action[createSnapshotPolicy] policy %v tiers %v", policy.Name, policy.Tiers)
+	return
+}
+
+// updatePolicy replaces the tiers, labels and switch of the policy, the attached volumes are kept.
+func (m *snapshotPolicyManager) updatePolicy(policy *proto.SnapshotPolicy) (err error) {
+	if err = checkSnapshotPolicy(policy); err != nil {
+		return
+	}
+	m.Lock()
+	defer m.Unlock()
+	old, ok := m.policies[policy.Name]
+	if !ok {
+		return fmt.Errorf("snapshot policy %v not found", policy.Name)
+	}
+	policy = copySnapshotPolicy(policy)
+	policy.Vols = old.Vols
+	policy.UTime = time.Now().Unix()
+	if err = m.cluster.syncPutSnapshotPolicy(policy); err != nil {
+		return
+	}
+	m.policies[policy.Name] = policy
+	log.LogInfof("action[updateSnapshotPolicy] policy %v tiers %v enable %v", policy.Name, policy.Tiers, policy.Enable)
+	return
+}
+
+func (m *snapshotPolicyManager) deletePolicy(name string) (err error) {
+	m.Lock()
+	defer m.Unlock()
+	policy, ok := m.policies[name]
+	if !ok {
+		return fmt.Errorf("snapshot policy %v not found", name)
+	}
+	if len(policy.Vols) > 0 {
+		return fmt.Errorf("snapshot policy %v is attached to volumes %v", name, policy.Vols)
+	}
+	if err = m.cluster.syncDeleteSnapshotPolicy(policy); err != nil {
+		return
+	}
+	delete(m.policies, name)
+	log.LogInfof("action[deleteSnapshotPolicy] policy %v", name)
+	return
+}
+
+// policyOfVol returns the name of the policy the volume is attached to.
+func (m *snapshotPolicyManager) policyOfVol(volName string) string {
+	m.RLock()
+	defer m.RUnlock()
+	for _, policy := range m.policies {
+		for _, name := range policy.Vols {
+			if name == volName {
+				return policy.Name
+			}
+		}
+	}
+	return ""
+}
+
+func (m *snapshotPolicyManager) attachVol(policyName, volName string) (err error) {
+	var vol *Vol
+	if vol, err = m.cluster.getVol(volName); err != nil {
+		return
+	}
+	if !proto.IsHot(vol.VolType) {
+		return fmt.Errorf("vol need be hot one")
+	}
+	vol.VersionMgr.RLock()
+	strategyEnabled := vol.VersionMgr.strategy.Enable
+	vol.VersionMgr.RUnlock()
+	if strategyEnabled {
+		return fmt.Errorf("vol %v has the version strategy enabled, disable it first", volName)
+	}
+	if name := m.policyOfVol(volName); name != "" {
+		return fmt.Errorf("vol %v is already attached to snapshot policy %v", volName, name)
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	policy, ok := m.policies[policyName]
+	if !ok {
+		return fmt.Errorf("snapshot policy %v not found", policyName)
+	}
+	policy = copySnapshotPolicy(policy)
+	policy.Vols = append(policy.Vols, volName)
+	sort.Strings(policy.Vols)
+	if err = m.cluster.syncPutSnapshotPolicy(policy); err != nil {
+		return
+	}
+	m.policies[policyName] = policy
+	log.LogInfof("action[attachSnapshotPolicy] policy %v vol %v", policyName, volName)
+	return
+}
+
+func (m *snapshotPolicyManager) detachVol(policyName, volName string) (err error) {
+	m.Lock()
+	defer m.Unlock()
+	policy, ok := m.policies[policyName]
+	if !ok {
+		return fmt.Errorf("snapshot policy %v not found", policyName)
+	}
+	policy = copySnapshotPolicy(policy)
+	vols := policy.Vols[:0]
+	for _, name := range policy.Vols {
+		if name != volName {
+			vols = append(vols, name)
+		}
+	}
+	if len(vols) == len(policy.Vols) {
+		return fmt.Errorf("vol %v is not attached to snapshot policy %v", volName, policyName)
+	}
+	policy.Vols = vols
+	if err = m.cluster.syncPutSnapshotPolicy(policy); err != nil {
+		return
+	}
+	m.policies[policyName] = policy
+	delete(m.volErrs, volName)
+	log.LogInfof("action[detachSnapshotPolicy] policy %v vol %v", policyName, volName)
+	return
+}
+
+func (m *snapshotPolicyManager) listPolicies() (policies []*proto.SnapshotPolicy) {
+	m.RLock()
+	defer m.RUnlock()
+	policies = make([]*proto.SnapshotPolicy, 0, len(m.policies))
+	for _, policy := range m.policies {
+		policies = append(policies, copySnapshotPolicy(policy))
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+	return
+}
+
+func (m *snapshotPolicyManager) getStatus(policyName string) (status *proto.SnapshotPolicyStatus, err error) {
+	m.RLock()
+	policy, ok := m.policies[policyName]
+	if ok {
+		policy = copySnapshotPolicy(policy)
+	}
+	m.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("snapshot policy %v not found", policyName)
+	}
+
+	status = &proto.SnapshotPolicyStatus{Policy: policy}
+	for _, volName := range policy.Vols {
+		volStatus := &proto.SnapshotPolicyVolStatus{VolName: volName}
+		status.Vols = append(status.Vols, volStatus)
+		m.RLock()
+		if volErr, ok := m.volErrs[volName]; ok {
+			volStatus.LastErr, volStatus.LastErrTime = volErr.err, volErr.time
+		}
+		m.RUnlock()
+
+		vol, err := m.cluster.getVol(volName)
+		if err != nil {
+			volStatus.LastErr = err.Error()
+			continue
+		}
+		versions := policyVersions(vol.VersionMgr.getVersionList().VerList, policy.Name)
+		for _, tier := range policy.Tiers {
+			tierStatus := proto.SnapshotPolicyTierStatus{Name: tier.Name}
+			base := time.Unix(policy.UTime, 0)
+			for _, v := range versions {
+				if v.status != proto.VersionNormal || !v.inTier(tier.Name) {
+					continue
+				}
+				tierStatus.Versions++
+				tierStatus.LastTime = v.frozenTime / 1e6
+				base = time.UnixMicro(v.frozenTime)
+			}
+			if sc, err := parseSnapshotCron(tier.Schedule); err == nil {
+				if next := sc.next(base); !next.IsZero() {
+					tierStatus.NextTime = next.Unix()
+				}
+			}
+			volStatus.Tiers = append(volStatus.Tiers, tierStatus)
+		}
+	}
+	return
+}
+
+func (m *snapshotPolicyManager) setVolErr(volName string, err error) {
+	m.Lock()
+	defer m.Unlock()
+	if err == nil {
+		delete(m.volErrs, volName)
+		return
+	}
+	m.volErrs[volName] = &snapshotPolicyVolErr{err: err.Error(), time: time.Now().Unix()}
+}
+
+func (m *snapshotPolicyManager) process() {
+	for _, policy := range m.listPolicies() {
+		if !policy.Enable {
+			continue
+		}
+		for _, volName := range policy.Vols {
+			vol, err := m.cluster.getVol(volName)
+			if err != nil || vol.Status == proto.VolStatusMarkDelete {
+				continue
+			}
+			err = m.processVol(vol, policy)
+			m.setVolErr(volName, err)
+			if err != nil {
+				log.LogWarnf("action[snapshotPolicy] policy %v vol %v err %v", policy.Name, volName, err)
+			}
+		}
+	}
+}
+
+// processVol takes a version of the volume when any tier is due, labeled with the due tiers,
+// otherwise deletes the versions of the policy out of the retention of all their tiers.
+func (m *snapshotPolicyManager) processVol(vol *Vol, policy *proto.SnapshotPolicy) (err error) {
+	verMgr := vol.VersionMgr
+	now := time.Now()
+	if tiers := dueTiers(verMgr.getVersionList().VerList, policy, now); len(tiers) > 0 {
+		log.LogInfof("action[snapshotPolicy] policy %v vol %v create version for tiers %v", policy.Name, vol.Name, tiers)
+		var verRsp *proto.VolVersionInfo
+		if verRsp, err = verMgr.createVer2PhaseTask(m.cluster, uint64(now.UnixMicro()), proto.CreateVersion, false); err != nil {
+			return fmt.Errorf("create version for tiers %v: %v", tiers, err)
+		}
+		if verRsp == nil {
+			return fmt.Errorf("create version for tiers %v: no version committed", tiers)
+		}
+		// the commit of the new version is still on the way, the retention waits for the next round
+		return verMgr.setVerPolicy(verRsp.Ver, policy.Name, tiers, policy.Labels)
+	}
+
+	for _, ver := range expiredPolicyVersions(verMgr.getVersionList().VerList, policy) {
+		log.LogInfof("action[snapshotPolicy] policy %v vol %v delete expired version %v", policy.Name, vol.Name, ver)
+		if _, err = verMgr.createVer2PhaseTask(m.cluster, ver, proto.DeleteVersion, false); err != nil {
+			return fmt.Errorf("delete expired version %v: %v", ver, err)
+		}
+	}
+	return
+}
+
+// setVerPolicy labels the committed version with the policy and tiers which took it.
+func (verMgr *VolVersionManager) setVerPolicy(verSeq uint64, policy string, tiers []string, labels map[string]string) (err error) {
+	verMgr.Lock()
+	defer verMgr.Unlock()
+	idx, found := verMgr.getLayInfo(verSeq)
+	if !found {
+		return fmt.Errorf("version %v not found", verSeq)
+	}
+	ver := verMgr.multiVersionList[idx]
+	ver.Policy = policy
+	ver.Tiers = tiers
+	if len(labels) > 0 {
+		ver.Labels = make(map[string]string, len(labels))
+		for k, v := range labels {
+			ver.Labels[k] = v
+		}
+	}
+	return verMgr.Persist()
+}
+
+func (c *Cluster) scheduleToSnapshotPolicy() {
+	go func() {
+		ticker := time.NewTicker(snapshotPolicyCheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if c.partition != nil && c.partition.IsRaftLeader() {
+				c.snapshotPolicyMgr.process()
+			}
+		}
+	}()
+}
+
+func (c *Cluster) syncPutSnapshotPolicy(policy *proto.SnapshotPolicy) (err error) {
+	return c.syncSnapshotPolicy(opSyncPutSnapshotPolicy, policy)
+}
+
+func (c *Cluster) syncDeleteSnapshotPolicy(policy *proto.SnapshotPolicy) (err error) {
+	return c.syncSnapshotPolicy(opSyncDeleteSnapshotPolicy, policy)
+}
+
+func (c *Cluster) syncSnapshotPolicy(opType uint32, policy *proto.SnapshotPolicy) (err error) {
+	metadata := new(RaftCmd)
+	metadata.Op = opType
+	metadata.K = snapshotPolicyPrefix + policy.Name
+	if metadata.V, err = json.Marshal(policy); err != nil {
+		return errors.New(err.Error())
+	}
+	return c.submit(metadata)
+}
+
+func (c *Cluster) loadSnapshotPolicies() (err error) {
+	result, err := c.fsm.store.SeekForPrefix([]byte(snapshotPolicyPrefix))
+	if err != nil {
+		return fmt.Errorf("action[loadSnapshotPolicies],err:%v", err.Error())
+	}
+	c.snapshotPolicyMgr.reset()
+	for _, value := range result {
+		policy := &proto.SnapshotPolicy{}
+		if err = json.Unmarshal(value, policy); err != nil {
+			return fmt.Errorf("action[loadSnapshotPolicies],value:%v,unmarshal err:%v", string(value), err)
+		}
+		c.snapshotPolicyMgr.putPolicy(policy)
+		log.LogInfof("action[loadSnapshotPolicies],policy[%v] vols %v", policy.Name, policy.Vols)
+	}
+	return
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func TestSnapshotCronNext(t *testing.T) {
+	// 2023-03-15 is a wednesday
+	base := time.Date(2023, 3, 15, 10, 30, 20, 0, time.UTC)
+	cases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"@hourly", time.Date(2023, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2023, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2023, 3, 19, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2023, 3, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 2-4 * * *", time.Date(2023, 3, 16, 2, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 10 * * 7", time.Date(2023, 3, 19, 10, 30, 0, 0, time.UTC)},
+		{"0 12 1,20 * 3", time.Date(2023, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		sc, err := parseSnapshotCron(c.spec)
+		if err != nil {
+			t.Errorf("parse %q err %v", c.spec, err)
+			continue
+		}
+		if next := sc.next(base); !next.Equal(c.next) {
+			t.Errorf("schedule %q next %v, expect %v", c.spec, next, c.next)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := parseSnapshotCron(spec); err == nil {
+			t.Errorf("schedule %q should be invalid", spec)
+		}
+	}
+}
+
+func TestCheckSnapshotPolicy(t *testing.T) {
+	policy := &proto.SnapshotPolicy{
+		Name: "backup",
+		Tiers: []proto.SnapshotPolicyTier{
+			{Name: "hourly", Schedule: "@hourly", Keep: 24},
+			{Name: "daily", Schedule: "@daily", Keep: 6},
+		},
+	}
+	if err := checkSnapshotPolicy(policy); err != nil {
+		t.Errorf("check policy err %v", err)
+	}
+	policy.Tiers[1].Keep = 7
+	if err := checkSnapshotPolicy(policy); err == nil {
+		t.Errorf("policy keeping %v versions should be rejected", 31)
+	}
+	policy.Tiers[1] = proto.SnapshotPolicyTier{Name: "hourly", Schedule: "@daily", Keep: 1}
+	if err := checkSnapshotPolicy(policy); err == nil {
+		t.Errorf("policy with duplicated tiers should be rejected")
+	}
+}
+
+func TestSnapshotPolicyRetention(t *testing.T) {
+	policy := &proto.SnapshotPolicy{
+		Name: "backup",
+		Tiers: []proto.SnapshotPolicyTier{
+			{Name: "hourly", Schedule: "@hourly", Keep: 2},
+			{Name: "daily", Schedule: "@daily", Keep: 1},
+		},
+		UTime: time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC).Unix(),
+	}
+	hour := uint64(time.Hour / time.Microsecond)
+	start := uint64(time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC).UnixMicro())
+	list := []*proto.VolVersionInfo{
+		{Ver: 0, Status: proto.VersionNormal},
+		{Ver: start + hour, Status: proto.VersionNormal, Policy: "backup", Tiers: []string{"hourly", "daily"}},
+		{Ver: start + 2*hour, Status: proto.VersionNormal, Policy: "backup", Tiers: []string{"hourly"}},
+		{Ver: start + 3*hour, Status: proto.VersionNormal, Policy: "other", Tiers: []string{"hourly"}},
+		{Ver: start + 4*hour, Status: proto.VersionNormal, Policy: "backup", Tiers: []string{"hourly"}},
+		{Ver: start + 5*hour, Status: proto.VersionNormal, Policy: "backup", Tiers: []string{"hourly"}},
+		{Ver: start + 6*hour, Status: proto.VersionNormal},
+	}
+
+	// the oldest one is still kept by the daily tier
+	expired := expiredPolicyVersions(list, policy)
+	if len(expired) != 1 || expired[0] != start+2*hour {
+		t.Errorf("expired %v, expect [%v]", expired, start+2*hour)
+	}
+
+	// the versions are frozen when the next one is created, 6 hours after the start for the newest one
+	now := time.UnixMicro(int64(start + 6*hour + 10*uint64(time.Minute/time.Microsecond)))
+	if due := dueTiers(list, policy, now); len(due) != 0 {
+		t.Errorf("due tiers %v, expect none", due)
+	}
+	now = time.UnixMicro(int64(start + 7*hour))
+	if due := dueTiers(list, policy, now); len(due) != 1 || due[0] != "hourly" {
+		t.Errorf("due tiers %v, expect [hourly]", due)
+	}
+	now = time.UnixMicro(int64(start + 24*hour))
+	if due := dueTiers(list, policy, now); len(due) != 2 {
+		t.Errorf("due tiers %v, expect [hourly daily]", due)
+	}
+}
@@ -0,0 +1,482 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	tenantQosAllocInterval = 2 * time.Second
+	// the reports of an object node expire if it misses 3 periods
+	objectNodeQosReportExpire = 3 * proto.DefaultObjectNodeQosReportPeriod * time.Second
+	// floor of a limit, so that the clients or object nodes without demand can still start
+	minTenantQosFlow = 1 * util.MB
+)
+
+// allocateByWeight divides the total among demands by weight. A demand below its weighted share
+// only gets what it needs and the rest is lent to the others, then what is left when every demand
+// is met is shared by weight as headroom, so the assignments always sum up to the total.
+func allocateByWeight(total uint64, demands, weights []uint64) []uint64 {
+	assigned := make([]uint64, len(demands))
+	active := make([]int, 0, len(demands))
+	for i := range demands {
+		if demands[i] > 0 {
+			active = append(active, i)
+		}
+	}
+	left := total
+	for len(active) > 0 && left > 0 {
+		var sumWeight uint64
+		for _, i := range active {
+			sumWeight += weights[i]
+		}
+		var given uint64
+		next := active[:0]
+		for _, i := range active {
+			share := uint64(float64(left) * float64(weights[i]) / float64(sumWeight))
+			if want := demands[i] - assigned[i]; share >= want {
+				share = want
+			} else {
+				next = append(next, i)
+			}
+			assigned[i] += share
+			given += share
+		}
+		left -= given
+		active = next
+		if given == 0 {
+			break
+		}
+	}
+	if left > 0 && len(demands) > 0 {
+		var sumWeight uint64
+		for _, w := range weights {
+			sumWeight += w
+		}
+		for i := range assigned {
+			assigned[i] += uint64(float64(left) * float64(weights[i]) / float64(sumWeight))
+		}
+	}
+	return assigned
+}
+
+// splitByDemand divides the total in proportion to the demands if they exceed it,
+// otherwise every demand is met and the rest is shared evenly.
+func splitByDemand(total uint64, demands []uint64) []uint64 {
+	res := make([]uint64, len(demands))
+	if len(demands) == 0 {
+		return res
+	}
+	var sum uint64
+	for _, d := range demands {
+		sum += d
+	}
+	if sum > total {
+		for i, d := range demands {
+			res[i] = uint64(float64(total) * float64(d) / float64(sum))
+		}
+		return res
+	}
+	extra := (total - sum) / uint64(len(demands))
+	for i, d := range demands {
+		res[i] = d + extra
+	}
+	return res
+}
+
+func atLeastMinFlow(limit uint64) uint64 {
+	if limit < minTenantQosFlow {
+		return minTenantQosFlow
+	}
+	return limit
+}
+
+type objectNodeQosReport struct {
+	report *proto.ObjectNodeQosReport
+	time   time.Time
+}
+
+// tenantQosManager divides the flow budget of the tenants among their volumes, and the share of
+// a volume between its FUSE clients, limited by the vol qos manager, and the object nodes.
+type tenantQosManager struct {
+	c *Cluster
+	sync.RWMutex
+	tenants     map[string]*proto.TenantQos
+	reports     map[string]*objectNodeQosReport // object node addr -> last report
+	status      map[string]*proto.TenantQosStatus
+	nodeLimits  map[string]map[string]*proto.ObjectNodeVolLimit // object node addr -> vol -> limit
+	volLimits   map[string]*proto.ObjectNodeVolLimit            // vol -> limit of the object nodes without report
+	limitedVols map[string]bool                                 // vols capped by a tenant share at the last allocation
+}
+
+func newTenantQosManager(c *Cluster) *tenantQosManager {
+	return &tenantQosManager{
+		c:           c,
+		tenants:     make(map[string]*proto.TenantQos),
+		reports:     make(map[string]*objectNodeQosReport),
+		status:      make(map[string]*proto.TenantQosStatus),
+		nodeLimits:  make(map[string]map[string]*proto.ObjectNodeVolLimit),
+		volLimits:   make(map[string]*proto.ObjectNodeVolLimit),
+		limitedVols: make(map[string]bool),
+	}
+}
+
+func checkTenantQos(q *proto.TenantQos) error {
+	if q.Tenant == "" {
+		return fmt.Errorf("tenant is empty")
+	}
+	for vol, w := range q.VolWeights {
+		if w == 0 {
+			return fmt.Errorf("weight of vol %v is 0", vol)
+		}
+	}
+	return nil
+}
+
+func (mgr *tenantQosManager) putTenant(q *proto.TenantQos) {
+	mgr.Lock()
+	defer mgr.Unlock()
+	mgr.tenants[q.Tenant] = q
+}
+
+// reset drops the tenants and their status, the reports of the object nodes are kept.
+func (mgr *tenantQosManager) reset() {
+	mgr.Lock()
+	defer mgr.Unlock()
+	mgr.tenants = make(map[string]*proto.TenantQos)
+	mgr.status = make(map[string]*proto.TenantQosStatus)
+}
+
+func (mgr *tenantQosManager) setTenant(q *proto.TenantQos) (err error) {
+	if err = checkTenantQos(q); err != nil {
+		return
+	}
+	if err = mgr.c.syncPutTenantQos(q); err != nil {
+		return
+	}
+	mgr.putTenant(q)
+	log.LogInfof("action[setTenantQos] tenant[%v] flowRLimit[%v] flowWLimit[%v] weights[%v]",
+		q.Tenant, q.FlowRLimit, q.FlowWLimit, q.VolWeights)
+	return
+}
+
+func (mgr *tenantQosManager) deleteTenant(tenant string) (err error) {
+	mgr.RLock()
+	q, ok := mgr.tenants[tenant]
+	mgr.RUnlock()
+	if !ok {
+		return fmt.Errorf("tenant qos of %v not found", tenant)
+	}
+	if err = mgr.c.syncDeleteTenantQos(q); err != nil {
+		return
+	}
+	mgr.Lock()
+	delete(mgr.tenants, tenant)
+	delete(mgr.status, tenant)
+	mgr.Unlock()
+	log.LogInfof("action[deleteTenantQos] tenant[%v]", tenant)
+	return
+}
+
+func (mgr *tenantQosManager) getStatus(tenant string) (status []*proto.TenantQosStatus, err error) {
+	mgr.RLock()
+	defer mgr.RUnlock()
+	if tenant != "" {
+		if _, ok := mgr.tenants[tenant]; !ok {
+			return nil, fmt.Errorf("tenant qos of %v not found", tenant)
+		}
+	}
+	status = make([]*proto.TenantQosStatus, 0, len(mgr.tenants))
+	for name, q := range mgr.tenants {
+		if tenant != "" && name != tenant {
+			continue
+		}
+		if st, ok := mgr.status[name]; ok {
+			status = append(status, st)
+			continue
+		}
+		// not allocated yet
+		status = append(status, &proto.TenantQosStatus{Tenant: name, FlowRLimit: q.FlowRLimit, FlowWLimit: q.FlowWLimit,
+			Vols: make([]*proto.TenantVolQosStatus, 0)})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Tenant < status[j].Tenant })
+	return
+}
+
+// handleObjectNodeReport keeps the flow reported by an object node, and returns its limits of the volumes under tenant qos.
+func (mgr *tenantQosManager) handleObjectNodeReport(report *proto.ObjectNodeQosReport) *proto.ObjectNodeQosLimit {
+	mgr.Lock()
+	defer mgr.Unlock()
+	mgr.reports[report.Addr] = &objectNodeQosReport{report: report, time: time.Now()}
+	limit := &proto.ObjectNodeQosLimit{
+		Vols:         make(map[string]*proto.ObjectNodeVolLimit, len(mgr.volLimits)),
+		ReportPeriod: proto.DefaultObjectNodeQosReportPeriod,
+	}
+	nodeLimits := mgr.nodeLimits[report.Addr]
+	for vol, volLimit := range mgr.volLimits {
+		if l, ok := nodeLimits[vol]; ok {
+			limit.Vols[vol] = l
+		} else {
+			limit.Vols[vol] = volLimit
+		}
+	}
+	return limit
+}
+
+// objectNodeDemands returns the demand of every object node of the vols, dropping the expired reports.
+func (mgr *tenantQosManager) objectNodeDemands(now time.Time) map[string]map[string]*proto.ObjectNodeVolFlow {
+	mgr.Lock()
+	defer mgr.Unlock()
+	demands := make(map[string]map[string]*proto.ObjectNodeVolFlow)
+	for addr, r := range mgr.reports {
+		if now.Sub(r.time) > objectNodeQosReportExpire {
+			delete(mgr.reports, addr)
+			continue
+		}
+		for vol, flow := range r.report.Vols {
+			if demands[vol] == nil {
+				demands[vol] = make(map[string]*proto.ObjectNodeVolFlow)
+			}
+			demands[vol][addr] = flow
+		}
+	}
+	return demands
+}
+
+type tenantVolDemand struct {
+	vol             *Vol
+	weight          uint64
+	clientDemand    uint64
+	nodeAddrs       []string
+	nodeDemands     []uint64
+	objectNodeTotal uint64
+}
+
+// allocateTenantFlow assigns a flow type of the tenant budget to the vols, it returns the client limit of every vol,
+// and fills the status and the object node limits.
+func allocateTenantFlow(limit uint64, demands []*tenantVolDemand, flows []*proto.TenantQosFlow,
+	nodeLimits map[string]map[string]uint64, volLimits map[string]uint64) (clientLimits []uint64) {
+	clientLimits = make([]uint64, len(demands))
+	totals := make([]uint64, len(demands))
+	weights := make([]uint64, len(demands))
+	for i, d := range demands {
+		totals[i] = d.clientDemand + d.objectNodeTotal
+		weights[i] = d.weight
+		flows[i].ClientDemand = d.clientDemand
+		flows[i].ObjectNodeDemand = d.objectNodeTotal
+	}
+	if limit == 0 {
+		return
+	}
+	assigned := allocateByWeight(limit, totals, weights)
+	for i, d := range demands {
+		flows[i].Assigned = assigned[i]
+		split := splitByDemand(assigned[i], []uint64{d.clientDemand, d.objectNodeTotal})
+		clientLimits[i] = atLeastMinFlow(split[0])
+		flows[i].ClientLimit = clientLimits[i]
+		flows[i].ObjectNodeLimit = atLeastMinFlow(split[1])
+
+		volName := d.vol.Name
+		nodeShares := splitByDemand(flows[i].ObjectNodeLimit, d.nodeDemands)
+		for j, addr := range d.nodeAddrs {
+			if nodeLimits[addr] == nil {
+				nodeLimits[addr] = make(map[string]uint64)
+			}
+			nodeLimits[addr][volName] = atLeastMinFlow(nodeShares[j])
+		}
+		// a node without report gets an even part
+		volLimits[volName] = atLeastMinFlow(flows[i].ObjectNodeLimit / uint64(len(d.nodeAddrs)+1))
+	}
+	return
+}
+
+func (mgr *tenantQosManager) allocate() {
+	now := time.Now()
+	nodeDemands := mgr.objectNodeDemands(now)
+
+	mgr.RLock()
+	tenants := make([]*proto.TenantQos, 0, len(mgr.tenants))
+	for _, q := range mgr.tenants {
+		tenants = append(tenants, q)
+	}
+	mgr.RUnlock()
+
+	volsOfTenant := make(map[string][]*Vol)
+	for _, vol := range mgr.c.copyVols() {
+		if vol.Status == proto.VolStatusMarkDelete {
+			continue
+		}
+		volsOfTenant[vol.Owner] = append(volsOfTenant[vol.Owner], vol)
+	}
+
+	status := make(map[string]*proto.TenantQosStatus, len(tenants))
+	readNodeLimits := make(map[string]map[string]uint64)
+	writeNodeLimits := make(map[string]map[string]uint64)
+	readVolLimits := make(map[string]uint64)
+	writeVolLimits := make(map[string]uint64)
+	limitedVols := make(map[string]bool)
+	for _, q := range tenants {
+		vols := volsOfTenant[q.Tenant]
+		sort.Slice(vols, func(i, j int) bool { return vols[i].Name < vols[j].Name })
+		st := &proto.TenantQosStatus{
+			Tenant:     q.Tenant,
+			FlowRLimit: q.FlowRLimit,
+			FlowWLimit: q.FlowWLimit,
+			Vols:       make([]*proto.TenantVolQosStatus, 0, len(vols)),
+			UpdateTime: now.Unix(),
+		}
+		readDemands := make([]*tenantVolDemand, 0, len(vols))
+		writeDemands := make([]*tenantVolDemand, 0, len(vols))
+		readFlows := make([]*proto.TenantQosFlow, 0, len(vols))
+		writeFlows := make([]*proto.TenantQosFlow, 0, len(vols))
+		for _, vol := range vols {
+			volSt := &proto.TenantVolQosStatus{Vol: vol.Name, Weight: q.VolWeight(vol.Name)}
+			st.Vols = append(st.Vols, volSt)
+			read := &tenantVolDemand{vol: vol, weight: volSt.Weight, clientDemand: vol.qosManager.clientDemand(proto.FlowReadType)}
+			write := &tenantVolDemand{vol: vol, weight: volSt.Weight, clientDemand: vol.qosManager.clientDemand(proto.FlowWriteType)}
+			addrs := make([]string, 0, len(nodeDemands[vol.Name]))
+			for addr := range nodeDemands[vol.Name] {
+				addrs = append(addrs, addr)
+			}
+			sort.Strings(addrs)
+			for _, addr := range addrs {
+				flow := nodeDemands[vol.Name][addr]
+				read.nodeAddrs = append(read.nodeAddrs, addr)
+				read.nodeDemands = append(read.nodeDemands, flow.ReadUsed+flow.ReadNeed)
+				read.objectNodeTotal += flow.ReadUsed + flow.ReadNeed
+				write.nodeAddrs = append(write.nodeAddrs, addr)
+				write.nodeDemands = append(write.nodeDemands, flow.WriteUsed+flow.WriteNeed)
+				write.objectNodeTotal += flow.WriteUsed + flow.WriteNeed
+			}
+			readDemands = append(readDemands, read)
+			writeDemands = append(writeDemands, write)
+			readFlows = append(readFlows, &volSt.Read)
+			writeFlows = append(writeFlows, &volSt.Write)
+		}
+		readLimits := allocateTenantFlow(q.FlowRLimit, readDemands, readFlows, readNodeLimits, readVolLimits)
+		writeLimits := allocateTenantFlow(q.FlowWLimit, writeDemands, writeFlows, writeNodeLimits, writeVolLimits)
+		for i, vol := range vols {
+			vol.qosManager.setTenantLimit(readLimits[i], writeLimits[i])
+			limitedVols[vol.Name] = readLimits[i] > 0 || writeLimits[i] > 0
+			addTenantQosFlow(&st.Read, readFlows[i])
+			addTenantQosFlow(&st.Write, writeFlows[i])
+		}
+		status[q.Tenant] = st
+	}
+
+	mgr.Lock()
+	defer mgr.Unlock()
+	for volName := range mgr.limitedVols {
+		if limitedVols[volName] {
+			continue
+		}
+		// the tenant qos is deleted or the vol changed of owner
+		if vol, err := mgr.c.getVol(volName); err == nil {
+			vol.qosManager.setTenantLimit(0, 0)
+		}
+	}
+	mgr.limitedVols = limitedVols
+	mgr.status = status
+	mgr.nodeLimits = make(map[string]map[string]*proto.ObjectNodeVolLimit)
+	mgr.volLimits = make(map[string]*proto.ObjectNodeVolLimit)
+	for volName, limited := range limitedVols {
+		if !limited {
+			continue
+		}
+		mgr.volLimits[volName] = &proto.ObjectNodeVolLimit{FlowRLimit: readVolLimits[volName], FlowWLimit: writeVolLimits[volName]}
+	}
+	for addr, vols := range readNodeLimits {
+		mgr.nodeLimits[addr] = make(map[string]*proto.ObjectNodeVolLimit, len(vols))
+		for volName, l := range vols {
+			mgr.nodeLimits[addr][volName] = &proto.ObjectNodeVolLimit{FlowRLimit: l}
+		}
+	}
+	for addr, vols := range writeNodeLimits {
+		if mgr.nodeLimits[addr] == nil {
+			mgr.nodeLimits[addr] = make(map[string]*proto.ObjectNodeVolLimit, len(vols))
+		}
+		for volName, l := range vols {
+			if mgr.nodeLimits[addr][volName] == nil {
+				mgr.nodeLimits[addr][volName] = &proto.ObjectNodeVolLimit{}
+			}
+			mgr.nodeLimits[addr][volName].FlowWLimit = l
+		}
+	}
+}
+
+func addTenantQosFlow(sum, flow *proto.TenantQosFlow) {
+	sum.ClientDemand += flow.ClientDemand
+	sum.ObjectNodeDemand += flow.ObjectNodeDemand
+	sum.Assigned += flow.Assigned
+	sum.ClientLimit += flow.ClientLimit
+	sum.ObjectNodeLimit += flow.ObjectNodeLimit
+}
+
+func (c *Cluster) scheduleToAllocTenantQos() {
+	go func() {
+		ticker := time.NewTicker(tenantQosAllocInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if c.partition != nil && c.partition.IsRaftLeader() {
+				c.tenantQosMgr.allocate()
+			}
+		}
+	}()
+}
+
+func (c *Cluster) syncPutTenantQos(q *proto.TenantQos) (err error) {
+	return c.syncTenantQos(opSyncPutTenantQos, q)
+}
+
+func (c *Cluster) syncDeleteTenantQos(q *proto.TenantQos) (err error) {
+	return c.syncTenantQos(opSyncDeleteTenantQos, q)
+}
+
+func (c *Cluster) syncTenantQos(opType uint32, q *proto.TenantQos) (err error) {
+	metadata := new(RaftCmd)
+	metadata.Op = opType
+	metadata.K = tenantQosPrefix + q.Tenant
+	if metadata.V, err = json.Marshal(q); err != nil {
+		return errors.New(err.Error())
+	}
+	return c.submit(metadata)
+}
+
+func (c *Cluster) loadTenantQos() (err error) {
+	result, err := c.fsm.store.SeekForPrefix([]byte(tenantQosPrefix))
+	if err != nil {
+		return fmt.Errorf("action[loadTenantQos],err:%v", err.Error())
+	}
+	c.tenantQosMgr.reset()
+	for _, value := range result {
+		q := &proto.TenantQos{}
+		if err = json.Unmarshal(value, q); err != nil {
+			return fmt.Errorf("action[loadTenantQos],value:%v,unmarshal err:%v", string(value), err)
+		}
+		c.tenantQosMgr.putTenant(q)
+		log.LogInfof("action[loadTenantQos],tenant[%v] flowRLimit[%v] flowWLimit[%v]", q.Tenant, q.FlowRLimit, q.FlowWLimit)
+	}
+	return
+}
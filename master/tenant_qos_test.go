@@ -0,0 +1,103 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+)
+
+func TestAllocateByWeight(t *testing.T) {
+	cases := []struct {
+		name    string
+		total   uint64
+		demands []uint64
+		weights []uint64
+		expect  []uint64
+	}{
+		{"even", 300, []uint64{1000, 1000, 1000}, []uint64{1, 1, 1}, []uint64{100, 100, 100}},
+		{"weighted", 400, []uint64{1000, 1000}, []uint64{3, 1}, []uint64{300, 100}},
+		{"lend", 300, []uint64{50, 1000, 1000}, []uint64{1, 1, 1}, []uint64{50, 125, 125}},
+		{"headroom", 400, []uint64{100, 100}, []uint64{1, 1}, []uint64{200, 200}},
+		{"idle", 200, []uint64{0, 100}, []uint64{1, 1}, []uint64{50, 150}},
+		{"none", 100, []uint64{}, []uint64{}, []uint64{}},
+	}
+	for _, c := range cases {
+		got := allocateByWeight(c.total, c.demands, c.weights)
+		if !reflect.DeepEqual(got, c.expect) {
+			t.Errorf("case %v: expect %v, got %v", c.name, c.expect, got)
+		}
+	}
+}
+
+func TestSplitByDemand(t *testing.T) {
+	if got := splitByDemand(100, []uint64{300, 100}); !reflect.DeepEqual(got, []uint64{75, 25}) {
+		t.Errorf("oversubscribed: got %v", got)
+	}
+	if got := splitByDemand(100, []uint64{20, 40}); !reflect.DeepEqual(got, []uint64{40, 60}) {
+		t.Errorf("undersubscribed: got %v", got)
+	}
+	if got := splitByDemand(100, nil); len(got) != 0 {
+		t.Errorf("empty: got %v", got)
+	}
+}
+
+func TestAllocateTenantFlow(t *testing.T) {
+	demands := []*tenantVolDemand{
+		{
+			vol:             &Vol{Name: "vol1"},
+			weight:          1,
+			clientDemand:    60 * util.MB,
+			nodeAddrs:       []string{"node1", "node2"},
+			nodeDemands:     []uint64{30 * util.MB, 10 * util.MB},
+			objectNodeTotal: 40 * util.MB,
+		},
+		{
+			vol:          &Vol{Name: "vol2"},
+			weight:       1,
+			clientDemand: 200 * util.MB,
+		},
+	}
+	flows := []*proto.TenantQosFlow{{}, {}}
+	nodeLimits := make(map[string]map[string]uint64)
+	volLimits := make(map[string]uint64)
+	clientLimits := allocateTenantFlow(200*util.MB, demands, flows, nodeLimits, volLimits)
+
+	if flows[0].Assigned != 100*util.MB || flows[1].Assigned != 100*util.MB {
+		t.Errorf("assigned: expect 100MB each, got %v %v", flows[0].Assigned, flows[1].Assigned)
+	}
+	if clientLimits[0] != 60*util.MB || clientLimits[1] != 100*util.MB {
+		t.Errorf("client limits: got %v", clientLimits)
+	}
+	if flows[0].ObjectNodeLimit != 40*util.MB || flows[1].ObjectNodeLimit != minTenantQosFlow {
+		t.Errorf("object node limits: got %v %v", flows[0].ObjectNodeLimit, flows[1].ObjectNodeLimit)
+	}
+	if nodeLimits["node1"]["vol1"] != 30*util.MB || nodeLimits["node2"]["vol1"] != 10*util.MB {
+		t.Errorf("node limits: got %v", nodeLimits)
+	}
+	if volLimits["vol1"] != 40*util.MB/3 || volLimits["vol2"] != minTenantQosFlow {
+		t.Errorf("vol limits: got %v", volLimits)
+	}
+
+	// no limit, only the demands are filled
+	flows = []*proto.TenantQosFlow{{}, {}}
+	clientLimits = allocateTenantFlow(0, demands, flows, nodeLimits, volLimits)
+	if clientLimits[0] != 0 || flows[0].ClientDemand != 60*util.MB || flows[0].ObjectNodeDemand != 40*util.MB {
+		t.Errorf("unlimited: got %v %+v", clientLimits, flows[0])
+	}
+}
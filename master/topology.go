@@ -956,6 +956,7 @@ type nodeSet struct {
 	startDecommissionDiskListTraverse chan struct{}
 	DecommissionDisks                 sync.Map
 	diskParallelFactorLk              sync.Mutex
+	placementDomain                   string
 }
 
 type nodeSetDecommissionParallelStatus struct {
@@ -980,6 +981,7 @@ func newNodeSet(c *Cluster, id uint64, cap int, zoneName string) *nodeSet {
 		startDecommissionDiskListTraverse: make(chan struct{}, 1),
 		dataNodeSelector:                  NewNodeSelector(DefaultNodeSelectorName, DataNodeType),
 		metaNodeSelector:                  NewNodeSelector(DefaultNodeSelectorName, MetaNodeType),
+		placementDomain:                   c.cfg.PlacementDomain,
 	}
 	go ns.traverseDecommissionDisk(c)
 	return ns
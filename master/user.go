@@ -80,6 +80,10 @@ func (u *User) createKey(param *proto.UserCreateParam) (userInfo *proto.UserInfo
 	}
 	userType := param.Type
 	description := param.Description
+	if param.Role != "" && !proto.IsValidAdminRole(param.Role) {
+		err = proto.ErrInvalidAdminRole
+		return
+	}
 	u.userStoreMutex.Lock()
 	defer u.userStoreMutex.Unlock()
 	u.AKStoreMutex.Lock()
@@ -98,6 +102,7 @@ func (u *User) createKey(param *proto.UserCreateParam) (userInfo *proto.UserInfo
 	userInfo = &proto.UserInfo{
 		UserID: userID, AccessKey: accessKey, SecretKey: secretKey, Policy: userPolicy,
 		UserType: userType, CreateTime: time.Unix(time.Now().Unix(), 0).Format(proto.TimeFormat), Description: description,
+		Role: param.Role,
 	}
 	AKUser = &proto.AKUser{AccessKey: accessKey, UserID: userID, Password: encodingPassword(password)}
 	if err = u.syncAddUserInfo(userInfo); err != nil {
@@ -180,7 +185,7 @@ func (u *User) updateKey(param *proto.UserUpdateParam) (userInfo *proto.UserInfo
 		return
 	}
 	formerAK := userInfo.AccessKey
-	var akMark, skMark, typeMark, describeMark int
+	var akMark, skMark, typeMark, describeMark, roleMark int
 	if param.AccessKey != "" {
 		if !proto.IsValidAK(param.AccessKey) {
 			err = proto.ErrInvalidAccessKey
@@ -211,6 +216,13 @@ func (u *User) updateKey(param *proto.UserUpdateParam) (userInfo *proto.UserInfo
 	if param.Description != "" {
 		describeMark = 1
 	}
+	if param.Role != "" {
+		if !proto.IsValidAdminRole(param.Role) {
+			err = proto.ErrInvalidAdminRole
+			return
+		}
+		roleMark = 1
+	}
 
 	var akUserBef *proto.AKUser
 	var akUserAft *proto.AKUser
@@ -233,6 +245,9 @@ func (u *User) updateKey(param *proto.UserUpdateParam) (userInfo *proto.UserInfo
 	if describeMark == 1 {
 		userInfo.Description = param.Description
 	}
+	if roleMark == 1 {
+		userInfo.Role = param.Role
+	}
 
 	if len(strings.TrimSpace(param.Password)) != 0 {
 		akUserBef.Password = encodingPassword(param.Password)
@@ -43,6 +43,7 @@ type VolVarargs struct {
 	domainId                uint64
 	dpReplicaNum            uint8
 	enablePosixAcl          bool
+	enableDedup             bool
 	dpReadOnlyWhenVolFull   bool
 	enableQuota             bool
 	enableTransaction       proto.TxOpMask
@@ -61,6 +62,8 @@ type Vol struct {
 	OSSSecretKey      string
 	dpReplicaNum      uint8
 	mpReplicaNum      uint8
+	ecDataNum         uint8 // data shards of the erasure-coded extents, 0 if disabled
+	ecParityNum       uint8
 	Status            uint8
 	threshold         float32
 	dataPartitionSize uint64 // byte
@@ -85,6 +88,7 @@ type Vol struct {
 	domainOn                bool
 	defaultPriority         bool // old default zone first
 	enablePosixAcl          bool
+	enableDedup             bool // extents may be shared by inodes, so they are never overwritten in place
 	enableTransaction       proto.TxOpMask
 	txTimeout               int64
 	txConflictRetryNum      int64
@@ -115,6 +119,8 @@ type Vol struct {
 	mpsLock                 *mpsLockManager
 	EnableAuditLog          bool
 	preloadCapacity         uint64
+	CloneSource             string // the volume this one is cloned from
+	CloneVerSeq             uint64 // the version of the clone source, pinned while the clone exists
 }
 
 func newVol(vv volValue) (vol *Vol) {
@@ -128,6 +134,8 @@ func newVol(vv volValue) (vol *Vol) {
 	vol.VersionMgr = newVersionMgr(vol)
 	vol.dpReplicaNum = vv.DpReplicaNum
 	vol.mpReplicaNum = vv.ReplicaNum
+	vol.ecDataNum = vv.EcDataNum
+	vol.ecParityNum = vv.EcParityNum
 	vol.Owner = vv.Owner
 
 	vol.dataPartitionSize = vv.DataPartitionSize
@@ -144,6 +152,7 @@ func newVol(vv volValue) (vol *Vol) {
 	vol.defaultPriority = vv.DefaultPriority
 	vol.domainId = vv.DomainId
 	vol.enablePosixAcl = vv.EnablePosixAcl
+	vol.enableDedup = vv.EnableDedup
 	vol.enableQuota = vv.EnableQuota
 	vol.enableTransaction = vv.EnableTransaction
 	vol.txTimeout = vv.TxTimeout
@@ -162,6 +171,8 @@ func newVol(vv volValue) (vol *Vol) {
 	vol.CacheLRUInterval = vv.CacheLRUInterval
 	vol.CacheRule = vv.CacheRule
 	vol.Status = vv.Status
+	vol.CloneSource = vv.CloneSource
+	vol.CloneVerSeq = vv.CloneVerSeq
 
 	limitQosVal := &qosArgs{
 		qosEnable:     vv.VolQosEnable,
@@ -606,7 +617,7 @@ func (vol *Vol) checkDataPartitions(c *Cluster) (cnt int) {
 		dp.checkReplicaStatus(c.cfg.DataPartitionTimeOutSec)
 		dp.checkStatus(c.Name, true, c.cfg.DataPartitionTimeOutSec, c, shouldDpInhibitWriteByVolFull, vol.Forbidden)
 		dp.checkLeader(c.Name, c.cfg.DataPartitionTimeOutSec)
-		dp.checkMissingReplicas(c.Name, c.leaderInfo.addr, c.cfg.MissingDataPartitionInterval, c.cfg.IntervalToAlarmMissingDataPartition)
+		dp.checkMissingReplicas(c, c.cfg.MissingDataPartitionInterval, c.cfg.IntervalToAlarmMissingDataPartition)
 		dp.checkReplicaNum(c, vol)
 
 		if time.Now().Unix()-vol.createTime < defaultIntervalToCheckHeartbeat*3 && !vol.Forbidden {
@@ -617,7 +628,7 @@ func (vol *Vol) checkDataPartitions(c *Cluster) (cnt int) {
 			cnt++
 		}
 
-		dp.checkDiskError(c.Name, c.leaderInfo.addr)
+		dp.checkDiskError(c)
 
 		dp.checkReplicationTask(c.Name, vol.dataPartitionSize)
 	}
@@ -750,7 +761,7 @@ func (vol *Vol) checkMetaPartitions(c *Cluster) {
 		mp.checkLeader(c.Name)
 		mp.checkReplicaNum(c, vol.Name, vol.mpReplicaNum)
 		mp.checkEnd(c, maxPartitionID)
-		mp.reportMissingReplicas(c.Name, c.leaderInfo.addr, defaultMetaPartitionTimeOutSec, defaultIntervalToAlarmMissingMetaPartition)
+		mp.reportMissingReplicas(c, defaultMetaPartitionTimeOutSec, defaultIntervalToAlarmMissingMetaPartition)
 		tasks = append(tasks, mp.replicaCreationTasks(c.Name, vol.Name)...)
 	}
 	c.addMetaNodeTasks(tasks)
@@ -1155,6 +1166,7 @@ func (vol *Vol) deleteDataPartition(c *Cluster, dp *DataPartition) {
 	for _, replica := range dp.Replicas {
 		addrs = append(addrs, replica.Addr)
 	}
+	addrs = append(addrs, dp.ecShardOnlyHosts()...)
 
 	for _, addr := range addrs {
 		if err := vol.deleteDataPartitionFromDataNode(c, dp.createTaskToDeleteDataPartition(addr)); err != nil {
@@ -1339,6 +1351,9 @@ func (vol *Vol) getTasksToDeleteDataPartitions() (tasks []*proto.AdminTask) {
 		for _, replica := range dp.Replicas {
 			tasks = append(tasks, dp.createTaskToDeleteDataPartition(replica.Addr))
 		}
+		for _, addr := range dp.ecShardOnlyHosts() {
+			tasks = append(tasks, dp.createTaskToDeleteDataPartition(addr))
+		}
 	}
 	return
 }
@@ -1443,11 +1458,8 @@ func (vol *Vol) doCreateMetaPartition(c *Cluster, start, end uint64) (mp *MetaPa
 		hosts       []string
 		partitionID uint64
 		peers       []proto.Peer
-		wg          sync.WaitGroup
 	)
 
-	errChannel := make(chan error, vol.mpReplicaNum)
-
 	if c.isFaultDomain(vol) {
 		if hosts, peers, err = c.getHostFromDomainZone(vol.domainId, TypeMetaPartition, vol.mpReplicaNum); err != nil {
 			log.LogErrorf("action[doCreateMetaPartition] getHostFromDomainZone err[%v]", err)
@@ -1472,6 +1484,18 @@ func (vol *Vol) doCreateMetaPartition(c *Cluster, start, end uint64) (mp *MetaPa
 	mp = newMetaPartition(partitionID, start, end, vol.mpReplicaNum, vol.Name, vol.ID, vol.VersionMgr.getLatestVer())
 	mp.setHosts(hosts)
 	mp.setPeers(peers)
+	if err = vol.syncCreateMetaPartition(c, mp); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// syncCreateMetaPartition creates the replicas of the meta partition on its hosts,
+// the created replicas are deleted if any of them failed.
+func (vol *Vol) syncCreateMetaPartition(c *Cluster, mp *MetaPartition) (err error) {
+	var wg sync.WaitGroup
+	hosts := mp.Hosts
+	errChannel := make(chan error, len(hosts))
 
 	for _, host := range hosts {
 		wg.Add(1)
@@ -1512,11 +1536,11 @@ func (vol *Vol) doCreateMetaPartition(c *Cluster, start, end uint64) (mp *MetaPa
 			}(host)
 		}
 		wg.Wait()
-		return nil, errors.NewError(err)
+		return errors.NewError(err)
 	default:
 		mp.Status = proto.ReadWrite
 	}
-	log.LogInfof("action[doCreateMetaPartition] success,volName[%v],partition[%v],start[%v],end[%v]", vol.Name, partitionID, start, end)
+	log.LogInfof("action[doCreateMetaPartition] success,volName[%v],partition[%v],start[%v],end[%v]", vol.Name, mp.PartitionID, mp.Start, mp.End)
 	return
 }
 
@@ -1527,6 +1551,7 @@ func setVolFromArgs(args *VolVarargs, vol *Vol) {
 	vol.FollowerRead = args.followerRead
 	vol.authenticate = args.authenticate
 	vol.enablePosixAcl = args.enablePosixAcl
+	vol.enableDedup = args.enableDedup
 	vol.DpReadOnlyWhenVolFull = args.dpReadOnlyWhenVolFull
 	vol.enableQuota = args.enableQuota
 	vol.enableTransaction = args.enableTransaction
@@ -1578,6 +1603,7 @@ func getVolVarargs(vol *Vol) *VolVarargs {
 		dpSelectorName:          vol.dpSelectorName,
 		dpSelectorParm:          vol.dpSelectorParm,
 		enablePosixAcl:          vol.enablePosixAcl,
+		enableDedup:             vol.enableDedup,
 		enableQuota:             vol.enableQuota,
 		dpReplicaNum:            vol.dpReplicaNum,
 		enableTransaction:       vol.enableTransaction,
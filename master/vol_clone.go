@@ -0,0 +1,175 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// cloneVol creates a writable volume starting from the version verSeq of the volume srcName.
+// The clone owns its meta partitions and new data partitions, while the data partitions
+// of the source are shared read only so the extents of the version are never copied.
+// Each meta partition of the clone covers the inode range of one source partition and is
+// placed on the same hosts, its replicas copy the metadata of the version from the local
+// source replicas. The version stays pinned on the source as long as the clone exists.
+func (c *Cluster) cloneVol(srcName, name, owner string, verSeq uint64) (vol *Vol, err error) {
+	var src *Vol
+	if src, err = c.getVol(srcName); err != nil {
+		return
+	}
+	if src.Status == proto.VolStatusMarkDelete {
+		return nil, fmt.Errorf("vol %v is deleting", srcName)
+	}
+	if !proto.IsHot(src.VolType) {
+		return nil, fmt.Errorf("vol %v need be hot one", srcName)
+	}
+	if err = src.VersionMgr.checkCloneVer(verSeq); err != nil {
+		return
+	}
+
+	req := &createVolReq{
+		name:                    name,
+		owner:                   owner,
+		dpSize:                  int(src.dataPartitionSize / util.GB),
+		mpCount:                 defaultInitMetaPartitionCount,
+		dpCount:                 defaultInitDataPartitionCnt,
+		dpReplicaNum:            src.dpReplicaNum,
+		capacity:                int(src.Capacity),
+		followerRead:            src.FollowerRead,
+		authenticate:            src.authenticate,
+		crossZone:               src.crossZone,
+		normalZonesFirst:        src.defaultPriority,
+		domainId:                src.domainId,
+		zoneName:                src.zoneName,
+		description:             fmt.Sprintf("clone of %v at version %v", srcName, verSeq),
+		volType:                 src.VolType,
+		enablePosixAcl:          src.enablePosixAcl,
+		DpReadOnlyWhenVolFull:   src.DpReadOnlyWhenVolFull,
+		ecDataNum:               src.ecDataNum,
+		ecParityNum:             src.ecParityNum,
+		enableTransaction:       src.enableTransaction,
+		enableQuota:             src.enableQuota,
+		txTimeout:               src.txTimeout,
+		txConflictRetryNum:      src.txConflictRetryNum,
+		txConflictRetryInterval: src.txConflictRetryInterval,
+		qosLimitArgs:            &qosArgs{},
+		cloneSource:             srcName,
+		cloneVerSeq:             verSeq,
+	}
+	if vol, err = c.createVol(req); err != nil {
+		return
+	}
+	c.linkVolClone(vol)
+	log.LogInfof("action[cloneVol] vol[%v] cloned from vol[%v] version[%v]", name, srcName, verSeq)
+	return
+}
+
+// initCloneMetaPartitions creates the meta partitions of the clone vol, one for each meta partition
+// of the clone source with the same inode range and hosts.
+func (vol *Vol) initCloneMetaPartitions(c *Cluster) (err error) {
+	var src *Vol
+	if src, err = c.getVol(vol.CloneSource); err != nil {
+		return
+	}
+	srcMps := make([]*MetaPartition, 0)
+	for _, mp := range src.cloneMetaPartitionMap() {
+		srcMps = append(srcMps, mp)
+	}
+	sort.Slice(srcMps, func(i, j int) bool { return srcMps[i].Start < srcMps[j].Start })
+
+	vol.createMpMutex.Lock()
+	defer vol.createMpMutex.Unlock()
+	for _, srcMp := range srcMps {
+		var partitionID uint64
+		if partitionID, err = c.idAlloc.allocateMetaPartitionID(); err != nil {
+			return errors.NewError(err)
+		}
+		srcMp.RLock()
+		mp := newMetaPartition(partitionID, srcMp.Start, srcMp.End, srcMp.ReplicaNum, vol.Name, vol.ID, vol.VersionMgr.getLatestVer())
+		mp.setHosts(append([]string{}, srcMp.Hosts...))
+		mp.setPeers(append([]proto.Peer{}, srcMp.Peers...))
+		srcMp.RUnlock()
+		mp.cloneSrcID = srcMp.PartitionID
+		mp.cloneVerSeq = vol.CloneVerSeq
+
+		if err = vol.syncCreateMetaPartition(c, mp); err != nil {
+			log.LogErrorf("action[initCloneMetaPartitions] vol[%v] clone mp[%v] err[%v]", vol.Name, srcMp.PartitionID, err)
+			return
+		}
+		// replicas added later are synchronized by raft
+		mp.cloneSrcID, mp.cloneVerSeq = 0, 0
+		if err = c.syncAddMetaPartition(mp); err != nil {
+			return errors.NewError(err)
+		}
+		vol.addMetaPartition(mp)
+	}
+	return
+}
+
+// linkVolClone shares the data partitions of the clone source in the view of the clone.
+func (c *Cluster) linkVolClone(vol *Vol) {
+	if vol.CloneSource == "" {
+		return
+	}
+	src, err := c.getVol(vol.CloneSource)
+	if err != nil {
+		log.LogErrorf("action[linkVolClone] vol[%v] clone source[%v] err[%v]", vol.Name, vol.CloneSource, err)
+		return
+	}
+	// drop the cached views, they are rebuilt with the shared partitions on the next request
+	vol.dataPartitions.Lock()
+	vol.dataPartitions.sharedFrom = src.dataPartitions
+	vol.dataPartitions.responseCache = nil
+	vol.dataPartitions.responseCompressCache = nil
+	vol.dataPartitions.Unlock()
+}
+
+// volClonesOf returns the names of the volumes cloned from the volume srcName,
+// all of them if verSeq is 0, otherwise the ones pinning the version verSeq.
+func (c *Cluster) volClonesOf(srcName string, verSeq uint64) (names []string) {
+	for _, vol := range c.copyVols() {
+		if vol.CloneSource != srcName {
+			continue
+		}
+		if verSeq != 0 && vol.CloneVerSeq != verSeq {
+			continue
+		}
+		names = append(names, vol.Name)
+	}
+	return
+}
+
+func (verMgr *VolVersionManager) checkCloneVer(verSeq uint64) (err error) {
+	verMgr.RLock()
+	defer verMgr.RUnlock()
+
+	idx, found := verMgr.getLayInfo(verSeq)
+	if !found || verSeq == 0 {
+		return fmt.Errorf("vol %v version %v not found", verMgr.vol.Name, verSeq)
+	}
+	if idx == len(verMgr.multiVersionList)-1 {
+		return fmt.Errorf("vol %v version %v is uncommitted", verMgr.vol.Name, verSeq)
+	}
+	if verMgr.multiVersionList[idx].Status != proto.VersionNormal {
+		return fmt.Errorf("vol %v version %v status %v not normal", verMgr.vol.Name, verSeq, verMgr.multiVersionList[idx].Status)
+	}
+	return
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTinyCompactionSkippedOnCloneSource(t *testing.T) {
+	srcName, cloneName := "tinyCompactSrc", "tinyCompactClone"
+	c := &Cluster{vols: map[string]*Vol{
+		srcName:   {Name: srcName},
+		cloneName: {Name: cloneName, CloneSource: srcName},
+	}}
+	node := newDataNode("127.0.0.1:17310", testZone1, "cluster")
+	dp := newDataPartition(1, 1, srcName, 1, proto.PartitionTypeNormal, 0)
+	dp.Hosts = []string{node.Addr}
+	report := func() *proto.DataPartitionReport {
+		return &proto.DataPartitionReport{
+			VolName:         srcName,
+			PartitionID:     dp.PartitionID,
+			PartitionStatus: proto.ReadWrite,
+			IsLeader:        true,
+			TinyCompacting:  []uint64{1, 2},
+		}
+	}
+
+	// the clone still refers to the tiny extents of the source
+	dp.updateMetric(report(), node, c)
+	require.Empty(t, dp.convertToDataPartitionResponse().TinyCompacting)
+
+	// the keys of the source are moved once the clone is gone
+	delete(c.vols, cloneName)
+	dp.updateMetric(report(), node, c)
+	require.Equal(t, []uint64{1, 2}, dp.convertToDataPartitionResponse().TinyCompacting)
+}
@@ -417,3 +417,41 @@ func TestConcurrentReadWriteDataPartitionMap(t *testing.T) {
 		vol.updateViewCache(server.cluster)
 	}
 }
+
+func TestCloneVolSharedDataPartitions(t *testing.T) {
+	src := newDataPartitionMap("cloneSrc")
+	clone := newDataPartitionMap("cloneDst")
+	for id := uint64(1); id <= 2; id++ {
+		dp := newDataPartition(id, 3, "cloneSrc", 1, 0, 0)
+		dp.Hosts = []string{"127.0.0.1:17310"}
+		dp.Status = proto.ReadWrite
+		src.put(dp)
+	}
+	dp := newDataPartition(3, 3, "cloneDst", 2, 0, 0)
+	dp.Hosts = []string{"127.0.0.1:17310"}
+	dp.Status = proto.ReadWrite
+	clone.put(dp)
+	clone.sharedFrom = src
+
+	shared := 0
+	for _, dpResp := range clone.getDataPartitionsView(0) {
+		if !dpResp.IsShared {
+			if dpResp.PartitionID != 3 {
+				t.Errorf("dp %v should be shared", dpResp.PartitionID)
+			}
+			continue
+		}
+		shared++
+		if dpResp.Status != proto.ReadOnly {
+			t.Errorf("shared dp %v status %v should be read only", dpResp.PartitionID, dpResp.Status)
+		}
+	}
+	if shared != 2 {
+		t.Errorf("shared dp count %v expect 2", shared)
+	}
+	for _, dpResp := range src.getDataPartitionsView(0) {
+		if dpResp.IsShared {
+			t.Errorf("dp %v of the source should not be shared", dpResp.PartitionID)
+		}
+	}
+}
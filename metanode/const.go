@@ -187,6 +187,15 @@ const (
 	opFSMStoreTickV1  = 72
 
 	opFSMVerListSnapShot = 73
+
+	// dedup
+	opFSMDedupExtentAdd = 74
+	opFSMDedupRegister  = 75
+	opFSMDedupIndexSnap = 76
+
+	// clone
+	opFSMCloneItems = 77
+	opFSMCloneDone  = 78
 )
 
 var exporterKey string
@@ -216,6 +225,8 @@ const (
 	cfgTotalMem                  = "totalMem"
 	cfgMemRatio                  = "memRatio"
 	cfgZoneName                  = "zoneName"
+	cfgRackName                  = "rackName"
+	cfgHostName                  = "hostName"
 	cfgTickInterval              = "tickInterval"
 	cfgRaftRecvBufSize           = "raftRecvBufSize"
 	cfgSmuxPortShift             = "smuxPortShift"             // int
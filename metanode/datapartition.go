@@ -30,6 +30,10 @@ type DataPartition struct {
 	PartitionType string
 	Hosts         []string
 	IsDiscard     bool
+	// tiny extents being compacted by the data partition leader
+	TinyCompacting []uint64
+	// partition of the clone source volume, its extents are never deleted by the clone
+	IsShared bool
 }
 
 // GetAllAddrs returns all addresses of the data partition.
@@ -75,6 +79,24 @@ func (v *Vol) UpdatePartitions(partitions *DataPartitionsView) {
 	}
 }
 
+// tinyCompactingExtents returns the tiny extents under compaction of every data partition.
+func (v *Vol) tinyCompactingExtents() (extents map[uint64]map[uint64]bool) {
+	v.RLock()
+	defer v.RUnlock()
+	extents = make(map[uint64]map[uint64]bool)
+	for id, dp := range v.dataPartitionView {
+		if len(dp.TinyCompacting) == 0 || dp.IsDiscard || dp.IsShared {
+			continue
+		}
+		ids := make(map[uint64]bool, len(dp.TinyCompacting))
+		for _, extentID := range dp.TinyCompacting {
+			ids[extentID] = true
+		}
+		extents[id] = ids
+	}
+	return
+}
+
 func (v *Vol) replaceOrInsert(partition *DataPartition) {
 	v.Lock()
 	defer v.Unlock()
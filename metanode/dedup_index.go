@@ -0,0 +1,214 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"hash/crc32"
+	"sync"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+)
+
+const (
+	dedupIndexVersion = 1
+	// upper bound of fingerprints kept by one meta partition
+	dedupMaxFingerprints = 4 * 1024 * 1024
+)
+
+// dedupEntry records where the data of a fingerprinted chunk lives.
+type dedupEntry struct {
+	Fingerprint  string `json:"fp"`
+	PartitionId  uint64 `json:"pid"`
+	ExtentId     uint64 `json:"eid"`
+	ExtentOffset uint64 `json:"eoff"`
+	Size         uint32 `json:"size"`
+	CRC          uint32 `json:"crc"`
+}
+
+func (e *dedupEntry) extentID() uint64 {
+	return e.PartitionId<<32 | e.ExtentId
+}
+
+// dedupIndex is the fingerprint index of a meta partition.
+//
+// fingerprints maps a chunk fingerprint to the extent range holding its data.
+// refs counts, per extent, the inodes of this partition that reference it.
+// An extent tracked by refs is never deleted from the datanode on behalf of a
+// single inode: it is only released once the last referencing inode drops it.
+type dedupIndex struct {
+	sync.RWMutex
+	fingerprints map[string]*dedupEntry
+	refs         map[uint64]uint32
+	extentFps    map[uint64][]string
+}
+
+type dedupIndexSnap struct {
+	Version int               `json:"ver"`
+	Entries []*dedupEntry     `json:"entries"`
+	Refs    map[uint64]uint32 `json:"refs"`
+}
+
+func newDedupIndex() *dedupIndex {
+	return &dedupIndex{
+		fingerprints: make(map[string]*dedupEntry),
+		refs:         make(map[uint64]uint32),
+		extentFps:    make(map[uint64][]string),
+	}
+}
+
+func dedupExtentID(ek *proto.ExtentKey) uint64 {
+	return ek.PartitionId<<32 | ek.ExtentId
+}
+
+func (idx *dedupIndex) clone() *dedupIndex {
+	idx.RLock()
+	defer idx.RUnlock()
+	n := newDedupIndex()
+	for fp, entry := range idx.fingerprints {
+		n.fingerprints[fp] = entry
+	}
+	for id, cnt := range idx.refs {
+		n.refs[id] = cnt
+	}
+	for id, fps := range idx.extentFps {
+		n.extentFps[id] = append([]string(nil), fps...)
+	}
+	return n
+}
+
+func (idx *dedupIndex) isEmpty() bool {
+	idx.RLock()
+	defer idx.RUnlock()
+	return len(idx.refs) == 0
+}
+
+func (idx *dedupIndex) stat() (fingerprints, extents int) {
+	idx.RLock()
+	defer idx.RUnlock()
+	return len(idx.fingerprints), len(idx.refs)
+}
+
+func (idx *dedupIndex) get(fp string) *dedupEntry {
+	idx.RLock()
+	defer idx.RUnlock()
+	return idx.fingerprints[fp]
+}
+
+func (idx *dedupIndex) isTracked(id uint64) bool {
+	idx.RLock()
+	defer idx.RUnlock()
+	_, ok := idx.refs[id]
+	return ok
+}
+
+// register adds a fingerprint owned by an inode which already holds the extent.
+// It returns false if the fingerprint is known or the index is full.
+func (idx *dedupIndex) register(entry *dedupEntry) bool {
+	idx.Lock()
+	defer idx.Unlock()
+	if _, ok := idx.fingerprints[entry.Fingerprint]; ok {
+		return false
+	}
+	if len(idx.fingerprints) >= dedupMaxFingerprints {
+		return false
+	}
+	id := entry.extentID()
+	idx.fingerprints[entry.Fingerprint] = entry
+	idx.extentFps[id] = append(idx.extentFps[id], entry.Fingerprint)
+	if _, ok := idx.refs[id]; !ok {
+		idx.refs[id] = 1
+	}
+	return true
+}
+
+func (idx *dedupIndex) addRef(id uint64) {
+	idx.Lock()
+	defer idx.Unlock()
+	if _, ok := idx.refs[id]; ok {
+		idx.refs[id]++
+	}
+}
+
+// release drops one inode reference of the extent, last is true if the extent
+// is no longer referenced and its data can be deleted.
+func (idx *dedupIndex) release(id uint64) (last bool) {
+	idx.Lock()
+	defer idx.Unlock()
+	cnt, ok := idx.refs[id]
+	if !ok {
+		return false
+	}
+	if cnt > 1 {
+		idx.refs[id] = cnt - 1
+		return false
+	}
+	for _, fp := range idx.extentFps[id] {
+		delete(idx.fingerprints, fp)
+	}
+	delete(idx.extentFps, id)
+	delete(idx.refs, id)
+	return true
+}
+
+func (idx *dedupIndex) Marshal() (buf []byte, crc uint32, err error) {
+	idx.RLock()
+	snap := &dedupIndexSnap{
+		Version: dedupIndexVersion,
+		Entries: make([]*dedupEntry, 0, len(idx.fingerprints)),
+		Refs:    make(map[uint64]uint32, len(idx.refs)),
+	}
+	for _, entry := range idx.fingerprints {
+		snap.Entries = append(snap.Entries, entry)
+	}
+	for id, cnt := range idx.refs {
+		snap.Refs[id] = cnt
+	}
+	idx.RUnlock()
+
+	if buf, err = json.Marshal(snap); err != nil {
+		return
+	}
+	crc = crc32.ChecksumIEEE(buf)
+	return
+}
+
+func (idx *dedupIndex) UnMarshal(data []byte) (err error) {
+	snap := &dedupIndexSnap{}
+	if err = json.Unmarshal(data, snap); err != nil {
+		return
+	}
+	if snap.Version != dedupIndexVersion {
+		return errors.NewErrorf("unknown dedup index version %v", snap.Version)
+	}
+	idx.Lock()
+	defer idx.Unlock()
+	idx.fingerprints = make(map[string]*dedupEntry, len(snap.Entries))
+	idx.refs = make(map[uint64]uint32, len(snap.Refs))
+	idx.extentFps = make(map[uint64][]string)
+	for id, cnt := range snap.Refs {
+		idx.refs[id] = cnt
+	}
+	for _, entry := range snap.Entries {
+		id := entry.extentID()
+		if _, ok := idx.refs[id]; !ok {
+			continue
+		}
+		idx.fingerprints[entry.Fingerprint] = entry
+		idx.extentFps[id] = append(idx.extentFps[id], entry.Fingerprint)
+	}
+	return
+}
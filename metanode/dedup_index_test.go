@@ -0,0 +1,89 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupIndex(t *testing.T) {
+	idx := newDedupIndex()
+	entry := &dedupEntry{Fingerprint: "fp1", PartitionId: 1, ExtentId: 1025, Size: util.BlockSize}
+	require.True(t, idx.register(entry))
+	require.False(t, idx.register(entry))
+	require.True(t, idx.register(&dedupEntry{Fingerprint: "fp2", PartitionId: 1, ExtentId: 1025,
+		ExtentOffset: util.BlockSize, Size: util.BlockSize}))
+
+	id := entry.extentID()
+	require.True(t, idx.isTracked(id))
+	idx.addRef(id)
+
+	buf, crc, err := idx.Marshal()
+	require.NoError(t, err)
+	require.NotZero(t, crc)
+	loaded := newDedupIndex()
+	require.NoError(t, loaded.UnMarshal(buf))
+	fps, extents := loaded.stat()
+	require.Equal(t, 2, fps)
+	require.Equal(t, 1, extents)
+
+	require.False(t, loaded.release(id))
+	require.NotNil(t, loaded.get("fp2"))
+	require.True(t, loaded.release(id))
+	require.Nil(t, loaded.get("fp1"))
+	require.Nil(t, loaded.get("fp2"))
+	require.True(t, loaded.isEmpty())
+	require.False(t, idx.isEmpty())
+}
+
+func TestDedupSharedExtentDelete(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	mp.uidManager = NewUidMgr(VolNameForTest, PartitionIdForTest)
+	ek := proto.ExtentKey{PartitionId: 1, ExtentId: 1025, Size: util.BlockSize}
+	for _, ino := range []uint64{10, 11} {
+		mp.inodeTree.ReplaceOrInsert(NewInode(ino, 0), true)
+	}
+
+	src := NewInode(10, 0)
+	src.Extents.Append(ek)
+	require.Equal(t, proto.OpOk, mp.fsmAppendExtents(src))
+	<-mp.extDelCh
+
+	status := mp.fsmDedupRegister(&fsmDedupRegisterRequest{
+		Inode:        10,
+		Fingerprints: []proto.DedupFingerprint{{Fingerprint: "fp", Extent: ek}},
+	})
+	require.Equal(t, proto.OpOk, status)
+
+	resp := mp.fsmDedupExtentAdd(&fsmDedupExtentAddRequest{Inode: 11, FileOffset: 0, Fingerprint: "fp"})
+	require.Equal(t, proto.OpOk, resp.Status)
+	require.Equal(t, ek.ExtentId, resp.Extent.ExtentId)
+
+	// the first inode drops the extent, data is still held by the second one
+	require.Equal(t, proto.OpOk, mp.fsmClearInodeCache(NewInode(10, 0)))
+	require.Len(t, <-mp.extDelCh, 0)
+	require.True(t, mp.dedupIndex.isTracked(dedupExtentID(&ek)))
+
+	require.Equal(t, proto.OpOk, mp.fsmClearInodeCache(NewInode(11, 0)))
+	eks := <-mp.extDelCh
+	require.Len(t, eks, 1)
+	require.Equal(t, ek.ExtentId, eks[0].ExtentId)
+	require.False(t, eks[0].IsSplit())
+	require.True(t, mp.dedupIndex.isEmpty())
+}
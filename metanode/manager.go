@@ -180,6 +180,12 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		err = m.opMetaExtentsAdd(conn, p, remoteAddr)
 	case proto.OpMetaExtentAddWithCheck:
 		err = m.opMetaExtentAddWithCheck(conn, p, remoteAddr)
+	case proto.OpMetaDedupExtentAdd:
+		err = m.opMetaDedupExtentAdd(conn, p, remoteAddr)
+	case proto.OpMetaDedupRegister:
+		err = m.opMetaDedupRegister(conn, p, remoteAddr)
+	case proto.OpMetaInodeVerDiff:
+		err = m.opMetaInodeVerDiff(conn, p, remoteAddr)
 	case proto.OpMetaExtentsList:
 		err = m.opMetaExtentsList(conn, p, remoteAddr)
 	case proto.OpMetaObjExtentsList:
@@ -556,6 +562,15 @@ func (m *metadataManager) createPartition(request *proto.CreateMetaPartitionRequ
 		RootDir:     path.Join(m.rootDir, partitionPrefix+partitionId),
 		ConnPool:    m.connPool,
 		VerSeq:      request.VerSeq,
+
+		CloneSrcPartitionID: request.CloneSrcPartitionID,
+		CloneVerSeq:         request.CloneVerSeq,
+	}
+	if mpc.CloneSrcPartitionID != 0 {
+		if _, err = m.getPartition(mpc.CloneSrcPartitionID); err != nil {
+			err = errors.NewErrorf("[createPartition] clone source partition %v: %s", mpc.CloneSrcPartitionID, err.Error())
+			return
+		}
 	}
 	mpc.AfterStop = func() {
 		m.detachPartition(request.PartitionID)
@@ -72,6 +72,18 @@ func (m *metadataManager) checkDisableAuditLogVolume(volNames []string, partitio
 	return
 }
 
+func (m *metadataManager) checkDedupVolume(volNames []string, partition MetaPartition) {
+	volName := partition.GetVolName()
+	for _, name := range volNames {
+		if name == volName {
+			partition.SetEnableDedup(true)
+			return
+		}
+	}
+	partition.SetEnableDedup(false)
+	return
+}
+
 func (m *metadataManager) opMasterHeartbeat(conn net.Conn, p *Packet,
 	remoteAddr string) (err error) {
 	// For ack to master
@@ -110,6 +122,7 @@ func (m *metadataManager) opMasterHeartbeat(conn net.Conn, p *Packet,
 			m.checkFollowerRead(req.FLReadVols, partition)
 			m.checkForbiddenVolume(req.ForbiddenVols, partition)
 			m.checkDisableAuditLogVolume(req.DisableAuditVols, partition)
+			m.checkDedupVolume(req.DedupVols, partition)
 			partition.SetUidLimit(req.UidLimitInfo)
 			partition.SetTxInfo(req.TxInfo)
 			partition.setQuotaHbInfo(req.QuotaHbInfos)
@@ -1227,6 +1240,102 @@ func (m *metadataManager) opMetaExtentAddWithCheck(conn net.Conn, p *Packet,
 	return
 }
 
+func (m *metadataManager) opMetaDedupExtentAdd(conn net.Conn, p *Packet,
+	remoteAddr string) (err error) {
+	req := &proto.DedupExtentAddRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+
+	if err = mp.DedupExtentAdd(req, p); err != nil {
+		log.LogErrorf("%s [opMetaDedupExtentAdd] DedupExtentAdd: %s", remoteAddr, err.Error())
+	}
+	if err = m.respondToClient(conn, p); err != nil {
+		log.LogErrorf("%s [opMetaDedupExtentAdd] DedupExtentAdd: %s, "+
+			"response to client: %s", remoteAddr, err.Error(), p.GetResultMsg())
+	}
+	log.LogDebugf("%s [opMetaDedupExtentAdd] req: %d - %v, resp: %v, body: %s",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg(), p.Data)
+	return
+}
+
+func (m *metadataManager) opMetaDedupRegister(conn net.Conn, p *Packet,
+	remoteAddr string) (err error) {
+	req := &proto.DedupRegisterRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+
+	if err = mp.DedupRegister(req, p); err != nil {
+		log.LogErrorf("%s [opMetaDedupRegister] DedupRegister: %s", remoteAddr, err.Error())
+	}
+	if err = m.respondToClient(conn, p); err != nil {
+		log.LogErrorf("%s [opMetaDedupRegister] DedupRegister: %s, "+
+			"response to client: %s", remoteAddr, err.Error(), p.GetResultMsg())
+	}
+	log.LogDebugf("%s [opMetaDedupRegister] req: %d - %v, resp: %v",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg())
+	return
+}
+
+func (m *metadataManager) opMetaInodeVerDiff(conn net.Conn, p *Packet,
+	remoteAddr string) (err error) {
+	req := &proto.InodeVerDiffRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+
+	if err = mp.InodeVerDiff(req, p); err != nil {
+		log.LogErrorf("%s [opMetaInodeVerDiff] InodeVerDiff: %s", remoteAddr, err.Error())
+	}
+	if err = m.respondToClient(conn, p); err != nil {
+		log.LogErrorf("%s [opMetaInodeVerDiff] InodeVerDiff: %s, "+
+			"response to client: %s", remoteAddr, err.Error(), p.GetResultMsg())
+	}
+	log.LogDebugf("%s [opMetaInodeVerDiff] req: %d - %v, resp: %v",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg())
+	return
+}
+
 func (m *metadataManager) opMetaExtentsList(conn net.Conn, p *Packet,
 	remoteAddr string) (err error) {
 	req := &proto.GetExtentsRequest{}
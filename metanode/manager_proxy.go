@@ -27,7 +27,10 @@ const (
 	NoClosedConnect    = false
 )
 
-var ErrForbiddenMetaPartition = errors.New("meta partition is forbidden")
+var (
+	ErrForbiddenMetaPartition = errors.New("meta partition is forbidden")
+	ErrCloningMetaPartition   = errors.New("meta partition is copying metadata of the clone source")
+)
 
 func (m *metadataManager) IsForbiddenOp(mp MetaPartition, reqOp uint8) bool {
 	if !mp.IsForbidden() {
@@ -57,6 +60,8 @@ func (m *metadataManager) IsForbiddenOp(mp MetaPartition, reqOp uint8) bool {
 		proto.OpMetaBatchObjExtentsAdd,
 		proto.OpMetaBatchExtentsAdd,
 		proto.OpMetaExtentsDel,
+		proto.OpMetaDedupExtentAdd,
+		proto.OpMetaDedupRegister,
 		// inode
 		proto.OpMetaCreateInode,
 		proto.OpQuotaCreateInode,
@@ -105,6 +110,13 @@ func (m *metadataManager) serveProxy(conn net.Conn, mp MetaPartition,
 		return false
 	}
 
+	// the metadata is incomplete until copied from the clone source
+	if mp.IsCloning() {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(ErrCloningMetaPartition.Error()))
+		m.respondToClient(conn, p)
+		return false
+	}
+
 	if leaderAddr, ok = mp.IsLeader(); ok {
 		return
 	}
@@ -64,6 +64,8 @@ type MetaNode struct {
 	raftRetainLogs            uint64
 	raftSyncSnapFormatVersion uint32 // format version of snapshot that raft leader sent to follower
 	zoneName                  string
+	rackName                  string
+	hostName                  string
 	httpStopC                 chan uint8
 	smuxStopC                 chan uint8
 	metrics                   *MetaNodeMetrics
@@ -208,6 +210,8 @@ func (m *MetaNode) parseConfig(cfg *config.Config) (err error) {
 	m.tickInterval = int(cfg.GetFloat(cfgTickInterval))
 	m.raftRecvBufSize = int(cfg.GetInt(cfgRaftRecvBufSize))
 	m.zoneName = cfg.GetString(cfgZoneName)
+	m.rackName = cfg.GetString(cfgRackName)
+	m.hostName = cfg.GetString(cfgHostName)
 
 	deleteBatchCount := cfg.GetInt64(cfgDeleteBatchCount)
 	if deleteBatchCount > 1 {
@@ -303,6 +307,7 @@ func (m *MetaNode) parseConfig(cfg *config.Config) (err error) {
 	log.LogInfof("[parseConfig] load raftHeartbeatPort[%v].", m.raftHeartbeatPort)
 	log.LogInfof("[parseConfig] load raftReplicatePort[%v].", m.raftReplicatePort)
 	log.LogInfof("[parseConfig] load zoneName[%v].", m.zoneName)
+	log.LogInfof("[parseConfig] load rackName[%v] hostName[%v].", m.rackName, m.hostName)
 
 	if err = m.parseSmuxConfig(cfg); err != nil {
 		return fmt.Errorf("parseSmuxConfig fail err %v", err)
@@ -462,7 +467,7 @@ func (m *MetaNode) register() (err error) {
 			step++
 		}
 		var nodeID uint64
-		if nodeID, err = masterClient.NodeAPI().AddMetaNodeWithAuthNode(nodeAddress, m.zoneName, m.serviceIDKey); err != nil {
+		if nodeID, err = masterClient.NodeAPI().AddMetaNodeWithLocation(nodeAddress, m.zoneName, m.rackName, m.hostName, m.serviceIDKey); err != nil {
 			log.LogErrorf("register: register to master fail: address(%v) err(%s)", nodeAddress, err)
 			time.Sleep(3 * time.Second)
 			continue
@@ -82,6 +82,7 @@ func newPartition(conf *MetaPartitionConfig, manager *metadataManager) (mp *meta
 		extReset:      make(chan struct{}),
 		vol:           NewVol(),
 		manager:       manager,
+		dedupIndex:    newDedupIndex(),
 		verSeq:        conf.VerSeq,
 	}
 	mp.config.Cursor = 0
@@ -106,6 +106,22 @@ func NewPacketToBatchDeleteExtent(dp *DataPartition, exts []*proto.ExtentKey) *P
 	return p
 }
 
+// NewPacketToTinyExtentRelocate returns a new packet asking the data partition
+// leader to move the data of a tiny extent key elsewhere.
+func NewPacketToTinyExtentRelocate(dp *DataPartition, ek *proto.ExtentKey) *Packet {
+	p := new(Packet)
+	p.Magic = proto.ProtoMagic
+	p.Opcode = proto.OpTinyExtentRelocate
+	p.ExtentType = proto.TinyExtentType
+	p.PartitionID = dp.PartitionID
+	p.ExtentID = ek.ExtentId
+	p.Data, _ = json.Marshal(ek)
+	p.Size = uint32(len(p.Data))
+	p.ReqID = proto.GenerateRequestID()
+
+	return p
+}
+
 // NewPacketToDeleteExtent returns a new packet to delete the extent.
 func NewPacketToFreeInodeOnRaftFollower(partitionID uint64, freeInodes []byte) *Packet {
 	p := new(Packet)
@@ -99,6 +99,10 @@ type MetaPartitionConfig struct {
 	RaftStore     raftstore.RaftStore `json:"-"`
 	ConnPool      *util.ConnectPool   `json:"-"`
 	Forbidden     bool                `json:"-"`
+	// copy the metadata of version CloneVerSeq from the local partition CloneSrcPartitionID,
+	// both are reset once the copy is done
+	CloneSrcPartitionID uint64 `json:"clone_src_partition_id,omitempty"`
+	CloneVerSeq         uint64 `json:"clone_ver_seq,omitempty"`
 }
 
 func (c *MetaPartitionConfig) checkMeta() (err error) {
@@ -203,6 +207,12 @@ type OpExtent interface {
 	// ExtentsDelete(req *proto.DelExtentKeyRequest, p *Packet) (err error)
 }
 
+// OpDedup defines the interface for the content deduplication operations.
+type OpDedup interface {
+	DedupExtentAdd(req *proto.DedupExtentAddRequest, p *Packet) (err error)
+	DedupRegister(req *proto.DedupRegisterRequest, p *Packet) (err error)
+}
+
 type OpMultipart interface {
 	GetMultipart(req *proto.GetMultipartRequest, p *Packet) (err error)
 	CreateMultipart(req *proto.CreateMultipartRequest, p *Packet) (err error)
@@ -225,6 +235,7 @@ type OpMultiVersion interface {
 	GetAllVersionInfo(req *proto.MultiVersionOpRequest, p *Packet) (err error)
 	GetSpecVersionInfo(req *proto.MultiVersionOpRequest, p *Packet) (err error)
 	GetExtentByVer(ino *Inode, req *proto.GetExtentsRequest, rsp *proto.GetExtentsResponse)
+	InodeVerDiff(req *proto.InodeVerDiffRequest, p *Packet) (err error)
 	checkVerList(info *proto.VolVersionInfoList, sync bool) (needUpdate bool, err error)
 	checkByMasterVerlist(mpVerList *proto.VolVersionInfoList, masterVerList *proto.VolVersionInfoList) (err error)
 }
@@ -234,6 +245,7 @@ type OpMeta interface {
 	OpInode
 	OpDentry
 	OpExtent
+	OpDedup
 	OpPartition
 	OpExtend
 	OpMultipart
@@ -278,8 +290,11 @@ type MetaPartition interface {
 	ForceSetMetaPartitionToFininshLoad()
 	IsForbidden() bool
 	SetForbidden(status bool)
+	IsCloning() bool
 	IsEnableAuditLog() bool
 	SetEnableAuditLog(status bool)
+	IsEnableDedup() bool
+	SetEnableDedup(status bool)
 }
 
 type UidManager struct {
@@ -513,11 +528,13 @@ type metaPartition struct {
 	mqMgr                  *MetaQuotaManager
 	nonIdempotent          sync.Mutex
 	uniqChecker            *uniqChecker
+	dedupIndex             *dedupIndex
 	verSeq                 uint64
 	multiVersionList       *proto.VolVersionInfoList
 	versionLock            sync.Mutex
 	verUpdateChan          chan []byte
 	enableAuditLog         bool
+	enableDedup            bool
 }
 
 func (mp *metaPartition) IsForbidden() bool {
@@ -536,6 +553,14 @@ func (mp *metaPartition) SetEnableAuditLog(status bool) {
 	mp.enableAuditLog = status
 }
 
+func (mp *metaPartition) IsEnableDedup() bool {
+	return mp.enableDedup
+}
+
+func (mp *metaPartition) SetEnableDedup(status bool) {
+	mp.enableDedup = status
+}
+
 func (mp *metaPartition) acucumRebuildStart() bool {
 	return mp.uidManager.accumRebuildStart()
 }
@@ -757,6 +782,7 @@ func (mp *metaPartition) onStart(isCreate bool) (err error) {
 	}
 
 	go mp.startCheckerEvict()
+	go mp.cloneWorker()
 
 	log.LogDebugf("[before raft] get mp[%v] applied(%d),inodeCount(%d),dentryCount(%d)", mp.config.PartitionId, mp.applyID, mp.inodeTree.Len(), mp.dentryTree.Len())
 
@@ -881,6 +907,7 @@ func NewMetaPartition(conf *MetaPartitionConfig, manager *metadataManager) MetaP
 		vol:           NewVol(),
 		manager:       manager,
 		uniqChecker:   newUniqChecker(),
+		dedupIndex:    newDedupIndex(),
 		verSeq:        conf.VerSeq,
 		multiVersionList: &proto.VolVersionInfoList{
 			TemporaryVerMap: make(map[uint64]*proto.VolVersionInfo),
@@ -1010,6 +1037,7 @@ const (
 	CRC_COUNT_TX_STUFF   int = 7
 	CRC_COUNT_UINQ_STUFF int = 8
 	CRC_COUNT_MULTI_VER  int = 9
+	CRC_COUNT_DEDUP      int = 10
 )
 
 func (mp *metaPartition) LoadSnapshot(snapshotPath string) (err error) {
@@ -1026,7 +1054,7 @@ func (mp *metaPartition) LoadSnapshot(snapshotPath string) (err error) {
 	}
 
 	crc_count := len(crcs)
-	if crc_count != CRC_COUNT_BASIC && crc_count != CRC_COUNT_TX_STUFF && crc_count != CRC_COUNT_UINQ_STUFF && crc_count != CRC_COUNT_MULTI_VER {
+	if crc_count != CRC_COUNT_BASIC && crc_count != CRC_COUNT_TX_STUFF && crc_count != CRC_COUNT_UINQ_STUFF && crc_count != CRC_COUNT_MULTI_VER && crc_count != CRC_COUNT_DEDUP {
 		log.LogErrorf("action[LoadSnapshot] crc array length %d not match", len(crcs))
 		return ErrSnapshotCrcMismatch
 	}
@@ -1045,7 +1073,7 @@ func (mp *metaPartition) LoadSnapshot(snapshotPath string) (err error) {
 		loadFuncs = append(loadFuncs, mp.loadUniqChecker)
 	}
 
-	if crc_count == CRC_COUNT_MULTI_VER {
+	if crc_count >= CRC_COUNT_MULTI_VER {
 		if err = mp.loadMultiVer(snapshotPath, crcs[CRC_COUNT_MULTI_VER-1]); err != nil {
 			return
 		}
@@ -1053,6 +1081,12 @@ func (mp *metaPartition) LoadSnapshot(snapshotPath string) (err error) {
 		mp.storeMultiVersion(snapshotPath, &storeMsg{multiVerList: mp.multiVersionList.VerList})
 	}
 
+	if crc_count >= CRC_COUNT_DEDUP {
+		if err = mp.loadDedupIndex(snapshotPath, crcs[CRC_COUNT_DEDUP-1]); err != nil {
+			return
+		}
+	}
+
 	errs := make([]error, len(loadFuncs))
 	var wg sync.WaitGroup
 	wg.Add(len(loadFuncs))
@@ -1164,6 +1198,7 @@ func (mp *metaPartition) store(sm *storeMsg) (err error) {
 		mp.storeTxRbDentry,
 		mp.storeUniqChecker,
 		mp.storeMultiVersion,
+		mp.storeDedupIndex,
 	}
 	for _, storeFunc := range storeFuncs {
 		var crc uint32
@@ -1710,6 +1745,7 @@ func (mp *metaPartition) storeSnapshotFiles() (err error) {
 		txRbDentryTree: NewBtree(),
 		uniqId:         mp.GetUniqId(),
 		uniqChecker:    newUniqChecker(),
+		dedupIndex:     newDedupIndex(),
 		multiVerList:   mp.multiVersionList.VerList,
 	}
 
@@ -0,0 +1,262 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// A meta partition of a cloned volume is created on the hosts of the source partition
+// with the same inode range. Its leader reads the inodes, dentries and xattrs seen by
+// the cloned version from the local source replica, and submits them through raft, so
+// every replica gets the same metadata. Requests are refused until the copy is done.
+const (
+	cloneCheckInterval = 5 * time.Second
+	cloneBatchCount    = 1024
+)
+
+type fsmCloneItemsRequest struct {
+	Inodes   [][]byte `json:"inodes,omitempty"`
+	Dentries [][]byte `json:"dentries,omitempty"`
+	Extends  [][]byte `json:"extends,omitempty"`
+}
+
+func (req *fsmCloneItemsRequest) count() int {
+	return len(req.Inodes) + len(req.Dentries) + len(req.Extends)
+}
+
+// IsCloning returns true until the metadata of the clone source is copied.
+func (mp *metaPartition) IsCloning() bool {
+	return mp.config.CloneSrcPartitionID != 0
+}
+
+func (mp *metaPartition) cloneWorker() {
+	if !mp.IsCloning() {
+		return
+	}
+	t := time.NewTicker(cloneCheckInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-mp.stopC:
+			return
+		case <-t.C:
+			if !mp.IsCloning() {
+				return
+			}
+			if _, ok := mp.IsLeader(); !ok {
+				continue
+			}
+			if err := mp.cloneFromSource(); err != nil {
+				log.LogWarnf("[cloneWorker] mp(%v) clone from mp(%v) version(%v) err(%v)",
+					mp.config.PartitionId, mp.config.CloneSrcPartitionID, mp.config.CloneVerSeq, err)
+			}
+		}
+	}
+}
+
+// cloneFromSource copies the metadata of the version from the local source replica,
+// the items copied by a former leader are skipped when applied again.
+func (mp *metaPartition) cloneFromSource() (err error) {
+	srcID, verSeq := mp.config.CloneSrcPartitionID, mp.config.CloneVerSeq
+	partition, err := mp.manager.getPartition(srcID)
+	if err != nil {
+		return
+	}
+	src, ok := partition.(*metaPartition)
+	if !ok {
+		return fmt.Errorf("unexpected partition type %T", partition)
+	}
+	// the version is applied in order with the changes, all of them have
+	// been applied on the source replica once a newer version is applied
+	if src.GetVerSeq() <= verSeq {
+		return fmt.Errorf("version %v is not sealed on source yet, current %v", verSeq, src.GetVerSeq())
+	}
+
+	req := &fsmCloneItemsRequest{}
+	flush := func(force bool) error {
+		if req.count() == 0 || (!force && req.count() < cloneBatchCount) {
+			return nil
+		}
+		data, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		req = &fsmCloneItemsRequest{}
+		resp, err := mp.submit(opFSMCloneItems, data)
+		if err != nil {
+			return err
+		}
+		if status, ok := resp.(uint8); ok && status != proto.OpOk {
+			return fmt.Errorf("apply clone items status %v", status)
+		}
+		return nil
+	}
+
+	src.inodeTree.GetTree().Ascend(func(i BtreeItem) bool {
+		ino := cloneInodeLayer(i.(*Inode), verSeq)
+		if ino == nil {
+			return true
+		}
+		var data []byte
+		if data, err = ino.Marshal(); err != nil {
+			return false
+		}
+		req.Inodes = append(req.Inodes, data)
+		err = flush(false)
+		return err == nil
+	})
+	if err != nil {
+		return
+	}
+	src.dentryTree.GetTree().Ascend(func(i BtreeItem) bool {
+		den := cloneDentryLayer(i.(*Dentry), verSeq)
+		if den == nil {
+			return true
+		}
+		var data []byte
+		if data, err = den.Marshal(); err != nil {
+			return false
+		}
+		req.Dentries = append(req.Dentries, data)
+		err = flush(false)
+		return err == nil
+	})
+	if err != nil {
+		return
+	}
+	src.extendTree.GetTree().Ascend(func(i BtreeItem) bool {
+		extend := cloneExtendLayer(i.(*Extend), verSeq)
+		if extend == nil {
+			return true
+		}
+		var data []byte
+		if data, err = extend.Bytes(); err != nil {
+			return false
+		}
+		req.Extends = append(req.Extends, data)
+		err = flush(false)
+		return err == nil
+	})
+	if err != nil {
+		return
+	}
+	if err = flush(true); err != nil {
+		return
+	}
+
+	if _, err = mp.submit(opFSMCloneDone, nil); err != nil {
+		return
+	}
+	log.LogInfof("[cloneFromSource] mp(%v) cloned from mp(%v) version(%v)", mp.config.PartitionId, srcID, verSeq)
+	return
+}
+
+// cloneInodeLayer returns the inode seen by the version without the older layers,
+// the clone starts without any version of its own.
+func cloneInodeLayer(ino *Inode, verSeq uint64) *Inode {
+	layer, _ := ino.getInoByVer(verSeq, false)
+	if !isLiveInodeLayer(layer) {
+		return nil
+	}
+	layer.RLock()
+	newIno := layer.CopyDirectly().(*Inode)
+	layer.RUnlock()
+	newIno.Inode = ino.Inode
+	for i := range newIno.Extents.eks {
+		newIno.Extents.eks[i].SnapInfo = nil
+	}
+	return newIno
+}
+
+func cloneDentryLayer(den *Dentry, verSeq uint64) *Dentry {
+	layer, _ := den.getDentryFromVerList(verSeq, false)
+	if layer == nil || layer.isDeleted() {
+		return nil
+	}
+	newDen := layer.CopyDirectly().(*Dentry)
+	newDen.ParentId = den.ParentId
+	newDen.Name = den.Name
+	newDen.setVerSeq(0)
+	return newDen
+}
+
+func cloneExtendLayer(extend *Extend, verSeq uint64) *Extend {
+	layer := extend
+	if extend.verSeq > verSeq {
+		layer = extend.GetExtentByVersion(verSeq)
+	}
+	if layer == nil {
+		return nil
+	}
+	newExtend := NewExtend(extend.GetInode())
+	layer.Range(func(key, value []byte) bool {
+		newExtend.Put(key, value, 0)
+		return true
+	})
+	return newExtend
+}
+
+func (mp *metaPartition) fsmCloneItems(req *fsmCloneItemsRequest) (status uint8, err error) {
+	for _, data := range req.Inodes {
+		ino := NewInode(0, 0)
+		if err = ino.Unmarshal(data); err != nil {
+			return
+		}
+		if mp.config.Cursor < ino.Inode {
+			mp.config.Cursor = ino.Inode
+		}
+		// copied by a former leader
+		if st := mp.fsmCreateInode(ino); st != proto.OpOk && st != proto.OpExistErr {
+			log.LogWarnf("[fsmCloneItems] mp(%v) create inode(%v) status(%v)", mp.config.PartitionId, ino.Inode, st)
+		}
+	}
+	for _, data := range req.Dentries {
+		den := &Dentry{}
+		if err = den.Unmarshal(data); err != nil {
+			return
+		}
+		// the links of the parent are copied with it
+		if st := mp.fsmCreateDentry(den, true); st != proto.OpOk && st != proto.OpExistErr {
+			log.LogWarnf("[fsmCloneItems] mp(%v) create dentry(%v) status(%v)", mp.config.PartitionId, den, st)
+		}
+	}
+	for _, data := range req.Extends {
+		var extend *Extend
+		if extend, err = NewExtendFromBytes(data); err != nil {
+			return
+		}
+		mp.fsmSetXAttr(extend)
+	}
+	return proto.OpOk, nil
+}
+
+func (mp *metaPartition) fsmCloneDone() (status uint8, err error) {
+	if !mp.IsCloning() {
+		return proto.OpOk, nil
+	}
+	mp.config.CloneSrcPartitionID = 0
+	mp.config.CloneVerSeq = 0
+	if err = mp.PersistMetadata(); err != nil {
+		log.LogErrorf("[fsmCloneDone] mp(%v) persist metadata err(%v)", mp.config.PartitionId, err)
+		return
+	}
+	return proto.OpOk, nil
+}
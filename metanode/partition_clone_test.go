@@ -0,0 +1,44 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneInodeLayer(t *testing.T) {
+	// modified in version 20, the clone of version 10 sees the older layer
+	modified := newVerDiffInode(1, 20, 1)
+	modified.Size = 200
+	older := newVerDiffInode(1, 10, 1)
+	older.Size = 100
+	modified.multiSnap.multiVersions = InodeBatch{older}
+	ino := cloneInodeLayer(modified, 10)
+	require.NotNil(t, ino)
+	require.Equal(t, uint64(1), ino.Inode)
+	require.Equal(t, uint64(100), ino.Size)
+	require.Equal(t, uint64(0), ino.getVer())
+
+	// created after version 10
+	created := newVerDiffInode(2, 20, 1)
+	created.multiSnap.multiVersions = InodeBatch{}
+	require.Nil(t, cloneInodeLayer(created, 10))
+
+	// deleted before version 10
+	deleted := newVerDiffInode(3, 5, 0)
+	require.Nil(t, cloneInodeLayer(deleted, 10))
+}
@@ -187,6 +187,10 @@ func (mp *metaPartition) batchDeleteExtentsByDp(dpId uint64, extents []*proto.Ex
 		log.LogDebugf("[batchDeleteExtentsByDp] mp(%v) dp(%v) is discard", mp.config.PartitionId, dpId)
 		return
 	}
+	if dp.IsShared {
+		log.LogDebugf("[batchDeleteExtentsByDp] mp(%v) dp(%v) is shared with the clone source", mp.config.PartitionId, dpId)
+		return
+	}
 	log.LogDebugf("[batchDeleteExtentsByDp] mp(%v) delete eks from dp(%v)", mp.config.PartitionId, dpId)
 	err = mp.doBatchDeleteExtentsByPartition(dpId, extents)
 	return
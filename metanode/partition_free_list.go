@@ -61,6 +61,7 @@ func (mp *metaPartition) startFreeList() (err error) {
 	// start vol update ticket
 	go mp.updateVolWorker()
 	go mp.deleteWorker()
+	go mp.tinyCompactWorker()
 	mp.startToDeleteExtents()
 	return
 }
@@ -99,11 +100,13 @@ func (mp *metaPartition) updateVolWorker() {
 				continue
 			}
 			newView.DataPartitions[i] = &DataPartition{
-				PartitionID: view.DataPartitions[i].PartitionID,
-				Status:      view.DataPartitions[i].Status,
-				Hosts:       view.DataPartitions[i].Hosts,
-				ReplicaNum:  view.DataPartitions[i].ReplicaNum,
-				IsDiscard:   view.DataPartitions[i].IsDiscard,
+				PartitionID:    view.DataPartitions[i].PartitionID,
+				Status:         view.DataPartitions[i].Status,
+				Hosts:          view.DataPartitions[i].Hosts,
+				ReplicaNum:     view.DataPartitions[i].ReplicaNum,
+				IsDiscard:      view.DataPartitions[i].IsDiscard,
+				TinyCompacting: view.DataPartitions[i].TinyCompacting,
+				IsShared:       view.DataPartitions[i].IsShared,
 			}
 		}
 		return newView
@@ -216,6 +219,11 @@ func (mp *metaPartition) batchDeleteExtentsByPartition(partitionDeleteExtents ma
 			log.LogWarnf("action[batchDeleteExtentsByPartition] dp(%v) is discard, skip extents count(%v)", partitionID, len(extents))
 			continue
 		}
+		// NOTE: the extents of a shared dp belong to the clone source, skip it
+		if dp.IsShared {
+			log.LogDebugf("action[batchDeleteExtentsByPartition] dp(%v) is shared, skip extents count(%v)", partitionID, len(extents))
+			continue
+		}
 		log.LogDebugf("batchDeleteExtentsByPartition partitionID %v extents %v", partitionID, extents)
 		wg.Add(1)
 		go func(partitionID uint64, extents []*proto.ExtentKey) {
@@ -294,6 +302,8 @@ func (mp *metaPartition) deleteMarkedInodes(inoSlice []uint64) {
 
 		extInfo := inode.GetAllExtsOfflineInode(mp.config.PartitionId)
 		for dpID, inodeExts := range extInfo {
+			// extents shared through the dedup index are released by internalDeleteInode
+			inodeExts = mp.dedupSkipTracked(inodeExts)
 			exts, ok := deleteExtentsByPartition[dpID]
 			if !ok {
 				exts = make([]*proto.ExtentKey, 0)
@@ -252,6 +252,7 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 		quotaRebuild := mp.mqMgr.statisticRebuildStart()
 		uidRebuild := mp.acucumRebuildStart()
 		uniqChecker := mp.uniqChecker.clone()
+		dedupIndex := mp.dedupIndex.clone()
 		msg := &storeMsg{
 			command:        opFSMStoreTick,
 			applyIndex:     index,
@@ -266,6 +267,7 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			quotaRebuild:   quotaRebuild,
 			uidRebuild:     uidRebuild,
 			uniqChecker:    uniqChecker,
+			dedupIndex:     dedupIndex,
 			multiVerList:   mp.GetAllVerList(),
 		}
 		log.LogDebugf("opFSMStoreTick: quotaRebuild [%v] uidRebuild [%v]", quotaRebuild, uidRebuild)
@@ -440,8 +442,28 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			return
 		}
 		err = mp.fsmUniqCheckerEvict(req)
+	case opFSMDedupExtentAdd:
+		req := &fsmDedupExtentAddRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmDedupExtentAdd(req)
+	case opFSMDedupRegister:
+		req := &fsmDedupRegisterRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmDedupRegister(req)
 	case opFSMVersionOp:
 		err = mp.fsmVersionOp(msg.V)
+	case opFSMCloneItems:
+		req := &fsmCloneItemsRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp, err = mp.fsmCloneItems(req)
+	case opFSMCloneDone:
+		resp, err = mp.fsmCloneDone()
 	default:
 		// do nothing
 	}
@@ -621,6 +643,7 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 		txRbInodeTree  = NewBtree()
 		txRbDentryTree = NewBtree()
 		uniqChecker    = newUniqChecker()
+		dedupIndex     = newDedupIndex()
 		verList        []*proto.VolVersionInfo
 	)
 
@@ -676,6 +699,7 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 			mp.txProcessor.txResource.txRbInodeTree = txRbInodeTree
 			mp.txProcessor.txResource.txRbDentryTree = txRbDentryTree
 			mp.uniqChecker = uniqChecker
+			mp.dedupIndex = dedupIndex
 			mp.multiVersionList.VerList = make([]*proto.VolVersionInfo, len(verList))
 			copy(mp.multiVersionList.VerList, verList)
 			mp.verSeq = mp.multiVersionList.GetLastVer()
@@ -694,6 +718,7 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 				txRbInodeTree:  mp.txProcessor.txResource.txRbInodeTree.GetTree(),
 				txRbDentryTree: mp.txProcessor.txResource.txRbDentryTree.GetTree(),
 				uniqChecker:    uniqChecker.clone(),
+				dedupIndex:     dedupIndex.clone(),
 				multiVerList:   mp.GetVerList(),
 			}
 			select {
@@ -842,6 +867,13 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 				return
 			}
 			log.LogDebugf("ApplySnapshot: write snap uniqChecker")
+		case opFSMDedupIndexSnap:
+			if err = dedupIndex.UnMarshal(snap.V); err != nil {
+				log.LogErrorf("ApplySnapshot: write snap dedupIndex fail: partitionID(%v) err(%v)",
+					mp.config.PartitionId, err)
+				return
+			}
+			log.LogDebugf("ApplySnapshot: write snap dedupIndex")
 
 		default:
 			if leaderSnapFormatVer != math.MaxUint32 && leaderSnapFormatVer > mp.manager.metaNode.raftSyncSnapFormatVersion {
@@ -898,7 +930,8 @@ func (mp *metaPartition) HandleLeaderChange(leader uint64) {
 
 	log.LogDebugf("[metaPartition] pid: %v HandleLeaderChange become leader conn %v, nodeId: %v, leader: %v", mp.config.PartitionId, serverPort, mp.config.NodeId, leader)
 	exporter.Warning(fmt.Sprintf("[metaPartition] pid: %v HandleLeaderChange become leader conn %v, nodeId: %v, leader: %v", mp.config.PartitionId, serverPort, mp.config.NodeId, leader))
-	if mp.config.Start == 0 && mp.config.Cursor == 0 {
+	// the root inode of a clone is copied from the source
+	if mp.config.Start == 0 && mp.config.Cursor == 0 && !mp.IsCloning() {
 		id, err := mp.nextInodeID()
 		if err != nil {
 			log.LogFatalf("[HandleLeaderChange] init root inode id: %s.", err.Error())
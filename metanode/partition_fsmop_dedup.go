@@ -0,0 +1,228 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+type fsmDedupExtentAddRequest struct {
+	Inode       uint64 `json:"ino"`
+	FileOffset  uint64 `json:"fo"`
+	Fingerprint string `json:"fp"`
+	ModifyTime  int64  `json:"mt"`
+}
+
+type fsmDedupExtentAddResp struct {
+	Status uint8
+	Extent proto.ExtentKey
+}
+
+type fsmDedupRegisterRequest struct {
+	Inode        uint64                   `json:"ino"`
+	Fingerprints []proto.DedupFingerprint `json:"fps"`
+}
+
+// inodeRefsExtent reports whether the current extents of the inode still
+// point into the given extent.
+func inodeRefsExtent(ino *Inode, id uint64) (ok bool) {
+	ino.Extents.Range(func(_ int, ek proto.ExtentKey) bool {
+		if dedupExtentID(&ek) == id {
+			ok = true
+			return false
+		}
+		return true
+	})
+	return
+}
+
+func (mp *metaPartition) fsmDedupExtentAdd(req *fsmDedupExtentAddRequest) (resp *fsmDedupExtentAddResp) {
+	resp = &fsmDedupExtentAddResp{Status: proto.OpOk}
+
+	entry := mp.dedupIndex.get(req.Fingerprint)
+	if entry == nil {
+		resp.Status = proto.OpNotExistErr
+		return
+	}
+	item := mp.inodeTree.CopyGet(NewInode(req.Inode, 0))
+	if item == nil {
+		resp.Status = proto.OpNotExistErr
+		return
+	}
+	ino := item.(*Inode)
+	if ino.ShouldDelete() || !proto.IsRegular(ino.Type) {
+		resp.Status = proto.OpNotExistErr
+		return
+	}
+
+	ek := proto.ExtentKey{
+		FileOffset:   req.FileOffset,
+		PartitionId:  entry.PartitionId,
+		ExtentId:     entry.ExtentId,
+		ExtentOffset: entry.ExtentOffset,
+		Size:         entry.Size,
+		CRC:          entry.CRC,
+	}
+	id := entry.extentID()
+	referenced := inodeRefsExtent(ino, id)
+
+	if resp.Status = mp.uidManager.addUidSpace(ino.Uid, ino.Inode, []proto.ExtentKey{ek}); resp.Status != proto.OpOk {
+		return
+	}
+	oldSize := int64(ino.Size)
+	param := &AppendExtParam{
+		mpId:             mp.config.PartitionId,
+		mpVer:            mp.verSeq,
+		ek:               ek,
+		ct:               req.ModifyTime,
+		volType:          mp.volType,
+		multiVersionList: mp.multiVersionList,
+	}
+	delExtents, status := ino.AppendExtentWithCheck(param)
+	if status != proto.OpOk {
+		mp.uidManager.minusUidSpace(ino.Uid, ino.Inode, []proto.ExtentKey{ek})
+		resp.Status = status
+		return
+	}
+	if !referenced {
+		mp.dedupIndex.addRef(id)
+	}
+	mp.updateUsedInfo(int64(ino.Size)-oldSize, 0, ino.Inode)
+
+	if len(delExtents) > 0 {
+		mp.uidManager.minusUidSpace(ino.Uid, ino.Inode, delExtents)
+		ino.DecSplitExts(mp.config.PartitionId, delExtents)
+		mp.extDelCh <- mp.dedupFilterDelExtents(ino, delExtents)
+	}
+	resp.Extent = ek
+	log.LogDebugf("fsmDedupExtentAdd: mp[%v] inode[%v] fp(%v) ek(%v) deleteExtents(%v)",
+		mp.config.PartitionId, ino.Inode, req.Fingerprint, ek, delExtents)
+	return
+}
+
+func (mp *metaPartition) fsmDedupRegister(req *fsmDedupRegisterRequest) (status uint8) {
+	status = proto.OpOk
+	item := mp.inodeTree.Get(NewInode(req.Inode, 0))
+	if item == nil {
+		status = proto.OpNotExistErr
+		return
+	}
+	ino := item.(*Inode)
+	if ino.ShouldDelete() {
+		status = proto.OpNotExistErr
+		return
+	}
+
+	for _, fp := range req.Fingerprints {
+		ek := &fp.Extent
+		if storage.IsTinyExtent(ek.ExtentId) || ek.Size == 0 {
+			continue
+		}
+		// only data the inode currently holds can be shared
+		covered := false
+		ino.Extents.Range(func(_ int, cur proto.ExtentKey) bool {
+			if cur.PartitionId == ek.PartitionId && cur.ExtentId == ek.ExtentId &&
+				cur.ExtentOffset <= ek.ExtentOffset &&
+				ek.ExtentOffset+uint64(ek.Size) <= cur.ExtentOffset+uint64(cur.Size) {
+				covered = true
+				return false
+			}
+			return true
+		})
+		if !covered {
+			log.LogDebugf("fsmDedupRegister: mp[%v] inode[%v] fp(%v) ek(%v) not held by inode",
+				mp.config.PartitionId, ino.Inode, fp.Fingerprint, ek)
+			continue
+		}
+		mp.dedupIndex.register(&dedupEntry{
+			Fingerprint:  fp.Fingerprint,
+			PartitionId:  ek.PartitionId,
+			ExtentId:     ek.ExtentId,
+			ExtentOffset: ek.ExtentOffset,
+			Size:         ek.Size,
+			CRC:          ek.CRC,
+		})
+	}
+	return
+}
+
+// dedupFilterDelExtents removes the extents shared through the dedup index
+// from the extents dropped by an inode. A shared extent is released once per
+// inode, and only handed over for deletion when its last reference is gone.
+func (mp *metaPartition) dedupFilterDelExtents(ino *Inode, eks []proto.ExtentKey) []proto.ExtentKey {
+	if len(eks) == 0 || mp.dedupIndex.isEmpty() {
+		return eks
+	}
+	handled := make(map[uint64]struct{})
+	result := make([]proto.ExtentKey, 0, len(eks))
+	for _, ek := range eks {
+		id := dedupExtentID(&ek)
+		if _, ok := handled[id]; ok {
+			continue
+		}
+		if !mp.dedupIndex.isTracked(id) {
+			result = append(result, ek)
+			continue
+		}
+		handled[id] = struct{}{}
+		if inodeRefsExtent(ino, id) {
+			continue
+		}
+		if mp.dedupIndex.release(id) {
+			ek.SetSplit(false)
+			result = append(result, ek)
+		}
+	}
+	return result
+}
+
+// dedupReleaseInode drops the references of an inode being removed from the
+// inode tree, and returns the shared extents nobody references any longer.
+func (mp *metaPartition) dedupReleaseInode(ino *Inode) (eks []proto.ExtentKey) {
+	if mp.dedupIndex.isEmpty() {
+		return
+	}
+	seen := make(map[uint64]struct{})
+	ino.Extents.Range(func(_ int, ek proto.ExtentKey) bool {
+		id := dedupExtentID(&ek)
+		if _, ok := seen[id]; ok {
+			return true
+		}
+		seen[id] = struct{}{}
+		if mp.dedupIndex.release(id) {
+			ek.SetSplit(false)
+			eks = append(eks, ek)
+		}
+		return true
+	})
+	return
+}
+
+// dedupSkipTracked filters out the extents tracked by the dedup index.
+func (mp *metaPartition) dedupSkipTracked(eks []*proto.ExtentKey) []*proto.ExtentKey {
+	if mp.dedupIndex.isEmpty() {
+		return eks
+	}
+	result := make([]*proto.ExtentKey, 0, len(eks))
+	for _, ek := range eks {
+		if mp.dedupIndex.isTracked(dedupExtentID(ek)) {
+			continue
+		}
+		result = append(result, ek)
+	}
+	return result
+}
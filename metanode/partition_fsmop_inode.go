@@ -349,7 +349,7 @@ func (mp *metaPartition) fsmUnlinkInode(ino *Inode, uniqID uint64) (resp *InodeR
 	if len(ext2Del) > 0 {
 		log.LogDebugf("action[fsmUnlinkInode] mp[%v] ino[%v] DecSplitExts ext2Del %v", mp.config.PartitionId, ino, ext2Del)
 		inode.DecSplitExts(mp.config.PartitionId, ext2Del)
-		mp.extDelCh <- ext2Del
+		mp.extDelCh <- mp.dedupFilterDelExtents(inode, ext2Del)
 	}
 	log.LogDebugf("action[fsmUnlinkInode] mp[%v] ino[%v] left", mp.config.PartitionId, inode)
 	return
@@ -413,6 +413,11 @@ func (mp *metaPartition) internalDeleteBatch(val []byte) error {
 
 func (mp *metaPartition) internalDeleteInode(ino *Inode) {
 	log.LogDebugf("action[internalDeleteInode] ino[%v] really be deleted", ino)
+	if item := mp.inodeTree.Get(ino); item != nil {
+		if delExtents := mp.dedupReleaseInode(item.(*Inode)); len(delExtents) > 0 {
+			mp.extDelCh <- delExtents
+		}
+	}
 	mp.inodeTree.Delete(ino)
 	mp.freeList.Remove(ino.Inode)
 	mp.extendTree.Delete(&Extend{inode: ino.Inode}) // Also delete extend attribute.
@@ -443,7 +448,7 @@ func (mp *metaPartition) fsmAppendExtents(ino *Inode) (status uint8) {
 
 	log.LogInfof("fsmAppendExtents mpId[%v].inode[%v] DecSplitExts deleteExtents(%v)", mp.config.PartitionId, ino2.Inode, delExtents)
 	ino2.DecSplitExts(mp.config.PartitionId, delExtents)
-	mp.extDelCh <- delExtents
+	mp.extDelCh <- mp.dedupFilterDelExtents(ino2, delExtents)
 	return
 }
 
@@ -505,7 +510,7 @@ func (mp *metaPartition) fsmAppendExtentsWithCheck(ino *Inode, isSplit bool) (st
 		if status == proto.OpOk {
 			log.LogInfof("action[fsmAppendExtentsWithCheck] mp[%v] DecSplitExts delExtents [%v]", mp.config.PartitionId, delExtents)
 			fsmIno.DecSplitExts(appendExtParam.mpId, delExtents)
-			mp.extDelCh <- delExtents
+			mp.extDelCh <- mp.dedupFilterDelExtents(fsmIno, delExtents)
 		}
 		// conflict need delete eks[0], to clear garbage data
 		if status == proto.OpConflictExtentsErr {
@@ -522,7 +527,7 @@ func (mp *metaPartition) fsmAppendExtentsWithCheck(ino *Inode, isSplit bool) (st
 		delExtents, status = fsmIno.SplitExtentWithCheck(appendExtParam)
 		log.LogInfof("action[fsmAppendExtentsWithCheck] mp[%v] DecSplitExts delExtents [%v]", mp.config.PartitionId, delExtents)
 		fsmIno.DecSplitExts(mp.config.PartitionId, delExtents)
-		mp.extDelCh <- delExtents
+		mp.extDelCh <- mp.dedupFilterDelExtents(fsmIno, delExtents)
 		mp.uidManager.minusUidSpace(fsmIno.Uid, fsmIno.Inode, delExtents)
 	}
 
@@ -618,7 +623,7 @@ func (mp *metaPartition) fsmExtentsTruncate(ino *Inode) (resp *InodeResponse) {
 	// now we should delete the extent
 	log.LogInfof("fsmExtentsTruncate.mp (%v) inode[%v] DecSplitExts exts(%v)", mp.config.PartitionId, i.Inode, delExtents)
 	i.DecSplitExts(mp.config.PartitionId, delExtents)
-	mp.extDelCh <- delExtents
+	mp.extDelCh <- mp.dedupFilterDelExtents(i, delExtents)
 	mp.uidManager.minusUidSpace(i.Uid, i.Inode, delExtents)
 	return
 }
@@ -772,7 +777,7 @@ func (mp *metaPartition) fsmClearInodeCache(ino *Inode) (status uint8) {
 	log.LogInfof("fsmClearInodeCache.mp[%v] inode[%v] DecSplitExts delExtents(%v)", mp.config.PartitionId, ino2.Inode, delExtents)
 	if len(delExtents) > 0 {
 		ino2.DecSplitExts(mp.config.PartitionId, delExtents)
-		mp.extDelCh <- delExtents
+		mp.extDelCh <- mp.dedupFilterDelExtents(ino2, delExtents)
 	}
 	return
 }
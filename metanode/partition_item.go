@@ -147,6 +147,7 @@ type MetaItemIterator struct {
 	txRbInodeTree     *BTree
 	txRbDentryTree    *BTree
 	uniqChecker       *uniqChecker
+	dedupIndex        *dedupIndex
 	verList           []*proto.VolVersionInfo
 
 	filenames []string
@@ -202,6 +203,7 @@ func newMetaItemIterator(mp *metaPartition) (si *MetaItemIterator, err error) {
 	si.txRbInodeTree = mp.txProcessor.txResource.txRbInodeTree.GetTree()
 	si.txRbDentryTree = mp.txProcessor.txResource.txRbDentryTree.GetTree()
 	si.uniqChecker = mp.uniqChecker.clone()
+	si.dedupIndex = mp.dedupIndex.clone()
 	si.verList = mp.GetAllVerList()
 	mp.nonIdempotent.Unlock()
 
@@ -349,6 +351,13 @@ func newMetaItemIterator(mp *metaPartition) (si *MetaItemIterator, err error) {
 					return
 				}
 			}
+
+			if !si.dedupIndex.isEmpty() {
+				produceItem(si.dedupIndex)
+				if checkClose() {
+					return
+				}
+			}
 		}
 
 		// process extent del files
@@ -483,6 +492,14 @@ func (si *MetaItemIterator) Next() (data []byte, err error) {
 			return
 		}
 		snap = NewMetaItem(opFSMUniqCheckerSnap, nil, raw)
+	case *dedupIndex:
+		var raw []byte
+		if raw, _, err = typedItem.Marshal(); err != nil {
+			si.err = err
+			si.Close()
+			return
+		}
+		snap = NewMetaItem(opFSMDedupIndexSnap, nil, raw)
 	default:
 		panic(fmt.Sprintf("unknown item type: %v", reflect.TypeOf(item).Name()))
 	}
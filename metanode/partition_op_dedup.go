@@ -0,0 +1,110 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// DedupExtentAdd maps a chunk of the inode onto the extent range already
+// holding data with the same fingerprint. OpNotExistErr tells the client
+// the fingerprint is unknown and the chunk has to be written.
+func (mp *metaPartition) DedupExtentAdd(req *proto.DedupExtentAddRequest, p *Packet) (err error) {
+	// data partitions of the volume copy the shared extents on write
+	if !mp.IsEnableDedup() {
+		err = fmt.Errorf("dedup is not enabled on volume %v", mp.config.VolName)
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+		return
+	}
+	if mp.dedupIndex.get(req.Fingerprint) == nil {
+		p.PacketErrorWithBody(proto.OpNotExistErr, nil)
+		return
+	}
+	if _, _, err = mp.CheckQuota(req.Inode, p); err != nil {
+		log.LogErrorf("DedupExtentAdd CheckQuota fail err [%v]", err)
+		return
+	}
+
+	op := &fsmDedupExtentAddRequest{
+		Inode:       req.Inode,
+		FileOffset:  req.FileOffset,
+		Fingerprint: req.Fingerprint,
+		ModifyTime:  time.Now().Unix(),
+	}
+	val, err := json.Marshal(op)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	r, err := mp.submit(opFSMDedupExtentAdd, val)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+
+	resp := r.(*fsmDedupExtentAddResp)
+	if resp.Status != proto.OpOk {
+		p.PacketErrorWithBody(resp.Status, nil)
+		return
+	}
+	reply, err := json.Marshal(&proto.DedupExtentAddResponse{Extent: resp.Extent})
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(reply)
+	return
+}
+
+// DedupRegister publishes the fingerprints of chunks written by an inode, so
+// that later writes with the same content can be mapped onto them.
+func (mp *metaPartition) DedupRegister(req *proto.DedupRegisterRequest, p *Packet) (err error) {
+	// data partitions of the volume copy the shared extents on write
+	if !mp.IsEnableDedup() {
+		err = fmt.Errorf("dedup is not enabled on volume %v", mp.config.VolName)
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+		return
+	}
+
+	fps := make([]proto.DedupFingerprint, 0, len(req.Fingerprints))
+	for _, fp := range req.Fingerprints {
+		if mp.dedupIndex.get(fp.Fingerprint) != nil {
+			continue
+		}
+		fps = append(fps, fp)
+	}
+	if len(fps) == 0 {
+		p.PacketOkReply()
+		return
+	}
+
+	val, err := json.Marshal(&fsmDedupRegisterRequest{Inode: req.Inode, Fingerprints: fps})
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	resp, err := mp.submit(opFSMDedupRegister, val)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	p.PacketErrorWithBody(resp.(uint8), nil)
+	return
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func newVerDiffInode(ino, verSeq uint64, nlink uint32) *Inode {
+	i := NewInode(ino, FileModeType)
+	i.NLink = nlink
+	i.setVerNoCheck(verSeq)
+	return i
+}
+
+func TestInodeVerChange(t *testing.T) {
+	// unchanged since version 10
+	unchanged := newVerDiffInode(1, 5, 1)
+	require.Equal(t, "", inodeVerChange(unchanged, 10, 0))
+
+	// created in version 20
+	created := newVerDiffInode(2, 20, 1)
+	created.multiSnap.multiVersions = InodeBatch{}
+	require.Equal(t, proto.VerDiffCreated, inodeVerChange(created, 10, 0))
+
+	// modified in version 20, the layer of version 10 is kept
+	modified := newVerDiffInode(3, 20, 1)
+	modified.multiSnap.multiVersions = InodeBatch{newVerDiffInode(3, 10, 1)}
+	require.Equal(t, proto.VerDiffModified, inodeVerChange(modified, 10, 0))
+	require.Equal(t, "", inodeVerChange(modified, 20, 0))
+
+	// deleted in version 20
+	deleted := newVerDiffInode(4, 20, 0)
+	deleted.multiSnap.multiVersions = InodeBatch{newVerDiffInode(4, 10, 1)}
+	require.Equal(t, proto.VerDiffDeleted, inodeVerChange(deleted, 10, 0))
+}
@@ -52,6 +52,7 @@ const (
 	uniqIDFile              = "uniqID"
 	uniqCheckerFile         = "uniqChecker"
 	verdataFile             = "multiVer"
+	dedupIndexFile          = "dedupIndex"
 	StaleMetadataSuffix     = ".old"
 	StaleMetadataTimeFormat = "20060102150405.000000000"
 	verdataInitFile         = "multiVerInitFile"
@@ -791,6 +792,34 @@ func (mp *metaPartition) loadMultiVer(rootDir string, crc uint32) (err error) {
 	return
 }
 
+func (mp *metaPartition) loadDedupIndex(rootDir string, crc uint32) (err error) {
+	filename := path.Join(rootDir, dedupIndexFile)
+	if _, err = os.Stat(filename); err != nil {
+		log.LogErrorf("loadDedupIndex get file %s err(%s)", filename, err)
+		err = nil
+		return
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		err = errors.NewErrorf("[loadDedupIndex] OpenFile: %v", err.Error())
+		return
+	}
+	if res := crc32.ChecksumIEEE(data); res != crc {
+		return fmt.Errorf("partitionID(%v) volume(%v) dedup index calc crc %v not equal with disk %v",
+			mp.config.PartitionId, mp.config.VolName, res, crc)
+	}
+	if err = mp.dedupIndex.UnMarshal(data); err != nil {
+		err = errors.NewErrorf("[loadDedupIndex] Unmarshal: %v", err.Error())
+		return
+	}
+
+	fps, extents := mp.dedupIndex.stat()
+	log.LogInfof("loadDedupIndex: load complete: partitionID(%v) volume(%v) fingerprints(%v) extents(%v)",
+		mp.config.PartitionId, mp.config.VolName, fps, extents)
+	return
+}
+
 func (mp *metaPartition) storeMultiVersion(rootDir string, sm *storeMsg) (crc uint32, err error) {
 	filename := path.Join(rootDir, verdataFile)
 	fp, err := os.OpenFile(filename, os.O_RDWR|os.O_APPEND|os.O_TRUNC|os.
@@ -1329,3 +1358,33 @@ func (mp *metaPartition) storeUniqChecker(rootDir string, sm *storeMsg) (crc uin
 		mp.config.UniqId, mp.config.VolName, crc)
 	return
 }
+
+func (mp *metaPartition) storeDedupIndex(rootDir string, sm *storeMsg) (crc uint32, err error) {
+	filename := path.Join(rootDir, dedupIndexFile)
+	fp, err := os.OpenFile(filename, os.O_RDWR|os.O_TRUNC|os.O_APPEND|os.
+		O_CREATE, 0o755)
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = fp.Sync()
+		fp.Close()
+	}()
+
+	index := sm.dedupIndex
+	if index == nil {
+		index = newDedupIndex()
+	}
+	var data []byte
+	if data, crc, err = index.Marshal(); err != nil {
+		return
+	}
+
+	if _, err = fp.Write(data); err != nil {
+		return
+	}
+
+	log.LogInfof("storeDedupIndex: store complete: partitionID(%v) volume(%v) crc(%v)",
+		mp.config.PartitionId, mp.config.VolName, crc)
+	return
+}
@@ -41,6 +41,7 @@ type storeMsg struct {
 	uidRebuild     bool
 	uniqId         uint64
 	uniqChecker    *uniqChecker
+	dedupIndex     *dedupIndex
 	multiVerList   []*proto.VolVersionInfo
 }
 
@@ -0,0 +1,181 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// The data partition leaders report the tiny extents they are compacting.
+// The meta partition leader relocates the keys of its inodes pointing at them,
+// the old key is swapped for the new one by opFSMExtentsAddWithCheck so that a
+// key changed meanwhile by a client is left alone, and the replaced range is
+// released through the usual extent deletion.
+const (
+	tinyCompactCheckInterval = 10 * time.Minute
+	tinyCompactBatchCount    = 1024
+	tinyCompactIdleTime      = 3600 // seconds, keys of files in use are not moved
+)
+
+func (mp *metaPartition) tinyCompactWorker() {
+	if proto.IsCold(mp.volType) {
+		return
+	}
+	t := time.NewTicker(tinyCompactCheckInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-mp.stopC:
+			return
+		case <-t.C:
+			if _, ok := mp.IsLeader(); !ok {
+				continue
+			}
+			mp.compactTinyExtents()
+		}
+	}
+}
+
+type tinyCompactCandidate struct {
+	ino        uint64
+	modifyTime int64
+	ek         proto.ExtentKey
+}
+
+func (mp *metaPartition) tinyCompactCandidates(compacting map[uint64]map[uint64]bool) (candidates []*tinyCompactCandidate) {
+	now := time.Now().Unix()
+	mp.inodeTree.GetTree().Ascend(func(i BtreeItem) bool {
+		inode := i.(*Inode)
+		if !proto.IsRegular(inode.Type) {
+			return true
+		}
+		inode.RLock()
+		defer inode.RUnlock()
+		if inode.ShouldDelete() || inode.getLayerLen() > 0 ||
+			now-inode.ModifyTime < tinyCompactIdleTime || now-inode.AccessTime < tinyCompactIdleTime {
+			return true
+		}
+		inode.Extents.Range(func(_ int, ek proto.ExtentKey) bool {
+			if extents, ok := compacting[ek.PartitionId]; !ok || !extents[ek.ExtentId] {
+				return true
+			}
+			if ek.IsSplit() || mp.dedupIndex.isTracked(dedupExtentID(&ek)) {
+				return true
+			}
+			candidates = append(candidates, &tinyCompactCandidate{ino: inode.Inode, modifyTime: inode.ModifyTime, ek: ek})
+			return len(candidates) < tinyCompactBatchCount
+		})
+		return len(candidates) < tinyCompactBatchCount
+	})
+	return
+}
+
+// compactTinyExtents moves the keys off the tiny extents under compaction.
+func (mp *metaPartition) compactTinyExtents() {
+	compacting := mp.vol.tinyCompactingExtents()
+	if len(compacting) == 0 {
+		return
+	}
+	var (
+		moved     int
+		movedSize uint64
+	)
+	for _, c := range mp.tinyCompactCandidates(compacting) {
+		if _, ok := mp.IsLeader(); !ok {
+			return
+		}
+		dp := mp.vol.GetPartition(c.ek.PartitionId)
+		if dp == nil {
+			continue
+		}
+		newEk, err := mp.relocateTinyExtentKey(dp, &c.ek)
+		if err != nil {
+			log.LogWarnf("action[compactTinyExtents] mp %v ino %v ek %v relocate err %v",
+				mp.config.PartitionId, c.ino, c.ek, err)
+			continue
+		}
+		status, err := mp.swapTinyExtentKey(c, newEk)
+		if err != nil || status != proto.OpOk {
+			log.LogWarnf("action[compactTinyExtents] mp %v ino %v ek %v swap status %v err %v",
+				mp.config.PartitionId, c.ino, c.ek, status, err)
+			continue
+		}
+		moved++
+		movedSize += uint64(c.ek.Size)
+	}
+	if moved > 0 {
+		log.LogInfof("action[compactTinyExtents] mp %v relocated %v tiny extent keys, %v bytes",
+			mp.config.PartitionId, moved, movedSize)
+	}
+}
+
+func (mp *metaPartition) relocateTinyExtentKey(dp *DataPartition, ek *proto.ExtentKey) (newEk *proto.ExtentKey, err error) {
+	if len(dp.Hosts) < 1 {
+		return nil, errors.NewErrorf("dp id(%v) is invalid", dp.PartitionID)
+	}
+	addr := util.ShiftAddrPort(dp.Hosts[0], smuxPortShift)
+	conn, err := smuxPool.GetConnect(addr)
+	if err != nil {
+		return
+	}
+	defer func() {
+		smuxPool.PutConnect(conn, err != nil)
+	}()
+	p := NewPacketToTinyExtentRelocate(dp, ek)
+	if err = p.WriteToConn(conn); err != nil {
+		return
+	}
+	if err = p.ReadFromConnWithVer(conn, proto.ReadDeadlineTime); err != nil {
+		return
+	}
+	if p.ResultCode != proto.OpOk {
+		return nil, fmt.Errorf("%v response: %v", p.GetUniqueLogId(), p.GetResultMsg())
+	}
+	newEk = new(proto.ExtentKey)
+	if err = json.Unmarshal(p.Data[:p.Size], newEk); err != nil {
+		return
+	}
+	if newEk.FileOffset != ek.FileOffset || newEk.Size != ek.Size || newEk.PartitionId != ek.PartitionId {
+		return nil, fmt.Errorf("relocated key %v mismatch %v", newEk, ek)
+	}
+	return
+}
+
+// swapTinyExtentKey replaces the key if it is still in the inode, otherwise
+// the fsm releases the relocated range.
+func (mp *metaPartition) swapTinyExtentKey(c *tinyCompactCandidate, newEk *proto.ExtentKey) (status uint8, err error) {
+	ino := NewInode(c.ino, 0)
+	ino.ModifyTime = c.modifyTime
+	ino.setVer(mp.verSeq)
+	ino.Extents.Append(*newEk)
+	// the discarded key is stored right after the new one
+	ino.Extents.eks = append(ino.Extents.eks, c.ek)
+	val, err := ino.Marshal()
+	if err != nil {
+		return
+	}
+	resp, err := mp.submit(opFSMExtentsAddWithCheck, val)
+	if err != nil {
+		return
+	}
+	return resp.(uint8), nil
+}
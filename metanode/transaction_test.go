@@ -69,6 +69,7 @@ func newMetaPartition(PartitionId uint64, manager *metadataManager) (mp *metaPar
 		extReset:      make(chan struct{}),
 		vol:           NewVol(),
 		manager:       manager,
+		dedupIndex:    newDedupIndex(),
 	}
 	mp.config.Cursor = 1000
 	mp.config.End = 100000
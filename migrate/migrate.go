@@ -0,0 +1,153 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cubefs/cubefs/migrate/sdk"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/config"
+)
+
+var (
+	configFile    = flag.String("c", "", "config file path")
+	configVersion = flag.Bool("v", false, "show version")
+)
+
+const (
+	Role = "Migrate"
+
+	actionSync    = "sync"
+	actionCutover = "cutover"
+	actionVerify  = "verify"
+	actionMigrate = "migrate"
+
+	defaultDrainSec         = 30
+	defaultMaxCatchUpPasses = 5
+	defaultCatchUpThreshold = 1000
+)
+
+func main() {
+	defer sdk.FlushLog()
+	flag.Parse()
+
+	if *configVersion {
+		fmt.Print(proto.DumpVersion(Role))
+		os.Exit(0)
+	}
+	cfg, err := config.LoadConfigFile(*configFile)
+	if err != nil {
+		fmt.Printf("LoadConfigFile failed: %v\n", err)
+		os.Exit(1)
+	}
+	if err = checkConfig(cfg); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err = sdk.InitLog(cfg.GetString("logDir"), cfg.GetString("logLevel")); err != nil {
+		fmt.Printf("init log failed: %v\n", err)
+		os.Exit(1)
+	}
+	proto.InitBufferPool(int64(32768))
+
+	m, err := sdk.NewMigrator(sdk.Config{
+		SrcMasters:      strings.Split(cfg.GetString("srcMasterAddr"), ","),
+		SrcVolume:       cfg.GetString("srcVolume"),
+		DstMasters:      strings.Split(cfg.GetString("dstMasterAddr"), ","),
+		DstVolume:       cfg.GetString("dstVolume"),
+		Mode:            cfg.GetString("mode"),
+		StateFile:       cfg.GetString("stateFile"),
+		CopyConcurrency: int(cfg.GetInt64("copyConcurrency")),
+		VerifyData:      cfg.GetBool("verifyData"),
+		DrainTime:       time.Duration(cfg.GetInt64WithDefault("drainSec", defaultDrainSec)) * time.Second,
+	})
+	if err != nil {
+		fmt.Printf("create migrator failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer m.Close()
+
+	var (
+		stats  *sdk.PassStats
+		report *sdk.VerifyReport
+	)
+	switch cfg.GetString("action") {
+	case actionSync:
+		stats, err = m.Sync()
+	case actionCutover:
+		stats, report, err = m.Cutover()
+	case actionVerify:
+		report, err = m.Verify()
+	case actionMigrate:
+		stats, report, err = m.Migrate(int(cfg.GetInt64WithDefault("maxCatchUpPasses", defaultMaxCatchUpPasses)),
+			cfg.GetInt64WithDefault("catchUpThreshold", defaultCatchUpThreshold))
+	}
+	if stats != nil {
+		fmt.Printf("pass %v: dirs %v files %v copied %v (%v bytes) removed %v errors %v\n",
+			stats.Pass, stats.Dirs, stats.Files, stats.Copied, stats.CopiedBytes, stats.Removed, stats.Errors)
+	}
+	if report != nil {
+		if e := writeReport(cfg.GetString("reportFile"), report); e != nil {
+			fmt.Printf("write report failed: %v\n", e)
+		}
+	}
+	if err != nil {
+		fmt.Printf("%v failed: %v\n", cfg.GetString("action"), err)
+		sdk.FlushLog()
+		os.Exit(1)
+	}
+	fmt.Printf("%v succeed\n", cfg.GetString("action"))
+}
+
+func writeReport(file string, report *sdk.VerifyReport) (err error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return
+	}
+	if file == "" {
+		fmt.Println(string(data))
+		return
+	}
+	return os.WriteFile(file, data, 0o644)
+}
+
+func checkConfig(cfg *config.Config) error {
+	for _, key := range []string{"srcMasterAddr", "srcVolume", "dstMasterAddr", "dstVolume", "logDir", "stateFile", "action"} {
+		if cfg.GetString(key) == "" {
+			return fmt.Errorf("%v cannot be empty", key)
+		}
+	}
+	switch cfg.GetString("action") {
+	case actionSync, actionCutover, actionVerify, actionMigrate:
+	default:
+		return fmt.Errorf("action[%v] is not support, one of %v, %v, %v, %v",
+			cfg.GetString("action"), actionSync, actionCutover, actionVerify, actionMigrate)
+	}
+	switch cfg.GetString("mode") {
+	case sdk.ModeVersion, sdk.ModeMtime:
+	default:
+		return fmt.Errorf("mode[%v] is not support, one of %v, %v", cfg.GetString("mode"), sdk.ModeVersion, sdk.ModeMtime)
+	}
+	if cfg.GetString("srcMasterAddr") == cfg.GetString("dstMasterAddr") && cfg.GetString("srcVolume") == cfg.GetString("dstVolume") {
+		return fmt.Errorf("source and target are the same volume")
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/util/config"
+)
+
+func TestCheckConfig(t *testing.T) {
+	valid := `{"srcMasterAddr": "10.0.0.1:17010", "srcVolume": "vol1",
+		"dstMasterAddr": "10.0.1.1:17010", "dstVolume": "vol1",
+		"logDir": "/tmp/migrate", "stateFile": "/tmp/migrate/vol1.state",
+		"mode": "mtime", "action": "migrate"}`
+	if err := checkConfig(config.LoadConfigString(valid)); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+
+	invalid := map[string]string{
+		"dst_empty": `{"srcMasterAddr": "10.0.0.1:17010", "srcVolume": "vol1", "dstVolume": "vol1",
+			"logDir": "/tmp/migrate", "stateFile": "/tmp/migrate/vol1.state", "mode": "mtime", "action": "sync"}`,
+		"bad_action": `{"srcMasterAddr": "10.0.0.1:17010", "srcVolume": "vol1", "dstMasterAddr": "10.0.1.1:17010",
+			"dstVolume": "vol1", "logDir": "/tmp/migrate", "stateFile": "/tmp/migrate/vol1.state", "mode": "mtime", "action": "copy"}`,
+		"bad_mode": `{"srcMasterAddr": "10.0.0.1:17010", "srcVolume": "vol1", "dstMasterAddr": "10.0.1.1:17010",
+			"dstVolume": "vol1", "logDir": "/tmp/migrate", "stateFile": "/tmp/migrate/vol1.state", "mode": "ctime", "action": "sync"}`,
+		"same_volume": `{"srcMasterAddr": "10.0.0.1:17010", "srcVolume": "vol1", "dstMasterAddr": "10.0.0.1:17010",
+			"dstVolume": "vol1", "logDir": "/tmp/migrate", "stateFile": "/tmp/migrate/vol1.state", "mode": "mtime", "action": "sync"}`,
+	}
+	for name, cfgJSON := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if err := checkConfig(config.LoadConfigString(cfgJSON)); err == nil {
+				t.Fatalf("expected error, but got nil")
+			}
+		})
+	}
+}
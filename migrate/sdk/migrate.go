@@ -0,0 +1,825 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sdk
+
+import (
+	"fmt"
+	"io"
+	"os"
+	gopath "path"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/stream"
+	masterSDK "github.com/cubefs/cubefs/sdk/master"
+	"github.com/cubefs/cubefs/sdk/meta"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	// ModeVersion finds the changed inodes of a catch-up pass by diffing the snapshot
+	// versions taken at the start of the passes, the source volume must enable snapshots.
+	ModeVersion = "version"
+	// ModeMtime copies the files modified since the start of the previous pass.
+	ModeMtime = "mtime"
+
+	defaultCopyConcurrency = 8
+	copyBufferSize         = 4 * util.MB
+	// tolerated clock skew between the clients writing the source volume and the migration tool
+	mtimeSlack         = 60
+	versionWaitTimeout = 2 * time.Minute
+	maxPassErrors      = 100
+)
+
+type Config struct {
+	SrcMasters      []string
+	SrcVolume       string
+	DstMasters      []string
+	DstVolume       string
+	Mode            string
+	StateFile       string
+	CopyConcurrency int
+	VerifyData      bool          // compare the file contents in the verification, not only the sizes
+	DrainTime       time.Duration // wait for the clients to flush after the source is frozen
+}
+
+// PassStats counts what a pass did.
+type PassStats struct {
+	Pass        int       `json:"pass"`
+	Mode        string    `json:"mode"`
+	VerSeq      uint64    `json:"verSeq,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Dirs        int64     `json:"dirs"`
+	Files       int64     `json:"files"`
+	Symlinks    int64     `json:"symlinks"`
+	HardLinks   int64     `json:"hardLinks"`
+	Copied      int64     `json:"copied"`
+	CopiedBytes int64     `json:"copiedBytes"`
+	Removed     int64     `json:"removed"`
+	Errors      int64     `json:"errors"`
+	ErrMsgs     []string  `json:"errMsgs,omitempty"`
+}
+
+type volClient struct {
+	name string
+	mc   *masterSDK.MasterClient
+	mw   *meta.MetaWrapper
+	ec   *stream.ExtentClient
+}
+
+func newVolClient(masters []string, volName string) (c *volClient, err error) {
+	c = &volClient{name: volName, mc: masterSDK.NewMasterClient(masters, false)}
+	var view *proto.SimpleVolView
+	if view, err = c.mc.AdminAPI().GetVolumeSimpleInfo(volName); err != nil {
+		return nil, fmt.Errorf("get volume %v failed: %v", volName, err)
+	}
+	if !proto.IsHot(view.VolType) {
+		return nil, fmt.Errorf("volume %v is not a hot volume, only hot volumes can be migrated", volName)
+	}
+	if c.mw, err = meta.NewMetaWrapper(&meta.MetaConfig{
+		Volume:        volName,
+		Masters:       masters,
+		ValidateOwner: false,
+	}); err != nil {
+		return nil, fmt.Errorf("NewMetaWrapper of volume %v failed: %v", volName, err)
+	}
+	if c.ec, err = stream.NewExtentClient(&stream.ExtentConfig{
+		Volume:            volName,
+		VolumeType:        view.VolType,
+		Masters:           masters,
+		OnAppendExtentKey: c.mw.AppendExtentKey,
+		OnSplitExtentKey:  c.mw.SplitExtentKey,
+		OnGetExtents:      c.mw.GetExtents,
+		OnTruncate:        c.mw.Truncate,
+		DisableMetaCache:  true,
+	}); err != nil {
+		c.mw.Close()
+		return nil, fmt.Errorf("NewExtentClient of volume %v failed: %v", volName, err)
+	}
+	return
+}
+
+func (c *volClient) close() {
+	c.ec.Close()
+	c.mw.Close()
+}
+
+// Migrator copies a volume to a volume of another cluster. Every pass walks the source
+// namespace and brings the target in line with it, the first pass copies all the files,
+// the next ones only the changed files, and the cut-over runs a last pass with the source frozen.
+type Migrator struct {
+	cfg   Config
+	src   *volClient
+	dst   *volClient
+	state *State
+
+	stats     *PassStats
+	changes   *changeSet
+	hardlinks map[uint64]uint64 // source inode of the files with several links to the target inode
+	linkMu    sync.Mutex
+	copySem   chan struct{}
+	copyWg    sync.WaitGroup
+	errMu     sync.Mutex
+}
+
+func NewMigrator(cfg Config) (m *Migrator, err error) {
+	if cfg.Mode != ModeVersion && cfg.Mode != ModeMtime {
+		return nil, fmt.Errorf("unknown mode %v, %v or %v", cfg.Mode, ModeVersion, ModeMtime)
+	}
+	if cfg.CopyConcurrency <= 0 {
+		cfg.CopyConcurrency = defaultCopyConcurrency
+	}
+	m = &Migrator{cfg: cfg}
+	if m.state, err = loadState(cfg.StateFile); err != nil {
+		return nil, err
+	}
+	if m.state.SrcVolume != "" && (m.state.SrcVolume != cfg.SrcVolume || m.state.DstVolume != cfg.DstVolume) {
+		return nil, fmt.Errorf("state file %v belongs to the migration of %v to %v",
+			cfg.StateFile, m.state.SrcVolume, m.state.DstVolume)
+	}
+	m.state.SrcVolume, m.state.DstVolume = cfg.SrcVolume, cfg.DstVolume
+	if m.src, err = newVolClient(cfg.SrcMasters, cfg.SrcVolume); err != nil {
+		return nil, err
+	}
+	if m.dst, err = newVolClient(cfg.DstMasters, cfg.DstVolume); err != nil {
+		m.src.close()
+		return nil, err
+	}
+	return
+}
+
+func (m *Migrator) Close() {
+	m.src.close()
+	m.dst.close()
+}
+
+// changeSet tells which source inodes changed since the previous pass.
+type changeSet struct {
+	all    bool
+	since  int64           // mtime mode, unix seconds
+	inodes map[uint64]bool // version mode
+}
+
+func (cs *changeSet) changed(info *proto.InodeInfo) bool {
+	if cs.all {
+		return true
+	}
+	if cs.inodes != nil {
+		return cs.inodes[info.Inode]
+	}
+	return info.ModifyTime.Unix() >= cs.since || info.CreateTime.Unix() >= cs.since
+}
+
+// attrsChanged tells if the attributes or the xattrs of the inode may have changed,
+// chmod, chown and setxattr leave the mtime alone so they are always compared in mode mtime.
+func (cs *changeSet) attrsChanged(info *proto.InodeInfo) bool {
+	return cs.inodes == nil || cs.changed(info)
+}
+
+// takeVersion creates a snapshot version of the source and waits for it to be committed.
+func (m *Migrator) takeVersion() (verSeq uint64, err error) {
+	var ver *proto.VolVersionInfo
+	if ver, err = m.src.mc.AdminAPI().CreateVersion(m.src.name); err != nil {
+		return 0, fmt.Errorf("create version of %v failed: %v, snapshots must be enabled in mode %v", m.src.name, err, ModeVersion)
+	}
+	deadline := time.Now().Add(versionWaitTimeout)
+	for time.Now().Before(deadline) {
+		var list *proto.VolVersionInfoList
+		if list, err = m.src.mc.AdminAPI().GetVerList(m.src.name); err == nil {
+			n := len(list.VerList)
+			if n > 0 && list.VerList[n-1].Ver > ver.Ver && list.VerList[n-1].Status == proto.VersionNormal {
+				return ver.Ver, nil
+			}
+		}
+		time.Sleep(time.Second)
+	}
+	return 0, fmt.Errorf("version %v of %v is not committed in %v", ver.Ver, m.src.name, versionWaitTimeout)
+}
+
+func (m *Migrator) beginPass() (verSeq uint64, err error) {
+	m.changes = &changeSet{all: m.state.Passes == 0}
+	if m.cfg.Mode == ModeMtime {
+		m.changes.since = m.state.LastPassStart - mtimeSlack
+		return
+	}
+	if verSeq, err = m.takeVersion(); err != nil {
+		return
+	}
+	if m.changes.all || m.state.LastVerSeq == 0 {
+		m.changes.all = true
+		return
+	}
+	var diffs []proto.InodeVerDiff
+	if diffs, err = m.src.mw.InodeVerDiff_ll(m.state.LastVerSeq, verSeq); err != nil {
+		return verSeq, fmt.Errorf("diff versions %v and %v of %v failed: %v", m.state.LastVerSeq, verSeq, m.src.name, err)
+	}
+	m.changes.inodes = make(map[uint64]bool, len(diffs))
+	for _, diff := range diffs {
+		m.changes.inodes[diff.Inode] = true
+	}
+	log.LogInfof("beginPass: %v inodes changed between versions %v and %v", len(diffs), m.state.LastVerSeq, verSeq)
+	return
+}
+
+// deleteVersion deletes a snapshot version taken on the source by a pass.
+func (m *Migrator) deleteVersion(verSeq uint64) {
+	if verSeq == 0 {
+		return
+	}
+	if err := m.src.mc.AdminAPI().DeleteVersion(m.src.name, strconv.FormatUint(verSeq, 10)); err != nil {
+		log.LogWarnf("deleteVersion: delete version %v of %v failed: %v", verSeq, m.src.name, err)
+		return
+	}
+	log.LogInfof("deleteVersion: version %v of %v deleted", verSeq, m.src.name)
+}
+
+// Sync runs a pass, the state is only advanced if the pass has no error,
+// so the files failed to copy are checked again by the next pass.
+func (m *Migrator) Sync() (stats *PassStats, err error) {
+	start := time.Now()
+	m.stats = &PassStats{Pass: m.state.Passes + 1, Mode: m.cfg.Mode, Start: start}
+	m.hardlinks = make(map[uint64]uint64)
+	m.copySem = make(chan struct{}, m.cfg.CopyConcurrency)
+	stats = m.stats
+	stats.VerSeq, err = m.beginPass()
+	defer func() {
+		// the version of a failed pass is never diffed with
+		if err != nil {
+			m.deleteVersion(stats.VerSeq)
+		}
+	}()
+	if err != nil {
+		return
+	}
+	log.LogInfof("Sync: pass %v of %v to %v begins, mode %v full %v", stats.Pass, m.src.name, m.dst.name, m.cfg.Mode, m.changes.all)
+
+	m.syncDir(proto.RootIno, proto.RootIno, "/")
+	if err = m.syncInode(m.srcInode(proto.RootIno), proto.RootIno, "/", true); err != nil {
+		m.fail("/", err)
+	}
+	m.copyWg.Wait()
+	stats.End = time.Now()
+	if stats.Errors > 0 {
+		return stats, fmt.Errorf("pass %v has %v errors", stats.Pass, stats.Errors)
+	}
+
+	state := *m.state
+	state.Passes++
+	state.LastPassStart = start.Unix()
+	state.LastVerSeq = stats.VerSeq
+	if err = state.save(m.cfg.StateFile); err != nil {
+		return
+	}
+	lastVerSeq := m.state.LastVerSeq
+	*m.state = state
+	// only the newest version is needed for the next diff
+	m.deleteVersion(lastVerSeq)
+	log.LogInfof("Sync: pass %v finished, %+v", stats.Pass, stats)
+	return
+}
+
+func (m *Migrator) fail(path string, err error) {
+	log.LogErrorf("migrate %v failed: %v", path, err)
+	m.errMu.Lock()
+	defer m.errMu.Unlock()
+	m.stats.Errors++
+	if len(m.stats.ErrMsgs) < maxPassErrors {
+		m.stats.ErrMsgs = append(m.stats.ErrMsgs, fmt.Sprintf("%v: %v", path, err))
+	}
+}
+
+func (m *Migrator) srcInode(ino uint64) *proto.InodeInfo {
+	info, err := m.src.mw.InodeGet_ll(ino)
+	if err != nil {
+		return &proto.InodeInfo{Inode: ino}
+	}
+	return info
+}
+
+func inodeInfoMap(infos []*proto.InodeInfo) map[uint64]*proto.InodeInfo {
+	res := make(map[uint64]*proto.InodeInfo, len(infos))
+	for _, info := range infos {
+		res[info.Inode] = info
+	}
+	return res
+}
+
+func dentryInodes(dentries []proto.Dentry) []uint64 {
+	inodes := make([]uint64, 0, len(dentries))
+	for _, d := range dentries {
+		inodes = append(inodes, d.Inode)
+	}
+	return inodes
+}
+
+func fileType(mode uint32) os.FileMode {
+	return os.FileMode(mode) & os.ModeType
+}
+
+// dirEntry pairs a source dentry with the target dentry of the same name and type.
+type dirEntry struct {
+	src proto.Dentry
+	dst *proto.Dentry
+}
+
+// planDir matches the entries of a source directory with the target one, the target entries
+// absent from the source or of another type are to be removed.
+func planDir(src, dst []proto.Dentry) (entries []dirEntry, removes []proto.Dentry) {
+	dstByName := make(map[string]proto.Dentry, len(dst))
+	for _, d := range dst {
+		dstByName[d.Name] = d
+	}
+	srcNames := make(map[string]bool, len(src))
+	for _, s := range src {
+		srcNames[s.Name] = true
+		entry := dirEntry{src: s}
+		if d, ok := dstByName[s.Name]; ok {
+			if fileType(d.Type) == fileType(s.Type) {
+				entry.dst = &d
+			} else {
+				removes = append(removes, d)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	for _, d := range dst {
+		if !srcNames[d.Name] {
+			removes = append(removes, d)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].src.Name < entries[j].src.Name })
+	sort.Slice(removes, func(i, j int) bool { return removes[i].Name < removes[j].Name })
+	return
+}
+
+func (m *Migrator) syncDir(srcIno, dstIno uint64, path string) {
+	srcDentries, err := m.src.mw.ReadDir_ll(srcIno)
+	if err != nil {
+		m.fail(path, fmt.Errorf("read source dir: %v", err))
+		return
+	}
+	dstDentries, err := m.dst.mw.ReadDir_ll(dstIno)
+	if err != nil {
+		m.fail(path, fmt.Errorf("read target dir: %v", err))
+		return
+	}
+	entries, removes := planDir(srcDentries, dstDentries)
+	for _, d := range removes {
+		if err = m.removeTree(dstIno, d, gopath.Join(path, d.Name)); err != nil {
+			m.fail(gopath.Join(path, d.Name), fmt.Errorf("remove target: %v", err))
+		}
+	}
+	srcInfos := inodeInfoMap(m.src.mw.BatchInodeGet(dentryInodes(srcDentries)))
+	for _, e := range entries {
+		childPath := gopath.Join(path, e.src.Name)
+		info, ok := srcInfos[e.src.Inode]
+		if !ok {
+			// removed since the dir was read
+			continue
+		}
+		if err = m.syncEntry(dstIno, e, info, childPath); err != nil {
+			m.fail(childPath, err)
+		}
+	}
+	atomic.AddInt64(&m.stats.Dirs, 1)
+}
+
+func (m *Migrator) syncEntry(dstParent uint64, e dirEntry, info *proto.InodeInfo, path string) (err error) {
+	var (
+		dstIno  uint64
+		created bool
+	)
+	if e.dst != nil {
+		dstIno = e.dst.Inode
+	}
+	switch {
+	case proto.IsDir(info.Mode):
+		if e.dst == nil {
+			if dstIno, err = m.create(dstParent, e.src.Name, info, path); err != nil {
+				return
+			}
+		}
+		m.syncDir(info.Inode, dstIno, path)
+		return m.syncInode(info, dstIno, path, true)
+	case proto.IsSymlink(info.Mode):
+		atomic.AddInt64(&m.stats.Symlinks, 1)
+		if e.dst != nil {
+			var dstInfo *proto.InodeInfo
+			if dstInfo, err = m.dst.mw.InodeGet_ll(dstIno); err != nil {
+				return
+			}
+			if string(dstInfo.Target) != string(info.Target) {
+				if err = m.removeTree(dstParent, *e.dst, path); err != nil {
+					return
+				}
+				e.dst = nil
+			}
+		}
+		if e.dst == nil {
+			if dstIno, err = m.create(dstParent, e.src.Name, info, path); err != nil {
+				return
+			}
+			created = true
+		}
+		return m.syncInode(info, dstIno, path, created || m.changes.attrsChanged(info))
+	}
+
+	atomic.AddInt64(&m.stats.Files, 1)
+	if info.Nlink > 1 && proto.IsRegular(info.Mode) {
+		var linked bool
+		if dstIno, linked, err = m.syncHardLink(dstParent, e, info, path); err != nil || linked {
+			return
+		}
+	}
+	if dstIno == 0 {
+		if dstIno, err = m.create(dstParent, e.src.Name, info, path); err != nil {
+			return
+		}
+		created = true
+		m.setHardLink(info, dstIno)
+	}
+	if !proto.IsRegular(info.Mode) {
+		return m.syncInode(info, dstIno, path, created || m.changes.attrsChanged(info))
+	}
+	needCopy := created || m.changes.changed(info)
+	if !needCopy {
+		var dstInfo *proto.InodeInfo
+		if dstInfo, err = m.dst.mw.InodeGet_ll(dstIno); err != nil {
+			return
+		}
+		needCopy = dstInfo.Size != info.Size
+	}
+	if !needCopy {
+		if m.changes.attrsChanged(info) {
+			return m.syncInode(info, dstIno, path, true)
+		}
+		return
+	}
+	m.copySem <- struct{}{}
+	m.copyWg.Add(1)
+	go func() {
+		defer func() {
+			<-m.copySem
+			m.copyWg.Done()
+		}()
+		if err := m.copyFile(info, dstParent, dstIno, path, !created); err != nil {
+			m.fail(path, err)
+			return
+		}
+		if err := m.syncInode(info, dstIno, path, true); err != nil {
+			m.fail(path, err)
+		}
+	}()
+	return
+}
+
+func (m *Migrator) setHardLink(info *proto.InodeInfo, dstIno uint64) {
+	if info.Nlink <= 1 || !proto.IsRegular(info.Mode) {
+		return
+	}
+	m.linkMu.Lock()
+	defer m.linkMu.Unlock()
+	if _, ok := m.hardlinks[info.Inode]; !ok {
+		m.hardlinks[info.Inode] = dstIno
+	}
+}
+
+// syncHardLink links the entry to the target inode of an other name of the same source inode met earlier in the pass.
+func (m *Migrator) syncHardLink(dstParent uint64, e dirEntry, info *proto.InodeInfo, path string) (dstIno uint64, linked bool, err error) {
+	m.linkMu.Lock()
+	first, ok := m.hardlinks[info.Inode]
+	if !ok && e.dst != nil {
+		m.hardlinks[info.Inode] = e.dst.Inode
+	}
+	m.linkMu.Unlock()
+	if !ok {
+		if e.dst != nil {
+			dstIno = e.dst.Inode
+		}
+		return
+	}
+	atomic.AddInt64(&m.stats.HardLinks, 1)
+	if e.dst != nil {
+		if e.dst.Inode == first {
+			return first, true, nil
+		}
+		if err = m.removeTree(dstParent, *e.dst, path); err != nil {
+			return
+		}
+	}
+	if _, err = m.dst.mw.Link(dstParent, e.src.Name, first, path); err != nil {
+		return 0, false, fmt.Errorf("link to target inode %v: %v", first, err)
+	}
+	return first, true, nil
+}
+
+func (m *Migrator) create(dstParent uint64, name string, info *proto.InodeInfo, path string) (uint64, error) {
+	dstInfo, err := m.dst.mw.Create_ll(dstParent, name, info.Mode, info.Uid, info.Gid, info.Target, path)
+	if err != nil {
+		return 0, fmt.Errorf("create target: %v", err)
+	}
+	return dstInfo.Inode, nil
+}
+
+// removeTree removes a target entry, and all its children for a directory.
+func (m *Migrator) removeTree(dstParent uint64, d proto.Dentry, path string) (err error) {
+	isDir := proto.IsDir(d.Type)
+	if isDir {
+		var children []proto.Dentry
+		if children, err = m.dst.mw.ReadDir_ll(d.Inode); err != nil {
+			return
+		}
+		for _, child := range children {
+			if err = m.removeTree(d.Inode, child, gopath.Join(path, child.Name)); err != nil {
+				return
+			}
+		}
+	}
+	info, err := m.dst.mw.Delete_ll(dstParent, d.Name, isDir, path)
+	if err != nil {
+		return
+	}
+	if info != nil && info.Nlink == 0 && !isDir {
+		if err = m.dst.mw.Evict(info.Inode, path); err != nil {
+			return
+		}
+	}
+	atomic.AddInt64(&m.stats.Removed, 1)
+	return
+}
+
+// syncInode copies the attributes and the xattrs of a source inode to the target one.
+func (m *Migrator) syncInode(info *proto.InodeInfo, dstIno uint64, path string, withXAttrs bool) (err error) {
+	if withXAttrs {
+		if err = m.syncXAttrs(info.Inode, dstIno); err != nil {
+			return fmt.Errorf("sync xattrs: %v", err)
+		}
+	}
+	dstInfo, err := m.dst.mw.InodeGet_ll(dstIno)
+	if err != nil {
+		return
+	}
+	if dstInfo.Mode == info.Mode && dstInfo.Uid == info.Uid && dstInfo.Gid == info.Gid &&
+		dstInfo.ModifyTime.Unix() == info.ModifyTime.Unix() {
+		return
+	}
+	valid := proto.AttrMode | proto.AttrUid | proto.AttrGid | proto.AttrModifyTime | proto.AttrAccessTime
+	if err = m.dst.mw.Setattr(dstIno, valid, info.Mode, info.Uid, info.Gid, info.AccessTime.Unix(), info.ModifyTime.Unix()); err != nil {
+		return fmt.Errorf("set attr: %v", err)
+	}
+	return
+}
+
+// diffXAttrs returns the xattrs to set and to delete to turn dst into src.
+func diffXAttrs(src, dst map[string]string) (set map[string]string, del []string) {
+	set = make(map[string]string)
+	for k, v := range src {
+		if dv, ok := dst[k]; !ok || dv != v {
+			set[k] = v
+		}
+	}
+	for k := range dst {
+		if _, ok := src[k]; !ok {
+			del = append(del, k)
+		}
+	}
+	sort.Strings(del)
+	return
+}
+
+func (m *Migrator) syncXAttrs(srcIno, dstIno uint64) (err error) {
+	srcXAttrs, err := m.src.mw.XAttrGetAll_ll(srcIno)
+	if err != nil {
+		return
+	}
+	dstXAttrs, err := m.dst.mw.XAttrGetAll_ll(dstIno)
+	if err != nil {
+		return
+	}
+	set, del := diffXAttrs(srcXAttrs.XAttrs, dstXAttrs.XAttrs)
+	if len(set) > 0 {
+		if err = m.dst.mw.BatchSetXAttr_ll(dstIno, set); err != nil {
+			return
+		}
+	}
+	for _, name := range del {
+		if err = m.dst.mw.XAttrDel_ll(dstIno, name); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (m *Migrator) copyFile(info *proto.InodeInfo, dstParent, dstIno uint64, path string, truncate bool) (err error) {
+	if err = m.src.ec.OpenStream(info.Inode); err != nil {
+		return
+	}
+	defer m.src.ec.CloseStream(info.Inode)
+	if err = m.dst.ec.OpenStream(dstIno); err != nil {
+		return
+	}
+	defer m.dst.ec.CloseStream(dstIno)
+	m.dst.ec.GetStreamer(dstIno).SetParentInode(dstParent)
+
+	if truncate {
+		if err = m.dst.ec.Truncate(m.dst.mw, dstParent, dstIno, 0, path); err != nil {
+			return fmt.Errorf("truncate target: %v", err)
+		}
+	}
+	buf := make([]byte, copyBufferSize)
+	var offset int
+	for {
+		n, rerr := m.src.ec.Read(info.Inode, buf, offset, len(buf))
+		if rerr != nil && rerr != io.EOF {
+			return fmt.Errorf("read source at %v: %v", offset, rerr)
+		}
+		if n > 0 {
+			if _, err = m.dst.ec.Write(dstIno, offset, buf[:n], 0, nil); err != nil {
+				return fmt.Errorf("write target at %v: %v", offset, err)
+			}
+			offset += n
+		}
+		if n == 0 || rerr == io.EOF {
+			break
+		}
+	}
+	if err = m.dst.ec.Flush(dstIno); err != nil {
+		return fmt.Errorf("flush target: %v", err)
+	}
+	atomic.AddInt64(&m.stats.Copied, 1)
+	atomic.AddInt64(&m.stats.CopiedBytes, int64(offset))
+	return
+}
+
+// SyncQuotas creates the quotas of the source on the target, on the same paths and with the same limits.
+func (m *Migrator) SyncQuotas() (err error) {
+	srcQuotas, err := m.src.mc.AdminAPI().ListQuota(m.src.name)
+	if err != nil {
+		return fmt.Errorf("list quotas of %v failed: %v", m.src.name, err)
+	}
+	dstQuotas, err := m.dst.mc.AdminAPI().ListQuota(m.dst.name)
+	if err != nil {
+		return fmt.Errorf("list quotas of %v failed: %v", m.dst.name, err)
+	}
+	dstByPaths := make(map[string]*proto.QuotaInfo, len(dstQuotas))
+	for _, q := range dstQuotas {
+		dstByPaths[quotaPathsKey(q)] = q
+	}
+	for _, q := range srcQuotas {
+		if dq, ok := dstByPaths[quotaPathsKey(q)]; ok {
+			if dq.MaxFiles != q.MaxFiles || dq.MaxBytes != q.MaxBytes {
+				if err = m.dst.mc.AdminAPI().UpdateQuota(m.dst.name, strconv.FormatUint(uint64(dq.QuotaId), 10), q.MaxFiles, q.MaxBytes); err != nil {
+					return fmt.Errorf("update quota %v of %v failed: %v", dq.QuotaId, m.dst.name, err)
+				}
+			}
+			continue
+		}
+		pathInfos := make([]proto.QuotaPathInfo, 0, len(q.PathInfos))
+		for _, pi := range q.PathInfos {
+			var ino uint64
+			if ino, err = m.dst.mw.LookupPath(pi.FullPath); err != nil {
+				return fmt.Errorf("lookup quota path %v on %v failed: %v", pi.FullPath, m.dst.name, err)
+			}
+			mp := m.dst.mw.GetPartitionByInodeId_ll(ino)
+			if mp == nil {
+				return fmt.Errorf("no meta partition of inode %v on %v", ino, m.dst.name)
+			}
+			pathInfos = append(pathInfos, proto.QuotaPathInfo{FullPath: pi.FullPath, RootInode: ino, PartitionId: mp.PartitionID})
+		}
+		var quotaID uint32
+		if quotaID, err = m.dst.mc.AdminAPI().CreateQuota(m.dst.name, pathInfos, q.MaxFiles, q.MaxBytes); err != nil {
+			return fmt.Errorf("create quota on %v failed: %v", quotaPathsKey(q), err)
+		}
+		// the quota only applies to the inodes created after it, tag the copied ones
+		for _, pi := range pathInfos {
+			if _, err = m.dst.mw.ApplyQuota_ll(pi.RootInode, quotaID, 0); err != nil {
+				return fmt.Errorf("apply quota %v on %v failed: %v", quotaID, pi.FullPath, err)
+			}
+		}
+		log.LogInfof("SyncQuotas: quota of %v created as %v on %v", quotaPathsKey(q), quotaID, m.dst.name)
+	}
+	return
+}
+
+func quotaPathsKey(q *proto.QuotaInfo) string {
+	paths := make([]string, 0, len(q.PathInfos))
+	for _, pi := range q.PathInfos {
+		paths = append(paths, pi.FullPath)
+	}
+	sort.Strings(paths)
+	return fmt.Sprintf("%v", paths)
+}
+
+// Cutover freezes the source, runs a last pass, copies the quotas and verifies the target.
+// The source is unfrozen if any step fails, and stays frozen once the target is verified,
+// for the clients to be moved to the target. The version pinned by the last pass is
+// deleted either way, a later pass after a failed cut-over walks the whole source.
+func (m *Migrator) Cutover() (stats *PassStats, report *VerifyReport, err error) {
+	if err = m.src.mc.AdminAPI().SetVolumeForbidden(m.src.name, true); err != nil {
+		return nil, nil, fmt.Errorf("freeze %v failed: %v", m.src.name, err)
+	}
+	log.LogWarnf("Cutover: volume %v is frozen", m.src.name)
+	defer m.dropLastVersion()
+	defer func() {
+		if err == nil {
+			return
+		}
+		if e := m.src.mc.AdminAPI().SetVolumeForbidden(m.src.name, false); e != nil {
+			log.LogErrorf("Cutover: unfreeze %v failed: %v", m.src.name, e)
+			err = fmt.Errorf("%v, and unfreeze %v failed: %v", err, m.src.name, e)
+			return
+		}
+		log.LogWarnf("Cutover: volume %v is unfrozen", m.src.name)
+	}()
+	time.Sleep(m.cfg.DrainTime)
+
+	if stats, err = m.Sync(); err != nil {
+		return
+	}
+	if err = m.SyncQuotas(); err != nil {
+		return
+	}
+	if report, err = m.Verify(); err != nil {
+		return
+	}
+	if report.DiffCount > 0 {
+		err = fmt.Errorf("verification found %v differences", report.DiffCount)
+	}
+	return
+}
+
+// dropLastVersion deletes the version pinned on the source for the next diff.
+func (m *Migrator) dropLastVersion() {
+	if m.state.LastVerSeq == 0 {
+		return
+	}
+	verSeq := m.state.LastVerSeq
+	m.state.LastVerSeq = 0
+	if err := m.state.save(m.cfg.StateFile); err != nil {
+		// the state file still refers to the version, it is kept for the next diff
+		m.state.LastVerSeq = verSeq
+		log.LogWarnf("dropLastVersion: save state failed: %v", err)
+		return
+	}
+	m.deleteVersion(verSeq)
+}
+
+// Migrate runs catch-up passes until a pass copies at most catchUpThreshold files
+// or maxPasses passes are done, then cuts over.
+func (m *Migrator) Migrate(maxPasses int, catchUpThreshold int64) (stats *PassStats, report *VerifyReport, err error) {
+	for i := 0; i < maxPasses; i++ {
+		if stats, err = m.Sync(); err != nil {
+			log.LogWarnf("Migrate: pass %v failed: %v", stats.Pass, err)
+			continue
+		}
+		if stats.Copied <= catchUpThreshold {
+			break
+		}
+	}
+	return m.Cutover()
+}
+
+func InitLog(dir, level string) (err error) {
+	if dir == "" {
+		return
+	}
+	_, err = log.InitLog(dir, "migrate", convertLogLevel(level), nil, log.DefaultLogLeftSpaceLimit)
+	return
+}
+
+func FlushLog() {
+	log.LogFlush()
+}
+
+func convertLogLevel(level string) log.Level {
+	switch level {
+	case "debug":
+		return log.DebugLevel
+	case "info":
+		return log.InfoLevel
+	case "warn":
+		return log.WarnLevel
+	case "error":
+		return log.ErrorLevel
+	default:
+		return log.InfoLevel
+	}
+}
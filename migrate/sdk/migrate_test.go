@@ -0,0 +1,142 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sdk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanDir(t *testing.T) {
+	src := []proto.Dentry{
+		{Name: "a", Inode: 10, Type: uint32(os.ModeDir)},
+		{Name: "b", Inode: 11, Type: 0o644},
+		{Name: "c", Inode: 12, Type: uint32(os.ModeSymlink)},
+		{Name: "d", Inode: 13, Type: 0o644},
+	}
+	dst := []proto.Dentry{
+		{Name: "a", Inode: 20, Type: uint32(os.ModeDir)},
+		{Name: "c", Inode: 22, Type: 0o644},
+		{Name: "d", Inode: 23, Type: 0o600},
+		{Name: "e", Inode: 24, Type: 0o644},
+	}
+	entries, removes := planDir(src, dst)
+	require.Len(t, entries, 4)
+	require.Equal(t, uint64(20), entries[0].dst.Inode)
+	require.Nil(t, entries[1].dst)
+	require.Nil(t, entries[2].dst, "type changed from file to symlink")
+	require.Equal(t, uint64(23), entries[3].dst.Inode, "permission bits are synced by setattr")
+	require.Len(t, removes, 2)
+	require.Equal(t, "c", removes[0].Name)
+	require.Equal(t, "e", removes[1].Name)
+}
+
+func TestDiffXAttrs(t *testing.T) {
+	set, del := diffXAttrs(
+		map[string]string{"user.a": "1", "user.b": "2", "user.c": "3"},
+		map[string]string{"user.a": "1", "user.b": "0", "user.d": "4", "user.e": "5"})
+	require.Equal(t, map[string]string{"user.b": "2", "user.c": "3"}, set)
+	require.Equal(t, []string{"user.d", "user.e"}, del)
+
+	set, del = diffXAttrs(map[string]string{}, nil)
+	require.Empty(t, set)
+	require.Empty(t, del)
+}
+
+func TestChangeSet(t *testing.T) {
+	since := time.Unix(1000, 0)
+	old := &proto.InodeInfo{Inode: 1, ModifyTime: since.Add(-time.Second), CreateTime: since.Add(-time.Hour)}
+	modified := &proto.InodeInfo{Inode: 2, ModifyTime: since, CreateTime: since.Add(-time.Hour)}
+	// a file moved in with an old mtime, e.g. by cp -p or tar
+	created := &proto.InodeInfo{Inode: 3, ModifyTime: since.Add(-time.Hour), CreateTime: since.Add(time.Second)}
+
+	cs := &changeSet{all: true}
+	require.True(t, cs.changed(old))
+
+	cs = &changeSet{since: since.Unix()}
+	require.False(t, cs.changed(old))
+	require.True(t, cs.changed(modified))
+	require.True(t, cs.changed(created))
+
+	cs = &changeSet{inodes: map[uint64]bool{1: true}}
+	require.True(t, cs.changed(old))
+	require.False(t, cs.changed(modified))
+	require.True(t, cs.attrsChanged(old))
+	require.False(t, cs.attrsChanged(modified))
+}
+
+func TestXAttrOnlyChangeInMtimeMode(t *testing.T) {
+	// setxattr leaves the mtime before the previous pass
+	since := time.Unix(1000, 0)
+	info := &proto.InodeInfo{Inode: 1, ModifyTime: since.Add(-time.Hour), CreateTime: since.Add(-time.Hour)}
+	cs := &changeSet{since: since.Unix()}
+	require.False(t, cs.changed(info), "the data is not copied again")
+	require.True(t, cs.attrsChanged(info), "the xattrs are compared")
+
+	set, del := diffXAttrs(map[string]string{"user.a": "2"}, map[string]string{"user.a": "1"})
+	require.Equal(t, map[string]string{"user.a": "2"}, set)
+	require.Empty(t, del)
+}
+
+func TestState(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "vol.state")
+	state, err := loadState(file)
+	require.NoError(t, err)
+	require.Equal(t, 0, state.Passes)
+
+	state.SrcVolume, state.DstVolume = "src", "dst"
+	state.Passes = 3
+	state.LastPassStart = 1700000000
+	state.LastVerSeq = 42
+	require.NoError(t, state.save(file))
+	loaded, err := loadState(file)
+	require.NoError(t, err)
+	require.Equal(t, state, loaded)
+
+	require.NoError(t, os.WriteFile(file, []byte("{"), 0o644))
+	_, err = loadState(file)
+	require.Error(t, err)
+}
+
+func TestCompareInodes(t *testing.T) {
+	mtime := time.Unix(1000, 0)
+	src := &proto.InodeInfo{Mode: 0o644, Uid: 1, Gid: 1, Size: 10, ModifyTime: mtime}
+	r := &VerifyReport{}
+	compareInodes(r, "/f", src, &proto.InodeInfo{Mode: 0o644, Uid: 1, Gid: 1, Size: 10, ModifyTime: mtime})
+	require.Zero(t, r.DiffCount)
+
+	compareInodes(r, "/f", src, &proto.InodeInfo{Mode: 0o600, Uid: 2, Gid: 1, Size: 11, ModifyTime: mtime.Add(time.Second)})
+	require.Equal(t, int64(4), r.DiffCount)
+	kinds := make([]string, 0)
+	for _, d := range r.Diffs {
+		kinds = append(kinds, d.Kind)
+	}
+	require.Equal(t, []string{DiffMode, DiffOwner, DiffSize, DiffMtime}, kinds)
+
+	r = &VerifyReport{}
+	compareInodes(r, "/l", &proto.InodeInfo{Mode: uint32(os.ModeSymlink | 0o777), Target: []byte("a")},
+		&proto.InodeInfo{Mode: uint32(os.ModeSymlink | 0o777), Target: []byte("b")})
+	require.Equal(t, int64(1), r.DiffCount)
+	require.Equal(t, DiffSymlink, r.Diffs[0].Kind)
+
+	r = &VerifyReport{}
+	compareInodes(r, "/d", &proto.InodeInfo{Mode: uint32(os.ModeDir | 0o755)}, src)
+	require.Equal(t, DiffType, r.Diffs[0].Kind)
+}
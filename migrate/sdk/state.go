@@ -0,0 +1,70 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// State is the progress of a migration, kept in a file so that the catch-up passes
+// of a later run only copy what changed since the last successful pass.
+type State struct {
+	SrcVolume     string `json:"srcVolume"`
+	DstVolume     string `json:"dstVolume"`
+	Passes        int    `json:"passes"`
+	LastPassStart int64  `json:"lastPassStart"` // unix seconds
+	LastVerSeq    uint64 `json:"lastVerSeq"`
+}
+
+func loadState(file string) (state *State, err error) {
+	state = &State{}
+	if file == "" {
+		return
+	}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state file %v failed: %v", file, err)
+	}
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parse state file %v failed: %v", file, err)
+	}
+	log.LogInfof("loadState: %+v", state)
+	return
+}
+
+func (s *State) save(file string) (err error) {
+	if file == "" {
+		return
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return
+	}
+	tmp := file + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write state file %v failed: %v", tmp, err)
+	}
+	if err = os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("rename state file %v failed: %v", tmp, err)
+	}
+	return
+}
@@ -0,0 +1,268 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sdk
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io"
+	gopath "path"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	DiffMissing = "missing"
+	DiffExtra   = "extra"
+	DiffType    = "type"
+	DiffSize    = "size"
+	DiffMode    = "mode"
+	DiffOwner   = "owner"
+	DiffMtime   = "mtime"
+	DiffSymlink = "symlink"
+	DiffXAttr   = "xattr"
+	DiffData    = "data"
+	DiffQuota   = "quota"
+
+	maxReportDiffs = 1000
+)
+
+type Diff struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// VerifyReport is the result of comparing the target volume with the source one.
+type VerifyReport struct {
+	SrcVolume  string    `json:"srcVolume"`
+	DstVolume  string    `json:"dstVolume"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Dirs       int64     `json:"dirs"`
+	Files      int64     `json:"files"`
+	Bytes      uint64    `json:"bytes"`
+	Quotas     int       `json:"quotas"`
+	DataVerify bool      `json:"dataVerify"`
+	DiffCount  int64     `json:"diffCount"`
+	Diffs      []Diff    `json:"diffs"` // the first diffs only
+}
+
+func (r *VerifyReport) add(path, kind, detail string) {
+	r.DiffCount++
+	if len(r.Diffs) < maxReportDiffs {
+		r.Diffs = append(r.Diffs, Diff{Path: path, Kind: kind, Detail: detail})
+	}
+}
+
+// compareInodes appends the metadata differences of two inodes of the same path.
+func compareInodes(r *VerifyReport, path string, src, dst *proto.InodeInfo) {
+	if fileType(src.Mode) != fileType(dst.Mode) {
+		r.add(path, DiffType, fmt.Sprintf("%v != %v", fileType(src.Mode), fileType(dst.Mode)))
+		return
+	}
+	if src.Mode != dst.Mode {
+		r.add(path, DiffMode, fmt.Sprintf("%o != %o", src.Mode, dst.Mode))
+	}
+	if src.Uid != dst.Uid || src.Gid != dst.Gid {
+		r.add(path, DiffOwner, fmt.Sprintf("%v:%v != %v:%v", src.Uid, src.Gid, dst.Uid, dst.Gid))
+	}
+	if proto.IsSymlink(src.Mode) {
+		if string(src.Target) != string(dst.Target) {
+			r.add(path, DiffSymlink, fmt.Sprintf("%s != %s", src.Target, dst.Target))
+		}
+		return
+	}
+	if proto.IsDir(src.Mode) {
+		return
+	}
+	if src.Size != dst.Size {
+		r.add(path, DiffSize, fmt.Sprintf("%v != %v", src.Size, dst.Size))
+	}
+	if src.ModifyTime.Unix() != dst.ModifyTime.Unix() {
+		r.add(path, DiffMtime, fmt.Sprintf("%v != %v", src.ModifyTime.Unix(), dst.ModifyTime.Unix()))
+	}
+}
+
+// Verify walks both volumes and reports every difference of the namespace, the attributes,
+// the xattrs and the quotas, and of the file contents if VerifyData is set.
+func (m *Migrator) Verify() (report *VerifyReport, err error) {
+	report = &VerifyReport{
+		SrcVolume:  m.src.name,
+		DstVolume:  m.dst.name,
+		Start:      time.Now(),
+		DataVerify: m.cfg.VerifyData,
+		Diffs:      make([]Diff, 0),
+	}
+	if err = m.verifyDir(report, proto.RootIno, proto.RootIno, "/"); err != nil {
+		return
+	}
+	if err = m.verifyQuotas(report); err != nil {
+		return
+	}
+	report.End = time.Now()
+	log.LogInfof("Verify: %v to %v, dirs %v files %v diffs %v", m.src.name, m.dst.name, report.Dirs, report.Files, report.DiffCount)
+	return
+}
+
+func (m *Migrator) verifyDir(r *VerifyReport, srcIno, dstIno uint64, path string) (err error) {
+	srcDentries, err := m.src.mw.ReadDir_ll(srcIno)
+	if err != nil {
+		return fmt.Errorf("read source dir %v: %v", path, err)
+	}
+	dstDentries, err := m.dst.mw.ReadDir_ll(dstIno)
+	if err != nil {
+		return fmt.Errorf("read target dir %v: %v", path, err)
+	}
+	r.Dirs++
+	dstByName := make(map[string]proto.Dentry, len(dstDentries))
+	for _, d := range dstDentries {
+		dstByName[d.Name] = d
+	}
+	srcNames := make(map[string]bool, len(srcDentries))
+	for _, s := range srcDentries {
+		srcNames[s.Name] = true
+	}
+	for _, d := range dstDentries {
+		if !srcNames[d.Name] {
+			r.add(gopath.Join(path, d.Name), DiffExtra, "")
+		}
+	}
+
+	srcInfos := inodeInfoMap(m.src.mw.BatchInodeGet(dentryInodes(srcDentries)))
+	dstInfos := inodeInfoMap(m.dst.mw.BatchInodeGet(dentryInodes(dstDentries)))
+	for _, s := range srcDentries {
+		childPath := gopath.Join(path, s.Name)
+		d, ok := dstByName[s.Name]
+		if !ok {
+			r.add(childPath, DiffMissing, "")
+			continue
+		}
+		srcInfo, dstInfo := srcInfos[s.Inode], dstInfos[d.Inode]
+		if srcInfo == nil || dstInfo == nil {
+			return fmt.Errorf("get inodes of %v failed", childPath)
+		}
+		compareInodes(r, childPath, srcInfo, dstInfo)
+		if fileType(srcInfo.Mode) != fileType(dstInfo.Mode) {
+			continue
+		}
+		if err = m.verifyXAttrs(r, childPath, s.Inode, d.Inode); err != nil {
+			return
+		}
+		if proto.IsDir(srcInfo.Mode) {
+			if err = m.verifyDir(r, s.Inode, d.Inode, childPath); err != nil {
+				return
+			}
+			continue
+		}
+		if !proto.IsRegular(srcInfo.Mode) {
+			continue
+		}
+		r.Files++
+		r.Bytes += srcInfo.Size
+		if m.cfg.VerifyData && srcInfo.Size == dstInfo.Size {
+			if err = m.verifyData(r, childPath, s.Inode, d.Inode); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+func (m *Migrator) verifyXAttrs(r *VerifyReport, path string, srcIno, dstIno uint64) (err error) {
+	srcXAttrs, err := m.src.mw.XAttrGetAll_ll(srcIno)
+	if err != nil {
+		return fmt.Errorf("get source xattrs of %v: %v", path, err)
+	}
+	dstXAttrs, err := m.dst.mw.XAttrGetAll_ll(dstIno)
+	if err != nil {
+		return fmt.Errorf("get target xattrs of %v: %v", path, err)
+	}
+	set, del := diffXAttrs(srcXAttrs.XAttrs, dstXAttrs.XAttrs)
+	for k := range set {
+		r.add(path, DiffXAttr, k)
+	}
+	for _, k := range del {
+		r.add(path, DiffXAttr, k)
+	}
+	return
+}
+
+func (m *Migrator) fileChecksum(c *volClient, ino uint64) (sum uint32, err error) {
+	if err = c.ec.OpenStream(ino); err != nil {
+		return
+	}
+	defer c.ec.CloseStream(ino)
+	hash := crc32.NewIEEE()
+	buf := make([]byte, copyBufferSize)
+	var offset int
+	for {
+		n, rerr := c.ec.Read(ino, buf, offset, len(buf))
+		if rerr != nil && rerr != io.EOF {
+			return 0, rerr
+		}
+		hash.Write(buf[:n])
+		offset += n
+		if n == 0 || rerr == io.EOF {
+			break
+		}
+	}
+	return hash.Sum32(), nil
+}
+
+func (m *Migrator) verifyData(r *VerifyReport, path string, srcIno, dstIno uint64) (err error) {
+	srcSum, err := m.fileChecksum(m.src, srcIno)
+	if err != nil {
+		return fmt.Errorf("read source %v: %v", path, err)
+	}
+	dstSum, err := m.fileChecksum(m.dst, dstIno)
+	if err != nil {
+		return fmt.Errorf("read target %v: %v", path, err)
+	}
+	if srcSum != dstSum {
+		r.add(path, DiffData, fmt.Sprintf("crc32 %08x != %08x", srcSum, dstSum))
+	}
+	return
+}
+
+func (m *Migrator) verifyQuotas(r *VerifyReport) (err error) {
+	srcQuotas, err := m.src.mc.AdminAPI().ListQuota(m.src.name)
+	if err != nil {
+		return fmt.Errorf("list quotas of %v: %v", m.src.name, err)
+	}
+	dstQuotas, err := m.dst.mc.AdminAPI().ListQuota(m.dst.name)
+	if err != nil {
+		return fmt.Errorf("list quotas of %v: %v", m.dst.name, err)
+	}
+	r.Quotas = len(srcQuotas)
+	dstByPaths := make(map[string]*proto.QuotaInfo, len(dstQuotas))
+	for _, q := range dstQuotas {
+		dstByPaths[quotaPathsKey(q)] = q
+	}
+	for _, q := range srcQuotas {
+		key := quotaPathsKey(q)
+		dq, ok := dstByPaths[key]
+		if !ok {
+			r.add(key, DiffQuota, "missing")
+			continue
+		}
+		if dq.MaxFiles != q.MaxFiles || dq.MaxBytes != q.MaxBytes {
+			r.add(key, DiffQuota, fmt.Sprintf("limits %v/%v != %v/%v", q.MaxFiles, q.MaxBytes, dq.MaxFiles, dq.MaxBytes))
+		}
+	}
+	return
+}
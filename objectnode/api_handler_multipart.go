@@ -250,6 +250,7 @@ func (o *ObjectNode) uploadPartHandler(w http.ResponseWriter, r *http.Request) {
 	} else {
 		reader = r.Body
 	}
+	reader = o.tenantQos.Reader(vol.Name(), reader)
 
 	// Write Part
 	start := time.Now()
@@ -399,6 +400,7 @@ func (o *ObjectNode) uploadPartCopyHandler(w http.ResponseWriter, r *http.Reques
 	} else {
 		rd = reader
 	}
+	rd = o.tenantQos.Reader(vol.Name(), rd)
 	start = time.Now()
 	fsFileInfo, err := vol.WritePart(param.Object(), uploadId, partNumberInt, rd)
 	span.AppendTrackLog("part.w", start, err)
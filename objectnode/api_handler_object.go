@@ -287,6 +287,7 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 	} else {
 		writer = w
 	}
+	writer = o.tenantQos.Writer(vol.Name(), writer)
 
 	// read file
 	start = time.Now()
@@ -1375,6 +1376,7 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 	} else {
 		reader = r.Body
 	}
+	reader = o.tenantQos.Reader(vol.Name(), reader)
 
 	// Put Object
 	opt := &PutFileOption{
@@ -1604,6 +1606,7 @@ func (o *ObjectNode) postObjectHandler(w http.ResponseWriter, r *http.Request) {
 	} else {
 		reader = f
 	}
+	reader = o.tenantQos.Reader(vol.Name(), reader)
 
 	// put object
 	putOpt := &PutFileOption{
@@ -71,8 +71,7 @@ func (s *CacheUserInfoStore) LoadUser(accessKey string) (*proto.UserInfo, error)
 	return s.selectLoader(accessKey).LoadUser(accessKey)
 }
 
-func NewUserInfoStore(masters []string, strict bool) UserInfoStore {
-	mc := master.NewMasterClient(masters, false)
+func NewUserInfoStore(mc *master.MasterClient, strict bool) UserInfoStore {
 	if strict {
 		return &StrictUserInfoStore{
 			mc: mc,
@@ -70,6 +70,17 @@ const (
 	//		}
 	configMasterAddr = proto.MasterAddr
 
+	// String configuration items, the keys of the master user signing the volume, lifecycle and user key
+	// requests of the ObjectNode, needed once the master checks the role of the callers. The user must be an
+	// admin, the master replies the secret keys of the other users to admins only.
+	// Example:
+	//		{
+	//			"masterAccessKey": "39bEF4RrAQgMj6RV",
+	//			"masterSecretKey": "TRL6o3JL16YOqvZGIohBDFTHZDEcFsyd"
+	//		}
+	configMasterAccessKey = "masterAccessKey"
+	configMasterSecretKey = "masterSecretKey"
+
 	// A bool type configuration is used to ensure that the topology information is consistent with the cluster
 	// in real time during the compatibility test. If true, the object node will not cache user information and
 	// volume topology. This configuration will cause a drastic decrease in performance after being turned on,
@@ -205,6 +216,8 @@ type ObjectNode struct {
 	rateLimit               RateLimiter
 	limitMutex              sync.RWMutex
 	disableCreateBucketByS3 bool
+
+	tenantQos *TenantQosLimiter // flow limits of the volumes under tenant qos, assigned by master
 }
 
 func (o *ObjectNode) Start(cfg *config.Config) (err error) {
@@ -291,9 +304,13 @@ func (o *ObjectNode) loadConfig(cfg *config.Config) (err error) {
 	log.LogInfof("loadConfig: strict: %v", strict)
 	o.disableCreateBucketByS3 = cfg.GetBool(disableCreateBucketByS3)
 
+	masterAK, masterSK := cfg.GetString(configMasterAccessKey), cfg.GetString(configMasterSecretKey)
 	o.mc = master.NewMasterClient(masters, false)
+	o.mc.SetAdminCredential(masterAK, masterSK)
 	o.vm = NewVolumeManager(masters, strict)
-	o.userStore = NewUserInfoStore(masters, strict)
+	userMC := master.NewMasterClient(masters, false)
+	userMC.SetAdminCredential(masterAK, masterSK)
+	o.userStore = NewUserInfoStore(userMC, strict)
 
 	// parse inode cache
 	cacheEnable := cfg.GetBool(configObjMetaCache)
@@ -416,6 +433,9 @@ func handleStart(s common.Server, cfg *config.Config) (err error) {
 		o.limitMutex.Unlock()
 	}
 
+	// tenant qos, report the flow of the volumes to master and apply the limits assigned
+	o.startTenantQosReport(fmt.Sprintf("%v:%v", ci.Ip, o.listen))
+
 	// start rest api
 	if err = o.startMuxRestAPI(); err != nil {
 		log.LogInfof("handleStart: start rest api fail: err(%v)", err)
@@ -493,5 +513,5 @@ func (o *ObjectNode) shutdown() {
 }
 
 func NewServer() *ObjectNode {
-	return &ObjectNode{}
+	return &ObjectNode{tenantQos: NewTenantQosLimiter()}
 }
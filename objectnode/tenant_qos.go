@@ -0,0 +1,204 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+	"golang.org/x/time/rate"
+)
+
+// volFlowLimiter limits the flow of a volume on this node to its share of the tenant budget,
+// and counts the flow to report the demand of the volume to the master.
+type volFlowLimiter struct {
+	read      *rate.Limiter
+	write     *rate.Limiter
+	readUsed  uint64
+	readNeed  uint64
+	writeUsed uint64
+	writeNeed uint64
+}
+
+func newRateLimiter(limit uint64) *rate.Limiter {
+	l := rate.NewLimiter(rate.Inf, 0)
+	setRateLimit(l, limit)
+	return l
+}
+
+func setRateLimit(l *rate.Limiter, limit uint64) {
+	if limit == 0 {
+		l.SetLimit(rate.Inf)
+		return
+	}
+	l.SetLimit(rate.Limit(limit))
+	// a burst of one second flow, no more than a request of it waits
+	l.SetBurst(int(limit))
+}
+
+// wait blocks until the limiter allows n bytes, the bytes are counted as needed if they have to wait.
+func wait(l *rate.Limiter, n int, used, need *uint64) {
+	atomic.AddUint64(used, uint64(n))
+	if l.Limit() == rate.Inf {
+		return
+	}
+	var delay time.Duration
+	for left := n; left > 0; {
+		size := left
+		if burst := l.Burst(); size > burst {
+			size = burst
+		}
+		delay += l.ReserveN(time.Now(), size).Delay()
+		left -= size
+	}
+	if delay > 0 {
+		atomic.AddUint64(need, uint64(n))
+		time.Sleep(delay)
+	}
+}
+
+type tenantQosReader struct {
+	r io.Reader
+	l *volFlowLimiter
+}
+
+func (tr *tenantQosReader) Read(p []byte) (n int, err error) {
+	n, err = tr.r.Read(p)
+	if n > 0 {
+		wait(tr.l.write, n, &tr.l.writeUsed, &tr.l.writeNeed)
+	}
+	return
+}
+
+type tenantQosWriter struct {
+	w io.Writer
+	l *volFlowLimiter
+}
+
+func (tw *tenantQosWriter) Write(p []byte) (n int, err error) {
+	wait(tw.l.read, len(p), &tw.l.readUsed, &tw.l.readNeed)
+	return tw.w.Write(p)
+}
+
+// TenantQosLimiter applies the tenant qos limits of the volumes assigned by the master to this node.
+type TenantQosLimiter struct {
+	mu         sync.RWMutex
+	vols       map[string]*volFlowLimiter
+	lastReport time.Time
+}
+
+func NewTenantQosLimiter() *TenantQosLimiter {
+	return &TenantQosLimiter{vols: make(map[string]*volFlowLimiter), lastReport: time.Now()}
+}
+
+func (t *TenantQosLimiter) get(vol string) *volFlowLimiter {
+	if t == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.vols[vol]
+}
+
+// Reader limits the data written to the volume.
+func (t *TenantQosLimiter) Reader(vol string, r io.Reader) io.Reader {
+	if l := t.get(vol); l != nil {
+		return &tenantQosReader{r: r, l: l}
+	}
+	return r
+}
+
+// Writer limits the data read from the volume.
+func (t *TenantQosLimiter) Writer(vol string, w io.Writer) io.Writer {
+	if l := t.get(vol); l != nil {
+		return &tenantQosWriter{w: w, l: l}
+	}
+	return w
+}
+
+// report returns the flow of the volumes since the last report, in bytes per second.
+func (t *TenantQosLimiter) report(addr string, now time.Time) *proto.ObjectNodeQosReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	report := &proto.ObjectNodeQosReport{Addr: addr, Vols: make(map[string]*proto.ObjectNodeVolFlow, len(t.vols))}
+	elapsed := now.Sub(t.lastReport).Seconds()
+	t.lastReport = now
+	if elapsed < 1 {
+		elapsed = 1
+	}
+	perSecond := func(v *uint64) uint64 {
+		return uint64(float64(atomic.SwapUint64(v, 0)) / elapsed)
+	}
+	for vol, l := range t.vols {
+		report.Vols[vol] = &proto.ObjectNodeVolFlow{
+			ReadUsed:  perSecond(&l.readUsed),
+			ReadNeed:  perSecond(&l.readNeed),
+			WriteUsed: perSecond(&l.writeUsed),
+			WriteNeed: perSecond(&l.writeNeed),
+		}
+	}
+	return report
+}
+
+func (t *TenantQosLimiter) update(limit *proto.ObjectNodeQosLimit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for vol, volLimit := range limit.Vols {
+		if l, ok := t.vols[vol]; ok {
+			setRateLimit(l.read, volLimit.FlowRLimit)
+			setRateLimit(l.write, volLimit.FlowWLimit)
+			continue
+		}
+		t.vols[vol] = &volFlowLimiter{read: newRateLimiter(volLimit.FlowRLimit), write: newRateLimiter(volLimit.FlowWLimit)}
+		log.LogInfof("TenantQosLimiter: vol(%v) is limited, read(%v) write(%v)", vol, volLimit.FlowRLimit, volLimit.FlowWLimit)
+	}
+	for vol := range t.vols {
+		if _, ok := limit.Vols[vol]; !ok {
+			delete(t.vols, vol)
+			log.LogInfof("TenantQosLimiter: vol(%v) is not limited anymore", vol)
+		}
+	}
+}
+
+func (o *ObjectNode) startTenantQosReport(addr string) {
+	stopC := make(chan struct{})
+	o.closes = append(o.closes, func() { close(stopC) })
+	go func() {
+		period := time.Duration(proto.DefaultObjectNodeQosReportPeriod) * time.Second
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-stopC:
+				return
+			case <-timer.C:
+			}
+			limit, err := o.mc.AdminAPI().ReportObjectNodeQos(o.tenantQos.report(addr, time.Now()))
+			if err != nil {
+				log.LogWarnf("startTenantQosReport: report to master err(%v)", err)
+			} else {
+				o.tenantQos.update(limit)
+				if limit.ReportPeriod > 0 {
+					period = time.Duration(limit.ReportPeriod) * time.Second
+				}
+			}
+			timer.Reset(period)
+		}
+	}()
+}
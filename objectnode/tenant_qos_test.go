@@ -0,0 +1,66 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantQosLimiter(t *testing.T) {
+	l := NewTenantQosLimiter()
+	var buf bytes.Buffer
+	// not limited, the reader and writer are returned as they are
+	require.Equal(t, io.Reader(&buf), l.Reader("vol1", &buf))
+	require.Equal(t, io.Writer(&buf), l.Writer("vol1", &buf))
+
+	l.update(&proto.ObjectNodeQosLimit{Vols: map[string]*proto.ObjectNodeVolLimit{
+		"vol1": {FlowRLimit: 1000, FlowWLimit: 0},
+	}})
+	data := make([]byte, 1500)
+	start := time.Now()
+	n, err := l.Writer("vol1", &buf).Write(data)
+	require.NoError(t, err)
+	require.Equal(t, len(data), n)
+	// the burst is 1000 bytes, the other 500 bytes wait for half a second
+	require.True(t, time.Since(start) >= 400*time.Millisecond)
+
+	n64, err := io.Copy(io.Discard, l.Reader("vol1", bytes.NewReader(data)))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), n64)
+
+	report := l.report("127.0.0.1:80", l.lastReport.Add(time.Second))
+	require.Equal(t, "127.0.0.1:80", report.Addr)
+	flow := report.Vols["vol1"]
+	require.Equal(t, uint64(1500), flow.ReadUsed)
+	require.Equal(t, uint64(1500), flow.ReadNeed)
+	require.Equal(t, uint64(1500), flow.WriteUsed)
+	require.Equal(t, uint64(0), flow.WriteNeed)
+
+	// the counters are reset by a report
+	report = l.report("127.0.0.1:80", l.lastReport.Add(time.Second))
+	require.Equal(t, uint64(0), report.Vols["vol1"].ReadUsed)
+
+	l.update(&proto.ObjectNodeQosLimit{Vols: map[string]*proto.ObjectNodeVolLimit{}})
+	require.Equal(t, io.Reader(&buf), l.Reader("vol1", &buf))
+
+	var nilLimiter *TenantQosLimiter
+	require.Equal(t, io.Writer(&buf), nilLimiter.Writer("vol1", &buf))
+}
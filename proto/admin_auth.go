@@ -0,0 +1,65 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The roles granted on the master admin API. Each role includes the rights of
+// the roles before it, except the volume owner which only manages its own
+// volumes besides viewing.
+const (
+	AdminRoleViewer      = "viewer"
+	AdminRoleOperator    = "operator"
+	AdminRoleAdmin       = "admin"
+	AdminRoleVolumeOwner = "volume-owner"
+)
+
+func IsValidAdminRole(role string) bool {
+	switch role {
+	case AdminRoleViewer, AdminRoleOperator, AdminRoleAdmin, AdminRoleVolumeOwner:
+		return true
+	default:
+		return false
+	}
+}
+
+// The headers of a signed admin request. The signature is the hex encoded
+// HMAC-SHA256, keyed by the secret key of the user, of the method, the path,
+// the sorted query, the date in unix seconds, the nonce and the sha256 of the
+// body. The nonce is random for each request, the master rejects a signature
+// seen again within AdminSignatureExpire.
+const (
+	AdminAccessKeyHeader = "X-Cfs-Access-Key"
+	AdminDateHeader      = "X-Cfs-Date"
+	AdminNonceHeader     = "X-Cfs-Nonce"
+	AdminSignatureHeader = "X-Cfs-Signature"
+
+	AdminSignatureExpire = 15 * time.Minute
+)
+
+func AdminRequestSignature(secretKey, method, path string, query url.Values, date, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	content := strings.Join([]string{method, path, query.Encode(), date, nonce, hex.EncodeToString(bodyHash[:])}, "\n")
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte(content))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,34 @@
+package proto
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminRequestSignature(t *testing.T) {
+	query := url.Values{"name": []string{"vol"}, "capacity": []string{"100"}}
+	sign := AdminRequestSignature("secret", "GET", AdminVolExpand, query, "1700000000", "nonce", nil)
+	require.Len(t, sign, 64)
+
+	// the order of the query does not matter
+	reordered, err := url.ParseQuery("name=vol&capacity=100")
+	require.NoError(t, err)
+	require.Equal(t, sign, AdminRequestSignature("secret", "GET", AdminVolExpand, reordered, "1700000000", "nonce", nil))
+
+	require.NotEqual(t, sign, AdminRequestSignature("other", "GET", AdminVolExpand, query, "1700000000", "nonce", nil))
+	require.NotEqual(t, sign, AdminRequestSignature("secret", "POST", AdminVolExpand, query, "1700000000", "nonce", nil))
+	require.NotEqual(t, sign, AdminRequestSignature("secret", "GET", AdminVolShrink, query, "1700000000", "nonce", nil))
+	require.NotEqual(t, sign, AdminRequestSignature("secret", "GET", AdminVolExpand, query, "1700000001", "nonce", nil))
+	require.NotEqual(t, sign, AdminRequestSignature("secret", "GET", AdminVolExpand, query, "1700000000", "other", nil))
+	require.NotEqual(t, sign, AdminRequestSignature("secret", "GET", AdminVolExpand, query, "1700000000", "nonce", []byte("{}")))
+}
+
+func TestUserAdminRole(t *testing.T) {
+	require.Equal(t, AdminRoleAdmin, (&UserInfo{UserType: UserTypeRoot}).AdminRole())
+	require.Equal(t, AdminRoleAdmin, (&UserInfo{UserType: UserTypeAdmin}).AdminRole())
+	require.Equal(t, AdminRoleVolumeOwner, (&UserInfo{UserType: UserTypeNormal}).AdminRole())
+	require.Equal(t, AdminRoleOperator, (&UserInfo{UserType: UserTypeNormal, Role: AdminRoleOperator}).AdminRole())
+	require.False(t, IsValidAdminRole("root"))
+}
@@ -129,6 +129,12 @@ const (
 	QosUpload              = "/admin/qosUpload"
 	QosUpdateMasterLimit   = "/qos/masterLimit"
 
+	// tenant qos api
+	QosSetTenant        = "/qos/tenant/set"
+	QosDeleteTenant     = "/qos/tenant/delete"
+	QosGetTenantStatus  = "/qos/tenant/status"
+	QosObjectNodeReport = "/qos/tenant/objectNodeReport"
+
 	// acl api
 	AdminACL = "/admin/aclOp"
 	// uid api
@@ -180,6 +186,32 @@ const (
 	AdminGetAllVersionInfo = "/multiVer/getAll"
 	AdminGetVolVer         = "/vol/getVer"
 	AdminSetVerStrategy    = "/vol/SetVerStrategy"
+	AdminCloneVol          = "/vol/clone"
+	AdminSnapshotDiff      = "/multiVer/diff"
+	AdminGetSnapshotDiff   = "/multiVer/diffResult"
+
+	// snapshot policy APIs
+	AdminCreateSnapshotPolicy = "/snapshotPolicy/create"
+	AdminUpdateSnapshotPolicy = "/snapshotPolicy/update"
+	AdminDeleteSnapshotPolicy = "/snapshotPolicy/delete"
+	AdminAttachSnapshotPolicy = "/snapshotPolicy/attach"
+	AdminDetachSnapshotPolicy = "/snapshotPolicy/detach"
+	AdminListSnapshotPolicy   = "/snapshotPolicy/list"
+	AdminSnapshotPolicyStatus = "/snapshotPolicy/status"
+
+	// metadata backup APIs
+	AdminMetadataBackup       = "/admin/metadataBackup"
+	AdminListMetadataBackup   = "/admin/metadataBackup/list"
+	AdminVerifyMetadataBackup = "/admin/metadataBackup/verify"
+
+	// cluster event APIs
+	AdminListEvents = "/events/list"
+
+	// capacity forecast and policy APIs
+	AdminSetCapacityPolicy       = "/capacity/policy/set"
+	AdminDeleteCapacityPolicy    = "/capacity/policy/delete"
+	AdminGetVolCapacityForecast  = "/capacity/forecast/vol"
+	AdminGetZoneCapacityForecast = "/capacity/forecast/zone"
 
 	// S3 lifecycle configuration APIS
 	SetBucketLifecycle    = "/s3/setLifecycle"
@@ -193,13 +225,15 @@ const (
 	GetMetaNodeTaskResponse = "/metaNode/response" // Method: 'POST', ContentType: 'application/json'
 	GetDataNodeTaskResponse = "/dataNode/response" // Method: 'POST', ContentType: 'application/json'
 	GetLcNodeTaskResponse   = "/lcNode/response"   // Method: 'POST', ContentType: 'application/json'
+	PutLcNodeSnapshotDiff   = "/lcNode/diffPage"   // Method: 'POST', ContentType: 'application/json'
 
-	GetTopologyView = "/topo/get"
-	UpdateZone      = "/zone/update"
-	GetAllZones     = "/zone/list"
-	GetAllNodeSets  = "/nodeSet/list"
-	GetNodeSet      = "/nodeSet/get"
-	UpdateNodeSet   = "/nodeSet/update"
+	GetTopologyView  = "/topo/get"
+	GetPlacementView = "/topo/placement"
+	UpdateZone       = "/zone/update"
+	GetAllZones      = "/zone/list"
+	GetAllNodeSets   = "/nodeSet/list"
+	GetNodeSet       = "/nodeSet/get"
+	UpdateNodeSet    = "/nodeSet/update"
 
 	// Header keys
 	SkipOwnerValidation = "Skip-Owner-Validation"
@@ -517,6 +551,10 @@ type CreateDataPartitionRequest struct {
 	DecommissionedDisks []string
 	IsMultiVer          bool
 	VerSeq              uint64
+	EcDataNum           int
+	EcParityNum         int
+	EcHosts             []string
+	EnableDedup         bool
 }
 
 // CreateDataPartitionResponse defines the response to the request of creating a data partition.
@@ -593,6 +631,12 @@ type StopDataPartitionRepairRequest struct {
 	Stop        bool
 }
 
+// UpdateDataPartitionEcHostsRequest replaces the shard holders of an erasure-coded data partition.
+type UpdateDataPartitionEcHostsRequest struct {
+	PartitionId uint64
+	EcHosts     []string
+}
+
 // DeleteDataPartitionResponse defines the response to the request of deleting a data partition.
 type StopDataPartitionRepairResponse struct {
 	Status      uint8
@@ -682,6 +726,7 @@ type HeartBeatRequest struct {
 	TxInfos
 	ForbiddenVols     []string
 	DisableAuditVols  []string
+	DedupVols         []string // extents of these volumes are copied on write
 	DecommissionDisks []string // NOTE: for datanode
 }
 
@@ -697,6 +742,8 @@ type DataPartitionReport struct {
 	ExtentCount                int
 	NeedCompare                bool
 	DecommissionRepairProgress float64
+	TinyCompacting             []uint64
+	TinyReclaimedSize          uint64
 }
 
 type DataNodeQosResponse struct {
@@ -862,6 +909,10 @@ type DataPartitionResponse struct {
 	IsRecover     bool
 	PartitionTTL  int64
 	IsDiscard     bool
+	// tiny extents the leader is compacting, meta nodes relocate their keys off them
+	TinyCompacting []uint64
+	// partition of the clone source volume, readable only and never freed by the clone
+	IsShared bool
 }
 
 // DataPartitionsView defines the view of a data partition
@@ -1057,6 +1108,7 @@ type SimpleVolView struct {
 	DeleteLockTime          int64
 	EnableToken             bool
 	EnablePosixAcl          bool
+	EnableDedup             bool
 	EnableQuota             bool
 	EnableTransaction       string
 	TxTimeout               int64
@@ -1068,6 +1120,8 @@ type SimpleVolView struct {
 	DpSelectorParm          string
 	DefaultZonePrior        bool
 	DpReadOnlyWhenVolFull   bool
+	EcDataNum               uint8
+	EcParityNum             uint8
 
 	VolType          int
 	ObjBlockSize     int
@@ -1085,6 +1139,11 @@ type SimpleVolView struct {
 	LatestVer      uint64
 	Forbidden      bool
 	EnableAuditLog bool
+	// the volume and version this volume is cloned from, empty if not a clone
+	CloneSource string
+	CloneVerSeq uint64
+	// usage forecast and capacity policy, nil before the first sample
+	CapacityStatus *VolCapacityStatus `json:",omitempty"`
 }
 
 type NodeSetInfo struct {
@@ -1171,6 +1230,23 @@ type TopologyView struct {
 	Zones []*ZoneView
 }
 
+// PlacementView shows the failure domains the replicas are spread across and
+// the partitions with more than one replica in a failure domain.
+type PlacementView struct {
+	Level      string              // rack or host
+	DataNodes  map[string][]string // failure domain -> node addresses
+	MetaNodes  map[string][]string
+	Violations []*PlacementViolation
+}
+
+type PlacementViolation struct {
+	PartitionID   uint64
+	PartitionType string // data or meta
+	VolName       string
+	Domain        string
+	Hosts         []string // the replicas in the failure domain
+}
+
 const (
 	PartitionTypeNormal  = 0
 	PartitionTypeCache   = 1
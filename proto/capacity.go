@@ -0,0 +1,63 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+// CapacityPolicy is applied by the master leader to a volume according to its usage and forecast.
+// A zero threshold disables the trigger.
+type CapacityPolicy struct {
+	Vol string `json:"vol"`
+	// expand the capacity by ExpandStepGB, up to MaxCapacityGB, when a trigger is hit
+	AutoExpand       bool    `json:"autoExpand"`
+	ExpandStepGB     uint64  `json:"expandStepGB"`
+	MaxCapacityGB    uint64  `json:"maxCapacityGB"`
+	ExpandUsedRatio  float64 `json:"expandUsedRatio"`
+	ExpandDaysToFull float64 `json:"expandDaysToFull"`
+	// record a warning event when a trigger is hit
+	AlertUsedRatio  float64 `json:"alertUsedRatio"`
+	AlertDaysToFull float64 `json:"alertDaysToFull"`
+	// create data partitions ahead of the growth of a day instead of waiting for the writable ones to run short
+	EarlyDpCreate bool  `json:"earlyDpCreate"`
+	UTime         int64 `json:"uTime"`
+}
+
+// CapacityForecast is the linear forecast of the usage by the samples in the window.
+type CapacityForecast struct {
+	UsedBytes    uint64  `json:"usedBytes"`
+	TotalBytes   uint64  `json:"totalBytes"`
+	UsedRatio    float64 `json:"usedRatio"`
+	GrowthPerDay int64   `json:"growthPerDay"` // bytes, negative if the usage is shrinking
+	DaysToFull   float64 `json:"daysToFull"`   // -1 if not growing or not enough samples
+	Samples      int     `json:"samples"`
+	WindowSec    int64   `json:"windowSec"`
+	UpdateTime   int64   `json:"updateTime"`
+}
+
+type VolCapacityStatus struct {
+	Vol                string            `json:"vol"`
+	Forecast           *CapacityForecast `json:"forecast"`
+	Policy             *CapacityPolicy   `json:"policy,omitempty"`
+	LastExpandTime     int64             `json:"lastExpandTime,omitempty"`
+	LastExpandCapacity uint64            `json:"lastExpandCapacity,omitempty"` // GB
+	LastDpCreateTime   int64             `json:"lastDpCreateTime,omitempty"`
+	LastDpCreateCount  int               `json:"lastDpCreateCount,omitempty"`
+	Alert              string            `json:"alert,omitempty"`
+	LastErr            string            `json:"lastErr,omitempty"`
+}
+
+// ZoneCapacityStatus is the forecast of the data nodes of a zone.
+type ZoneCapacityStatus struct {
+	Zone     string            `json:"zone"`
+	Forecast *CapacityForecast `json:"forecast"`
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+// event types
+const (
+	EventDiskError           = "diskError"
+	EventDataPartitionRdOnly = "dataPartitionReadOnly"
+	EventMissingReplica      = "missingReplica"
+	EventDecommissionFailed  = "decommissionFailed"
+	EventMasterLeaderChange  = "masterLeaderChange"
+	EventCapacityForecast    = "capacityForecast"
+	EventVolAutoExpand       = "volAutoExpand"
+)
+
+const (
+	EventSeverityInfo     = "info"
+	EventSeverityWarning  = "warning"
+	EventSeverityCritical = "critical"
+)
+
+// kinds of the object an event is about
+const (
+	EventObjectDataPartition = "dataPartition"
+	EventObjectMetaPartition = "metaPartition"
+	EventObjectDataNode      = "dataNode"
+	EventObjectDisk          = "disk"
+	EventObjectMaster        = "master"
+	EventObjectVol           = "vol"
+	EventObjectZone          = "zone"
+)
+
+const (
+	DefaultEventQueryLimit = 100
+	MaxEventQueryLimit     = 10000
+)
+
+// ClusterEvent is an operational event recorded by the master leader.
+type ClusterEvent struct {
+	ID         uint64 `json:"id"`
+	Cluster    string `json:"cluster"`
+	Type       string `json:"type"`
+	Severity   string `json:"severity"`
+	Time       int64  `json:"time"`
+	ObjectType string `json:"objectType"`
+	ObjectID   string `json:"objectID"` // partition id, node address, or address:disk for disks
+	Vol        string `json:"vol,omitempty"`
+	Message    string `json:"message"`
+}
+
+// ClusterEventFilter selects the events returned by the event query API, empty fields match all.
+type ClusterEventFilter struct {
+	Type     string
+	Severity string
+	ObjectID string
+	Vol      string
+	Start    int64
+	End      int64
+	Limit    int
+}
+
+func (f *ClusterEventFilter) Match(e *ClusterEvent) bool {
+	if f.Type != "" && f.Type != e.Type {
+		return false
+	}
+	if f.Severity != "" && f.Severity != e.Severity {
+		return false
+	}
+	if f.ObjectID != "" && f.ObjectID != e.ObjectID {
+		return false
+	}
+	if f.Vol != "" && f.Vol != e.Vol {
+		return false
+	}
+	if f.Start > 0 && e.Time < f.Start {
+		return false
+	}
+	if f.End > 0 && e.Time > f.End {
+		return false
+	}
+	return true
+}
+
+func IsValidEventSeverity(severity string) bool {
+	return severity == EventSeverityInfo || severity == EventSeverityWarning || severity == EventSeverityCritical
+}
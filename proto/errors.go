@@ -97,6 +97,8 @@ var (
 	ErrNodeSetNotExists                        = errors.New("node set not exists")
 	ErrCompressFailed                          = errors.New("compress data failed")
 	ErrDecompressFailed                        = errors.New("decompress data failed")
+	ErrInvalidAdminRole                        = errors.New("invalid admin role")
+	ErrInvalidAdminSignature                   = errors.New("invalid admin request signature")
 )
 
 // http response error code and error message definitions
@@ -164,6 +166,8 @@ const (
 	ErrCodeZoneNumError
 	ErrCodeVersionOpError
 	ErrCodeNodeSetNotExists
+	ErrCodeInvalidAdminRole
+	ErrCodeInvalidAdminSignature
 )
 
 // Err2CodeMap error map to code
@@ -229,6 +233,8 @@ var Err2CodeMap = map[error]int32{
 	ErrZoneNum:                         ErrCodeZoneNumError,
 	ErrCodeVersionOp:                   ErrCodeVersionOpError,
 	ErrNodeSetNotExists:                ErrCodeNodeSetNotExists,
+	ErrInvalidAdminRole:                ErrCodeInvalidAdminRole,
+	ErrInvalidAdminSignature:           ErrCodeInvalidAdminSignature,
 }
 
 func ParseErrorCode(code int32) error {
@@ -301,6 +307,8 @@ var code2ErrMap = map[int32]error{
 	ErrCodeZoneNumError:                    ErrZoneNum,
 	ErrCodeVersionOpError:                  ErrCodeVersionOp,
 	ErrCodeNodeSetNotExists:                ErrNodeSetNotExists,
+	ErrCodeInvalidAdminRole:                ErrInvalidAdminRole,
+	ErrCodeInvalidAdminSignature:           ErrInvalidAdminSignature,
 }
 
 type GeneralResp struct {
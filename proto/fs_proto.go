@@ -1026,3 +1026,63 @@ type GetUniqIDRequest struct {
 type GetUniqIDResponse struct {
 	Start uint64 `json:"start"`
 }
+
+// DedupExtentAddRequest asks the meta partition to map a chunk of the inode
+// onto an already stored extent range that has the same fingerprint.
+type DedupExtentAddRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	Inode       uint64 `json:"ino"`
+	FileOffset  uint64 `json:"fo"`
+	Fingerprint string `json:"fp"`
+}
+
+type DedupExtentAddResponse struct {
+	Extent ExtentKey `json:"ek"`
+}
+
+// DedupFingerprint binds a chunk fingerprint to the extent range holding its data.
+type DedupFingerprint struct {
+	Fingerprint string    `json:"fp"`
+	Extent      ExtentKey `json:"ek"`
+}
+
+// DedupRegisterRequest publishes the fingerprints of chunks written by an inode.
+type DedupRegisterRequest struct {
+	VolName      string             `json:"vol"`
+	PartitionID  uint64             `json:"pid"`
+	Inode        uint64             `json:"ino"`
+	Fingerprints []DedupFingerprint `json:"fps"`
+}
+
+// The changes of an inode or a path between two versions.
+const (
+	VerDiffCreated  = "created"
+	VerDiffDeleted  = "deleted"
+	VerDiffModified = "modified"
+	VerDiffRenamed  = "renamed"
+)
+
+// InodeVerDiffRequest lists the inodes of the meta partition changed between
+// the versions FromVerSeq and ToVerSeq, scanning at most Limit inodes after Marker.
+// ToVerSeq 0 stands for the current version.
+type InodeVerDiffRequest struct {
+	VolName     string `json:"vol"`
+	PartitionID uint64 `json:"pid"`
+	FromVerSeq  uint64 `json:"from"`
+	ToVerSeq    uint64 `json:"to"`
+	Marker      uint64 `json:"marker"`
+	Limit       uint64 `json:"limit"`
+}
+
+type InodeVerDiff struct {
+	Inode  uint64 `json:"ino"`
+	Mode   uint32 `json:"mode"`
+	Change string `json:"change"`
+}
+
+// InodeVerDiffResponse carries the changed inodes, NextMarker is 0 once the partition is scanned.
+type InodeVerDiffResponse struct {
+	Diffs      []InodeVerDiff `json:"diffs"`
+	NextMarker uint64         `json:"next"`
+}
@@ -42,6 +42,9 @@ type CreateMetaPartitionRequest struct {
 	PartitionID uint64
 	Members     []Peer
 	VerSeq      uint64
+	// the partition copies the metadata of version CloneVerSeq from the local replica of CloneSrcPartitionID
+	CloneSrcPartitionID uint64 `json:",omitempty"`
+	CloneVerSeq         uint64 `json:",omitempty"`
 }
 
 // CreateMetaPartitionResponse defines the response to the request of creating a meta partition.
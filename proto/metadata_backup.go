@@ -0,0 +1,47 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+// MetadataBackupInfo describes a backup file of the master metadata.
+type MetadataBackupInfo struct {
+	File       string         `json:"file"`
+	Cluster    string         `json:"cluster"`
+	Applied    uint64         `json:"applied"` // raft index the backup is consistent with
+	CreateTime int64          `json:"createTime"`
+	Keys       int            `json:"keys"`
+	Size       int64          `json:"size"`
+	Counts     map[string]int `json:"counts,omitempty"` // keys per kind, vol, dataPartition, user...
+}
+
+const (
+	MetadataBackupDiffMissing = "missing" // in the backup, not in the cluster
+	MetadataBackupDiffExtra   = "extra"   // in the cluster, not in the backup
+	MetadataBackupDiffHosts   = "hosts"   // replicas reported by the nodes differ from the backup
+	MetadataBackupDiffRange   = "range"   // inode range of the meta partition differs
+)
+
+type MetadataBackupDiff struct {
+	Kind   string `json:"kind"` // vol, dataPartition, metaPartition, dataNode, metaNode
+	ID     string `json:"id"`
+	Diff   string `json:"diff"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// MetadataBackupVerifyReport lists the differences between a backup and the live cluster.
+type MetadataBackupVerifyReport struct {
+	Backup  *MetadataBackupInfo  `json:"backup"`
+	Checked map[string]int       `json:"checked"`
+	Diffs   []MetadataBackupDiff `json:"diffs"`
+}
@@ -34,6 +34,8 @@ type MetaNodeInfo struct {
 	IsActive                  bool
 	IsWriteAble               bool
 	ZoneName                  string `json:"Zone"`
+	Rack                      string
+	Host                      string
 	MaxMemAvailWeight         uint64 `json:"MaxMemAvailWeight"`
 	Total                     uint64 `json:"TotalWeight"`
 	Used                      uint64 `json:"UsedWeight"`
@@ -55,6 +57,8 @@ type DataNodeInfo struct {
 	AvailableSpace            uint64
 	ID                        uint64
 	ZoneName                  string `json:"Zone"`
+	Rack                      string
+	Host                      string
 	Addr                      string
 	DomainAddr                string
 	ReportTime                time.Time
@@ -158,6 +162,8 @@ type NodeView struct {
 	DomainAddr string
 	ID         uint64
 	IsWritable bool
+	Rack       string `json:",omitempty"`
+	Host       string `json:",omitempty"`
 }
 
 type DpRepairInfo struct {
@@ -274,6 +280,9 @@ type DataPartitionInfo struct {
 	RdOnly                   bool
 	IsDiscard                bool
 	Forbidden                bool
+	EcDataNum                uint8
+	EcParityNum              uint8
+	EcHosts                  []string // holders of the erasure-coded shards
 }
 
 // FileInCore define file in data partition
@@ -305,6 +314,8 @@ type DataReplica struct {
 	NeedsToCompare             bool
 	DiskPath                   string
 	DecommissionRepairProgress float64
+	TinyCompacting             []uint64
+	TinyReclaimedSize          uint64
 }
 
 // data partition diagnosis represents the inactive data nodes, corrupt data partitions, and data partitions lack of replicas
@@ -359,6 +370,10 @@ type VolVersionInfo struct {
 	Ver     uint64 // unixMicro of createTime used as version
 	DelTime int64
 	Status  uint8 // building,normal,deleted,abnormal
+	// set on the versions taken by a snapshot policy
+	Policy string            `json:",omitempty"`
+	Tiers  []string          `json:",omitempty"`
+	Labels map[string]string `json:",omitempty"`
 }
 
 func (vv *VolVersionInfo) String() string {
@@ -74,8 +74,13 @@ const (
 
 	// snapshot
 	SnapshotReadVerSeq
+	SnapshotDir
 
 	DisableMountSubtype
+
+	// dedup
+	DedupChunkSize
+
 	MaxMountOption
 )
 
@@ -169,7 +174,9 @@ func InitMountOptions(opts []MountOption) {
 
 	opts[FileSystemName] = MountOption{"fileSystemName", "The explicit name of the filesystem", "", ""}
 	opts[SnapshotReadVerSeq] = MountOption{"snapshotReadSeq", "Snapshot read seq", "", int64(0)} // default false
+	opts[SnapshotDir] = MountOption{"snapshotDir", "Show the versions in a .snapshot directory, root or all", "", ""}
 	opts[DisableMountSubtype] = MountOption{"disableMountSubtype", "Disable Mount Subtype", "", false}
+	opts[DedupChunkSize] = MountOption{"dedupChunkSize", "The chunk size of content deduplication", "", int64(128 * 1024)}
 
 	for i := 0; i < MaxMountOption; i++ {
 		flag.StringVar(&opts[i].cmdlineValue, opts[i].keyword, "", opts[i].description)
@@ -329,6 +336,8 @@ type MountOptions struct {
 	MinWriteAbleDataPartitionCnt int
 	FileSystemName               string
 	VerReadSeq                   uint64
+	SnapshotDir                  string
 	// disable mount subtype
 	DisableMountSubtype bool
+	DedupChunkSize      int64
 }
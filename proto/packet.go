@@ -70,6 +70,16 @@ const (
 	OpTinyExtentRepairRead           uint8 = 0x15
 	OpGetMaxExtentIDAndPartitionSize uint8 = 0x16
 
+	// Operations: DataNode -> DataNode, erasure-coded extents of hot volumes.
+	OpEcShardWrite  uint8 = 0x17
+	OpEcShardRead   uint8 = 0x18
+	OpEcShardDelete uint8 = 0x19
+	OpEcExtentSeal  uint8 = 0x1A
+
+	// Operations: MetaNode/DataNode -> DataNode, tiny extent compaction.
+	OpTinyExtentRelocate uint8 = 0x1B
+	OpTinyExtentReset    uint8 = 0x1C
+
 	// Operations: Client -> MetaNode.
 	OpMetaCreateInode   uint8 = 0x20
 	OpMetaUnlinkInode   uint8 = 0x21
@@ -126,6 +136,7 @@ const (
 	OpLcNodeHeartbeat      uint8 = 0x55
 	OpLcNodeScan           uint8 = 0x56
 	OpLcNodeSnapshotVerDel uint8 = 0x57
+	OpLcNodeSnapshotDiff   uint8 = 0x58
 
 	// Operations: Master -> DataNode
 	OpCreateDataPartition           uint8 = 0x60
@@ -140,6 +151,7 @@ const (
 	OpDataPartitionTryToLeader      uint8 = 0x69
 	OpQos                           uint8 = 0x6A
 	OpStopDataPartitionRepair       uint8 = 0x6B
+	OpUpdateDataPartitionEcHosts    uint8 = 0x6C
 
 	// Operations: MultipartInfo
 	OpCreateMultipart  uint8 = 0x70
@@ -174,6 +186,13 @@ const (
 	// Operations: Client -> MetaNode.
 	OpMetaGetUniqID uint8 = 0xAC
 
+	// Content-based deduplication: Client -> MetaNode.
+	OpMetaDedupExtentAdd uint8 = 0xC0
+	OpMetaDedupRegister  uint8 = 0xC1
+
+	// Snapshot diff: LcNode -> MetaNode.
+	OpMetaInodeVerDiff uint8 = 0xC2
+
 	// Multi version snapshot
 	OpRandomWriteAppend     uint8 = 0xB1
 	OpSyncRandomWriteAppend uint8 = 0xB2
@@ -443,6 +462,12 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaExtentsAdd"
 	case OpMetaExtentAddWithCheck:
 		m = "OpMetaExtentAddWithCheck"
+	case OpMetaDedupExtentAdd:
+		m = "OpMetaDedupExtentAdd"
+	case OpMetaDedupRegister:
+		m = "OpMetaDedupRegister"
+	case OpMetaInodeVerDiff:
+		m = "OpMetaInodeVerDiff"
 	case OpMetaObjExtentAdd:
 		m = "OpMetaObjExtentAdd"
 	case OpMetaExtentsDel:
@@ -511,6 +536,18 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpTinyExtentRepairRead"
 	case OpGetMaxExtentIDAndPartitionSize:
 		m = "OpGetMaxExtentIDAndPartitionSize"
+	case OpEcShardWrite:
+		m = "OpEcShardWrite"
+	case OpEcShardRead:
+		m = "OpEcShardRead"
+	case OpEcShardDelete:
+		m = "OpEcShardDelete"
+	case OpEcExtentSeal:
+		m = "OpEcExtentSeal"
+	case OpTinyExtentRelocate:
+		m = "OpTinyExtentRelocate"
+	case OpTinyExtentReset:
+		m = "OpTinyExtentReset"
 	case OpBroadcastMinAppliedID:
 		m = "OpBroadcastMinAppliedID"
 	case OpRemoveDataPartitionRaftMember:
@@ -591,12 +628,16 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaGetInodeQuota"
 	case OpStopDataPartitionRepair:
 		m = "OpStopDataPartitionRepair"
+	case OpUpdateDataPartitionEcHosts:
+		m = "OpUpdateDataPartitionEcHosts"
 	case OpLcNodeHeartbeat:
 		m = "OpLcNodeHeartbeat"
 	case OpLcNodeScan:
 		m = "OpLcNodeScan"
 	case OpLcNodeSnapshotVerDel:
 		m = "OpLcNodeSnapshotVerDel"
+	case OpLcNodeSnapshotDiff:
+		m = "OpLcNodeSnapshotDiff"
 	case OpMetaReadDirOnly:
 		m = "OpMetaReadDirOnly"
 	default:
@@ -879,7 +920,8 @@ func (p *Packet) IsWriteOperation() bool {
 func (p *Packet) IsReadOperation() bool {
 	return p.Opcode == OpStreamRead || p.Opcode == OpRead ||
 		p.Opcode == OpExtentRepairRead || p.Opcode == OpReadTinyDeleteRecord ||
-		p.Opcode == OpTinyExtentRepairRead || p.Opcode == OpStreamFollowerRead
+		p.Opcode == OpTinyExtentRepairRead || p.Opcode == OpStreamFollowerRead ||
+		p.Opcode == OpEcShardRead
 }
 
 // ReadFromConn reads the data from the given connection.
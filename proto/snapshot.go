@@ -37,3 +37,105 @@ type SnapshotStatistics struct {
 	DirNum          int64
 	ErrorSkippedNum int64
 }
+
+type SnapshotDiffTaskRequest struct {
+	MasterAddr string
+	LcNodeAddr string
+	Task       *SnapshotDiffTask
+}
+
+// SnapshotDiffTask compares the version FromVerSeq of a volume with the version ToVerSeq,
+// 0 stands for the current version.
+type SnapshotDiffTask struct {
+	Id         string
+	VolName    string
+	FromVerSeq uint64
+	ToVerSeq   uint64
+}
+
+type SnapshotDiffTaskResponse struct {
+	ID               string
+	LcNode           string
+	StartTime        *time.Time
+	EndTime          *time.Time
+	UpdateTime       *time.Time
+	Done             bool
+	Status           uint8
+	Result           string
+	SnapshotDiffTask *SnapshotDiffTask
+	Total            int // the entries put in pages of SnapshotDiffPageSize
+	Pages            int
+}
+
+// SnapshotDiffPageSize is the number of entries in a page of a snapshot diff job.
+const SnapshotDiffPageSize = 1000
+
+// SnapshotDiffPage is the Page-th page of the entries of a snapshot diff job, the lcnode
+// running the job puts the pages to the master in order before reporting it done.
+type SnapshotDiffPage struct {
+	ID      string
+	VolName string
+	LcNode  string
+	Page    int
+	Entries []SnapshotDiffEntry
+}
+
+// SnapshotDiffEntry is a path changed between the two versions, OldPath is only set if renamed.
+type SnapshotDiffEntry struct {
+	Path    string `json:"path"`
+	OldPath string `json:"oldPath,omitempty"`
+	Change  string `json:"change"`
+	Inode   uint64 `json:"ino"`
+	IsDir   bool   `json:"isDir"`
+}
+
+// SnapshotDiffResult is a page of the entries of a snapshot diff job.
+type SnapshotDiffResult struct {
+	ID         string
+	VolName    string
+	FromVerSeq uint64
+	ToVerSeq   uint64
+	Done       bool
+	Status     uint8
+	Result     string
+	Total      int
+	Entries    []SnapshotDiffEntry
+	NextMarker int
+}
+
+// SnapshotPolicyTier takes a version on a cron-like schedule, "minute hour day-of-month month day-of-week"
+// or one of @hourly, @daily, @weekly, and keeps the Keep newest versions it took.
+type SnapshotPolicyTier struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	Keep     int    `json:"keep"`
+}
+
+// SnapshotPolicy takes the versions of the volumes attached to it, labeled with Labels.
+type SnapshotPolicy struct {
+	Name   string               `json:"name"`
+	Tiers  []SnapshotPolicyTier `json:"tiers"`
+	Labels map[string]string    `json:"labels,omitempty"`
+	Vols   []string             `json:"vols,omitempty"`
+	Enable bool                 `json:"enable"`
+	UTime  int64                `json:"utime"`
+}
+
+type SnapshotPolicyTierStatus struct {
+	Name     string `json:"name"`
+	Versions int    `json:"versions"`
+	LastTime int64  `json:"lastTime"` // the time the newest version of the tier was taken
+	NextTime int64  `json:"nextTime"`
+}
+
+type SnapshotPolicyVolStatus struct {
+	VolName     string                     `json:"vol"`
+	Tiers       []SnapshotPolicyTierStatus `json:"tiers"`
+	LastErr     string                     `json:"lastErr,omitempty"`
+	LastErrTime int64                      `json:"lastErrTime,omitempty"`
+}
+
+type SnapshotPolicyStatus struct {
+	Policy *SnapshotPolicy            `json:"policy"`
+	Vols   []*SnapshotPolicyVolStatus `json:"vols"`
+}
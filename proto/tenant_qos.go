@@ -0,0 +1,88 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+const (
+	DefaultTenantQosVolWeight = 1
+	// DefaultObjectNodeQosReportPeriod is the period in seconds the object nodes report the flow of the volumes under tenant qos
+	DefaultObjectNodeQosReportPeriod = 5
+)
+
+// TenantQos is the flow budget of a tenant, the owner of the volumes, shared by all its volumes
+// by weight. A volume with less demand than its share lends the rest to the others.
+type TenantQos struct {
+	Tenant     string            `json:"tenant"`
+	FlowRLimit uint64            `json:"flowRLimit"` // bytes per second, 0 is unlimited
+	FlowWLimit uint64            `json:"flowWLimit"`
+	VolWeights map[string]uint64 `json:"volWeights"` // vol name -> weight, DefaultTenantQosVolWeight if absent
+}
+
+func (q *TenantQos) VolWeight(volName string) uint64 {
+	if w, ok := q.VolWeights[volName]; ok && w > 0 {
+		return w
+	}
+	return DefaultTenantQosVolWeight
+}
+
+// TenantQosFlow is the demand and the assigned limit of a flow type, in bytes per second.
+type TenantQosFlow struct {
+	ClientDemand     uint64 `json:"clientDemand"`     // used and needed by the FUSE clients
+	ObjectNodeDemand uint64 `json:"objectNodeDemand"` // used and needed by the object nodes
+	Assigned         uint64 `json:"assigned"`         // share of the tenant budget
+	ClientLimit      uint64 `json:"clientLimit"`
+	ObjectNodeLimit  uint64 `json:"objectNodeLimit"`
+}
+
+type TenantVolQosStatus struct {
+	Vol    string        `json:"vol"`
+	Weight uint64        `json:"weight"`
+	Read   TenantQosFlow `json:"read"`
+	Write  TenantQosFlow `json:"write"`
+}
+
+type TenantQosStatus struct {
+	Tenant     string                `json:"tenant"`
+	FlowRLimit uint64                `json:"flowRLimit"`
+	FlowWLimit uint64                `json:"flowWLimit"`
+	Read       TenantQosFlow         `json:"read"`
+	Write      TenantQosFlow         `json:"write"`
+	Vols       []*TenantVolQosStatus `json:"vols"`
+	UpdateTime int64                 `json:"updateTime"`
+}
+
+// ObjectNodeVolFlow is the flow of a volume on an object node, in bytes per second,
+// Need is the flow of the requests delayed by the limit.
+type ObjectNodeVolFlow struct {
+	ReadUsed  uint64 `json:"readUsed"`
+	ReadNeed  uint64 `json:"readNeed"`
+	WriteUsed uint64 `json:"writeUsed"`
+	WriteNeed uint64 `json:"writeNeed"`
+}
+
+type ObjectNodeQosReport struct {
+	Addr string                        `json:"addr"`
+	Vols map[string]*ObjectNodeVolFlow `json:"vols"`
+}
+
+type ObjectNodeVolLimit struct {
+	FlowRLimit uint64 `json:"flowRLimit"` // bytes per second on this node, 0 is unlimited
+	FlowWLimit uint64 `json:"flowWLimit"`
+}
+
+// ObjectNodeQosLimit is the reply to an object node report, the limits of all the volumes under tenant qos.
+type ObjectNodeQosLimit struct {
+	Vols         map[string]*ObjectNodeVolLimit `json:"vols"`
+	ReportPeriod uint32                         `json:"reportPeriod"`
+}
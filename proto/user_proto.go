@@ -105,6 +105,7 @@ type UserInfo struct {
 	UserType    UserType     `json:"user_type" graphql:"user_type"`
 	CreateTime  string       `json:"create_time" graphql:"create_time"`
 	Description string       `json:"description" graphql:"description"`
+	Role        string       `json:"role,omitempty" graphql:"role"`
 	Mu          sync.RWMutex `json:"-" graphql:"-"`
 	EMPTY       bool         // graphql need ???
 }
@@ -117,6 +118,20 @@ func (i *UserInfo) String() string {
 		i.UserID, i.AccessKey, i.SecretKey, i.UserType)
 }
 
+// AdminRole returns the role of the user on the master admin API, the root and
+// admin users are admins unless another role is set.
+func (i *UserInfo) AdminRole() string {
+	if i.Role != "" {
+		return i.Role
+	}
+	switch i.UserType {
+	case UserTypeRoot, UserTypeAdmin:
+		return AdminRoleAdmin
+	default:
+		return AdminRoleVolumeOwner
+	}
+}
+
 func NewUserInfo() *UserInfo {
 	return &UserInfo{Policy: NewUserPolicy()}
 }
@@ -334,6 +349,7 @@ type UserCreateParam struct {
 	SecretKey   string   `json:"sk"`
 	Type        UserType `json:"type"`
 	Description string   `json:"description"`
+	Role        string   `json:"role"`
 }
 
 type UserPermUpdateParam struct {
@@ -374,4 +390,5 @@ type UserUpdateParam struct {
 	Type        UserType `json:"type"`
 	Password    string   `json:"password"`
 	Description string   `json:"description"`
+	Role        string   `json:"role"`
 }
@@ -194,13 +194,15 @@ func NewPacketToGetAllWatermarks(partitionID uint64, extentType uint8) (p *Packe
 	return
 }
 
-func NewPacketToReadTinyDeleteRecord(partitionID uint64, offset int64) (p *Packet) {
+func NewPacketToReadTinyDeleteRecord(partitionID uint64, offset int64, generation uint64) (p *Packet) {
 	p = new(Packet)
 	p.Opcode = proto.OpReadTinyDeleteRecord
 	p.PartitionID = partitionID
 	p.Magic = proto.ProtoMagic
 	p.ReqID = proto.GenerateRequestID()
 	p.ExtentOffset = offset
+	// the generation of the tiny delete file rides on the otherwise unused kernel offset
+	p.KernelOffset = generation
 
 	return
 }
@@ -46,6 +46,8 @@ type (
 	LoadBcacheFunc      func(key string, buf []byte, offset uint64, size uint32) (int, error)
 	CacheBcacheFunc     func(key string, buf []byte) error
 	EvictBacheFunc      func(key string) error
+	DedupExtentKeyFunc  func(parentInode, inode, fileOffset uint64, fingerprint string) (proto.ExtentKey, bool, error)
+	DedupRegisterFunc   func(inode uint64, fps []proto.DedupFingerprint) error
 )
 
 const (
@@ -118,6 +120,11 @@ type ExtentConfig struct {
 	OnLoadBcache      LoadBcacheFunc
 	OnCacheBcache     CacheBcacheFunc
 	OnEvictBcache     EvictBacheFunc
+	OnDedupExtentKey  DedupExtentKeyFunc
+	OnDedupRegister   DedupRegisterFunc
+
+	// chunk size of content deduplication, enabled by the volume
+	DedupChunkSize int
 
 	DisableMetaCache             bool
 	MinWriteAbleDataPartitionCnt int
@@ -155,6 +162,9 @@ type ExtentClient struct {
 	loadBcache         LoadBcacheFunc
 	cacheBcache        CacheBcacheFunc
 	evictBcache        EvictBacheFunc
+	dedupExtentKey     DedupExtentKeyFunc
+	dedupRegister      DedupRegisterFunc
+	dedupChunkSize     int
 	inflightL1cache    sync.Map
 	inflightL1BigBlock int32
 	multiVerMgr        *MultiVerMgr
@@ -274,6 +284,12 @@ retry:
 	client.BcacheHealth = true
 	client.preload = config.Preload
 	client.disableMetaCache = config.DisableMetaCache
+	client.dedupExtentKey = config.OnDedupExtentKey
+	client.dedupRegister = config.OnDedupRegister
+	client.dedupChunkSize = config.DedupChunkSize
+	if client.dedupChunkSize <= 0 {
+		client.dedupChunkSize = util.BlockSize
+	}
 
 	var readLimit, writeLimit rate.Limit
 	if config.ReadRate <= 0 {
@@ -315,6 +331,12 @@ func (client *ExtentClient) GetEnablePosixAcl() bool {
 	return client.dataWrapper.EnablePosixAcl
 }
 
+// isDedupEnable returns true if appended chunks are deduplicated, the extents
+// of such volume may be shared by inodes and are copied on write.
+func (client *ExtentClient) isDedupEnable() bool {
+	return client.dataWrapper.EnableDedup && client.dedupExtentKey != nil && client.dedupRegister != nil
+}
+
 func (client *ExtentClient) GetFlowInfo() (*proto.ClientReportLimitInfo, bool) {
 	log.LogInfof("action[ExtentClient.GetFlowInfo]")
 	return client.LimitManager.GetFlowInfo()
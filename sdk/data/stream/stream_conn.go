@@ -28,8 +28,9 @@ import (
 )
 
 var (
-	TryOtherAddrError = errors.New("TryOtherAddrError")
-	DpDiscardError    = errors.New("DpDiscardError")
+	TryOtherAddrError      = errors.New("TryOtherAddrError")
+	DpDiscardError         = errors.New("DpDiscardError")
+	OverwriteRejectedError = errors.New("OverwriteRejectedError")
 )
 
 const (
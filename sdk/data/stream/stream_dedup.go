@@ -0,0 +1,137 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// dedupChunk is a chunk written by the streamer whose fingerprint is
+// registered to the meta partition once its extent key is persisted.
+type dedupChunk struct {
+	fileOffset  uint64
+	size        uint32
+	fingerprint string
+}
+
+func dedupFingerprint(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// doDedupWriteAppend splits the append request into chunks aligned to the
+// dedup chunk size. A full chunk whose fingerprint is known by the meta
+// partition is mapped onto the stored data instead of being written again.
+func (s *Streamer) doDedupWriteAppend(req *ExtentRequest, direct bool) (total int, err error) {
+	chunkSize := s.client.dedupChunkSize
+	end := req.FileOffset + req.Size
+	for offset := req.FileOffset; offset < end; {
+		size := end - offset
+		full := offset%chunkSize == 0 && size >= chunkSize
+		if full {
+			size = chunkSize
+		} else if next := (offset/chunkSize + 1) * chunkSize; next < end {
+			size = next - offset
+		}
+		data := req.Data[offset-req.FileOffset : offset-req.FileOffset+size]
+
+		var fingerprint string
+		if full {
+			fingerprint = dedupFingerprint(data)
+			if s.dedupExtentAdd(offset, fingerprint) {
+				total += size
+				offset += size
+				continue
+			}
+		}
+
+		var writeSize int
+		chunkReq := &ExtentRequest{FileOffset: offset, Size: size, Data: data}
+		if writeSize, err = s.doWriteAppend(chunkReq, direct); err != nil {
+			return
+		}
+		if full {
+			s.dedupPending = append(s.dedupPending, dedupChunk{
+				fileOffset:  uint64(offset),
+				size:        uint32(size),
+				fingerprint: fingerprint,
+			})
+		}
+		total += writeSize
+		offset += size
+	}
+	return
+}
+
+// dedupExtentAdd asks the meta partition for the data of the fingerprint, and
+// reports whether the chunk at offset has been mapped onto it.
+func (s *Streamer) dedupExtentAdd(offset int, fingerprint string) bool {
+	ek, found, err := s.client.dedupExtentKey(s.parentInode, s.inode, uint64(offset), fingerprint)
+	if err != nil {
+		log.LogWarnf("dedupExtentAdd: ino(%v) offset(%v) fp(%v) err(%v)", s.inode, offset, fingerprint, err)
+		return false
+	}
+	if !found {
+		return false
+	}
+	// the open handler can not go on writing across the mapped chunk
+	if s.handler != nil {
+		s.closeOpenHandler()
+	}
+	ek.SetSeq(s.verSeq)
+	_ = s.extents.Append(&ek, false)
+	log.LogDebugf("dedupExtentAdd: ino(%v) offset(%v) fp(%v) ek(%v)", s.inode, offset, fingerprint, ek)
+	return true
+}
+
+// dedupRegisterPending publishes the fingerprints of the flushed chunks.
+func (s *Streamer) dedupRegisterPending() {
+	if len(s.dedupPending) == 0 {
+		return
+	}
+	fps := make([]proto.DedupFingerprint, 0, len(s.dedupPending))
+	for _, chunk := range s.dedupPending {
+		ek := s.extents.Get(chunk.fileOffset)
+		if ek == nil || ek.PartitionId == 0 || storage.IsTinyExtent(ek.ExtentId) {
+			continue
+		}
+		// the chunk has to be held by a single extent key
+		if chunk.fileOffset+uint64(chunk.size) > ek.FileOffset+uint64(ek.Size) {
+			continue
+		}
+		fps = append(fps, proto.DedupFingerprint{
+			Fingerprint: chunk.fingerprint,
+			Extent: proto.ExtentKey{
+				FileOffset:   chunk.fileOffset,
+				PartitionId:  ek.PartitionId,
+				ExtentId:     ek.ExtentId,
+				ExtentOffset: ek.ExtentOffset + (chunk.fileOffset - ek.FileOffset),
+				Size:         chunk.size,
+			},
+		})
+	}
+	s.dedupPending = s.dedupPending[:0]
+	if len(fps) == 0 {
+		return
+	}
+	if err := s.client.dedupRegister(s.inode, fps); err != nil {
+		log.LogWarnf("dedupRegisterPending: ino(%v) fps(%v) err(%v)", s.inode, len(fps), err)
+	}
+}
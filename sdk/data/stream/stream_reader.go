@@ -54,6 +54,7 @@ type Streamer struct {
 	pendingCache         chan bcacheKey
 	verSeq               uint64
 	needUpdateVer        int32
+	dedupPending         []dedupChunk // written chunks whose fingerprints are not registered yet
 }
 
 type bcacheKey struct {
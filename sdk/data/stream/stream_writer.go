@@ -339,6 +339,10 @@ begin:
 
 	for _, req := range requests {
 		var writeSize int
+		if req.ExtentKey != nil && s.client.dataWrapper.IsSharedPartition(req.ExtentKey.PartitionId) {
+			// extents of the clone source are read only, write the range to a new extent instead
+			req.ExtentKey = nil
+		}
 		if req.ExtentKey != nil {
 			if s.client.bcacheEnable {
 				cacheKey := util.GenerateRepVolKey(s.client.volumeName, s.inode, req.ExtentKey.PartitionId, req.ExtentKey.ExtentId, uint64(req.FileOffset))
@@ -352,8 +356,15 @@ begin:
 			}
 			log.LogDebugf("action[streamer.write] inode [%v] latest seq [%v] extentkey seq [%v]  info [%v] before compare seq",
 				s.inode, s.verSeq, req.ExtentKey.GetSeq(), req.ExtentKey)
-			if req.ExtentKey.GetSeq() == s.verSeq {
+			if s.client.isDedupEnable() && !storage.IsTinyExtent(req.ExtentKey.ExtentId) {
+				// extents may be shared by other inodes, never overwrite them in place
+				writeSize, _, err, _ = s.doOverWriteByAppend(req, direct)
+			} else if req.ExtentKey.GetSeq() == s.verSeq {
 				writeSize, err = s.doOverwrite(req, direct)
+				if err == OverwriteRejectedError {
+					log.LogDebugf("action[streamer.write] ino %v do OverWriteByAppend extent key (%v) because overwrite rejected", s.inode, req.ExtentKey)
+					writeSize, _, err, _ = s.doOverWriteByAppend(req, direct)
+				}
 				if err == proto.ErrCodeVersionOp {
 					log.LogDebugf("action[streamer.write] write need version update")
 					if err = s.GetExtentsForce(); err != nil {
@@ -386,7 +397,11 @@ begin:
 					return
 				}
 			}
-			writeSize, err = s.doWriteAppend(req, direct)
+			if s.client.isDedupEnable() {
+				writeSize, err = s.doDedupWriteAppend(req, direct)
+			} else {
+				writeSize, err = s.doWriteAppend(req, direct)
+			}
 		}
 		if err != nil {
 			log.LogErrorf("Streamer write: ino(%v) err(%v)", s.inode, err)
@@ -670,6 +685,11 @@ func (s *Streamer) doOverwrite(req *ExtentRequest, direct bool) (total int, err
 		log.LogDebugf("doOverwrite: ino(%v) req(%v) reqPacket(%v) err(%v) replyPacket(%v)", s.inode, req, reqPacket, err, replyPacket)
 
 		if err != nil || replyPacket.ResultCode != proto.OpOk {
+			if replyPacket.ResultCode == proto.OpTryOtherExtent {
+				// the extent may be shared, the datanode of dedup volume refuses to overwrite it in place
+				err = OverwriteRejectedError
+				return
+			}
 			if replyPacket.ResultCode == proto.ErrCodeVersionOpError {
 				err = proto.ErrCodeVersionOp
 				log.LogWarnf("doOverwrite: need retry.ino(%v) req(%v) reqPacket(%v) err(%v) replyPacket(%v)", s.inode, req, reqPacket, err, replyPacket)
@@ -692,7 +712,12 @@ func (s *Streamer) doOverwrite(req *ExtentRequest, direct bool) (total int, err
 func (s *Streamer) tryInitExtentHandlerByLastEk(offset, size int) (isLastEkVerNotEqual bool) {
 	storeMode := s.GetStoreMod(offset, size)
 	getEndEkFunc := func() *proto.ExtentKey {
-		if ek := s.extents.GetEndForAppendWrite(uint64(offset), s.verSeq, false); ek != nil && !storage.IsTinyExtent(ek.ExtentId) {
+		if s.client.isDedupEnable() {
+			// the last extent key may point into data shared with other inodes
+			return nil
+		}
+		if ek := s.extents.GetEndForAppendWrite(uint64(offset), s.verSeq, false); ek != nil && !storage.IsTinyExtent(ek.ExtentId) &&
+			!s.client.dataWrapper.IsSharedPartition(ek.PartitionId) {
 			return ek
 		}
 		return nil
@@ -888,6 +913,7 @@ func (s *Streamer) flush() (err error) {
 		}
 		log.LogDebugf("Streamer flush end: eh(%v)", eh)
 	}
+	s.dedupRegisterPending()
 	return
 }
 
@@ -58,6 +58,7 @@ type Wrapper struct {
 	volName               string
 	volType               int
 	EnablePosixAcl        bool
+	EnableDedup           bool
 	masters               []string
 	partitions            map[uint64]*DataPartition
 	followerRead          bool
@@ -217,6 +218,7 @@ func (w *Wrapper) GetSimpleVolView() (err error) {
 	w.dpSelectorParm = view.DpSelectorParm
 	w.volType = view.VolType
 	w.EnablePosixAcl = view.EnablePosixAcl
+	w.EnableDedup = view.EnableDedup
 	w.UpdateUidsView(view)
 
 	log.LogDebugf("GetSimpleVolView: get volume simple info: ID(%v) name(%v) owner(%v) status(%v) capacity(%v) "+
@@ -333,6 +335,11 @@ func (w *Wrapper) updateSimpleVolView() (err error) {
 
 	w.UpdateUidsView(view)
 
+	if w.EnableDedup != view.EnableDedup {
+		log.LogInfof("UpdateSimpleVolView: update enableDedup from old(%v) to new(%v)", w.EnableDedup, view.EnableDedup)
+		w.EnableDedup = view.EnableDedup
+	}
+
 	if w.followerRead != view.FollowerRead && !w.followerReadClientCfg {
 		log.LogDebugf("UpdateSimpleVolView: update followerRead from old(%v) to new(%v)",
 			w.followerRead, view.FollowerRead)
@@ -497,6 +504,7 @@ func (w *Wrapper) replaceOrInsertPartition(dp *DataPartition) {
 		old.ReplicaNum = dp.ReplicaNum
 		old.Hosts = dp.Hosts
 		old.IsDiscard = dp.IsDiscard
+		old.IsShared = dp.IsShared
 		old.NearHosts = dp.Hosts
 
 		dp.Metrics = old.Metrics
@@ -532,6 +540,13 @@ func (w *Wrapper) GetDataPartition(partitionID uint64) (*DataPartition, error) {
 	return dp, nil
 }
 
+// IsSharedPartition returns if the data partition belongs to the clone source of the volume,
+// the extents on it are read only and must be overwritten by append.
+func (w *Wrapper) IsSharedPartition(partitionID uint64) bool {
+	dp, ok := w.tryGetPartition(partitionID)
+	return ok && dp.IsShared
+}
+
 func (w *Wrapper) GetReadVerSeq() uint64 {
 	return w.verReadSeq
 }
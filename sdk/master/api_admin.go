@@ -112,6 +112,12 @@ func (api *AdminAPI) Topo() (topo *proto.TopologyView, err error) {
 	return
 }
 
+func (api *AdminAPI) GetPlacementView() (view *proto.PlacementView, err error) {
+	view = &proto.PlacementView{}
+	err = api.mc.requestWith(view, newRequest(get, proto.GetPlacementView).Header(api.h))
+	return
+}
+
 func (api *AdminAPI) GetDataPartition(volName string, partitionID uint64) (partition *proto.DataPartitionInfo, err error) {
 	partition = &proto.DataPartitionInfo{}
 	err = api.mc.requestWith(partition, newRequest(get, proto.AdminGetDataPartition).
@@ -635,6 +641,122 @@ func (api *AdminAPI) SetStrategy(volName string, periodic string, count string,
 	return
 }
 
+func (api *AdminAPI) CloneVolume(volName string, verSeq uint64, cloneName, owner string) (vv *proto.SimpleVolView, err error) {
+	vv = &proto.SimpleVolView{}
+	err = api.mc.requestWith(vv, newRequest(get, proto.AdminCloneVol).Header(api.h).
+		addParam("name", volName).
+		addParam("verSeq", strconv.FormatUint(verSeq, 10)).
+		addParam("cloneName", cloneName).
+		addParam("owner", owner))
+	return
+}
+
+// CreateSnapshotDiff starts listing the paths changed between two versions of the volume,
+// toVerSeq 0 stands for the current version. It returns the id of the diff task.
+func (api *AdminAPI) CreateSnapshotDiff(volName string, fromVerSeq, toVerSeq uint64) (id string, err error) {
+	err = api.mc.requestWith(&id, newRequest(get, proto.AdminSnapshotDiff).Header(api.h).
+		addParam("name", volName).
+		addParam("fromVerSeq", strconv.FormatUint(fromVerSeq, 10)).
+		addParam("toVerSeq", strconv.FormatUint(toVerSeq, 10)))
+	return
+}
+
+func (api *AdminAPI) GetSnapshotDiff(volName, id string, marker, limit int) (result *proto.SnapshotDiffResult, err error) {
+	result = &proto.SnapshotDiffResult{}
+	err = api.mc.requestWith(result, newRequest(get, proto.AdminGetSnapshotDiff).Header(api.h).
+		addParam("name", volName).
+		addParam("id", id).
+		addParam("marker", strconv.Itoa(marker)).
+		addParam("limit", strconv.Itoa(limit)))
+	return
+}
+
+func (api *AdminAPI) CreateSnapshotPolicy(policy *proto.SnapshotPolicy) (err error) {
+	return api.mc.request(newRequest(post, proto.AdminCreateSnapshotPolicy).Header(api.h).Body(policy))
+}
+
+func (api *AdminAPI) UpdateSnapshotPolicy(policy *proto.SnapshotPolicy) (err error) {
+	return api.mc.request(newRequest(post, proto.AdminUpdateSnapshotPolicy).Header(api.h).Body(policy))
+}
+
+func (api *AdminAPI) DeleteSnapshotPolicy(policyName string) (err error) {
+	return api.mc.request(newRequest(get, proto.AdminDeleteSnapshotPolicy).Header(api.h).
+		addParam("policy", policyName))
+}
+
+func (api *AdminAPI) AttachSnapshotPolicy(volName, policyName string) (err error) {
+	return api.mc.request(newRequest(get, proto.AdminAttachSnapshotPolicy).Header(api.h).
+		addParam("name", volName).
+		addParam("policy", policyName))
+}
+
+func (api *AdminAPI) DetachSnapshotPolicy(volName, policyName string) (err error) {
+	return api.mc.request(newRequest(get, proto.AdminDetachSnapshotPolicy).Header(api.h).
+		addParam("name", volName).
+		addParam("policy", policyName))
+}
+
+func (api *AdminAPI) ListSnapshotPolicy() (policies []*proto.SnapshotPolicy, err error) {
+	policies = make([]*proto.SnapshotPolicy, 0)
+	err = api.mc.requestWith(&policies, newRequest(get, proto.AdminListSnapshotPolicy).Header(api.h))
+	return
+}
+
+func (api *AdminAPI) GetSnapshotPolicyStatus(policyName string) (status *proto.SnapshotPolicyStatus, err error) {
+	status = &proto.SnapshotPolicyStatus{}
+	err = api.mc.requestWith(status, newRequest(get, proto.AdminSnapshotPolicyStatus).Header(api.h).
+		addParam("policy", policyName))
+	return
+}
+
+func (api *AdminAPI) BackupMetadata(file string) (info *proto.MetadataBackupInfo, err error) {
+	info = &proto.MetadataBackupInfo{}
+	err = api.mc.requestWith(info, newRequest(post, proto.AdminMetadataBackup).Header(api.h).
+		addParam("file", file))
+	return
+}
+
+func (api *AdminAPI) ListMetadataBackup() (infos []*proto.MetadataBackupInfo, err error) {
+	infos = make([]*proto.MetadataBackupInfo, 0)
+	err = api.mc.requestWith(&infos, newRequest(get, proto.AdminListMetadataBackup).Header(api.h))
+	return
+}
+
+func (api *AdminAPI) VerifyMetadataBackup(file string) (report *proto.MetadataBackupVerifyReport, err error) {
+	report = &proto.MetadataBackupVerifyReport{}
+	err = api.mc.requestWith(report, newRequest(get, proto.AdminVerifyMetadataBackup).Header(api.h).
+		addParam("file", file))
+	return
+}
+
+func (api *AdminAPI) ListEvents(filter *proto.ClusterEventFilter) (events []*proto.ClusterEvent, err error) {
+	events = make([]*proto.ClusterEvent, 0)
+	request := newRequest(get, proto.AdminListEvents).Header(api.h)
+	if filter.Type != "" {
+		request.addParam("type", filter.Type)
+	}
+	if filter.Severity != "" {
+		request.addParam("severity", filter.Severity)
+	}
+	if filter.ObjectID != "" {
+		request.addParam("object", filter.ObjectID)
+	}
+	if filter.Vol != "" {
+		request.addParam("name", filter.Vol)
+	}
+	if filter.Start > 0 {
+		request.addParam("start", strconv.FormatInt(filter.Start, 10))
+	}
+	if filter.End > 0 {
+		request.addParam("end", strconv.FormatInt(filter.End, 10))
+	}
+	if filter.Limit > 0 {
+		request.addParam("limit", strconv.Itoa(filter.Limit))
+	}
+	err = api.mc.requestWith(&events, request)
+	return
+}
+
 func (api *AdminAPI) CreateVersion(volName string) (ver *proto.VolVersionInfo, err error) {
 	ver = &proto.VolVersionInfo{}
 	err = api.mc.requestWith(ver, newRequest(get, proto.AdminCreateVersion).
@@ -681,3 +803,58 @@ func (api *AdminAPI) DelBucketLifecycle(volume string) (err error) {
 func (api *AdminAPI) GetS3QoSInfo() (data []byte, err error) {
 	return api.mc.serveRequest(newRequest(get, proto.S3QoSGet).Header(api.h))
 }
+
+func (api *AdminAPI) SetTenantQos(q *proto.TenantQos) (err error) {
+	return api.mc.request(newRequest(post, proto.QosSetTenant).Header(api.h).Body(q))
+}
+
+func (api *AdminAPI) DeleteTenantQos(tenant string) (err error) {
+	return api.mc.request(newRequest(get, proto.QosDeleteTenant).Header(api.h).
+		addParam("tenant", tenant))
+}
+
+func (api *AdminAPI) GetTenantQosStatus(tenant string) (status []*proto.TenantQosStatus, err error) {
+	status = make([]*proto.TenantQosStatus, 0)
+	request := newRequest(get, proto.QosGetTenantStatus).Header(api.h)
+	if tenant != "" {
+		request.addParam("tenant", tenant)
+	}
+	err = api.mc.requestWith(&status, request)
+	return
+}
+
+func (api *AdminAPI) ReportObjectNodeQos(report *proto.ObjectNodeQosReport) (limit *proto.ObjectNodeQosLimit, err error) {
+	limit = &proto.ObjectNodeQosLimit{}
+	err = api.mc.requestWith(limit, newRequest(post, proto.QosObjectNodeReport).Header(api.h).Body(report))
+	return
+}
+
+func (api *AdminAPI) SetCapacityPolicy(policy *proto.CapacityPolicy) (err error) {
+	return api.mc.request(newRequest(post, proto.AdminSetCapacityPolicy).Header(api.h).Body(policy))
+}
+
+func (api *AdminAPI) DeleteCapacityPolicy(volName string) (err error) {
+	return api.mc.request(newRequest(get, proto.AdminDeleteCapacityPolicy).Header(api.h).
+		addParam("name", volName))
+}
+
+// GetVolCapacityForecast returns the forecast of the volume, or of all the volumes if volName is empty.
+func (api *AdminAPI) GetVolCapacityForecast(volName string) (status []*proto.VolCapacityStatus, err error) {
+	status = make([]*proto.VolCapacityStatus, 0)
+	request := newRequest(get, proto.AdminGetVolCapacityForecast).Header(api.h)
+	if volName != "" {
+		request.addParam("name", volName)
+	}
+	err = api.mc.requestWith(&status, request)
+	return
+}
+
+func (api *AdminAPI) GetZoneCapacityForecast(zoneName string) (status []*proto.ZoneCapacityStatus, err error) {
+	status = make([]*proto.ZoneCapacityStatus, 0)
+	request := newRequest(get, proto.AdminGetZoneCapacityForecast).Header(api.h)
+	if zoneName != "" {
+		request.addParam("zoneName", zoneName)
+	}
+	err = api.mc.requestWith(&status, request)
+	return
+}
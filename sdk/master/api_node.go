@@ -50,9 +50,17 @@ func (api *NodeAPI) AddDataNode(serverAddr, zoneName string) (id uint64, err err
 }
 
 func (api *NodeAPI) AddDataNodeWithAuthNode(serverAddr, zoneName, clientIDKey string) (id uint64, err error) {
+	return api.AddDataNodeWithLocation(serverAddr, zoneName, "", "", clientIDKey)
+}
+
+// AddDataNodeWithLocation registers the node with its rack and host labels, an
+// empty label is left unchanged on the master.
+func (api *NodeAPI) AddDataNodeWithLocation(serverAddr, zoneName, rackName, hostName, clientIDKey string) (id uint64, err error) {
 	request := newRequest(get, proto.AddDataNode).Header(api.h)
 	request.addParam("addr", serverAddr)
 	request.addParam("zoneName", zoneName)
+	request.addParam("rackName", rackName)
+	request.addParam("hostName", hostName)
 	request.addParam("clientIDKey", clientIDKey)
 	var data []byte
 	if data, err = api.mc.serveRequest(request); err != nil {
@@ -75,9 +83,17 @@ func (api *NodeAPI) AddMetaNode(serverAddr, zoneName string) (id uint64, err err
 }
 
 func (api *NodeAPI) AddMetaNodeWithAuthNode(serverAddr, zoneName, clientIDKey string) (id uint64, err error) {
+	return api.AddMetaNodeWithLocation(serverAddr, zoneName, "", "", clientIDKey)
+}
+
+// AddMetaNodeWithLocation registers the node with its rack and host labels, an
+// empty label is left unchanged on the master.
+func (api *NodeAPI) AddMetaNodeWithLocation(serverAddr, zoneName, rackName, hostName, clientIDKey string) (id uint64, err error) {
 	request := newRequest(get, proto.AddMetaNode).Header(api.h)
 	request.addParam("addr", serverAddr)
 	request.addParam("zoneName", zoneName)
+	request.addParam("rackName", rackName)
+	request.addParam("hostName", hostName)
 	request.addParam("clientIDKey", clientIDKey)
 	var data []byte
 	if data, err = api.mc.serveRequest(request); err != nil {
@@ -166,3 +182,7 @@ func (api *NodeAPI) AddLcNode(serverAddr string) (id uint64, err error) {
 func (api *NodeAPI) ResponseLcNodeTask(task *proto.AdminTask) (err error) {
 	return api.mc.request(newRequest(post, proto.GetLcNodeTaskResponse).Header(api.h).Body(task))
 }
+
+func (api *NodeAPI) PutSnapshotDiffPage(page *proto.SnapshotDiffPage) (err error) {
+	return api.mc.request(newRequest(post, proto.PutLcNodeSnapshotDiff).Header(api.h).Body(page))
+}
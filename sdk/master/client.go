@@ -16,11 +16,14 @@ package master
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -59,6 +62,8 @@ type MasterClient struct {
 	leaderAddr  string
 	timeout     time.Duration
 	clientIDKey string
+	accessKey   string
+	secretKey   string
 
 	adminAPI  *AdminAPI
 	clientAPI *ClientAPI
@@ -128,6 +133,33 @@ func (c *MasterClient) SetClientIDKey(clientIDKey string) {
 	c.Unlock()
 }
 
+// SetAdminCredential signs the requests with the keys of a master user, see
+// proto.AdminRequestSignature.
+func (c *MasterClient) SetAdminCredential(accessKey, secretKey string) {
+	c.Lock()
+	c.accessKey = accessKey
+	c.secretKey = secretKey
+	c.Unlock()
+}
+
+func (c *MasterClient) signRequest(req *http.Request, body []byte) {
+	c.RLock()
+	accessKey, secretKey := c.accessKey, c.secretKey
+	c.RUnlock()
+	if accessKey == "" {
+		return
+	}
+	date := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	nonceStr := hex.EncodeToString(nonce)
+	signature := proto.AdminRequestSignature(secretKey, req.Method, req.URL.Path, req.URL.Query(), date, nonceStr, body)
+	req.Header.Set(proto.AdminAccessKeyHeader, accessKey)
+	req.Header.Set(proto.AdminDateHeader, date)
+	req.Header.Set(proto.AdminNonceHeader, nonceStr)
+	req.Header.Set(proto.AdminSignatureHeader, signature)
+}
+
 func (c *MasterClient) serveRequest(r *request) (repsData []byte, err error) {
 	leaderAddr, nodes := c.prepareRequest()
 	host := leaderAddr
@@ -258,6 +290,7 @@ func (c *MasterClient) httpRequest(method, url string, r *request) (resp *http.R
 	for k, v := range r.header {
 		req.Header.Set(k, v)
 	}
+	c.signRequest(req, r.body)
 	resp, err = client.Do(req)
 	return
 }
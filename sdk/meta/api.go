@@ -1443,6 +1443,90 @@ func (mw *MetaWrapper) AppendExtentKeys(inode uint64, eks []proto.ExtentKey) err
 	return nil
 }
 
+// DedupExtentKey tries to map the chunk at fileOffset onto already stored data
+// with the same fingerprint. found is false if the fingerprint is unknown.
+func (mw *MetaWrapper) DedupExtentKey(parentInode, inode, fileOffset uint64, fingerprint string) (ek proto.ExtentKey, found bool, err error) {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		return ek, false, syscall.ENOENT
+	}
+	var oldInfo *proto.InodeInfo
+	if mw.EnableSummary {
+		oldInfo, _ = mw.InodeGet_ll(inode)
+	}
+
+	status, ek, err := mw.dedupExtentAdd(mp, inode, fileOffset, fingerprint)
+	if status == statusNoent {
+		return ek, false, nil
+	}
+	if err != nil || status != statusOK {
+		log.LogErrorf("DedupExtentKey: inode(%v) fileOffset(%v) fp(%v) err(%v) status(%v)", inode, fileOffset, fingerprint, err, status)
+		return ek, false, statusToErrno(status)
+	}
+	log.LogDebugf("DedupExtentKey: ino(%v) fileOffset(%v) fp(%v) ek(%v)", inode, fileOffset, fingerprint, ek)
+
+	if mw.EnableSummary {
+		go func() {
+			newInfo, _ := mw.InodeGet_ll(inode)
+			if oldInfo != nil && newInfo != nil {
+				if int64(oldInfo.Size) < int64(newInfo.Size) {
+					mw.UpdateSummary_ll(parentInode, 0, 0, int64(newInfo.Size)-int64(oldInfo.Size))
+				}
+			}
+		}()
+	}
+	return ek, true, nil
+}
+
+// DedupRegister publishes the fingerprints of the chunks written by an inode.
+func (mw *MetaWrapper) DedupRegister(inode uint64, fps []proto.DedupFingerprint) error {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		return syscall.ENOENT
+	}
+
+	status, err := mw.dedupRegister(mp, inode, fps)
+	if err != nil || status != statusOK {
+		log.LogErrorf("DedupRegister: inode(%v) fps(%v) err(%v) status(%v)", inode, len(fps), err, status)
+		return statusToErrno(status)
+	}
+	log.LogDebugf("DedupRegister: ino(%v) fps(%v)", inode, len(fps))
+	return nil
+}
+
+// InodeVerDiff_ll lists the inodes of the volume changed between the versions
+// fromVerSeq and toVerSeq, toVerSeq 0 stands for the current version.
+func (mw *MetaWrapper) InodeVerDiff_ll(fromVerSeq, toVerSeq uint64) (diffs []proto.InodeVerDiff, err error) {
+	mw.RLock()
+	partitions := make([]*MetaPartition, 0, len(mw.partitions))
+	for _, mp := range mw.partitions {
+		partitions = append(partitions, mp)
+	}
+	mw.RUnlock()
+
+	for _, mp := range partitions {
+		var marker uint64
+		for {
+			status, resp, err := mw.inodeVerDiff(mp, fromVerSeq, toVerSeq, marker, 0)
+			if err != nil || status != statusOK {
+				log.LogErrorf("InodeVerDiff_ll: mp(%v) from(%v) to(%v) marker(%v) err(%v) status(%v)",
+					mp.PartitionID, fromVerSeq, toVerSeq, marker, err, status)
+				if err == nil {
+					err = statusToErrno(status)
+				}
+				return nil, err
+			}
+			diffs = append(diffs, resp.Diffs...)
+			if resp.NextMarker == 0 {
+				break
+			}
+			marker = resp.NextMarker
+		}
+	}
+	log.LogDebugf("InodeVerDiff_ll: from(%v) to(%v) diffs(%v)", fromVerSeq, toVerSeq, len(diffs))
+	return
+}
+
 // AppendObjExtentKeys append multiple obj extent key into specified inode with single request.
 func (mw *MetaWrapper) AppendObjExtentKeys(inode uint64, eks []proto.ObjExtentKey) error {
 	mp := mw.getPartitionByInode(inode)
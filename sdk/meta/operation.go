@@ -1262,6 +1262,149 @@ func (mw *MetaWrapper) appendExtentKey(mp *MetaPartition, inode uint64, extent p
 	return status, err
 }
 
+func (mw *MetaWrapper) dedupExtentAdd(mp *MetaPartition, inode, fileOffset uint64, fingerprint string) (status int, ek proto.ExtentKey, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("dedupExtentAdd", err, bgTime, 1)
+	}()
+
+	req := &proto.DedupExtentAddRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		Inode:       inode,
+		FileOffset:  fileOffset,
+		Fingerprint: fingerprint,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaDedupExtentAdd
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("dedupExtentAdd: req(%v) err(%v)", *req, err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("dedupExtentAdd: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		if status != statusNoent {
+			err = errors.New(packet.GetResultMsg())
+			log.LogErrorf("dedupExtentAdd: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		}
+		return
+	}
+
+	resp := new(proto.DedupExtentAddResponse)
+	if err = packet.UnmarshalData(resp); err != nil {
+		log.LogErrorf("dedupExtentAdd: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
+		return
+	}
+	ek = resp.Extent
+	return
+}
+
+func (mw *MetaWrapper) dedupRegister(mp *MetaPartition, inode uint64, fps []proto.DedupFingerprint) (status int, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("dedupRegister", err, bgTime, 1)
+	}()
+
+	req := &proto.DedupRegisterRequest{
+		VolName:      mw.volname,
+		PartitionID:  mp.PartitionID,
+		Inode:        inode,
+		Fingerprints: fps,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaDedupRegister
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("dedupRegister: req(%v) err(%v)", *req, err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("dedupRegister: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		err = errors.New(packet.GetResultMsg())
+		log.LogErrorf("dedupRegister: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+	}
+	return
+}
+
+func (mw *MetaWrapper) inodeVerDiff(mp *MetaPartition, fromVerSeq, toVerSeq, marker, limit uint64) (status int, resp *proto.InodeVerDiffResponse, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("inodeVerDiff", err, bgTime, 1)
+	}()
+
+	req := &proto.InodeVerDiffRequest{
+		VolName:     mw.volname,
+		PartitionID: mp.PartitionID,
+		FromVerSeq:  fromVerSeq,
+		ToVerSeq:    toVerSeq,
+		Marker:      marker,
+		Limit:       limit,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaInodeVerDiff
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("inodeVerDiff: req(%v) err(%v)", *req, err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("inodeVerDiff: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		err = errors.New(packet.GetResultMsg())
+		log.LogErrorf("inodeVerDiff: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	resp = new(proto.InodeVerDiffResponse)
+	if err = packet.UnmarshalData(resp); err != nil {
+		log.LogErrorf("inodeVerDiff: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
+		return
+	}
+	return
+}
+
 func (mw *MetaWrapper) getExtents(mp *MetaPartition, inode uint64) (resp *proto.GetExtentsResponse, err error) {
 	bgTime := stat.BeginStat()
 	defer func() {
@@ -377,6 +377,45 @@ func (e *Extent) Write(data []byte, offset, size int64, crc uint32, writeType in
 	return
 }
 
+// ReplayWrite writes back data that has been acknowledged before it reached
+// the extent file, the extent may already hold part or all of it. Unlike Write
+// no offset is checked, the data size only grows and the data is synced.
+func (e *Extent) ReplayWrite(data []byte, offset, size int64, writeType int, crcFunc UpdateCrcFunc) (err error) {
+	e.Lock()
+	defer e.Unlock()
+	if _, err = e.file.WriteAt(data[:size], offset); err != nil {
+		return
+	}
+	if err = e.file.Sync(); err != nil {
+		return
+	}
+	index := offset + size
+	if IsTinyExtent(e.extentID) {
+		if !IsAppendWrite(writeType) {
+			return
+		}
+		if index%util.PageSize != 0 {
+			index = index + (util.PageSize - index%util.PageSize)
+		}
+		if index > e.dataSize {
+			e.dataSize = index
+		}
+		return
+	}
+	if IsAppendWrite(writeType) && index > e.dataSize {
+		e.dataSize = index
+	} else if IsAppendRandomWrite(writeType) && uint64(index) > e.snapshotDataOff {
+		e.snapshotDataOff = uint64(index)
+	}
+	// the crc of the touched blocks is computed again when read
+	for blockNo := offset / util.BlockSize; blockNo*util.BlockSize < index; blockNo++ {
+		if err = crcFunc(e, int(blockNo), 0); err != nil {
+			return
+		}
+	}
+	return
+}
+
 // Read reads data from an extent.
 func (e *Extent) Read(data []byte, offset, size int64, isRepairRead bool) (crc uint32, err error) {
 	log.LogDebugf("action[Extent.read] offset %v size %v extent %v", offset, size, e)
@@ -122,6 +122,8 @@ type ExtentStore struct {
 	storeSize              int      // size of the extent store
 	metadataFp             *os.File // metadata file pointer?
 	tinyExtentDeleteFp     *os.File
+	tinyDeleteMutex        sync.Mutex // guards tinyExtentDeleteFp, it is replaced on compaction
+	tinyDeleteGeneration   uint64     // changed whenever tiny delete records are dropped
 	normalExtentDeleteFp   *os.File
 	closeC                 chan bool
 	closed                 bool
@@ -198,6 +200,9 @@ func NewExtentStore(dataDir string, partitionID uint64, storeSize, dpType int, i
 		data := make([]byte, needWriteEmpty)
 		s.tinyExtentDeleteFp.Write(data)
 	}
+	if err = s.loadTinyDeleteGeneration(); err != nil {
+		return
+	}
 
 	log.LogDebugf("NewExtentStore.partitionID [%v] dataPath %v verifyExtentFp init", partitionID, s.dataPath)
 	if s.verifyExtentFp, err = os.OpenFile(path.Join(s.dataPath, ExtCrcHeaderFileName), os.O_CREATE|os.O_RDWR, 0o666); err != nil {
@@ -408,6 +413,38 @@ func (s *ExtentStore) Write(extentID uint64, offset, size int64, data []byte, cr
 	return status, nil
 }
 
+// ReplayWrite writes back data logged by the write journal of the data node.
+// It returns ExtentNotFoundError if the extent has been deleted meanwhile.
+func (s *ExtentStore) ReplayWrite(extentID uint64, offset, size int64, data []byte, writeType int) (err error) {
+	s.eiMutex.Lock()
+	ei := s.extentInfoMap[extentID]
+	e, err := s.extentWithHeader(ei)
+	s.eiMutex.Unlock()
+	if err != nil {
+		return
+	}
+	if err = e.ReplayWrite(data, offset, size, writeType, s.PersistenceBlockCrc); err != nil {
+		return
+	}
+	ei.UpdateExtentInfo(e, 0)
+	return
+}
+
+// SyncExtent flushes the extent file. A deleted extent is skipped.
+func (s *ExtentStore) SyncExtent(extentID uint64) (err error) {
+	s.eiMutex.Lock()
+	ei := s.extentInfoMap[extentID]
+	e, err := s.extentWithHeader(ei)
+	s.eiMutex.Unlock()
+	if err == ExtentNotFoundError {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	return e.Flush()
+}
+
 func (s *ExtentStore) checkOffsetAndSize(extentID uint64, offset, size int64, writeType int) error {
 	if IsTinyExtent(extentID) {
 		return nil
@@ -491,6 +528,23 @@ func (s *ExtentStore) punchDelete(extentID uint64, offset, size int64) (err erro
 	return
 }
 
+// PunchExtentData releases the disk space taken by the data of a normal
+// extent whose content is kept elsewhere, the extent size is left unchanged.
+func (s *ExtentStore) PunchExtentData(extentID uint64) (err error) {
+	if IsTinyExtent(extentID) {
+		return ParameterMismatchError
+	}
+	e, err := s.extentWithHeaderByExtentID(extentID)
+	if err != nil {
+		return
+	}
+	if e.dataSize == 0 {
+		return
+	}
+	_, err = e.punchDelete(0, e.dataSize)
+	return
+}
+
 // MarkDelete marks the given extent as deleted.
 func (s *ExtentStore) MarkDelete(extentID uint64, offset, size int64) (err error) {
 	var ei *ExtentInfo
@@ -857,6 +911,8 @@ func UnMarshalTinyExtent(data []byte) (extentID, offset, size uint64) {
 }
 
 func (s *ExtentStore) RecordTinyDelete(extentID uint64, offset, size int64) (err error) {
+	s.tinyDeleteMutex.Lock()
+	defer s.tinyDeleteMutex.Unlock()
 	record := MarshalTinyExtent(extentID, offset, size)
 	stat, err := s.tinyExtentDeleteFp.Stat()
 	if err != nil {
@@ -876,6 +932,8 @@ func (s *ExtentStore) RecordTinyDelete(extentID uint64, offset, size int64) (err
 }
 
 func (s *ExtentStore) ReadTinyDeleteRecords(offset, size int64, data []byte) (crc uint32, err error) {
+	s.tinyDeleteMutex.Lock()
+	defer s.tinyDeleteMutex.Unlock()
 	_, err = s.tinyExtentDeleteFp.ReadAt(data[:size], offset)
 	if err == nil || err == io.EOF {
 		err = nil
@@ -891,6 +949,8 @@ type ExtentDeleted struct {
 }
 
 func (s *ExtentStore) GetHasDeleteTinyRecords() (extentDes []ExtentDeleted, err error) {
+	s.tinyDeleteMutex.Lock()
+	defer s.tinyDeleteMutex.Unlock()
 	data := make([]byte, DeleteTinyRecordSize)
 	offset := int64(0)
 
@@ -920,6 +980,8 @@ func (s *ExtentStore) NextExtentID() (extentID uint64, err error) {
 }
 
 func (s *ExtentStore) LoadTinyDeleteFileOffset() (offset int64, err error) {
+	s.tinyDeleteMutex.Lock()
+	defer s.tinyDeleteMutex.Unlock()
 	stat, err := s.tinyExtentDeleteFp.Stat()
 	if err == nil {
 		offset = stat.Size()
@@ -345,3 +345,85 @@ func TestExtentStores(t *testing.T) {
 		extentStoreTest(t, ty)
 	}
 }
+
+func TestResetTinyExtent(t *testing.T) {
+	path, clean, err := getTestPathExtentStore()
+	require.NoError(t, err)
+	defer clean()
+	s, err := storage.NewExtentStore(path, 0, 1*util.GB, proto.PartitionTypeNormal, true)
+	require.NoError(t, err)
+	defer s.Close()
+
+	id, other := uint64(storage.TinyExtentStartID), uint64(storage.TinyExtentStartID+1)
+	data := bytes.Repeat([]byte("a"), util.PageSize*2)
+	crc := crc32.ChecksumIEEE(data)
+	for _, extentID := range []uint64{id, other} {
+		_, err = s.Write(extentID, 0, int64(len(data)), data, crc, storage.AppendWriteType, true)
+		require.NoError(t, err)
+		require.NoError(t, s.MarkDelete(extentID, 0, int64(len(data))))
+		s.SendToAvailableTinyExtentC(extentID)
+	}
+
+	cnt := s.AvailableTinyExtentCnt()
+	require.True(t, s.ReserveTinyExtent(id))
+	require.False(t, s.ReserveTinyExtent(id))
+	require.Equal(t, cnt-1, s.AvailableTinyExtentCnt())
+
+	size, used, err := s.TinyExtentUsage(id)
+	require.NoError(t, err)
+	require.EqualValues(t, len(data), size)
+	require.EqualValues(t, 0, used)
+
+	// the other extent still holds its data
+	_, err = s.Write(other, int64(len(data)), int64(len(data)), data, crc, storage.AppendWriteType, true)
+	require.NoError(t, err)
+	recordSize, err := s.LoadTinyDeleteFileOffset()
+	require.NoError(t, err)
+	require.EqualValues(t, 2*storage.DeleteTinyRecordSize, recordSize)
+	require.EqualValues(t, 0, s.TinyDeleteGeneration())
+
+	released, err := s.ResetTinyExtent(id, 10)
+	require.NoError(t, err)
+	require.EqualValues(t, storage.DeleteTinyRecordSize, released)
+	require.EqualValues(t, 10, s.TinyDeleteGeneration())
+
+	// the extent starts over and the delete file shrinks
+	size, used, err = s.TinyExtentUsage(id)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, size)
+	require.EqualValues(t, 0, used)
+	offset, err := s.GetTinyExtentOffset(id)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, offset)
+	newRecordSize, err := s.LoadTinyDeleteFileOffset()
+	require.NoError(t, err)
+	require.Equal(t, recordSize-storage.DeleteTinyRecordSize, newRecordSize)
+	eds, err := s.GetHasDeleteTinyRecords()
+	require.NoError(t, err)
+	require.Len(t, eds, 1)
+	require.Equal(t, other, eds[0].ExtentID)
+	offset, err = s.GetTinyExtentOffset(other)
+	require.NoError(t, err)
+	require.EqualValues(t, 2*len(data), offset)
+
+	// a retried reset moves to the new generation
+	released, err = s.ResetTinyExtent(id, 11)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, released)
+	require.EqualValues(t, 11, s.TinyDeleteGeneration())
+
+	// the extent takes writes again from offset zero
+	_, err = s.Write(id, 0, int64(len(data)), data, crc, storage.AppendWriteType, true)
+	require.NoError(t, err)
+	require.NoError(t, s.RecordTinyDelete(id, 0, int64(len(data))))
+	newRecordSize, err = s.LoadTinyDeleteFileOffset()
+	require.NoError(t, err)
+	require.Equal(t, recordSize, newRecordSize)
+
+	// the generation survives a restart
+	s.Close()
+	s, err = storage.NewExtentStore(path, 0, 1*util.GB, proto.PartitionTypeNormal, false)
+	require.NoError(t, err)
+	defer s.Close()
+	require.EqualValues(t, 11, s.TinyDeleteGeneration())
+}
@@ -0,0 +1,202 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync/atomic"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// A tiny extent only grows, deleting a small file punches a hole and appends a
+// record to the tiny delete file. Once the live data of a tiny extent has been
+// moved elsewhere the extent can be reset: it is truncated, its records are
+// dropped from the tiny delete file and the reset generation of the store is
+// changed. The replicas sync the tiny delete file by offset only between the
+// same generation, so a record dropped by a reset is never applied to the data
+// appended to the extent afterwards.
+
+// TinyExtDeletedGenFileName persists the reset generation of the tiny delete file.
+const TinyExtDeletedGenFileName = "TINYEXTENT_DELETE_GEN"
+
+func (s *ExtentStore) loadTinyDeleteGeneration() (err error) {
+	data, err := os.ReadFile(path.Join(s.dataPath, TinyExtDeletedGenFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	if len(data) != 8 {
+		return fmt.Errorf("invalid %v size %v", TinyExtDeletedGenFileName, len(data))
+	}
+	atomic.StoreUint64(&s.tinyDeleteGeneration, binary.BigEndian.Uint64(data))
+	return
+}
+
+func (s *ExtentStore) persistTinyDeleteGeneration(generation uint64) (err error) {
+	filePath := path.Join(s.dataPath, TinyExtDeletedGenFileName)
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, generation)
+	if err = writeFileSync(filePath+".tmp", data); err != nil {
+		return
+	}
+	return os.Rename(filePath+".tmp", filePath)
+}
+
+func writeFileSync(filePath string, data []byte) (err error) {
+	fp, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o666)
+	if err != nil {
+		return
+	}
+	if _, err = fp.Write(data); err == nil {
+		err = fp.Sync()
+	}
+	if errClose := fp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		os.Remove(filePath)
+	}
+	return
+}
+
+// TinyDeleteGeneration returns the reset generation of the tiny delete file.
+func (s *ExtentStore) TinyDeleteGeneration() uint64 {
+	return atomic.LoadUint64(&s.tinyDeleteGeneration)
+}
+
+// TinyExtentUsage returns the logical size of the tiny extent and the bytes
+// actually taken on disk.
+func (s *ExtentStore) TinyExtentUsage(extentID uint64) (size, used int64, err error) {
+	if !IsTinyExtent(extentID) {
+		return 0, 0, ParameterMismatchError
+	}
+	e, err := s.extentWithHeaderByExtentID(extentID)
+	if err != nil {
+		return
+	}
+	e.Lock()
+	defer e.Unlock()
+	return e.dataSize, e.getRealBlockCnt() * DiskSectorSize, nil
+}
+
+// ReserveTinyExtent takes the tiny extent out of the available channel so that
+// nothing is appended to it. It returns false if the extent is not available.
+func (s *ExtentStore) ReserveTinyExtent(extentID uint64) (ok bool) {
+	cnt := s.AvailableTinyExtentCnt()
+	for i := 0; i < cnt; i++ {
+		id, err := s.GetAvailableTinyExtent()
+		if err != nil {
+			break
+		}
+		if id == extentID {
+			ok = true
+			continue
+		}
+		s.SendToAvailableTinyExtentC(id)
+	}
+	return
+}
+
+// ResetTinyExtent truncates the tiny extent, drops its records from the tiny
+// delete file and moves the store to the given reset generation. The caller
+// must make sure no extent key refers to the extent any more and nothing is
+// appended to it meanwhile, it may be called again with another generation if
+// the reset fails on some replica. It returns the bytes released on disk.
+func (s *ExtentStore) ResetTinyExtent(extentID, generation uint64) (released int64, err error) {
+	if !IsTinyExtent(extentID) {
+		return 0, ParameterMismatchError
+	}
+	e, err := s.extentWithHeaderByExtentID(extentID)
+	if err != nil {
+		return
+	}
+	e.Lock()
+	used := e.getRealBlockCnt() * DiskSectorSize
+	size := e.dataSize
+	if err = e.file.Truncate(0); err != nil {
+		e.Unlock()
+		return
+	}
+	e.dataSize = 0
+	e.Unlock()
+
+	s.eiMutex.Lock()
+	if ei, ok := s.extentInfoMap[extentID]; ok {
+		ei.Size = 0
+	}
+	s.eiMutex.Unlock()
+
+	records, err := s.dropTinyDeleteRecords(extentID, generation)
+	if err != nil {
+		return
+	}
+	released = used + records
+	log.LogInfof("action[ResetTinyExtent] dp %v extent %v size %v used %v records %v generation %v",
+		s.partitionID, extentID, size, used, records, generation)
+	return
+}
+
+// dropTinyDeleteRecords rewrites the tiny delete file without the records of
+// the extent, the file and the generation are replaced together.
+func (s *ExtentStore) dropTinyDeleteRecords(extentID, generation uint64) (dropped int64, err error) {
+	s.tinyDeleteMutex.Lock()
+	defer s.tinyDeleteMutex.Unlock()
+
+	stat, err := s.tinyExtentDeleteFp.Stat()
+	if err != nil {
+		return
+	}
+	data := make([]byte, stat.Size()-stat.Size()%DeleteTinyRecordSize)
+	if _, err = s.tinyExtentDeleteFp.ReadAt(data, 0); err != nil && err != io.EOF {
+		return
+	}
+	kept := make([]byte, 0, len(data))
+	for off := 0; off < len(data); off += DeleteTinyRecordSize {
+		record := data[off : off+DeleteTinyRecordSize]
+		if id, _, _ := UnMarshalTinyExtent(record); id == extentID {
+			dropped += DeleteTinyRecordSize
+			continue
+		}
+		kept = append(kept, record...)
+	}
+
+	filePath := path.Join(s.dataPath, TinyExtDeletedFileName)
+	if dropped > 0 {
+		if err = writeFileSync(filePath+".tmp", kept); err != nil {
+			return
+		}
+		if err = os.Rename(filePath+".tmp", filePath); err != nil {
+			return
+		}
+		var fp *os.File
+		if fp, err = os.OpenFile(filePath, os.O_RDWR|os.O_APPEND, 0o666); err != nil {
+			return 0, fmt.Errorf("reopen %v: %v", filePath, err)
+		}
+		s.tinyExtentDeleteFp.Close()
+		s.tinyExtentDeleteFp = fp
+	}
+	if err = s.persistTinyDeleteGeneration(generation); err != nil {
+		return
+	}
+	atomic.StoreUint64(&s.tinyDeleteGeneration, generation)
+	return
+}